@@ -0,0 +1,121 @@
+package cloud
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Strategies of spreading the uploads across buckets.
+const (
+	BucketRoundRobin = "round-robin" // in turn by weight
+	BucketRandom     = "random"      // randomly by weight
+	BucketFastest    = "fastest"     // in turn, weighted by the measured latency
+)
+
+// BucketStrategies are the supported strategies of BucketPicker.
+var BucketStrategies = []string{BucketRoundRobin, BucketRandom, BucketFastest}
+
+// WeightedBucket is a bucket with its share of the uploads.
+type WeightedBucket struct {
+	Name   string
+	Weight int
+}
+
+// ParseBuckets parses the comma separated buckets of s, each optionally with
+// a weight, e.g. 'a,b:3' uploads 3 times as many files to b as to a.
+func ParseBuckets(s string) ([]WeightedBucket, error) {
+	var ret []WeightedBucket
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		b := WeightedBucket{Name: f, Weight: 1}
+		if i := strings.LastIndex(f, ":"); i >= 0 {
+			w, err := strconv.Atoi(f[i+1:])
+			if err != nil || w <= 0 || i == 0 {
+				return nil, fmt.Errorf("invalid bucket %q, expect name or name:weight", f)
+			}
+			b = WeightedBucket{Name: f[:i], Weight: w}
+		}
+		ret = append(ret, b)
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no bucket is given")
+	}
+	return ret, nil
+}
+
+// BucketPicker picks the bucket of each upload among the weighted buckets,
+// either in turn by the smooth weighted round-robin, or randomly by weight.
+// It is safe for concurrent use.
+type BucketPicker struct {
+	buckets []WeightedBucket
+	random  bool
+
+	mu      sync.Mutex
+	current []int
+	total   int
+	rnd     *rand.Rand
+}
+
+// NewBucketPicker creates a picker of buckets, randomly if random is set.
+// Buckets of non-positive weights are never picked.
+func NewBucketPicker(buckets []WeightedBucket, random bool) *BucketPicker {
+	bp := BucketPicker{
+		random: random,
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, b := range buckets {
+		if b.Weight > 0 {
+			bp.buckets = append(bp.buckets, b)
+			bp.total += b.Weight
+		}
+	}
+	bp.current = make([]int, len(bp.buckets))
+	return &bp
+}
+
+// Buckets gives the buckets that could be picked.
+func (bp *BucketPicker) Buckets() []WeightedBucket {
+	return bp.buckets
+}
+
+// Next gives the bucket of the next upload, "" if there is none.
+func (bp *BucketPicker) Next() string {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if bp.total == 0 {
+		return ""
+	}
+	if bp.random {
+		n := bp.rnd.Intn(bp.total)
+		for _, b := range bp.buckets {
+			if n < b.Weight {
+				return b.Name
+			}
+			n -= b.Weight
+		}
+	}
+	best := 0
+	for i, b := range bp.buckets {
+		bp.current[i] += b.Weight
+		if bp.current[i] > bp.current[best] {
+			best = i
+		}
+	}
+	bp.current[best] -= bp.total
+	return bp.buckets[best].Name
+}
+
+// String gives the names of the buckets, separated by commas.
+func (bp *BucketPicker) String() string {
+	names := make([]string, len(bp.buckets))
+	for i, b := range bp.buckets {
+		names[i] = b.Name
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,169 @@
+package cloud
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/service"
+)
+
+// Policies of the existing files of Downloader.
+const (
+	ExistingOverwrite = ""       // always download
+	ExistingSkip      = "skip"   // never download
+	ExistingUpdate    = "update" // download only if changed on the server
+)
+
+// DownloadCacheName is the filename of the download cache in the directory.
+const DownloadCacheName = ".alti-downloads.json"
+
+// Validator is the cached validator of a downloaded file.
+type Validator struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Size         int64  `json:"size"`
+}
+
+// DownloadCache records the validators of the downloaded files of a
+// directory, for skipping the unchanged ones by conditional requests.
+type DownloadCache struct {
+	path  string
+	mu    sync.Mutex
+	files map[string]Validator
+}
+
+// LoadDownloadCache loads the download cache of dir, empty if not found.
+// An invalid cache is reset with an error.
+func LoadDownloadCache(dir string) (*DownloadCache, error) {
+	c := DownloadCache{
+		path:  filepath.Join(dir, DownloadCacheName),
+		files: make(map[string]Validator),
+	}
+	b, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return &c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &c.files); err != nil {
+		c.files = make(map[string]Validator)
+		return &c, fmt.Errorf("invalid download cache %q: %v", c.path, err)
+	}
+	return &c, nil
+}
+
+// Get gives the validator of the file p.
+func (c *DownloadCache) Get(p string) (Validator, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.files[filepath.Base(p)]
+	return v, ok
+}
+
+// Put sets the validator of the file p.
+func (c *DownloadCache) Put(p string, v Validator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[filepath.Base(p)] = v
+}
+
+// Save writes the cache into its directory.
+func (c *DownloadCache) Save() error {
+	c.mu.Lock()
+	b, err := json.MarshalIndent(c.files, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, b, 0644)
+}
+
+// localValidator gives the validator of the existing file p for a conditional
+// request. It is the cached one if the size still matches, otherwise the md5
+// of p as an ETag, which is the one of most object storages.
+func localValidator(p string, size int64, c *DownloadCache) (Validator, error) {
+	if c != nil {
+		if v, ok := c.Get(p); ok && v.Size == size && (v.ETag != "" || v.LastModified != "") {
+			return v, nil
+		}
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return Validator{}, err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return Validator{}, err
+	}
+	return Validator{ETag: fmt.Sprintf("%q", hex.EncodeToString(h.Sum(nil))), Size: size}, nil
+}
+
+// getFileIfChanged downloads url into p unless it is not modified since v,
+// i.e. the server replies 304. The file is written to p + ".tmp" first, so that
+// p is kept if the download fails. Return the validator of the downloaded or
+// unchanged file, and whether it is unchanged.
+func getFileIfChanged(ctx context.Context, p, url string, v Validator, pr service.ProgressReporter) (Validator, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return v, false, err
+	}
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+	resp, err := config.HTTPClient(0).Do(req)
+	if err != nil {
+		return v, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return v, true, nil
+	case http.StatusOK:
+	default:
+		return v, false, errors.NetworkError{Code: resp.StatusCode, Message: "bad status"}
+	}
+
+	tmp := p + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return v, false, err
+	}
+	var body io.Reader = resp.Body
+	if pr != nil {
+		pr.Start(p, resp.ContentLength)
+		body = &progressReader{Reader: resp.Body, name: p, pr: pr}
+	}
+	n, err := io.Copy(out, body)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, p)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return v, false, err
+	}
+	ret := Validator{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Size:         n,
+	}
+	return ret, false, nil
+}
@@ -0,0 +1,173 @@
+package cloud
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+)
+
+// DownloadItem represents a remote file to be downloaded into a local path.
+type DownloadItem struct {
+	URL     string
+	Path    string
+	Size    int64  // in bytes
+	SHA1    string // optional, verified after download
+	Skipped bool   // existing or unchanged file that is not downloaded
+	Error   error
+}
+
+// Downloader downloads each item concurrently with retry.
+// If Resume is set, the partial file of an interrupted download is kept in
+// Path + ".part" and resumed by the next run.
+// If Ctx is set, the downloads are stopped once it is done.
+// Existing is the policy of the existing files, see ExistingUpdate. If Cache is
+// set, the validators of the downloaded files are recorded in it.
+type Downloader struct {
+	Items    <-chan DownloadItem
+	Done     <-chan struct{}
+	Ctx      context.Context
+	Result   chan<- DownloadItem
+	Retry    int
+	Verbose  bool
+	Resume   bool
+	Existing string
+	Cache    *DownloadCache           // optional
+	Progress service.ProgressReporter // optional
+}
+
+// Digest downloads each item from Items and send back the
+// result to Result until either Items or Done is closed.
+func (d *Downloader) Digest() {
+	for item := range d.Items {
+		select {
+		case d.Result <- d.download(item):
+		case <-d.Done:
+			return
+		}
+	}
+}
+
+// Run starts n number of goroutines to digest each item.
+// If n is not positive, it will be set to number of CPU cores.
+// Return n.
+func (d *Downloader) Run(n int) int {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	var wg sync.WaitGroup
+	wg.Add(n)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			d.Digest()
+			wg.Done()
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(d.Result)
+	}()
+
+	return n
+}
+
+// download gets the file of item with retry.
+// Partially downloaded file is removed if all trials fail, unless resuming.
+// The downloaded file of a different checksum is removed and retried.
+// The existing file is skipped, or only replaced if changed, by d.Existing.
+func (d *Downloader) download(item DownloadItem) DownloadItem {
+	var v Validator
+	if st, err := os.Stat(item.Path); err == nil && st.Mode().IsRegular() {
+		switch d.Existing {
+		case ExistingSkip:
+			item.Size = st.Size()
+			item.Skipped = true
+			reportDone(d.Progress, item.Path, nil)
+			return item
+		case ExistingUpdate:
+			// download it again if it could not be validated
+			v, _ = localValidator(item.Path, st.Size(), d.Cache)
+		}
+	}
+	conditional := d.Existing == ExistingUpdate || d.Cache != nil
+
+	trial := d.Retry
+	if trial <= 0 {
+		trial = 1
+	}
+	ctx := d.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var err error
+	for i := 0; i < trial; i++ {
+		switch {
+		case conditional:
+			v, item.Skipped, err = getFileIfChanged(ctx, item.Path, item.URL, v, d.Progress)
+		case d.Resume:
+			err = ResumeFile(ctx, item.Path, item.URL, d.Progress)
+		default:
+			err = getFile(ctx, item.Path, item.URL, d.Progress)
+		}
+		if err == nil && item.SHA1 != "" && !item.Skipped {
+			if err = verifySHA1(item.Path, item.SHA1); err != nil {
+				// removed, so download it unconditionally
+				v = Validator{}
+			}
+		}
+		if err == nil {
+			break
+		}
+		// not found or forbidden would not be better by retrying
+		if netErr, ok := err.(errors.NetworkError); ok && netErr.Code < 500 {
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if d.Verbose {
+			logging.Warnf("Retrying (x %d) download of %q\n", i+1, item.URL)
+		}
+		if sleep(ctx, time.Second) != nil {
+			break
+		}
+	}
+	reportDone(d.Progress, item.Path, err)
+	if err != nil {
+		// the existing file is kept by a conditional download
+		if !d.Resume && !conditional {
+			os.Remove(item.Path)
+		}
+		item.Error = err
+		return item
+	}
+	if d.Cache != nil {
+		d.Cache.Put(item.Path, v)
+	}
+
+	if stat, err := os.Stat(item.Path); err == nil {
+		item.Size = stat.Size()
+	}
+	return item
+}
+
+// verifySHA1 checks if the sha1 of file p is sum.
+// The file is removed if not.
+func verifySHA1(p, sum string) error {
+	local, err := file.Sha1sum(p)
+	if err != nil {
+		return err
+	}
+	if err = file.VerifyChecksum(p, local, sum); err != nil {
+		os.Remove(p)
+	}
+	return err
+}
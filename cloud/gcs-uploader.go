@@ -0,0 +1,37 @@
+package cloud
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/service"
+)
+
+// PutGCS puts the local file to Google Cloud Storage via the signed url
+// returned from the api server.
+func PutGCS(localPath, url string) error {
+	return putSigned(context.Background(), service.GCSUploadMethod, localPath, url, nil)
+}
+
+// putSigned puts the local file to the signed url of s3, minio or gcs,
+// reporting the uploaded bytes to pr if it is not nil.
+// method is "s3", "minio" or "gcs".
+func putSigned(ctx context.Context, method, localPath, url string, pr service.ProgressReporter) error {
+	res, err := putFile(ctx, localPath, url, pr)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		switch method {
+		case service.MinioUploadMethod:
+			return errors.ErrMinioError
+		case service.GCSUploadMethod:
+			return errors.ErrGCSError
+		default:
+			return errors.ErrS3Error
+		}
+	}
+	return nil
+}
@@ -1,40 +1,49 @@
 package cloud
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/jackytck/alti-cli/config"
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/service"
 )
 
 // PutS3 is a helper func to put to s3.
 func PutS3(localPath, url string) error {
-	res, err2 := PutFile(localPath, url)
-	if err2 != nil {
-		return err2
-	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		return errors.ErrS3Error
-	}
-	return nil
+	return putSigned(context.Background(), service.S3UploadMethod, localPath, url, nil)
 }
 
 // PutFile puts the local file specified in filepath to the remote url
 // via http PUT.
 func PutFile(filepath string, url string) (*http.Response, error) {
-	f, err := os.Open(filepath)
+	return putFile(context.Background(), filepath, url, nil)
+}
+
+// putFile puts the local file to the remote url via http PUT,
+// reporting the uploaded bytes to pr if it is not nil.
+// The request is aborted if ctx is canceled.
+func putFile(ctx context.Context, filepath string, url string, pr service.ProgressReporter) (*http.Response, error) {
+	f, err := file.OpenFile(filepath)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
-	stats, err := f.Stat()
+	stats, err := file.StatFile(filepath)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("PUT", url, f)
+	var body io.Reader = f
+	if pr != nil {
+		pr.Start(filepath, stats.Size())
+		body = &progressReader{Reader: f, name: filepath, pr: pr}
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -45,8 +54,7 @@ func PutFile(filepath string, url string) (*http.Response, error) {
 	req.Header.Set("Content-Type", t)
 	req.ContentLength = stats.Size()
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+	res, err := config.HTTPClient(0).Do(req)
 	if err != nil {
 		return res, err
 	}
@@ -56,6 +64,12 @@ func PutFile(filepath string, url string) (*http.Response, error) {
 
 // GetFile downloads a file from the given url and stores it in filepath.
 func GetFile(filepath string, url string) error {
+	return getFile(context.Background(), filepath, url, nil)
+}
+
+// getFile downloads a file from the given url and stores it in filepath,
+// reporting the downloaded bytes to pr if it is not nil.
+func getFile(ctx context.Context, filepath string, url string, pr service.ProgressReporter) error {
 	// Create the file
 	out, err := os.Create(filepath)
 	if err != nil {
@@ -64,7 +78,11 @@ func GetFile(filepath string, url string) error {
 	defer out.Close()
 
 	// Get the data
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := config.HTTPClient(0).Do(req)
 	if err != nil {
 		return err
 	}
@@ -76,7 +94,12 @@ func GetFile(filepath string, url string) error {
 	}
 
 	// Writer the body to file
-	_, err = io.Copy(out, resp.Body)
+	var body io.Reader = resp.Body
+	if pr != nil {
+		pr.Start(filepath, resp.ContentLength)
+		body = &progressReader{Reader: resp.Body, name: filepath, pr: pr}
+	}
+	_, err = io.Copy(out, body)
 	if err != nil {
 		return err
 	}
@@ -84,12 +107,108 @@ func GetFile(filepath string, url string) error {
 	return nil
 }
 
+// ResumeFile downloads a file from the given url and stores it in filepath,
+// resuming from the partial file of a previous interrupted download if any.
+// The partial file is kept in filepath + ".part" until the download completes.
+// The downloaded bytes are reported to pr if it is not nil.
+func ResumeFile(ctx context.Context, filepath string, url string, pr service.ProgressReporter) error {
+	part := filepath + ".part"
+	out, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := config.HTTPClient(0).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// already completed in previous run
+		out.Close()
+		return os.Rename(part, filepath)
+	case resp.StatusCode == http.StatusOK:
+		// range is not supported, start over
+		if err = out.Truncate(0); err != nil {
+			return err
+		}
+		if offset, err = out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	default:
+		return errors.NetworkError{Code: resp.StatusCode, Message: "bad status"}
+	}
+
+	var body io.Reader = resp.Body
+	if pr != nil {
+		size := int64(-1)
+		if resp.ContentLength >= 0 {
+			size = offset + resp.ContentLength
+		}
+		pr.Start(filepath, size)
+		pr.Add(filepath, offset)
+		body = &progressReader{Reader: resp.Body, name: filepath, pr: pr}
+	}
+	if _, err = io.Copy(out, body); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(part, filepath)
+}
+
 // GetStatus get the http status of an url.
 func GetStatus(url string) (int, error) {
-	resp, err := http.Get(url)
+	resp, err := config.HTTPClient(0).Get(url)
 	if err != nil {
 		return -1, err
 	}
 	defer resp.Body.Close()
 	return resp.StatusCode, nil
 }
+
+// reportDone reports the end of transfer of file name to pr if it is not nil.
+func reportDone(pr service.ProgressReporter, name string, err error) {
+	if pr != nil {
+		pr.Done(name, err)
+	}
+}
+
+// sleep pauses for d, returns the error of ctx if it is canceled before.
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// progressReader reports the number of bytes read of file name to pr.
+type progressReader struct {
+	io.Reader
+	name string
+	pr   service.ProgressReporter
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.pr.Add(r.name, int64(n))
+	return n, err
+}
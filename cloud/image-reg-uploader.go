@@ -1,69 +1,110 @@
 package cloud
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"net/http"
 	"runtime"
 	"sync"
 	"time"
 
 	"github.com/jackytck/alti-cli/db"
 	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/schedule"
 	"github.com/jackytck/alti-cli/service"
 	"github.com/jackytck/alti-cli/types"
 )
 
 // ImageRegUploader coordinates image registration and uploading concurrently.
 type ImageRegUploader struct {
-	Method  string
-	Bucket  string
-	BaseURL string
-	Images  <-chan db.Image
-	Done    <-chan struct{}
-	Result  chan<- db.Image
-	Verbose bool
-	ossUp   *OSSUploader
+	Method   string
+	Bucket   string
+	Buckets  *BucketPicker // optional, spreads the uploads across buckets instead of Bucket
+	BaseURL  string
+	Images   <-chan db.Image
+	Ctx      context.Context
+	Result   chan<- db.Image
+	Verbose  bool
+	Progress service.ProgressReporter // optional
+	// Throttle adapts the number of concurrent uploads, nil for a fixed one.
+	Throttle *Throttle
+	// Schedule pauses the uploads outside of the window, nil for any time.
+	Schedule *schedule.Window
+	ossUps   map[string]*OSSUploader // by bucket
 }
 
-// WithOSSUploader setups an OSS uploader for current pid and bucket.
+// WithOSSUploader setups an OSS uploader for current pid and each bucket.
 func (iru *ImageRegUploader) WithOSSUploader(pid string) error {
-	up, err := NewOSSUploader(pid, gql.RefreshSTS(pid, iru.Bucket))
-	if err != nil {
-		return err
+	buckets := []string{iru.Bucket}
+	if iru.Buckets != nil {
+		buckets = nil
+		for _, b := range iru.Buckets.Buckets() {
+			buckets = append(buckets, b.Name)
+		}
+	}
+	iru.ossUps = make(map[string]*OSSUploader)
+	for _, b := range buckets {
+		up, err := NewOSSUploader(pid, gql.RefreshSTS(pid, b))
+		if err != nil {
+			return err
+		}
+		iru.ossUps[b] = up
 	}
-	iru.ossUp = up
 	return nil
 }
 
+// bucket gives the bucket of the next upload.
+func (iru *ImageRegUploader) bucket() string {
+	if iru.Buckets != nil {
+		return iru.Buckets.Next()
+	}
+	return iru.Bucket
+}
+
 // Digest registers and uploads each image from Images and send back the
-// result to Result until either Images or Done is closed.
+// result to Result until Images is closed. Once Ctx is canceled, the
+// remaining images are sent with the error of Ctx without being uploaded.
 func (iru *ImageRegUploader) Digest() {
+	iru.digest(0)
+}
+
+// digest is Digest of the given worker, for recording its throughput.
+func (iru *ImageRegUploader) digest(worker int) {
 	for img := range iru.Images {
-		select {
-		case iru.Result <- iru.regUpload(img):
-		case <-iru.Done:
-			return
+		if iru.Schedule != nil && !img.IsUploaded() {
+			iru.Schedule.Wait(iru.Ctx)
 		}
+		if err := iru.Ctx.Err(); err != nil {
+			img.Error = err.Error()
+			iru.Result <- img
+			continue
+		}
+		iru.Result <- iru.regUpload(worker, img)
 	}
 }
 
 // Run starts n number of goroutines to digest each image.
 // If n is not positive, it will be set to number of CPU cores.
+// If Throttle is set, Throttle.Max goroutines are started instead, of which
+// at most the limit of Throttle are uploading at a time.
 // Return n.
 func (iru *ImageRegUploader) Run(n int) int {
 	if n <= 0 {
 		n = runtime.NumCPU()
 	}
+	if iru.Throttle != nil {
+		n = iru.Throttle.Max
+	}
 	var wg sync.WaitGroup
 	wg.Add(n)
 
 	for i := 0; i < n; i++ {
-		go func() {
-			iru.Digest()
+		go func(w int) {
+			iru.digest(w)
 			wg.Done()
-		}()
+		}(i)
 	}
 
 	go func() {
@@ -74,7 +115,58 @@ func (iru *ImageRegUploader) Run(n int) int {
 	return n
 }
 
-func (iru *ImageRegUploader) regUpload(img db.Image) db.Image {
+func (iru *ImageRegUploader) regUpload(worker int, img db.Image) db.Image {
+	// already uploaded in a previous run
+	if img.IsUploaded() {
+		if iru.Verbose {
+			logging.Infof("Skipped uploaded %q\n", img.Filename)
+		}
+		if iru.Progress != nil {
+			// count it as processed, so that the progress reaches the total
+			iru.Progress.Start(img.LocalPath, localSize(img.LocalPath))
+			iru.Progress.Done(img.LocalPath, nil)
+		}
+		return img
+	}
+	if iru.Progress == nil && iru.Throttle == nil {
+		return iru.upload(img)
+	}
+
+	size := localSize(img.LocalPath)
+	if iru.Throttle != nil {
+		if err := iru.Throttle.Acquire(iru.Ctx); err != nil {
+			img.Error = err.Error()
+			return img
+		}
+	}
+	if iru.Progress != nil {
+		iru.Progress.Start(img.LocalPath, size)
+	}
+	start := time.Now()
+	ret := iru.upload(img)
+	var err error
+	if ret.Error != "" {
+		err = errors.UploadError(ret.Error)
+	}
+	if iru.Throttle != nil {
+		iru.Throttle.Release(worker, size, time.Since(start), err)
+	}
+	if iru.Progress != nil {
+		iru.Progress.Done(img.LocalPath, err)
+	}
+	return ret
+}
+
+// localSize gives the size of the local file p, 0 if unknown.
+func localSize(p string) int64 {
+	if stat, err := file.StatFile(p); err == nil {
+		return stat.Size()
+	}
+	return 0
+}
+
+// upload registers and uploads img by the chosen method.
+func (iru *ImageRegUploader) upload(img db.Image) db.Image {
 	var ret db.Image
 	switch iru.Method {
 	case service.DirectUploadMethod:
@@ -82,6 +174,8 @@ func (iru *ImageRegUploader) regUpload(img db.Image) db.Image {
 	case service.S3UploadMethod:
 		fallthrough
 	case service.MinioUploadMethod:
+		fallthrough
+	case service.GCSUploadMethod:
 		return iru.smUpload(iru.Method, img, 5)
 	case service.OSSUploadMethod:
 		return iru.ossUpload(img)
@@ -91,29 +185,34 @@ func (iru *ImageRegUploader) regUpload(img db.Image) db.Image {
 
 func (iru *ImageRegUploader) directUpload(img db.Image) db.Image {
 	u := fmt.Sprintf("%s/%s", iru.BaseURL, img.URL)
-	gqlImg, err := gql.RegisterImageURL(img.PID, u, img.Filename, img.Hash)
+	gqlImg, err := gql.RegisterImageURL(iru.Ctx, img.PID, u, img.Filename, img.Hash, img.Meta)
 	if err != nil {
 		img.Error = err.Error()
 		return img
 	}
 	img.IID = gqlImg.ID
 	img.State = gqlImg.State
+	// api server pulls the image from the local server by itself
+	img.Stage = db.StageUploaded
 	return img
 }
 
-// smUpload uploads to either s3 or minio.
-// kind is "s3" or "minio"
+// smUpload uploads to either s3, minio or gcs via signed url.
+// kind is "s3", "minio" or "gcs"
 func (iru *ImageRegUploader) smUpload(kind string, img db.Image, retry int) db.Image {
 	// a. register image
 	var gqlImg *types.Image
 	var url string
 	var err error
 
+	bucket := iru.bucket()
 	switch kind {
 	case service.S3UploadMethod:
-		gqlImg, url, err = gql.RegisterImageS3(img.PID, iru.Bucket, img.Filename, img.Filetype, img.Hash)
+		gqlImg, url, err = gql.RegisterImageS3(iru.Ctx, img.PID, bucket, img.Filename, img.Filetype, img.Hash, img.Meta)
 	case service.MinioUploadMethod:
-		gqlImg, url, err = gql.RegisterImageMinio(img.PID, iru.Bucket, img.Filename, img.Filetype, img.Hash)
+		gqlImg, url, err = gql.RegisterImageMinio(iru.Ctx, img.PID, bucket, img.Filename, img.Filetype, img.Hash, img.Meta)
+	case service.GCSUploadMethod:
+		gqlImg, url, err = gql.RegisterImageGCS(iru.Ctx, img.PID, bucket, img.Filename, img.Filetype, img.Hash, img.Meta)
 	}
 	if err != nil {
 		img.Error = err.Error()
@@ -121,46 +220,37 @@ func (iru *ImageRegUploader) smUpload(kind string, img db.Image, retry int) db.I
 	}
 	img.IID = gqlImg.ID
 	img.State = gqlImg.State
+	img.Stage = db.StageRegistered
 
 	// b. signal the start of upload
 	trial := retry
 	for i := 0; i < trial; i++ {
-		state, e := gql.StartImageUpload(img.IID)
+		state, e := gql.StartImageUpload(iru.Ctx, img.IID)
 		err = e
 		if e == nil {
 			img.State = state
 			break
 		}
 		if iru.Verbose {
-			log.Printf("Retrying (x %d) mutating state for %q\n", i+1, img.Filename)
+			logging.Warnf("Retrying (x %d) mutating state for %q\n", i+1, img.Filename)
+		}
+		if e := sleep(iru.Ctx, time.Second); e != nil {
+			err = e
+			break
 		}
-		time.Sleep(time.Second)
 	}
 	if err != nil {
 		img.Error = err.Error()
 		return img
 	}
 
-	// c. upload to s3/minio with retry
-	// helper func to put to s3/minio
+	// c. upload to s3/minio/gcs with retry
+	// helper func to put to s3/minio/gcs
 	upload := func() error {
 		if iru.Verbose {
-			log.Printf("Uploading %q\n", img.Filename)
-		}
-		res, err2 := PutFile(img.LocalPath, url)
-		if err2 != nil {
-			return err2
-		}
-		defer res.Body.Close()
-		if res.StatusCode != http.StatusOK {
-			switch kind {
-			case service.S3UploadMethod:
-				return errors.ErrS3Error
-			case service.MinioUploadMethod:
-				return errors.ErrMinioError
-			}
+			logging.Infof("Uploading %q\n", img.Filename)
 		}
-		return nil
+		return putSigned(iru.Ctx, kind, img.LocalPath, url, iru.Progress)
 	}
 
 	for i := 0; i < trial; i++ {
@@ -169,45 +259,56 @@ func (iru *ImageRegUploader) smUpload(kind string, img db.Image, retry int) db.I
 			break
 		}
 		if iru.Verbose {
-			log.Printf("Retrying (x %d) upload to %s for %q\n", i+1, kind, img.Filename)
+			logging.Warnf("Retrying (x %d) upload to %s for %q\n", i+1, kind, img.Filename)
+		}
+		if e := sleep(iru.Ctx, time.Second); e != nil {
+			err = e
+			break
 		}
-		time.Sleep(time.Second)
 	}
 	if err != nil {
 		img.Error = err.Error()
+		return img
 	}
+	img.Stage = db.StageUploaded
 
 	return img
 }
 
 func (iru *ImageRegUploader) ossUpload(img db.Image) db.Image {
-	if iru.ossUp == nil {
+	bucket := iru.bucket()
+	up := iru.ossUps[bucket]
+	if up == nil {
 		img.Error = errors.ErrOSSUploaderNotFound.Error()
 		return img
 	}
 
 	// a. register oss image
-	gqlImg, err := gql.RegisterImageOSS(img.PID, iru.Bucket, img.Filename, img.Filetype, img.Hash)
+	gqlImg, err := gql.RegisterImageOSS(iru.Ctx, img.PID, bucket, img.Filename, img.Filetype, img.Hash, img.Meta)
 	if err != nil {
 		img.Error = err.Error()
 		return img
 	}
 	img.IID = gqlImg.ID
 	img.State = gqlImg.State
+	img.Stage = db.StageRegistered
 
 	// b. signal the start of upload
 	trial := 5
 	for i := 0; i < trial; i++ {
-		state, e := gql.StartImageUpload(img.IID)
+		state, e := gql.StartImageUpload(iru.Ctx, img.IID)
 		err = e
 		if e == nil {
 			img.State = state
 			break
 		}
 		if iru.Verbose {
-			log.Printf("Retrying (x %d) mutating state for %q\n", i+1, img.Filename)
+			logging.Warnf("Retrying (x %d) mutating state for %q\n", i+1, img.Filename)
+		}
+		if e := sleep(iru.Ctx, time.Second); e != nil {
+			err = e
+			break
 		}
-		time.Sleep(time.Second)
 	}
 	if err != nil {
 		img.Error = err.Error()
@@ -217,23 +318,28 @@ func (iru *ImageRegUploader) ossUpload(img db.Image) db.Image {
 	// c. upload to oss with retry
 	for i := 0; i < trial; i++ {
 		if iru.Verbose {
-			log.Printf("Uploading %q\n", img.Filename)
+			logging.Infof("Uploading %q\n", img.Filename)
 		}
-		err = iru.ossUp.PutFile(img.LocalPath, gqlImg.Filename)
+		err = up.PutFile(img.LocalPath, gqlImg.Filename)
 		if err == nil {
 			break
 		}
 		if iru.Verbose {
-			log.Printf("Retrying (x %d) upload to OSS for %q\n", i+1, img.Filename)
+			logging.Warnf("Retrying (x %d) upload to OSS for %q\n", i+1, img.Filename)
+		}
+		if e := sleep(iru.Ctx, time.Second); e != nil {
+			err = e
+			break
 		}
-		time.Sleep(time.Second)
 	}
 	if err != nil {
 		img.Error = err.Error()
+	} else {
+		img.Stage = db.StageUploaded
 	}
 
 	// d. signal the end of upload
-	state, err := gql.DoneImageUpload(img.IID)
+	state, err := gql.DoneImageUpload(iru.Ctx, img.IID)
 	if err != nil {
 		img.Error = err.Error()
 		return img
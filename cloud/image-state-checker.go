@@ -1,6 +1,8 @@
 package cloud
 
 import (
+	"context"
+	"fmt"
 	"runtime"
 	"strings"
 	"sync"
@@ -8,26 +10,52 @@ import (
 
 	"github.com/jackytck/alti-cli/db"
 	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/types"
 )
 
+// Wait strategies of polling the image states.
+const (
+	WaitFixed   = "fixed"   // poll every Interval
+	WaitBackoff = "backoff" // poll from every Interval, doubled after each poll up to MaxInterval
+	WaitNone    = "none"    // do not check, leave the images unverified
+)
+
+// WaitStrategies are all the wait strategies of ImageStateChecker.
+var WaitStrategies = []string{WaitFixed, WaitBackoff, WaitNone}
+
+// DefaultMaxInterval is the default max poll interval of WaitBackoff.
+const DefaultMaxInterval = 30 * time.Second
+
 // ImageStateChecker check the image states of all images within timeout.
+// The states are pushed by the subscription if the api server supports it,
+// otherwise polled by Strategy, WaitFixed if empty, every Interval,
+// 1 second if not positive. If Verify is set, the checksum of each ready
+// image computed by the server is compared with the local one.
 type ImageStateChecker struct {
-	Images  <-chan db.Image
-	Done    <-chan struct{}
-	Result  chan<- db.Image
-	Timeout time.Duration
+	Images      <-chan db.Image
+	Ctx         context.Context
+	Result      chan<- db.Image
+	Timeout     time.Duration
+	Strategy    string
+	Interval    time.Duration
+	MaxInterval time.Duration // of WaitBackoff, DefaultMaxInterval if not positive
+	Verify      bool
+	watcher     *imageStateWatcher
 }
 
 // Digest checks state of each image from Images and send back the
-// result to Result until either Images or Done is closed.
+// result to Result until Images is closed. Once Ctx is canceled, the
+// remaining images are sent with the error of Ctx without being checked.
 func (isc *ImageStateChecker) Digest() {
 	for img := range isc.Images {
-		select {
-		case isc.Result <- isc.checkState(img):
-		case <-isc.Done:
-			return
+		if err := isc.Ctx.Err(); err != nil {
+			img.Error = err.Error()
+			isc.Result <- img
+			continue
 		}
+		isc.Result <- isc.checkState(img)
 	}
 }
 
@@ -38,6 +66,9 @@ func (isc *ImageStateChecker) Run(n int) int {
 	if n <= 0 {
 		n = runtime.NumCPU()
 	}
+	if isc.Strategy != WaitNone && gql.HasSubscription(gql.ImageStatesField) {
+		isc.watcher = newImageStateWatcher(isc.Ctx)
+	}
 	var wg sync.WaitGroup
 	wg.Add(n)
 
@@ -50,6 +81,9 @@ func (isc *ImageStateChecker) Run(n int) int {
 
 	go func() {
 		wg.Wait()
+		if isc.watcher != nil {
+			isc.watcher.close()
+		}
 		close(isc.Result)
 	}()
 
@@ -57,47 +91,138 @@ func (isc *ImageStateChecker) Run(n int) int {
 }
 
 // checkState checks the db image state via api, until state is changed to
-// 'Ready' or 'Invalid', or timeout in this client.
+// 'Ready' or 'Invalid', or timeout in this client, or Ctx is canceled.
 func (isc *ImageStateChecker) checkState(img db.Image) db.Image {
 	// may already have error from ImageRegUploader
 	if img.Error != "" {
 		return img
 	}
-	imgCh := make(chan db.Image)
+	// may already be verified in a previous run
+	if img.Stage == db.StageVerified || isc.Strategy == WaitNone {
+		return img
+	}
+	ctx, cancel := context.WithTimeout(isc.Ctx, isc.Timeout)
+	defer cancel()
+	imgCh := make(chan db.Image, 1)
 
 	go func() {
 		defer close(imgCh)
+		var states <-chan types.Image
+		if isc.watcher != nil {
+			if ch, ok := isc.watcher.wait(img.PID, img.IID); ok {
+				states = ch
+				defer isc.watcher.done(img.IID)
+			}
+		}
 		i := img
-		for {
-			qImg, err := gql.ProjectImage(img.PID, img.IID)
+		for n := 0; ; n++ {
+			// the state may be changed before subscribing
+			qImg, err := gql.ProjectImage(ctx, img.PID, img.IID)
 			if err != nil {
 				i.Error = err.Error()
 				imgCh <- i
 				return
 			}
-			i.State = qImg.State
-			if qImg.State == "Ready" {
-				imgCh <- i
+			if settle(&i, qImg) {
+				imgCh <- isc.verify(ctx, i)
 				return
 			}
-			if qImg.State == "Invalid" {
-				i.Error = strings.Join(qImg.Error, ";")
-				if i.Error == "" {
-					i.Error = errors.ErrImgInvalid.Error()
+			// wait for the pushed states until the subscription ends
+			for states != nil {
+				select {
+				case s, ok := <-states:
+					if !ok {
+						states = nil
+						break
+					}
+					if settle(&i, &s) {
+						imgCh <- isc.verify(ctx, i)
+						return
+					}
+				case <-ctx.Done():
+					return
 				}
-				imgCh <- i
+			}
+			if sleep(ctx, isc.interval(n)) != nil {
 				return
 			}
-			time.Sleep(time.Second)
 		}
 	}()
 
 	ret := img
 	select {
-	case <-time.After(isc.Timeout):
+	case <-ctx.Done():
 		ret.Error = errors.ErrClientTimeout.Error()
+		if err := isc.Ctx.Err(); err != nil {
+			ret.Error = err.Error()
+		}
 	case ret = <-imgCh:
 	}
 
 	return ret
 }
+
+// settle updates the state of img by the queried or pushed image q.
+// Return true if it is settled, i.e. 'Ready' or 'Invalid'.
+func settle(img *db.Image, q *types.Image) bool {
+	img.State = q.State
+	switch q.State {
+	case "Ready":
+		img.Stage = db.StageVerified
+		return true
+	case "Invalid":
+		img.Error = strings.Join(q.Error, ";")
+		if img.Error == "" {
+			img.Error = errors.ErrImgInvalid.Error()
+		}
+		return true
+	}
+	return false
+}
+
+// verify compares the local checksum of the ready img with the one
+// computed by the server if Verify is set. A mismatch is flagged as the
+// error of img, which is left unverified.
+func (isc *ImageStateChecker) verify(ctx context.Context, img db.Image) db.Image {
+	// url imports are not digested locally
+	if !isc.Verify || img.Stage != db.StageVerified || img.Hash == "" {
+		return img
+	}
+	remote, err := gql.ImageChecksum(ctx, img.PID, img.IID)
+	if err != nil {
+		img.Stage = db.StageUploaded
+		img.Error = err.Error()
+		return img
+	}
+	// not computed by the server
+	if remote == "" {
+		return img
+	}
+	if err = file.VerifyChecksum(img.LocalPath, img.Hash, remote); err != nil {
+		img.Stage = db.StageUploaded
+		img.Error = fmt.Sprintf("%v: local %s, server %s", err, img.Hash, remote)
+	}
+	return img
+}
+
+// interval gives the interval after the n-th poll.
+func (isc *ImageStateChecker) interval(n int) time.Duration {
+	d := isc.Interval
+	if d <= 0 {
+		d = time.Second
+	}
+	if isc.Strategy != WaitBackoff {
+		return d
+	}
+	max := isc.MaxInterval
+	if max <= 0 {
+		max = DefaultMaxInterval
+	}
+	for i := 0; i < n && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
@@ -0,0 +1,101 @@
+package cloud
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/types"
+)
+
+// imageStateWatcher dispatches the image states pushed by the subscription
+// of each project to the images waiting for them.
+type imageStateWatcher struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	projs   map[string]bool             // pid -> subscribed, false if failed or ended
+	waiters map[string]chan types.Image // iid -> latest state
+	pids    map[string]string           // iid -> pid
+	// subscribe subscribes the image states of a project
+	subscribe func(ctx context.Context, pid string) (<-chan types.Image, error)
+}
+
+// newImageStateWatcher creates a watcher of subscriptions closed once ctx
+// is canceled or close is called.
+func newImageStateWatcher(ctx context.Context) *imageStateWatcher {
+	ctx, cancel := context.WithCancel(ctx)
+	return &imageStateWatcher{
+		ctx:       ctx,
+		cancel:    cancel,
+		projs:     make(map[string]bool),
+		waiters:   make(map[string]chan types.Image),
+		pids:      make(map[string]string),
+		subscribe: gql.ImageStates,
+	}
+}
+
+// wait registers the image iid of project pid for its pushed states, which
+// are sent on the returned channel until the subscription ends, then it is
+// closed. Return false if the states of pid could not be subscribed.
+func (w *imageStateWatcher) wait(pid, iid string) (<-chan types.Image, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ok, tried := w.projs[pid]
+	if !tried {
+		states, err := w.subscribe(w.ctx, pid)
+		ok = err == nil
+		w.projs[pid] = ok
+		if ok {
+			go w.dispatch(pid, states)
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	ch := make(chan types.Image, 1)
+	w.waiters[iid] = ch
+	w.pids[iid] = pid
+	return ch, true
+}
+
+// done unregisters the image iid.
+func (w *imageStateWatcher) done(iid string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.waiters, iid)
+	delete(w.pids, iid)
+}
+
+// dispatch sends each state of states to its waiting image. Once the
+// subscription ends, the waiting images fall back to polling.
+func (w *imageStateWatcher) dispatch(pid string, states <-chan types.Image) {
+	for img := range states {
+		w.mu.Lock()
+		if ch, ok := w.waiters[img.ID]; ok {
+			// keep the latest state only
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- img
+		}
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.projs[pid] = false
+	for iid, p := range w.pids {
+		if p == pid {
+			close(w.waiters[iid])
+			delete(w.waiters, iid)
+			delete(w.pids, iid)
+		}
+	}
+}
+
+// close ends all the subscriptions.
+func (w *imageStateWatcher) close() {
+	w.cancel()
+}
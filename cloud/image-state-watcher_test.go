@@ -0,0 +1,86 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+// recvState receives from ch, failing t if nothing is received in time.
+func recvState(t *testing.T, ch <-chan types.Image) (types.Image, bool) {
+	t.Helper()
+	select {
+	case img, ok := <-ch:
+		return img, ok
+	case <-time.After(5 * time.Second):
+		t.Fatal("no state is received")
+		return types.Image{}, false
+	}
+}
+
+func TestImageStateWatcherFailed(t *testing.T) {
+	w := newImageStateWatcher(context.Background())
+	defer w.close()
+	var n int
+	w.subscribe = func(ctx context.Context, pid string) (<-chan types.Image, error) {
+		n++
+		return nil, errors.New("subscription unsupported")
+	}
+
+	for _, iid := range []string{"a", "b"} {
+		if _, ok := w.wait("p", iid); ok {
+			t.Errorf("wait(%q) = true, want false for polling", iid)
+		}
+	}
+	if n != 1 {
+		t.Errorf("subscribed %d times, want once", n)
+	}
+}
+
+func TestImageStateWatcherDispatch(t *testing.T) {
+	w := newImageStateWatcher(context.Background())
+	defer w.close()
+	states := make(chan types.Image)
+	w.subscribe = func(ctx context.Context, pid string) (<-chan types.Image, error) {
+		return states, nil
+	}
+
+	a, ok := w.wait("p", "a")
+	if !ok {
+		t.Fatal("wait(a) = false, want true")
+	}
+	b, ok := w.wait("p", "b")
+	if !ok {
+		t.Fatal("wait(b) = false, want true")
+	}
+	c, _ := w.wait("p", "c")
+	w.done("c")
+
+	states <- types.Image{ID: "a", State: "Pending"}
+	states <- types.Image{ID: "a", State: "Ready"}
+	states <- types.Image{ID: "c", State: "Ready"}
+	// the state of c is dispatched once a is dispatched
+	states <- types.Image{ID: "x", State: "Ready"}
+
+	// only the latest state is kept
+	if img, _ := recvState(t, a); img.State != "Ready" {
+		t.Errorf("state of a = %q, want %q", img.State, "Ready")
+	}
+	select {
+	case img := <-c:
+		t.Errorf("done image c receives %v", img)
+	default:
+	}
+
+	// the waiting images fall back to polling once the subscription ends
+	close(states)
+	if _, ok := recvState(t, b); ok {
+		t.Error("channel of b is not closed after the subscription ends")
+	}
+	if _, ok := w.wait("p", "d"); ok {
+		t.Error("wait(d) after the subscription ends = true, want false for polling")
+	}
+}
@@ -1,13 +1,16 @@
 package cloud
 
 import (
-	"log"
+	"context"
 	"time"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/schedule"
 	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
 )
 
 // MetaFileRegUploader coordinates single meta file registration and uploading.
@@ -19,16 +22,21 @@ type MetaFileRegUploader struct {
 	Filename  string
 	DirectURL string
 	Bucket    string
+	PartSize  int64 // in bytes, s3 or minio file larger than it is uploaded in parts; 0 for only files larger than 5GB in 100MB parts
+	Parallel  int   // number of parts uploaded concurrently, default is number of cores
+	Resume    bool  // resume the previous interrupted multipart upload
 	Timeout   int
 	Verbose   bool
+	Progress  service.ProgressReporter // optional
+	Schedule  *schedule.Window         // optional, waits for the window before uploading
 	checksum  string
 }
 
-// Run starts the registration and uploading process.
-// Return the state of imported meta file.
-func (mru *MetaFileRegUploader) Run() (string, error) {
+// Run starts the registration and uploading process, which is aborted
+// if ctx is canceled. Return the state of imported meta file.
+func (mru *MetaFileRegUploader) Run(ctx context.Context) (string, error) {
 	// check existence
-	exists, err := mru.isUploaded()
+	exists, err := mru.isUploaded(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -37,59 +45,76 @@ func (mru *MetaFileRegUploader) Run() (string, error) {
 	}
 
 	// upload
+	if mru.Schedule != nil {
+		if err := mru.Schedule.Wait(ctx); err != nil {
+			return "", err
+		}
+	}
 	switch mru.Method {
 	case service.DirectUploadMethod:
-		return mru.directUpload()
+		return mru.directUpload(ctx)
 	case service.S3UploadMethod:
-		return mru.s3Upload()
+		return mru.s3Upload(ctx)
 	case service.MinioUploadMethod:
-		return mru.minioUpload()
+		return mru.minioUpload(ctx)
+	case service.GCSUploadMethod:
+		return mru.gcsUpload(ctx)
+	case service.OSSUploadMethod:
+		return mru.ossUpload(ctx)
 	}
 	return "", errors.ErrUploadMethodInvalid
 }
 
+// Checksum gives the SHA1 sum of the meta file, empty if not yet computed.
+func (mru *MetaFileRegUploader) Checksum() string {
+	return mru.checksum
+}
+
 // Done cleanups this uploader if user wants to terminate early.
 func (mru *MetaFileRegUploader) Done() error {
 	return nil
 }
 
-func (mru *MetaFileRegUploader) isUploaded() (bool, error) {
+func (mru *MetaFileRegUploader) isUploaded(ctx context.Context) (bool, error) {
 	hash, err := mru.computeChecksum()
 	if err != nil {
 		return false, err
 	}
 	mru.checksum = hash
-	return gql.HasMetaFile(mru.PID, hash)
+	return gql.HasMetaFile(ctx, mru.PID, hash)
 }
 
 // directUpload registers the meta file via direct upload method and query its state
 // change until timeout. Return the state of meta file.
-func (mru *MetaFileRegUploader) directUpload() (string, error) {
+func (mru *MetaFileRegUploader) directUpload(ctx context.Context) (string, error) {
 	// register meta file
-	mf, err := gql.RegisterMetaURL(mru.PID, mru.DirectURL, mru.Filename, mru.checksum)
+	mf, err := gql.RegisterMetaURL(ctx, mru.PID, mru.DirectURL, mru.Filename, mru.checksum)
 	if err != nil {
 		return "", err
 	}
 	mru.MID = mf.ID
 
-	log.Printf("Registered meta with state: %q\n", mf.State)
+	logging.Infof("Registered meta with state: %q\n", mf.State)
 
-	return mru.checkState()
+	return mru.checkState(ctx)
 }
 
 // s3Upload uploads to s3.
-func (mru *MetaFileRegUploader) s3Upload() (string, error) {
+func (mru *MetaFileRegUploader) s3Upload(ctx context.Context) (string, error) {
 	if mru.Verbose {
-		log.Printf("Uploading %q\n", mru.Filename)
+		logging.Infof("Uploading %q\n", mru.Filename)
 	}
 	size, err := mru.filesize()
 	if err != nil {
 		return "", err
 	}
 	if mru.Verbose {
-		log.Printf("Size: %.2f MB\n", size)
+		logging.Infof("Size: %.2f MB\n", size)
+	}
+	if b, _ := file.Filesize(mru.MetaPath); useMultipart(service.S3UploadMethod, b, mru.PartSize) {
+		return mru.multipartUpload(ctx, service.S3UploadMethod)
 	}
-	meta, url, err := gql.RegisterMetaFileS3(mru.PID, mru.Bucket, mru.Filename)
+	meta, url, err := gql.RegisterMetaFileS3(ctx, mru.PID, mru.Bucket, mru.Filename)
 	if err != nil {
 		return "", err
 	}
@@ -98,35 +123,42 @@ func (mru *MetaFileRegUploader) s3Upload() (string, error) {
 	// b. upload to s3 with retry
 	trial := 5
 	for i := 0; i < trial; i++ {
-		err = PutS3(mru.MetaPath, url)
+		err = putSigned(ctx, service.S3UploadMethod, mru.MetaPath, url, mru.Progress)
 		if err == nil {
 			break
 		}
 		if mru.Verbose {
-			log.Printf("Retrying (x %d) upload to S3 for %q\n", i+1, mru.Filename)
+			logging.Warnf("Retrying (x %d) upload to S3 for %q\n", i+1, mru.Filename)
+		}
+		if e := sleep(ctx, time.Second); e != nil {
+			err = e
+			break
 		}
-		time.Sleep(time.Second)
 	}
+	reportDone(mru.Progress, mru.MetaPath, err)
 	if err != nil {
 		return "", err
 	}
 
-	return mru.checkState()
+	return mru.checkState(ctx)
 }
 
 // minioUpload uploads to minio in AltiOne.
-func (mru *MetaFileRegUploader) minioUpload() (string, error) {
+func (mru *MetaFileRegUploader) minioUpload(ctx context.Context) (string, error) {
 	if mru.Verbose {
-		log.Printf("Uploading %q\n", mru.Filename)
+		logging.Infof("Uploading %q\n", mru.Filename)
 	}
 	size, err := mru.filesize()
 	if err != nil {
 		return "", err
 	}
 	if mru.Verbose {
-		log.Printf("Size: %.2f MB\n", size)
+		logging.Infof("Size: %.2f MB\n", size)
+	}
+	if b, _ := file.Filesize(mru.MetaPath); useMultipart(service.MinioUploadMethod, b, mru.PartSize) {
+		return mru.multipartUpload(ctx, service.MinioUploadMethod)
 	}
-	meta, url, err := gql.RegisterMetaFileMinio(mru.PID, mru.Bucket, mru.Filename)
+	meta, url, err := gql.RegisterMetaFileMinio(ctx, mru.PID, mru.Bucket, mru.Filename)
 	if err != nil {
 		return "", err
 	}
@@ -135,37 +167,175 @@ func (mru *MetaFileRegUploader) minioUpload() (string, error) {
 	// b. upload to minio with retry
 	trial := 5
 	for i := 0; i < trial; i++ {
-		err = PutS3(mru.MetaPath, url)
+		err = putSigned(ctx, service.MinioUploadMethod, mru.MetaPath, url, mru.Progress)
 		if err == nil {
 			break
 		}
 		if mru.Verbose {
-			log.Printf("Retrying (x %d) upload to Minio for %q\n", i+1, mru.Filename)
+			logging.Warnf("Retrying (x %d) upload to Minio for %q\n", i+1, mru.Filename)
 		}
-		time.Sleep(time.Second)
+		if e := sleep(ctx, time.Second); e != nil {
+			err = e
+			break
+		}
+	}
+	reportDone(mru.Progress, mru.MetaPath, err)
+	if err != nil {
+		return "", err
+	}
+
+	return mru.checkState(ctx)
+}
+
+// multipartUpload uploads to s3 or minio in a multipart upload session.
+// The session is kept by the cloud for resuming if any part fails.
+func (mru *MetaFileRegUploader) multipartUpload(ctx context.Context, method string) (string, error) {
+	mu := multipartUpload{
+		PID:      mru.PID,
+		Method:   method,
+		Path:     mru.MetaPath,
+		PartSize: mru.PartSize,
+		Parallel: mru.Parallel,
+		Resume:   mru.Resume,
+		Verbose:  mru.Verbose,
+		Progress: mru.Progress,
+		Schedule: mru.Schedule,
+		Register: func(ctx context.Context) (string, error) {
+			var meta *types.MetaFile
+			var err error
+			if method == service.MinioUploadMethod {
+				meta, _, err = gql.RegisterMetaFileMinio(ctx, mru.PID, mru.Bucket, mru.Filename)
+			} else {
+				meta, _, err = gql.RegisterMetaFileS3(ctx, mru.PID, mru.Bucket, mru.Filename)
+			}
+			if err != nil {
+				return "", err
+			}
+			return meta.ID, nil
+		},
+	}
+	mid, err := mu.Run(ctx)
+	if mid != "" {
+		mru.MID = mid
 	}
+	reportDone(mru.Progress, mru.MetaPath, err)
 	if err != nil {
+		if mid != "" {
+			logging.Infoln("Uploaded parts are kept by the cloud. Resume the upload by adding '--resume'")
+		}
 		return "", err
 	}
 
-	return mru.checkState()
+	return mru.checkState(ctx)
+}
+
+// gcsUpload uploads to gcs.
+func (mru *MetaFileRegUploader) gcsUpload(ctx context.Context) (string, error) {
+	if mru.Verbose {
+		logging.Infof("Uploading %q\n", mru.Filename)
+	}
+	size, err := mru.filesize()
+	if err != nil {
+		return "", err
+	}
+	if mru.Verbose {
+		logging.Infof("Size: %.2f MB\n", size)
+	}
+	meta, url, err := gql.RegisterMetaFileGCS(ctx, mru.PID, mru.Bucket, mru.Filename)
+	if err != nil {
+		return "", err
+	}
+	mru.MID = meta.ID
+
+	// b. upload to gcs with retry
+	trial := 5
+	for i := 0; i < trial; i++ {
+		err = putSigned(ctx, service.GCSUploadMethod, mru.MetaPath, url, mru.Progress)
+		if err == nil {
+			break
+		}
+		if mru.Verbose {
+			logging.Warnf("Retrying (x %d) upload to GCS for %q\n", i+1, mru.Filename)
+		}
+		if e := sleep(ctx, time.Second); e != nil {
+			err = e
+			break
+		}
+	}
+	reportDone(mru.Progress, mru.MetaPath, err)
+	if err != nil {
+		return "", err
+	}
+
+	return mru.checkState(ctx)
+}
+
+// ossUpload uploads to oss by the STS creds of the registration.
+func (mru *MetaFileRegUploader) ossUpload(ctx context.Context) (string, error) {
+	if mru.Verbose {
+		logging.Infof("Uploading %q\n", mru.Filename)
+	}
+	size, err := mru.filesize()
+	if err != nil {
+		return "", err
+	}
+	if mru.Verbose {
+		logging.Infof("Size: %.2f MB\n", size)
+	}
+	meta, sts, err := gql.RegisterMetaFileOSS(ctx, mru.PID, mru.Bucket, mru.Filename)
+	if err != nil {
+		return "", err
+	}
+	mru.MID = meta.ID
+	up, err := NewOSSUploader(mru.PID, onceSTS(sts))
+	if err != nil {
+		return "", err
+	}
+	cloudPath := mru.Filename
+	if meta.Filename != "" {
+		cloudPath = meta.Filename
+	}
+
+	// b. upload to oss with retry
+	trial := 5
+	for i := 0; i < trial; i++ {
+		err = up.PutFile(mru.MetaPath, cloudPath)
+		if err == nil {
+			break
+		}
+		if mru.Verbose {
+			logging.Warnf("Retrying (x %d) upload to OSS for %q\n", i+1, mru.Filename)
+		}
+		if e := sleep(ctx, time.Second); e != nil {
+			err = e
+			break
+		}
+	}
+	reportDone(mru.Progress, mru.MetaPath, err)
+	if err != nil {
+		return "", err
+	}
+
+	return mru.checkState(ctx)
 }
 
 // checkState checks if the model state is changed from Pending until timeout.
-func (mru *MetaFileRegUploader) checkState() (string, error) {
+func (mru *MetaFileRegUploader) checkState(ctx context.Context) (string, error) {
 	timeout, err := mru.getTimeout()
 	if err != nil {
 		return "", err
 	}
 
-	stateC := make(chan string)
+	stateC := make(chan string, 1)
 	var stateErr error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	// check per second
 	go func() {
-		log.Println("Checking state...")
+		logging.Infoln("Checking state...")
 		for {
-			m, err := gql.ProjectMetaFile(mru.PID, mru.MID)
+			m, err := gql.ProjectMetaFile(ctx, mru.PID, mru.MID)
 			if err != nil {
 				stateErr = err
 				stateC <- ""
@@ -173,7 +343,7 @@ func (mru *MetaFileRegUploader) checkState() (string, error) {
 			}
 			s := m.State
 			if mru.Verbose {
-				log.Println(s)
+				logging.Infoln(s)
 			}
 			if mru.Method == service.DirectUploadMethod {
 				if s == service.Ready || s == service.Failed {
@@ -186,15 +356,19 @@ func (mru *MetaFileRegUploader) checkState() (string, error) {
 					return
 				}
 			}
-			time.Sleep(time.Second * 1)
+			if sleep(ctx, time.Second) != nil {
+				return
+			}
 		}
 	}()
 
 	var state string
-	log.Printf("Client will be timeout in %d seconds\n", timeout)
+	logging.Infof("Client will be timeout in %d seconds\n", timeout)
 	select {
 	case <-time.After(time.Second * timeout):
 		return service.Pending, errors.ErrClientTimeout
+	case <-ctx.Done():
+		return service.Pending, ctx.Err()
 	case state = <-stateC:
 		if stateErr != nil {
 			return state, stateErr
@@ -206,14 +380,14 @@ func (mru *MetaFileRegUploader) checkState() (string, error) {
 // computeChecksum computes the SHA1 sum.
 func (mru *MetaFileRegUploader) computeChecksum() (string, error) {
 	if mru.Verbose {
-		log.Printf("Computing checksum: %q...\n", mru.Filename)
+		logging.Infof("Computing checksum: %q...\n", mru.Filename)
 	}
 	checksum, err := file.Sha1sum(mru.MetaPath)
 	if err != nil {
 		return "", err
 	}
 	if mru.Verbose {
-		log.Printf("SHA1: %s\n", checksum)
+		logging.Infof("SHA1: %s\n", checksum)
 	}
 	return checksum, nil
 }
@@ -1,17 +1,22 @@
 package cloud
 
 import (
+	"context"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/schedule"
 	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
 )
 
 // ModelRegUploader coordinates model registration and uploading.
@@ -21,36 +26,55 @@ type ModelRegUploader struct {
 	ModelPath    string
 	Filename     string
 	DirectURL    string
+	Chunked      bool // DirectURL is the manifest url of the chunks
 	Bucket       string
 	MultipartDir string // dir storing the 7zip multiparts
+	PartSize     int64  // in bytes, file larger than it is split; 0 for splitting only files larger than 5GB into 100MB parts
+	Parallel     int    // number of parts uploaded concurrently, default is number of cores
+	Resume       bool   // resume the previous interrupted multipart upload
 	Timeout      int
 	Verbose      bool
-	tmpDir       string // for storing newly created multipart files
+	Verify       bool                     // compare the checksum computed by the server once ready
+	Progress     service.ProgressReporter // optional
+	Schedule     *schedule.Window         // optional, pauses the uploads outside of the window
+	partsDir     string                   // for storing newly created multipart files
+	multipart    bool                     // in a multipart upload session of s3 or minio
 }
 
 // Run starts the registration and uploading process.
 // Return the state of imported model.
 func (mru *ModelRegUploader) Run() (string, error) {
+	mru.wait()
 	switch mru.Method {
 	case service.DirectUploadMethod:
 		return mru.directUpload()
 	case service.S3UploadMethod:
 		fallthrough
 	case service.MinioUploadMethod:
+		fallthrough
+	case service.GCSUploadMethod:
+		fallthrough
+	case service.OSSUploadMethod:
 		return mru.smUpload(mru.Method)
 	}
 	return "", errors.ErrUploadMethodInvalid
 }
 
 // Done cleanups this uploader if user wants to terminate early.
+// The created parts are kept for resuming.
 func (mru *ModelRegUploader) Done() {
-	if mru.tmpDir != "" {
-		err := os.RemoveAll(mru.tmpDir)
-		if err != nil {
-			log.Println(err)
-			return
-		}
-		log.Printf("Removed %q\n", mru.tmpDir)
+	if mru.partsDir != "" {
+		logging.Infof("Parts are kept in %q. Resume the upload by adding '--resume'\n", mru.partsDir)
+	}
+	if mru.multipart {
+		logging.Infoln("Uploaded parts are kept by the cloud. Resume the upload by adding '--resume'")
+	}
+}
+
+// wait blocks until the window of Schedule is open, if any.
+func (mru *ModelRegUploader) wait() {
+	if mru.Schedule != nil {
+		mru.Schedule.Wait(context.Background())
 	}
 }
 
@@ -64,11 +88,15 @@ func (mru *ModelRegUploader) directUpload() (string, error) {
 	}
 
 	// register model
-	im, err := gql.RegisterModelURL(mru.PID, mru.DirectURL, mru.Filename, checksum)
+	register := gql.RegisterModelURL
+	if mru.Chunked {
+		register = gql.RegisterModelChunkedURL
+	}
+	im, err := register(mru.PID, mru.DirectURL, mru.Filename, checksum)
 	if err != nil {
 		return "", err
 	}
-	log.Printf("Registered model with state: %q\n", im.State)
+	logging.Infof("Registered model with state: %q\n", im.State)
 
 	// c. signal completing upload
 	state, err := gql.DoneModelUpload(mru.PID, false)
@@ -79,7 +107,7 @@ func (mru *ModelRegUploader) directUpload() (string, error) {
 	return mru.checkState()
 }
 
-// smUpload uploads to s3 or minio via a single zip or multipart way of uploading.
+// smUpload uploads to s3, minio, gcs or oss via a single zip or multipart way of uploading.
 func (mru *ModelRegUploader) smUpload(method string) (string, error) {
 	if mru.MultipartDir != "" {
 		return mru.smUploadMulti7z(method)
@@ -87,33 +115,102 @@ func (mru *ModelRegUploader) smUpload(method string) (string, error) {
 	return mru.smUploadSingle(method)
 }
 
-// smUploadMulti uploads each multipart of a obj zip to s3 or minio.
-// Each part could be concatenated in raw binary form.
+// smUploadMulti splits the obj zip into parts and uploads each of them to
+// s3, minio, gcs or oss. Each part could be concatenated in raw binary form.
+// The parts and the upload state are kept for resuming if any part fails.
 func (mru *ModelRegUploader) smUploadMulti(method string) (string, error) {
-	tmpDir, err := ioutil.TempDir(".", "")
+	dir, err := sessionDir(mru.PID, mru.ModelPath)
 	if err != nil {
 		return "", err
 	}
-	mru.tmpDir = tmpDir
-	log.Printf("Created dir %q for storing parts\n", tmpDir)
-	defer mru.Done()
+	partsList := filepath.Join(dir, "parts.txt")
+
+	var parts []string
+	if mru.Resume {
+		parts, _ = readLines(partsList)
+		if len(parts) > 0 {
+			logging.Infof("Resuming from %q\n", dir)
+		}
+	}
+	if len(parts) == 0 {
+		if err = os.RemoveAll(dir); err != nil {
+			return "", err
+		}
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		logging.Infof("Created dir %q for storing parts\n", dir)
+		parts, err = file.SplitFile(mru.ModelPath, dir, mru.PartSize, mru.Verbose)
+		if err != nil {
+			return "", err
+		}
+		if err = writeLines(partsList, parts); err != nil {
+			return "", err
+		}
+	}
+	mru.partsDir = dir
 
-	parts, err := file.SplitFile(mru.ModelPath, tmpDir, 0, mru.Verbose)
+	// a. register and upload each part
+	err = mru.uploadParts(method, dir, parts, true, filepath.Join(dir, "uploaded.txt"))
 	if err != nil {
+		mru.Done()
 		return "", err
 	}
 
-	// a. register each part
-	err = mru.uploadParts(method, tmpDir, parts, true)
+	// c. signal completing multipart upload
+	state, err := gql.DoneModelUpload(mru.PID, true)
+	if err != nil {
+		return state, err
+	}
+	mru.partsDir = ""
+	return state, os.RemoveAll(dir)
+}
+
+// smUploadMultipart uploads the obj zip to s3 or minio in a multipart upload
+// session, without splitting it into part files. The session is kept by the
+// cloud for resuming if any part fails.
+func (mru *ModelRegUploader) smUploadMultipart(method string) (string, error) {
+	mu := multipartUpload{
+		PID:      mru.PID,
+		Method:   method,
+		Path:     mru.ModelPath,
+		PartSize: mru.PartSize,
+		Parallel: mru.Parallel,
+		Resume:   mru.Resume,
+		Verbose:  mru.Verbose,
+		Progress: mru.Progress,
+		Schedule: mru.Schedule,
+		Register: func(ctx context.Context) (string, error) {
+			var m *types.Model
+			var err error
+			if method == service.MinioUploadMethod {
+				m, _, err = gql.RegisterModelMinio(mru.PID, mru.Bucket, mru.Filename)
+			} else {
+				m, _, err = gql.RegisterModelS3(mru.PID, mru.Bucket, mru.Filename)
+			}
+			if err != nil {
+				return "", err
+			}
+			return m.ID, nil
+		},
+	}
+	mru.multipart = true
+	_, err := mu.Run(context.Background())
+	reportDone(mru.Progress, mru.ModelPath, err)
 	if err != nil {
+		mru.Done()
 		return "", err
 	}
+	mru.multipart = false
 
-	// c. signal completing multipart upload
-	return gql.DoneModelUpload(mru.PID, true)
+	// c. signal completing upload
+	if _, err = gql.DoneModelUpload(mru.PID, false); err != nil {
+		return "", err
+	}
+	return mru.checkState()
 }
 
-// smUploadMulti7z uploads 7z multipart to s3 or minio.
+// smUploadMulti7z uploads 7z multipart to s3, minio, gcs or oss.
 func (mru *ModelRegUploader) smUploadMulti7z(method string) (string, error) {
 	files, err := ioutil.ReadDir(mru.MultipartDir)
 	if err != nil {
@@ -124,98 +221,189 @@ func (mru *ModelRegUploader) smUploadMulti7z(method string) (string, error) {
 	for _, f := range files {
 		parts = append(parts, f.Name())
 	}
-	err = mru.uploadParts(method, mru.MultipartDir, parts, false)
+
+	// upload state is kept separately from the user's parts
+	dir, err := sessionDir(mru.PID, mru.MultipartDir)
+	if err != nil {
+		return "", err
+	}
+	if !mru.Resume {
+		if err = os.RemoveAll(dir); err != nil {
+			return "", err
+		}
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	err = mru.uploadParts(method, mru.MultipartDir, parts, false, filepath.Join(dir, "uploaded.txt"))
 	if err != nil {
+		logging.Infoln("Resume the upload by adding '--resume'")
 		return "", err
 	}
 
 	// c. signal completing multipart upload
-	return gql.DoneModelUpload(mru.PID, false)
+	state, err := gql.DoneModelUpload(mru.PID, false)
+	if err != nil {
+		return state, err
+	}
+	return state, os.RemoveAll(dir)
 }
 
-// uploadParts registers and uploads each part to s3.
+// uploadParts registers and uploads the parts concurrently, skipping the
+// ones recorded as uploaded in the state file.
 // baseDir is the dir that contains all the parts.
 // parts is the slice of filenames of each part.
-func (mru *ModelRegUploader) uploadParts(method string, baseDir string, parts []string, removePart bool) error {
+// Return the first error after all parts are attempted.
+func (mru *ModelRegUploader) uploadParts(method, baseDir string, parts []string, removePart bool, statePath string) error {
+	ps, err := loadPartState(statePath)
+	if err != nil {
+		return err
+	}
+	var todo []string
 	for _, p := range parts {
-		if mru.Verbose {
-			log.Printf("Uploading %q\n", p)
-		}
-		localPath := filepath.Join(baseDir, p)
-		var url string
-		var err error
-		switch method {
-		case service.S3UploadMethod:
-			_, url, err = gql.RegisterModelS3(mru.PID, mru.Bucket, p)
-		case service.MinioUploadMethod:
-			_, url, err = gql.RegisterModelMinio(mru.PID, mru.Bucket, p)
-		}
-		if err != nil {
-			return err
+		if ps.has(p) {
+			if mru.Verbose {
+				logging.Infof("Skipped uploaded %q\n", p)
+			}
+			continue
 		}
+		todo = append(todo, p)
+	}
 
-		// b. upload to s3 with retry
-		trial := 5
-		for i := 0; i < trial; i++ {
-			err = PutS3(localPath, url)
-			if err == nil {
-				if removePart {
-					os.Remove(localPath)
+	n := mru.Parallel
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	if n > len(todo) {
+		n = len(todo)
+	}
+
+	partc := make(chan string)
+	errc := make(chan error, len(todo))
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			for p := range partc {
+				mru.wait()
+				err := mru.uploadPart(method, baseDir, p)
+				if err == nil {
+					err = ps.add(p)
 				}
-				break
-			}
-			if mru.Verbose {
-				log.Printf("Retrying (x %d) upload to %s for %q\n", i+1, strings.Title(method), p)
+				if err == nil && removePart {
+					os.Remove(filepath.Join(baseDir, p))
+				}
+				errc <- err
 			}
-			time.Sleep(time.Second)
-		}
+		}()
+	}
+	for _, p := range todo {
+		partc <- p
+	}
+	close(partc)
+	wg.Wait()
+	close(errc)
+
+	var firstErr error
+	var failed int
+	for err := range errc {
 		if err != nil {
-			return err
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
 		}
 	}
-	return nil
+	if failed > 0 {
+		logging.Warnf("%d out of %d parts failed to upload\n", failed, len(todo))
+	}
+	return firstErr
 }
 
-// smUploadSingle uploads a single obj zip to s3 or minio.
-func (mru *ModelRegUploader) smUploadSingle(method string) (string, error) {
-	if mru.Verbose {
-		log.Printf("Uploading %q\n", mru.Filename)
-	}
-	size, err := mru.filesize()
-	if err != nil {
-		return "", err
-	}
+// uploadPart registers and uploads a single part with retry.
+func (mru *ModelRegUploader) uploadPart(method, baseDir, p string) error {
 	if mru.Verbose {
-		log.Printf("Size: %.2f MB\n", size)
-	}
-	if size > 5*1024 {
-		log.Printf("Filesize (%.2f MB) is bigger than 5GB", size)
-		return mru.smUploadMulti(method)
+		logging.Infof("Uploading %q\n", p)
 	}
+	localPath := filepath.Join(baseDir, p)
+	err := mru.put(method, localPath, p)
+	reportDone(mru.Progress, localPath, err)
+	return err
+}
 
+// put registers the model file of filename and uploads the file of
+// localPath to s3, minio, gcs or oss with retry.
+func (mru *ModelRegUploader) put(method, localPath, filename string) error {
 	var url string
+	var up *OSSUploader
+	var err error
 	switch method {
 	case service.S3UploadMethod:
-		_, url, err = gql.RegisterModelS3(mru.PID, mru.Bucket, mru.Filename)
+		_, url, err = gql.RegisterModelS3(mru.PID, mru.Bucket, filename)
 	case service.MinioUploadMethod:
-		_, url, err = gql.RegisterModelMinio(mru.PID, mru.Bucket, mru.Filename)
+		_, url, err = gql.RegisterModelMinio(mru.PID, mru.Bucket, filename)
+	case service.GCSUploadMethod:
+		_, url, err = gql.RegisterModelGCS(mru.PID, mru.Bucket, filename)
+	case service.OSSUploadMethod:
+		var m *types.Model
+		var sts *types.STS
+		if m, sts, err = gql.RegisterModelOSS(mru.PID, mru.Bucket, filename); err == nil {
+			if m.Filename != "" {
+				filename = m.Filename
+			}
+			up, err = NewOSSUploader(mru.PID, onceSTS(sts))
+		}
 	}
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	// b. upload to s3 with retry
+	// b. upload with retry
 	trial := 5
 	for i := 0; i < trial; i++ {
-		err = PutS3(mru.ModelPath, url)
+		if up != nil {
+			err = up.PutFile(localPath, filename)
+		} else {
+			err = putSigned(context.Background(), method, localPath, url, mru.Progress)
+		}
 		if err == nil {
 			break
 		}
 		if mru.Verbose {
-			log.Printf("Retrying (x %d) upload to %s for %q\n", i+1, strings.Title(method), mru.Filename)
+			logging.Warnf("Retrying (x %d) upload to %s for %q\n", i+1, strings.Title(method), filename)
 		}
 		time.Sleep(time.Second)
 	}
+	return err
+}
+
+// smUploadSingle uploads a single obj zip to s3, minio, gcs or oss.
+func (mru *ModelRegUploader) smUploadSingle(method string) (string, error) {
+	if mru.Verbose {
+		logging.Infof("Uploading %q\n", mru.Filename)
+	}
+	size, err := mru.filesize()
+	if err != nil {
+		return "", err
+	}
+	if mru.Verbose {
+		logging.Infof("Size: %.2f MB\n", size)
+	}
+	limit := 5 * 1024.0
+	if mru.PartSize > 0 {
+		limit = file.BytesToMB(mru.PartSize)
+	}
+	if size > limit {
+		logging.Warnf("Filesize (%.2f MB) is bigger than %.2f MB", size, limit)
+		if b, _ := file.Filesize(mru.ModelPath); useMultipart(method, b, mru.PartSize) {
+			return mru.smUploadMultipart(method)
+		}
+		return mru.smUploadMulti(method)
+	}
+
+	err = mru.put(method, mru.ModelPath, mru.Filename)
+	reportDone(mru.Progress, mru.ModelPath, err)
 	if err != nil {
 		return "", err
 	}
@@ -239,7 +427,7 @@ func (mru *ModelRegUploader) checkState() (string, error) {
 
 	// check per second
 	go func() {
-		log.Println("Checking state...")
+		logging.Infoln("Checking state...")
 		for {
 			p, err := gql.Project(mru.PID)
 			errors.Must(err)
@@ -253,30 +441,57 @@ func (mru *ModelRegUploader) checkState() (string, error) {
 	}()
 
 	var state string
-	log.Printf("Client will be timeout in %d seconds\n", timeout)
+	logging.Infof("Client will be timeout in %d seconds\n", timeout)
 	select {
 	case <-time.After(time.Second * timeout):
 		return service.Pending, errors.ErrClientTimeout
 	case state = <-stateC:
-		return state, nil
+		return mru.verify(state)
 	}
 }
 
 // checksum computes the SHA1 sum.
 func (mru *ModelRegUploader) checksum() (string, error) {
 	if mru.Verbose {
-		log.Printf("Computing checksum: %q...\n", mru.Filename)
+		logging.Infof("Computing checksum: %q...\n", mru.Filename)
 	}
 	checksum, err := file.Sha1sum(mru.ModelPath)
 	if err != nil {
 		return "", err
 	}
 	if mru.Verbose {
-		log.Printf("SHA1: %s\n", checksum)
+		logging.Infof("SHA1: %s\n", checksum)
 	}
 	return checksum, nil
 }
 
+// verify compares the checksum of the ready model computed by the server
+// with the local one if Verify is set. Return ErrChecksumMismatch if they
+// differ.
+func (mru *ModelRegUploader) verify(state string) (string, error) {
+	if !mru.Verify || state != service.Ready {
+		return state, nil
+	}
+	remote, err := gql.ModelChecksum(context.Background(), mru.PID)
+	if err != nil {
+		return state, err
+	}
+	if remote == "" {
+		logging.Warnln("Checksum is not computed by the server, not verified")
+		return state, nil
+	}
+	local, err := mru.checksum()
+	if err != nil {
+		return state, err
+	}
+	if err = file.VerifyChecksum(mru.ModelPath, local, remote); err != nil {
+		logging.Errorf("Checksum mismatch, local: %s, server: %s\n", local, remote)
+		return state, err
+	}
+	logging.Infoln("Checksum is verified")
+	return state, nil
+}
+
 // filesize gives the size of model in MegaByte.
 func (mru *ModelRegUploader) filesize() (float64, error) {
 	size, err := file.Filesize(mru.ModelPath)
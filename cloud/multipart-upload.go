@@ -0,0 +1,362 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/schedule"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+)
+
+// Limits of the parts of a multipart upload of s3 and minio.
+const (
+	minPartSize     = 5 * 1024 * 1024
+	defaultPartSize = 100 * 1024 * 1024
+	maxParts        = 10000
+)
+
+// multipartLimit gives the size in bytes above which a file is uploaded in
+// parts of partSize, or of 100MB above 5GB if partSize is not set.
+func multipartLimit(partSize int64) int64 {
+	if partSize > 0 {
+		return partSize
+	}
+	return 5 * 1024 * 1024 * 1024
+}
+
+// multipartPartSize gives the size of each part of a file of size, which is
+// partSize if it fits in the limits of s3.
+func multipartPartSize(size, partSize int64) int64 {
+	if partSize <= 0 {
+		partSize = defaultPartSize
+	}
+	if partSize < minPartSize {
+		partSize = minPartSize
+	}
+	if n := (size + partSize - 1) / partSize; n > maxParts {
+		partSize = (size + maxParts - 1) / maxParts
+	}
+	return partSize
+}
+
+// useMultipart tells if a file of size uploaded by method goes in a multipart
+// upload session, which is only if the api server advertises it.
+func useMultipart(method string, size, partSize int64) bool {
+	if method != service.S3UploadMethod && method != service.MinioUploadMethod {
+		return false
+	}
+	if size <= multipartLimit(partSize) {
+		return false
+	}
+	c, err := gql.Capabilities()
+	return err == nil && c.Features != nil && c.Has(types.FeatureMultipartUpload)
+}
+
+// multipartState is the session of a multipart upload kept for resuming.
+type multipartState struct {
+	FileID   string
+	UploadID string
+	Size     int64
+	ModTime  time.Time
+	PartSize int64
+}
+
+// multipartUpload uploads a single file to s3 or minio in a multipart upload
+// session: the parts are read directly from the file and put concurrently
+// to their signed urls, then the cloud concatenates them once completed.
+// The session is kept if any part fails, so that it could be resumed by
+// uploading only the parts the cloud does not have.
+type multipartUpload struct {
+	PID      string
+	Method   string
+	Path     string
+	PartSize int64 // in bytes, 0 for 100MB
+	Parallel int   // number of parts uploaded concurrently, default is number of cores
+	Resume   bool
+	Verbose  bool
+	Progress service.ProgressReporter // optional
+	Schedule *schedule.Window         // optional, each part waits for the window
+	// Register registers the file for uploading, giving its id.
+	Register func(ctx context.Context) (string, error)
+}
+
+// Run uploads the file and completes the session. Return the id of the
+// registered file.
+func (mu *multipartUpload) Run(ctx context.Context) (string, error) {
+	stat, err := file.StatFile(mu.Path)
+	if err != nil {
+		return "", err
+	}
+	size := stat.Size()
+	partSize := multipartPartSize(size, mu.PartSize)
+	dir, err := sessionDir(mu.PID, mu.Path)
+	if err != nil {
+		return "", err
+	}
+	statePath := filepath.Join(dir, "multipart.json")
+
+	st, uploaded := mu.resume(ctx, statePath, size, stat.ModTime(), partSize)
+	if st.UploadID == "" {
+		fid, err := mu.Register(ctx)
+		if err != nil {
+			return "", err
+		}
+		uid, err := gql.StartMultipartUpload(ctx, fid)
+		if err != nil {
+			return fid, err
+		}
+		st = multipartState{
+			FileID:   fid,
+			UploadID: uid,
+			Size:     size,
+			ModTime:  stat.ModTime(),
+			PartSize: partSize,
+		}
+		if err = saveMultipartState(statePath, st); err != nil {
+			return fid, err
+		}
+	}
+
+	n := int((size + partSize - 1) / partSize)
+	var done int64
+	var todo []int
+	for i := 1; i <= n; i++ {
+		if p, ok := uploaded[i]; ok && p.Size == partLength(i, size, partSize) {
+			done += p.Size
+			continue
+		}
+		delete(uploaded, i)
+		todo = append(todo, i)
+	}
+	if mu.Verbose {
+		logging.Infof("Uploading %d of %d parts of %.2f MB\n", len(todo), n, file.BytesToMB(partSize))
+	}
+	if mu.Progress != nil {
+		mu.Progress.Start(mu.Path, size)
+		mu.Progress.Add(mu.Path, done)
+	}
+
+	if err = mu.uploadParts(ctx, st, todo, uploaded); err != nil {
+		return st.FileID, err
+	}
+
+	var parts []types.UploadedPart
+	for _, p := range uploaded {
+		parts = append(parts, p)
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	if err = gql.CompleteMultipartUpload(ctx, st.FileID, st.UploadID, parts); err != nil {
+		return st.FileID, err
+	}
+	return st.FileID, os.Remove(statePath)
+}
+
+// resume loads the previous session of statePath and the parts uploaded in
+// it if Resume is set and the file is unchanged. Otherwise the previous
+// session is aborted and an empty one is returned.
+func (mu *multipartUpload) resume(ctx context.Context, statePath string, size int64, modTime time.Time, partSize int64) (multipartState, map[int]types.UploadedPart) {
+	uploaded := make(map[int]types.UploadedPart)
+	var st multipartState
+	b, err := ioutil.ReadFile(statePath)
+	if err != nil || json.Unmarshal(b, &st) != nil || st.UploadID == "" {
+		return multipartState{}, uploaded
+	}
+	if !mu.Resume || st.Size != size || !st.ModTime.Equal(modTime) || st.PartSize != partSize {
+		if err = gql.AbortMultipartUpload(ctx, st.FileID, st.UploadID); err != nil && mu.Verbose {
+			logging.Warnf("Could not abort the previous multipart upload: %v\n", err)
+		}
+		os.Remove(statePath)
+		return multipartState{}, uploaded
+	}
+	parts, err := gql.MultipartParts(ctx, st.FileID, st.UploadID)
+	if err != nil {
+		logging.Warnf("Could not resume the multipart upload, starting over: %v\n", err)
+		os.Remove(statePath)
+		return multipartState{}, uploaded
+	}
+	for _, p := range parts {
+		uploaded[p.PartNumber] = p
+	}
+	logging.Infof("Resuming the multipart upload, %d parts are uploaded\n", len(parts))
+	return st, uploaded
+}
+
+// uploadParts puts the parts of todo concurrently, adding each uploaded one
+// to uploaded. Return the first error after all parts are attempted.
+func (mu *multipartUpload) uploadParts(ctx context.Context, st multipartState, todo []int, uploaded map[int]types.UploadedPart) error {
+	n := mu.Parallel
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	if n > len(todo) {
+		n = len(todo)
+	}
+
+	var mutex sync.Mutex
+	partc := make(chan int)
+	errc := make(chan error, len(todo))
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			for num := range partc {
+				if mu.Schedule != nil {
+					if err := mu.Schedule.Wait(ctx); err != nil {
+						errc <- err
+						continue
+					}
+				}
+				p, err := mu.uploadPart(ctx, st, num)
+				if err == nil {
+					mutex.Lock()
+					uploaded[num] = p
+					mutex.Unlock()
+				}
+				errc <- err
+			}
+		}()
+	}
+	for _, num := range todo {
+		partc <- num
+	}
+	close(partc)
+	wg.Wait()
+	close(errc)
+
+	var firstErr error
+	var failed int
+	for err := range errc {
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if failed > 0 {
+		logging.Warnf("%d out of %d parts failed to upload\n", failed, len(todo))
+	}
+	return firstErr
+}
+
+// uploadPart puts the part of num with retry.
+func (mu *multipartUpload) uploadPart(ctx context.Context, st multipartState, num int) (types.UploadedPart, error) {
+	part := types.UploadedPart{
+		PartNumber: num,
+		Size:       partLength(num, st.Size, st.PartSize),
+	}
+	offset := int64(num-1) * st.PartSize
+
+	var err error
+	trial := 5
+	for i := 0; i < trial; i++ {
+		var url string
+		if url, err = gql.MultipartPartURL(ctx, st.FileID, st.UploadID, num); err == nil {
+			if part.ETag, err = mu.putPart(ctx, url, offset, part.Size); err == nil {
+				return part, nil
+			}
+		}
+		if mu.Verbose {
+			logging.Warnf("Retrying (x %d) upload of part %d: %v\n", i+1, num, err)
+		}
+		if e := sleep(ctx, time.Second); e != nil {
+			return part, e
+		}
+	}
+	return part, err
+}
+
+// putPart puts the n bytes of the file from offset to the signed url of a
+// part. Return the ETag of the part.
+func (mu *multipartUpload) putPart(ctx context.Context, url string, offset, n int64) (string, error) {
+	f, err := file.OpenFile(mu.Path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	var sent int64
+	var body io.Reader = io.LimitReader(f, n)
+	if mu.Progress != nil {
+		body = &progressReader{Reader: body, name: mu.Path, pr: countReporter{mu.Progress, &sent}}
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = n
+
+	res, err := config.HTTPClient(0).Do(req)
+	if err == nil {
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			err = errors.ErrS3Error
+			if mu.Method == service.MinioUploadMethod {
+				err = errors.ErrMinioError
+			}
+		}
+	}
+	if err != nil {
+		// the bytes of a failed part are sent again
+		if mu.Progress != nil {
+			mu.Progress.Add(mu.Path, -atomic.LoadInt64(&sent))
+		}
+		return "", err
+	}
+	return res.Header.Get("ETag"), nil
+}
+
+// countReporter is a progress reporter that also counts the bytes added.
+type countReporter struct {
+	service.ProgressReporter
+	n *int64
+}
+
+func (r countReporter) Add(name string, n int64) {
+	atomic.AddInt64(r.n, n)
+	r.ProgressReporter.Add(name, n)
+}
+
+// partLength gives the size of the part of num of a file of size.
+func partLength(num int, size, partSize int64) int64 {
+	offset := int64(num-1) * partSize
+	if size-offset < partSize {
+		return size - offset
+	}
+	return partSize
+}
+
+// saveMultipartState writes the session st to path.
+func saveMultipartState(path string, st multipartState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
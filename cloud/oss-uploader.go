@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/jackytck/alti-cli/config"
 	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/types"
 )
 
@@ -24,6 +26,19 @@ func NewOSSUploader(pid string, refresh func() (*types.STS, error)) (*OSSUploade
 	return &ret, nil
 }
 
+// onceSTS gives the refresh func of OSSUploader that gives sts once, for
+// uploading a single file by the STS creds of its registration.
+func onceSTS(sts *types.STS) func() (*types.STS, error) {
+	var used bool
+	return func() (*types.STS, error) {
+		if used {
+			return nil, errors.ErrNOSTS
+		}
+		used = true
+		return sts, nil
+	}
+}
+
 // OSSUploader takes care of uploading files to a specific loccation
 // and refresh its credentials.
 type OSSUploader struct {
@@ -68,6 +83,14 @@ func (ou *OSSUploader) PutFile(filepath, cloudPath string) error {
 		return errors.ErrNOSTS
 	}
 	key := fmt.Sprintf("%s/%s", ou.PID, cloudPath)
+	if _, _, ok := file.SplitArchivePath(filepath); ok {
+		f, err := file.OpenFile(filepath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return ou.getBucket().PutObject(key, f)
+	}
 	return ou.getBucket().PutObjectFromFile(key, filepath)
 }
 
@@ -103,7 +126,7 @@ func (ou *OSSUploader) setBucket(bucket *oss.Bucket) {
 func (ou *OSSUploader) reconnect() error {
 	// setup new connection
 	sts := ou.getCreds()
-	c, err := oss.New(sts.Endpoint, sts.ID, sts.Secret, oss.SecurityToken(sts.Token))
+	c, err := oss.New(sts.Endpoint, sts.ID, sts.Secret, oss.SecurityToken(sts.Token), oss.HTTPClient(config.HTTPClient(0)))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,92 @@
+package cloud
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jackytck/alti-cli/db"
+)
+
+// partState records the uploaded parts of a multipart upload in a text file,
+// one part name per line, so that an interrupted upload could be resumed.
+type partState struct {
+	path     string
+	mu       sync.Mutex
+	uploaded map[string]bool
+}
+
+// loadPartState loads the uploaded parts from path if it exists.
+func loadPartState(path string) (*partState, error) {
+	lines, err := readLines(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	ps := partState{
+		path:     path,
+		uploaded: make(map[string]bool),
+	}
+	for _, l := range lines {
+		ps.uploaded[l] = true
+	}
+	return &ps, nil
+}
+
+// has tells if part is uploaded.
+func (ps *partState) has(part string) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.uploaded[part]
+}
+
+// add records part as uploaded.
+func (ps *partState) add(part string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	f, err := os.OpenFile(ps.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = fmt.Fprintln(f, part); err != nil {
+		return err
+	}
+	ps.uploaded[part] = true
+	return nil
+}
+
+// sessionDir infers the dir for storing the parts and state of uploading
+// src into project pid. The same pid and src always give the same dir.
+func sessionDir(pid, src string) (string, error) {
+	p, err := db.ResumePath(pid, src)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(p, ".db") + ".parts", nil
+}
+
+// readLines reads the non-empty lines of a text file.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ret []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if l := strings.TrimSpace(scanner.Text()); l != "" {
+			ret = append(ret, l)
+		}
+	}
+	return ret, scanner.Err()
+}
+
+// writeLines writes each line into a text file.
+func writeLines(path string, lines []string) error {
+	return ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
@@ -0,0 +1,32 @@
+package cloud
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+)
+
+// pingTimeout is the timeout of each request of Ping.
+var pingTimeout = 10 * time.Second
+
+// Ping gives the median round trip time of n HEAD requests to url.
+// Any response, even not ok, counts as reachable.
+func Ping(url string, n int) (time.Duration, error) {
+	if n <= 0 {
+		n = 1
+	}
+	client := config.HTTPClient(pingTimeout)
+	var rtts []time.Duration
+	for i := 0; i < n; i++ {
+		start := time.Now()
+		res, err := client.Head(url)
+		if err != nil {
+			return 0, err
+		}
+		res.Body.Close()
+		rtts = append(rtts, time.Since(start))
+	}
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+	return rtts[len(rtts)/2], nil
+}
@@ -0,0 +1,159 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/service"
+)
+
+// MinRangeSize is the minimum size of each range of a parallel download.
+const MinRangeSize = 8 << 20
+
+// rangeRetry is the number of trials of downloading each range.
+const rangeRetry = 3
+
+// RangedFile downloads the file of size bytes from url into filepath by n
+// concurrent range requests, each retried from where it stopped. It falls
+// back to ResumeFile if the size is unknown, too small to split or the server
+// does not support range requests.
+// The downloaded bytes are reported to pr if it is not nil.
+func RangedFile(ctx context.Context, filepath, url string, size int64, n int, pr service.ProgressReporter) error {
+	if max := int(size / MinRangeSize); n > max {
+		n = max
+	}
+	if n <= 1 || !supportsRange(url) {
+		return ResumeFile(ctx, filepath, url, pr)
+	}
+
+	part := filepath + ".part"
+	out, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err = out.Truncate(size); err != nil {
+		return err
+	}
+	if pr != nil {
+		pr.Start(filepath, size)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	errc := make(chan error, n)
+	chunk := size / int64(n)
+	for i := 0; i < n; i++ {
+		start := int64(i) * chunk
+		end := start + chunk - 1
+		if i == n-1 {
+			end = size - 1
+		}
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+			if err := getRange(ctx, out, filepath, url, start, end, pr); err != nil {
+				errc <- err
+				cancel()
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	close(errc)
+	if err = <-errc; err != nil {
+		out.Close()
+		os.Remove(part)
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(part, filepath)
+}
+
+// supportsRange tells if the server of url serves range requests.
+func supportsRange(url string) bool {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err := config.HTTPClient(time.Minute).Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusPartialContent
+}
+
+// getRange downloads the bytes from start to end inclusively of url into out,
+// resuming from the last written byte on each retry.
+func getRange(ctx context.Context, out io.WriterAt, name, url string, start, end int64, pr service.ProgressReporter) error {
+	var err error
+	for i := 0; i < rangeRetry && start <= end; i++ {
+		if i > 0 {
+			if err = sleep(ctx, time.Second); err != nil {
+				return err
+			}
+		}
+		var n int64
+		n, err = copyRange(ctx, out, name, url, start, end, pr)
+		start += n
+		if err == nil {
+			return nil
+		}
+		// not found or forbidden would not be better by retrying
+		if netErr, ok := err.(errors.NetworkError); ok && netErr.Code < 500 {
+			return err
+		}
+	}
+	return err
+}
+
+// copyRange copies a single range request into out, returns the number of
+// bytes written.
+func copyRange(ctx context.Context, out io.WriterAt, name, url string, start, end int64, pr service.ProgressReporter) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := config.HTTPClient(0).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, errors.NetworkError{Code: resp.StatusCode, Message: "bad status"}
+	}
+
+	var body io.Reader = io.LimitReader(resp.Body, end-start+1)
+	if pr != nil {
+		body = &progressReader{Reader: body, name: name, pr: pr}
+	}
+	n, err := io.Copy(&offsetWriter{w: out, off: start}, body)
+	if err == nil && n < end-start+1 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// offsetWriter writes sequentially into w from offset off.
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.off)
+	o.off += int64(n)
+	return n, err
+}
@@ -0,0 +1,189 @@
+package cloud
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// throttleKeep is the fraction of the best throughput a round has to keep
+// for growing the limit, otherwise the limit is halved.
+const throttleKeep = 0.75
+
+// WorkerStat is the throughput of an upload worker.
+type WorkerStat struct {
+	Worker  int
+	Files   int
+	Bytes   int64
+	Elapsed time.Duration // total time spent on uploading
+}
+
+// Speed gives the throughput of the worker in bytes per second.
+func (w WorkerStat) Speed() float64 {
+	if w.Elapsed <= 0 {
+		return 0
+	}
+	return float64(w.Bytes) / w.Elapsed.Seconds()
+}
+
+// Throttle adapts the number of concurrent uploads by AIMD, i.e. the limit
+// grows by one after each round of uploads that keeps the throughput, and is
+// halved after a failed upload or a drop of the throughput.
+// A round is as many uploads as the limit.
+type Throttle struct {
+	Min int
+	Max int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	active  int
+	done    int // uploads of the current round
+	bytes   int64
+	start   time.Time
+	best    float64 // best throughput of a round in bytes per second
+	workers map[int]*WorkerStat
+}
+
+// NewThrottle creates a throttle of the initial limit n, bounded by min and max.
+func NewThrottle(n, min, max int) *Throttle {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	t := Throttle{
+		Min:     min,
+		Max:     max,
+		limit:   clamp(n, min, max),
+		workers: make(map[int]*WorkerStat),
+	}
+	t.cond = sync.NewCond(&t.mu)
+	return &t
+}
+
+// Acquire blocks until the number of active uploads is below the limit, or
+// until ctx is done, in which case the error of ctx is returned.
+func (t *Throttle) Acquire(ctx context.Context) error {
+	// wake up the waiting below once ctx is done
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.mu.Lock()
+			t.cond.Broadcast()
+			t.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for t.active >= t.limit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		t.cond.Wait()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if t.active == 0 && t.done == 0 {
+		t.start = time.Now()
+	}
+	t.active++
+	return nil
+}
+
+// Release records an upload of worker that sent n bytes in d, and adapts the
+// limit.
+func (t *Throttle) Release(worker int, n int64, d time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	defer t.cond.Broadcast()
+	t.active--
+
+	if err != nil {
+		t.setLimit(t.limit / 2)
+		return
+	}
+	w, ok := t.workers[worker]
+	if !ok {
+		w = &WorkerStat{Worker: worker}
+		t.workers[worker] = w
+	}
+	w.Files++
+	w.Bytes += n
+	w.Elapsed += d
+
+	t.done++
+	t.bytes += n
+	if t.done < t.limit {
+		return
+	}
+	elapsed := time.Since(t.start).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	speed := float64(t.bytes) / elapsed
+	if speed >= t.best*throttleKeep {
+		if speed > t.best {
+			t.best = speed
+		}
+		t.setLimit(t.limit + 1)
+		return
+	}
+	// a new baseline, so that the limit could grow again
+	t.best = speed
+	t.setLimit(t.limit / 2)
+}
+
+// setLimit sets the limit within the bounds and starts a new round.
+func (t *Throttle) setLimit(n int) {
+	t.limit = clamp(n, t.Min, t.Max)
+	t.done = 0
+	t.bytes = 0
+	t.start = time.Now()
+}
+
+// Limit gives the current limit of concurrent uploads.
+func (t *Throttle) Limit() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limit
+}
+
+// Speed gives the best throughput of a round in bytes per second.
+func (t *Throttle) Speed() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.best
+}
+
+// Stats gives the throughput of each worker, sorted by worker.
+func (t *Throttle) Stats() []WorkerStat {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ret := make([]WorkerStat, 0, len(t.workers))
+	for _, w := range t.workers {
+		ret = append(ret, *w)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Worker < ret[j].Worker
+	})
+	return ret
+}
+
+// clamp bounds n within [min, max].
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
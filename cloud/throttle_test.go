@@ -0,0 +1,114 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// round is a round of uploads of a throttle, each of which sends n bytes.
+// The round takes d, or it is a single failed upload if err is set.
+type round struct {
+	n   int64
+	d   time.Duration
+	err error
+}
+
+// run runs the round r on th.
+func (r round) run(t *testing.T, th *Throttle) {
+	t.Helper()
+	ctx := context.Background()
+	if r.err != nil {
+		if err := th.Acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+		th.Release(0, 0, 0, r.err)
+		return
+	}
+	n := th.Limit()
+	for i := 0; i < n; i++ {
+		if err := th.Acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	th.mu.Lock()
+	th.start = time.Now().Add(-r.d)
+	th.mu.Unlock()
+	for i := 0; i < n; i++ {
+		th.Release(i, r.n, r.d, nil)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	errUpload := errors.New("upload failed")
+	tests := []struct {
+		name     string
+		n        int
+		min, max int
+		rounds   []round
+		want     int
+	}{
+		{"initial clamped to max", 10, 1, 4, nil, 4},
+		{"initial clamped to min", 0, 2, 4, nil, 2},
+		{"grow", 2, 1, 8, []round{{100, time.Second, nil}}, 3},
+		{"grow while keeping", 2, 1, 8, []round{
+			{100, time.Second, nil},
+			{100, time.Second, nil},
+		}, 4},
+		{"grow up to max", 3, 1, 4, []round{
+			{100, time.Second, nil},
+			{100, time.Second, nil},
+		}, 4},
+		{"halve on error", 6, 1, 8, []round{{err: errUpload}}, 3},
+		{"halve down to min", 3, 2, 8, []round{{err: errUpload}}, 2},
+		{"halve on drop", 4, 1, 8, []round{
+			{100, time.Second, nil},
+			{10, time.Second, nil},
+		}, 2},
+		{"grow after drop", 4, 1, 8, []round{
+			{100, time.Second, nil},
+			{10, time.Second, nil},
+			{100, time.Second, nil},
+		}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := NewThrottle(tt.n, tt.min, tt.max)
+			for _, r := range tt.rounds {
+				r.run(t, th)
+			}
+			if got := th.Limit(); got != tt.want {
+				t.Errorf("Limit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThrottleAcquireCanceled(t *testing.T) {
+	th := NewThrottle(1, 1, 1)
+	if err := th.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		errc <- th.Acquire(ctx)
+	}()
+	cancel()
+	select {
+	case err := <-errc:
+		if err != context.Canceled {
+			t.Errorf("Acquire() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Acquire() is not returned after its context is canceled")
+	}
+
+	// the canceled acquire does not take the slot
+	th.Release(0, 0, 0, nil)
+	if err := th.Acquire(context.Background()); err != nil {
+		t.Errorf("Acquire() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// accountListCmd represents the account list command
+var accountListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all the available accounts",
+	Long:  "List all the previously logined accounts across different servers.",
+	Run: func(cmd *cobra.Command, args []string) {
+		accountCmd.Run(cmd, args)
+	},
+}
+
+func init() {
+	accountCmd.AddCommand(accountListCmd)
+	accountListCmd.Flags().IntVarP(&actTimeout, "timeout", "t", 3, "Timeout of checking api server state in seconds")
+	accountListCmd.Flags().StringVar(&outputTemplate, "template", outputTemplate, "Go template of each account, e.g. '{{.ID}} {{.Endpoint}} {{.Status}}'")
+}
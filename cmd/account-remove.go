@@ -2,17 +2,20 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jackytck/alti-cli/config"
 	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
 
 // removeCmd represents the remove command
 var removeCmd = &cobra.Command{
-	Use:   "remove",
-	Short: "Remove account profile",
-	Long:  "Remove a non-default user profile from the account list and config file.",
+	Use:               "remove",
+	Short:             "Remove account profile",
+	Long:              "Remove a non-default user profile from the account list and config file.",
+	ValidArgsFunction: completeProfileIDs,
 	Run: func(cmd *cobra.Command, args []string) {
 		config := config.Load()
 		if len(args) < 1 {
@@ -21,21 +24,47 @@ var removeCmd = &cobra.Command{
 		}
 		id := args[0]
 
-		p, err := config.RemoveProfile(id, true)
+		// confirm the matched profile, as the id could be partial
+		p, err := config.GetProfile(id)
+		if err != nil {
+			fmt.Printf("ID: '%s' not found!\nLook up at 'alti-cli account list'.\n", id)
+			return
+		}
+		fmt.Printf("Profile: %s (%s)\n", p.ID, p.Name)
+		if p.ID == config.Active {
+			fmt.Println("Warning: it is the active profile, the default profile will be used instead.")
+		}
+		fmt.Print("Remove this profile or not? (Y/N): ")
+		if assumeYes {
+			fmt.Println("Yes")
+		} else {
+			var ans string
+			fmt.Scanln(&ans)
+			ans = strings.ToUpper(ans)
+			if ans != "Y" && ans != service.Yes {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		a, err := config.RemoveProfile(p.ID, true)
 		if err != nil {
 			switch err {
 			case errors.ErrProfileNotFound:
-				fmt.Printf("ID: '%s' not found!\nLook up at 'alti-cli account'.", id)
+				fmt.Printf("ID: '%s' not found!\nLook up at 'alti-cli account list'.\n", id)
 			case errors.ErrProfileNotRemovable:
 				fmt.Println("Default profile could not be removed!")
+			default:
+				errors.Exit(err)
 			}
 			return
 		}
 
-		fmt.Printf("Removed: %s: %s\n", p.Endpoint, p.Name)
+		fmt.Printf("Removed: %s: %s\n", a.Endpoint, a.Name)
 	},
 }
 
 func init() {
 	accountCmd.AddCommand(removeCmd)
+	removeCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/spf13/cobra"
+)
+
+// renameCmd represents the account rename command
+var renameCmd = &cobra.Command{
+	Use:               "rename",
+	Short:             "Rename account profile",
+	Long:              "Change the reference ID of a non-default user profile, e.g. 'alti-cli account rename ID work'",
+	ValidArgsFunction: completeProfileIDs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config := config.Load()
+		if len(args) < 2 {
+			fmt.Println("Usage: alti-cli account rename ID NEW_ID")
+			return
+		}
+		id, newID := args[0], args[1]
+
+		p, err := config.RenameProfile(id, newID, true)
+		if err != nil {
+			switch err {
+			case errors.ErrProfileNotFound:
+				fmt.Printf("ID: '%s' not found!\nLook up at 'alti-cli account list'.\n", id)
+			case errors.ErrProfileNotRemovable:
+				fmt.Println("Default profile could not be renamed!")
+			case errors.ErrProfileIDInvalid:
+				fmt.Printf("New ID: '%s' is empty, taken or contains spaces!\n", newID)
+			default:
+				errors.Exit(err)
+			}
+			return
+		}
+
+		fmt.Printf("Renamed: %s: %s\n", p.ID, p.Name)
+	},
+}
+
+func init() {
+	accountCmd.AddCommand(renameCmd)
+}
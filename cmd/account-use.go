@@ -8,22 +8,24 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// useCmd represents the use command
+// useCmd represents the switch command
 var useCmd = &cobra.Command{
-	Use:   "use",
-	Short: "Choose which account to use.",
-	Long: `List all accounts by 'account'. Get the reference ID and use this
-command to switch to that, e.g. 'alti-cli account use ID'`,
+	Use:     "switch",
+	Aliases: []string{"use"},
+	Short:   "Choose which account to use.",
+	Long: `List all accounts by 'account list'. Get the reference ID and use this
+command to switch to that, e.g. 'alti-cli account switch ID'`,
+	ValidArgsFunction: completeProfileIDs,
 	Run: func(cmd *cobra.Command, args []string) {
 		config := config.Load()
 		if len(args) < 1 {
-			fmt.Println("Usage: alti-cli account use ID")
+			fmt.Println("Usage: alti-cli account switch ID")
 			return
 		}
 		id := args[0]
 		p, err := config.GetProfile(id)
 		if err != nil {
-			fmt.Printf("ID: '%s' not found!\nLook up at 'alti-cli account'.", id)
+			fmt.Printf("ID: '%s' not found!\nLook up at 'alti-cli account list'.\n", id)
 			return
 		}
 		config.Active = p.ID
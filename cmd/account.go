@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"log"
-	"os"
 	"sort"
 	"strings"
 	"sync"
@@ -10,8 +8,8 @@ import (
 
 	"github.com/jackytck/alti-cli/config"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -27,13 +25,13 @@ var accountCmd = &cobra.Command{
 		start := time.Now()
 		defer func() {
 			elapsed := time.Since(start)
-			log.Println("Took", elapsed)
+			logging.Infoln("Took", elapsed)
 		}()
 
 		// prepare account list
-		cfg := config.Load()
+		cfg := config.LoadAll()
 		timeout := time.Second * time.Duration(actTimeout)
-		actCh := make(chan []string)
+		actCh := make(chan account)
 		var wg sync.WaitGroup
 		wg.Add(cfg.Size())
 
@@ -54,24 +52,79 @@ var accountCmd = &cobra.Command{
 		}
 
 		// aggregate all accounts
-		var accounts [][]string
+		var accounts []account
 		for r := range actCh {
 			accounts = append(accounts, r)
 		}
 		sort.Sort(byEndpoint(accounts))
 
 		// render
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"ID", "Endpoint", "Username/Email", "Status", "Select", "Sales", "Super", "Image Cloud", "Model Cloud", "Meta Cloud", "Version", "Response Time"})
-		table.AppendBulk(accounts)
+		if printTemplate(accounts) {
+			return
+		}
+		table := newTable()
+		table.SetHeader([]string{"ID", "Endpoint", "Username/Email", "Status", "Select", "Sales", "Super", "Image Cloud", "Model Cloud", "Meta Cloud", "Version", "Response Time", "Token Expiry"})
+		for _, a := range accounts {
+			table.Append(a.RowString())
+		}
 		table.Render()
 
 		// readme
-		log.Println("To switch account: Use: alti-cli account use ID")
+		logging.Infoln("To switch account: Use: alti-cli account switch ID")
 	},
 }
 
-func getActRow(s config.Scope, p config.Profile, activeID string, timeout time.Duration) []string {
+// account is a profile with the state of its api server.
+type account struct {
+	ID           string
+	Endpoint     string
+	Name         string // username or email
+	Status       string
+	Active       bool
+	Sales        bool
+	Super        bool
+	ImageCloud   []string
+	ModelCloud   []string
+	MetaCloud    []string
+	Version      string
+	ResponseTime time.Duration
+	Expiry       time.Time // zero if unknown
+}
+
+// RowString gives a row of string for the table output.
+func (a account) RowString() []string {
+	yes := func(b bool) string {
+		if b {
+			return "Yes"
+		}
+		return ""
+	}
+	active := ""
+	if a.Active {
+		active = "Active"
+	}
+	expiry := ""
+	if !a.Expiry.IsZero() {
+		expiry = a.Expiry.Format("2006-01-02 15:04")
+	}
+	return []string{
+		a.ID,
+		a.Endpoint,
+		a.Name,
+		a.Status,
+		active,
+		yes(a.Sales),
+		yes(a.Super),
+		strings.Join(a.ImageCloud, ","),
+		strings.Join(a.ModelCloud, ","),
+		strings.Join(a.MetaCloud, ","),
+		a.Version,
+		a.ResponseTime.String(),
+		expiry,
+	}
+}
+
+func getActRow(s config.Scope, p config.Profile, activeID string, timeout time.Duration) account {
 	var mode = gql.CheckSystemModeWithTimeout(s.Endpoint, p.Key, timeout)
 	var info gql.AccountInfo
 	var err error
@@ -88,26 +141,29 @@ func getActRow(s config.Scope, p config.Profile, activeID string, timeout time.D
 		nameOrEmail = p.Email
 	}
 
-	r := []string{p.ID, s.Endpoint, nameOrEmail, mode, "", "", "", "", "", "", "", ""}
-	if p.ID == activeID {
-		r[4] = "Active"
+	var expiry time.Time
+	if p.Expiry > 0 {
+		expiry = time.Unix(p.Expiry, 0)
 	}
-	if info.Sales {
-		r[5] = "Yes"
-	}
-	if info.Super {
-		r[6] = "Yes"
-	}
-	r[7] = strings.Join(info.ImageCloud, ",")
-	r[8] = strings.Join(info.ModelCloud, ",")
-	r[9] = strings.Join(info.MetaCloud, ",")
-	r[10] = info.Version
-	r[11] = info.ResponseTime.String()
 
-	return r
+	return account{
+		ID:           p.ID,
+		Endpoint:     s.Endpoint,
+		Name:         nameOrEmail,
+		Status:       mode,
+		Active:       p.ID == activeID,
+		Sales:        info.Sales,
+		Super:        info.Super,
+		ImageCloud:   info.ImageCloud,
+		ModelCloud:   info.ModelCloud,
+		MetaCloud:    info.MetaCloud,
+		Version:      info.Version,
+		ResponseTime: info.ResponseTime,
+		Expiry:       expiry,
+	}
 }
 
-type byEndpoint [][]string
+type byEndpoint []account
 
 func (e byEndpoint) Len() int {
 	return len(e)
@@ -118,10 +174,11 @@ func (e byEndpoint) Swap(i, j int) {
 }
 
 func (e byEndpoint) Less(i, j int) bool {
-	return e[i][1]+e[i][0] < e[j][1]+e[j][0]
+	return e[i].Endpoint+e[i].ID < e[j].Endpoint+e[j].ID
 }
 
 func init() {
 	rootCmd.AddCommand(accountCmd)
 	accountCmd.Flags().IntVarP(&actTimeout, "timeout", "t", 3, "Timeout of checking api server state in seconds")
+	accountCmd.Flags().StringVar(&outputTemplate, "template", outputTemplate, "Go template of each account, e.g. '{{.ID}} {{.Endpoint}} {{.Status}}'")
 }
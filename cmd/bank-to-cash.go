@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
@@ -20,13 +19,11 @@ var toCashCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		currency, err := service.SuggestCurrency(currency)
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 		cash, err := gql.CoinsToMoney(coins, currency)
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 		fmt.Printf("%.2f coins could be bought by %s%.2f\n", coins, currency, cash)
 	},
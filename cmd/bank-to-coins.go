@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
@@ -21,13 +20,11 @@ var toCoinsCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		cur, err := service.SuggestCurrency(currency)
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 		coins, err := gql.MoneyToCoins(cash, cur)
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 		fmt.Printf("%s%.2f could buy %.2f coins\n", cur, cash, coins)
 	},
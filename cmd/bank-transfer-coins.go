@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
@@ -26,15 +26,13 @@ var transferCoinsCmd = &cobra.Command{
 			service.CheckAPIServer(),
 			service.CheckBalance(coins),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		// current balance
 		_, myself, err := gql.MySelf()
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 		var ans string
 		fmt.Printf("You have %.2f coins. Are you sure to transfer %.2f coins to %q? (Y/N): ", myself.Balance, coins, email)
@@ -44,22 +42,20 @@ var transferCoinsCmd = &cobra.Command{
 			fmt.Scanln(&ans)
 			ans = strings.ToUpper(ans)
 			if ans != "Y" && ans != service.Yes {
-				log.Println("Cancelled.")
+				logging.Infoln("Cancelled.")
 				return
 			}
 		}
 
 		_, err = gql.TransferCoins(coins, email, message)
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		// balnce after transaction
 		_, myself, err = gql.MySelf()
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		fmt.Printf("Successfully transferred %.2f coins to %q\nCurrent balnce: %.2f coins", coins, email, myself.Balance)
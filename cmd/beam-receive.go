@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/web"
+	"github.com/spf13/cobra"
+)
+
+var beamOut string
+
+// beamReceiveCmd represents the beam receive command
+var beamReceiveCmd = &cobra.Command{
+	Use:   "receive URL",
+	Short: "Receive the image directory served by 'beam send'",
+	Long:  "Download the files served by 'alti-cli beam send' at URL and verify their sha1. The received files are skipped and the interrupted downloads are resumed by running the same command again.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		baseURL := args[0]
+		idx, err := web.FetchBeamIndex(baseURL)
+		if err != nil {
+			logging.Errorf("Could not get the files from %q: %v\n", baseURL, err)
+			errors.Exit(err)
+		}
+		if beamOut == "" {
+			beamOut = filepath.Base(idx.Name)
+			if beamOut == "." || beamOut == ".." || beamOut == string(filepath.Separator) {
+				beamOut = "beam"
+			}
+		}
+
+		// a. plan
+		var todo []cloud.DownloadItem
+		var need int64
+		for _, f := range idx.Files {
+			p, err := web.BeamTarget(beamOut, f)
+			if err != nil {
+				logging.Errorln(err)
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			if beamReceived(p, f) {
+				if verbose {
+					logging.Infof("Skipped received %q\n", f.Path)
+				}
+				continue
+			}
+			errors.Must(file.EnsureDir(filepath.Dir(p), 0755))
+			todo = append(todo, cloud.DownloadItem{
+				URL:  web.BeamURL(baseURL, f),
+				Path: p,
+				Size: f.Size,
+				SHA1: f.SHA1,
+			})
+			need += f.Size
+		}
+		skipped := len(idx.Files) - len(todo)
+		if len(todo) == 0 {
+			logging.Infof("All %d file(s) are already received in %q\n", skipped, beamOut)
+			return
+		}
+		checkDiskSpace(beamOut, need)
+		logging.Infof("Receiving %d file(s), %s into %q, skipped %d received\n", len(todo), humanize.IBytes(uint64(need)), beamOut, skipped)
+
+		// b. download
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+		pr := service.NewProgressReporter(len(todo), need)
+		items := make(chan cloud.DownloadItem)
+		res := make(chan cloud.DownloadItem)
+		done := make(chan struct{})
+		defer close(done)
+		downloader := cloud.Downloader{
+			Items:    items,
+			Done:     done,
+			Ctx:      ctx,
+			Result:   res,
+			Retry:    3,
+			Verbose:  verbose,
+			Resume:   true,
+			Progress: pr,
+		}
+		threads := downloader.Run(thread)
+		logging.Debugf("Receiving in %d thread(s)...", threads)
+		go func() {
+			for _, it := range todo {
+				items <- it
+			}
+			close(items)
+		}()
+
+		var failed []cloud.DownloadItem
+		for r := range res {
+			if r.Error != nil {
+				failed = append(failed, r)
+			}
+		}
+		pr.Close()
+
+		// c. summary
+		if len(failed) > 0 {
+			if ctx.Err() != nil {
+				logging.Infof("Received %d of %d file(s) into %q\n", len(todo)-len(failed), len(todo), beamOut)
+				return
+			}
+			for _, r := range failed {
+				logging.Errorf("Failed to receive %q: %v\n", r.Path, r.Error)
+			}
+			logging.Infoln("Run the same command again to resume.")
+			errors.Exit(errors.ErrDownloadFailed)
+		}
+		logging.Infof("Received %d file(s) into %q\n", len(todo), beamOut)
+	},
+}
+
+// beamReceived checks if f is already received in path p, by its size and sha1.
+func beamReceived(p string, f web.BeamFile) bool {
+	size, err := file.Filesize(p)
+	if err != nil || size != f.Size {
+		return false
+	}
+	sum, err := file.Sha1sum(p)
+	return err == nil && file.VerifyChecksum(p, sum, f.SHA1) == nil
+}
+
+func init() {
+	beamCmd.AddCommand(beamReceiveCmd)
+	beamReceiveCmd.Flags().StringVarP(&beamOut, "out", "o", beamOut, "Directory to receive into, default is the name of the sent directory")
+	beamReceiveCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of concurrent downloads, default is number of cores")
+	beamReceiveCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info")
+}
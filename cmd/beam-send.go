@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/rand"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/web"
+	"github.com/spf13/cobra"
+)
+
+// beamSendCmd represents the beam send command
+var beamSendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Serve an image directory to 'beam receive' on the same LAN",
+	Long:  "Serve the files of a directory with their sha1 to another alti-cli on the same LAN until ctrl+c, e.g. from a field laptop to an office workstation. Run the printed 'alti-cli beam receive' command on the other machine.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := service.Check(
+			nil,
+			service.CheckDir(dir),
+		); err != nil {
+			errors.Exit(err)
+		}
+		f := pathFilter()
+
+		ctx, cancel := interruptContext()
+		defer cancel()
+		paths, errc := file.WalkFilesBy(ctx, dir, f)
+		if listOnly {
+			listPaths(paths, errc)
+			return
+		}
+
+		// a. index
+		logging.Infof("Indexing %q...\n", dir)
+		idx, err := beamIndex(dir, paths, errc, thread)
+		if ctx.Err() != nil {
+			logging.Infoln("Bye!")
+			return
+		}
+		errors.Must(err)
+		if len(idx.Files) == 0 {
+			logging.Infoln("No file is found! Bye.")
+			return
+		}
+
+		// b. serve
+		if ip == "" {
+			if ip, err = web.GetOutboundIP(); err != nil {
+				logging.Warnf("Could not get the LAN ip, serving at %s: %v\n", ip, err)
+			}
+		}
+		token, err := rand.String(16)
+		errors.Must(err)
+		s := web.Server{
+			Directory: dir,
+			Address:   ip + ":" + port,
+			Token:     token,
+			Handlers:  map[string]http.Handler{web.BeamIndexPath: idx},
+		}
+		hs, p, err := s.ServeStatic(verbose)
+		errors.Must(err)
+
+		logging.Infof("Serving %d file(s), %s\n", len(idx.Files), humanize.IBytes(uint64(idx.TotalSize())))
+		logging.Infoln("Receive them on the other machine by:")
+		fmt.Printf("\n  alti-cli beam receive %s://%s:%d/%s\n\n", s.Scheme(), ip, p, token)
+		logging.Infoln("Press ctrl+c to stop.")
+
+		<-ctx.Done()
+		errors.Must(hs.Shutdown(context.TODO()))
+		logging.Infoln("Bye!")
+	},
+}
+
+// beamIndex digests the walked paths of dir into the beam index by n
+// goroutines. The unreadable files are skipped with warning.
+func beamIndex(dir string, paths <-chan string, errc <-chan error, n int) (*web.BeamIndex, error) {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	idx := web.BeamIndex{Name: filepath.Base(filepath.Clean(dir))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			for p := range paths {
+				bf, err := beamFile(dir, p)
+				if err != nil {
+					logging.Warnf("Skipped %q: %v\n", p, err)
+					continue
+				}
+				if verbose {
+					logging.Infof("Indexed %q\n", bf.Path)
+				}
+				mu.Lock()
+				idx.Files = append(idx.Files, bf)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+	sort.Slice(idx.Files, func(i, j int) bool {
+		return idx.Files[i].Path < idx.Files[j].Path
+	})
+	return &idx, nil
+}
+
+// beamFile gives the relative path, size and sha1 of file p in dir.
+func beamFile(dir, p string) (web.BeamFile, error) {
+	var bf web.BeamFile
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return bf, err
+	}
+	size, err := file.Filesize(p)
+	if err != nil {
+		return bf, err
+	}
+	sum, err := file.Sha1sum(p)
+	if err != nil {
+		return bf, err
+	}
+	return web.BeamFile{Path: filepath.ToSlash(rel), Size: size, SHA1: sum}, nil
+}
+
+func init() {
+	beamCmd.AddCommand(beamSendCmd)
+	beamSendCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory path")
+	beamSendCmd.Flags().StringVar(&ip, "ip", ip, "IP address to serve at, default is the LAN ip")
+	beamSendCmd.Flags().StringVar(&port, "port", port, "Port to serve at, default is a random port")
+	beamSendCmd.Flags().StringVarP(&skip, "skip", "s", skip, "Regular expression to skip paths")
+	beamSendCmd.Flags().StringArrayVar(&includes, "include", includes, "Glob of paths to include, e.g. '*.jpg' or 'raw/**', or a regular expression prefixed by 're:'; repeatable")
+	beamSendCmd.Flags().StringArrayVar(&excludes, "exclude", excludes, "Glob of paths to exclude, taking precedence over --include; repeatable")
+	beamSendCmd.Flags().BoolVar(&withHidden, "hidden", withHidden, "Include the dotfiles and OS junk files, e.g. Thumbs.db and __MACOSX")
+	beamSendCmd.Flags().BoolVar(&listOnly, "list-only", listOnly, "List the paths that would be processed, without processing them")
+	beamSendCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads of computing sha1, default is number of cores")
+	beamSendCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info")
+	errors.Must(beamSendCmd.MarkFlagRequired("dir"))
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// beamCmd represents the beam command
+var beamCmd = &cobra.Command{
+	Use:   "beam",
+	Short: "Transfer an image directory between two machines on the same LAN",
+	Long:  `'alti-cli beam send -d DIR' on one machine, then 'alti-cli beam receive URL' on the other`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("See alti-cli help beam")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(beamCmd)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/spf13/cobra"
+)
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear the cached image digests, server capabilities and image copies",
+	Long:  "Remove the cached checksums and dimensions of local images, so that all images are digested again, the cached capabilities of the api servers, and the upright, converted and downsized copies of the images.",
+	Run: func(cmd *cobra.Command, args []string) {
+		errors.Must(db.ClearDigestCache())
+		errors.Must(gql.ClearCapabilities())
+		errors.Must(os.RemoveAll(copiesDir("upright")))
+		errors.Must(os.RemoveAll(copiesDir("raw")))
+		errors.Must(os.RemoveAll(copiesDir("resized")))
+		fmt.Println("Cache is cleared!")
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Root command for all local cache related commands",
+	Long:  `'alti-cli cache clear' to remove the cached image digests`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("See alti-cli help cache")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+}
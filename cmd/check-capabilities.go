@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/spf13/cobra"
+)
+
+var refreshCapabilities bool
+
+// checkCapabilitiesCmd represents the check capabilities command
+var checkCapabilitiesCmd = &cobra.Command{
+	Use:   "capabilities",
+	Short: "Show the capabilities of the api server",
+	Long: `Show the version, the supported clouds, the max file sizes and the optional features of the api server of the active profile.
+They are cached per profile for an hour, and used to hide the unsupported upload methods and skip the unsupported features.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := service.Check(nil, service.CheckAPIServerLite()); err != nil {
+			errors.Exit(err)
+		}
+		c, err := gql.QueryCapabilities(refreshCapabilities)
+		errors.Must(err)
+
+		fmt.Printf("Endpoint: %s\n", c.Endpoint)
+		fmt.Printf("Version: %s\n", c.Version)
+		fmt.Printf("Checked at: %s\n", c.Time.Format("2006-01-02 15:04:05"))
+
+		table := newTable()
+		table.SetHeader([]string{"Kind", "Clouds", "Max File Size"})
+		for _, k := range []string{"image", "model", "meta"} {
+			max := "unlimited"
+			if s := c.MaxFileSize(k); s > 0 {
+				max = humanize.IBytes(uint64(s))
+			}
+			table.Append([]string{k, strings.Join(c.Clouds[k], ", "), max})
+		}
+		table.Render()
+
+		switch {
+		case c.Features == nil:
+			fmt.Println("Features: unknown, all are assumed to be supported")
+		case len(c.Features) == 0:
+			fmt.Println("Features: none")
+		default:
+			fmt.Printf("Features: %s\n", strings.Join(c.Features, ", "))
+		}
+	},
+}
+
+func init() {
+	checkCmd.AddCommand(checkCapabilitiesCmd)
+	checkCapabilitiesCmd.Flags().BoolVar(&refreshCapabilities, "refresh", refreshCapabilities, "Query the api server again instead of using the cache")
+}
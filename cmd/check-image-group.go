@@ -1,18 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
 	"github.com/jackytck/alti-cli/text"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
@@ -26,11 +27,11 @@ var checkImageGroupCmd = &cobra.Command{
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
-		log.Printf("Checking %s...\n", dir)
+		logging.Infof("Checking %s...\n", dir)
 
 		// pre-checks general
 		groupPath := path.Join(dir, "group.txt")
@@ -39,8 +40,7 @@ var checkImageGroupCmd = &cobra.Command{
 			service.CheckDir(dir),
 			service.CheckFile(groupPath),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		// a. read group.txt
@@ -49,38 +49,38 @@ var checkImageGroupCmd = &cobra.Command{
 
 		// b. read images
 		var undefined []file.ImageDigest
+		scanned := make(map[string]bool)
 
-		done := make(chan struct{})
-		defer close(done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 
-		paths, errc := file.WalkFiles(done, dir, skip)
+		paths, errc := file.WalkFilesBy(ctx, dir, pathFilter())
 		result := make(chan file.ImageDigest)
 
 		digester := file.ImageDigester{
 			Root:      dir,
-			Done:      done,
+			Ctx:       ctx,
 			Paths:     paths,
 			Result:    result,
 			LightWork: true,
 		}
 		threads := digester.Run(thread)
-		if verbose {
-			log.Printf("Working in %d thread(s)...", threads)
-		}
+		logging.Debugf("Working in %d thread(s)...", threads)
 
-		table := tablewriter.NewWriter(os.Stdout)
+		table := newTable()
 		table.SetHeader([]string{"Undefined Filename"})
 
 		for r := range result {
 			if !r.IsImage {
 				continue
 			}
+			scanned[r.Filename] = true
 			if _, ok := group[r.Filename]; ok {
 				continue
 			}
 
 			if verbose {
-				log.Printf("Path: %q, URL: %q, Filename: %q\n", r.Path, r.URL, r.Filename)
+				logging.Infof("Path: %q, URL: %q, Filename: %q\n", r.Path, r.URL, r.Filename)
 			}
 
 			if printTable {
@@ -99,15 +99,18 @@ var checkImageGroupCmd = &cobra.Command{
 		}
 
 		// c. display results
+		if missing := missingGroupImages(group, scanned); len(missing) > 0 {
+			logging.Warnf("%d image(s) of group.txt are not found: %s\n", len(missing), strings.Join(missing, ", "))
+		}
 		totalImg := len(undefined)
 		plural := ""
 		if totalImg > 1 {
 			plural = "s"
 		}
 		if totalImg > 0 {
-			log.Printf("Found %d undefined image%s\n", totalImg, plural)
+			logging.Infof("Found %d undefined image%s\n", totalImg, plural)
 		} else {
-			log.Println("No image outside group.txt is found!")
+			logging.Infoln("No image outside group.txt is found!")
 		}
 
 		if printTable {
@@ -124,7 +127,7 @@ var checkImageGroupCmd = &cobra.Command{
 			fmt.Scanln(&ans)
 			ans = strings.ToUpper(ans)
 			if ans != "Y" && ans != service.Yes {
-				log.Println("Cancelled.")
+				logging.Infoln("Cancelled.")
 				return
 			}
 		}
@@ -138,7 +141,7 @@ var checkImageGroupCmd = &cobra.Command{
 			errors.Must(err)
 		}
 
-		log.Println("Done")
+		logging.Infoln("Done")
 	},
 }
 
@@ -157,10 +160,67 @@ func readGroupTxt(p string) (map[string]bool, error) {
 	return text.SliceToMap(s, true), nil
 }
 
+// writeGroup writes the group.txt of the images into path, grouped by their
+// top-level subdirectories, and validates that every image of it is scanned.
+func writeGroup(path string, imgs []file.ImageDigest) {
+	groups, err := file.GroupBySubdir(imgs)
+	if err != nil {
+		logging.Errorln("group.txt could not be generated:", err)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	if len(groups) == 0 {
+		logging.Infoln("No image is found for group.txt!")
+		return
+	}
+	f, err := os.Create(path)
+	errors.Must(err)
+	err = file.WriteGroup(f, groups)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	errors.Must(err)
+
+	// validate
+	group, err := readGroupTxt(path)
+	errors.Must(err)
+	scanned := make(map[string]bool)
+	for _, img := range imgs {
+		scanned[img.Filename] = true
+	}
+	if missing := missingGroupImages(group, scanned); len(missing) > 0 {
+		logging.Errorf("Invalid %q, images not found: %s\n", path, strings.Join(missing, ", "))
+		errors.Exit(errors.ErrInvalidInput)
+	}
+
+	for _, g := range groups {
+		name := g.Name
+		if name == "" {
+			name = "."
+		}
+		logging.Infof("Group %d: %q, %d image(s)\n", g.ID, name, len(g.Images))
+	}
+	logging.Infof("Wrote %d groups of %d images into %q\n", len(groups), len(group), path)
+}
+
+// missingGroupImages gives the images of group that are not scanned, sorted.
+func missingGroupImages(group, scanned map[string]bool) []string {
+	var ret []string
+	for f := range group {
+		if f != "" && !scanned[f] {
+			ret = append(ret, f)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
 func init() {
 	checkCmd.AddCommand(checkImageGroupCmd)
 	checkImageGroupCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory path")
 	checkImageGroupCmd.Flags().StringVarP(&skip, "skip", "s", skip, "Regular expression to skip paths")
+	checkImageGroupCmd.Flags().StringArrayVar(&includes, "include", includes, "Glob of paths to include, e.g. '*.jpg' or 'raw/**', or a regular expression prefixed by 're:'; repeatable")
+	checkImageGroupCmd.Flags().StringArrayVar(&excludes, "exclude", excludes, "Glob of paths to exclude, taking precedence over --include; repeatable")
+	checkImageGroupCmd.Flags().BoolVar(&withHidden, "hidden", withHidden, "Include the dotfiles and OS junk files, e.g. Thumbs.db and __MACOSX")
 	checkImageGroupCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display individual image info")
 	checkImageGroupCmd.Flags().BoolVarP(&printTable, "table", "t", printTable, "Output all of the found images in table format")
 	checkImageGroupCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads to process, default is number of cores x 4")
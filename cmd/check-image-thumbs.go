@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
+)
+
+var thumbsDir string
+var thumbSize = file.DefaultThumbSize
+var thumbsHTML bool
+
+// contactSheet is the data of the html index of the thumbnails.
+type contactSheet struct {
+	Dir    string
+	Size   int
+	Thumbs []file.ImageDigest
+}
+
+// excludeThumbs excludes thumbsDir from f if it is under dir, so that the
+// thumbnails are not digested as images.
+func excludeThumbs(f *file.PathFilter) *file.PathFilter {
+	if thumbsDir == "" {
+		return f
+	}
+	root, err := filepath.Abs(dir)
+	errors.Must(err)
+	td, err := filepath.Abs(thumbsDir)
+	errors.Must(err)
+	rel, err := filepath.Rel(root, td)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return f
+	}
+	if rel == "." {
+		logging.Errorln("--thumbs must not be the checked directory")
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	r := regexp.MustCompile(`^` + regexp.QuoteMeta(filepath.ToSlash(rel)) + `(/|$)`)
+	f.Exclude = append(f.Exclude, r)
+	return f
+}
+
+// writeContactSheet writes the index.html of the thumbnails of imgs into
+// thumbsDir, sorted by their paths.
+func writeContactSheet(root string, imgs []file.ImageDigest) {
+	sort.Slice(imgs, func(i, j int) bool {
+		return imgs[i].Path < imgs[j].Path
+	})
+	path := filepath.Join(thumbsDir, "index.html")
+	f, err := os.Create(path)
+	errors.Must(err)
+	defer f.Close()
+
+	errors.Must(thumbsTmpl.Execute(f, contactSheet{Dir: root, Size: thumbSize, Thumbs: imgs}))
+	logging.Infof("Wrote the contact sheet of %d images into %q", len(imgs), path)
+}
+
+var thumbsTmpl = template.Must(template.New("thumbs").Funcs(template.FuncMap{
+	"base": filepath.Base,
+	"join": strings.Join,
+	"mb":   file.BytesToMB,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Dir}} - Contact Sheet</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+.sheet { display: flex; flex-wrap: wrap; gap: 8px; }
+figure { margin: 0; width: {{.Size}}px; font-size: 12px; }
+figure img { max-width: 100%; display: block; }
+figcaption { overflow-wrap: anywhere; }
+.issues { color: #c00; }
+</style>
+</head>
+<body>
+<h1>{{.Dir}}</h1>
+<p>{{len .Thumbs}} image(s)</p>
+<div class="sheet">
+{{range .Thumbs}}<figure>
+<img src="{{base .Thumb}}" alt="{{.Filename}}" loading="lazy">
+<figcaption title="{{.Path}}">{{.Filename}}<br>{{.Width}} x {{.Height}}, {{printf "%.2f" (mb .Filesize)}} MB{{if .Issues}}<br><span class="issues">{{join .Issues ", "}}</span>{{end}}</figcaption>
+</figure>
+{{end}}</div>
+</body>
+</html>
+`))
@@ -1,24 +1,40 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/c2h5oh/datasize"
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
-	"github.com/olekukonko/tablewriter"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
 
 var dir string
 var skip string
+var includes []string
+var excludes []string
+var excludeFrom string
+var withHidden bool
+var listOnly bool
 var verbose bool
 var printTable bool
 var thread = -1
+var genPose string
+var genGroup string
+var noCache bool
+var checkQuality bool
+var checksumAlgo = file.ChecksumSHA1
+var dupesMode string
+var dupesList = "dupes.txt"
+var imageReport string
 
 // checkImageCmd represents the checkImage command
 var checkImageCmd = &cobra.Command{
@@ -31,57 +47,141 @@ of all images of a given directory.`,
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
-		log.Printf("Checking %s...\n", dir)
+		checkChecksumAlgo()
+		if thumbSize <= 0 {
+			logging.Errorf("Invalid thumbnail size: %d\n", thumbSize)
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		if thumbsHTML && thumbsDir == "" {
+			logging.Errorln("--thumbs-html requires --thumbs")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		dupesAct, dupesDir := parseDupesMode()
+		var reportFormat string
+		if imageReport != "" {
+			f, err := file.ReportFormat(imageReport)
+			if err != nil {
+				logging.Errorln(err)
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			reportFormat = f
+		}
+		logging.Infof("Checking %s...\n", dir)
 
 		var totalGP float64
-		var totalImg int
+		var totalImg, lowQualityCnt int
 		var totalByte datasize.ByteSize
 
-		done := make(chan struct{})
-		defer close(done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 
-		paths, errc := file.WalkFiles(done, dir, skip)
+		if listOnly {
+			listPaths(file.WalkArchivesBy(ctx, dir, excludeThumbs(pathFilter())))
+			return
+		}
+
+		paths, errc := file.WalkArchivesBy(ctx, dir, excludeThumbs(pathFilter()))
+		defer file.CloseArchives()
 		result := make(chan file.ImageDigest)
 
+		cache := openDigestCache()
+		if cache != nil {
+			defer cache.Close()
+		}
+
+		var qf *file.QualityFilter
+		if checkQuality {
+			qf = &file.DefaultQualityFilter
+		}
 		digester := file.ImageDigester{
-			Root:   dir,
-			Done:   done,
-			Paths:  paths,
-			Result: result,
+			Root:      imageRoot(dir),
+			WithExif:  genPose != "",
+			Checksum:  checksumAlgo,
+			Quality:   qf,
+			Cache:     cache,
+			ThumbDir:  thumbsDir,
+			ThumbSize: thumbSize,
+			Ctx:       ctx,
+			Paths:     paths,
+			Result:    result,
 		}
 		threads := digester.Run(thread)
-		if verbose {
-			log.Printf("Working in %d thread(s)...", threads)
+		logging.Debugf("Working in %d thread(s)...", threads)
+
+		var rep *file.DigestReport
+		if imageReport != "" {
+			out, err := os.Create(imageReport)
+			errors.Must(err)
+			defer out.Close()
+			rep, err = file.NewDigestReport(out, reportFormat)
+			errors.Must(err)
 		}
 
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Filename", "Dimension", "GP", "Size (MB)", "Checksum"})
+		table := newTable()
+		header := []string{"Filename", "Dimension", "GP", "Size (MB)", "Checksum"}
+		if checkQuality {
+			header = append(header, "Issues")
+		}
+		table.SetHeader(header)
 
+		var imgs, thumbs, all []file.ImageDigest
+		var noThumbCnt int
 		for r := range result {
+			if rep != nil {
+				errors.Must(rep.Write(r))
+			}
 			if r.Error != nil {
-				log.Printf("Invalid image: %q, Reason: %v", r.Path, r.Error)
+				logging.Warnf("Invalid image: %q, Reason: %v", r.Path, r.Error)
 				continue
 			}
 
 			mb := file.BytesToMB(r.Filesize)
 			if verbose {
-				log.Printf("Path: %q, URL: %q, Filename: %q, Dimension: %d x %d, GP: %.2f, Type: %s, Size: %.2f MB, Checksum: %s\n",
-					r.Path, r.URL, r.Filename, r.Width, r.Height, r.GP, r.Filetype, mb, r.SHA1)
+				logging.Infof("Path: %q, URL: %q, Filename: %q, Dimension: %d x %d, GP: %.2f, Type: %s, Size: %.2f MB, Checksum: %s\n",
+					r.Path, r.URL, r.Filename, r.Width, r.Height, r.GP, r.Filetype, mb, r.Checksum)
+				if e := r.Exif; e != nil {
+					logging.Infof("EXIF: %q, GPS: %v, Lat: %f, Lng: %f, Alt: %.2f, Focal length: %.2f mm, Time: %v\n",
+						r.Filename, e.HasGPS, e.Lat, e.Lng, e.Alt, e.FocalLength, e.Time)
+				}
+				if q := r.Quality; q != nil {
+					logging.Infof("Quality: %q, Sharpness: %.2f, Brightness: %.2f\n", r.Filename, q.Sharpness, q.Brightness)
+				}
+			}
+			if len(r.Issues) > 0 {
+				logging.Warnf("Low quality image: %q, Issues: %s", r.Path, strings.Join(r.Issues, ", "))
+				lowQualityCnt++
+			}
+			if genPose != "" || genGroup != "" {
+				imgs = append(imgs, r)
+			}
+			if dupesAct != "" {
+				all = append(all, r)
+			}
+			if thumbsDir != "" {
+				if r.Thumb == "" {
+					logging.Warnf("No thumbnail: %q", r.Path)
+					noThumbCnt++
+				} else {
+					thumbs = append(thumbs, r)
+				}
 			}
 
 			if printTable {
-				r := []string{
+				row := []string{
 					fmt.Sprintf("%q", r.Filename),
 					fmt.Sprintf("%d x %d", r.Width, r.Height),
 					fmt.Sprintf("%.2f", r.GP),
 					fmt.Sprintf("%.2f", mb),
-					r.SHA1,
+					r.Checksum,
 				}
-				table.Append(r)
+				if checkQuality {
+					row = append(row, strings.Join(r.Issues, ", "))
+				}
+				table.Append(row)
 			}
 
 			totalGP += r.GP
@@ -93,30 +193,225 @@ of all images of a given directory.`,
 		if err := <-errc; err != nil {
 			panic(err)
 		}
+		if rep != nil {
+			errors.Must(rep.Close())
+			logging.Infof("Wrote the properties of %d files into %q", rep.Len(), imageReport)
+		}
 
 		usd, err := gql.CoinsToMoney(totalGP, "USD")
 		if err != nil {
 			panic(err)
 		}
 		if totalImg > 0 {
-			log.Printf("Found %d images, total %.2f GP, %s, USD $%.2f", totalImg, totalGP, totalByte.HumanReadable(), usd)
+			logging.Infof("Found %d images, total %.2f GP, %s, USD $%.2f", totalImg, totalGP, totalByte.HumanReadable(), usd)
 		} else {
-			log.Println("No image is found!")
+			logging.Infoln("No image is found!")
+		}
+		if checkQuality {
+			logging.Infof("%d out of %d images are of low quality", lowQualityCnt, totalImg)
+		}
+
+		if genPose != "" {
+			writePose(genPose, imgs)
+		}
+		if genGroup != "" {
+			writeGroup(genGroup, imgs)
+		}
+		if thumbsDir != "" {
+			logging.Infof("Wrote %d thumbnails into %q", len(thumbs), thumbsDir)
+			if noThumbCnt > 0 {
+				logging.Infof("%d images could not be decoded for thumbnails", noThumbCnt)
+			}
+			if thumbsHTML {
+				writeContactSheet(dir, thumbs)
+			}
+		}
+
+		if dupesAct != "" {
+			handleDupes(file.FindDupes(all), dupesAct, dupesDir)
 		}
 
 		if printTable {
-			table.SetFooter([]string{fmt.Sprintf("%d image(s)", totalImg), fmt.Sprintf("USD $%.2f", usd), fmt.Sprintf("%.2f GP", totalGP), totalByte.HumanReadable(), `\ (•◡•) /`})
+			footer := []string{fmt.Sprintf("%d image(s)", totalImg), fmt.Sprintf("USD $%.2f", usd), fmt.Sprintf("%.2f GP", totalGP), totalByte.HumanReadable(), `\ (•◡•) /`}
+			if checkQuality {
+				footer = append(footer, fmt.Sprintf("%d low quality", lowQualityCnt))
+			}
+			table.SetFooter(footer)
 			table.Render()
 		}
 	},
 }
 
+// Actions of '--dupes'.
+const (
+	dupesReport = "report"
+	dupesSkip   = "skip"
+	dupesMove   = "move"
+	dupesDelete = "delete"
+)
+
+// parseDupesMode parses '--dupes' into its action and the directory of
+// 'move=dir'. Exit if it is invalid.
+func parseDupesMode() (string, string) {
+	if dupesMode == "" {
+		return "", ""
+	}
+	act, moveDir := dupesMode, ""
+	if i := strings.Index(dupesMode, "="); i >= 0 {
+		act, moveDir = dupesMode[:i], dupesMode[i+1:]
+	}
+	act = strings.ToLower(act)
+	valid := act == dupesReport || act == dupesSkip || act == dupesDelete
+	if act == dupesMove {
+		valid = moveDir != ""
+	} else if moveDir != "" {
+		valid = false
+	}
+	if !valid {
+		logging.Errorf("Invalid --dupes: %q, valid modes are: 'report', 'skip', 'move=dir' or 'delete'\n", dupesMode)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	return act, moveDir
+}
+
+// handleDupes prints the clusters of the duplicates, and writes their paths
+// to '--dupes-list', moves them into moveDir or deletes them by act. The
+// first image of each cluster is kept.
+func handleDupes(clusters []file.Dupes, act, moveDir string) {
+	if len(clusters) == 0 {
+		logging.Infoln("No duplicate is found!")
+		return
+	}
+	root := imageRoot(dir)
+	table := newTable()
+	table.SetHeader([]string{"Checksum", "Kept", "Duplicate", "Size (MB)"})
+	var dupes []string
+	var wasted int64
+	for _, c := range clusters {
+		for i, img := range c[1:] {
+			sum, kept := "", ""
+			if i == 0 {
+				sum, kept = c[0].Checksum, c[0].Path
+			}
+			table.Append([]string{sum, kept, img.Path, fmt.Sprintf("%.2f", file.BytesToMB(img.Filesize))})
+			dupes = append(dupes, img.Path)
+			wasted += img.Filesize
+		}
+	}
+	table.Render()
+	logging.Infof("Found %d duplicates of %d images, %s in total", len(dupes), len(clusters), datasize.ByteSize(wasted).HumanReadable())
+
+	switch act {
+	case dupesSkip:
+		f, err := os.Create(dupesList)
+		errors.Must(err)
+		defer f.Close()
+		errors.Must(file.WritePathList(f, root, dupes))
+		logging.Infof("Paths of the duplicates are written to %q, exclude them by '--exclude-from %s' with the same '--dir'\n", dupesList, dupesList)
+	case dupesMove:
+		var n int
+		for _, p := range dupes {
+			dst := filepath.Join(moveDir, filepath.FromSlash(relPath(root, p)))
+			if err := moveDupe(p, dst); err != nil {
+				logging.Warnf("Failed to move %q: %v\n", p, err)
+				continue
+			}
+			n++
+		}
+		logging.Infof("Moved %d out of %d duplicates into %q\n", n, len(dupes), moveDir)
+	case dupesDelete:
+		fmt.Printf("Delete the %d duplicates or not? (Y/N): ", len(dupes))
+		if assumeYes {
+			fmt.Println("Yes")
+		} else {
+			var ans string
+			fmt.Scanln(&ans)
+			ans = strings.ToUpper(ans)
+			if ans != "Y" && ans != service.Yes {
+				logging.Infoln("Cancelled.")
+				return
+			}
+		}
+		var n int
+		for _, p := range dupes {
+			if _, _, ok := file.SplitArchivePath(p); ok {
+				logging.Warnf("Failed to delete %q: in a zip archive\n", p)
+				continue
+			}
+			if err := os.Remove(p); err != nil {
+				logging.Warnf("Failed to delete %q: %v\n", p, err)
+				continue
+			}
+			n++
+		}
+		logging.Infof("Deleted %d out of %d duplicates\n", n, len(dupes))
+	}
+}
+
+// relPath gives the path of p relative to root, p itself if not under root.
+func relPath(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return p
+	}
+	return rel
+}
+
+// moveDupe moves the file p to dst, creating its directory.
+func moveDupe(p, dst string) error {
+	if _, _, ok := file.SplitArchivePath(p); ok {
+		return fmt.Errorf("in a zip archive")
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("%q already exists", dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.Rename(p, dst)
+}
+
+// writePose writes the pose.txt of the geotagged images into path.
+func writePose(path string, imgs []file.ImageDigest) {
+	f, err := os.Create(path)
+	errors.Must(err)
+	defer f.Close()
+
+	n, err := file.WritePose(f, imgs)
+	errors.Must(err)
+	if n == 0 {
+		logging.Infoln("No geotagged image is found!")
+		return
+	}
+	logging.Infof("Wrote the GPS of %d images into %q", n, path)
+	if skipped := len(imgs) - n; skipped > 0 {
+		logging.Infof("%d images without GPS are skipped", skipped)
+	}
+}
+
 func init() {
 	checkCmd.AddCommand(checkImageCmd)
-	checkImageCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory path")
+	checkImageCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory or zip path, zips in the directory are also read")
 	checkImageCmd.Flags().StringVarP(&skip, "skip", "s", skip, "Regular expression to skip paths")
+	checkImageCmd.Flags().StringArrayVar(&includes, "include", includes, "Glob of paths to include, e.g. '*.jpg' or 'raw/**', or a regular expression prefixed by 're:'; repeatable")
+	checkImageCmd.Flags().StringArrayVar(&excludes, "exclude", excludes, "Glob of paths to exclude, taking precedence over --include; repeatable")
+	checkImageCmd.Flags().StringVar(&excludeFrom, "exclude-from", excludeFrom, "File of the paths relative to '--dir' to exclude, one per line, e.g. written by 'check image --dupes skip'")
+	checkImageCmd.Flags().BoolVar(&withHidden, "hidden", withHidden, "Include the dotfiles and OS junk files, e.g. Thumbs.db and __MACOSX")
+	checkImageCmd.Flags().BoolVar(&listOnly, "list-only", listOnly, "List the paths that would be processed, without processing them")
 	checkImageCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display individual image info")
 	checkImageCmd.Flags().BoolVarP(&printTable, "table", "t", printTable, "Output all of the found images in table format")
+	checkImageCmd.Flags().StringVar(&imageReport, "report", imageReport, "Write the path, dimension, GP, size, checksum and error of every digested file into this report, in csv or json by its extension")
 	checkImageCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads to process, default is number of cores x 4")
+	checkImageCmd.Flags().BoolVar(&checkQuality, "quality", checkQuality, "Flag the blurred, over/under-exposed or small images")
+	checkImageCmd.Flags().StringVar(&checksumAlgo, "checksum", checksumAlgo, "Checksum algorithm: 'sha1', 'sha256' or 'xxh64' (fastest)")
+	checkImageCmd.Flags().BoolVar(&noCache, "no-cache", noCache, "Digest all images again instead of using the cache of unchanged ones")
+	checkImageCmd.Flags().StringVar(&genPose, "gen-pose", genPose, "Generate pose.txt into this path from the GPS of geotagged images")
+	checkImageCmd.Flags().StringVar(&genGroup, "gen-group", genGroup, "Generate group.txt into this path, grouping the images by their top-level subdirectories")
+	checkImageCmd.Flags().StringVar(&thumbsDir, "thumbs", thumbsDir, "Write the JPEG thumbnails of the images into this directory")
+	checkImageCmd.Flags().IntVar(&thumbSize, "thumb-size", thumbSize, "Longer side of the thumbnails in pixels")
+	checkImageCmd.Flags().BoolVar(&thumbsHTML, "thumbs-html", thumbsHTML, "Also write an index.html contact sheet of the thumbnails")
+	checkImageCmd.Flags().StringVar(&dupesMode, "dupes", dupesMode, "Group the images by checksum and handle the duplicates, keeping the first path of each: 'report', 'skip' (write them to '--dupes-list'), 'move=dir' or 'delete'")
+	checkImageCmd.Flags().StringVar(&dupesList, "dupes-list", dupesList, "Path of the list of the duplicates written by '--dupes skip', for '--exclude-from'")
+	checkImageCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
 	errors.Must(checkImageCmd.MarkFlagRequired("dir"))
 }
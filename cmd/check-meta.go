@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	altimeta "github.com/jackytck/alti-cli/meta"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/spf13/cobra"
+)
+
+// checkMetaCmd represents the check meta command
+var checkMetaCmd = &cobra.Command{
+	Use:   "meta",
+	Short: "Validate the contents of camera.txt, pose.txt and group.txt",
+	Long: `Validate a meta file or all of the meta files of a directory before uploading: the column counts, the numeric ranges,
+the duplicate entries and the sanity of the coordinates. The image names are cross-referenced against the images of '-d' or of the project of '-p'.
+e.g. 'alti-cli check meta -f ~/myimg -d ~/myimg'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		checks := []service.CheckFn{service.CheckFile(meta)}
+		stat, err := os.Stat(meta)
+		isDir := err == nil && stat.IsDir()
+		if isDir {
+			checks = []service.CheckFn{service.CheckDir(meta)}
+		}
+		if dir != "" {
+			checks = append(checks, service.CheckDir(dir))
+		}
+		if id != "" {
+			checks = append(checks, service.CheckAPIServerLite(), service.CheckPID("meta", id))
+		}
+		if err := service.Check(nil, checks...); err != nil {
+			errors.Exit(err)
+		}
+
+		// a. meta files to validate
+		var paths []string
+		if isDir {
+			for _, n := range altimeta.Files {
+				p := filepath.Join(meta, n)
+				if _, err := os.Stat(p); err == nil {
+					paths = append(paths, p)
+				}
+			}
+			if len(paths) == 0 {
+				logging.Errorf("No meta file is found in %q, validated filenames are: %q\n", meta, altimeta.Files)
+				errors.Exit(errors.ErrMetaFilenameInvalid)
+			}
+		} else {
+			n := filepath.Base(meta)
+			if _, ok := text.Contains(altimeta.Files, n); !ok {
+				logging.Errorf("Unknown meta file %q, validated filenames are: %q\n", n, altimeta.Files)
+				errors.Exit(errors.ErrMetaFilenameInvalid)
+			}
+			paths = []string{meta}
+		}
+
+		crs := checkCRS()
+
+		// b. images to cross-reference
+		var images map[string]bool
+		var where string
+		switch {
+		case dir != "":
+			images, where = localImageNames(dir), dir
+		case id != "":
+			p, err := gql.SearchProjectID(id, true)
+			errors.Must(err)
+			id = p.ID
+			images, where = projectImageNames(), fmt.Sprintf("project %q", p.Name)
+		}
+
+		// c. validate
+		table := newTable()
+		table.SetHeader([]string{"File", "Entries", "Errors", "Warnings", "Undefined Images"})
+		var nErr int
+		for _, p := range paths {
+			f, err := os.Open(p)
+			errors.Must(err)
+			var r io.Reader = f
+			if crs != nil && filepath.Base(p) == altimeta.PoseTxt {
+				r, err = altimeta.ReprojectPose(f, crs)
+				errors.Must(err)
+			}
+			rep, err := altimeta.Validate(filepath.Base(p), r)
+			f.Close()
+			errors.Must(err)
+
+			undefined := "n/a"
+			if images != nil {
+				undefined = strconv.Itoa(rep.CrossCheck(images, where))
+			}
+			for _, i := range rep.Issues {
+				fmt.Println(i)
+			}
+			errs := rep.Errors()
+			nErr += errs
+			table.Append([]string{p, strconv.Itoa(len(rep.Names)), strconv.Itoa(errs), strconv.Itoa(len(rep.Issues) - errs), undefined})
+		}
+		table.Render()
+
+		if nErr > 0 {
+			logging.Errorf("%d error(s) are found\n", nErr)
+			errors.Exit(errors.ErrMetaInvalid)
+		}
+		logging.Infoln("Meta files are ready to be imported")
+	},
+}
+
+// localImageNames gives the filenames of the images of root.
+func localImageNames(root string) map[string]bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	paths, errc := file.WalkFilesBy(ctx, root, pathFilter())
+	result := make(chan file.ImageDigest)
+	digester := file.ImageDigester{
+		Root:      root,
+		Ctx:       ctx,
+		Paths:     paths,
+		Result:    result,
+		LightWork: true,
+	}
+	digester.Run(thread)
+
+	ret := make(map[string]bool)
+	for r := range result {
+		if r.IsImage {
+			ret[r.Filename] = true
+		}
+	}
+	errors.Must(<-errc)
+	return ret
+}
+
+// projectImageNames gives the names of the images of the project of '--id'.
+func projectImageNames() map[string]bool {
+	ret := make(map[string]bool)
+	after := ""
+	for {
+		imgs, page, _, err := allImages(context.Background(), pageSize, after)
+		errors.Must(err)
+		for _, img := range imgs {
+			ret[img.Name] = true
+		}
+		if !page.HasNextPage {
+			return ret
+		}
+		after = page.EndCursor
+	}
+}
+
+func init() {
+	checkCmd.AddCommand(checkMetaCmd)
+	checkMetaCmd.Flags().StringVarP(&meta, "file", "f", meta, "Path of camera.txt, pose.txt or group.txt, or a directory of them")
+	checkMetaCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Cross-reference the image names against the images of this directory")
+	checkMetaCmd.Flags().StringVarP(&id, "id", "p", id, "Cross-reference the image names against the images of this (partial) project id")
+	checkMetaCmd.Flags().StringVar(&poseCRS, "crs", poseCRS, "EPSG code of the positions of pose.txt, e.g. EPSG:2326, which are converted to WGS84 before validating")
+	checkMetaCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads of reading the images of '-d', default is number of cores")
+	errors.Must(checkMetaCmd.MarkFlagRequired("file"))
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/spf13/cobra"
+)
+
+// checkModelCmd represents the check model command
+var checkModelCmd = &cobra.Command{
+	Use:   "model",
+	Short: "Check a model file before uploading",
+	Long:  "Check the filename, the vertices and faces, the referenced materials and textures, and the upload size of an OBJ, PLY, FBX or zip model locally.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := service.Check(
+			nil,
+			service.CheckFile(model),
+		); err != nil {
+			errors.Exit(err)
+		}
+		nameErr := service.CheckFilename(model, service.ModelFilenameRegex)(logging.Warnf)
+
+		r, err := file.CheckModel(model)
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"File", "Format", "Size", "Vertices", "Faces", "Materials", "Textures", "Missing"})
+		for _, m := range r.Models {
+			table.Append([]string{
+				m.Path,
+				m.Format,
+				humanize.IBytes(uint64(m.Size)),
+				countString(m.Vertices),
+				countString(m.Faces),
+				strings.Join(m.Materials, "\n"),
+				strings.Join(m.Textures, "\n"),
+				strings.Join(m.Missing, "\n"),
+			})
+		}
+		table.SetFooter([]string{fmt.Sprintf("%d model(s)", len(r.Models)), "", "", "", "", "", "Upload size", humanize.IBytes(uint64(r.Size))})
+		table.Render()
+
+		if !r.IsZip && len(r.Models) > 0 {
+			logging.Infoln("Zip the model with its materials and textures before uploading")
+		}
+		switch {
+		case len(r.Models) == 0:
+			logging.Errorf("No model of %s is found in %q\n", strings.Join(file.ModelFormats, ", "), model)
+			errors.Exit(errors.ErrModelInvalid)
+		case len(r.Missing()) > 0:
+			logging.Errorf("%d referenced file(s) are missing\n", len(r.Missing()))
+			errors.Exit(errors.ErrModelInvalid)
+		case nameErr != nil:
+			errors.Exit(nameErr)
+		}
+		logging.Infof("%q is ready to be imported\n", model)
+	},
+}
+
+// countString gives the count, or "n/a" if it is unknown.
+func countString(n int) string {
+	if n < 0 {
+		return "n/a"
+	}
+	return humanize.Comma(int64(n))
+}
+
+func init() {
+	checkCmd.AddCommand(checkModelCmd)
+	checkModelCmd.Flags().StringVarP(&model, "file", "f", model, "File path of model zip, obj, ply or fbx file")
+	errors.Must(checkModelCmd.MarkFlagRequired("file"))
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/web"
+	"github.com/spf13/cobra"
+)
+
+// checkNetworkCmd represents the check network command
+var checkNetworkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Diagnose the upload paths",
+	Long:  "Check DNS and latency of the api server, visibility for direct upload and latency to each bucket, then recommend the best upload method and bucket.",
+	Run: func(cmd *cobra.Command, args []string) {
+		table := newTable()
+		table.SetHeader([]string{"Check", "Target", "Result"})
+
+		// a. dns and api latency
+		active := config.Load().GetActive()
+		u, err := url.Parse(active.Endpoint)
+		errors.Must(err)
+		start := time.Now()
+		addrs, err := net.LookupHost(u.Hostname())
+		if err != nil {
+			table.Append([]string{"DNS", u.Hostname(), err.Error()})
+		} else {
+			table.Append([]string{"DNS", u.Hostname(), fmt.Sprintf("%s (%s)", strings.Join(addrs, ", "), time.Since(start).Round(time.Millisecond))})
+		}
+		start = time.Now()
+		mode := gql.ActiveSystemMode()
+		table.Append([]string{"API", active.Endpoint, fmt.Sprintf("%s (%s)", mode, time.Since(start).Round(time.Millisecond))})
+
+		// b. direct upload
+		var direct string
+		if ip != "" && port != "" {
+			ok, err := web.CheckVisibilityIPPort(ip, port, verbose)
+			errors.Must(err)
+			table.Append([]string{"Direct", net.JoinHostPort(ip, port), visibleStr(ok)})
+			if ok {
+				direct = net.JoinHostPort(ip, port)
+			}
+		} else {
+			pu, res, err := web.PreferredLocalURL(verbose)
+			if err != nil && err != errors.ErrClientInvisible {
+				panic(err)
+			}
+			for _, v := range res {
+				r := visibleStr(v.Visible)
+				if v.Reason != "" {
+					r += ": " + v.Reason
+				}
+				table.Append([]string{"Direct", v.URL, r})
+			}
+			if pu != nil {
+				direct = pu.Host
+			}
+		}
+
+		// c. bucket latency
+		type bucketRTT struct {
+			cloud  string
+			bucket string
+			rtt    time.Duration
+		}
+		var best *bucketRTT
+		for _, c := range gql.SupportedCloud("", "", "image") {
+			buks, err := gql.BucketList("image", c)
+			if err != nil {
+				continue
+			}
+			for _, b := range buks {
+				target := fmt.Sprintf("%s %s", strings.ToLower(c), b)
+				bu := service.BucketURL(c, b)
+				if bu == "" {
+					table.Append([]string{"Bucket", target, "not measurable"})
+					continue
+				}
+				if verbose {
+					logging.Infof("Pinging %q...\n", bu)
+				}
+				rtt, err := cloud.Ping(bu, 3)
+				if err != nil {
+					table.Append([]string{"Bucket", target, err.Error()})
+					continue
+				}
+				table.Append([]string{"Bucket", target, rtt.Round(time.Millisecond).String()})
+				if best == nil || rtt < best.rtt {
+					best = &bucketRTT{strings.ToLower(c), b, rtt}
+				}
+			}
+		}
+		table.Render()
+
+		// d. recommendation
+		switch {
+		case direct != "":
+			fmt.Printf("Recommended: direct upload via %s, i.e. '-m direct'\n", direct)
+		case best != nil:
+			fmt.Printf("Recommended: '-m %s -b %s' (%s)\n", best.cloud, best.bucket, best.rtt.Round(time.Millisecond))
+		default:
+			fmt.Println("No upload path is reachable!")
+		}
+	},
+}
+
+// visibleStr describes the visibility of a direct upload url.
+func visibleStr(ok bool) string {
+	if ok {
+		return "visible"
+	}
+	return "invisible"
+}
+
+func init() {
+	checkCmd.AddCommand(checkNetworkCmd)
+	checkNetworkCmd.Flags().StringVar(&ip, "ip", ip, "IP address of ad-hoc local server for direct upload, check all interfaces if empty")
+	checkNetworkCmd.Flags().StringVar(&port, "port", port, "Port of ad-hoc local server for direct upload")
+	checkNetworkCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more network checking info")
+}
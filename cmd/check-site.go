@@ -2,13 +2,13 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/jackytck/alti-cli/cloud"
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
@@ -23,7 +23,7 @@ var checkSiteCmd = &cobra.Command{
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
@@ -31,13 +31,12 @@ var checkSiteCmd = &cobra.Command{
 			nil,
 			service.CheckAPIServerLite(),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		url := gql.WebEndpoint()
 		if verbose {
-			log.Printf("Checking %s...\n", url)
+			logging.Infof("Checking %s...\n", url)
 		}
 
 		ch := make(chan res)
@@ -48,20 +47,20 @@ var checkSiteCmd = &cobra.Command{
 
 		select {
 		case <-time.After(time.Second * time.Duration(timeout)):
-			log.Println(errors.ErrClientTimeout)
+			logging.Errorln(errors.ErrClientTimeout)
 		case r := <-ch:
 			if r.err != nil {
-				log.Println(r.err)
+				logging.Errorln(r.err)
 				return
 			}
 			if r.status != http.StatusOK {
 				if verbose {
-					log.Printf("Status is not OK: %v", r.status)
+					logging.Errorf("Status is not OK: %v", r.status)
 				}
 				return
 			}
 			if verbose {
-				log.Printf("Success with status code: %v", r.status)
+				logging.Infof("Success with status code: %v", r.status)
 			} else {
 				fmt.Println("Success")
 			}
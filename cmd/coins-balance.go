@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/spf13/cobra"
+)
+
+// coinsBalanceCmd represents the coins balance command
+var coinsBalanceCmd = &cobra.Command{
+	Use:   "balance",
+	Short: "Coin balance",
+	Long:  "Show the coin balance of current user and its value in the given currency.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cur, err := service.SuggestCurrency(currency)
+		if err != nil {
+			errors.Exit(err)
+		}
+		endpoint, user, err := gql.MySelf()
+		if msg := errors.MustGQL(err, endpoint); msg != "" {
+			fmt.Println(msg)
+			return
+		}
+		value, err := gql.CoinsToMoney(user.Balance, cur)
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"Username/Email", "Coins", "Value", "Free GP Quota", "Coin/GP"})
+		table.Append([]string{
+			user.NameOrEmail(),
+			fmt.Sprintf("%.2f", user.Balance),
+			fmt.Sprintf("%s%.2f", cur, value),
+			fmt.Sprintf("%.2f", user.FreeGPQuota),
+			fmt.Sprintf("%.2f", user.Membership.CoinPerGP),
+		})
+		table.Render()
+	},
+}
+
+func init() {
+	coinsCmd.AddCommand(coinsBalanceCmd)
+	coinsBalanceCmd.Flags().StringVarP(&currency, "currency", "f", currency, "Type of currency of the value (default USD)")
+}
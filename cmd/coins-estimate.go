@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/spf13/cobra"
+)
+
+// coinsEstimateCmd represents the coins estimate command
+var coinsEstimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Estimate coins of reconstructing a directory",
+	Long:  "Estimate the coins of reconstructing the images of a directory by the giga-pixel and the coins per GP of current membership, before importing.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := service.Check(nil, service.CheckDir(dir)); err != nil {
+			errors.Exit(err)
+		}
+		if listOnly {
+			listPaths(file.WalkFilesBy(context.Background(), dir, pathFilter()))
+			return
+		}
+		cur, err := service.SuggestCurrency(currency)
+		if err != nil {
+			errors.Exit(err)
+		}
+		endpoint, user, err := gql.MySelf()
+		if msg := errors.MustGQL(err, endpoint); msg != "" {
+			fmt.Println(msg)
+			return
+		}
+
+		// digest local images
+		logging.Infof("Checking %s...\n", dir)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		paths, errc := file.WalkFilesBy(ctx, dir, pathFilter())
+		result := make(chan file.ImageDigest)
+		cache := openDigestCache()
+		if cache != nil {
+			defer cache.Close()
+		}
+		digester := file.ImageDigester{
+			Root:   dir,
+			Cache:  cache,
+			Ctx:    ctx,
+			Paths:  paths,
+			Result: result,
+		}
+		threads := digester.Run(thread)
+		logging.Debugf("Working in %d thread(s)...", threads)
+
+		var totalGP float64
+		var totalImg int
+		var totalByte datasize.ByteSize
+		for r := range result {
+			if r.Error != nil {
+				if r.IsImage {
+					logging.Warnf("Invalid image: %q, Reason: %v", r.Path, r.Error)
+				}
+				continue
+			}
+			totalGP += r.GP
+			totalImg++
+			totalByte += datasize.ByteSize(r.Filesize)
+		}
+		if err = <-errc; err != nil {
+			panic(err)
+		}
+		if totalImg == 0 {
+			logging.Infoln("No image is found!")
+			return
+		}
+
+		coinPerGP := user.Membership.CoinPerGP
+		if coinPerGP <= 0 {
+			// same as the price of pro project without membership
+			coinPerGP = 1
+		}
+		estimate := totalGP * coinPerGP
+		value, err := gql.CoinsToMoney(estimate, cur)
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"Images", "GP", "Size", "Coin/GP", "Coins", "Value", "Balance", "Balance After"})
+		table.Append([]string{
+			fmt.Sprintf("%d", totalImg),
+			fmt.Sprintf("%.2f", totalGP),
+			totalByte.HumanReadable(),
+			fmt.Sprintf("%.2f", coinPerGP),
+			fmt.Sprintf("%.2f", estimate),
+			fmt.Sprintf("%s%.2f", cur, value),
+			fmt.Sprintf("%.2f", user.Balance),
+			fmt.Sprintf("%.2f", user.Balance-estimate),
+		})
+		table.Render()
+		if estimate > user.Balance {
+			logging.Warnf("Current balance: %.2f is not enough to pay %.2f coins.", user.Balance, estimate)
+		}
+	},
+}
+
+func init() {
+	coinsCmd.AddCommand(coinsEstimateCmd)
+	coinsEstimateCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory path")
+	coinsEstimateCmd.Flags().StringVarP(&skip, "skip", "s", skip, "Regular expression to skip paths")
+	coinsEstimateCmd.Flags().StringArrayVar(&includes, "include", includes, "Glob of paths to include, e.g. '*.jpg' or 'raw/**', or a regular expression prefixed by 're:'; repeatable")
+	coinsEstimateCmd.Flags().StringArrayVar(&excludes, "exclude", excludes, "Glob of paths to exclude, taking precedence over --include; repeatable")
+	coinsEstimateCmd.Flags().BoolVar(&withHidden, "hidden", withHidden, "Include the dotfiles and OS junk files, e.g. Thumbs.db and __MACOSX")
+	coinsEstimateCmd.Flags().BoolVar(&listOnly, "list-only", listOnly, "List the paths that would be processed, without processing them")
+	coinsEstimateCmd.Flags().StringVarP(&currency, "currency", "f", currency, "Type of currency of the estimated coins (default USD)")
+	coinsEstimateCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads to process, default is number of cores x 4")
+	coinsEstimateCmd.Flags().BoolVar(&noCache, "no-cache", noCache, "Digest all images again instead of using the cache of unchanged ones")
+	errors.Must(coinsEstimateCmd.MarkFlagRequired("dir"))
+}
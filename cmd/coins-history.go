@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var since string
+
+// coinsHistoryCmd represents the coins history command
+var coinsHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Coin transactions",
+	Long:  "List the coin transactions of current user since the given date, with the total coins received and spent.",
+	Run: func(cmd *cobra.Command, args []string) {
+		var from time.Time
+		if since != "" {
+			t, err := time.ParseInLocation("2006-01-02", since, time.Local)
+			if err != nil {
+				logging.Errorf("Invalid date: %q, expected format is YYYY-MM-DD\n", since)
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			from = t
+		}
+		cur, err := service.SuggestCurrency(currency)
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		trans, err := listCoinTransactions(from)
+		if msg := errors.MustGQL(err, ""); msg != "" {
+			fmt.Println(msg)
+			return
+		}
+
+		var received, spent float64
+		table := newTable()
+		table.SetHeader([]string{"Date", "Type", "Amount", "Balance", "Description"})
+		for _, t := range trans {
+			if t.Amount >= 0 {
+				received += t.Amount
+			} else {
+				spent -= t.Amount
+			}
+			table.Append([]string{
+				t.Date.Local().Format("2006-01-02 15:04:05"),
+				t.Type,
+				fmt.Sprintf("%+.2f", t.Amount),
+				fmt.Sprintf("%.2f", t.Balance),
+				t.Description,
+			})
+		}
+		value, err := gql.CoinsToMoney(spent, cur)
+		if err != nil {
+			errors.Exit(err)
+		}
+		table.SetFooter([]string{
+			fmt.Sprintf("%d transaction(s)", len(trans)),
+			"",
+			fmt.Sprintf("+%.2f / -%.2f", received, spent),
+			"Spent",
+			fmt.Sprintf("%s%.2f", cur, value),
+		})
+		table.Render()
+	},
+}
+
+// listCoinTransactions pages through the coin transactions of current user
+// until the ones before from. All transactions are listed if from is zero.
+func listCoinTransactions(from time.Time) ([]types.CoinTransaction, error) {
+	var ret []types.CoinTransaction
+	after := ""
+	for {
+		trans, page, err := gql.CoinTransactions(50, after)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range trans {
+			// latest first
+			if t.Date.Before(from) {
+				return ret, nil
+			}
+			ret = append(ret, t)
+		}
+		if !page.HasNextPage {
+			break
+		}
+		after = page.EndCursor
+	}
+	return ret, nil
+}
+
+func init() {
+	coinsCmd.AddCommand(coinsHistoryCmd)
+	coinsHistoryCmd.Flags().StringVar(&since, "since", since, "List the transactions since this date, e.g. 2019-11-01, default is all")
+	coinsHistoryCmd.Flags().StringVarP(&currency, "currency", "f", currency, "Type of currency of the coins spent (default USD)")
+}
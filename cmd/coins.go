@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// coinsCmd represents the coins command
+var coinsCmd = &cobra.Command{
+	Use:   "coins",
+	Short: "Audit and estimate coins",
+	Long:  "Check the coin balance, audit the coin transactions and estimate the coins of reconstructing a directory of images.",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("See alti-cli help coins")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(coinsCmd)
+}
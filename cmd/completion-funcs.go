@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/update"
+	"github.com/spf13/cobra"
+)
+
+// completeFunc gives the completions of an arg or a flag value.
+type completeFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// flagCompletions are the dynamic completions of the flags of any command.
+var flagCompletions = map[string]completeFunc{
+	"id":       completeProjectIDs,
+	"bucket":   completeBuckets,
+	"method":   completeWords(service.DirectUploadMethod, service.S3UploadMethod, service.GCSUploadMethod, service.OSSUploadMethod, service.MinioUploadMethod),
+	"checksum": completeWords(file.ChecksumAlgorithms...),
+	"channel":  completeWords(update.Channels...),
+}
+
+// registerCompletions registers flagCompletions to the flags of cmd and its sub-commands.
+func registerCompletions(cmd *cobra.Command) {
+	for name, fn := range flagCompletions {
+		if cmd.Flags().Lookup(name) != nil {
+			// never registered twice, as each command has its own flags
+			cmd.RegisterFlagCompletionFunc(name, fn)
+		}
+	}
+	for _, c := range cmd.Commands() {
+		registerCompletions(c)
+	}
+}
+
+// completeWords completes by the fixed words.
+func completeWords(words ...string) completeFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return words, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeProjectIDs completes the ids of my latest projects, with their
+// names as descriptions.
+func completeProjectIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !IsLogin() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	projs, _, _, err := gql.MyProjects(uiProjectCount, 0, "", "", "")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var ret []string
+	for _, p := range projs {
+		if strings.HasPrefix(p.ID, toComplete) {
+			ret = append(ret, fmt.Sprintf("%s\t%s", p.ID, p.Name))
+		}
+	}
+	return ret, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBuckets completes the buckets of the cloud of '--method', or of all
+// clouds if not given. The kind is 'meta' or 'model' for those import commands,
+// otherwise 'image'.
+func completeBuckets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	kind := "image"
+	if n := cmd.Name(); n == "meta" || n == "model" {
+		kind = n
+	}
+	var clouds []string
+	if m, _ := cmd.Flags().GetString("method"); m != "" && m != service.DirectUploadMethod {
+		clouds = []string{strings.ToUpper(m)}
+	} else {
+		clouds = gql.SupportedCloud("", "", kind)
+	}
+
+	seen := make(map[string]bool)
+	var ret []string
+	for _, c := range clouds {
+		buks, err := gql.BucketList(kind, c)
+		if err != nil {
+			continue
+		}
+		for _, b := range buks {
+			if !seen[b] && strings.HasPrefix(b, toComplete) {
+				seen[b] = true
+				ret = append(ret, fmt.Sprintf("%s\t%s", b, strings.ToLower(c)))
+			}
+		}
+	}
+	return ret, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileIDs completes the first arg by the ids of the account profiles.
+func completeProfileIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	conf := config.Load()
+	var ret []string
+	for _, s := range conf.Scopes {
+		for _, p := range s.Profiles {
+			if strings.HasPrefix(p.ID, toComplete) {
+				ret = append(ret, fmt.Sprintf("%s\t%s", p.ID, strings.TrimSpace(p.Name+" "+s.Endpoint)))
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret, cobra.ShellCompDirectiveNoFileComp
+}
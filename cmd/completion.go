@@ -2,28 +2,60 @@ package cmd
 
 import (
 	"os"
+	"strings"
 
 	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/spf13/cobra"
 )
 
+// completionShells are the shells of which the completion could be generated.
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
 // completionCmd represents the completion command
 var completionCmd = &cobra.Command{
-	Use:   "completion",
-	Short: "Generates bash completion scripts",
-	Long: `To load completion run:
+	Use:       "completion [bash|zsh|fish|powershell]",
+	Short:     "Generates shell completion scripts",
+	ValidArgs: completionShells,
+	Args:      cobra.MaximumNArgs(1),
+	Long: `Generate the completion script of the shell, default is bash.
+Project ids, bucket names and profile ids are completed dynamically in bash and fish.
+
+To load completion run:
 
-[Linux]:
+[Bash on Linux]:
   sudo yum install bash-completion -y
-  echo "source <(alti-cli completion)" >> ~/.bashrc
-[Mac]:
+  echo "source <(alti-cli completion bash)" >> ~/.bashrc
+[Bash on Mac]:
   brew install bash-completion@2
-  alti-cli completion > $(brew --prefix)/etc/bash_completion.d/alti-cli
+  alti-cli completion bash > $(brew --prefix)/etc/bash_completion.d/alti-cli
+[Zsh]:
+  alti-cli completion zsh > "${fpath[1]}/_alti-cli"
+[Fish]:
+  alti-cli completion fish > ~/.config/fish/completions/alti-cli.fish
+[PowerShell]:
+  alti-cli completion powershell | Out-String | Invoke-Expression
 
 Then restart shell.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		errors.Must(rootCmd.GenBashCompletion(os.Stdout))
+		shell := "bash"
+		if len(args) > 0 {
+			shell = strings.ToLower(args[0])
+		}
+		switch shell {
+		case "bash":
+			errors.Must(rootCmd.GenBashCompletion(os.Stdout))
+		case "zsh":
+			errors.Must(rootCmd.GenZshCompletion(os.Stdout))
+		case "fish":
+			errors.Must(rootCmd.GenFishCompletion(os.Stdout, true))
+		case "powershell":
+			errors.Must(rootCmd.GenPowerShellCompletion(os.Stdout))
+		default:
+			logging.Errorf("Unknown shell: %q, valid shells are: %q\n", shell, strings.Join(completionShells, ", "))
+			errors.Exit(errors.ErrInvalidInput)
+		}
 	},
 }
 
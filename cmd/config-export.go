@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var bundlePath = "profiles.enc"
+var bundlePassword string
+var redactParts []string
+var exportProfiles []string
+
+// configExportCmd represents the config export command
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the profiles encrypted by a password, or redacted for sharing",
+	Long: `Export the endpoints, keys, tokens and defaults of the profiles into a file encrypted by a password, for 'alti-cli config import' on another machine, e.g.
+'alti-cli config export --out profiles.enc --password -', where '-' prompts for the password.
+With '--redact', the secrets are replaced, e.g. 'alti-cli config export --redact secrets,user --out -' prints a sanitized config for bug reports.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, p := range redactParts {
+			if _, ok := text.Contains(config.RedactParts, p); !ok {
+				logging.Errorf("Unknown --redact: %q, valid parts are: %q\n", p, strings.Join(config.RedactParts, ", "))
+				errors.Exit(errors.ErrInvalidInput)
+			}
+		}
+		c, err := config.LoadAll().Select(exportProfiles)
+		if err != nil {
+			logging.Errorf("Profile of %q is not found, look up at 'alti-cli account list'\n", exportProfiles)
+			errors.Exit(err)
+		}
+		if len(redactParts) > 0 {
+			c = c.Redact(redactParts)
+		}
+
+		// the secrets are never written in plaintext
+		_, redacted := text.Contains(redactParts, config.RedactSecrets)
+		password := bundlePasswordOf(cmd)
+		if password == "" && !redacted {
+			password = promptPassword("Password: ")
+			if promptPassword("Confirm password: ") != password {
+				logging.Errorln("Passwords do not match")
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			if password == "" {
+				logging.Errorln("Password could not be empty unless the secrets are redacted by '--redact secrets'")
+				errors.Exit(errors.ErrInvalidInput)
+			}
+		}
+
+		var data []byte
+		if password != "" {
+			data, err = config.EncryptBundle(c, password)
+		} else {
+			data, err = yaml.Marshal(c)
+		}
+		errors.Must(err)
+		if bundlePath == "-" {
+			if password != "" {
+				logging.Errorln("Encrypted profiles could not be written to stdout, give a path by --out")
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			fmt.Print(string(data))
+			return
+		}
+		errors.Must(ioutil.WriteFile(bundlePath, data, 0600))
+
+		var n int
+		for _, s := range c.Scopes {
+			n += len(s.Profiles)
+		}
+		if password != "" {
+			fmt.Printf("Exported %d profile(s) encrypted to %q\n", n, bundlePath)
+			return
+		}
+		fmt.Printf("Exported %d redacted profile(s) to %q\n", n, bundlePath)
+	},
+}
+
+// bundlePasswordOf gives the password of '--password', prompting for it if
+// it is '-', or of ALTI_CONFIG_PASSWORD.
+func bundlePasswordOf(cmd *cobra.Command) string {
+	if !cmd.Flags().Changed("password") {
+		return os.Getenv(config.AltiConfigPassword)
+	}
+	if bundlePassword == "-" {
+		return promptPassword("Password: ")
+	}
+	return bundlePassword
+}
+
+func init() {
+	configCmd.AddCommand(configExportCmd)
+	configExportCmd.Flags().StringVarP(&bundlePath, "out", "o", bundlePath, "Path of the exported file, '-' for stdout if it is redacted without password")
+	configExportCmd.Flags().StringVar(&bundlePassword, "password", bundlePassword, "Password of encrypting the profiles, '-' to prompt for it, default is by ALTI_CONFIG_PASSWORD or prompted")
+	configExportCmd.Flags().StringSliceVar(&redactParts, "redact", redactParts, "Redact 'secrets' (keys, tokens and proxy credentials), 'user' (names and emails) or 'endpoint' (non-public hostnames), comma separated")
+	configExportCmd.Flags().StringSliceVar(&exportProfiles, "profile", exportProfiles, "IDs of the profiles to export, default is all")
+}
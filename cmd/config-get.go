@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/spf13/cobra"
+)
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get [KEY]",
+	Short: "Get the default values of flags for the active profile",
+	Long:  "Get the default value of a flag, or all of them if key is omitted, for the active profile.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		defaults := config.Load().GetDefaults()
+		if len(args) == 1 {
+			fmt.Println(defaults[args[0]])
+			return
+		}
+		for _, k := range config.SortedDefaultKeys(defaults) {
+			fmt.Printf("%s = %q\n", k, defaults[k])
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+}
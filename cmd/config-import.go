@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/spf13/cobra"
+)
+
+var activateImported bool
+
+// configImportCmd represents the config import command
+var configImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the profiles exported by 'config export'",
+	Long: `Import the profiles exported by 'alti-cli config export' on another machine, e.g. 'alti-cli config import --in profiles.enc --password -'.
+The profiles of existing ids are skipped unless '--overwrite', as are the redacted ones. The secrets are stored in the OS keychain unless '--no-keychain'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := ioutil.ReadFile(bundlePath)
+		errors.Must(err)
+		var password string
+		if config.IsEncryptedBundle(data) {
+			password = bundlePasswordOf(cmd)
+			if password == "" {
+				password = promptPassword("Password: ")
+			}
+		}
+		in, err := config.DecryptBundle(data, password)
+		switch err {
+		case nil:
+		case errors.ErrDecrypt:
+			logging.Errorln("Profiles could not be decrypted, the password is wrong or the file is tampered")
+			errors.Exit(err)
+		default:
+			logging.Errorf("%q is not exported by 'alti-cli config export'\n", bundlePath)
+			errors.Exit(err)
+		}
+
+		c := config.LoadAll()
+		added, replaced, skipped := c.Merge(in, overwrite)
+		switched := activateImported && in.Active != "" && (isIn(added, in.Active) || isIn(replaced, in.Active))
+		if switched {
+			c.Active = in.Active
+		}
+		if len(added)+len(replaced) > 0 || switched {
+			errors.Must(c.Save())
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"ID", "Endpoint", "Status"})
+		for _, k := range in.ScopeKeys() {
+			s := in.Scopes[k]
+			for _, p := range s.Profiles {
+				status := "added"
+				switch {
+				case isIn(replaced, p.ID):
+					status = "replaced"
+				case p.IsRedacted():
+					status = "skipped, redacted"
+				case isIn(skipped, p.ID):
+					status = "skipped, existed"
+				}
+				table.Append([]string{p.ID, s.Endpoint, status})
+			}
+		}
+		table.Render()
+		fmt.Printf("Imported %d profile(s), skipped %d\n", len(added)+len(replaced), len(skipped))
+		if switched {
+			fmt.Printf("Switched to %q\n", c.Active)
+		}
+	},
+}
+
+// isIn tells if s is one of a.
+func isIn(a []string, s string) bool {
+	_, ok := text.Contains(a, s)
+	return ok
+}
+
+func init() {
+	configCmd.AddCommand(configImportCmd)
+	configImportCmd.Flags().StringVarP(&bundlePath, "in", "i", bundlePath, "Path of the file exported by 'config export'")
+	configImportCmd.Flags().StringVar(&bundlePassword, "password", bundlePassword, "Password of the exported profiles, '-' to prompt for it, default is by ALTI_CONFIG_PASSWORD or prompted")
+	configImportCmd.Flags().BoolVar(&overwrite, "overwrite", overwrite, "Replace the existing profiles of the same ids")
+	configImportCmd.Flags().BoolVar(&activateImported, "activate", activateImported, "Switch to the active profile of the exported ones")
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/spf13/cobra"
+)
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Set the default value of a flag for the active profile",
+	Long:  fmt.Sprintf("Set the default value of a flag for the active profile. It is used if the flag is not given. Keys: %s.", strings.Join(config.DefaultKeys, ", ")),
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if args[1] == "" {
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		conf := config.Load()
+		if err := conf.SetDefault(args[0], args[1], true); err != nil {
+			errors.Exit(err)
+		}
+		fmt.Printf("%s = %q\n", args[0], args[1])
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/spf13/cobra"
+)
+
+// configUnsetCmd represents the config unset command
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset KEY",
+	Short: "Unset the default value of a flag for the active profile",
+	Long:  "Unset the default value of a flag for the active profile.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		conf := config.Load()
+		if err := conf.SetDefault(args[0], "", true); err != nil {
+			errors.Exit(err)
+		}
+		fmt.Printf("%s is unset\n", args[0])
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configUnsetCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Root command for all profile defaults related commands",
+	Long:  `'alti-cli config set method s3' to upload by s3 by default for the active profile`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("See alti-cli help config")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
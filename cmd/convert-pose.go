@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/logging"
+	altimeta "github.com/jackytck/alti-cli/meta"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/spf13/cobra"
+)
+
+var poseFrom string
+var poseIn string
+var poseOut = "pose.txt"
+var cameraOut string
+var utmZone string
+var labelExt string
+var poseCRS string
+
+// convertPoseCmd represents the convert pose command
+var convertPoseCmd = &cobra.Command{
+	Use:   "pose",
+	Short: "Convert the camera file of Pix4D, Metashape or OPK csv to pose.txt and camera.txt",
+	Long: `Convert the calibrated camera parameters of Pix4D, the cameras xml of Agisoft Metashape or a csv of omega, phi, kappa
+to the pose.txt of the positions, and optionally the camera.txt of the calibrations, for 'import meta'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		poseFrom = strings.ToLower(poseFrom)
+		if _, ok := text.Contains(altimeta.Formats, poseFrom); !ok {
+			logging.Errorf("Unknown format: %q, valid formats are: %q\n", poseFrom, strings.Join(altimeta.Formats, ", "))
+			errors.Exit(errors.ErrInvalidInput)
+		}
+
+		f, err := os.Open(poseIn)
+		if err != nil {
+			logging.Errorln(err)
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		cams, err := altimeta.Read(poseFrom, f)
+		f.Close()
+		if err != nil {
+			logging.Errorln(err)
+			errors.Exit(errors.ErrCameraFileInvalid)
+		}
+		if len(cams) == 0 {
+			logging.Errorf("No camera is found in %q\n", poseIn)
+			errors.Exit(errors.ErrCameraFileInvalid)
+		}
+
+		for i, c := range cams {
+			if labelExt != "" && filepath.Ext(c.Filename) == "" {
+				cams[i].Filename += "." + strings.TrimPrefix(labelExt, ".")
+			}
+			if strings.ContainsAny(cams[i].Filename, " \t") {
+				logging.Warnf("Filename with spaces is not supported by pose.txt: %q\n", cams[i].Filename)
+			}
+		}
+		if utmZone != "" && poseCRS != "" {
+			logging.Errorln("Only one of '--utm-zone' and '--crs' could be given")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		if utmZone != "" {
+			if err = altimeta.ToGeodetic(cams, utmZone); err != nil {
+				logging.Errorln(err)
+				errors.Exit(errors.ErrInvalidInput)
+			}
+		}
+		if crs := checkCRS(); crs != nil {
+			if err = altimeta.Reproject(cams, crs); err != nil {
+				logging.Errorln(err)
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			logging.Infof("Converted the positions of %s to WGS84\n", crs)
+		}
+		for _, c := range cams {
+			if !c.Geodetic {
+				logging.Errorf("Positions of %q are projected, convert them to latitude and longitude by '--crs', e.g. EPSG:2326, or '--utm-zone', e.g. 50N\n", poseIn)
+				errors.Exit(errors.ErrInvalidInput)
+			}
+		}
+
+		out, err := os.Create(poseOut)
+		errors.Must(err)
+		defer out.Close()
+		errors.Must(altimeta.WritePose(out, cams))
+		logging.Infof("Wrote the poses of %d images into %q\n", len(cams), poseOut)
+
+		if cameraOut != "" {
+			co, err := os.Create(cameraOut)
+			errors.Must(err)
+			defer co.Close()
+			n, err := altimeta.WriteCamera(co, cams)
+			errors.Must(err)
+			if n == 0 {
+				logging.Warnf("No calibration is found in %q\n", poseIn)
+				return
+			}
+			logging.Infof("Wrote the calibrations of %d images into %q\n", n, cameraOut)
+		}
+	},
+}
+
+// checkCRS parses the crs of '--crs', nil if it is not given.
+func checkCRS() *altimeta.CRS {
+	if poseCRS == "" {
+		return nil
+	}
+	crs, err := altimeta.ParseCRS(poseCRS)
+	if err != nil {
+		logging.Errorln(err)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	return crs
+}
+
+func init() {
+	convertCmd.AddCommand(convertPoseCmd)
+	convertPoseCmd.Flags().StringVar(&poseFrom, "from", poseFrom, "Format of the input: 'pix4d' (calibrated camera parameters), 'metashape' (cameras xml) or 'opk' (csv with header)")
+	convertPoseCmd.Flags().StringVar(&poseIn, "in", poseIn, "Path of the input camera file")
+	convertPoseCmd.Flags().StringVar(&poseOut, "out", poseOut, "Path of the output pose.txt")
+	convertPoseCmd.Flags().StringVar(&cameraOut, "camera", cameraOut, "Also write the calibrations into this camera.txt, if the input has them")
+	convertPoseCmd.Flags().StringVar(&utmZone, "utm-zone", utmZone, "UTM zone of the projected positions, e.g. 50N or 18S, converted to latitude and longitude")
+	convertPoseCmd.Flags().StringVar(&poseCRS, "crs", poseCRS, "EPSG code of the positions, e.g. EPSG:2326 (Hong Kong 1980 Grid), converted to WGS84")
+	convertPoseCmd.Flags().StringVar(&labelExt, "ext", labelExt, "Extension appended to the filenames without one, e.g. JPG for the labels of Metashape")
+	errors.Must(convertPoseCmd.MarkFlagRequired("from"))
+	errors.Must(convertPoseCmd.MarkFlagRequired("in"))
+}
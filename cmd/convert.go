@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Root command for all convert related commands",
+	Long:  `'alti-cli convert pose' to convert the camera files of other software to pose.txt and camera.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("See alti-cli help convert")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+}
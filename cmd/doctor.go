@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/spf13/cobra"
+)
+
+// Minimum free disk space and maximum clock skew of doctor.
+const (
+	doctorMinDisk = 1 << 30
+	doctorMaxSkew = 5 * time.Minute
+)
+
+// Results of the checks of doctor.
+const (
+	doctorPass = "PASS"
+	doctorWarn = "WARN"
+	doctorFail = "FAIL"
+	doctorSkip = "SKIP"
+)
+
+// doctorCheck is a diagnostic check of doctor.
+type doctorCheck struct {
+	name string
+	fn   service.CheckFn
+	hint string
+	// warn tells if a failure is only a warning, i.e. not required by all commands
+	warn bool
+	// login tells if the check needs a logged in user
+	login bool
+	// online tells if the check is skipped when the api server is unreachable
+	online bool
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the environment",
+	Long: `Run the checks of login, api server, direct upload, config, disk space and clock skew, then print a table of the results with remediation hints.
+Useful before large imports or when filing support tickets. Exit with non-zero code if any required check fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		disk := dir
+		if disk == "" {
+			d, err := config.GetConfigDir()
+			errors.Must(err)
+			disk = d
+			if _, err := os.Stat(disk); err != nil {
+				disk = "."
+			}
+		}
+		checks := []doctorCheck{
+			{
+				name: "Config",
+				fn:   service.CheckConfig(),
+				hint: "Switch to an existing profile by 'alti-cli account use' or login again",
+			},
+			{
+				name: "Login",
+				fn:   service.CheckIsLogin(),
+				hint: "Login by 'alti-cli login'",
+			},
+			{
+				name: "Token",
+				fn: func(logger service.LogFn) error {
+					_, user, err := gql.MySelf()
+					if err != nil {
+						return err
+					}
+					if exp := config.Load().GetActive().Expiry; exp > 0 {
+						logger("Logged in as %s, token expires at %s", user.NameOrEmail(), time.Unix(exp, 0).Format(time.RFC3339))
+						return nil
+					}
+					logger("Logged in as %s", user.NameOrEmail())
+					return nil
+				},
+				hint:  "Token may be expired or revoked, login again by 'alti-cli login'",
+				login: true,
+			},
+			{
+				name: "API server",
+				fn:   service.CheckAPIServer(),
+				hint: "Check the network by 'alti-cli check network' or retry later",
+			},
+			{
+				name: "Direct upload",
+				fn: func(logger service.LogFn) error {
+					return service.CheckDirectUpload(verbose, logger)
+				},
+				hint: "Upload by cloud with '-m s3', '-m gcs' or '-m oss', or open a port with '--ip' and '--port'",
+				warn: true,
+			},
+			{
+				name: "Disk space",
+				fn:   service.CheckDiskSpace(disk, doctorMinDisk),
+				hint: "Free up at least 1 GiB for the manifests, history and downloads",
+				warn: true,
+			},
+			{
+				name:   "Clock skew",
+				fn:     service.CheckClockSkew(doctorMaxSkew),
+				hint:   "Synchronize the system clock, e.g. by NTP; signed upload urls expire on skewed clocks",
+				online: true,
+			},
+		}
+
+		active := config.Load().GetActive()
+		fmt.Printf("Version: %s (%s/%s, %s)\n", Version, runtime.GOOS, runtime.GOARCH, runtime.Version())
+		fmt.Printf("Endpoint: %s\n", active.Endpoint)
+		fmt.Printf("Profile: %s\n", active.Name)
+
+		table := newTable()
+		table.SetHeader([]string{"Check", "Result", "Detail", "Hint"})
+		failed := false
+		logged := service.Check(service.QuietLog, service.CheckIsLogin()) == nil
+		for _, c := range checks {
+			if c.login && !logged {
+				table.Append([]string{c.name, doctorSkip, "Not logged in", ""})
+				continue
+			}
+			var details []string
+			err := c.fn(func(format string, a ...interface{}) {
+				// skip the progress lines
+				if d := strings.TrimSpace(fmt.Sprintf(format, a...)); !strings.HasSuffix(d, "...") {
+					details = append(details, d)
+				}
+			})
+			result, hint := doctorPass, ""
+			switch {
+			case err == errors.ErrOffline && c.online:
+				result = doctorSkip
+				details = append(details, "Server unreachable")
+			case err != nil:
+				details = append(details, err.Error())
+				result, hint = doctorFail, c.hint
+				if c.warn {
+					result = doctorWarn
+				} else {
+					failed = true
+				}
+			}
+			table.Append([]string{c.name, result, strings.Join(details, "\n"), hint})
+		}
+		table.Render()
+
+		if failed {
+			errors.Exit(errors.ErrDoctorFailed)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory to check the free disk space, default is the config directory")
+	doctorCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more network checking info")
+}
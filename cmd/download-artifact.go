@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var artifactTypes = "ortho,dsm,pointcloud,calibration"
+var dlConns = 4
+
+// downloadArtifactCmd represents the download artifact command
+var downloadArtifactCmd = &cobra.Command{
+	Use:   "artifact",
+	Short: "Download the reconstruction artifacts of types.",
+	Long: `Download the reconstruction artifacts of a project of the types: model, ortho (orthophoto), dsm (GeoTIFF),
+pointcloud (dense point cloud) or calibration (camera calibration). Each file is downloaded by '-n' parallel range requests.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		start := time.Now()
+		defer func() {
+			if verbose {
+				elapsed := time.Since(start)
+				logging.Infoln("Took", elapsed)
+			}
+		}()
+
+		if err := service.Check(
+			nil,
+			service.CheckAPIServerLite(),
+			service.CheckDir(dlDir),
+		); err != nil {
+			errors.Exit(err)
+		}
+		kinds := make(map[string]bool)
+		for _, t := range strings.Split(strings.ToLower(artifactTypes), ",") {
+			if t = strings.TrimSpace(t); t == "" {
+				continue
+			}
+			if _, ok := text.Contains(types.ArtifactTypes, t); !ok {
+				logging.Errorf("Unknown type: %q, valid types are: %q\n", t, strings.Join(types.ArtifactTypes, ", "))
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			kinds[t] = true
+		}
+
+		key := readEncryptKey()
+
+		p, err := gql.SearchProjectID(id, false)
+		if err != nil {
+			fmt.Println("Project could not be found! Error:", err)
+			return
+		}
+
+		// display
+		var items []types.Downloadable
+		var total int64
+		table := newTable()
+		table.SetHeader([]string{"Type", "State", "Name", "Size", "Last modified"})
+		for _, e := range p.Downloads.Edges {
+			d := e.Node
+			if d.Link == "" || !kinds[d.ArtifactType()] {
+				continue
+			}
+			items = append(items, d)
+			total += d.Size
+			table.Append([]string{d.ArtifactType(), d.State, d.Name, fmt.Sprintf("%.2f MB", file.BytesToMB(d.Size)), d.Mtime.Format("2006-01-02 15:04:05")})
+		}
+		if len(items) == 0 {
+			logging.Errorf("No %s could be downloaded from project %q\n", artifactTypes, p.ID)
+			errors.Exit(errors.ErrDownloadNotFound)
+		}
+		table.Render()
+		checkDiskSpace(dlDir, total)
+
+		// download
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+		pr := service.NewProgressReporter(len(items), total)
+		var failed []string
+		for _, d := range items {
+			if ctx.Err() != nil {
+				failed = append(failed, d.Name)
+				continue
+			}
+			path := filepath.Join(dlDir, d.Name)
+			err := cloud.RangedFile(ctx, path, d.Link, d.Size, dlConns, pr)
+			if err == nil {
+				// verify
+				var size int64
+				if size, err = file.Filesize(path); err == nil && d.Size > 0 && size != d.Size {
+					err = fmt.Errorf("%v: expected %d bytes, got %d bytes", errors.ErrFileSizeMismatch, d.Size, size)
+					os.Remove(path)
+				}
+			}
+			if err == nil && key != nil {
+				if err = decryptDownload(path, key); err != nil {
+					err = fmt.Errorf("decrypt by key %s: %v", file.KeyID(key), err)
+				}
+			}
+			pr.Done(path, err)
+			if err != nil {
+				failed = append(failed, d.Name)
+			}
+		}
+		pr.Close()
+		if len(failed) > 0 {
+			logging.Errorf("Failed to download: %s\n", strings.Join(failed, ", "))
+			logging.Infoln("Run the same command again to retry.")
+			if ctx.Err() != nil {
+				return
+			}
+			errors.Exit(errors.ErrDownloadFailed)
+		}
+		logging.Infof("Downloaded %d artifact(s) into %q\n", len(items), dlDir)
+	},
+}
+
+func init() {
+	downloadCmd.AddCommand(downloadArtifactCmd)
+	downloadArtifactCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id")
+	downloadArtifactCmd.Flags().StringVar(&artifactTypes, "type", artifactTypes, "Comma separated types: 'model', 'ortho', 'dsm', 'pointcloud' or 'calibration'")
+	downloadArtifactCmd.Flags().StringVarP(&dlDir, "out", "o", dlDir, "Directory to download into")
+	downloadArtifactCmd.Flags().IntVarP(&dlConns, "conn", "n", dlConns, "Number of parallel range requests of each file")
+	downloadArtifactCmd.Flags().StringVar(&encryptKeyFile, "encrypt-key-file", encryptKeyFile, "File of the 256-bit key to decrypt the artifacts which are encrypted on import")
+	downloadArtifactCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info")
+	errors.Must(downloadArtifactCmd.MarkFlagRequired("id"))
+}
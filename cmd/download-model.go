@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var modelFormat = "obj"
+var dlDir = "."
+
+// modelFormats are the supported formats of reconstructed model.
+var modelFormats = []string{"obj", "ply", "las", "fbx"}
+
+// downloadModelCmd represents the download model command
+var downloadModelCmd = &cobra.Command{
+	Use:   "model",
+	Short: "Download the reconstructed model in a format.",
+	Long:  "Download the reconstructed model of a project in one of the formats: obj, ply, las or fbx. Interrupted download is resumed. The size, and the checksum if given by the server, are verified after downloading.",
+	Run: func(cmd *cobra.Command, args []string) {
+		start := time.Now()
+		defer func() {
+			if verbose {
+				elapsed := time.Since(start)
+				logging.Infoln("Took", elapsed)
+			}
+		}()
+
+		modelFormat = strings.ToLower(modelFormat)
+		if err := service.Check(
+			nil,
+			service.CheckAPIServerLite(),
+			service.CheckDir(dlDir),
+		); err != nil {
+			errors.Exit(err)
+		}
+		if !isModelFormat(modelFormat) {
+			logging.Errorf("Unknown format: %q, valid formats are: %q\n", modelFormat, strings.Join(modelFormats, ", "))
+			errors.Exit(errors.ErrInvalidInput)
+		}
+
+		key := readEncryptKey()
+
+		p, err := gql.SearchProjectID(id, false)
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		d, err := findDownload(p.Downloads, modelFormat)
+		if err != nil {
+			logging.Errorf("No %s model could be downloaded from project %q\n", modelFormat, p.ID)
+			errors.Exit(err)
+		}
+
+		path := filepath.Join(dlDir, d.Name)
+		need := d.Size
+		if part, err := file.Filesize(path + ".part"); err == nil {
+			need -= part
+		}
+		checkDiskSpace(dlDir, need)
+		if verbose {
+			logging.Infof("Downloading %q (%s) to %q...\n", d.Name, d.State, path)
+		}
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+		pr := service.NewProgressReporter(1, d.Size)
+		err = cloud.ResumeFile(ctx, path, d.Link, pr)
+		pr.Done(path, err)
+		pr.Close()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logging.Infoln("Run the same command again to resume.")
+			errors.Exit(err)
+		}
+
+		// verify
+		size, err := file.Filesize(path)
+		errors.Must(err)
+		if d.Size > 0 && size != d.Size {
+			logging.Errorf("%v: expected %d bytes, got %d bytes\n", errors.ErrFileSizeMismatch, d.Size, size)
+			errors.Must(os.Remove(path))
+			errors.Exit(errors.ErrFileSizeMismatch)
+		}
+		var remote string
+		if hasFeature(types.FeatureDownloadChecksum) {
+			if remote, err = gql.DownloadChecksum(ctx, p.ID, d.Name); err != nil {
+				logging.Warnln("Checksum could not be queried, only the size is verified:", err)
+			}
+		}
+		if remote != "" || verbose {
+			checksum, err := file.Sha1sum(path)
+			errors.Must(err)
+			if verbose {
+				logging.Infof("SHA1: %s\n", checksum)
+			}
+			if remote != "" {
+				if err = file.VerifyChecksum(path, checksum, remote); err != nil {
+					logging.Errorf("%v: expected %s of %q\n", err, remote, d.Name)
+					errors.Must(os.Remove(path))
+					errors.Exit(err)
+				}
+			}
+		}
+		if key != nil {
+			if err = decryptDownload(path, key); err != nil {
+				logging.Errorf("Failed to decrypt %q by key %s: %v\n", path, file.KeyID(key), err)
+				errors.Exit(errors.ErrDecrypt)
+			}
+		}
+		logging.Infof("Downloaded %q\n", path)
+	},
+}
+
+// isModelFormat tells if f is one of the supported model formats.
+func isModelFormat(f string) bool {
+	for _, v := range modelFormats {
+		if v == f {
+			return true
+		}
+	}
+	return false
+}
+
+// findDownload finds the first downloadable with link of the given format,
+// e.g. "xxx.obj.zip" of obj but not "xxx.objects.ply".
+func findDownload(dc types.DownloadsConnection, format string) (*types.Downloadable, error) {
+	for _, e := range dc.Edges {
+		d := e.Node
+		if d.Link == "" {
+			continue
+		}
+		if d.Format() == format {
+			return &d, nil
+		}
+	}
+	return nil, errors.ErrDownloadNotFound
+}
+
+func init() {
+	downloadCmd.AddCommand(downloadModelCmd)
+	downloadModelCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id")
+	downloadModelCmd.Flags().StringVarP(&modelFormat, "format", "f", modelFormat, "Model format: 'obj', 'ply', 'las' or 'fbx'")
+	downloadModelCmd.Flags().StringVarP(&dlDir, "out", "o", dlDir, "Directory to download into")
+	downloadModelCmd.Flags().StringVar(&encryptKeyFile, "encrypt-key-file", encryptKeyFile, "File of the 256-bit key to decrypt the model which is encrypted on import")
+	downloadModelCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info")
+	errors.Must(downloadModelCmd.MarkFlagRequired("id"))
+}
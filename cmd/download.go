@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// downloadCmd represents the download command
+var downloadCmd = &cobra.Command{
+	Use:   "download",
+	Short: "Root command for all download related commands",
+	Long:  `'alti-cli download model' to download the reconstructed model of a project`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("See alti-cli help download")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(downloadCmd)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
+)
+
+// encryptKeyFile is the '--encrypt-key-file' of the client-side encryption.
+var encryptKeyFile string
+
+// readEncryptKey reads the key of '--encrypt-key-file', nil if it is not given.
+func readEncryptKey() []byte {
+	if encryptKeyFile == "" {
+		return nil
+	}
+	key, err := file.ReadKeyFile(encryptKeyFile)
+	if err != nil {
+		logging.Errorf("Invalid key file %q, expect 32 raw bytes, 64 hex digits or base64: %v\n", encryptKeyFile, err)
+		errors.Exit(errors.ErrEncryptKeyInvalid)
+	}
+	return key
+}
+
+// encryptDir gives the temporary directory of the encrypted copies of src by
+// key. It is the same for the same src and key, so that a resumed upload
+// sends the same ciphertext.
+func encryptDir(src string, key []byte) string {
+	abs, _ := filepath.Abs(src)
+	h := sha1.Sum([]byte(abs + file.KeyID(key)))
+	return filepath.Join(os.TempDir(), "alti-encrypted-"+hex.EncodeToString(h[:6]))
+}
+
+// encryptFiles encrypts each of paths by key into dir, keeping the filenames,
+// and gives the paths of the encrypted copies. The existing copies are reused
+// if reuse is set.
+func encryptFiles(paths []string, dir string, key []byte, reuse bool) ([]string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	ret := make([]string, len(paths))
+	for i, p := range paths {
+		dst := filepath.Join(dir, filepath.Base(p))
+		ret[i] = dst
+		if _, err := os.Stat(dst); reuse && err == nil {
+			continue
+		}
+		if err := file.EncryptFile(p, dst+".tmp", key); err != nil {
+			return nil, err
+		}
+		if err := os.Rename(dst+".tmp", dst); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// encryptEntry records the encryption by key in the manifest entry e.
+func encryptEntry(e *db.ManifestEntry, key []byte) {
+	if key == nil {
+		return
+	}
+	e.Encryption = file.EncryptAlgorithm
+	e.KeyID = file.KeyID(key)
+}
+
+// decryptDownload decrypts the downloaded file p by key in place.
+// A file which is not encrypted is kept as is.
+func decryptDownload(p string, key []byte) error {
+	enc, err := file.IsEncrypted(p)
+	if err != nil {
+		return err
+	}
+	if !enc {
+		logging.Warnf("%q is not encrypted, kept as is\n", p)
+		return nil
+	}
+	tmp := p + ".dec"
+	if err = file.DecryptFile(p, tmp, key); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
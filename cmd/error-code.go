@@ -1,12 +1,10 @@
 package cmd
 
 import (
-	"log"
-	"os"
 	"time"
 
 	"github.com/jackytck/alti-cli/gql"
-	"github.com/olekukonko/tablewriter"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -15,25 +13,26 @@ var errLang = "en"
 
 // errorCmd represents the error command
 var errorCmd = &cobra.Command{
-	Use:   "error",
-	Short: "Query description and solution of error code.",
-	Long:  "Query the description and suggested solution of an error code.",
+	Use:     "error",
+	Aliases: []string{"errors"},
+	Short:   "Query description and solution of error code.",
+	Long:    "Query the description and suggested solution of an error code.",
 	Run: func(cmd *cobra.Command, args []string) {
 		start := time.Now()
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
 		info, err := gql.GetErrorCodeInfo(errCode, errLang)
 		if err != nil {
-			log.Printf("Invalid code: %q\n", errCode)
+			logging.Errorf("Invalid code: %q\n", errCode)
 			return
 		}
 
-		table := tablewriter.NewWriter(os.Stdout)
+		table := newTable()
 		table.Append([]string{"Code", info.Code})
 		table.Append([]string{"Description", info.Description})
 		table.Append([]string{"Solution", info.Solution})
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/spf13/cobra"
+)
+
+// errorListCmd represents the error list command
+var errorListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the exit codes of errors.",
+	Long:  "List the process exit codes of the known errors, for scripts to branch on specific failures.",
+	Run: func(cmd *cobra.Command, args []string) {
+		table := newTable()
+		table.SetHeader([]string{"Exit Code", "Error", "Message"})
+		for _, e := range errors.ExitCodes() {
+			msg := "any other error of this type"
+			if e.Err != nil {
+				msg = e.Err.Error()
+			}
+			if e.Code == errors.ExitGeneric {
+				msg = "unknown error"
+			}
+			table.Append([]string{fmt.Sprintf("%d", e.Code), e.Name, msg})
+		}
+		table.Render()
+	},
+}
+
+func init() {
+	errorCmd.AddCommand(errorListCmd)
+}
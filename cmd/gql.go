@@ -3,53 +3,110 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/spf13/cobra"
 )
 
 var queryFile string
 var varFile string
+var gqlVars []string
 
 // gqlCmd represents the gql command
 var gqlCmd = &cobra.Command{
 	Use:   "gql",
 	Short: "Run arbitrary gql request.",
-	Long:  "Run arbitrary gql request.",
+	Long: `Run arbitrary gql query or mutation against the active profile, and print the response data as json.
+The query is read from a file by '--query @file.graphql', from stdin by '--query @-', or given inline.
+Variables are given by a json file of '--variable', and each '--var key=value' as a string or '--var key:=json' as a json value, e.g. '--var first:=10'.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		q, err := ioutil.ReadFile(queryFile)
+		q, err := readQuery(queryFile)
 		if err != nil {
-			fmt.Println(errors.ErrClientQuery)
-			return
+			logging.Errorln(err)
+			errors.Exit(errors.ErrClientQuery)
 		}
 		va := make(map[string]interface{})
 		if varFile != "" {
-			vb, err2 := ioutil.ReadFile(varFile)
+			vb, err2 := os.ReadFile(varFile)
 			if err2 != nil {
-				fmt.Println(errors.ErrClientVar)
-				return
+				errors.Exit(errors.ErrClientVar)
 			}
 			err = json.Unmarshal(vb, &va)
 			if err != nil {
-				fmt.Println(errors.ErrClientVarInvalid)
-				return
+				errors.Exit(errors.ErrClientVarInvalid)
 			}
 		}
+		for _, kv := range gqlVars {
+			k, v, err := parseGQLVar(kv)
+			if err != nil {
+				logging.Errorln(err)
+				errors.Exit(errors.ErrClientVarInvalid)
+			}
+			va[k] = v
+		}
 
-		res, err := gql.Arbitrary(string(q), va)
+		res, err := gql.Arbitrary(q, va)
 		if err != nil {
-			fmt.Println(err)
-			return
+			logging.Errorln(err)
+			errors.Exit(err)
 		}
 
 		fmt.Println(res)
 	},
 }
 
+// readQuery gives the query of '--query', i.e. the file of '@path', stdin of
+// '@-', or the inline query. A path without '@' is also read, as before.
+func readQuery(q string) (string, error) {
+	var b []byte
+	var err error
+	switch {
+	case q == "@-" || q == "-":
+		b, err = io.ReadAll(os.Stdin)
+	case strings.HasPrefix(q, "@"):
+		b, err = os.ReadFile(q[1:])
+	case strings.Contains(q, "{"):
+		return q, nil
+	default:
+		b, err = os.ReadFile(q)
+	}
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(b)) == "" {
+		return "", fmt.Errorf("empty query")
+	}
+	return string(b), nil
+}
+
+// parseGQLVar parses a variable of 'key=value' as a string, or 'key:=json'
+// as a json value.
+func parseGQLVar(kv string) (string, interface{}, error) {
+	i := strings.Index(kv, "=")
+	if i <= 0 {
+		return "", nil, fmt.Errorf("invalid variable %q, expect key=value or key:=json", kv)
+	}
+	k, v := kv[:i], kv[i+1:]
+	if !strings.HasSuffix(k, ":") {
+		return k, v, nil
+	}
+	k = strings.TrimSuffix(k, ":")
+	var ret interface{}
+	if k == "" || json.Unmarshal([]byte(v), &ret) != nil {
+		return "", nil, fmt.Errorf("invalid variable %q, expect key:=json", kv)
+	}
+	return k, ret, nil
+}
+
 func init() {
 	rootCmd.AddCommand(gqlCmd)
-	gqlCmd.Flags().StringVarP(&queryFile, "query", "q", queryFile, "File storing the gql string.")
+	gqlCmd.Flags().StringVarP(&queryFile, "query", "q", queryFile, "Query or mutation, '@path' of a file, '@-' of stdin, or inline")
 	gqlCmd.Flags().StringVarP(&varFile, "variable", "k", varFile, "File storing the related variables.")
+	gqlCmd.Flags().StringArrayVar(&gqlVars, "var", gqlVars, "Variable of 'key=value' as a string or 'key:=json', repeatable, overrides the ones of '--variable'")
+	errors.Must(gqlCmd.MarkFlagRequired("query"))
 }
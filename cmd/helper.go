@@ -1,12 +1,35 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jackytck/alti-cli/cloud"
 	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/lifecycle"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/render"
+	"github.com/jackytck/alti-cli/schedule"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/jackytck/alti-cli/types"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 // LoginHint is shown when user wants to perfom operation that requires user token.
-const LoginHint = "You are not login in!\nLogin with 'alti-cli login' or\nSwith account with 'alti-cli account use XXX'"
+const LoginHint = "You are not login in!\nLogin with 'alti-cli login' or\nSwith account with 'alti-cli account switch XXX'"
 
 // IsLogin determines if user has logined.
 func IsLogin() bool {
@@ -21,3 +44,574 @@ func IsSuperuser() bool {
 	active := config.GetActive()
 	return gql.IsSuper(active.Endpoint, active.Key, active.Token)
 }
+
+// newProgressReporter creates the reporter of the upload progress,
+// replaced by the dashboard of 'alti-cli ui'.
+var newProgressReporter = service.NewProgressReporter
+
+// deadline is the '--deadline' of the long-running operations, zero means none.
+var deadline time.Duration
+
+// openDigestCache opens the cache of image digests.
+// Return nil if it is disabled by '--no-cache' or could not be opened.
+func openDigestCache() *db.DigestCache {
+	if noCache {
+		return nil
+	}
+	cache, err := db.OpenDigestCache()
+	if err != nil {
+		logging.Warnln("Digest cache is disabled:", err)
+		return nil
+	}
+	return cache
+}
+
+// uprightDir gives the directory of the upright copies of the rotated images
+// if '--fix-orientation' is set, empty otherwise.
+func uprightDir() string {
+	if !fixOrientation {
+		return ""
+	}
+	return copiesDir("upright")
+}
+
+// dcrawPath is the path of dcraw, found by checkConvertRaw.
+var dcrawPath string
+
+// checkConvertRaw exits if '--convert-raw' or '--quality' is invalid, or if
+// dcraw is not found for converting.
+func checkConvertRaw(meth string) {
+	if convertRaw == "" {
+		return
+	}
+	convertRaw = strings.ToLower(convertRaw)
+	if convertRaw == "jpg" {
+		convertRaw = file.RawJPEG
+	}
+	if convertRaw != file.RawJPEG {
+		logging.Errorf("Unknown --convert-raw: %q, valid format is: %q\n", convertRaw, file.RawJPEG)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	if rawQuality < 1 || rawQuality > 100 {
+		logging.Errorf("Invalid --quality %d, expect 1 to 100\n", rawQuality)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	if meth == service.DirectUploadMethod {
+		logging.Errorln("--convert-raw is not supported by direct upload, as the converted copies are not under the served directory")
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	p, err := file.DcrawPath()
+	if err != nil {
+		logging.Errorln("dcraw is required for converting the RAW images, please install it and add it to PATH")
+		errors.Exit(err)
+	}
+	dcrawPath = p
+}
+
+// rawDir gives the directory of the JPEG copies of the RAW images if
+// '--convert-raw' is set, empty otherwise.
+func rawDir() string {
+	if convertRaw == "" {
+		return ""
+	}
+	return copiesDir("raw")
+}
+
+// checkResize exits if '--max-dimension' or '--jpeg-quality' is invalid.
+func checkResize(meth string) {
+	if maxDimension < 0 {
+		logging.Errorf("Invalid --max-dimension %d, expect a positive number of pixels, or 0 to disable\n", maxDimension)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	if maxDimension == 0 {
+		return
+	}
+	if jpegQuality < 1 || jpegQuality > 100 {
+		logging.Errorf("Invalid --jpeg-quality %d, expect 1 to 100\n", jpegQuality)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	if meth == service.DirectUploadMethod {
+		logging.Errorln("--max-dimension is not supported by direct upload, as the downsized copies are not under the served directory")
+		errors.Exit(errors.ErrInvalidInput)
+	}
+}
+
+// resizeDir gives the directory of the downsized copies of the images if
+// '--max-dimension' is set, empty otherwise.
+func resizeDir() string {
+	if maxDimension <= 0 {
+		return ""
+	}
+	return copiesDir("resized")
+}
+
+// copiesDir gives the directory of name under the config directory, where
+// the copies of the images uploaded instead of the originals are kept across
+// runs for resuming, until removed by 'cache clear'.
+func copiesDir(name string) string {
+	d, err := config.GetConfigDir()
+	errors.Must(err)
+	return filepath.Join(d, name)
+}
+
+// checkChecksumAlgo exits if the algorithm of '--checksum' is not supported.
+func checkChecksumAlgo() {
+	checksumAlgo = strings.ToLower(checksumAlgo)
+	if _, ok := text.Contains(file.ChecksumAlgorithms, checksumAlgo); !ok {
+		logging.Errorf("Unknown checksum: %q, valid algorithms are: %q\n", checksumAlgo, strings.Join(file.ChecksumAlgorithms, ", "))
+		errors.Exit(errors.ErrInvalidInput)
+	}
+}
+
+// imageRoot gives the root of the relative urls of the images of '--dir',
+// i.e. its parent if it is a zip archive.
+func imageRoot(d string) string {
+	if file.IsZipName(d) {
+		return filepath.Dir(d)
+	}
+	return d
+}
+
+// checkWaitStrategy exits if the strategy of '--wait-strategy' is not supported.
+func checkWaitStrategy() {
+	waitStrategy = strings.ToLower(waitStrategy)
+	if _, ok := text.Contains(cloud.WaitStrategies, waitStrategy); !ok {
+		logging.Errorf("Unknown wait strategy: %q, valid strategies are: %q\n", waitStrategy, strings.Join(cloud.WaitStrategies, ", "))
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	if verifyUpload && waitStrategy == cloud.WaitNone {
+		logging.Errorln("--verify waits for the uploads to be ready, which is skipped by --wait-strategy none")
+		errors.Exit(errors.ErrInvalidInput)
+	}
+}
+
+// uploadWindow is the '--schedule' of the uploads, e.g. '22:00-06:00', and
+// uploadSchedule is its parsed window, nil for any time.
+var uploadWindow string
+var uploadSchedule *schedule.Window
+
+// checkSchedule exits if the window of '--schedule' is invalid.
+func checkSchedule() {
+	if uploadWindow == "" {
+		return
+	}
+	w, err := schedule.Parse(uploadWindow)
+	if err != nil {
+		logging.Errorf("Invalid --schedule: %v\n", err)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	uploadSchedule = w
+	logging.Infof("Uploads only run within %s (local time), pausing outside of it\n", w)
+}
+
+// apiServerCheck checks if the api server accepts changes, or only if it is
+// online, possibly in ReadOnly mode, for the read-only runs, e.g. '--dry-run'.
+func apiServerCheck(readOnly bool) service.CheckFn {
+	if readOnly {
+		return service.CheckAPIServerLite()
+	}
+	return service.CheckAPIServer()
+}
+
+// hasFeature tells if the optional feature is supported by the api server,
+// assumed so if its capabilities could not be queried.
+func hasFeature(feature string) bool {
+	c, err := gql.Capabilities()
+	return err != nil || c.Has(feature)
+}
+
+// maxFileSize gives the max bytes of a file of kind of the api server, zero
+// if unlimited or unknown.
+func maxFileSize(kind string) int64 {
+	c, err := gql.Capabilities()
+	if err != nil {
+		return 0
+	}
+	return c.MaxFileSize(kind)
+}
+
+// checkDiskSpace exits if the disk of p has less than need free bytes, so that
+// a download fails early instead of filling up the disk. It is skipped if the
+// free space could not be told.
+func checkDiskSpace(p string, need int64) {
+	var msg string
+	err := service.Check(func(format string, a ...interface{}) {
+		msg = fmt.Sprintf(format, a...)
+	}, service.CheckDiskSpace(p, uint64(need)))
+	switch {
+	case err == errors.ErrDiskSpace:
+		logging.Errorf("Insufficient disk space: %s. Free up some space or choose another directory.\n", msg)
+		errors.Exit(err)
+	case err != nil:
+		logging.Debugln(msg)
+	}
+}
+
+// spreadBuckets and bucketStrategy are the '--buckets' and '--bucket-strategy' of
+// spreading the image uploads across buckets.
+var spreadBuckets string
+var bucketStrategy string
+
+// imageBuckets is the picker of the buckets of the image uploads, nil for the
+// single bucket.
+var imageBuckets *cloud.BucketPicker
+
+// suggestImageBucket sets the bucket of the image uploads, or imageBuckets by
+// '--buckets' and '--bucket-strategy', in which case bucket is the list of
+// buckets for the manifest.
+func suggestImageBucket(meth string) {
+	if spreadBuckets == "" && bucketStrategy == "" {
+		b, err := service.SuggestBucket(meth, bucket, "image", autoBucket)
+		if err != nil {
+			errors.Exit(err)
+		}
+		bucket = b
+		if bucket != "" {
+			logging.Infof("Bucket %q is chosen", bucket)
+		}
+		return
+	}
+	if meth == service.DirectUploadMethod {
+		logging.Errorln("--buckets and --bucket-strategy are not for direct upload")
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	if bucket != "" {
+		logging.Errorln("--bucket could not be used with --buckets or --bucket-strategy")
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	if bucketStrategy == "" {
+		bucketStrategy = cloud.BucketRoundRobin
+	}
+	if _, ok := text.Contains(cloud.BucketStrategies, bucketStrategy); !ok {
+		logging.Errorf("Unknown bucket strategy: %q, valid strategies are: %q\n", bucketStrategy, strings.Join(cloud.BucketStrategies, ", "))
+		errors.Exit(errors.ErrInvalidInput)
+	}
+
+	// all of the buckets of the cloud if not given
+	var buckets []cloud.WeightedBucket
+	if spreadBuckets == "" {
+		names, err := gql.BucketList("image", meth)
+		if err != nil {
+			errors.Exit(err)
+		}
+		for _, n := range names {
+			buckets = append(buckets, cloud.WeightedBucket{Name: n, Weight: 1})
+		}
+	} else {
+		bs, err := cloud.ParseBuckets(spreadBuckets)
+		if err != nil {
+			logging.Errorln(err)
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		for _, b := range bs {
+			name, valid, err := gql.QueryBucket("image", meth, b.Name)
+			if err != nil {
+				logging.Errorf("Valid buckets are: %q. Your input: %q\n", valid, b.Name)
+				errors.Exit(errors.ErrBucketInvalid)
+			}
+			buckets = append(buckets, cloud.WeightedBucket{Name: name, Weight: b.Weight})
+		}
+	}
+
+	// weighted by the inverse of the latency, the unreachable ones are dropped
+	if bucketStrategy == cloud.BucketFastest {
+		names := make([]string, len(buckets))
+		for i, b := range buckets {
+			names[i] = b.Name
+		}
+		rtts := service.ProbeBuckets(meth, names)
+		var fastest time.Duration
+		for _, rtt := range rtts {
+			if fastest == 0 || rtt < fastest {
+				fastest = rtt
+			}
+		}
+		for i, b := range buckets {
+			rtt, ok := rtts[b.Name]
+			if !ok {
+				logging.Warnf("Bucket %q is not measurable and skipped\n", b.Name)
+				buckets[i].Weight = 0
+				continue
+			}
+			buckets[i].Weight = b.Weight * int(math.Max(1, math.Round(10*float64(fastest)/float64(rtt))))
+		}
+	}
+
+	imageBuckets = cloud.NewBucketPicker(buckets, bucketStrategy == cloud.BucketRandom)
+	if len(imageBuckets.Buckets()) == 0 {
+		logging.Errorf("No %s bucket could be chosen\n", meth)
+		errors.Exit(errors.ErrBucketInvalid)
+	}
+	var ws []string
+	for _, b := range imageBuckets.Buckets() {
+		ws = append(ws, fmt.Sprintf("%s:%d", b.Name, b.Weight))
+	}
+	logging.Infof("Images are spread across buckets %s by %s", strings.Join(ws, ", "), bucketStrategy)
+	bucket = imageBuckets.String()
+}
+
+// printTemplate prints items by the Go template of '--template' if given,
+// and tells if printed.
+func printTemplate(items interface{}) bool {
+	if outputTemplate == "" {
+		return false
+	}
+	t, err := render.New(outputTemplate)
+	if err == nil {
+		err = t.Render(os.Stdout, items)
+	}
+	if err != nil {
+		logging.Errorln("Invalid template:", err)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	return true
+}
+
+// outputTable is a table printed to stdout by '--style' and '--columns'.
+type outputTable struct {
+	*render.Table
+}
+
+// newTable creates a table printed to stdout by '--style' and '--columns'.
+func newTable() outputTable {
+	return outputTable{render.NewTable(os.Stdout, tableStyle, tableColumns)}
+}
+
+// projectsTable creates a table of the projects.
+func projectsTable(ps []types.Project) outputTable {
+	table := newTable()
+	table.SetHeader(types.ProjectHeaderString())
+	for _, p := range ps {
+		table.Append(p.RowString(gql.WebEndpoint()))
+	}
+	return table
+}
+
+// Render prints the table, exits if any column of '--columns' is unknown.
+func (t outputTable) Render() {
+	if err := t.Table.Render(); err != nil {
+		logging.Errorln(err)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+}
+
+// minQualityFilter returns the filter of low quality images by '--min-quality',
+// i.e. the min sharpness. Return nil if it is not set.
+func minQualityFilter() *file.QualityFilter {
+	if minQuality <= 0 {
+		return nil
+	}
+	qf := file.DefaultQualityFilter
+	qf.MinSharpness = minQuality
+	return &qf
+}
+
+// stateTimeout gives the timeout of checking the upload states of the images
+// by '--timeout', which is in seconds.
+func stateTimeout() time.Duration {
+	return time.Second * time.Duration(timeout)
+}
+
+// pathFilter returns the filter of the walked paths by '--skip', '--include',
+// '--exclude', '--exclude-from' and '--hidden'. Exit if any pattern is invalid.
+func pathFilter() *file.PathFilter {
+	ex := excludes
+	if excludeFrom != "" {
+		f, err := os.Open(excludeFrom)
+		var ps []string
+		if err == nil {
+			ps, err = file.ReadPathList(f)
+			f.Close()
+		}
+		if err != nil {
+			logging.Errorf("Invalid --exclude-from %q: %v\n", excludeFrom, err)
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		ex = append(append([]string{}, excludes...), ps...)
+	}
+	f, err := file.NewPathFilter(skip, includes, ex, withHidden)
+	if err != nil {
+		logging.Errorf("Invalid path pattern: %v\n", err)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	return f
+}
+
+// listPaths prints the walked paths for '--list-only', and the total.
+func listPaths(paths <-chan string, errc <-chan error) {
+	var n int
+	for p := range paths {
+		fmt.Println(p)
+		n++
+	}
+	errors.Must(<-errc)
+	logging.Infof("%d file(s) would be processed\n", n)
+}
+
+// warnChecksumMismatch warns if the checksum of the uploaded img differs
+// from the one computed by the server, by '--verify'.
+func warnChecksumMismatch(img db.Image) {
+	if strings.HasPrefix(img.Error, errors.ErrChecksumMismatch.Error()) {
+		logging.Warnf("Image %q differs from the uploaded one, %s\n", img.LocalPath, img.Error)
+	}
+}
+
+// removeImages removes the remote images from project pid.
+// Failures are logged and skipped.
+func removeImages(pid string, imgs []types.ProjectImage) {
+	var rmCnt int
+	for _, r := range imgs {
+		_, err := gql.RemoveImage(pid, r.ID)
+		if err != nil {
+			logging.Warnf("Failed to remove %q: %v\n", r.Name, err)
+			continue
+		}
+		rmCnt++
+		if verbose {
+			logging.Infof("Removed %q\n", r.Name)
+		}
+	}
+	if len(imgs) > 0 {
+		logging.Infof("%d out of %d images are removed.", rmCnt, len(imgs))
+	}
+}
+
+// interruptContext returns a context canceled on the first ctrl+c or SIGTERM,
+// or when '--deadline' is passed, so that the running pipelines could finish
+// cleaning up. Another ctrl+c, or the first one if '--on-interrupt' is abort,
+// runs the registered cleanup hooks and quits immediately. The returned cancel
+// also stops the handling of the signals.
+func interruptContext() (context.Context, context.CancelFunc) {
+	notifyOnCompletion()
+	ctx, cancel := context.WithCancel(context.Background())
+	if deadline > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), deadline)
+	}
+	cc := make(chan os.Signal, 1)
+	signal.Notify(cc, os.Interrupt, syscall.SIGTERM)
+	// done ends the signal handling once the command returns
+	done := make(chan struct{})
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			signal.Stop(cc)
+			close(done)
+		})
+	}
+	go func() {
+		select {
+		case <-cc:
+			fmt.Println()
+			notifyInterrupted()
+			if onInterrupt == lifecycle.Abort {
+				abortNow()
+			}
+			logging.Infoln("Stopping... Press ctrl+c again to quit immediately.")
+			cancel()
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				logging.Warnf("Stopping by the deadline of %s...\n", deadline)
+			}
+			signal.Stop(cc)
+			return
+		case <-done:
+			return
+		}
+		select {
+		case <-cc:
+			fmt.Println()
+			abortNow()
+		case <-done:
+		}
+	}()
+	return ctx, stop
+}
+
+// abortNow runs the registered cleanup hooks and exits with non-zero status.
+func abortNow() {
+	if hooks := lifecycle.Pending(); len(hooks) > 0 {
+		logging.Infof("Cleaning up %s...\n", strings.Join(hooks, ", "))
+	}
+	lifecycle.Cleanup()
+	logging.Infoln("Bye!")
+	os.Exit(1)
+}
+
+// exitIfInterrupted cancels ctx of interruptContext and exits with non-zero
+// status if it was interrupted or passed the deadline. It is deferred before
+// any cleanup, so that it runs after all of them.
+func exitIfInterrupted(ctx context.Context, cancel context.CancelFunc) {
+	err := ctx.Err()
+	cancel()
+	switch err {
+	case nil:
+	case context.DeadlineExceeded:
+		logging.Warnf("Stopped by the deadline of %s, the results are partial. Run the same command again to continue.\n", deadline)
+		errors.Exit(errors.ErrDeadlineExceeded)
+	default:
+		lifecycle.Cleanup()
+		logging.Infoln("Bye!")
+		os.Exit(1)
+	}
+}
+
+// manifestFile gives the path of the upload manifest of project pid,
+// i.e. '--manifest' or the default path.
+func manifestFile(pid string) string {
+	if manifestPath != "" {
+		return manifestPath
+	}
+	p, err := db.ManifestPath(pid)
+	if err != nil {
+		logging.Errorln("Manifest could not be saved:", err)
+	}
+	return p
+}
+
+// saveManifest writes the upload manifest m to p,
+// and records the session in the upload history.
+func saveManifest(m *db.Manifest, p string) {
+	if p != "" {
+		if err := m.Save(p); err != nil {
+			logging.Errorln("Manifest could not be saved:", err)
+			p = ""
+		} else {
+			logging.Infof("Manifest is written to %q\n", p)
+		}
+	}
+	if p == "" {
+		m.End = time.Now()
+	}
+	recordHistory(m, p)
+}
+
+// recordHistory records the upload session of manifest m, written to p,
+// in the upload history.
+func recordHistory(m *db.Manifest, p string) {
+	h, err := db.OpenHistory()
+	if err != nil {
+		logging.Warnln("Upload history could not be opened:", err)
+		return
+	}
+	defer h.Close()
+	s, err := h.Record(m, p)
+	if err != nil {
+		logging.Warnln("Upload history could not be recorded:", err)
+		return
+	}
+	notifyStats["total"] += s.Total
+	notifyStats["failed"] += s.Failed
+	if s.Failed > 0 {
+		logging.Infof("Retry the failed files by: 'alti-cli history retry %d'\n", s.ID)
+	}
+}
+
+// promptPassword reads a password from the terminal without echoing it.
+func promptPassword(prompt string) string {
+	fmt.Print(prompt)
+	b, err := terminal.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+	errors.Must(err)
+	return string(b)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/spf13/cobra"
+)
+
+var historyLimit = 20
+
+// historyListCmd represents the history list command
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List past upload sessions",
+	Long:  "List the latest upload sessions of images, meta files and models, with the number of failed files.",
+	Run: func(cmd *cobra.Command, args []string) {
+		h, err := db.OpenHistory()
+		if err != nil {
+			errors.Exit(err)
+		}
+		defer h.Close()
+		sessions, err := h.Sessions(id, historyLimit)
+		if err != nil {
+			errors.Exit(err)
+		}
+		if len(sessions) == 0 {
+			fmt.Println("No upload session is found.")
+			return
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"Session", "Start", "Duration", "Project", "Method", "Files", "Failed"})
+		for _, s := range sessions {
+			table.Append([]string{
+				fmt.Sprintf("%d", s.ID),
+				s.Start.Local().Format("2006-01-02 15:04:05"),
+				s.End.Sub(s.Start).Round(time.Second).String(),
+				s.PID,
+				s.Method,
+				fmt.Sprintf("%d", s.Total),
+				fmt.Sprintf("%d", s.Failed),
+			})
+		}
+		table.Render()
+	},
+}
+
+func init() {
+	historyCmd.AddCommand(historyListCmd)
+	historyListCmd.Flags().StringVarP(&id, "id", "p", id, "List only the sessions of this project (partial) id")
+	historyListCmd.Flags().IntVarP(&historyLimit, "limit", "l", historyLimit, "Maximum number of sessions to list, 0 for all")
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/jackytck/alti-cli/web"
+	"github.com/spf13/cobra"
+)
+
+// historyRetryCmd represents the history retry command
+var historyRetryCmd = &cobra.Command{
+	Use:   "retry SESSION",
+	Short: "Retry the failed files of an upload session",
+	Long:  "Upload again the images, meta files and models that failed in an upload session. The failed images are removed from the project before uploading. The retry is recorded as a new session.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s := historySession(args[0])
+
+		// failed files that still exist locally
+		var images, metas, models []db.ManifestEntry
+		for _, e := range s.Entries {
+			if !e.Failed() {
+				continue
+			}
+			if e.Path == "" {
+				logging.Warnf("%q has no local path and could not be retried\n", e.Filename)
+				continue
+			}
+			if _, err := file.StatFile(e.Path); err != nil {
+				logging.Warnf("%q could not be retried: %v\n", e.Path, err)
+				continue
+			}
+			switch e.Kind {
+			case "image":
+				images = append(images, e)
+			case "meta":
+				metas = append(metas, e)
+			case "model":
+				models = append(models, e)
+			}
+		}
+		total := len(images) + len(metas) + len(models)
+		if total == 0 {
+			fmt.Println("Nothing to retry.")
+			return
+		}
+
+		// same method and bucket as the session, unless overridden
+		if !cmd.Flags().Changed("method") && s.Method != "" {
+			method = s.Method
+		}
+		if !cmd.Flags().Changed("bucket") {
+			bucket = s.Bucket
+		}
+		id = s.PID
+
+		fmt.Printf("Retry %d images, %d meta files and %d models of project %q by %q.\n", len(images), len(metas), len(models), s.PID, method)
+		fmt.Print("Continue to retry or not? (Y/N): ")
+		if assumeYes {
+			fmt.Println("Yes")
+		} else {
+			var ans string
+			fmt.Scanln(&ans)
+			ans = strings.ToUpper(ans)
+			if ans != "Y" && ans != service.Yes {
+				logging.Infoln("Cancelled.")
+				return
+			}
+		}
+
+		if len(images) > 0 {
+			retryImages(s.PID, images)
+		}
+		for _, e := range metas {
+			meta = e.Path
+			importMetaCmd.Run(importMetaCmd, nil)
+		}
+		for _, e := range models {
+			model = e.Path
+			importModelCmd.Run(importModelCmd, nil)
+		}
+	},
+}
+
+// retryImages removes the failed images from project pid and uploads them again.
+func retryImages(pid string, entries []db.ManifestEntry) {
+	ctx, cancel := interruptContext()
+	defer exitIfInterrupted(ctx, cancel)
+
+	meth, mOK := service.SuggestUploadMethod(method, "image")
+	if err := service.Check(
+		nil,
+		service.CheckAPIServer(),
+		service.CheckUploadMethod("image", meth, ip, port, mOK),
+		service.CheckPID("image", pid),
+	); err != nil {
+		errors.Exit(err)
+	}
+	checkWaitStrategy()
+	b, err := service.SuggestBucket(meth, bucket, "image", autoBucket)
+	if err != nil {
+		errors.Exit(err)
+	}
+	bucket = b
+
+	// remove the registered but failed images, so that they could be registered again
+	var toRemove []types.ProjectImage
+	paths := make([]string, len(entries))
+	for i, e := range entries {
+		if e.ID != "" {
+			toRemove = append(toRemove, types.ProjectImage{ID: e.ID, Name: e.Filename})
+		}
+		paths[i] = e.Path
+	}
+	removeImages(pid, toRemove)
+
+	root := commonDir(paths)
+	var baseURL string
+	if meth == service.DirectUploadMethod {
+		bu, done, err := web.StartLocalServer(root, ip, port, false)
+		errors.Must(err)
+		defer done()
+		baseURL = bu
+	}
+
+	pathc := make(chan string)
+	go func() {
+		defer close(pathc)
+		for _, p := range paths {
+			pathc <- p
+		}
+	}()
+	result := make(chan file.ImageDigest)
+	digester := file.ImageDigester{
+		Root:   root,
+		PID:    pid,
+		Ctx:    ctx,
+		Paths:  pathc,
+		Result: result,
+	}
+	digester.Run(thread)
+
+	var digests []file.ImageDigest
+	for r := range result {
+		if r.Error != nil {
+			logging.Warnf("Invalid image: %q, Reason: %v", r.Path, r.Error)
+			continue
+		}
+		digests = append(digests, r)
+	}
+	if len(digests) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	mf := db.NewManifest(pid, meth, bucket)
+	uploadDigests(ctx, pid, meth, baseURL, digests, mf)
+	if ctx.Err() != nil {
+		return
+	}
+	saveManifest(mf, manifestFile(pid))
+}
+
+// commonDir gives the deepest directory containing all of the paths.
+func commonDir(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	ret := filepath.Dir(paths[0])
+	for _, p := range paths[1:] {
+		for !strings.HasPrefix(p, ret+string(filepath.Separator)) && ret != filepath.Dir(ret) {
+			ret = filepath.Dir(ret)
+		}
+	}
+	return ret
+}
+
+func init() {
+	historyCmd.AddCommand(historyRetryCmd)
+	historyRetryCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload, default is the method of the session")
+	historyRetryCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload, default is the bucket of the session")
+	historyRetryCmd.Flags().BoolVar(&autoBucket, "auto-bucket", autoBucket, "Choose the bucket of the lowest measured upload latency if bucket is not given, cached for 24 hours")
+	historyRetryCmd.Flags().StringVar(&ip, "ip", ip, "IP address of ad-hoc local server for direct upload.")
+	historyRetryCmd.Flags().StringVar(&port, "port", port, "Port of ad-hoc local server for direct upload.")
+	historyRetryCmd.Flags().IntVarP(&timeout, "timeout", "t", timeout, "Timeout of checking upload state in seconds")
+	historyRetryCmd.Flags().StringVar(&waitStrategy, "wait-strategy", waitStrategy, "Strategy of waiting for the upload states: 'fixed', 'backoff' or 'none' (not waiting)")
+	historyRetryCmd.Flags().IntVar(&pollInterval, "poll-interval", pollInterval, "Interval of polling the upload states in seconds, the initial one of 'backoff'")
+	historyRetryCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads to process, default is number of cores x 4")
+	historyRetryCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
+	historyRetryCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info of operation")
+}
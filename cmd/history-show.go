@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var failedOnly bool
+
+// historyShowCmd represents the history show command
+var historyShowCmd = &cobra.Command{
+	Use:   "show SESSION",
+	Short: "Show the files of an upload session",
+	Long:  "Show the recorded state of each file of an upload session. Session id is given by 'alti-cli history list'.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s := historySession(args[0])
+
+		fmt.Printf("Session: %d\tProject: %s\tMethod: %s\n", s.ID, s.PID, s.Method)
+		fmt.Printf("Start: %s\tEnd: %s\n", s.Start.Local().Format("2006-01-02 15:04:05"), s.End.Local().Format("2006-01-02 15:04:05"))
+		if s.Path != "" {
+			fmt.Printf("Manifest: %s\n", s.Path)
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"Kind", "Path", "ID", "State", "Error"})
+		for _, e := range s.Entries {
+			if failedOnly && !e.Failed() {
+				continue
+			}
+			table.Append([]string{e.Kind, e.Path, e.ID, e.State, e.Error})
+		}
+		if table.NumLines() > 0 {
+			table.Render()
+		}
+		fmt.Printf("%d out of %d files failed.\n", s.Failed, s.Total)
+	},
+}
+
+func init() {
+	historyCmd.AddCommand(historyShowCmd)
+	historyShowCmd.Flags().BoolVar(&failedOnly, "failed", failedOnly, "Show only the failed files")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Root command for all upload history related commands",
+	Long:  `'alti-cli history list' to list the past upload sessions`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("See alti-cli help history")
+	},
+}
+
+// historySession gives the upload session of the given id argument,
+// with all of its entries. Exit if it is not found.
+func historySession(arg string) *db.Session {
+	sid, err := strconv.Atoi(arg)
+	if err != nil {
+		logging.Errorf("Invalid session id: %q\n", arg)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	h, err := db.OpenHistory()
+	if err != nil {
+		errors.Exit(err)
+	}
+	defer h.Close()
+	s, err := h.Session(sid)
+	if err != nil {
+		logging.Errorf("Session %d could not be found: %v\n", sid, err)
+		errors.Exit(errors.ErrSessionNotFound)
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var batchParallel int
+
+// batchResult is the result of a job of 'import batch'.
+type batchResult struct {
+	job     types.BatchJob
+	pid     string
+	done    []string // finished steps
+	step    string   // failed step
+	err     error
+	elapsed time.Duration
+}
+
+// importBatchCmd represents the import batch command
+var importBatchCmd = &cobra.Command{
+	Use:   "batch MANIFEST",
+	Short: "Import multiple projects from a manifest",
+	Long: `Create or find each project of a yaml or json manifest, import its images, meta files or model, and optionally start its reconstruction.
+The jobs are run sequentially, or in parallel by 'parallel' of the manifest or '--parallel'. A summary table is printed at the end.
+
+Manifest example:
+  parallel: 2
+  jobs:
+    - name: Site A
+      projectType: pro
+      images: /data/site-a
+      meta: /data/site-a
+      method: s3
+      start: true
+    - pid: 5d37e
+      images: /data/site-b.zip
+    - name: Bunny
+      modelType: CAD
+      model: /data/bunny.zip`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		start := time.Now()
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+
+		// pre-checks
+		b, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			errors.Exit(err)
+		}
+		m, err := types.ParseBatchManifest(args[0], b)
+		if err != nil {
+			logging.Errorln("Invalid manifest:", err)
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		checks := []service.CheckFn{service.CheckAPIServer(), service.CheckIsLogin()}
+		for _, j := range m.Jobs {
+			if j.Images != "" {
+				checks = append(checks, service.CheckDirOrZip(j.Images))
+			}
+			for _, f := range []string{j.Meta, j.Model} {
+				if f != "" {
+					checks = append(checks, service.CheckFile(f))
+				}
+			}
+		}
+		if err := service.Check(nil, checks...); err != nil {
+			errors.Exit(err)
+		}
+		exe, err := os.Executable()
+		errors.Must(err)
+		if batchParallel > 0 {
+			m.Parallel = batchParallel
+		}
+
+		// run the jobs by a pool of m.Parallel workers
+		jobs := make(chan int)
+		results := make([]batchResult, len(m.Jobs))
+		var finished int
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for w := 0; w < m.Parallel && w < len(m.Jobs); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					results[i] = runBatchJob(ctx, exe, m.Jobs[i], &mu)
+					mu.Lock()
+					finished++
+					logging.Infof("Finished %d/%d jobs\n", finished, len(m.Jobs))
+					mu.Unlock()
+				}
+			}()
+		}
+		for i := range m.Jobs {
+			if ctx.Err() != nil {
+				results[i] = batchResult{job: m.Jobs[i], err: ctx.Err()}
+				continue
+			}
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+
+		// summary
+		failed := 0
+		table := newTable()
+		table.SetHeader([]string{"Job", "Project", "Imported", "Result", "Elapsed"})
+		for _, r := range results {
+			res := "OK"
+			if r.err != nil {
+				failed++
+				res = fmt.Sprintf("FAILED: %v", r.err)
+				if r.step != "" {
+					res = fmt.Sprintf("FAILED at %s: %v", r.step, r.err)
+				}
+			}
+			table.Append([]string{r.job.Title(), r.pid, strings.Join(r.done, ", "), res, r.elapsed.Round(time.Second).String()})
+		}
+		table.Render()
+		fmt.Printf("Succeeded: %d\tFailed: %d\tTook: %s\n", len(results)-failed, failed, time.Since(start).Round(time.Second))
+		if failed > 0 && ctx.Err() == nil {
+			errors.Exit(errors.ErrBatchFailed)
+		}
+	},
+}
+
+// runBatchJob creates or finds the project of job j, then runs each of its
+// import steps by a sub-process of exe. The outputs are prefixed by the
+// title of the job and written under the lock mu.
+func runBatchJob(ctx context.Context, exe string, j types.BatchJob, mu *sync.Mutex) batchResult {
+	start := time.Now()
+	ret := batchResult{job: j}
+	defer func() {
+		ret.elapsed = time.Since(start)
+	}()
+
+	// a. project
+	if j.PID != "" {
+		p, err := gql.SearchProjectID(j.PID, true)
+		if err != nil || p == nil {
+			ret.step, ret.err = "project", errors.ErrProjNotFound
+			return ret
+		}
+		ret.pid = p.ID
+	} else {
+		pt, vis := j.ProjectType, j.Visibility
+		if pt == "" {
+			pt = "free"
+		}
+		if vis == "" {
+			vis = "public"
+		}
+		var err error
+		if j.IsModel() {
+			mt := j.ModelType
+			if mt == "" {
+				mt = "CAD"
+			}
+			ret.pid, err = gql.CreateProject(j.Name, pt, mt, vis)
+		} else {
+			ret.pid, err = gql.CreateReconProject(j.Name, pt, vis, gql.ReconOptions{})
+		}
+		if err != nil {
+			ret.step, ret.err = "project", err
+			return ret
+		}
+		logging.Infof("[%s] Created project %s\n", j.Title(), ret.pid)
+	}
+
+	// b. import and start
+	type step struct {
+		name string
+		args []string
+	}
+	upload := func(kind, flag, src string) step {
+		args := []string{"import", kind, "-p", ret.pid, flag, src}
+		if j.Method != "" {
+			args = append(args, "-m", j.Method)
+		}
+		if j.Bucket != "" {
+			args = append(args, "-b", j.Bucket)
+		}
+		return step{kind, args}
+	}
+	var steps []step
+	if j.Images != "" {
+		s := upload("image", "-d", j.Images)
+		s.args = append(s.args, "-y")
+		steps = append(steps, s)
+	}
+	if j.Meta != "" {
+		steps = append(steps, upload("meta", "-f", j.Meta))
+	}
+	if j.Model != "" {
+		steps = append(steps, upload("model", "-f", j.Model))
+	}
+	if j.Start {
+		args := []string{"project", "start", "-p", ret.pid}
+		if j.TaskType != "" {
+			args = append(args, "-t", j.TaskType)
+		}
+		steps = append(steps, step{"start", args})
+	}
+
+	out := &prefixWriter{prefix: fmt.Sprintf("[%s] ", j.Title()), mu: mu}
+	defer out.Flush()
+	for _, s := range steps {
+		if err := ctx.Err(); err != nil {
+			ret.step, ret.err = s.name, err
+			return ret
+		}
+		logging.Infof("[%s] Running %s...\n", j.Title(), strings.Join(s.args, " "))
+		c := exec.Command(exe, append(s.args, persistentArgs()...)...)
+		c.Stdout = out
+		c.Stderr = out
+		if err := c.Run(); err != nil {
+			ret.step, ret.err = s.name, err
+			return ret
+		}
+		ret.done = append(ret.done, s.name)
+	}
+	return ret
+}
+
+// persistentArgs gives the global flags that are set explicitly, to be
+// passed to the sub-processes.
+func persistentArgs() []string {
+	var ret []string
+	rootCmd.PersistentFlags().Visit(func(f *pflag.Flag) {
+		ret = append(ret, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+	})
+	return ret
+}
+
+// prefixWriter writes each line with prefix to stdout under the lock mu,
+// so that the outputs of parallel jobs are not mixed within a line.
+type prefixWriter struct {
+	prefix string
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexAny(w.buf.Bytes(), "\r\n")
+		if i < 0 {
+			return len(p), nil
+		}
+		line := w.buf.Next(i + 1)
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		w.mu.Lock()
+		fmt.Fprintf(os.Stdout, "%s%s\n", w.prefix, bytes.TrimRight(line, "\r\n"))
+		w.mu.Unlock()
+	}
+}
+
+// Flush writes the remaining partial line.
+func (w *prefixWriter) Flush() {
+	if w.buf.Len() > 0 {
+		io.WriteString(w, "\n")
+	}
+}
+
+func init() {
+	importCmd.AddCommand(importBatchCmd)
+	importBatchCmd.Flags().IntVarP(&batchParallel, "parallel", "n", batchParallel, "Number of jobs run at the same time, overriding 'parallel' of the manifest")
+}
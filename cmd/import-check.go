@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
+)
+
+var checkFormat = "text"
+
+// checkFormats are the output formats of '--check-only'.
+var checkFormats = []string{"text", "json"}
+
+// runCheckOnly runs all of the items for '--check-only', prints the report in
+// checkFormat and exits with the error of the first failed check.
+func runCheckOnly(items ...service.CheckItem) {
+	if _, ok := text.Contains(checkFormats, checkFormat); !ok {
+		logging.Errorf("Unknown format: %q, valid formats are: %q\n", checkFormat, strings.Join(checkFormats, ", "))
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	r := service.RunChecks(items...)
+	switch checkFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		errors.Must(enc.Encode(r))
+	default:
+		table := newTable()
+		table.SetHeader([]string{"Check", "Result", "Error", "Messages"})
+		table.SetAutoWrapText(false)
+		for _, c := range r.Checks {
+			res := "pass"
+			if !c.Passed {
+				res = "FAIL"
+			}
+			table.Append([]string{c.Name, res, c.Error, strings.Join(c.Messages, "\n")})
+		}
+		table.Render()
+	}
+	if err := r.Err(); err != nil {
+		os.Exit(errors.ExitCode(err))
+	}
+}
+
+// imageScan reads the headers of the local images for '--check-only',
+// without computing their checksums.
+type imageScan struct {
+	once   sync.Once
+	images int
+	gp     float64
+	dups   []string // filenames shared by different paths, ignoring case
+	err    error
+}
+
+// scan walks dir once and sums the giga-pixel of its images.
+func (s *imageScan) scan() {
+	s.once.Do(func() {
+		paths, errc := file.WalkArchivesBy(context.Background(), dir, pathFilter())
+		defer file.CloseArchives()
+		var imgs []string
+		for p := range paths {
+			if ok, err := file.IsImageFile(p); err != nil || !ok {
+				continue
+			}
+			w, h, err := file.GetImageSize(p)
+			if err != nil {
+				continue
+			}
+			s.images++
+			s.gp += file.DimToGigaPixel(w, h)
+			imgs = append(imgs, p)
+		}
+		s.err = <-errc
+		s.dups = file.DuplicateFilenames(imgs)
+	})
+}
+
+// checkFilenames checks if the filenames of the local images are unique.
+func (s *imageScan) checkFilenames() service.CheckFn {
+	return func(logger service.LogFn) error {
+		if s.scan(); s.err != nil {
+			return s.err
+		}
+		if len(s.dups) > 0 {
+			logger("Filenames shared by different images: %q", s.dups)
+			return errors.ErrImageFilenameDuplicate
+		}
+		return nil
+	}
+}
+
+// checkBalance checks if the balance could pay the estimated coins of
+// reconstructing the local images.
+func (s *imageScan) checkBalance() service.CheckFn {
+	return func(logger service.LogFn) error {
+		if s.scan(); s.err != nil {
+			return s.err
+		}
+		_, user, err := gql.MySelf()
+		if err != nil {
+			return err
+		}
+		coinPerGP := user.Membership.CoinPerGP
+		if coinPerGP <= 0 {
+			// same as the price of pro project without membership
+			coinPerGP = 1
+		}
+		estimate := s.gp * coinPerGP
+		logger("%d images, %.2f GP, estimated %.2f coins", s.images, s.gp, estimate)
+		return service.CheckBalance(estimate)(logger)
+	}
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+)
+
+// csvImage is a row of the csv of images to import:
+// local path or url, filename (optional for local path) and checksum (optional).
+type csvImage struct {
+	Source   string
+	Filename string
+	Checksum string
+}
+
+// isURL tells if the source is a url instead of a local path.
+func (c csvImage) isURL() bool {
+	u, err := url.Parse(c.Source)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "s3")
+}
+
+// httpURL gives the http url of the source, s3://bucket/key is converted
+// into the virtual-hosted url of the bucket.
+func (c csvImage) httpURL() string {
+	u, err := url.Parse(c.Source)
+	if err != nil || u.Scheme != "s3" {
+		return c.Source
+	}
+	return fmt.Sprintf("https://%s.s3.amazonaws.com%s", u.Host, u.Path)
+}
+
+// readImageCSV reads the rows of the csv of images.
+// The first row is skipped if it is a header, i.e. starts with 'path' or 'url'.
+func readImageCSV(csvPath string) ([]csvImage, error) {
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	var ret []csvImage
+	for i := 0; ; i++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) == 0 || rec[0] == "" {
+			continue
+		}
+		if h := strings.ToLower(rec[0]); i == 0 && (h == "path" || h == "url") {
+			continue
+		}
+		c := csvImage{Source: rec[0]}
+		if len(rec) > 1 {
+			c.Filename = rec[1]
+		}
+		if len(rec) > 2 {
+			c.Checksum = rec[2]
+		}
+		if c.Filename == "" && c.isURL() {
+			if u, err := url.Parse(c.Source); err == nil {
+				c.Filename = path.Base(u.Path)
+			}
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+// readURLList reads the http(s) urls of images, one per line.
+// Blank lines and lines starting with '#' are skipped.
+func readURLList(p string) ([]csvImage, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ret []csvImage
+	scanner := bufio.NewScanner(f)
+	for i := 1; scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		u, err := url.Parse(line)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			logging.Warnf("Skipped line %d, not a http(s) url: %q\n", i, line)
+			continue
+		}
+		ret = append(ret, csvImage{Source: line, Filename: path.Base(u.Path)})
+	}
+	return ret, scanner.Err()
+}
+
+// importCSV registers the urls and uploads the local paths of the csv of
+// images into project pid, until ctx is canceled.
+func importCSV(ctx context.Context, pid, meth string, rows []csvImage) {
+	var urls, locals []csvImage
+	for _, r := range rows {
+		if r.isURL() {
+			urls = append(urls, r)
+		} else {
+			locals = append(locals, r)
+		}
+	}
+	logging.Infof("Found %d urls and %d local images\n", len(urls), len(locals))
+	if dryRun {
+		logging.Infoln("Dry run: nothing is registered or uploaded.")
+		return
+	}
+	mf := db.NewManifest(pid, meth, bucket)
+	defer saveManifest(mf, manifestFile(pid))
+
+	if len(locals) > 0 {
+		if meth == service.DirectUploadMethod {
+			logging.Errorln("Local images in csv could not be uploaded directly, choose another method by '-m'")
+		} else {
+			uploadDigests(ctx, pid, meth, "", digestCSV(ctx, pid, locals), mf)
+		}
+	}
+	if len(urls) > 0 && ctx.Err() == nil {
+		registerURLs(ctx, pid, urls, mf)
+	}
+}
+
+// digestCSV digests the local images of rows, skipping the existed ones.
+func digestCSV(ctx context.Context, pid string, rows []csvImage) []file.ImageDigest {
+	names := make(map[string]string)
+	for _, r := range rows {
+		names[r.Source] = r.Filename
+	}
+	paths := make(chan string)
+	go func() {
+		defer close(paths)
+		for _, r := range rows {
+			paths <- r.Source
+		}
+	}()
+
+	result := make(chan file.ImageDigest)
+	digester := file.ImageDigester{
+		PID:      pid,
+		WithExif: withExif,
+		Checksum: checksumAlgo,
+		Cache:    openDigestCache(),
+		Ctx:      ctx,
+		Paths:    paths,
+		Result:   result,
+	}
+	if digester.Cache != nil {
+		defer digester.Cache.Close()
+	}
+	digester.Run(thread)
+
+	var ret []file.ImageDigest
+	for r := range result {
+		if r.Error != nil {
+			logging.Warnf("Invalid image: %q, Reason: %v", r.Path, r.Error)
+			continue
+		}
+		if r.Existed {
+			if verbose {
+				logging.Infof("Already existed: %q\n", r.Path)
+			}
+			continue
+		}
+		ret = append(ret, r)
+	}
+	for i, r := range ret {
+		if n := names[r.Path]; n != "" {
+			ret[i].Filename = n
+		}
+	}
+	return ret
+}
+
+// registerURLs registers the images of urls and checks their states,
+// recording them in manifest m.
+func registerURLs(ctx context.Context, pid string, rows []csvImage, m *db.Manifest) {
+	n := thread
+	if n <= 0 {
+		n = runtime.NumCPU() * 4
+	}
+	in := make(chan csvImage)
+	go func() {
+		defer close(in)
+		for _, r := range rows {
+			in <- r
+		}
+	}()
+
+	imgc := make(chan db.Image)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			for r := range in {
+				img := db.Image{PID: pid, Filename: r.Filename, URL: r.httpURL(), Hash: r.Checksum}
+				gqlImg, err := gql.RegisterImageURL(ctx, pid, img.URL, img.Filename, img.Hash, nil)
+				if err != nil {
+					img.Error = err.Error()
+				} else {
+					img.IID = gqlImg.ID
+					img.State = gqlImg.State
+					img.Stage = db.StageUploaded
+				}
+				if verbose {
+					logging.Infof("Registered %q\n", img.URL)
+				}
+				imgc <- img
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(imgc)
+	}()
+
+	// check for image state: Ready / Invalid / Client timeout
+	checkerRes := make(chan db.Image)
+	checker := cloud.ImageStateChecker{
+		Images:   imgc,
+		Ctx:      ctx,
+		Result:   checkerRes,
+		Timeout:  stateTimeout(),
+		Strategy: waitStrategy,
+		Interval: time.Second * time.Duration(pollInterval),
+	}
+	checker.Run(thread)
+
+	var okCnt int
+	for img := range checkerRes {
+		m.AddImage(img)
+		if img.Error != "" || img.State == "Invalid" {
+			logging.Warnf("Image %q failed: %s\n", img.URL, img.Error)
+			continue
+		}
+		okCnt++
+	}
+	logging.Infof("%d out of %d image urls are registered and ready.", okCnt, len(rows))
+}
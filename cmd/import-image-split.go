@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+)
+
+var maxGP float64
+var maxImages int
+var splitMap string
+
+// checkSplit checks '--max-gp' and '--max-images', and tells if the import is split.
+func checkSplit() bool {
+	if maxGP < 0 || maxImages < 0 {
+		logging.Errorf("Invalid --max-gp %.2f or --max-images %d\n", maxGP, maxImages)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	if maxGP == 0 && maxImages == 0 {
+		return false
+	}
+	if fromCSV != "" || urlList != "" || watchDir || resume {
+		logging.Errorln("--max-gp and --max-images could not be used with --from-csv, --url-list, --watch or --resume")
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	return true
+}
+
+// splitName gives the name of the i-th project split from the one of name,
+// counted from 1.
+func splitName(name string, i int) string {
+	if i <= 1 {
+		return name
+	}
+	return fmt.Sprintf("%s (%d)", name, i)
+}
+
+// findMyProject finds my project of exactly name.
+func findMyProject(name string) (*types.Project, bool) {
+	ps, _, _, err := gql.MyProjects(50, 0, "", "", name)
+	if err != nil {
+		return nil, false
+	}
+	for _, p := range ps {
+		if p.Name == name {
+			return &p, true
+		}
+	}
+	return nil, false
+}
+
+// importSplit imports the new images of dir into project p, and the projects
+// named after p with suffixes ' (2)', ' (3)'..., created as needed, so that
+// each stays within '--max-gp' and '--max-images'. The projects of the
+// previous runs are reused, and the mapping of the images to the projects is
+// written to '--split-map'.
+func importSplit(ctx context.Context, p *types.Project, meth, baseURL string) {
+	// a. digest the images
+	logging.Infof("Checking %s...\n", dir)
+	paths, errc := file.WalkArchivesBy(ctx, dir, pathFilter())
+	defer file.CloseArchives()
+	result := make(chan file.ImageDigest)
+	cache := openDigestCache()
+	if cache != nil {
+		defer cache.Close()
+	}
+	digester := file.ImageDigester{
+		Root:         imageRoot(dir),
+		PID:          p.ID,
+		Quality:      minQualityFilter(),
+		WithExif:     withExif,
+		Checksum:     checksumAlgo,
+		Cache:        cache,
+		UprightDir:   uprightDir(),
+		RawDir:       rawDir(),
+		RawQuality:   rawQuality,
+		ResizeDir:    resizeDir(),
+		MaxDimension: maxDimension,
+		JPEGQuality:  jpegQuality,
+		Dcraw:        dcrawPath,
+		Ctx:          ctx,
+		Paths:        paths,
+		Result:       result,
+	}
+	digester.Run(thread)
+
+	var imgs []file.ImageDigest
+	var existedCnt int
+	for r := range result {
+		switch {
+		case r.Error != nil:
+			logging.Warnf("Invalid image: %q, Reason: %v", r.Path, r.Error)
+		case len(r.Issues) > 0:
+			logging.Warnf("Low quality image: %q, Issues: %s", r.Path, strings.Join(r.Issues, ", "))
+		case r.Existed:
+			existedCnt++
+		default:
+			imgs = append(imgs, r)
+		}
+	}
+	if err := <-errc; err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		panic(err)
+	}
+	// keep the images of a subdirectory together
+	sort.Slice(imgs, func(i, j int) bool {
+		return imgs[i].URL < imgs[j].URL
+	})
+
+	// b. the projects split by the previous runs, skipping their images
+	projs := []types.Project{*p}
+	for i := 2; ; i++ {
+		q, ok := findMyProject(splitName(p.Name, i))
+		if !ok {
+			break
+		}
+		projs = append(projs, *q)
+	}
+	if len(projs) > 1 {
+		var rest []file.ImageDigest
+		for _, img := range imgs {
+			existed := false
+			for _, q := range projs[1:] {
+				if iid, err := gql.FindImage(q.ID, img.SHA1); err == nil && iid != "" {
+					existed = true
+					break
+				}
+			}
+			if existed {
+				existedCnt++
+				continue
+			}
+			rest = append(rest, img)
+		}
+		imgs = rest
+	}
+	if existedCnt > 0 {
+		logging.Infof("%d images already existed in the projects", existedCnt)
+	}
+	if len(imgs) == 0 {
+		logging.Infoln("No new image is found!")
+		return
+	}
+
+	// c. split by the budget
+	used := make([]file.Usage, len(projs))
+	for i, q := range projs {
+		used[i] = file.Usage{GP: q.GigaPixel, Images: q.NumImage}
+	}
+	parts, err := file.SplitByBudget(imgs, maxGP, maxImages, used)
+	if err != nil {
+		logging.Errorln(err)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+
+	// d. plan
+	var totalGP float64
+	table := newTable()
+	table.SetHeader([]string{"ID", "Name", "Images #", "GP", "New images #", "New GP"})
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		var gp float64
+		for _, img := range part {
+			gp += img.GP
+		}
+		totalGP += gp
+		r := []string{"(new)", splitName(p.Name, i+1), "0", "0.00"}
+		if i < len(projs) {
+			q := projs[i]
+			r = []string{q.ID, q.Name, fmt.Sprintf("%d", q.NumImage), fmt.Sprintf("%.2f", q.GigaPixel)}
+		}
+		table.Append(append(r, fmt.Sprintf("%d", len(part)), fmt.Sprintf("%.2f", gp)))
+	}
+	table.Render()
+	logging.Infof("Found %d images, total %.2f GP, split into projects of max %.2f GP and %d images (0 is unlimited)", len(imgs), totalGP, maxGP, maxImages)
+	service.Check(nil, service.CheckGPQuota(totalGP))
+	if dryRun {
+		logging.Infof("Dry run: %d images would be uploaded by %q. Nothing is created, registered or uploaded.\n", len(imgs), meth)
+		return
+	}
+	fmt.Print("Continue to create the projects and import or not? (Y/N): ")
+	if assumeYes {
+		fmt.Println("Yes")
+	} else {
+		var ans string
+		fmt.Scanln(&ans)
+		ans = strings.ToUpper(ans)
+		if ctx.Err() != nil {
+			return
+		}
+		if ans != "Y" && ans != service.Yes {
+			logging.Infoln("Cancelled.")
+			return
+		}
+	}
+
+	// e. create the projects and upload
+	var rows [][]string
+	defer func() {
+		writeSplitMap(p.ID, rows)
+	}()
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		pid, name := p.ID, splitName(p.Name, i+1)
+		if i < len(projs) {
+			pid, name = projs[i].ID, projs[i].Name
+		} else {
+			pid, err = gql.CreateReconProject(name, strings.ToLower(p.ProjectType), visibility, gql.ReconOptions{})
+			if err != nil {
+				logging.Errorf("Project %q could not be created: %v\n", name, err)
+				errors.Exit(errors.ErrProjCreate)
+			}
+			logging.Infof("Created project %q (%s)\n", name, pid)
+		}
+		logging.Infof("Importing %d images into project %q (%s)...\n", len(part), name, pid)
+		mf := db.NewManifest(pid, meth, bucket)
+		uploadDigests(ctx, pid, meth, baseURL, part, mf)
+		saveManifest(mf, manifestFile(pid))
+		for _, img := range part {
+			rows = append(rows, []string{img.Path, img.Filename, pid, name})
+		}
+	}
+}
+
+// writeSplitMap writes the rows of path, filename, project id and name to
+// '--split-map', or next to the manifests of project pid by default.
+func writeSplitMap(pid string, rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+	p := splitMap
+	if p == "" {
+		mp, err := db.ManifestPath(pid)
+		if err != nil {
+			logging.Errorln("Split map could not be saved:", err)
+			return
+		}
+		p = strings.TrimSuffix(mp, ".json") + "-split.csv"
+	}
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	var f *os.File
+	if err == nil {
+		f, err = os.Create(p)
+	}
+	if err != nil {
+		logging.Errorln("Split map could not be saved:", err)
+		return
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	w.Write([]string{"Path", "Filename", "PID", "Project"})
+	w.WriteAll(rows)
+	if err = w.Error(); err != nil {
+		logging.Errorln("Split map could not be saved:", err)
+		return
+	}
+	logging.Infof("Mapping of the images to the projects is written to %q\n", p)
+}
@@ -3,20 +3,20 @@ package cmd
 import (
 	"encoding/csv"
 	"fmt"
-	"log"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
+	"github.com/asdine/storm"
 	"github.com/c2h5oh/datasize"
 	"github.com/jackytck/alti-cli/cloud"
 	"github.com/jackytck/alti-cli/db"
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
 	"github.com/jackytck/alti-cli/types"
 	"github.com/jackytck/alti-cli/web"
 	"github.com/spf13/cobra"
@@ -24,8 +24,29 @@ import (
 
 var method string
 var bucket string
+var autoBucket bool
 var report string
 var assumeYes bool
+var resume bool
+var dryRun bool
+var watchDir bool
+var fromCSV string
+var urlList string
+var minQuality float64
+var dedupe = "skip"
+var withExif bool
+var fixOrientation bool
+var convertRaw string
+var rawQuality = file.DefaultRawQuality
+var maxDimension int
+var jpegQuality = file.DefaultJPEGQuality
+var waitStrategy = cloud.WaitFixed
+var pollInterval = 1
+var verifyUpload bool
+
+// dedupeModes are the ways of handling the local images whose filenames are
+// taken by different project images. Images of the same checksums are always skipped.
+var dedupeModes = []string{"skip", "replace", "suffix"}
 
 // importImageCmd represents the importImage command
 var importImageCmd = &cobra.Command{
@@ -37,79 +58,190 @@ var importImageCmd = &cobra.Command{
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+
+		// preview the walked paths only
+		if listOnly {
+			if err := service.Check(nil, service.CheckDirOrZip(dir)); err != nil {
+				errors.Exit(err)
+			}
+			listPaths(file.WalkArchivesBy(ctx, dir, pathFilter()))
+			return
+		}
 
 		// pre-checks general
 		meth, mOK := service.SuggestUploadMethod(method, "image")
-		if err := service.Check(
-			nil,
-			service.CheckAPIServer(),
-			service.CheckUploadMethod("image", meth, ip, port, mOK),
-			service.CheckPID("image", id),
-			service.CheckDir(dir),
-		); err != nil {
-			log.Println(err)
+		src := service.CheckDirOrZip(dir)
+		if watchDir {
+			src = service.CheckDir(dir)
+		}
+		if fromCSV != "" {
+			src = service.CheckFile(fromCSV)
+		}
+		if urlList != "" {
+			src = service.CheckFile(urlList)
+		}
+		checks := []service.CheckItem{
+			{Name: "server", Fn: apiServerCheck(dryRun)},
+			{Name: "method", Fn: service.CheckUploadMethod("image", meth, ip, port, mOK)},
+			{Name: "pid", Fn: service.CheckPID("image", id)},
+			{Name: "source", Fn: src},
+		}
+		if checkOnly {
+			if fromCSV == "" && urlList == "" {
+				var s imageScan
+				checks = append(checks,
+					service.CheckItem{Name: "filenames", Fn: s.checkFilenames()},
+					service.CheckItem{Name: "balance", Fn: s.checkBalance()},
+				)
+			}
+			runCheckOnly(checks...)
 			return
 		}
+		if err := service.Check(nil, service.CheckFns(checks)...); err != nil {
+			errors.Exit(err)
+		}
+
+		checkChecksumAlgo()
+		checkWaitStrategy()
+		checkSchedule()
+		checkAdaptive(meth)
+		if fixOrientation && meth == service.DirectUploadMethod {
+			logging.Errorln("--fix-orientation is not supported by direct upload, as the upright copies are not under the served directory")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		checkConvertRaw(meth)
+		checkResize(meth)
+		if _, ok := text.Contains(dedupeModes, dedupe); !ok {
+			logging.Errorf("Unknown dedupe: %q, valid modes are: %q\n", dedupe, strings.Join(dedupeModes, ", "))
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		split := checkSplit()
 
 		// get pid
 		p, _ := gql.SearchProjectID(id, true)
 
 		// setup direct upload server
-		var serDone func()
 		var baseURL string
-		if meth == service.DirectUploadMethod {
-			bu, done, err := web.StartLocalServer(dir, ip, port, false)
+		if meth == service.DirectUploadMethod && !dryRun && fromCSV == "" && urlList == "" {
+			bu, done, err := web.StartLocalServer(imageRoot(dir), ip, port, false)
 			errors.Must(err)
 			defer done()
-			serDone = done
 			baseURL = bu
 		}
 
 		// set bucket
-		b, err := service.SuggestBucket(meth, bucket, "image")
-		if err != nil {
-			log.Println(err)
+		suggestImageBucket(meth)
+
+		if fromCSV != "" {
+			rows, err := readImageCSV(fromCSV)
+			if err != nil {
+				errors.Exit(err)
+			}
+			importCSV(ctx, p.ID, meth, rows)
+			return
+		}
+		if urlList != "" {
+			rows, err := readURLList(urlList)
+			if err != nil {
+				errors.Exit(err)
+			}
+			importCSV(ctx, p.ID, meth, rows)
+			return
+		}
+
+		if watchDir && !dryRun {
+			if err := watchImport(ctx, p.ID, meth, baseURL); err != nil {
+				errors.Exit(err)
+			}
+			return
+		}
+		if split {
+			importSplit(ctx, p, meth, baseURL)
+			return
+		}
+
+		// remote images by filename, for handling the taken filenames
+		byName := make(map[string][]types.ProjectImage)
+		taken := make(map[string]bool)
+		logging.Infoln("Listing project images...")
+		remote, err := listRemoteImages(p.ID)
+		if msg := errors.MustGQL(err, ""); msg != "" {
+			logging.Errorln(msg)
 			return
 		}
-		bucket = b
-		if bucket != "" {
-			log.Printf("Bucket %q is chosen", bucket)
+		for _, r := range remote {
+			byName[r.Name] = append(byName[r.Name], r)
+			taken[r.Name] = true
 		}
+		existedIIDs := make(map[string]bool)
+		var replaced []types.ProjectImage
+		var nameSkippedCnt, suffixedCnt int
 
 		// stats
-		log.Printf("Checking %s...\n", dir)
+		logging.Infof("Checking %s...\n", dir)
 		var totalGP float64
 		var totalImg int
 		var totalByte datasize.ByteSize
 		var existedCnt int
 
 		// setup image digester
-		done := make(chan struct{})
-		defer close(done)
-
-		paths, errc := file.WalkFiles(done, dir, skip)
+		paths, errc := file.WalkArchivesBy(ctx, dir, pathFilter())
+		defer file.CloseArchives()
 		result := make(chan file.ImageDigest)
 
+		cache := openDigestCache()
+		if cache != nil {
+			defer cache.Close()
+		}
+
 		digester := file.ImageDigester{
-			Root:   dir,
-			PID:    p.ID,
-			Done:   done,
-			Paths:  paths,
-			Result: result,
+			Root:         imageRoot(dir),
+			PID:          p.ID,
+			Quality:      minQualityFilter(),
+			WithExif:     withExif,
+			Checksum:     checksumAlgo,
+			Cache:        cache,
+			UprightDir:   uprightDir(),
+			RawDir:       rawDir(),
+			RawQuality:   rawQuality,
+			ResizeDir:    resizeDir(),
+			MaxDimension: maxDimension,
+			JPEGQuality:  jpegQuality,
+			Dcraw:        dcrawPath,
+			Ctx:          ctx,
+			Paths:        paths,
+			Result:       result,
 		}
 		threads := digester.Run(thread)
-		if verbose {
-			log.Printf("Working in %d thread(s)...", threads)
-		}
+		logging.Debugf("Working in %d thread(s)...", threads)
 
-		// setup local temp db
-		dbPath, err := db.OpenPath()
+		// setup local db for storing the upload state
+		dbPath, err := db.ResumePath(p.ID, dir)
 		if err != nil {
 			panic(err)
 		}
+		if dryRun && !resume {
+			// leave the state of previous run untouched
+			dbPath, err = db.OpenPath()
+			if err != nil {
+				panic(err)
+			}
+		}
+		if resume {
+			if file.IsFileExist(dbPath) {
+				logging.Infof("Resuming from %q\n", dbPath)
+			}
+		} else {
+			err = os.RemoveAll(dbPath)
+			if err != nil {
+				panic(err)
+			}
+		}
 		localDB, err := db.OpenDB(dbPath)
 		if err != nil {
 			panic(err)
@@ -118,56 +250,100 @@ var importImageCmd = &cobra.Command{
 		if err != nil {
 			panic(err)
 		}
-		cleanupDB := func() {
-			err2 := localDB.Close()
-			if err2 != nil {
-				panic(err2)
-			}
-			err2 = os.Remove(dbPath)
-			if err2 != nil {
+		// queues between the stages, so that each stage resumes where it left off
+		uploadQ, err := db.OpenQueue(localDB, db.QueueUpload)
+		if err != nil {
+			panic(err)
+		}
+		checkQ, err := db.OpenQueue(localDB, db.QueueCheck)
+		if err != nil {
+			panic(err)
+		}
+		// the state is kept for resuming unless the import is finished
+		finished := false
+		closeDB := func() {
+			if err2 := closeImportDB(localDB, dbPath, dryRun, resume, finished); err2 != nil {
 				panic(err2)
 			}
 		}
-		defer cleanupDB()
-
-		// capture ctrl+c
-		cc := make(chan os.Signal)
-		signal.Notify(cc, os.Interrupt, syscall.SIGTERM)
-		go func() {
-			<-cc
-			cleanupDB()
-			if serDone != nil {
-				serDone()
-			}
-			fmt.Println()
-			log.Println("Bye!")
-			os.Exit(1)
-		}()
+		defer closeDB()
 
+		var resumedCnt, lowQualityCnt int
+		maxSize := maxFileSize("image")
 		for r := range result {
 			if r.Error != nil {
-				log.Printf("Invalid image: %q, Reason: %v", r.Path, r.Error)
+				logging.Warnf("Invalid image: %q, Reason: %v", r.Path, r.Error)
+				continue
+			}
+			if maxSize > 0 && r.Filesize > maxSize {
+				logging.Warnf("Invalid image: %q, Reason: %s exceeds the max image size of the api server: %s", r.Path, datasize.ByteSize(r.Filesize).HumanReadable(), datasize.ByteSize(maxSize).HumanReadable())
+				continue
+			}
+			if len(r.Issues) > 0 {
+				logging.Warnf("Low quality image: %q, Issues: %s", r.Path, strings.Join(r.Issues, ", "))
+				lowQualityCnt++
 				continue
 			}
 
 			mb := file.BytesToMB(r.Filesize)
 			if verbose {
-				log.Printf("Path: %q, URL: %q, Filename: %q, Dimension: %d x %d, GP: %.2f, Type: %s, Size: %.2f MB, Checksum: %s, Existed: %v\n",
-					r.Path, r.URL, r.Filename, r.Width, r.Height, r.GP, r.Filetype, mb, r.SHA1, r.Existed)
+				logging.Infof("Path: %q, URL: %q, Filename: %q, Dimension: %d x %d, GP: %.2f, Type: %s, Size: %.2f MB, Checksum: %s, Existed: %v\n",
+					r.Path, r.URL, r.Filename, r.Width, r.Height, r.GP, r.Filetype, mb, r.Checksum, r.Existed)
+			}
+
+			// uploading state of previous run
+			var prev db.Image
+			err = localDB.One("Hash", r.SHA1, &prev)
+			if err != nil && err != storm.ErrNotFound {
+				panic(err)
+			}
+			hasPrev := err == nil
+			if hasPrev && prev.Stage == db.StageVerified {
+				resumedCnt++
+				continue
 			}
 
 			if r.Existed {
+				existedIIDs[r.IID] = true
+			}
+			if r.Existed && !(hasPrev && prev.IsUploaded()) {
 				existedCnt++
 				continue
 			}
 
+			// filename taken by a different remote image
+			filename := r.Filename
+			if rs, ok := byName[r.Filename]; ok && !r.Existed {
+				switch dedupe {
+				case "skip":
+					if verbose {
+						logging.Infof("Skipped %q, filename is taken\n", r.Path)
+					}
+					nameSkippedCnt++
+					continue
+				case "replace":
+					replaced = append(replaced, rs...)
+					delete(byName, r.Filename)
+				case "suffix":
+					if hasPrev {
+						filename = prev.Filename
+					} else {
+						filename = file.SuffixFilename(r.Filename, taken)
+					}
+					suffixedCnt++
+					if verbose {
+						logging.Infof("Renamed %q to %q\n", r.Path, filename)
+					}
+				}
+			}
+
 			totalGP += r.GP
 			totalImg++
 			totalByte += datasize.ByteSize(r.Filesize)
 
 			img := db.Image{
 				PID:       p.ID,
-				Filename:  r.Filename,
+				Filename:  filename,
 				Filetype:  types.ConvertToImageType(r.Filetype),
 				URL:       r.URL,
 				LocalPath: r.Path,
@@ -175,23 +351,57 @@ var importImageCmd = &cobra.Command{
 				Width:     r.Width,
 				Height:    r.Height,
 				GP:        r.GP,
+				Meta:      r.Exif.ImageMeta(),
+			}
+			if hasPrev {
+				img.SID = prev.SID
+				img.IID = prev.IID
+				img.State = prev.State
+				img.Stage = prev.Stage
+			}
+			if dryRun {
+				if verbose {
+					logging.Infof("Would upload %q\n", img.LocalPath)
+				}
+				continue
 			}
 			err = localDB.Save(&img)
 			if err != nil {
 				panic(err)
 			}
+			if img.IsUploaded() {
+				err = checkQ.Push(img.Hash)
+			} else {
+				err = uploadQ.Push(img.Hash)
+			}
+			if err != nil {
+				panic(err)
+			}
 		}
 
 		// check whether the Walk failed
 		if err = <-errc; err != nil {
+			if ctx.Err() != nil {
+				return
+			}
 			panic(err)
 		}
 
+		if resumedCnt > 0 {
+			logging.Infof("%d images were uploaded and verified in previous run", resumedCnt)
+		}
+		if lowQualityCnt > 0 {
+			logging.Infof("%d low quality images are excluded", lowQualityCnt)
+		}
+		if nameSkippedCnt > 0 {
+			logging.Infof("%d images are skipped as their filenames are taken, add '--dedupe replace' or '--dedupe suffix' to upload them", nameSkippedCnt)
+		}
 		if totalImg == 0 {
-			if existedCnt > 0 {
-				log.Println("No new image is found! All of the images in this directory have been imported.")
+			finished = true
+			if existedCnt > 0 || resumedCnt > 0 {
+				logging.Infoln("No new image is found! All of the images in this directory have been imported.")
 			} else {
-				log.Println("No image is found!")
+				logging.Infoln("No image is found!")
 			}
 			return
 		}
@@ -199,9 +409,23 @@ var importImageCmd = &cobra.Command{
 		// ask user to proceed or not
 		var ans string
 		if existedCnt > 0 {
-			log.Printf("%d images already existed in the project", existedCnt)
+			logging.Infof("%d images already existed in the project", existedCnt)
+		}
+		// keep the remote images that are still referenced by other local images
+		var toRemove []types.ProjectImage
+		for _, r := range replaced {
+			if !existedIIDs[r.ID] {
+				toRemove = append(toRemove, r)
+			}
+		}
+		if len(toRemove) > 0 {
+			logging.Infof("%d remote images of the same filenames will be replaced", len(toRemove))
 		}
-		log.Printf("Found %d images, total %.2f GP, %s", totalImg, totalGP, totalByte.HumanReadable())
+		if suffixedCnt > 0 {
+			logging.Infof("%d images of taken filenames will be uploaded with suffixed filenames", suffixedCnt)
+		}
+		logging.Infof("Found %d images, total %.2f GP, %s", totalImg, totalGP, totalByte.HumanReadable())
+		service.Check(nil, service.CheckGPQuota(totalGP))
 		plural := ""
 		if totalImg > 1 {
 			plural = "s"
@@ -211,29 +435,42 @@ var importImageCmd = &cobra.Command{
 			panic(err)
 		}
 		fmt.Printf("After importing (if no duplicate):\nImages #: %d -> %d\tGP: %.2f -> %.2f\tPRO: USD $%.2f\n", p.NumImage, p.NumImage+totalImg, p.GigaPixel, p.GigaPixel+totalGP, usd)
+		if dryRun {
+			logging.Infof("Dry run: %d image%s would be uploaded by %q. Nothing is registered or uploaded.\n", totalImg, plural, meth)
+			return
+		}
 		fmt.Printf("Continue to import %d image%s or not? (Y/N): ", totalImg, plural)
 		if assumeYes {
 			fmt.Println("Yes")
 		} else {
 			fmt.Scanln(&ans)
 			ans = strings.ToUpper(ans)
+			if ctx.Err() != nil {
+				return
+			}
 			if ans != "Y" && ans != service.Yes {
-				log.Println("Cancelled.")
+				finished = true
+				logging.Infoln("Cancelled.")
 				return
 			}
 		}
 
-		// read from local db, register and upload
-		imgc, errc := db.AllImage(localDB)
+		// read from the upload queue, register and upload
+		imgc, errc := uploadQ.Images()
 		ruRes := make(chan db.Image)
+		pr := service.NewProgressReporter(totalImg, int64(totalByte))
 		ruDigester := cloud.ImageRegUploader{
-			Method:  meth,
-			Bucket:  bucket,
-			BaseURL: baseURL,
-			Images:  imgc,
-			Done:    done,
-			Result:  ruRes,
-			Verbose: verbose,
+			Method:   meth,
+			Bucket:   bucket,
+			Buckets:  imageBuckets,
+			BaseURL:  baseURL,
+			Images:   imgc,
+			Ctx:      ctx,
+			Result:   ruRes,
+			Verbose:  verbose,
+			Progress: pr,
+			Throttle: uploadThrottle(meth),
+			Schedule: uploadSchedule,
 		}
 		if meth == "oss" {
 			err2 := ruDigester.WithOSSUploader(p.ID)
@@ -243,18 +480,24 @@ var importImageCmd = &cobra.Command{
 		}
 		ruDigester.Run(thread)
 
-		regFailCnt := 0
+		var regCnt imageCount
 		for img := range ruRes {
 			err = localDB.Save(&img)
+			if err == nil {
+				err = uploadQ.Done(img.Hash)
+			}
+			if err == nil {
+				err = checkQ.Push(img.Hash)
+			}
+			ok := regCnt.add(img)
 			if verbose {
-				if img.Error != "" {
-					log.Printf("Registration failed: %q\n", img.Error)
-					regFailCnt++
+				if !ok {
+					logging.Warnf("Registration failed: %q\n", img.Error)
 				} else {
 					if meth == service.DirectUploadMethod {
-						log.Printf("Registered %q\n", img.Filename)
+						logging.Infof("Registered %q\n", img.Filename)
 					} else {
-						log.Printf("Registered and uploaded %q\n", img.Filename)
+						logging.Infof("Registered and uploaded %q\n", img.Filename)
 					}
 				}
 			}
@@ -262,38 +505,57 @@ var importImageCmd = &cobra.Command{
 				panic(err)
 			}
 		}
+		pr.Close()
 
 		// check whether the read from local db failed
 		if err = <-errc; err != nil {
 			panic(err)
 		}
-		if regFailCnt == totalImg {
-			log.Println("You run out of luck! All images failed to register!")
+		if ctx.Err() != nil {
+			return
+		}
+		saveThrottle(meth, ruDigester.Throttle)
+		if regCnt.failed == totalImg {
+			logging.Errorln("You run out of luck! All images failed to register!")
 			return
 		}
 
 		// check for image state: Ready / Invalid / Client timeout
-		log.Println("Checking image states....")
-		imgc, errc = db.AllImage(localDB)
+		if waitStrategy == cloud.WaitNone {
+			logging.Infoln("Skipped checking image states, verify them later by 'alti-cli verify'")
+		} else {
+			logging.Infoln("Checking image states....")
+		}
+		imgc, errc = checkQ.Images()
 		checkerRes := make(chan db.Image)
 		checker := cloud.ImageStateChecker{
-			Images:  imgc,
-			Done:    done,
-			Result:  checkerRes,
-			Timeout: time.Minute * time.Duration(timeout),
+			Images:   imgc,
+			Ctx:      ctx,
+			Result:   checkerRes,
+			Timeout:  stateTimeout(),
+			Strategy: waitStrategy,
+			Interval: time.Second * time.Duration(pollInterval),
+			Verify:   verifyUpload,
 		}
 		checker.Run(thread)
 
-		var okCnt, errCnt int
+		var cnt imageCount
 		for img := range checkerRes {
 			err = localDB.Save(&img)
+			if err == nil {
+				if img.Error != "" {
+					err = checkQ.Fail(img.Hash, img.Error)
+				} else {
+					err = checkQ.Done(img.Hash)
+				}
+			}
+			warnChecksumMismatch(img)
+			ok := cnt.add(img)
 			if verbose {
-				if img.Error != "" || img.State == "Invalid" {
-					errCnt++
-					log.Printf("Image upload error: %q\n", img.Error)
+				if !ok {
+					logging.Warnf("Image upload error: %q\n", img.Error)
 				} else {
-					okCnt++
-					log.Printf("Image %q is %q\n", img.Filename, img.State)
+					logging.Infof("Image %q is %q\n", img.Filename, img.State)
 				}
 			}
 			if err != nil {
@@ -305,16 +567,35 @@ var importImageCmd = &cobra.Command{
 		if err = <-errc; err != nil {
 			panic(err)
 		}
+		if ctx.Err() != nil {
+			return
+		}
 
-		log.Printf("%d out of %d images are uploaded and ready.", okCnt, totalImg)
-		if errCnt > 0 {
-			log.Printf("%d images failed. Please try again later.", errCnt)
+		// record all images of the session, including the resumed ones
+		mf := db.NewManifest(p.ID, meth, bucket)
+		mf.Start = start
+		imgc, errc = db.AllImage(localDB)
+		for img := range imgc {
+			mf.AddImage(img)
 		}
-		log.Printf("To inspect more, type: 'alti-cli myproj inspect -p %v'\n", id)
+		if err = <-errc; err != nil {
+			panic(err)
+		}
+		saveManifest(mf, manifestFile(p.ID))
+
+		// remove after upload, so that the project is never left with less images
+		removeImages(p.ID, toRemove)
+
+		logging.Infof("%d out of %d images are uploaded and ready.", cnt.ok, totalImg)
+		if cnt.failed > 0 {
+			logging.Warnf("%d images failed. Please try again later.", cnt.failed)
+		}
+		finished = cnt.finished()
+		logging.Infof("To inspect more, type: 'alti-cli myproj inspect -p %v'\n", id)
 
 		// generate report of uploading
 		if report != "" {
-			log.Println("Generating csv upload report...")
+			logging.Infoln("Generating csv upload report...")
 			out, err := os.Create(report)
 			errors.Must(err)
 
@@ -340,20 +621,94 @@ var importImageCmd = &cobra.Command{
 	},
 }
 
+// closeImportDB closes the local db of an import at path. It is removed
+// unless it is kept for resuming, i.e. the import is not finished, or it is
+// the db of a resumed import checked by a dry run.
+func closeImportDB(localDB *storm.DB, path string, dryRun, resume, finished bool) error {
+	if err := localDB.Close(); err != nil {
+		return err
+	}
+	if dryRun {
+		// only the temporary db is removed
+		if resume {
+			return nil
+		}
+	} else if !finished {
+		logging.Infoln("Resume the import by adding '--resume'")
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// imageCount counts the images of an import that are ok or failed.
+type imageCount struct {
+	ok     int
+	failed int
+}
+
+// add counts img, telling if it is ok, i.e. without error and not Invalid.
+func (c *imageCount) add(img db.Image) bool {
+	if img.Error != "" || img.State == "Invalid" {
+		c.failed++
+		return false
+	}
+	c.ok++
+	return true
+}
+
+// finished tells if no image failed, so that the state for resuming the
+// import is no longer needed.
+func (c imageCount) finished() bool {
+	return c.failed == 0
+}
+
 func init() {
 	importCmd.AddCommand(importImageCmd)
 	importImageCmd.Flags().StringVarP(&id, "id", "p", id, "Project id")
-	importImageCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory path")
+	importImageCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory or zip path, zips in the directory are also read")
 	importImageCmd.Flags().StringVarP(&skip, "skip", "s", skip, "Regular expression to skip paths")
+	importImageCmd.Flags().StringArrayVar(&includes, "include", includes, "Glob of paths to include, e.g. '*.jpg' or 'raw/**', or a regular expression prefixed by 're:'; repeatable")
+	importImageCmd.Flags().StringArrayVar(&excludes, "exclude", excludes, "Glob of paths to exclude, taking precedence over --include; repeatable")
+	importImageCmd.Flags().StringVar(&excludeFrom, "exclude-from", excludeFrom, "File of the paths relative to '--dir' to exclude, one per line, e.g. written by 'check image --dupes skip'")
+	importImageCmd.Flags().BoolVar(&withHidden, "hidden", withHidden, "Include the dotfiles and OS junk files, e.g. Thumbs.db and __MACOSX")
+	importImageCmd.Flags().BoolVar(&listOnly, "list-only", listOnly, "List the paths that would be processed, without processing them")
 	importImageCmd.Flags().StringVarP(&report, "report", "r", report, "Path of csv upload report output")
-	importImageCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct', 's3' or 'oss'")
+	importImageCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct', 's3', 'gcs' or 'oss'")
 	importImageCmd.Flags().IntVarP(&timeout, "timeout", "t", timeout, "Timeout of checking upload state in seconds")
+	importImageCmd.Flags().StringVar(&waitStrategy, "wait-strategy", waitStrategy, "Strategy of waiting for the upload states: 'fixed', 'backoff' or 'none' (not waiting)")
+	importImageCmd.Flags().IntVar(&pollInterval, "poll-interval", pollInterval, "Interval of polling the upload states in seconds, the initial one of 'backoff'")
+	importImageCmd.Flags().BoolVar(&verifyUpload, "verify", verifyUpload, "Compare the checksum computed by the server of each uploaded image with the local one")
 	importImageCmd.Flags().StringVar(&ip, "ip", ip, "IP address of ad-hoc local server for direct upload.")
 	importImageCmd.Flags().StringVar(&port, "port", port, "Port of ad-hoc local server for direct upload.")
-	importImageCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload for method: 's3' or 'oss'")
+	importImageCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload for method: 's3', 'gcs' or 'oss'")
+	importImageCmd.Flags().BoolVar(&autoBucket, "auto-bucket", autoBucket, "Choose the bucket of the lowest measured upload latency if bucket is not given, cached for 24 hours")
+	importImageCmd.Flags().StringVar(&spreadBuckets, "buckets", spreadBuckets, "Spread the uploads across the comma separated buckets, each optionally weighted, e.g. 'a,b:2'")
+	importImageCmd.Flags().StringVar(&bucketStrategy, "bucket-strategy", bucketStrategy, "Spread the uploads across '--buckets', or all of the buckets if not given: 'round-robin', 'random' or 'fastest' (weighted by latency)")
+	importImageCmd.Flags().StringVar(&uploadWindow, "schedule", uploadWindow, "Only upload within the daily window of local time, e.g. '22:00-06:00', pausing and resuming automatically")
+	importImageCmd.Flags().BoolVar(&resume, "resume", resume, "Resume the previous interrupted import and skip the completed images")
+	importImageCmd.Flags().StringVar(&fromCSV, "from-csv", fromCSV, "Csv of images to import instead of a directory, rows of: path or url, filename, checksum")
+	importImageCmd.Flags().StringVar(&urlList, "url-list", urlList, "Text file of http(s) urls of images to register directly without downloading, one per line")
+	importImageCmd.Flags().BoolVar(&watchDir, "watch", watchDir, "Keep running and import the new images as they appear in the directory")
+	importImageCmd.Flags().StringVar(&dedupe, "dedupe", dedupe, "Handle the images whose filenames are taken by different project images: 'skip', 'replace' (remove the project ones) or 'suffix' (rename the local ones)")
+	importImageCmd.Flags().Float64Var(&maxGP, "max-gp", maxGP, "Split the images into the project and new projects named after it, each of at most this giga-pixel")
+	importImageCmd.Flags().IntVar(&maxImages, "max-images", maxImages, "Split the images into the project and new projects named after it, each of at most this number of images")
+	importImageCmd.Flags().StringVar(&splitMap, "split-map", splitMap, "Path of the csv mapping the images to the projects they are split into, default is next to the manifests")
+	importImageCmd.Flags().StringVar(&visibility, "visibility", visibility, "Visibility of the projects created by '--max-gp' or '--max-images': public, unlisted, private")
+	importImageCmd.Flags().Float64Var(&minQuality, "min-quality", minQuality, "Exclude the blurred, over/under-exposed or small images, with this min sharpness (variance of Laplacian), e.g. 100")
+	importImageCmd.Flags().BoolVar(&withExif, "with-exif", withExif, "Send the GPS, orientation and capture time from the EXIF of each image along its registration")
+	importImageCmd.Flags().BoolVar(&fixOrientation, "fix-orientation", fixOrientation, "Upload the upright copies of the JPEGs rotated by EXIF orientation, written under the config directory")
+	importImageCmd.Flags().StringVar(&convertRaw, "convert-raw", convertRaw, "Upload the copies of the RAW images (DNG, CR2, NEF, ARW) converted by dcraw to this format: 'jpeg', written under the config directory")
+	importImageCmd.Flags().IntVar(&rawQuality, "quality", rawQuality, "JPEG quality of the RAW images converted by '--convert-raw', from 1 to 100")
+	importImageCmd.Flags().IntVar(&maxDimension, "max-dimension", maxDimension, "Upload the JPEG copies of the images whose longer side exceeds this many pixels, downsized and written under the config directory, 0 to disable")
+	importImageCmd.Flags().IntVar(&jpegQuality, "jpeg-quality", jpegQuality, "JPEG quality of the images downsized by '--max-dimension', from 1 to 100")
+	importImageCmd.Flags().StringVar(&checksumAlgo, "checksum", checksumAlgo, "Checksum algorithm of the local digests: 'sha1', 'sha256' or 'xxh64' (fastest), the server always gets the sha1")
+	importImageCmd.Flags().BoolVar(&noCache, "no-cache", noCache, "Digest all images again instead of using the cache of unchanged ones")
+	importImageCmd.Flags().BoolVar(&dryRun, "dry-run", dryRun, "Check and print what would be uploaded without registering or uploading")
+	importImageCmd.Flags().BoolVar(&checkOnly, "check-only", checkOnly, "Only run the pre-checks, including the filenames and the balance against the estimated coins, and report the result of each")
+	importImageCmd.Flags().StringVar(&checkFormat, "format", checkFormat, "Format of the '--check-only' report: 'text' or 'json'")
 	importImageCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
 	importImageCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display individual image info")
 	importImageCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads to process, default is number of cores x 4")
+	importImageCmd.Flags().BoolVar(&adaptive, "adaptive", adaptive, "Adapt the number of concurrent uploads to the measured throughput, starting from the one learned in the last run")
 	errors.Must(importImageCmd.MarkFlagRequired("id"))
-	errors.Must(importImageCmd.MarkFlagRequired("dir"))
 }
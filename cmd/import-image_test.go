@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackytck/alti-cli/db"
+)
+
+func TestImportDBKeptOnFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		imgs     []db.Image
+		dryRun   bool
+		resume   bool
+		wantKept bool
+	}{
+		{"all ready", []db.Image{{State: "Ready"}, {State: "Ready"}}, false, false, false},
+		{"upload error", []db.Image{{State: "Ready"}, {Error: "timeout"}}, false, false, true},
+		{"invalid", []db.Image{{State: "Invalid"}}, false, false, true},
+		{"dry run", nil, true, false, false},
+		{"dry run of resumed", nil, true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "import")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			p := filepath.Join(dir, "import.db")
+			localDB, err := db.OpenDB(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var cnt imageCount
+			for _, img := range tt.imgs {
+				cnt.add(img)
+			}
+			if err = closeImportDB(localDB, p, tt.dryRun, tt.resume, cnt.finished()); err != nil {
+				t.Fatal(err)
+			}
+			_, err = os.Stat(p)
+			if kept := err == nil; kept != tt.wantKept {
+				t.Errorf("db is kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
@@ -2,16 +2,20 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
+	"strings"
+	"sync"
 	"time"
 
+	humanize "github.com/dustin/go-humanize"
 	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/db"
 	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/lifecycle"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
 	"github.com/jackytck/alti-cli/web"
 	"github.com/spf13/cobra"
@@ -23,102 +27,201 @@ var meta string
 var importMetaCmd = &cobra.Command{
 	Use:   "meta",
 	Short: "Import meta file to a project",
-	Long:  "Import meta files to a project. Recognized filenames are: camera.txt, pose.txt and group.txt.",
+	Long:  "Import a meta file or all of the meta files of a directory to a project. Recognized filenames are: camera.txt, pose.txt and group.txt.",
 	Run: func(cmd *cobra.Command, args []string) {
 		start := time.Now()
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
 
 		// pre-checks general
 		meth, mOK := service.SuggestUploadMethod(method, "meta")
-		if err := service.Check(
-			nil,
-			service.CheckAPIServer(),
-			service.CheckUploadMethod("meta", meth, ip, port, mOK),
-			service.CheckPID("meta", id),
-			service.CheckFile(meta),
-			service.CheckFilenames(meta, service.ValidMetafileNames),
-		); err != nil {
-			log.Println(err)
+		src := []service.CheckItem{
+			{Name: "file", Fn: service.CheckFile(meta)},
+			{Name: "filename", Fn: service.CheckFilenames(meta, service.ValidMetafileNames)},
+		}
+		if stat, err := os.Stat(meta); err == nil && stat.IsDir() {
+			src = []service.CheckItem{{Name: "dir", Fn: service.CheckDir(meta)}}
+		}
+		checks := append([]service.CheckItem{
+			{Name: "server", Fn: apiServerCheck(dryRun)},
+			{Name: "method", Fn: service.CheckUploadMethod("meta", meth, ip, port, mOK)},
+			{Name: "pid", Fn: service.CheckPID("meta", id)},
+		}, src...)
+		if checkOnly {
+			runCheckOnly(checks...)
 			return
 		}
+		if err := service.Check(nil, service.CheckFns(checks)...); err != nil {
+			errors.Exit(err)
+		}
+		checkSchedule()
+
+		// all of the recognized meta files of a directory
+		paths := []string{meta}
+		if len(src) == 1 {
+			ps, err := service.GetMetafilePaths(meta)
+			if err != nil {
+				errors.Exit(err)
+			}
+			if len(ps) == 0 {
+				logging.Errorf("No meta file is found in %q, recognized filenames are: %s\n", meta, strings.Join(service.ValidMetafileNames, ", "))
+				errors.Exit(errors.ErrMetaFilenameInvalid)
+			}
+			paths = ps
+		}
 
 		// get project
 		proj, _ := gql.SearchProjectID(id, true)
 
+		// encrypted copies are uploaded instead for client-side encryption
+		key := readEncryptKey()
+		uploads := paths
+		if key != nil && !dryRun {
+			dir := encryptDir(meta, key)
+			ps, err := encryptFiles(paths, dir, key, false)
+			if err != nil {
+				logging.Errorln("Failed to encrypt:", err)
+				errors.Exit(err)
+			}
+			defer lifecycle.Register("encrypted copies", func() { os.RemoveAll(dir) }).Release()
+			uploads = ps
+			logging.Infof("Encrypted by %s key %s\n", file.EncryptAlgorithm, file.KeyID(key))
+		}
+
 		// local server for direct upload
-		var serDone func()
-		var baseURL, directURL string
-		filename := filepath.Base(meta)
-		if meth == service.DirectUploadMethod {
-			bu, done, err := web.StartLocalServer(filepath.Dir(meta), ip, port, false)
+		var baseURL string
+		if meth == service.DirectUploadMethod && !dryRun {
+			bu, done, err := web.StartLocalServer(filepath.Dir(uploads[0]), ip, port, false)
 			errors.Must(err)
 			defer done()
-			serDone = done
 			baseURL = bu
-			directURL = fmt.Sprintf("%s/%s", baseURL, filename)
 		}
 
 		// set bucket
-		b, err := service.SuggestBucket(meth, bucket, "meta")
+		b, err := service.SuggestBucket(meth, bucket, "meta", autoBucket)
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 		bucket = b
 		if bucket != "" {
-			log.Printf("Bucket %q is chosen", bucket)
-		}
-
-		// register + upload + state check
-		mru := cloud.MetaFileRegUploader{
-			Method:    meth,
-			PID:       proj.ID,
-			MetaPath:  meta,
-			Filename:  filename,
-			DirectURL: directURL,
-			Bucket:    bucket,
-			Timeout:   timeout,
-			Verbose:   verbose,
-		}
-
-		// capture and handle ctrl+c
-		cc := make(chan os.Signal)
-		signal.Notify(cc, os.Interrupt, syscall.SIGTERM)
-		go func() {
-			<-cc
-			fmt.Println()
-			if serDone != nil {
-				serDone()
+			logging.Infof("Bucket %q is chosen", bucket)
+		}
+
+		var totalSize int64
+		for _, p := range uploads {
+			size, err2 := file.Filesize(p)
+			errors.Must(err2)
+			if err2 = service.Check(nil, service.CheckMaxFileSize("meta", p, size)); err2 != nil {
+				errors.Exit(err2)
 			}
-			errors.Must(mru.Done())
-			log.Println("Bye!")
-			os.Exit(1)
-		}()
+			totalSize += size
+			if dryRun {
+				fmt.Printf("Meta file: %q\tSize: %s\tMethod: %q\n", p, humanize.IBytes(uint64(size)), meth)
+			}
+		}
+		if dryRun {
+			if key != nil {
+				logging.Infof("Would be encrypted by %s key %s\n", file.EncryptAlgorithm, file.KeyID(key))
+			}
+			logging.Infoln("Dry run: nothing is registered or uploaded.")
+			return
+		}
 
-		state, err := mru.Run()
-		if err != nil {
-			log.Println(err.Error())
+		// the api server pulls the file by itself for direct upload
+		var pr service.ProgressReporter
+		if meth != service.DirectUploadMethod {
+			pr = service.NewProgressReporter(len(paths), totalSize)
+		}
+
+		// register + upload + state check concurrently
+		entries := make([]db.ManifestEntry, len(paths))
+		var wg sync.WaitGroup
+		wg.Add(len(paths))
+		for i, p := range paths {
+			go func(i int, p string) {
+				defer wg.Done()
+				filename := filepath.Base(p)
+				mru := cloud.MetaFileRegUploader{
+					Method:   meth,
+					PID:      proj.ID,
+					MetaPath: uploads[i],
+					Filename: filename,
+					Bucket:   bucket,
+					PartSize: partSize * (1 << 20),
+					Parallel: thread,
+					Resume:   resume,
+					Timeout:  timeout,
+					Verbose:  verbose,
+					Progress: pr,
+					Schedule: uploadSchedule,
+				}
+				if baseURL != "" {
+					mru.DirectURL = fmt.Sprintf("%s/%s", baseURL, filename)
+				}
+				state, err := mru.Run(ctx)
+				if ctx.Err() != nil {
+					errors.Must(mru.Done())
+				}
+				e := db.ManifestEntry{Kind: "meta", Path: p, Filename: filename, Checksum: mru.Checksum(), ID: mru.MID, State: state}
+				encryptEntry(&e, key)
+				if err != nil {
+					e.Error = err.Error()
+				}
+				entries[i] = e
+			}(i, p)
+		}
+		wg.Wait()
+		if pr != nil {
+			pr.Close()
+		}
+		if ctx.Err() != nil {
 			return
 		}
 
-		log.Printf("Successfully registered and uplaoded in state: %q!\n", state)
+		mf := db.NewManifest(proj.ID, meth, bucket)
+		mf.Start = start
+		mf.Entries = entries
+		saveManifest(mf, manifestFile(proj.ID))
+
+		// report per-file state
+		var okCnt int
+		table := newTable()
+		table.SetHeader([]string{"Filename", "State", "Error"})
+		for _, e := range entries {
+			if e.Error == "" {
+				okCnt++
+			}
+			table.Append([]string{e.Filename, e.State, e.Error})
+		}
+		table.Render()
+		logging.Infof("%d out of %d meta files are registered and uploaded.\n", okCnt, len(entries))
 	},
 }
 
 func init() {
 	importCmd.AddCommand(importMetaCmd)
 	importMetaCmd.Flags().StringVarP(&id, "id", "p", id, "Project id")
-	importMetaCmd.Flags().StringVarP(&meta, "file", "f", model, "File path of meta file.")
-	importMetaCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct' or 's3' or 'minio'")
+	importMetaCmd.Flags().StringVarP(&meta, "file", "f", model, "File path of meta file, or directory of meta files.")
+	importMetaCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct', 's3', 'minio', 'gcs' or 'oss'")
 	importMetaCmd.Flags().IntVarP(&timeout, "timeout", "t", timeout, "Timeout of checking direct upload state in seconds")
 	importMetaCmd.Flags().StringVar(&ip, "ip", ip, "IP address of ad-hoc local server for direct upload.")
 	importMetaCmd.Flags().StringVar(&port, "port", port, "Port of ad-hoc local server for direct upload.")
-	importMetaCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload for method: 's3'")
+	importMetaCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload for method: 's3', 'gcs' or 'oss'")
+	importMetaCmd.Flags().BoolVar(&autoBucket, "auto-bucket", autoBucket, "Choose the bucket of the lowest measured upload latency if bucket is not given, cached for 24 hours")
+	importMetaCmd.Flags().Int64Var(&partSize, "part-size", partSize, "Upload the meta file to 's3' or 'minio' in parts of this size in MB if it is larger, default is only meta files larger than 5GB in 100MB parts")
+	importMetaCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of parts to upload concurrently, default is number of cores")
+	importMetaCmd.Flags().BoolVar(&resume, "resume", resume, "Resume the previous interrupted multipart upload and skip the uploaded parts")
+	importMetaCmd.Flags().StringVar(&uploadWindow, "schedule", uploadWindow, "Only upload within the daily window of local time, e.g. '22:00-06:00', pausing and resuming automatically")
+	importMetaCmd.Flags().StringVar(&encryptKeyFile, "encrypt-key-file", encryptKeyFile, "File of a 256-bit key to encrypt the meta files by AES-GCM before upload")
+	importMetaCmd.Flags().BoolVar(&dryRun, "dry-run", dryRun, "Check and print what would be uploaded without registering or uploading")
+	importMetaCmd.Flags().BoolVar(&checkOnly, "check-only", checkOnly, "Only run the pre-checks and report the result of each")
+	importMetaCmd.Flags().StringVar(&checkFormat, "format", checkFormat, "Format of the '--check-only' report: 'text' or 'json'")
 	importMetaCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info of operation")
 	errors.Must(importMetaCmd.MarkFlagRequired("id"))
 	errors.Must(importMetaCmd.MarkFlagRequired("file"))
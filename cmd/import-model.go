@@ -1,19 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io/ioutil"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"regexp"
-	"syscall"
 	"time"
 
+	humanize "github.com/dustin/go-humanize"
 	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/db"
 	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/lifecycle"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
 	"github.com/jackytck/alti-cli/web"
 	"github.com/spf13/cobra"
 )
@@ -22,6 +26,7 @@ var model string
 var ip string
 var port string
 var timeout int
+var partSize int64
 
 // importModelCmd represents the importModel command
 var importModelCmd = &cobra.Command{
@@ -33,27 +38,31 @@ var importModelCmd = &cobra.Command{
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
 		// pre-checks general
 		meth, mOK := service.SuggestUploadMethod(method, "model")
-		if err := service.Check(
-			nil,
-			service.CheckAPIServer(),
-			service.CheckUploadMethod("model", meth, ip, port, mOK),
-			service.CheckPID("model", id),
-			service.CheckFilename(model, regexp.MustCompile(`^[a-zA-Z0-9\._]*$`)),
-			service.CheckFile(model),
-		); err != nil {
-			log.Println(err)
+		checks := []service.CheckItem{
+			{Name: "server", Fn: apiServerCheck(dryRun)},
+			{Name: "method", Fn: service.CheckUploadMethod("model", meth, ip, port, mOK)},
+			{Name: "pid", Fn: service.CheckPID("model", id)},
+			{Name: "filename", Fn: service.CheckFilename(model, service.ModelFilenameRegex)},
+			{Name: "file", Fn: service.CheckFile(model)},
+		}
+		if checkOnly {
+			runCheckOnly(checks...)
 			return
 		}
+		if err := service.Check(nil, service.CheckFns(checks)...); err != nil {
+			errors.Exit(err)
+		}
+		checkSchedule()
 
 		// determine if single or multipart upload
 		var partsDir string
-		if err := service.CheckDir(model)(log.Printf); err == nil {
+		if err := service.CheckDir(model)(logging.Debugf); err == nil {
 			partsDir = model
 			model = ""
 		}
@@ -64,36 +73,107 @@ var importModelCmd = &cobra.Command{
 				nil,
 				service.CheckZip(model),
 			); err != nil {
-				log.Println(err)
-				return
+				errors.Exit(err)
 			}
 		}
 
 		// get project
 		proj, _ := gql.SearchProjectID(id, true)
 
+		// encrypted copies are uploaded instead for client-side encryption,
+		// kept until success for '--resume'
+		origModel, origParts := model, partsDir
+		key := readEncryptKey()
+		var encDir string
+		if key != nil && !dryRun {
+			srcs := []string{model}
+			if partsDir != "" {
+				files, err := ioutil.ReadDir(partsDir)
+				errors.Must(err)
+				srcs = nil
+				for _, f := range files {
+					if f.Mode().IsRegular() {
+						srcs = append(srcs, filepath.Join(partsDir, f.Name()))
+					}
+				}
+			}
+			need, err := file.Filesize(model)
+			if partsDir != "" {
+				need, err = dirSize(partsDir)
+			}
+			errors.Must(err)
+			checkDiskSpace(os.TempDir(), need)
+			encDir = encryptDir(origModel+origParts, key)
+			encs, err := encryptFiles(srcs, encDir, key, resume)
+			if err != nil {
+				logging.Errorln("Failed to encrypt:", err)
+				errors.Exit(err)
+			}
+			if partsDir != "" {
+				partsDir = encDir
+			} else {
+				model = encs[0]
+			}
+			logging.Infof("Encrypted by %s key %s\n", file.EncryptAlgorithm, file.KeyID(key))
+		}
+
 		// setup direct upload server
-		var serDone func()
 		var baseURL, directURL string
 		filename := filepath.Base(model)
-		if meth == service.DirectUploadMethod {
+		if meth == service.DirectUploadMethod && !dryRun {
 			bu, done, err := web.StartLocalServer(filepath.Dir(model), ip, port, false)
 			errors.Must(err)
 			defer done()
-			serDone = done
 			baseURL = bu
 			directURL = fmt.Sprintf("%s/%s", baseURL, filename)
 		}
 
+		// large file is pulled in chunks, so that it survives connection drops
+		var chunked bool
+		if meth == service.DirectUploadMethod && !dryRun && model != "" {
+			size, err := file.Filesize(model)
+			errors.Must(err)
+			if size > web.ChunkSize {
+				if hasFeature(types.FeatureChunkedModel) {
+					chunked = true
+					directURL = web.ChunkedURL(baseURL, filename)
+				} else {
+					logging.Warnln("Chunked upload is not supported by the api server, the model is pulled as a whole")
+				}
+			}
+		}
+
 		// set bucket
-		b, err := service.SuggestBucket(meth, bucket, "model")
+		b, err := service.SuggestBucket(meth, bucket, "model", autoBucket)
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 		bucket = b
 		if bucket != "" {
-			log.Printf("Bucket %q is chosen", bucket)
+			logging.Infof("Bucket %q is chosen", bucket)
+		}
+
+		src := model
+		size, err := file.Filesize(model)
+		if partsDir != "" {
+			src = partsDir
+			size, err = dirSize(partsDir)
+		}
+		errors.Must(err)
+		service.Check(nil, service.CheckStorageQuota(size))
+		if partsDir == "" {
+			if err = service.Check(nil, service.CheckMaxFileSize("model", model, size)); err != nil {
+				errors.Exit(err)
+			}
+		}
+
+		if dryRun {
+			fmt.Printf("Model: %q\tSize: %s\tMethod: %q\n", src, humanize.IBytes(uint64(size)), meth)
+			if key != nil {
+				logging.Infof("Would be encrypted by %s key %s\n", file.EncryptAlgorithm, file.KeyID(key))
+			}
+			logging.Infoln("Dry run: nothing is registered or uploaded.")
+			return
 		}
 
 		// register + upload + state check
@@ -103,46 +183,121 @@ var importModelCmd = &cobra.Command{
 			ModelPath:    model,
 			Filename:     filename,
 			DirectURL:    directURL,
+			Chunked:      chunked,
 			Bucket:       bucket,
 			MultipartDir: partsDir,
+			PartSize:     partSize * (1 << 20),
+			Parallel:     thread,
+			Resume:       resume,
 			Timeout:      timeout,
 			Verbose:      verbose,
+			Verify:       verifyUpload,
+			Schedule:     uploadSchedule,
 		}
 
-		// capture and handle ctrl+c
-		cc := make(chan os.Signal)
-		signal.Notify(cc, os.Interrupt, syscall.SIGTERM)
+		// the upload could not be stopped gracefully, so it is aborted on
+		// ctrl+c or '--deadline', keeping the parts for '--resume'
+		ctx, cancel := interruptContext()
+		defer cancel()
+		uploader := lifecycle.Register("model uploader", mru.Done)
+		finished := make(chan struct{})
 		go func() {
-			<-cc
-			fmt.Println()
-			if serDone != nil {
-				serDone()
+			select {
+			case <-finished:
+				return
+			case <-ctx.Done():
+			}
+			select {
+			case <-finished:
+				return
+			default:
+			}
+			if ctx.Err() == context.DeadlineExceeded {
+				logging.Warnf("Stopped by the deadline of %s, the model is not fully imported. Run the same command with --resume to continue.\n", deadline)
+				errors.Exit(errors.ErrDeadlineExceeded)
 			}
-			mru.Done()
-			log.Println("Bye!")
-			os.Exit(1)
+			abortNow()
 		}()
 
+		// the api server pulls the file by itself for direct upload
+		var pr service.ProgressReporter
+		if meth != service.DirectUploadMethod {
+			// size of multiparts is unknown until split
+			var size int64
+			if model != "" {
+				size, err = file.Filesize(model)
+				errors.Must(err)
+			}
+			pr = service.NewProgressReporter(0, size)
+			mru.Progress = pr
+		}
+
 		state, err := mru.Run()
+		close(finished)
+		uploader.Remove()
+		if pr != nil {
+			pr.Close()
+		}
+
+		mf := db.NewManifest(proj.ID, meth, bucket)
+		mf.Start = start
+		e := db.ManifestEntry{Kind: "model", Path: origModel, Filename: filename, ID: proj.ID, State: state}
+		if origParts != "" {
+			e.Path = origParts
+		}
+		encryptEntry(&e, key)
+		if err != nil {
+			e.Error = err.Error()
+		}
+		mf.Entries = append(mf.Entries, e)
+		saveManifest(mf, manifestFile(proj.ID))
 		if err != nil {
-			log.Println(err.Error())
+			logging.Errorln(err.Error())
 			return
 		}
+		if encDir != "" {
+			os.RemoveAll(encDir)
+		}
 
-		log.Printf("Successfully registered and uplaoded in state: %q!\n", state)
-		log.Printf("PID: %q\n", proj.ID)
+		logging.Infof("Successfully registered and uplaoded in state: %q!\n", state)
+		logging.Infof("PID: %q\n", proj.ID)
 	},
 }
 
+// dirSize gives the total size of the regular files directly under dir.
+func dirSize(dir string) (int64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	var ret int64
+	for _, f := range files {
+		if f.Mode().IsRegular() {
+			ret += f.Size()
+		}
+	}
+	return ret, nil
+}
+
 func init() {
 	importCmd.AddCommand(importModelCmd)
 	importModelCmd.Flags().StringVarP(&id, "id", "p", id, "Project id")
 	importModelCmd.Flags().StringVarP(&model, "file", "f", model, "File path of model zip file or directory of multiparts zip.")
-	importModelCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct' or 's3'")
+	importModelCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct', 's3', 'gcs' or 'oss'")
 	importModelCmd.Flags().IntVarP(&timeout, "timeout", "t", timeout, "Timeout of checking direct upload state in seconds")
 	importModelCmd.Flags().StringVar(&ip, "ip", ip, "IP address of ad-hoc local server for direct upload.")
 	importModelCmd.Flags().StringVar(&port, "port", port, "Port of ad-hoc local server for direct upload.")
-	importModelCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload for method: 's3'")
+	importModelCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload for method: 's3', 'gcs' or 'oss'")
+	importModelCmd.Flags().BoolVar(&autoBucket, "auto-bucket", autoBucket, "Choose the bucket of the lowest measured upload latency if bucket is not given, cached for 24 hours")
+	importModelCmd.Flags().Int64Var(&partSize, "part-size", partSize, "Split the model into parts of this size in MB if it is larger, default is splitting only models larger than 5GB into 100MB parts")
+	importModelCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of parts to upload concurrently, default is number of cores")
+	importModelCmd.Flags().StringVar(&uploadWindow, "schedule", uploadWindow, "Only upload within the daily window of local time, e.g. '22:00-06:00', pausing and resuming automatically")
+	importModelCmd.Flags().BoolVar(&resume, "resume", resume, "Resume the previous interrupted multipart upload and skip the uploaded parts")
+	importModelCmd.Flags().StringVar(&encryptKeyFile, "encrypt-key-file", encryptKeyFile, "File of a 256-bit key to encrypt the model by AES-GCM before upload")
+	importModelCmd.Flags().BoolVar(&dryRun, "dry-run", dryRun, "Check and print what would be uploaded without registering or uploading")
+	importModelCmd.Flags().BoolVar(&checkOnly, "check-only", checkOnly, "Only run the pre-checks and report the result of each")
+	importModelCmd.Flags().StringVar(&checkFormat, "format", checkFormat, "Format of the '--check-only' report: 'text' or 'json'")
+	importModelCmd.Flags().BoolVar(&verifyUpload, "verify", verifyUpload, "Compare the checksum computed by the server of the imported model with the local one")
 	importModelCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info of operation")
 	errors.Must(importModelCmd.MarkFlagRequired("id"))
 	errors.Must(importModelCmd.MarkFlagRequired("file"))
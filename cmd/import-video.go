@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/lifecycle"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/spf13/cobra"
+)
+
+var video string
+var frameInterval = 1.0
+var keyframes bool
+var framesDir string
+var keepFrames bool
+
+// keyframeCandidates is the number of frames extracted per interval for
+// keeping the sharpest one of '--keyframes'.
+const keyframeCandidates = 4
+
+// importVideoCmd represents the import video command
+var importVideoCmd = &cobra.Command{
+	Use:   "video",
+	Short: "Import the frames of a video into a project",
+	Long: `Extract the frames of a video by ffmpeg at every interval, or the sharpest frame of each interval by '--keyframes', then import them by 'import image'.
+ffmpeg must be installed and found in PATH.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		start := time.Now()
+		defer func() {
+			if verbose {
+				elapsed := time.Since(start)
+				logging.Infoln("Took", elapsed)
+			}
+		}()
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+
+		// pre-checks
+		if frameInterval <= 0 {
+			logging.Errorf("Invalid interval: %g\n", frameInterval)
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		if err := service.Check(
+			nil,
+			apiServerCheck(dryRun),
+			service.CheckPID("image", id),
+			service.CheckFile(video),
+		); err != nil {
+			errors.Exit(err)
+		}
+		ffmpeg, err := file.FFmpegPath()
+		if err != nil {
+			logging.Errorln("ffmpeg is required for extracting the frames, install it from https://ffmpeg.org")
+			errors.Exit(err)
+		}
+
+		// extract frames
+		out := framesDir
+		cleanup := func() {}
+		if out == "" {
+			out, err = os.MkdirTemp("", "alti-cli-frames-")
+			errors.Must(err)
+			cleanup = func() {
+				if keepFrames {
+					logging.Infof("Frames are kept in %q\n", out)
+					return
+				}
+				os.RemoveAll(out)
+			}
+		}
+		defer lifecycle.Register("extracted frames", cleanup).Release()
+		fps := 1 / frameInterval
+		if keyframes {
+			fps *= keyframeCandidates
+		}
+		logging.Infof("Extracting frames of %q into %q...\n", video, out)
+		frames, err := file.ExtractFrames(ctx, ffmpeg, video, out, fps)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logging.Errorln("Frame extraction failed:", err)
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		if keyframes {
+			n := len(frames)
+			frames, err = file.KeepSharpest(frames, keyframeCandidates)
+			errors.Must(err)
+			logging.Infof("Kept the sharpest %d out of %d frames\n", len(frames), n)
+		}
+		if len(frames) == 0 {
+			logging.Infoln("No frame is extracted!")
+			return
+		}
+		logging.Infof("Extracted %d frames\n", len(frames))
+
+		// import frames by 'import image'
+		exe, err := os.Executable()
+		errors.Must(err)
+		c := exec.Command(exe, append(importVideoArgs(out), persistentArgs()...)...)
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err = c.Run(); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if framesDir == "" {
+				logging.Infoln("Keep the frames by '--frames-dir' for resuming the import by '--resume'")
+			}
+			cleanup()
+			if ee, ok := err.(*exec.ExitError); ok {
+				os.Exit(ee.ExitCode())
+			}
+			errors.Exit(err)
+		}
+	},
+}
+
+// importVideoArgs gives the args of importing the frames of dir by
+// 'import image'.
+func importVideoArgs(dir string) []string {
+	ret := []string{"import", "image", "-p", id, "-d", dir, "-n", fmt.Sprint(thread)}
+	if method != "" {
+		ret = append(ret, "-m", method)
+	}
+	if bucket != "" {
+		ret = append(ret, "-b", bucket)
+	}
+	if manifestPath != "" {
+		ret = append(ret, "--manifest", manifestPath)
+	}
+	for _, f := range []struct {
+		set  bool
+		flag string
+	}{
+		{assumeYes, "-y"},
+		{verbose, "-v"},
+		{dryRun, "--dry-run"},
+		{resume, "--resume"},
+		{adaptive, "--adaptive"},
+	} {
+		if f.set {
+			ret = append(ret, f.flag)
+		}
+	}
+	return ret
+}
+
+func init() {
+	importCmd.AddCommand(importVideoCmd)
+	importVideoCmd.Flags().StringVarP(&id, "id", "p", id, "Project id")
+	importVideoCmd.Flags().StringVarP(&video, "file", "f", video, "Video file path, of any format supported by ffmpeg")
+	importVideoCmd.Flags().Float64Var(&frameInterval, "interval", frameInterval, "Interval of the extracted frames in seconds")
+	importVideoCmd.Flags().BoolVar(&keyframes, "keyframes", keyframes, "Extract 4 frames per interval and keep the sharpest one")
+	importVideoCmd.Flags().StringVar(&framesDir, "frames-dir", framesDir, "Directory of the extracted frames, which are kept; default is a temporary one")
+	importVideoCmd.Flags().BoolVar(&keepFrames, "keep-frames", keepFrames, "Keep the temporary directory of the extracted frames")
+	importVideoCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct', 's3', 'gcs' or 'oss'")
+	importVideoCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload for method: 's3', 'gcs' or 'oss'")
+	importVideoCmd.Flags().BoolVar(&resume, "resume", resume, "Resume an interrupted import of the frames of '--frames-dir'")
+	importVideoCmd.Flags().BoolVar(&dryRun, "dry-run", dryRun, "Check and print what would be uploaded without registering or uploading")
+	importVideoCmd.Flags().BoolVar(&adaptive, "adaptive", adaptive, "Adapt the number of concurrent uploads to the measured throughput, starting from the one learned in the last run")
+	importVideoCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
+	importVideoCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display individual image info")
+	importVideoCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads to process, default is number of cores x 4")
+	errors.Must(importVideoCmd.MarkFlagRequired("id"))
+	errors.Must(importVideoCmd.MarkFlagRequired("file"))
+}
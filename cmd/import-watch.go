@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
+)
+
+// debounce is the quiet period of a new file before it is imported,
+// so that files being copied are not digested halfway.
+var debounce = 3 * time.Second
+
+// watchImport imports the existing and then the new images of dir into
+// project pid, until ctx is canceled.
+// Images of the same checksum are uploaded once.
+func watchImport(ctx context.Context, pid, meth, baseURL string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	f := pathFilter()
+
+	// existing images are imported first
+	pending := make(map[string]time.Time)
+	if err = watchTree(watcher, dir, f, pending); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	mf := db.NewManifest(pid, meth, bucket)
+	mfPath := manifestFile(pid)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	logging.Infof("Watching %s for new images, press ctrl+c to stop...\n", dir)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+				continue
+			}
+			info, err := os.Stat(ev.Name)
+			if err != nil {
+				continue
+			}
+			if info.IsDir() {
+				if skipWatchDir(f, ev.Name) {
+					continue
+				}
+				// files copied before the dir is watched are not notified
+				if err = watchTree(watcher, ev.Name, f, pending); err != nil {
+					logging.Warnf("Could not watch %q: %v\n", ev.Name, err)
+				}
+				continue
+			}
+			if info.Mode().IsRegular() && f.Match(dir, ev.Name) {
+				pending[ev.Name] = time.Now()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logging.Errorln("Watch error:", err)
+		case <-ticker.C:
+			var ready []string
+			for p, t := range pending {
+				if time.Since(t) >= debounce {
+					ready = append(ready, p)
+					delete(pending, p)
+				}
+			}
+			if len(ready) > 0 {
+				if importPaths(ctx, pid, meth, baseURL, ready, seen, mf) {
+					saveManifest(mf, mfPath)
+				}
+			}
+		}
+	}
+}
+
+// watchTree watches root and its sub-directories, and adds the files
+// under root selected by f to pending.
+func watchTree(watcher *fsnotify.Watcher, root string, f *file.PathFilter, pending map[string]time.Time) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if skipWatchDir(f, path) {
+				return filepath.SkipDir
+			}
+			return watcher.Add(path)
+		}
+		if info.Mode().IsRegular() && f.Match(dir, path) {
+			pending[path] = time.Time{}
+		}
+		return nil
+	})
+}
+
+// skipWatchDir tells if the directory p is not watched, i.e. excluded by f
+// or matching '--skip'.
+func skipWatchDir(f *file.PathFilter, p string) bool {
+	return f.SkipDir(dir, p) || (f.Skip != nil && f.Skip.MatchString(p))
+}
+
+// importPaths digests and uploads the images of paths that are neither
+// in the project nor seen before, recording them in manifest m.
+// Return true if any image is uploaded.
+func importPaths(ctx context.Context, pid, meth, baseURL string, paths []string, seen map[string]bool, m *db.Manifest) bool {
+	pc := make(chan string)
+	go func() {
+		defer close(pc)
+		for _, p := range paths {
+			pc <- p
+		}
+	}()
+
+	cache := openDigestCache()
+	if cache != nil {
+		defer cache.Close()
+	}
+	result := make(chan file.ImageDigest)
+	digester := file.ImageDigester{
+		Root:         dir,
+		PID:          pid,
+		Quality:      minQualityFilter(),
+		WithExif:     withExif,
+		Checksum:     checksumAlgo,
+		Cache:        cache,
+		UprightDir:   uprightDir(),
+		RawDir:       rawDir(),
+		RawQuality:   rawQuality,
+		ResizeDir:    resizeDir(),
+		MaxDimension: maxDimension,
+		JPEGQuality:  jpegQuality,
+		Dcraw:        dcrawPath,
+		Ctx:          ctx,
+		Paths:        pc,
+		Result:       result,
+	}
+	digester.Run(thread)
+
+	var digests []file.ImageDigest
+	for r := range result {
+		if r.Error != nil {
+			if verbose {
+				logging.Warnf("Skipped %q, Reason: %v", r.Path, r.Error)
+			}
+			continue
+		}
+		if len(r.Issues) > 0 {
+			logging.Warnf("Skipped low quality %q, Issues: %s", r.Path, strings.Join(r.Issues, ", "))
+			continue
+		}
+		if r.Existed || seen[r.Checksum] {
+			if verbose {
+				logging.Infof("Skipped duplicate %q\n", r.Path)
+			}
+			continue
+		}
+		seen[r.Checksum] = true
+		digests = append(digests, r)
+	}
+	if len(digests) == 0 {
+		return false
+	}
+	logging.Infof("Importing %d new images...\n", len(digests))
+	uploadDigests(ctx, pid, meth, baseURL, digests, m)
+	return true
+}
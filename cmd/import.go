@@ -16,6 +16,9 @@ var importCmd = &cobra.Command{
 	},
 }
 
+var manifestPath string
+
 func init() {
 	rootCmd.AddCommand(importCmd)
+	importCmd.PersistentFlags().StringVar(&manifestPath, "manifest", manifestPath, "Path of the json manifest of uploaded files, default is under ~/.altizure/manifests")
 }
@@ -2,14 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"log"
-	"os"
 	"strings"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
 	"github.com/jackytck/alti-cli/service"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
@@ -22,15 +19,14 @@ var bucketCmd = &cobra.Command{
 		// pre-checks
 		if err := service.Check(
 			nil,
-			service.CheckAPIServer(),
+			service.CheckAPIServerLite(),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		kinds := []string{"image", "meta", "model"}
 
-		var buckets [][]string
+		var buckets []bucketList
 		for _, k := range kinds {
 			clouds := gql.SupportedCloud("", "", k)
 			for _, c := range clouds {
@@ -45,18 +41,32 @@ var bucketCmd = &cobra.Command{
 				if err != nil {
 					panic(err)
 				}
-				buckets = append(buckets, []string{k, strings.ToLower(c), strings.Join(buks, ", "), suggested, fmt.Sprintf("%d", len(buks))})
+				buckets = append(buckets, bucketList{k, strings.ToLower(c), buks, suggested})
 			}
 		}
 
 		// render
-		table := tablewriter.NewWriter(os.Stdout)
+		if printTemplate(buckets) {
+			return
+		}
+		table := newTable()
 		table.SetHeader([]string{"Kind", "Cloud", "Buckets", "Suggested", "Count"})
-		table.AppendBulk(buckets)
+		for _, b := range buckets {
+			table.Append([]string{b.Kind, b.Cloud, strings.Join(b.Buckets, ", "), b.Suggested, fmt.Sprintf("%d", len(b.Buckets))})
+		}
 		table.Render()
 	},
 }
 
+// bucketList is the buckets of a kind in a cloud.
+type bucketList struct {
+	Kind      string
+	Cloud     string
+	Buckets   []string
+	Suggested string
+}
+
 func init() {
 	listCmd.AddCommand(bucketCmd)
+	bucketCmd.Flags().StringVar(&outputTemplate, "template", outputTemplate, "Go template of the buckets of each kind and cloud, e.g. '{{.Kind}} {{.Cloud}} {{join .Buckets \",\"}}'")
 }
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var imgStates, nameRegex string
+var outputFormat = "table"
+var outputTemplate string
+var imgLimit int
+var imgPageSize = 50
+
+// outputFormats are the supported formats of list output.
+var outputFormats = []string{"table", "json", "csv"}
+
+// listImageCmd represents the list image command
+var listImageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "List the images of a project",
+	Long:  "Page through the images of a project, filtered by states and regular expression of names, in table, json or csv.",
+	Run: func(cmd *cobra.Command, args []string) {
+		// pre-checks
+		if err := service.Check(
+			nil,
+			service.CheckAPIServerLite(),
+			service.CheckPID("", id),
+		); err != nil {
+			errors.Exit(err)
+		}
+		checkOutputFormat()
+		nameRe, err := regexp.Compile(nameRegex)
+		if err != nil {
+			errors.Exit(err)
+		}
+		states := make(map[string]bool)
+		for _, s := range strings.Split(imgStates, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				states[strings.ToLower(s)] = true
+			}
+		}
+
+		p, _ := gql.SearchProjectID(id, true)
+
+		// page through and filter
+		var imgs []types.ProjectImage
+		var total int
+		after := ""
+		for {
+			page, pi, t, err := gql.AllProjectImages(context.Background(), p.ID, imgPageSize, 0, "", after)
+			if err != nil {
+				errors.Exit(err)
+			}
+			total = t
+			for _, img := range page {
+				if len(states) > 0 && !states[strings.ToLower(img.State)] {
+					continue
+				}
+				if nameRegex != "" && !nameRe.MatchString(img.Filename) && !nameRe.MatchString(img.Name) {
+					continue
+				}
+				imgs = append(imgs, img)
+			}
+			if imgLimit > 0 && len(imgs) >= imgLimit {
+				imgs = imgs[:imgLimit]
+				break
+			}
+			if !pi.HasNextPage {
+				break
+			}
+			after = pi.EndCursor
+		}
+
+		if printTemplate(imgs) {
+			return
+		}
+		switch outputFormat {
+		case "json":
+			j, err := json.MarshalIndent(imgs, "", "  ")
+			errors.Must(err)
+			fmt.Println(string(j))
+		case "csv":
+			w := csv.NewWriter(os.Stdout)
+			errors.Must(w.Write(projectImageHeader()))
+			for _, img := range imgs {
+				errors.Must(w.Write(projectImageRow(img)))
+			}
+			w.Flush()
+			errors.Must(w.Error())
+		default:
+			table := newTable()
+			table.SetHeader(projectImageHeader())
+			for _, img := range imgs {
+				table.Append(projectImageRow(img))
+			}
+			table.Render()
+			fmt.Printf("Listed: %d\tTotal: %d\n", len(imgs), total)
+		}
+	},
+}
+
+// projectImageHeader gives the header of listed images.
+func projectImageHeader() []string {
+	return []string{"ID", "Filename", "Name", "State", "Grounded", "URL"}
+}
+
+// projectImageRow gives a row of listed image.
+func projectImageRow(img types.ProjectImage) []string {
+	return []string{img.ID, img.Filename, img.Name, img.State, fmt.Sprintf("%v", img.Grounded), img.URL}
+}
+
+// checkOutputFormat exits if '--output' is not a supported format.
+func checkOutputFormat() {
+	outputFormat = strings.ToLower(outputFormat)
+	if !isOutputFormat(outputFormat) {
+		logging.Errorf("Unknown output: %q, valid outputs are: %q\n", outputFormat, strings.Join(outputFormats, ", "))
+		errors.Exit(errors.ErrInvalidInput)
+	}
+}
+
+// isOutputFormat tells if f is a supported output format.
+func isOutputFormat(f string) bool {
+	for _, o := range outputFormats {
+		if f == o {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	listCmd.AddCommand(listImageCmd)
+	listImageCmd.Flags().StringVarP(&id, "id", "p", id, "Project (partial) id")
+	listImageCmd.Flags().StringVar(&imgStates, "state", imgStates, "Comma separated states to list, e.g. 'Invalid,Pending', default is all")
+	listImageCmd.Flags().StringVar(&nameRegex, "name-regex", nameRegex, "Regular expression of filename or name to list")
+	listImageCmd.Flags().IntVar(&imgLimit, "limit", imgLimit, "Maximum number of images to list, 0 for all")
+	listImageCmd.Flags().IntVar(&imgPageSize, "page-size", imgPageSize, "Number of images to fetch per request")
+	listImageCmd.Flags().StringVarP(&outputFormat, "output", "o", outputFormat, "Output format: 'table', 'json' or 'csv'")
+	listImageCmd.Flags().StringVar(&outputTemplate, "template", outputTemplate, "Go template of each image, e.g. '{{.Filename}} {{.State}}', overrides '--output'")
+	errors.Must(listImageCmd.MarkFlagRequired("id"))
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var projSearch []string
+var projSort string
+var projLimit = 50
+var projAfter string
+var onlyMine, onlyShared bool
+
+// listProjectCmd represents the list project command
+var listProjectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "List my projects by server-side search and sort",
+	Long:  "Search, sort and page through my projects on the server, e.g. 'alti-cli list project --search name~tower --sort -date --mine'.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !IsLogin() {
+			fmt.Println(LoginHint)
+			return
+		}
+		checkOutputFormat()
+
+		q := types.ProjectQuery{First: projLimit, After: projAfter}
+		if err := q.ParseSearch(projSearch); err != nil {
+			logging.Errorln(err)
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		if projSort != "" {
+			if err := q.SetSort(projSort); err != nil {
+				logging.Errorln(err)
+				errors.Exit(errors.ErrInvalidInput)
+			}
+		}
+		switch {
+		case onlyMine && onlyShared:
+			logging.Errorln("Only one of --mine and --shared could be given")
+			errors.Exit(errors.ErrInvalidInput)
+		case onlyMine:
+			q.Scope = types.ProjectScopeMine
+		case onlyShared:
+			q.Scope = types.ProjectScopeShared
+		}
+
+		projs, page, total, err := gql.ListProjects(q)
+		if msg := errors.MustGQL(err, ""); msg != "" {
+			fmt.Println(msg)
+			return
+		}
+		if printTemplate(projs) {
+			return
+		}
+		switch outputFormat {
+		case "json":
+			j, err := json.MarshalIndent(projs, "", "  ")
+			errors.Must(err)
+			fmt.Println(string(j))
+		case "csv":
+			w := csv.NewWriter(os.Stdout)
+			errors.Must(w.Write(types.ProjectHeaderString()))
+			for _, p := range projs {
+				errors.Must(w.Write(p.RowString(gql.WebEndpoint())))
+			}
+			w.Flush()
+			errors.Must(w.Error())
+		default:
+			table := projectsTable(projs)
+			table.Render()
+			fmt.Printf("Listed: %d\tTotal: %d\n", len(projs), total)
+			if page.HasNextPage {
+				fmt.Printf("More projects by: '--after %s'\n", page.EndCursor)
+			}
+		}
+	},
+}
+
+func init() {
+	listCmd.AddCommand(listProjectCmd)
+	listProjectCmd.Flags().StringArrayVarP(&projSearch, "search", "q", projSearch, "Search term: 'name~foo', 'state=Done', 'type=pro' or a part of the name; repeatable")
+	listProjectCmd.Flags().StringVar(&projSort, "sort", projSort, "Sort by 'date', 'gp' or 'numImage', descending if prefixed by '-', e.g. '-date'")
+	listProjectCmd.Flags().IntVar(&projLimit, "limit", projLimit, "Maximum number of projects to list")
+	listProjectCmd.Flags().StringVar(&projAfter, "after", projAfter, "Cursor to list after, as printed by the previous page")
+	listProjectCmd.Flags().BoolVar(&onlyMine, "mine", onlyMine, "List only the projects owned by me")
+	listProjectCmd.Flags().BoolVar(&onlyShared, "shared", onlyShared, "List only the projects shared with me")
+	listProjectCmd.Flags().StringVarP(&outputFormat, "output", "o", outputFormat, "Output format: 'table', 'json' or 'csv'")
+	listProjectCmd.Flags().StringVar(&outputTemplate, "template", outputTemplate, "Go template of each project, e.g. '{{.ID}} {{.Name}}', overrides '--output'")
+}
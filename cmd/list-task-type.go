@@ -1,12 +1,9 @@
 package cmd
 
 import (
-	"log"
-	"os"
-
+	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
 	"github.com/jackytck/alti-cli/service"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
@@ -19,10 +16,9 @@ var taskTypeCmd = &cobra.Command{
 		// pre-checks
 		if err := service.Check(
 			nil,
-			service.CheckAPIServer(),
+			service.CheckAPIServerLite(),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		tts, err := gql.EnumValues("TASK_TYPE")
@@ -35,7 +31,7 @@ var taskTypeCmd = &cobra.Command{
 		}
 
 		// render
-		table := tablewriter.NewWriter(os.Stdout)
+		table := newTable()
 		table.SetHeader([]string{"Task Type"})
 		table.AppendBulk(rows)
 		table.Render()
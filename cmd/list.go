@@ -10,7 +10,7 @@ import (
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Root command for listing various resources or variables.",
-	Long:  `'alti-cli list bucket' to list all available buckets`,
+	Long:  "'alti-cli list bucket' to list all available buckets\n'alti-cli list image' to list the images of a project\n'alti-cli list project' to search and sort my projects",
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("See alti-cli help list")
 	},
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"syscall"
+	"time"
 
 	"github.com/jackytck/alti-cli/config"
 	"github.com/jackytck/alti-cli/errors"
@@ -14,18 +15,19 @@ import (
 
 var byPhone bool
 var withKey bool
+var bySSO bool
 
 // loginCmd represents the login command
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login with email and password.",
-	Long: `Login to Altizure with email and password.
+	Long: `Login to Altizure with email and password, phone or browser.
 	Credentials are stored in '~/.altizure/config'.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// a. api endpoint
 		dc := config.DefaultConfig()
 		dap := dc.GetActive()
-		conf := config.Load()
+		conf := config.LoadAll()
 		var endpoint string
 		fmt.Printf("Endpoint (%s): ", dap.Endpoint)
 		fmt.Scanln(&endpoint)
@@ -48,7 +50,14 @@ var loginCmd = &cobra.Command{
 
 		var token string
 
-		if byPhone {
+		if bySSO {
+			// c0. browser
+			token, err = ssoLogin(endpoint, appKey)
+			if err != nil {
+				fmt.Println("Login failed! Error:", err)
+				return
+			}
+		} else if byPhone {
 			// c1. phone
 			var phone string
 			fmt.Printf("Your phone number (international): ")
@@ -96,6 +105,7 @@ var loginCmd = &cobra.Command{
 			Endpoint: endpoint,
 			Key:      appKey,
 			Token:    token,
+			Expiry:   config.TokenExpiry(token),
 		}
 		err = conf.AddProfile(p)
 		errors.Must(err)
@@ -114,8 +124,40 @@ var loginCmd = &cobra.Command{
 	},
 }
 
+// ssoLogin logins by device code confirmed in browser, polling until the
+// code is confirmed, denied or expired.
+func ssoLogin(endpoint, appKey string) (string, error) {
+	dc, err := gql.RequestDeviceCode(endpoint, appKey)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Open %s in your browser and enter the code: %s\n", dc.VerificationURI, dc.UserCode)
+	fmt.Println("Waiting for confirmation...")
+
+	wait := time.Second * time.Duration(dc.Interval)
+	if wait <= 0 {
+		wait = time.Second * 5
+	}
+	deadline := time.Now().Add(time.Second * time.Duration(dc.ExpiresIn))
+	for dc.ExpiresIn <= 0 || time.Now().Before(deadline) {
+		time.Sleep(wait)
+		token, err := gql.GetUserTokenByDeviceCode(endpoint, appKey, dc.DeviceCode)
+		switch err {
+		case nil:
+			return token, nil
+		case errors.ErrAuthPending:
+		case errors.ErrSlowDown:
+			wait += time.Second * 5
+		default:
+			return "", err
+		}
+	}
+	return "", errors.ErrDeviceCodeExpired
+}
+
 func init() {
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.Flags().BoolVarP(&byPhone, "phone", "p", byPhone, "Use verified phone number to login")
+	loginCmd.Flags().BoolVar(&bySSO, "sso", bySSO, "Login with browser by a device code")
 	loginCmd.Flags().BoolVarP(&withKey, "key", "k", withKey, "Set specific app key instead of default.")
 }
@@ -1,27 +1,38 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	humanize "github.com/dustin/go-humanize"
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
-	"github.com/olekukonko/tablewriter"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/types"
 	"github.com/spf13/cobra"
 )
 
 var jsonOut bool
 
+// expiryWarnDays is the number of days before expiry to start warning.
+const expiryWarnDays = 14
+
 // membershipCmd represents the membership command
 var membershipCmd = &cobra.Command{
 	Use:   "membership",
 	Short: "Membership info",
-	Long:  "My membership info if any.",
+	Long:  "My membership info if any, with the GP quota, storage usage, concurrent task limit and expiry, in table, json or csv.",
 	Run: func(cmd *cobra.Command, args []string) {
+		if jsonOut {
+			outputFormat = "json"
+		}
+		checkOutputFormat()
+
 		endpoint, user, err := gql.MySelf()
 		if msg := errors.MustGQL(err, endpoint); msg != "" {
 			fmt.Println(msg)
@@ -31,58 +42,105 @@ var membershipCmd = &cobra.Command{
 			fmt.Println(LoginHint)
 			return
 		}
+		printMembership(user, time.Now())
+	},
+}
 
-		m := user.Membership
-		if jsonOut {
-			j, err := json.Marshal(m)
-			errors.Must(err)
-			js, err := gql.PrettyPrint(j)
-			errors.Must(err)
-			fmt.Println(js)
-			return
-		}
+// membership is the membership of a user with its quota.
+type membership struct {
+	types.MembershipInfo
+	Quota *types.Quota `json:"quota"`
+}
 
-		header := []string{
-			"State",
-			"Plan",
-			"Months",
-			"Start",
-			"End",
-			"GP Quota",
-			"Coin/GP",
-			"Storage",
-			"Usage",
-			"Visibility",
-			"Coupon",
-			"Model/Project",
-			"Collaborator",
-			"Watermark",
-		}
-		row := []string{
-			m.State,
-			m.PlanName,
-			strconv.Itoa(m.Period),
-			m.StartDate.Format("2006-01-02 15:04:05"),
-			m.EndDate.Format("2006-01-02 15:04:05"),
-			fmt.Sprintf("%.2f", m.MemberGPQuota),
-			fmt.Sprintf("%.2f", m.CoinPerGP),
-			humanize.IBytes(uint64(m.AssetStorage * 1048576)),
-			humanize.IBytes(uint64(user.ModelUsage * 1048576)),
-			strings.Join(m.Visibility, ", "),
-			m.Coupon.String(),
-			strconv.Itoa(m.ModelPerProject),
-			strconv.Itoa(m.CollaboratorQuota),
-			fmt.Sprintf("%v", m.ForceWatermark),
-		}
+// printMembership prints the membership of u by '--template' or in the
+// format of '--output', and warns if it is about to expire at now.
+func printMembership(u *types.User, now time.Time) {
+	m := membership{u.Membership, types.NewQuota(u)}
+	if printTemplate(m) {
+		return
+	}
+	switch outputFormat {
+	case "json":
+		j, err := json.MarshalIndent(m, "", "  ")
+		errors.Must(err)
+		fmt.Println(string(j))
+		return
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		errors.Must(w.Write(membershipHeader()))
+		errors.Must(w.Write(membershipRow(u, now)))
+		w.Flush()
+		errors.Must(w.Error())
+		return
+	}
 
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader(header)
-		table.Append(row)
-		table.Render()
-	},
+	table := newTable()
+	table.SetHeader(membershipHeader())
+	table.Append(membershipRow(u, now))
+	table.Render()
+
+	q := m.Quota
+	if q.Expiry.IsZero() {
+		return
+	}
+	if d := q.DaysLeft(now); d < 0 {
+		logging.Warnf("Membership has expired on %s.", q.Expiry.Format("2006-01-02"))
+	} else if d < expiryWarnDays {
+		logging.Warnf("Membership will expire in %d days.", d)
+	}
+}
+
+// membershipHeader gives the table header of the membership.
+func membershipHeader() []string {
+	return []string{
+		"State",
+		"Plan",
+		"Months",
+		"Start",
+		"End",
+		"Days Left",
+		"GP Quota",
+		"Total GP Quota",
+		"Coin/GP",
+		"Storage",
+		"Usage",
+		"Concurrent Tasks",
+		"Visibility",
+		"Coupon",
+		"Model/Project",
+		"Collaborator",
+		"Watermark",
+	}
+}
+
+// membershipRow gives the table row of the membership of u at now.
+func membershipRow(u *types.User, now time.Time) []string {
+	m := u.Membership
+	q := types.NewQuota(u)
+	return []string{
+		m.State,
+		m.PlanName,
+		strconv.Itoa(m.Period),
+		m.StartDate.Format("2006-01-02 15:04:05"),
+		m.EndDate.Format("2006-01-02 15:04:05"),
+		q.DaysLeftString(now),
+		fmt.Sprintf("%.2f", m.MemberGPQuota),
+		fmt.Sprintf("%.2f", q.GPQuota),
+		fmt.Sprintf("%.2f", m.CoinPerGP),
+		humanize.IBytes(uint64(m.AssetStorage * 1048576)),
+		humanize.IBytes(uint64(u.ModelUsage * 1048576)),
+		q.TasksString(),
+		strings.Join(m.Visibility, ", "),
+		m.Coupon.String(),
+		strconv.Itoa(m.ModelPerProject),
+		strconv.Itoa(m.CollaboratorQuota),
+		fmt.Sprintf("%v", m.ForceWatermark),
+	}
 }
 
 func init() {
 	myCmd.AddCommand(membershipCmd)
-	membershipCmd.Flags().BoolVarP(&jsonOut, "json", "j", jsonOut, "Get JSON output.")
+	membershipCmd.Flags().BoolVarP(&jsonOut, "json", "j", jsonOut, "Get JSON output, same as '-o json'.")
+	membershipCmd.Flags().StringVarP(&outputFormat, "output", "o", outputFormat, "Output format: 'table', 'json' or 'csv'")
+	membershipCmd.Flags().StringVar(&outputTemplate, "template", outputTemplate, "Go template of the membership, e.g. '{{.PlanName}} {{.Quota.GPQuota}}', overrides '--output'")
 }
@@ -3,12 +3,10 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/jackytck/alti-cli/config"
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
@@ -53,7 +51,7 @@ var myTokenCmd = &cobra.Command{
 				active.Token,
 			}
 
-			table := tablewriter.NewWriter(os.Stdout)
+			table := newTable()
 			table.SetHeader(header)
 			table.Append(row)
 			table.Render()
@@ -3,13 +3,11 @@ package cmd
 import (
 	"fmt"
 	"math"
-	"os"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
 	"github.com/jackytck/alti-cli/types"
 	tb "github.com/nsf/termbox-go"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
@@ -78,21 +76,21 @@ func clear() error {
 	return nil
 }
 
-func next(cur string) (*types.PageInfo, int, *tablewriter.Table, error) {
+func next(cur string) (*types.PageInfo, int, outputTable, error) {
 	return get(pageCount, 0, "", cur)
 }
 
-func prev(cur string) (*types.PageInfo, int, *tablewriter.Table, error) {
+func prev(cur string) (*types.PageInfo, int, outputTable, error) {
 	return get(0, pageCount, cur, "")
 }
 
-func get(first, last int, before, after string) (*types.PageInfo, int, *tablewriter.Table, error) {
+func get(first, last int, before, after string) (*types.PageInfo, int, outputTable, error) {
 	projs, page, total, err := gql.MyProjects(first, last, before, after, search)
 	if msg := errors.MustGQL(err, ""); msg != "" {
 		fmt.Println(msg)
-		return nil, 0, nil, err
+		return nil, 0, outputTable{}, err
 	}
-	table := types.ProjectsToTable(projs, gql.WebEndpoint(), os.Stdout)
+	table := projectsTable(projs)
 	return page, total, table, nil
 }
 
@@ -2,13 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"log"
-	"os"
 	"time"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
-	"github.com/jackytck/alti-cli/types"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -25,7 +23,7 @@ var myprojCmd = &cobra.Command{
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
@@ -39,7 +37,10 @@ var myprojCmd = &cobra.Command{
 			fmt.Println(msg)
 			return
 		}
-		table := types.ProjectsToTable(projs, gql.WebEndpoint(), os.Stdout)
+		if printTemplate(projs) {
+			return
+		}
+		table := projectsTable(projs)
 		table.Render()
 		fmt.Printf("Total: %d\n", total)
 		if page.HasNextPage {
@@ -52,5 +53,6 @@ func init() {
 	rootCmd.AddCommand(myprojCmd)
 	myprojCmd.Flags().IntVarP(&pageCount, "count", "c", pageCount, "number of projects to fetch")
 	myprojCmd.Flags().StringVarP(&search, "search", "q", search, "display name to search")
+	myprojCmd.Flags().StringVar(&outputTemplate, "template", outputTemplate, "Go template of each project, e.g. '{{.ID}} {{.TaskState}}'")
 	myprojCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info of operation")
 }
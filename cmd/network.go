@@ -2,12 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/web"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
@@ -15,17 +13,17 @@ import (
 var networkCmd = &cobra.Command{
 	Use:   "network",
 	Short: "Check if api server could reach this client",
-	Long:  "Locally start a web server and check if the api server could reach this server.",
+	Long:  "Locally start a web server and check if the api server could reach this server over each IPv4 and IPv6 address, or only of '--interface', with the reason of each invisible one.",
 	Run: func(cmd *cobra.Command, args []string) {
 		u, res, err := web.PreferredLocalURL(verbose)
 		if err != errors.ErrClientInvisible {
 			errors.Must(err)
 		}
 
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"URL", "Visibility"})
-		for k, v := range res {
-			r := []string{k, strconv.FormatBool(v)}
+		table := newTable()
+		table.SetHeader([]string{"Interface", "URL", "Visibility", "Reason"})
+		for _, v := range res {
+			r := []string{v.Interface, v.URL, strconv.FormatBool(v.Visible), v.Reason}
 			table.Append(r)
 		}
 		table.Render()
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/lifecycle"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/notify"
+)
+
+// notifyTimeout is the timeout of posting a notification.
+const notifyTimeout = 10 * time.Second
+
+var notifyURL string
+var notifyDesktop bool
+
+// notifyCommand is the path of the running command.
+var notifyCommand string
+
+// notifyStats are the counts of the finished command, e.g. total and failed
+// files, sent with the notification.
+var notifyStats = make(map[string]int)
+
+var notifyOnce sync.Once
+var notifyHook *lifecycle.Hook
+var notifyMu sync.Mutex
+var notifyStatus = notify.Interrupted
+var interrupted bool
+
+// notifyOnCompletion sends the notifications of '--notify-url' and
+// '--notify-desktop' when the running long-running command finishes,
+// fails or is interrupted. It is called by interruptContext.
+func notifyOnCompletion() {
+	if notifyURL == "" && !notifyDesktop {
+		return
+	}
+	notifyOnce.Do(func() {
+		start := time.Now()
+		notifyHook = lifecycle.Register("notification", func() {
+			sendNotification(notifyCommand, start)
+		})
+	})
+}
+
+// notifyInterrupted marks the command as interrupted by ctrl+c or SIGTERM,
+// even if it then finishes without error.
+func notifyInterrupted() {
+	notifyMu.Lock()
+	defer notifyMu.Unlock()
+	interrupted = true
+}
+
+// finishNotification sends the notification of the finished command, if any.
+func finishNotification() {
+	if notifyHook == nil {
+		return
+	}
+	notifyMu.Lock()
+	if !interrupted {
+		notifyStatus = notify.Succeeded
+	}
+	notifyMu.Unlock()
+	notifyHook.Release()
+}
+
+// sendNotification posts the summary of the command started at start, with
+// the error of errors.Exit if it failed.
+func sendNotification(command string, start time.Time) {
+	notifyMu.Lock()
+	status := notifyStatus
+	notifyMu.Unlock()
+	s := notify.Summary{
+		Command:  command,
+		Status:   status,
+		Project:  id,
+		Stats:    notifyStats,
+		Version:  Version,
+		Started:  start,
+		Finished: time.Now(),
+	}
+	if err := errors.Exiting(); err != nil {
+		s.Status = notify.Failed
+		s.Error = err.Error()
+		s.ExitCode = errors.ExitCode(err)
+	}
+	s.Host, _ = os.Hostname()
+
+	if notifyURL != "" {
+		if err := notify.Post(context.Background(), notifyURL, s, notifyTimeout); err != nil {
+			logging.Warnln("Notification could not be posted:", err)
+		}
+	}
+	if notifyDesktop {
+		title := "alti-cli " + s.Status
+		if err := notify.Desktop(title, strings.TrimPrefix(s.Line(), "alti-cli ")); err != nil {
+			logging.Warnln("Desktop notification could not be shown:", err)
+		}
+	}
+}
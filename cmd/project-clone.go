@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var cloneImages bool
+
+// projCloneCmd represents the project clone command
+var projCloneCmd = &cobra.Command{
+	Use:   "clone",
+	Short: "Clone a reconstruction project into a new project.",
+	Long:  "Clone the name, project type, meta files and optionally images of a reconstruction project into a new project, e.g. for re-running reconstruction with different settings.",
+	Run: func(cmd *cobra.Command, args []string) {
+		// pre-checks general
+		if err := service.Check(
+			nil,
+			service.CheckAPIServer(),
+			service.CheckPID("", id),
+		); err != nil {
+			errors.Exit(err)
+		}
+
+		p, err := gql.SearchProjectID(id, true)
+		if err != nil {
+			errors.Exit(err)
+		}
+		if p.IsImported {
+			logging.Errorln("Only reconstruction project could be cloned!")
+			return
+		}
+		if name == "" {
+			name = p.Name + " (clone)"
+		}
+
+		// a. project
+		pid, err := gql.CreateProject(name, p.ProjectType, "", visibility)
+		if err != nil {
+			errors.Exit(err)
+		}
+		logging.Infof("Created project %q (%s)\n", name, pid)
+
+		// b. meta files
+		metas, err := gql.AllMetaFiles(p.ID)
+		if err != nil {
+			errors.Exit(err)
+		}
+		var metaCnt int
+		for _, m := range metas {
+			if m.State != service.Ready || m.URL == "" {
+				continue
+			}
+			if _, err := gql.RegisterMetaURL(context.Background(), pid, m.URL, m.Filename, m.Checksum); err != nil {
+				logging.Warnf("Meta file %q could not be cloned: %v\n", m.Filename, err)
+				continue
+			}
+			metaCnt++
+			if verbose {
+				logging.Infof("Cloned meta file %q\n", m.Filename)
+			}
+		}
+		logging.Infof("%d meta files are cloned.\n", metaCnt)
+
+		// c. images
+		if cloneImages {
+			imgs, err := listRemoteImages(p.ID)
+			if err != nil {
+				errors.Exit(err)
+			}
+			okCnt := cloneProjectImages(pid, imgs, thread)
+			logging.Infof("%d out of %d images are cloned.\n", okCnt, len(imgs))
+		}
+
+		fmt.Printf("Successfully cloned %q (%s) into %q (%s)\n", p.Name, p.ID, name, pid)
+	},
+}
+
+// cloneProjectImages re-registers the ready images by their urls into
+// project pid with n workers. Return the number of cloned images.
+func cloneProjectImages(pid string, imgs []types.ProjectImage, n int) int {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	in := make(chan types.ProjectImage)
+	go func() {
+		defer close(in)
+		for _, img := range imgs {
+			if img.State == service.Ready && img.URL != "" {
+				in <- img
+			}
+		}
+	}()
+
+	var mu sync.Mutex
+	var okCnt int
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			for img := range in {
+				if _, err := gql.RegisterImageURL(context.Background(), pid, img.URL, img.Filename, "", nil); err != nil {
+					logging.Warnf("Image %q could not be cloned: %v\n", img.Filename, err)
+					continue
+				}
+				if verbose {
+					logging.Infof("Cloned image %q\n", img.Filename)
+				}
+				mu.Lock()
+				okCnt++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return okCnt
+}
+
+func init() {
+	projectCmd.AddCommand(projCloneCmd)
+	projCloneCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id of the project to clone")
+	projCloneCmd.Flags().StringVarP(&name, "name", "n", name, "Name of the new project, default is the original name with suffix '(clone)'")
+	projCloneCmd.Flags().StringVar(&visibility, "visibility", visibility, "public, unlisted, private")
+	projCloneCmd.Flags().BoolVar(&cloneImages, "images", cloneImages, "Clone the images too by re-registering their urls")
+	projCloneCmd.Flags().IntVar(&thread, "thread", thread, "Number of images to clone concurrently, default is number of cores")
+	projCloneCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display individual image info")
+	errors.Must(projCloneCmd.MarkFlagRequired("id"))
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+// projCollaboratorsCmd represents the project collaborators command
+var projCollaboratorsCmd = &cobra.Command{
+	Use:     "collaborators",
+	Aliases: []string{"members"},
+	Short:   "List the collaborators of a project.",
+	Long:    "List the users and invited emails sharing a project, with their permission levels, in table, json or csv.",
+	Run: func(cmd *cobra.Command, args []string) {
+		// pre-checks
+		if err := service.Check(
+			nil,
+			service.CheckAPIServerLite(),
+			service.CheckPID("", id),
+		); err != nil {
+			errors.Exit(err)
+		}
+		checkOutputFormat()
+
+		p, _ := gql.SearchProjectID(id, true)
+		cs, err := gql.ProjectCollaborators(p.ID)
+		if err != nil {
+			errors.Exit(err)
+		}
+		printCollaborators(cs)
+	},
+}
+
+// printCollaborators prints the collaborators by '--template' or in the format of '--output'.
+func printCollaborators(cs []types.Collaborator) {
+	if printTemplate(cs) {
+		return
+	}
+	switch outputFormat {
+	case "json":
+		j, err := json.MarshalIndent(cs, "", "  ")
+		errors.Must(err)
+		fmt.Println(string(j))
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		errors.Must(w.Write(types.CollaboratorHeaderString()))
+		for _, c := range cs {
+			errors.Must(w.Write(c.RowString()))
+		}
+		w.Flush()
+		errors.Must(w.Error())
+	default:
+		table := newTable()
+		table.SetHeader(types.CollaboratorHeaderString())
+		for _, c := range cs {
+			table.Append(c.RowString())
+		}
+		table.Render()
+		fmt.Printf("Collaborators: %d\n", len(cs))
+	}
+}
+
+func init() {
+	projectCmd.AddCommand(projCollaboratorsCmd)
+	projCollaboratorsCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id")
+	projCollaboratorsCmd.Flags().StringVarP(&outputFormat, "output", "o", outputFormat, "Output format: 'table', 'json' or 'csv'")
+	projCollaboratorsCmd.Flags().StringVar(&outputTemplate, "template", outputTemplate, "Go template of each collaborator, e.g. '{{.Email}} {{.Permission}}', overrides '--output'")
+	errors.Must(projCollaboratorsCmd.MarkFlagRequired("id"))
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var projFilter string
+
+// projDeleteCmd represents the project delete command
+var projDeleteCmd = &cobra.Command{
+	Use:               "delete [PID...]",
+	ValidArgsFunction: completeProjectIDs,
+	Short:             "Remove projects by pids or filter",
+	Long: `Remove the projects of the given (partial) pids, or all my projects matching '--filter', e.g.
+  alti-cli project delete --filter "taskState=Failed and date<2023-01-01"
+Conditions are joined by 'and' on the fields: taskState, name, projectType, isImported, numImage, gigaPixel and date.
+If both pids and filter are given, only the matched projects of the pids are removed.
+The projects to remove are listed for confirmation, skip it by '-y'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// pre-checks
+		if err := service.Check(nil, service.CheckAPIServer()); err != nil {
+			errors.Exit(err)
+		}
+		if len(args) == 0 && projFilter == "" {
+			logging.Errorln("Either pids or '--filter' is required")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		var filter types.ProjectFilter
+		if projFilter != "" {
+			f, err := types.ParseProjectFilter(projFilter)
+			if err != nil {
+				logging.Errorln("Invalid filter:", err)
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			filter = f
+		}
+
+		projs, err := projectsToDelete(args, filter)
+		if err != nil {
+			errors.Exit(err)
+		}
+		if len(projs) == 0 {
+			fmt.Println("No project matched.")
+			return
+		}
+
+		// confirm
+		table := projectsTable(projs)
+		table.Render()
+		fmt.Printf("Remove these %d project(s) or not? (Y/N): ", len(projs))
+		if assumeYes {
+			fmt.Println("Yes")
+		} else {
+			var ans string
+			fmt.Scanln(&ans)
+			ans = strings.ToUpper(ans)
+			if ans != "Y" && ans != service.Yes {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		failed := 0
+		for _, p := range projs {
+			if _, err := gql.RemoveProject(p.ID); err != nil {
+				logging.Errorf("Project %s (%s) could not be removed! Error: %v\n", p.ID, p.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Removed: %s (%s)\n", p.ID, p.Name)
+		}
+		fmt.Printf("Removed %d of %d project(s).\n", len(projs)-failed, len(projs))
+		if failed > 0 {
+			errors.Exit(errors.ErrProjRemove)
+		}
+	},
+}
+
+// projectsToDelete resolves the (partial) pids, or pages through all my
+// projects if none, and keeps the ones matching filter.
+func projectsToDelete(pids []string, filter types.ProjectFilter) ([]types.Project, error) {
+	var cands []types.Project
+	if len(pids) > 0 {
+		for _, pid := range pids {
+			p, err := gql.SearchProjectID(pid, true)
+			if err != nil {
+				logging.Errorf("Project %q could not be found! Error: %v\n", pid, err)
+				return nil, errors.ErrProjNotFound
+			}
+			cands = append(cands, *p)
+		}
+	} else {
+		after := ""
+		for {
+			page, pi, _, err := gql.MyProjects(50, 0, "", after, "")
+			if err != nil {
+				return nil, err
+			}
+			cands = append(cands, page...)
+			if pi == nil || !pi.HasNextPage {
+				break
+			}
+			after = pi.EndCursor
+		}
+	}
+
+	seen := make(map[string]bool)
+	var ret []types.Project
+	for _, p := range cands {
+		if seen[p.ID] || !filter.Match(p) {
+			continue
+		}
+		seen[p.ID] = true
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+func init() {
+	projectCmd.AddCommand(projDeleteCmd)
+	projDeleteCmd.Flags().StringVarP(&projFilter, "filter", "f", projFilter, "Filter of projects, e.g. \"taskState=Failed and date<2023-01-01\"")
+	projDeleteCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
+}
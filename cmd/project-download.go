@@ -2,8 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"log"
-	"os"
 	"strings"
 	"time"
 
@@ -11,8 +9,8 @@ import (
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +24,7 @@ var projDownloadCmd = &cobra.Command{
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
@@ -37,9 +35,10 @@ var projDownloadCmd = &cobra.Command{
 		}
 
 		// display
-		table := tablewriter.NewWriter(os.Stdout)
+		table := newTable()
 		table.SetHeader([]string{"PID", "State", "Name", "Size", "Last modified", "Link"})
 		var items []item
+		var need int64
 		for _, d := range p.Downloads.Edges {
 			state := d.Node.State
 			name := d.Node.Name
@@ -49,6 +48,7 @@ var projDownloadCmd = &cobra.Command{
 			table.Append([]string{p.ID, state, name, size, modified, link})
 			if link != "" {
 				items = append(items, item{name, link})
+				need += d.Node.Size
 			}
 		}
 		table.Render()
@@ -73,15 +73,16 @@ var projDownloadCmd = &cobra.Command{
 			fmt.Scanln(&ans)
 			ans = strings.ToUpper(ans)
 			if ans != "Y" && ans != service.Yes {
-				log.Println("Cancelled.")
+				logging.Infoln("Cancelled.")
 				return
 			}
 		}
 
+		checkDiskSpace(".", need)
 		for _, v := range items {
-			log.Printf("Downloading %q...", v.Name)
+			logging.Infof("Downloading %q...", v.Name)
 			errors.Must(cloud.GetFile(v.Name, v.Link))
-			log.Println("Done")
+			logging.Infoln("Done")
 		}
 	},
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var description string
+var tags []string
+var newVisibility string
+
+// projEditCmd represents the project edit command
+var projEditCmd = &cobra.Command{
+	Use:     "edit",
+	Aliases: []string{"rename"},
+	Short:   "Edit the name, visibility, description or tags of a project",
+	Long:    "Edit the name, visibility, description or tags of my project, e.g. 'alti-cli project edit -p 5d37e --name tower --tags drone,2024'. Only the given fields are changed, and '--tags \"\"' clears the tags.",
+	Run: func(cmd *cobra.Command, args []string) {
+		var info types.ProjectInfo
+		flags := cmd.Flags()
+		if flags.Changed("name") {
+			if strings.TrimSpace(name) == "" {
+				logging.Errorln("Name could not be empty")
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			info.Name = &name
+		}
+		if flags.Changed("visibility") {
+			newVisibility = strings.ToLower(newVisibility)
+			if _, ok := text.Contains(service.ProjectVisibilities, newVisibility); !ok {
+				logging.Errorf("Unknown visibility: %q, valid visibilities are: %q\n", newVisibility, strings.Join(service.ProjectVisibilities, ", "))
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			info.Visibility = &newVisibility
+		}
+		if flags.Changed("description") {
+			info.Description = &description
+		}
+		if flags.Changed("tags") {
+			ts := []string{}
+			for _, t := range tags {
+				if t = strings.TrimSpace(t); t != "" {
+					ts = append(ts, t)
+				}
+			}
+			info.Tags = &ts
+		}
+		if info == (types.ProjectInfo{}) {
+			logging.Errorln("Nothing to edit, give at least one of --name, --visibility, --description and --tags")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+
+		// pre-checks general
+		if err := service.Check(
+			nil,
+			service.CheckAPIServer(),
+			service.CheckPID("", id),
+		); err != nil {
+			errors.Exit(err)
+		}
+		p, err := gql.SearchProjectID(id, true)
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		res, err := gql.UpdateProjectInfo(p.ID, info)
+		if err != nil {
+			logging.Errorf("Project %q (%s) could not be updated: %v\n", p.Name, p.ID, err)
+			errors.Exit(errors.ErrProjUpdate)
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"ID", "Name", "Visibility", "Description", "Tags"})
+		table.Append([]string{res.ID, res.Name, res.Visibility, res.Description, strings.Join(res.Tags, ", ")})
+		table.Render()
+		fmt.Printf("Successfully updated project: %q (%s)\n", res.Name, res.ID)
+	},
+}
+
+func init() {
+	projectCmd.AddCommand(projEditCmd)
+	projEditCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id")
+	projEditCmd.Flags().StringVar(&name, "name", name, "New name of the project")
+	projEditCmd.Flags().StringVar(&newVisibility, "visibility", newVisibility, "New visibility: public, unlisted, private")
+	projEditCmd.Flags().StringVar(&description, "description", description, "New description of the project")
+	projEditCmd.Flags().StringSliceVar(&tags, "tags", tags, "Comma separated tags replacing the current ones, e.g. 'drone,2024'")
+	errors.Must(projEditCmd.MarkFlagRequired("id"))
+}
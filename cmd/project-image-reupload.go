@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+// reuploadInvalidCmd represents the project image reupload-invalid command
+var reuploadInvalidCmd = &cobra.Command{
+	Use:   "reupload-invalid",
+	Short: "Re-upload the Invalid images of a project from the local files",
+	Long: `List the Invalid images of a project, match them to the local files of a directory by checksum, or by filename if the checksum differs, then remove and upload just those again, e.g. after a flaky network.
+The images that could not be matched are listed with the reasons.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// a. checks
+		if err := service.Check(
+			nil,
+			apiServerCheck(dryRun),
+			service.CheckPID("image", id),
+			service.CheckDir(dir),
+		); err != nil {
+			errors.Exit(err)
+		}
+		checkChecksumAlgo()
+		p, _ := gql.SearchProjectID(id, true)
+
+		// b. invalid images
+		logging.Infoln("Listing project images...")
+		remote, err := listRemoteImages(p.ID)
+		if msg := errors.MustGQL(err, ""); msg != "" {
+			logging.Errorln(msg)
+			return
+		}
+		var invalid []types.ProjectImage
+		byID := make(map[string]bool)
+		byName := make(map[string]bool)
+		for _, r := range remote {
+			if r.State != "Invalid" {
+				continue
+			}
+			invalid = append(invalid, r)
+			byID[r.ID] = true
+			byName[r.Name] = true
+		}
+		if len(invalid) == 0 {
+			fmt.Println("No invalid image is found.")
+			return
+		}
+		logging.Infof("%d out of %d images are invalid", len(invalid), len(remote))
+
+		// c. digest the local images of the same checksums or filenames
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+		logging.Infof("Matching local images in %s...\n", dir)
+		paths, errc := file.WalkFilesBy(ctx, dir, pathFilter())
+		result := make(chan file.ImageDigest)
+		cache := openDigestCache()
+		if cache != nil {
+			defer cache.Close()
+		}
+		digester := file.ImageDigester{
+			Root:     dir,
+			PID:      p.ID,
+			Checksum: checksumAlgo,
+			Cache:    cache,
+			Ctx:      ctx,
+			Paths:    paths,
+			Result:   result,
+		}
+		digester.Run(thread)
+
+		byChecksum := make(map[string]string)
+		byFilename := make(map[string][]string)
+		for r := range result {
+			if r.Error != nil {
+				if verbose {
+					logging.Warnf("Invalid image: %q, Reason: %v", r.Path, r.Error)
+				}
+				continue
+			}
+			if byID[r.IID] {
+				byChecksum[r.IID] = r.Path
+			}
+			if byName[r.Filename] {
+				byFilename[r.Filename] = append(byFilename[r.Filename], r.Path)
+			}
+		}
+		if err = <-errc; err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			panic(err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		// d. match each invalid image, preferring the same checksum
+		var entries []db.ManifestEntry
+		unmatched := newTable()
+		unmatched.SetHeader([]string{"Filename", "ID", "Reason"})
+		for _, r := range invalid {
+			path, reason := matchInvalid(r, byChecksum, byFilename)
+			if reason != "" {
+				unmatched.Append([]string{r.Name, r.ID, reason})
+				continue
+			}
+			if verbose {
+				logging.Infof("Matched %q to %q\n", r.Name, path)
+			}
+			entries = append(entries, db.ManifestEntry{Kind: "image", Path: path, Filename: r.Name, ID: r.ID})
+		}
+		if unmatched.NumLines() > 0 {
+			logging.Warnf("%d invalid images could not be matched:\n", unmatched.NumLines())
+			unmatched.Render()
+		}
+		if len(entries) == 0 {
+			fmt.Println("Nothing to re-upload.")
+			return
+		}
+		if dryRun {
+			logging.Infof("Dry run: %d invalid images would be removed and uploaded again. Nothing is changed.\n", len(entries))
+			return
+		}
+
+		fmt.Printf("Remove and re-upload %d invalid images of project %q or not? (Y/N): ", len(entries), p.ID)
+		if assumeYes {
+			fmt.Println("Yes")
+		} else {
+			var ans string
+			fmt.Scanln(&ans)
+			ans = strings.ToUpper(ans)
+			if ans != "Y" && ans != service.Yes {
+				logging.Infoln("Cancelled.")
+				return
+			}
+		}
+		retryImages(p.ID, entries)
+	},
+}
+
+// matchInvalid gives the local path of invalid image r, by its checksum, or by
+// its filename if it is unique, otherwise the reason why it is not matched.
+func matchInvalid(r types.ProjectImage, byChecksum map[string]string, byFilename map[string][]string) (string, string) {
+	if p, ok := byChecksum[r.ID]; ok {
+		return p, ""
+	}
+	switch ps := byFilename[r.Name]; len(ps) {
+	case 0:
+		return "", "no local file of the same checksum or filename"
+	case 1:
+		return ps[0], ""
+	default:
+		return "", fmt.Sprintf("%d local files of the same filename but different checksums, e.g. %q", len(ps), ps[0])
+	}
+}
+
+func init() {
+	exportImageCmd.AddCommand(reuploadInvalidCmd)
+	reuploadInvalidCmd.Flags().StringVarP(&id, "id", "p", id, "Project id")
+	reuploadInvalidCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory of the local images")
+	reuploadInvalidCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload")
+	reuploadInvalidCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload")
+	reuploadInvalidCmd.Flags().BoolVar(&autoBucket, "auto-bucket", autoBucket, "Choose the bucket of the lowest measured upload latency if bucket is not given, cached for 24 hours")
+	reuploadInvalidCmd.Flags().StringVar(&ip, "ip", ip, "IP address of ad-hoc local server for direct upload.")
+	reuploadInvalidCmd.Flags().StringVar(&port, "port", port, "Port of ad-hoc local server for direct upload.")
+	reuploadInvalidCmd.Flags().StringVar(&checksumAlgo, "checksum", checksumAlgo, "Checksum algorithm of the cached local digests: 'sha1', 'sha256' or 'xxh64'")
+	reuploadInvalidCmd.Flags().StringVarP(&skip, "skip", "s", skip, "Regular expression to skip paths")
+	reuploadInvalidCmd.Flags().StringArrayVar(&includes, "include", includes, "Glob of paths to include, e.g. '*.jpg' or 'raw/**', or a regular expression prefixed by 're:'; repeatable")
+	reuploadInvalidCmd.Flags().StringArrayVar(&excludes, "exclude", excludes, "Glob of paths to exclude, taking precedence over --include; repeatable")
+	reuploadInvalidCmd.Flags().BoolVar(&noCache, "no-cache", noCache, "Digest all images again instead of using the cache of unchanged ones")
+	reuploadInvalidCmd.Flags().IntVarP(&timeout, "timeout", "t", timeout, "Timeout of checking upload state in seconds")
+	reuploadInvalidCmd.Flags().StringVar(&waitStrategy, "wait-strategy", waitStrategy, "Strategy of waiting for the upload states: 'fixed', 'backoff' or 'none' (not waiting)")
+	reuploadInvalidCmd.Flags().IntVar(&pollInterval, "poll-interval", pollInterval, "Interval of polling the upload states in seconds, the initial one of 'backoff'")
+	reuploadInvalidCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads to process, default is number of cores x 4")
+	reuploadInvalidCmd.Flags().BoolVar(&dryRun, "dry-run", dryRun, "Only list the matched and unmatched invalid images, without removing or uploading")
+	reuploadInvalidCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
+	reuploadInvalidCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info of operation")
+}
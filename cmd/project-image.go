@@ -1,146 +1,244 @@
 package cmd
 
 import (
-	"encoding/csv"
+	"context"
 	"fmt"
-	"log"
-	"os"
 	"path/filepath"
+	"strings"
 
+	humanize "github.com/dustin/go-humanize"
 	"github.com/jackytck/alti-cli/cloud"
 	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/export"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
 	"github.com/jackytck/alti-cli/types"
 	"github.com/jackytck/jcconv/file"
 	"github.com/spf13/cobra"
 )
 
 var out, download string
+var exportFormat = "csv"
+var overwrite, skipExisting bool
+var pageSize = 100
+var fetchThread = 4
 
 // exportImageCmd represents the image command
 var exportImageCmd = &cobra.Command{
-	Use:   "image",
-	Short: "Export all images to csv",
-	Long:  "Export all images of a project to a csv.",
+	Use:     "image",
+	Aliases: []string{"images"},
+	Short:   "Export all images to csv, jsonl, parquet or sqlite",
+	Long:    "Export all images of a project to a csv, json lines, parquet or sqlite file, e.g. for analyzing large projects with data tools.",
 	Run: func(cmd *cobra.Command, args []string) {
 		// a. check
 		if err := service.Check(
 			nil,
-			service.CheckAPIServer(),
+			service.CheckAPIServerLite(),
 			service.CheckPID("image", id),
 		); err != nil {
-			log.Println(err)
+			errors.Exit(err)
+		}
+		if overwrite && skipExisting {
+			logging.Errorln("Only one of --overwrite and --skip-existing could be set")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		exportFormat = strings.ToLower(exportFormat)
+		if _, ok := text.Contains(export.Formats(), exportFormat); !ok {
+			logging.Errorf("Unknown format: %q, valid formats are: %q\n", exportFormat, strings.Join(export.Formats(), ", "))
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		if pageSize < 1 || fetchThread < 1 {
+			logging.Errorln("--page-size and --fetch-thread must be positive")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+		imgs, page, total, err := allImages(ctx, pageSize, "")
+		if ctx.Err() != nil {
 			return
 		}
-		first := 10
-		imgs, page, total, err := allImages(first, "")
 		errors.Must(err)
 		if total == 0 {
-			log.Println("No image is found! Bye.")
+			logging.Infoln("No image is found! Bye.")
 			return
 		}
 
-		// b. setup csv writer
+		// b. setup exporter
 		if out == "" {
-			out = fmt.Sprintf("%s-images.csv", id)
+			out = fmt.Sprintf("%s-images.%s", id, exportFormat)
 		}
-		o, err := os.Create(out)
-		errors.Must(err)
-
-		defer o.Close()
-		writer := csv.NewWriter(o)
-		err = writer.Write([]string{"Filename", "Hashed Name", "State", "URL"})
+		exporter, err := export.New(exportFormat, out)
 		errors.Must(err)
 
-		// c. setup download directory
+		// c. setup progress, download directory and downloader
+		pr := service.NewProgressReporter(total, 0)
+		done := make(chan struct{})
+		defer close(done)
+		var items chan cloud.DownloadItem
+		var dlFinished chan struct{}
+		var cache *cloud.DownloadCache
 		if download != "" {
 			err := file.EnsureDir(download, 0755)
 			errors.Must(err)
-			log.Printf("Downloading to %q\n", download)
+			logging.Infof("Downloading to %q\n", download)
+			cache, err = cloud.LoadDownloadCache(download)
+			if err != nil {
+				logging.Warnln("Download cache is reset:", err)
+			}
+
+			items = make(chan cloud.DownloadItem)
+			dlRes := make(chan cloud.DownloadItem)
+			downloader := cloud.Downloader{
+				Items:    items,
+				Done:     done,
+				Ctx:      ctx,
+				Result:   dlRes,
+				Retry:    3,
+				Verbose:  verbose,
+				Existing: existingPolicy(),
+				Cache:    cache,
+				Progress: pr,
+			}
+			threads := downloader.Run(thread)
+			logging.Debugf("Downloading in %d thread(s)...", threads)
+
+			dlFinished = make(chan struct{})
+			go func() {
+				defer close(dlFinished)
+				logDownloads(dlRes)
+			}()
 		}
 
 		// d. export
-		var cnt int
-		log.Printf("Exporting %d images...\n", total)
-		printProgress(cnt, total)
+		logging.Infof("Exporting %d images...\n", total)
 
-		work := func() {
-			c, err := writeCSV(writer, imgs)
-			if err != nil {
-				panic(err)
+		// e. fetch the pages concurrently if their cursors could be derived
+		// from the first one, otherwise one by one, and write them in order
+		pages := (total + pageSize - 1) / pageSize
+		workers := fetchThread
+		first := *page
+		if _, ok := gql.OffsetCursor(first.EndCursor, pageSize); !ok {
+			logging.Debugln("Cursors are not offset based, fetching the pages one by one")
+			workers = 1
+		}
+		logging.Debugf("Fetching %d page(s) of %d images in %d thread(s)...\n", pages, pageSize, workers)
+		fetch := func(ctx context.Context, i int) ([]types.ProjectImage, error) {
+			if i == 0 {
+				return imgs, nil
+			}
+			if workers == 1 {
+				if !page.HasNextPage {
+					return nil, nil
+				}
+				ret, next, _, err := allImages(ctx, pageSize, page.EndCursor)
+				if err == nil {
+					page = next
+				}
+				return ret, err
+			}
+			after, _ := gql.OffsetCursor(first.EndCursor, i*pageSize-1)
+			ret, _, _, err := allImages(ctx, pageSize, after)
+			return ret, err
+		}
+		write := func(imgs []types.ProjectImage) error {
+			if verbose {
+				for _, img := range imgs {
+					logging.Infoln(img.Name, img.Filename, img.State, img.URL)
+				}
+			}
+			if err := exporter.Write(imgs); err != nil {
+				return err
 			}
 			if download != "" {
-				err = downloadImages(imgs)
-				if err != nil {
-					panic(err)
+				queueDownloads(items, imgs, pr)
+			} else {
+				for _, img := range imgs {
+					pr.Done(img.Name, nil)
 				}
 			}
-			cnt += c
-			printProgress(cnt, total)
+			return nil
+		}
+		err = export.Stream(ctx, pages, workers, 2*workers, fetch, write)
+		if err != nil && ctx.Err() == nil {
+			panic(err)
 		}
 
-		// e. loop all images in batch, fetch `first` images at a time
-		work()
-		for page.HasNextPage {
-			imgs, page, _, err = allImages(first, page.EndCursor)
-			if err != nil {
-				panic(err)
+		// f. wait for all downloads
+		if download != "" {
+			close(items)
+			<-dlFinished
+			if cache != nil {
+				if err := cache.Save(); err != nil {
+					logging.Warnln("Download cache could not be saved:", err)
+				}
 			}
-			work()
 		}
+		pr.Close()
+		errors.Must(exporter.Close())
 
-		log.Println("Done")
+		logging.Infof("Exported to %q\n", out)
 	},
 }
 
-func printProgress(work, total int) {
-	log.Printf("========== %v/%v ==========\n", work, total)
-}
-
-func writeCSV(w *csv.Writer, imgs []types.ProjectImage) (int, error) {
+// queueDownloads sends the ready images to the downloader.
+// Images not ready are reported as done without downloading.
+func queueDownloads(items chan<- cloud.DownloadItem, imgs []types.ProjectImage, pr service.ProgressReporter) {
 	for _, img := range imgs {
-		fields := []string{
-			img.Name,
-			img.Filename,
-			img.State,
-			img.URL,
-		}
-		if verbose {
-			log.Println(fields)
+		if img.State != "Ready" {
+			pr.Done(img.Name, nil)
+			continue
 		}
-		err := w.Write(fields)
-		if err != nil {
-			return 0, err
+		items <- cloud.DownloadItem{
+			URL:  img.URL,
+			Path: filepath.Join(download, img.Name),
 		}
 	}
-	w.Flush()
-	return len(imgs), nil
 }
 
-func downloadImages(imgs []types.ProjectImage) error {
-	for _, img := range imgs {
-		if img.State != "Ready" {
+// existingPolicy gives the policy of the existing files by '--overwrite' and
+// '--skip-existing', default is to download only the changed ones.
+func existingPolicy() string {
+	switch {
+	case overwrite:
+		return cloud.ExistingOverwrite
+	case skipExisting:
+		return cloud.ExistingSkip
+	}
+	return cloud.ExistingUpdate
+}
+
+// logDownloads logs each downloaded file if verbose, and the number of
+// skipped ones. Progress and errors are reported by the downloader.
+func logDownloads(res <-chan cloud.DownloadItem) {
+	var skipped int
+	for r := range res {
+		if r.Error != nil {
 			continue
 		}
-		p := filepath.Join(download, img.Name)
-		err := cloud.GetFile(p, img.URL)
-		if err != nil {
-			netErr, ok := err.(errors.NetworkError)
-			if ok {
-				// ignore
-				log.Printf("[Error] %s failed with status code: %d\n", img.URL, netErr.Code)
-				continue
-			} else {
-				return err
+		if r.Skipped {
+			skipped++
+			if verbose {
+				logging.Infof("Skipped %q, %s\n", filepath.Base(r.Path), humanize.IBytes(uint64(r.Size)))
 			}
+			continue
+		}
+		if verbose {
+			logging.Infof("Downloaded %q, %s\n", filepath.Base(r.Path), humanize.IBytes(uint64(r.Size)))
 		}
 	}
-	return nil
+	if skipped > 0 {
+		logging.Infof("Skipped %d existing or unchanged image(s)\n", skipped)
+	}
 }
 
-func allImages(first int, after string) ([]types.ProjectImage, *types.PageInfo, int, error) {
-	imgs, page, total, err := gql.AllProjectImages(id, first, 0, "", after)
+// allImages fetches a page of images after the cursor until ctx is done.
+func allImages(ctx context.Context, first int, after string) ([]types.ProjectImage, *types.PageInfo, int, error) {
+	imgs, page, total, err := gql.AllProjectImages(ctx, id, first, 0, "", after)
+	if ctx.Err() != nil {
+		return nil, nil, 0, ctx.Err()
+	}
 	if msg := errors.MustGQL(err, ""); msg != "" {
 		fmt.Println(msg)
 		return nil, nil, 0, err
@@ -151,7 +249,13 @@ func allImages(first int, after string) ([]types.ProjectImage, *types.PageInfo,
 func init() {
 	projectCmd.AddCommand(exportImageCmd)
 	exportImageCmd.Flags().StringVarP(&id, "id", "p", id, "Project id")
-	exportImageCmd.Flags().StringVarP(&out, "out", "o", out, "Path of output csv")
+	exportImageCmd.Flags().StringVarP(&out, "out", "o", out, "Path of output file, default is PID-images.FORMAT")
+	exportImageCmd.Flags().StringVar(&exportFormat, "format", exportFormat, "Output format: 'csv', 'jsonl', 'parquet' or 'sqlite' (only in builds with cgo)")
 	exportImageCmd.Flags().StringVarP(&download, "download", "d", out, "Directory to download all images")
+	exportImageCmd.Flags().BoolVar(&overwrite, "overwrite", overwrite, "Download all images again, default is to download only the new and changed ones")
+	exportImageCmd.Flags().BoolVar(&skipExisting, "skip-existing", skipExisting, "Skip the existing images without checking if they are changed")
+	exportImageCmd.Flags().IntVar(&pageSize, "page-size", pageSize, "Number of images of each page fetched from the server")
+	exportImageCmd.Flags().IntVar(&fetchThread, "fetch-thread", fetchThread, "Number of pages fetched concurrently")
+	exportImageCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of concurrent downloads, default is number of cores")
 	exportImageCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display individual image info")
 }
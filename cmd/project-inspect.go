@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
@@ -21,7 +20,10 @@ var projInspectCmd = &cobra.Command{
 			fmt.Println("Project could not be found! Error:", err)
 			return
 		}
-		table := types.ProjectsToTable([]types.Project{*p}, gql.WebEndpoint(), os.Stdout)
+		if printTemplate(p) {
+			return
+		}
+		table := projectsTable([]types.Project{*p})
 		table.Render()
 	},
 }
@@ -29,5 +31,6 @@ var projInspectCmd = &cobra.Command{
 func init() {
 	projectCmd.AddCommand(projInspectCmd)
 	projInspectCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id")
+	projInspectCmd.Flags().StringVar(&outputTemplate, "template", outputTemplate, "Go template of the project, e.g. '{{.ID}} {{.TaskState}}'")
 	errors.Must(projInspectCmd.MarkFlagRequired("id"))
 }
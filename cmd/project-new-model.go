@@ -2,11 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
-	"github.com/olekukonko/tablewriter"
+	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +17,10 @@ var newModelCmd = &cobra.Command{
 	Short: "Create an empty model project",
 	Long:  "Create an empty model project.",
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := service.Check(nil, service.CheckAPIServer()); err != nil {
+			errors.Exit(err)
+		}
+
 		pid, err := gql.CreateProject(name, projType, modelType, visibility)
 		if err != nil {
 			fmt.Println("Project could not be created!", err)
@@ -26,7 +29,7 @@ var newModelCmd = &cobra.Command{
 
 		fmt.Println("Successfully created an empty imported project:")
 
-		table := tablewriter.NewWriter(os.Stdout)
+		table := newTable()
 		table.SetHeader([]string{"ID", "Name", "Project Type", "ModelType", "Visibility"})
 		r := []string{pid, name, projType, modelType, visibility}
 		table.Append(r)
@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
-	"github.com/olekukonko/tablewriter"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
 	"github.com/spf13/cobra"
 )
 
@@ -15,32 +17,65 @@ var projType = "free"
 var visibility = "public"
 var silent bool
 var newPID string
+var reconOpt gql.ReconOptions
+var importDir string
 
 // newReconCmd represents the new command
 var newReconCmd = &cobra.Command{
 	Use:   "recon",
 	Short: "Create an empty reconstruction project",
-	Long:  "Create an empty reconstruction project.",
+	Long:  "Create an empty reconstruction project with the model quality and geo-reference options, and optionally import the images of a directory into it.",
 	Run: func(cmd *cobra.Command, args []string) {
-		pid, err := gql.CreateProject(name, projType, "", visibility)
+		reconOpt.Quality = strings.ToLower(reconOpt.Quality)
+		if q := reconOpt.Quality; q != "" {
+			if _, ok := text.Contains(service.ModelQualities, q); !ok {
+				logging.Errorf("Unknown quality: %q, valid qualities are: %q\n", q, strings.Join(service.ModelQualities, ", "))
+				errors.Exit(errors.ErrInvalidInput)
+			}
+		}
+		reconOpt.GeoRef = strings.ToLower(reconOpt.GeoRef)
+		if g := reconOpt.GeoRef; g != "" {
+			if _, ok := text.Contains(service.GeoRefModes, g); !ok {
+				logging.Errorf("Unknown geo-reference: %q, valid modes are: %q\n", g, strings.Join(service.GeoRefModes, ", "))
+				errors.Exit(errors.ErrInvalidInput)
+			}
+		}
+		if reconOpt.CRS != "" && reconOpt.GeoRef == "none" {
+			logging.Errorln("Coordinate reference system is not applicable to a project without geo-reference")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		checks := []service.CheckFn{service.CheckAPIServer()}
+		if importDir != "" {
+			checks = append(checks, service.CheckDir(importDir))
+		}
+		if err := service.Check(nil, checks...); err != nil {
+			errors.Exit(err)
+		}
+
+		pid, err := gql.CreateReconProject(name, projType, visibility, reconOpt)
 		if err != nil {
 			fmt.Println("Project could not be created!", err)
 			return
 		}
+		newPID = pid
 
 		if silent {
 			fmt.Println(pid)
-			return
-		}
-		fmt.Println("Successfully created an empty project:")
+		} else {
+			fmt.Println("Successfully created an empty project:")
 
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"ID", "Name", "Project Type", "Visibility"})
-		r := []string{pid, name, projType, visibility}
-		table.Append(r)
-		table.Render()
+			table := newTable()
+			table.SetHeader([]string{"ID", "Name", "Project Type", "Visibility", "Quality", "Geo-reference", "CRS"})
+			r := []string{pid, name, projType, visibility, reconOpt.Quality, reconOpt.GeoRef, reconOpt.CRS}
+			table.Append(r)
+			table.Render()
+		}
 
-		newPID = pid
+		if importDir != "" {
+			id = pid
+			dir = importDir
+			importImageCmd.Run(cmd, args)
+		}
 	},
 }
 
@@ -49,6 +84,12 @@ func init() {
 	newReconCmd.Flags().StringVarP(&name, "name", "n", name, "Project name")
 	newReconCmd.Flags().StringVarP(&projType, "projectType", "p", projType, "free, pro")
 	newReconCmd.Flags().StringVarP(&visibility, "visibility", "v", visibility, "public, unlisted, private")
+	newReconCmd.Flags().StringVar(&reconOpt.Quality, "quality", reconOpt.Quality, "Model quality preset: low, medium, high, ultra, default is decided by server")
+	newReconCmd.Flags().StringVar(&reconOpt.GeoRef, "georef", reconOpt.GeoRef, "Geo-reference by: none, gps (of images), gcp (ground control points)")
+	newReconCmd.Flags().StringVar(&reconOpt.CRS, "crs", reconOpt.CRS, "Coordinate reference system of the geo-reference, e.g. EPSG:4326")
+	newReconCmd.Flags().StringVar(&importDir, "import-dir", importDir, "Import the images of this directory into the new project")
+	newReconCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload of '--import-dir': 'direct', 's3', 'gcs' or 'oss'")
+	newReconCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
 	newReconCmd.Flags().BoolVarP(&silent, "silent", "s", silent, "Display the new project id only")
 	errors.Must(newReconCmd.MarkFlagRequired("name"))
 }
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var publishPublic bool
+var publishUnlisted bool
+var publishPrivate bool
+var viewerPassword string
+var noPassword bool
+
+// projPublishCmd represents the project publish command
+var projPublishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish a project with the visibility and optional viewer password",
+	Long: `Publish my project as public (default), unlisted or private, optionally protected by a viewer password, e.g.
+'alti-cli project publish -p 5d37e --unlisted --password -', where '-' prompts for the password.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vis := "public"
+		var n int
+		for v, set := range map[string]bool{"public": publishPublic, "unlisted": publishUnlisted, "private": publishPrivate} {
+			if set {
+				vis = v
+				n++
+			}
+		}
+		if n > 1 {
+			logging.Errorln("Only one of --public, --unlisted and --private could be given")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		setPassword := cmd.Flags().Changed("password")
+		if setPassword && noPassword {
+			logging.Errorln("--password and --no-password could not be used together")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		if setPassword && viewerPassword == "-" {
+			viewerPassword = promptPassword("Viewer password: ")
+		}
+		if setPassword && viewerPassword == "" {
+			logging.Errorln("Password could not be empty, clear it by --no-password")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		var pw *string
+		switch {
+		case setPassword:
+			pw = &viewerPassword
+		case noPassword:
+			pw = new(string)
+		}
+		publishProject(vis, pw)
+	},
+}
+
+// projUnpublishCmd represents the project unpublish command
+var projUnpublishCmd = &cobra.Command{
+	Use:   "unpublish",
+	Short: "Unpublish a project by making it private",
+	Long:  "Unpublish my project by making it private, e.g. 'alti-cli project unpublish -p 5d37e'. '--no-password' also clears its viewer password.",
+	Run: func(cmd *cobra.Command, args []string) {
+		var pw *string
+		if noPassword {
+			pw = new(string)
+		}
+		publishProject("private", pw)
+	},
+}
+
+// publishProject sets the visibility of the project of '--id' to vis, and its
+// viewer password to pw, which is cleared if empty and unchanged if nil.
+func publishProject(vis string, pw *string) {
+	if err := service.Check(
+		nil,
+		service.CheckAPIServer(),
+		service.CheckPID("", id),
+	); err != nil {
+		errors.Exit(err)
+	}
+	p, err := gql.SearchProjectID(id, true)
+	if err != nil {
+		errors.Exit(err)
+	}
+
+	res, err := gql.UpdateProjectInfo(p.ID, types.ProjectInfo{Visibility: &vis})
+	if err != nil {
+		logging.Errorf("Visibility of project %q (%s) could not be updated: %v\n", p.Name, p.ID, err)
+		errors.Exit(errors.ErrProjUpdate)
+	}
+	password := "unchanged"
+	if pw != nil {
+		has, err := gql.SetProjectPassword(p.ID, *pw)
+		if err != nil {
+			logging.Errorf("Viewer password of project %q (%s) could not be updated: %v\n", p.Name, p.ID, err)
+			errors.Exit(errors.ErrProjUpdate)
+		}
+		password = "none"
+		if has {
+			password = "set"
+		}
+	}
+
+	table := newTable()
+	table.SetHeader([]string{"ID", "Name", "Visibility", "Password"})
+	table.Append([]string{res.ID, res.Name, res.Visibility, password})
+	table.Render()
+	if strings.ToLower(res.Visibility) == "private" {
+		fmt.Printf("Successfully unpublished project: %q (%s)\n", res.Name, res.ID)
+		return
+	}
+	fmt.Printf("Successfully published project: %q (%s)\n", res.Name, res.ID)
+}
+
+func init() {
+	projectCmd.AddCommand(projPublishCmd)
+	projPublishCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id")
+	projPublishCmd.Flags().BoolVar(&publishPublic, "public", publishPublic, "Publish as public, the default")
+	projPublishCmd.Flags().BoolVar(&publishUnlisted, "unlisted", publishUnlisted, "Publish as unlisted, i.e. only viewable by its link")
+	projPublishCmd.Flags().BoolVar(&publishPrivate, "private", publishPrivate, "Publish as private, i.e. only viewable by me and the collaborators")
+	projPublishCmd.Flags().StringVar(&viewerPassword, "password", viewerPassword, "Password of viewing the project, '-' to prompt for it")
+	projPublishCmd.Flags().BoolVar(&noPassword, "no-password", noPassword, "Clear the password of viewing the project")
+	errors.Must(projPublishCmd.MarkFlagRequired("id"))
+
+	projectCmd.AddCommand(projUnpublishCmd)
+	projUnpublishCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id")
+	projUnpublishCmd.Flags().BoolVar(&noPassword, "no-password", noPassword, "Also clear the password of viewing the project")
+	errors.Must(projUnpublishCmd.MarkFlagRequired("id"))
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/jackytck/alti-cli/errors"
@@ -20,6 +19,10 @@ var projRemoveCmd = &cobra.Command{
 	Short: "Remove project by pid",
 	Long:  "Remove a project by its pid.",
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := service.Check(nil, service.CheckAPIServer()); err != nil {
+			errors.Exit(err)
+		}
+
 		p, err := gql.SearchProjectID(id, true)
 		if err != nil {
 			fmt.Println("Project could not be found! Error:", err)
@@ -49,7 +52,7 @@ var projRemoveCmd = &cobra.Command{
 		}
 
 		fmt.Println("Successfully removed project:")
-		table := types.ProjectsToTable([]types.Project{*p}, gql.WebEndpoint(), os.Stdout)
+		table := projectsTable([]types.Project{*p})
 		table.Render()
 	},
 }
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+// projReport is the data of the html report of a project.
+type projReport struct {
+	Project     types.Project
+	ModelLink   string
+	Generated   time.Time
+	States      []stateCount
+	Failed      []types.ProjectImage
+	Tasks       []types.Task
+	CoinPerGP   float64
+	CoinsSpent  float64
+	TaskFailed  int
+	TotalImages int
+}
+
+// stateCount is the number of images in a state.
+type stateCount struct {
+	State string
+	Count int
+}
+
+// projReportCmd represents the project report command
+var projReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate a html report of a project.",
+	Long:  "Aggregate the project info, image states, giga-pixel, coins spent, task timeline and errors into a single html report for sharing. The report is print friendly, save it as pdf from the browser.",
+	Run: func(cmd *cobra.Command, args []string) {
+		// pre-checks
+		if err := service.Check(
+			nil,
+			service.CheckAPIServerLite(),
+			service.CheckPID("", id),
+		); err != nil {
+			errors.Exit(err)
+		}
+		p, _ := gql.SearchProjectID(id, false)
+
+		logging.Infoln("Listing project images...")
+		imgs, err := listRemoteImages(p.ID)
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		logging.Infoln("Listing project tasks...")
+		tasks, err := gql.ProjectTasks(p.ID)
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		_, user, err := gql.MySelf()
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		r := newProjReport(*p, imgs, tasks, user.Membership.CoinPerGP)
+		r.ModelLink = fmt.Sprintf("%s/project-model?pid=%v", gql.WebEndpoint(), p.ID)
+
+		if out == "" {
+			out = fmt.Sprintf("%s-report.html", p.ID)
+		}
+		f, err := os.Create(out)
+		if err != nil {
+			errors.Exit(err)
+		}
+		defer f.Close()
+		if err = reportTmpl.Execute(f, r); err != nil {
+			errors.Exit(err)
+		}
+		logging.Infof("Report of project %q is written to %q\n", p.ID, out)
+	},
+}
+
+// newProjReport aggregates the images and tasks of project p.
+// Coins spent is estimated from the giga-pixel of the project.
+func newProjReport(p types.Project, imgs []types.ProjectImage, tasks []types.Task, coinPerGP float64) projReport {
+	r := projReport{
+		Project:     p,
+		Generated:   time.Now(),
+		Tasks:       tasks,
+		CoinPerGP:   coinPerGP,
+		CoinsSpent:  p.GigaPixel * coinPerGP,
+		TotalImages: len(imgs),
+	}
+
+	cnt := make(map[string]int)
+	for _, img := range imgs {
+		cnt[img.State]++
+		if img.State != service.Ready {
+			r.Failed = append(r.Failed, img)
+		}
+	}
+	for s, c := range cnt {
+		r.States = append(r.States, stateCount{s, c})
+	}
+	sort.Slice(r.States, func(i, j int) bool {
+		return r.States[i].Count > r.States[j].Count
+	})
+
+	sort.Slice(r.Tasks, func(i, j int) bool {
+		return r.Tasks[i].StartDate.Before(r.Tasks[j].StartDate)
+	})
+	for _, t := range r.Tasks {
+		if t.State == service.Failed {
+			r.TaskFailed++
+		}
+	}
+	return r
+}
+
+var reportTmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04:05")
+	},
+	"duration": func(t types.Task) string {
+		if t.StartDate.IsZero() || t.EndDate.IsZero() {
+			return "-"
+		}
+		return t.EndDate.Sub(t.StartDate).Round(time.Second).String()
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Project.Name}} - Project Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f0f0f0; }
+.failed { color: #c00; }
+@media print {
+	body { margin: 0; }
+	a { color: #222; text-decoration: none; }
+	h2 { page-break-after: avoid; }
+	tr { page-break-inside: avoid; }
+}
+</style>
+</head>
+<body>
+<h1>{{.Project.Name}}</h1>
+<p>Generated at {{date .Generated}}</p>
+
+<h2>Project</h2>
+<table>
+<tr><th>ID</th><td>{{.Project.ID}}</td></tr>
+<tr><th>Type</th><td>{{.Project.ProjectType}}</td></tr>
+<tr><th>Created</th><td>{{date .Project.Date}}</td></tr>
+<tr><th>Task State</th><td>{{.Project.TaskState}}</td></tr>
+<tr><th>Images</th><td>{{.TotalImages}}</td></tr>
+<tr><th>Giga-Pixel</th><td>{{printf "%.2f" .Project.GigaPixel}}</td></tr>
+<tr><th>Coins Spent (estimated)</th><td>{{printf "%.2f" .CoinsSpent}} ({{printf "%.2f" .CoinPerGP}} coins per GP)</td></tr>
+<tr><th>Model</th><td><a href="{{.ModelLink}}">{{.ModelLink}}</a></td></tr>
+</table>
+
+<h2>Image States</h2>
+<table>
+<tr><th>State</th><th>Count</th></tr>
+{{range .States}}<tr><td>{{.State}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+
+<h2>Task Timeline</h2>
+<table>
+<tr><th>Task</th><th>State</th><th>Start</th><th>End</th><th>Duration</th></tr>
+{{range .Tasks}}<tr{{if eq .State "Failed"}} class="failed"{{end}}><td>{{.TaskType}}</td><td>{{.State}}</td><td>{{date .StartDate}}</td><td>{{date .EndDate}}</td><td>{{duration .}}</td></tr>
+{{else}}<tr><td colspan="5">No task</td></tr>
+{{end}}</table>
+
+<h2>Errors</h2>
+<p>{{len .Failed}} image(s) not ready, {{.TaskFailed}} task(s) failed.</p>
+{{if .Failed}}<table>
+<tr><th>Filename</th><th>Name</th><th>State</th></tr>
+{{range .Failed}}<tr class="failed"><td>{{.Filename}}</td><td>{{.Name}}</td><td>{{.State}}</td></tr>
+{{end}}</table>{{end}}
+</body>
+</html>
+`))
+
+func init() {
+	projectCmd.AddCommand(projReportCmd)
+	projReportCmd.Flags().StringVarP(&id, "id", "p", id, "Project (partial) id")
+	projReportCmd.Flags().StringVarP(&out, "out", "o", out, "Path of the html report, default is <pid>-report.html")
+	errors.Must(projReportCmd.MarkFlagRequired("id"))
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/spf13/cobra"
+)
+
+// projRevokeCmd represents the project revoke command
+var projRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke the access of a collaborator to my project.",
+	Long:  "Revoke the access of a user, or the pending invitation of an email, to my project.",
+	Run: func(cmd *cobra.Command, args []string) {
+		// pre-checks
+		if err := service.Check(
+			nil,
+			service.CheckAPIServer(),
+			service.CheckPID("", id),
+		); err != nil {
+			errors.Exit(err)
+		}
+		p, _ := gql.SearchProjectID(id, true)
+
+		// confirm?
+		fmt.Printf("Are you sure to revoke the access of %q to project: %q (%s)? (Y/N): ", email, p.Name, p.ID)
+		if assumeYes {
+			fmt.Println("Yes")
+		} else {
+			var ans string
+			fmt.Scanln(&ans)
+			ans = strings.ToUpper(ans)
+			if ans != "Y" && ans != service.Yes {
+				logging.Infoln("Cancelled.")
+				return
+			}
+		}
+
+		res, err := gql.RevokeProjectShare(p.ID, email)
+		if err != nil {
+			errors.Exit(err)
+		}
+		fmt.Printf("Successfully revoked the access of %q to project: %q with status: %q\n", email, p.Name, res)
+	},
+}
+
+func init() {
+	projectCmd.AddCommand(projRevokeCmd)
+	projRevokeCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id")
+	projRevokeCmd.Flags().StringVarP(&email, "email", "e", email, "Email of the collaborator")
+	projRevokeCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
+	errors.Must(projRevokeCmd.MarkFlagRequired("id"))
+	errors.Must(projRevokeCmd.MarkFlagRequired("email"))
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/spf13/cobra"
+)
+
+var permission = "view"
+
+// projShareCmd represents the project share command
+var projShareCmd = &cobra.Command{
+	Use:   "share",
+	Short: "Share my project with other users.",
+	Long:  "Share my project with other users by emails at a permission level: 'view', 'edit' or 'admin'. Sharing again with existing collaborators updates their permission.",
+	Run: func(cmd *cobra.Command, args []string) {
+		// pre-checks
+		if err := service.Check(
+			nil,
+			service.CheckAPIServer(),
+			service.CheckPID("", id),
+		); err != nil {
+			errors.Exit(err)
+		}
+		permission = strings.ToLower(permission)
+		if _, ok := text.Contains(service.SharePermissions, permission); !ok {
+			logging.Errorf("Unknown permission: %q, valid permissions are: %q\n", permission, strings.Join(service.SharePermissions, ", "))
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		var emails []string
+		for _, e := range strings.Split(email, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				emails = append(emails, e)
+			}
+		}
+		if len(emails) == 0 {
+			logging.Errorln("No email is given.")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+		checkOutputFormat()
+
+		p, _ := gql.SearchProjectID(id, true)
+		cs, err := gql.ShareProject(p.ID, emails, permission)
+		if err != nil {
+			errors.Exit(err)
+		}
+		logging.Infof("Shared project %q with %q at %q permission\n", p.Name, strings.Join(emails, ", "), permission)
+		printCollaborators(cs)
+	},
+}
+
+func init() {
+	projectCmd.AddCommand(projShareCmd)
+	projShareCmd.Flags().StringVarP(&id, "id", "p", id, "(Partial) Project id")
+	projShareCmd.Flags().StringVarP(&email, "email", "e", email, "Comma separated emails of the users to share with")
+	projShareCmd.Flags().StringVar(&permission, "permission", permission, "Permission level: 'view', 'edit' or 'admin'")
+	projShareCmd.Flags().StringVarP(&outputFormat, "output", "o", outputFormat, "Output format of the collaborators: 'table', 'json' or 'csv'")
+	errors.Must(projShareCmd.MarkFlagRequired("id"))
+	errors.Must(projShareCmd.MarkFlagRequired("email"))
+}
@@ -2,12 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
-	"github.com/olekukonko/tablewriter"
+	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +18,10 @@ var startReconCmd = &cobra.Command{
 	Short: "Start reconstruction.",
 	Long:  "Start a native reconstruction of a project.",
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := service.Check(nil, service.CheckAPIServer()); err != nil {
+			errors.Exit(err)
+		}
+
 		p, err := gql.SearchProjectID(id, true)
 		if err != nil {
 			fmt.Println("Project could not be found! Error:", err)
@@ -38,7 +41,7 @@ var startReconCmd = &cobra.Command{
 			return
 		}
 
-		table := tablewriter.NewWriter(os.Stdout)
+		table := newTable()
 		table.SetHeader([]string{"ID", "Task Type", "State", "Start Date", "Queueing"})
 		d := t.StartDate.Format("2006-01-02 15:04:05")
 		r := []string{t.ID, t.TaskType, t.State, d, string(t.Queueing)}
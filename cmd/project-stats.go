@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var statsJSON string
+
+// projStats is the analytics of a project.
+type projStats struct {
+	PID       string      `json:"pid"`
+	Name      string      `json:"name"`
+	Generated time.Time   `json:"generated"`
+	Images    int         `json:"images"`
+	GigaPixel float64     `json:"gigaPixel"`
+	States    []statCount `json:"states"`
+	Groups    []groupStat `json:"groups"`
+	Reasons   []statCount `json:"invalidReasons"`
+	Timeline  []dayStat   `json:"timeline"`
+	Tasks     []taskStat  `json:"tasks"`
+}
+
+// statCount is the count of a state or reason.
+type statCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// groupStat is the images of a capture group of group.txt, '-' if ungrouped.
+type groupStat struct {
+	Group     string  `json:"group"`
+	Images    int     `json:"images"`
+	GigaPixel float64 `json:"gigaPixel"`
+}
+
+// dayStat is the images uploaded in a day, with the running total.
+type dayStat struct {
+	Date      string  `json:"date"`
+	Images    int     `json:"images"`
+	GigaPixel float64 `json:"gigaPixel"`
+	Total     int     `json:"total"`
+}
+
+// taskStat is the duration of a task, up to now if it is still running.
+type taskStat struct {
+	TaskType string    `json:"taskType"`
+	State    string    `json:"state"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Seconds  float64   `json:"seconds"`
+}
+
+// projStatsCmd represents the project stats command
+var projStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize the images and reconstructions of a project.",
+	Long: `Summarize a project by the histogram of image states, the giga-pixel of each capture group of group.txt, the ranked reasons of the invalid images,
+the upload timeline by day and the durations of the reconstruction tasks. '--json' exports them for dashboards.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := service.Check(
+			nil,
+			service.CheckAPIServerLite(),
+			service.CheckPID("", id),
+		); err != nil {
+			errors.Exit(err)
+		}
+		p, _ := gql.SearchProjectID(id, false)
+
+		logging.Infoln("Listing project images...")
+		imgs := listImageDetails(p.ID)
+		groups := projectGroups(p.ID)
+		tasks, err := gql.ProjectTasks(p.ID)
+		if err != nil {
+			errors.Exit(err)
+		}
+		s := newProjStats(*p, imgs, groups, tasks)
+
+		if statsJSON != "" {
+			b, err := json.MarshalIndent(s, "", "  ")
+			errors.Must(err)
+			if statsJSON == "-" {
+				fmt.Println(string(b))
+				return
+			}
+			errors.Must(os.WriteFile(statsJSON, append(b, '\n'), 0644))
+			defer logging.Infof("Stats of project %q are written to %q\n", p.ID, statsJSON)
+		}
+		printProjStats(s)
+	},
+}
+
+// listImageDetails lists the images of project pid with their details, or
+// only their states if the api server does not give the details.
+func listImageDetails(pid string) []types.ImageDetail {
+	var ret []types.ImageDetail
+	after := ""
+	for {
+		imgs, page, err := gql.AllImageDetails(pid, 50, after)
+		if err != nil {
+			logging.Warnln("Upload dates, giga-pixels and errors of images are unavailable:", err)
+			ret = nil
+			break
+		}
+		ret = append(ret, imgs...)
+		if !page.HasNextPage {
+			return ret
+		}
+		after = page.EndCursor
+	}
+
+	imgs, err := listRemoteImages(pid)
+	if err != nil {
+		errors.Exit(err)
+	}
+	for _, img := range imgs {
+		ret = append(ret, types.ImageDetail{ProjectImage: img})
+	}
+	return ret
+}
+
+// projectGroups gives the capture group of each image of the group.txt of
+// project pid, empty if there is none.
+func projectGroups(pid string) map[string]string {
+	metas, err := gql.AllMetaFiles(pid)
+	if err != nil {
+		logging.Warnln("Meta files could not be listed:", err)
+		return nil
+	}
+	for _, m := range metas {
+		if (m.Name != "group.txt" && m.Filename != "group.txt") || m.URL == "" {
+			continue
+		}
+		res, err := config.HTTPClient(0).Get(m.URL)
+		if err != nil {
+			logging.Warnln("group.txt could not be downloaded:", err)
+			return nil
+		}
+		defer res.Body.Close()
+		groups, err := file.ReadGroup(res.Body)
+		if err != nil {
+			logging.Warnln("group.txt could not be read:", err)
+			return nil
+		}
+		return groups
+	}
+	return nil
+}
+
+// newProjStats aggregates the images, their capture groups and the tasks of
+// project p.
+func newProjStats(p types.Project, imgs []types.ImageDetail, groups map[string]string, tasks []types.Task) projStats {
+	s := projStats{
+		PID:       p.ID,
+		Name:      p.Name,
+		Generated: time.Now(),
+		Images:    len(imgs),
+		GigaPixel: p.GigaPixel,
+	}
+
+	states := make(map[string]int)
+	reasons := make(map[string]int)
+	byGroup := make(map[string]*groupStat)
+	byDay := make(map[string]*dayStat)
+	for _, img := range imgs {
+		states[img.State]++
+		if img.State == "Invalid" {
+			if len(img.Error) == 0 {
+				reasons["(unknown)"]++
+			}
+			for _, e := range img.Error {
+				reasons[e]++
+			}
+		}
+
+		g, ok := groups[img.Name]
+		if !ok {
+			g = "-"
+		}
+		if byGroup[g] == nil {
+			byGroup[g] = &groupStat{Group: g}
+		}
+		byGroup[g].Images++
+		byGroup[g].GigaPixel += img.GigaPixel
+
+		if !img.Date.IsZero() {
+			d := img.Date.Local().Format("2006-01-02")
+			if byDay[d] == nil {
+				byDay[d] = &dayStat{Date: d}
+			}
+			byDay[d].Images++
+			byDay[d].GigaPixel += img.GigaPixel
+		}
+	}
+	s.States = sortedCounts(states)
+	s.Reasons = sortedCounts(reasons)
+
+	for _, g := range byGroup {
+		s.Groups = append(s.Groups, *g)
+	}
+	sort.Slice(s.Groups, func(i, j int) bool {
+		a, errA := strconv.Atoi(s.Groups[i].Group)
+		b, errB := strconv.Atoi(s.Groups[j].Group)
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return s.Groups[i].Group < s.Groups[j].Group
+	})
+
+	for _, d := range byDay {
+		s.Timeline = append(s.Timeline, *d)
+	}
+	sort.Slice(s.Timeline, func(i, j int) bool {
+		return s.Timeline[i].Date < s.Timeline[j].Date
+	})
+	var total int
+	for i := range s.Timeline {
+		total += s.Timeline[i].Images
+		s.Timeline[i].Total = total
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartDate.Before(tasks[j].StartDate)
+	})
+	for _, t := range tasks {
+		ts := taskStat{TaskType: t.TaskType, State: t.State, Start: t.StartDate, End: t.EndDate}
+		switch {
+		case t.StartDate.IsZero():
+		case t.EndDate.IsZero():
+			ts.Seconds = time.Since(t.StartDate).Seconds()
+		default:
+			ts.Seconds = t.EndDate.Sub(t.StartDate).Seconds()
+		}
+		s.Tasks = append(s.Tasks, ts)
+	}
+	return s
+}
+
+// sortedCounts gives the counts, in the descending order of count then name.
+func sortedCounts(m map[string]int) []statCount {
+	var ret []statCount
+	for n, c := range m {
+		ret = append(ret, statCount{n, c})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count != ret[j].Count {
+			return ret[i].Count > ret[j].Count
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// printProjStats prints the stats as tables.
+func printProjStats(s projStats) {
+	fmt.Printf("Project: %s (%s)\tImages #: %d\tGP: %.2f\n", s.Name, s.PID, s.Images, s.GigaPixel)
+	percent := func(n int) string {
+		if s.Images == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", float64(n)*100/float64(s.Images))
+	}
+
+	fmt.Println("\nImage states")
+	table := newTable()
+	table.SetHeader([]string{"State", "Images #", "%"})
+	for _, c := range s.States {
+		table.Append([]string{c.Name, fmt.Sprintf("%d", c.Count), percent(c.Count)})
+	}
+	table.Render()
+
+	fmt.Println("\nCapture groups")
+	table = newTable()
+	table.SetHeader([]string{"Group", "Images #", "GP"})
+	for _, g := range s.Groups {
+		table.Append([]string{g.Group, fmt.Sprintf("%d", g.Images), fmt.Sprintf("%.2f", g.GigaPixel)})
+	}
+	table.Render()
+
+	if len(s.Reasons) > 0 {
+		fmt.Println("\nInvalid reasons")
+		table = newTable()
+		table.SetHeader([]string{"Reason", "Images #"})
+		for _, c := range s.Reasons {
+			table.Append([]string{c.Name, fmt.Sprintf("%d", c.Count)})
+		}
+		table.Render()
+	}
+
+	if len(s.Timeline) > 0 {
+		fmt.Println("\nUpload timeline")
+		table = newTable()
+		table.SetHeader([]string{"Date", "Images #", "GP", "Total #"})
+		for _, d := range s.Timeline {
+			table.Append([]string{d.Date, fmt.Sprintf("%d", d.Images), fmt.Sprintf("%.2f", d.GigaPixel), fmt.Sprintf("%d", d.Total)})
+		}
+		table.Render()
+	}
+
+	fmt.Println("\nReconstruction tasks")
+	table = newTable()
+	table.SetHeader([]string{"Task type", "State", "Start", "End", "Duration"})
+	var total time.Duration
+	for _, t := range s.Tasks {
+		d := time.Duration(t.Seconds * float64(time.Second)).Round(time.Second)
+		total += d
+		table.Append([]string{t.TaskType, t.State, statsDate(t.Start), statsDate(t.End), d.String()})
+	}
+	table.SetFooter([]string{fmt.Sprintf("%d tasks", len(s.Tasks)), "", "", "Total", total.String()})
+	table.Render()
+}
+
+// statsDate formats t, '-' if it is zero.
+func statsDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func init() {
+	projectCmd.AddCommand(projStatsCmd)
+	projStatsCmd.Flags().StringVarP(&id, "id", "p", id, "Project (partial) id")
+	projStatsCmd.Flags().StringVar(&statsJSON, "json", statsJSON, "Also export the stats as json to this path, '-' for stdout only")
+	errors.Must(projStatsCmd.MarkFlagRequired("id"))
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/lifecycle"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+)
+
+var fromProfile, toProfile, toPID string
+var transferBatch = 100
+
+// profileEnv gives the env vars of using the profile ap, see config.FromEnv.
+func profileEnv(ap *config.APoint) []string {
+	return []string{
+		fmt.Sprintf("%s=%s", config.AltiEndpoint, ap.Endpoint),
+		fmt.Sprintf("%s=%s", config.AltiKey, ap.Key),
+		fmt.Sprintf("%s=%s", config.AltiToken, ap.Token),
+	}
+}
+
+// useProfile switches the api calls of this process to the profile ap.
+func useProfile(ap *config.APoint) {
+	for _, kv := range profileEnv(ap) {
+		i := strings.Index(kv, "=")
+		errors.Must(os.Setenv(kv[:i], kv[i+1:]))
+	}
+}
+
+// transferAPoint gives the endpoint and profile of id, exits if not found.
+func transferAPoint(id string) *config.APoint {
+	ap, err := config.LoadAll().GetAPoint(id)
+	if err != nil {
+		logging.Errorf("Profile %q is not found, see 'alti-cli account'\n", id)
+		errors.Exit(err)
+	}
+	if ap.Token == "" {
+		logging.Errorf("Profile %q is not logged in\n", id)
+		errors.Exit(errors.ErrNotLogin)
+	}
+	return ap
+}
+
+// transferProfile copies the images and meta files of project id of
+// fromProfile into a project of toProfile, by batches of transferBatch images.
+// Each batch is downloaded into a temporary directory, imported by
+// 'import image' of toProfile and removed, while the next batch is downloaded.
+func transferProfile() {
+	if fromProfile == "" {
+		fromProfile = config.Load().Active
+	}
+	src := transferAPoint(fromProfile)
+	dst := transferAPoint(toProfile)
+	if transferBatch <= 0 {
+		logging.Errorf("Invalid batch size: %d\n", transferBatch)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+
+	// a. source project
+	useProfile(src)
+	if err := service.Check(
+		nil,
+		service.CheckAPIServerLite(),
+		service.CheckPID("image", id),
+	); err != nil {
+		errors.Exit(err)
+	}
+	p, err := gql.SearchProjectID(id, true)
+	if err != nil {
+		errors.Exit(err)
+	}
+	id = p.ID
+
+	// b. target project
+	useProfile(dst)
+	if err := service.Check(nil, service.CheckAPIServer()); err != nil {
+		errors.Exit(err)
+	}
+	target := "a new project"
+	if toPID != "" {
+		if err := service.Check(nil, service.CheckPID("image", toPID)); err != nil {
+			errors.Exit(err)
+		}
+		tp, err := gql.SearchProjectID(toPID, true)
+		if err != nil {
+			errors.Exit(err)
+		}
+		toPID = tp.ID
+		target = fmt.Sprintf("project %q (%s)", tp.Name, toPID)
+	}
+	fmt.Printf("Copy %d images of project %q (%s) of %q into %s of %q? (Y/N): ", p.NumImage, p.Name, p.ID, src.Endpoint, target, dst.Endpoint)
+	if assumeYes {
+		fmt.Println("Yes")
+	} else {
+		var ans string
+		fmt.Scanln(&ans)
+		ans = strings.ToUpper(ans)
+		if ans != "Y" && ans != service.Yes {
+			logging.Infoln("Cancelled.")
+			return
+		}
+	}
+	if toPID == "" {
+		if name == "" {
+			name = p.Name
+		}
+		toPID, err = gql.CreateReconProject(name, strings.ToLower(p.ProjectType), visibility, gql.ReconOptions{})
+		if err != nil {
+			errors.Exit(err)
+		}
+		logging.Infof("Created project %s of %q\n", toPID, dst.Endpoint)
+	}
+
+	ctx, cancel := interruptContext()
+	defer exitIfInterrupted(ctx, cancel)
+	tmp, err := os.MkdirTemp("", "alti-cli-transfer-")
+	errors.Must(err)
+	defer lifecycle.Register("downloaded images", func() { os.RemoveAll(tmp) }).Release()
+
+	// c. images, downloading the next batch while importing the current one
+	useProfile(src)
+	batches := make(chan string, 1)
+	var listErr error
+	go func() {
+		defer close(batches)
+		var after string
+		for i := 0; ctx.Err() == nil; i++ {
+			imgs, page, _, err := gql.AllProjectImages(ctx, id, transferBatch, 0, "", after)
+			if err != nil {
+				listErr = err
+				return
+			}
+			d := filepath.Join(tmp, fmt.Sprintf("batch-%d", i))
+			if n := downloadImages(ctx, d, imgs); n > 0 {
+				batches <- d
+			}
+			if !page.HasNextPage {
+				return
+			}
+			after = page.EndCursor
+		}
+	}()
+	var failed int
+	for d := range batches {
+		if ctx.Err() == nil {
+			args := []string{"import", "image", "-p", toPID, "-d", d, "-y", "-n", fmt.Sprint(thread)}
+			if err := runAs(dst, append(args, transferUploadArgs()...)); err != nil {
+				logging.Warnf("Importing %q failed: %v\n", d, err)
+				failed++
+			}
+		}
+		os.RemoveAll(d)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if listErr != nil {
+		logging.Errorln("Listing images failed:", listErr)
+		errors.Exit(listErr)
+	}
+
+	// d. meta files
+	metas, err := gql.AllMetaFiles(id)
+	if err != nil {
+		errors.Exit(err)
+	}
+	var items []cloud.DownloadItem
+	metaDir := filepath.Join(tmp, "meta")
+	for _, m := range metas {
+		if m.State == service.Ready {
+			items = append(items, cloud.DownloadItem{URL: m.URL, Path: filepath.Join(metaDir, m.Filename)})
+		}
+	}
+	errors.Must(os.MkdirAll(metaDir, 0755))
+	if len(items) > 0 && downloadItems(ctx, items) < len(items) {
+		args := []string{"import", "meta", "-p", toPID, "-f", metaDir}
+		if err := runAs(dst, append(args, transferUploadArgs()...)); err != nil {
+			logging.Warnf("Importing meta files failed: %v\n", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		logging.Errorf("%d import(s) failed, run again to copy the rest, the imported images are skipped\n", failed)
+		errors.Exit(errors.ErrTransferProject)
+	}
+	logging.Infof("Copied project %s of %q into %s of %q\n", id, src.Endpoint, toPID, dst.Endpoint)
+}
+
+// downloadImages downloads the ready images into dir, named by their
+// filenames, or names if taken. Return the number of downloaded images.
+func downloadImages(ctx context.Context, dir string, imgs []types.ProjectImage) int {
+	taken := make(map[string]bool)
+	var items []cloud.DownloadItem
+	for _, img := range imgs {
+		if img.State != service.Ready {
+			continue
+		}
+		name := img.Filename
+		if name == "" || taken[name] {
+			name = img.Name
+		}
+		taken[name] = true
+		items = append(items, cloud.DownloadItem{URL: img.URL, Path: filepath.Join(dir, name)})
+	}
+	if len(items) == 0 {
+		return 0
+	}
+	errors.Must(os.MkdirAll(dir, 0755))
+	return len(items) - downloadItems(ctx, items)
+}
+
+// downloadItems downloads all of the items concurrently. Return the number
+// of failed ones.
+func downloadItems(ctx context.Context, items []cloud.DownloadItem) int {
+	in := make(chan cloud.DownloadItem)
+	res := make(chan cloud.DownloadItem)
+	done := make(chan struct{})
+	defer close(done)
+	d := cloud.Downloader{
+		Items:   in,
+		Done:    done,
+		Ctx:     ctx,
+		Result:  res,
+		Retry:   3,
+		Verbose: verbose,
+	}
+	d.Run(thread)
+	go func() {
+		defer close(in)
+		for _, it := range items {
+			in <- it
+		}
+	}()
+	var failed int
+	for r := range res {
+		if r.Error != nil {
+			logging.Warnf("Download failed: %q, Reason: %v\n", filepath.Base(r.Path), r.Error)
+			failed++
+		}
+	}
+	return failed
+}
+
+// transferUploadArgs gives the upload flags passed to the imports.
+func transferUploadArgs() []string {
+	var ret []string
+	if method != "" {
+		ret = append(ret, "-m", method)
+	}
+	if bucket != "" {
+		ret = append(ret, "-b", bucket)
+	}
+	if verbose {
+		ret = append(ret, "-v")
+	}
+	return ret
+}
+
+// runAs runs this executable with args as the profile ap.
+func runAs(ap *config.APoint, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	c := exec.Command(exe, append(args, persistentArgs()...)...)
+	c.Env = append(os.Environ(), profileEnv(ap)...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
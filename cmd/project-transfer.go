@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
@@ -14,24 +14,32 @@ import (
 // projTransferCmd represents the project transfer command
 var projTransferCmd = &cobra.Command{
 	Use:   "transfer",
-	Short: "Transfer my project to another user.",
-	Long:  "Transfer my project to another user by email, with custom message.",
+	Short: "Transfer my project to another user, or copy it to another profile.",
+	Long: `Transfer my project to another user by email, with custom message.
+Or copy the images and meta files of my project to another profile by '--to-profile', e.g. from a self-hosted server to the cloud. The images are streamed by batches, without keeping the full dataset on disk.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if toProfile != "" {
+			transferProfile()
+			return
+		}
+		if email == "" {
+			logging.Errorln("Either --email or --to-profile is required")
+			errors.Exit(errors.ErrInvalidInput)
+		}
+
 		// pre-checks general
 		if err := service.Check(
 			nil,
 			service.CheckAPIServer(),
 			service.CheckPID("", id),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		// project info
 		p, err := gql.Project(id)
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		// confirm?
@@ -43,15 +51,14 @@ var projTransferCmd = &cobra.Command{
 			fmt.Scanln(&ans)
 			ans = strings.ToUpper(ans)
 			if ans != "Y" && ans != service.Yes {
-				log.Println("Cancelled.")
+				logging.Infoln("Cancelled.")
 				return
 			}
 		}
 
 		res, err := gql.TransferProject(id, email, message)
 		if err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		fmt.Printf("Successfully transferred project: %q (%s) to %q with status: %q\n", p.Name, id, email, res)
@@ -64,6 +71,15 @@ func init() {
 	projTransferCmd.Flags().StringVarP(&email, "email", "e", email, "Recipient email")
 	projTransferCmd.Flags().StringVarP(&message, "message", "m", message, "Message to recipient")
 	projTransferCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
+	projTransferCmd.Flags().StringVar(&fromProfile, "from-profile", fromProfile, "(Partial) id of the profile of the project to copy, default is the active one")
+	projTransferCmd.Flags().StringVar(&toProfile, "to-profile", toProfile, "(Partial) id of the profile to copy the project to, instead of transferring to a user")
+	projTransferCmd.Flags().StringVar(&toPID, "to-pid", toPID, "(Partial) id of the project of '--to-profile' to copy into, default is a new one")
+	projTransferCmd.Flags().StringVar(&name, "name", name, "Name of the new project of '--to-profile', default is the name of the copied one")
+	projTransferCmd.Flags().StringVar(&visibility, "visibility", visibility, "Visibility of the new project of '--to-profile': 'public', 'unlisted' or 'private'")
+	projTransferCmd.Flags().IntVar(&transferBatch, "batch", transferBatch, "Number of images downloaded and imported at a time")
+	projTransferCmd.Flags().StringVar(&method, "method", method, "Desired method of upload to '--to-profile': 'direct', 's3', 'gcs' or 'oss'")
+	projTransferCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload to '--to-profile' for method: 's3', 'gcs' or 'oss'")
+	projTransferCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of concurrent downloads and uploads, default is number of cores")
+	projTransferCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display individual image info")
 	errors.Must(projTransferCmd.MarkFlagRequired("id"))
-	errors.Must(projTransferCmd.MarkFlagRequired("email"))
 }
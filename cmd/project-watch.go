@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+var interval = 10
+var watchTimeout int
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch the task state of a project.",
+	Long:  "Poll the most current task of a project until it ends. Exit with non-zero status if it does not end in Done.",
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := gql.SearchProjectID(id, true)
+		if err != nil {
+			fmt.Println("Project could not be found! Error:", err)
+			os.Exit(1)
+		}
+
+		tty := service.IsTerminal(os.Stdout)
+		start := time.Now()
+		var deadline <-chan time.Time
+		if watchTimeout > 0 {
+			deadline = time.After(time.Minute * time.Duration(watchTimeout))
+		}
+		if interval <= 0 {
+			interval = 1
+		}
+		ticker := time.NewTicker(time.Second * time.Duration(interval))
+		defer ticker.Stop()
+
+		var last string
+		for {
+			t, err := gql.ProjectTask(p.ID)
+			if err != nil {
+				if tty {
+					fmt.Println()
+				}
+				logging.Errorln("Error:", err)
+				if err == errors.ErrTaskNotFound {
+					os.Exit(1)
+				}
+			} else {
+				status := taskStatus(t)
+				if tty {
+					fmt.Printf("\r\033[K%s\tElapsed: %s", status, time.Since(start).Round(time.Second))
+				} else if status != last {
+					logging.Infoln(status)
+				}
+				last = status
+
+				switch t.State {
+				case service.TaskDone, service.Failed, service.TaskStopped:
+					if tty {
+						fmt.Println()
+					}
+					fmt.Printf("Task %q ended in state: %q\n", t.TaskType, t.State)
+					if t.State != service.TaskDone {
+						os.Exit(1)
+					}
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-deadline:
+				if tty {
+					fmt.Println()
+				}
+				logging.Errorf("Timeout after %d minute(s)\n", watchTimeout)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// taskStatus gives a one-line status of the task.
+func taskStatus(t *types.Task) string {
+	ret := fmt.Sprintf("Task: %s\tState: %s", t.TaskType, t.State)
+	if t.TotalSteps > 0 {
+		pct := float64(t.Step) / float64(t.TotalSteps) * 100
+		ret += fmt.Sprintf("\tStep: %d/%d (%.0f%%)", t.Step, t.TotalSteps, pct)
+	}
+	if t.Queueing > 0 {
+		ret += fmt.Sprintf("\tQueueing: %d", t.Queueing)
+	}
+	return ret
+}
+
+func init() {
+	projectCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringVarP(&id, "id", "p", id, "Project (partial) id")
+	watchCmd.Flags().IntVarP(&interval, "interval", "i", interval, "Polling interval in seconds")
+	watchCmd.Flags().IntVarP(&watchTimeout, "timeout", "t", watchTimeout, "Timeout in minutes, 0 for no timeout")
+	errors.Must(watchCmd.MarkFlagRequired("id"))
+}
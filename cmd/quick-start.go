@@ -1,13 +1,14 @@
 package cmd
 
 import (
-	"log"
 	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
@@ -24,7 +25,7 @@ var quickCmd = &cobra.Command{
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
@@ -35,8 +36,7 @@ var quickCmd = &cobra.Command{
 			service.CheckFile(inputPath),
 			service.CheckDirOrZip(inputPath),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		// 1. determine project type
@@ -70,10 +70,10 @@ var quickCmd = &cobra.Command{
 			if len(metafiles) > 0 {
 				bucket = ""
 				for i, f := range metafiles {
-					log.Printf("Detected metafile(%d/%d): %q\n", i+1, len(metafiles), f)
-					meta = f
-					importMetaCmd.Run(cmd, args)
+					logging.Infof("Detected metafile(%d/%d): %q\n", i+1, len(metafiles), f)
 				}
+				meta = inputPath
+				importMetaCmd.Run(cmd, args)
 			}
 
 			// 4. start reconstruction task
@@ -96,8 +96,11 @@ func init() {
 	quickCmd.Flags().StringVarP(&inputPath, "input", "i", inputPath, "Directory path or model zip file")
 	quickCmd.Flags().StringVarP(&name, "name", "n", name, "Project name")
 	quickCmd.Flags().StringVarP(&projType, "projectType", "p", projType, "free, pro")
-	quickCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct', 's3' or 'oss'")
+	quickCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct', 's3', 'gcs' or 'oss'")
 	quickCmd.Flags().StringVarP(&modelType, "modelType", "t", modelType, "CAD, PHOTOGRAMMETRY, PTCLOUD")
 	quickCmd.Flags().StringVarP(&skip, "skip", "s", skip, "Regular expression to skip paths")
+	quickCmd.Flags().StringArrayVar(&includes, "include", includes, "Glob of paths to include, e.g. '*.jpg' or 'raw/**', or a regular expression prefixed by 're:'; repeatable")
+	quickCmd.Flags().StringArrayVar(&excludes, "exclude", excludes, "Glob of paths to exclude, taking precedence over --include; repeatable")
+	quickCmd.Flags().BoolVar(&withHidden, "hidden", withHidden, "Include the dotfiles and OS junk files, e.g. Thumbs.db and __MACOSX")
 	quickCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display more info of operation")
 }
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
@@ -23,8 +22,7 @@ var reportCmd = &cobra.Command{
 			service.CheckAPIServer(),
 			service.CheckPID("", id),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		// get pid
@@ -2,22 +2,57 @@ package cmd
 
 import (
 	"fmt"
+	"log"
+	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackytck/alti-cli/config"
 	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/lifecycle"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/render"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/jackytck/alti-cli/web"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
+var traceGQL string
+var logLevel = "info"
+var logJSON bool
+var logFile string
+var netOpt config.Network
+var tableStyle = render.StyleTable
+var tableColumns []string
+var onInterrupt = lifecycle.Graceful
+var requestTag string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "alti-cli",
 	Short: "An Altizure CLI",
 	Long:  `A CLI tool for interacting with Altizure service.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupLog()
+		applyDefaults(cmd)
+		setupNetwork()
+		setupTrace()
+		checkTableStyle()
+		checkOnInterrupt()
+		checkInterface()
+		notifyCommand = cmd.CommandPath()
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		finishNotification()
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//	Run: func(cmd *cobra.Command, args []string) { },
@@ -25,7 +60,18 @@ var rootCmd = &cobra.Command{
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Known errors panicked by commands exit with their exit codes.
 func Execute() {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			if !ok || errors.ExitCode(err) == errors.ExitGeneric {
+				panic(r)
+			}
+			errors.Exit(err)
+		}
+	}()
+	registerCompletions(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -39,6 +85,28 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.altizure/config)")
+	rootCmd.PersistentFlags().BoolVar(&config.NoKeychain, "no-keychain", config.NoKeychain, "store key and token in plaintext config instead of the OS keychain, e.g. for headless machines")
+	rootCmd.PersistentFlags().StringVar(&traceGQL, "trace-gql", "", "trace gql operations, variables, latency and response size to stderr, or to the given file by '--trace-gql=path'")
+	rootCmd.PersistentFlags().Lookup("trace-gql").NoOptDefVal = "-"
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", logLevel, "minimum level of logs: 'debug', 'info', 'warn' or 'error'")
+	rootCmd.PersistentFlags().BoolVar(&logJSON, "log-json", logJSON, "write each log as a json object per line")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", logFile, "append the logs to the given file instead of stderr")
+	rootCmd.PersistentFlags().StringVar(&netOpt.Proxy, "proxy", netOpt.Proxy, "http, https or socks5 proxy of all requests, e.g. socks5://127.0.0.1:1080, default is by HTTPS_PROXY")
+	rootCmd.PersistentFlags().StringVar(&netOpt.CACert, "ca-cert", netOpt.CACert, "path of the pem bundle of extra trusted CAs, e.g. of a self-hosted api server")
+	rootCmd.PersistentFlags().BoolVar(&netOpt.Insecure, "insecure", netOpt.Insecure, "skip TLS verification of all requests, use with care")
+	rootCmd.PersistentFlags().BoolVar(&web.DirectTLS, "direct-tls", web.DirectTLS, "serve the direct upload over https with a self-signed cert, the api server must accept it")
+	rootCmd.PersistentFlags().StringVar(&web.DirectInterface, "interface", web.DirectInterface, "serve the direct upload only over the addresses of the network interface, e.g. eth1")
+	rootCmd.PersistentFlags().BoolVar(&web.PreferIPv6, "prefer-ipv6", web.PreferIPv6, "prefer the IPv6 addresses over the IPv4 ones for direct upload")
+	rootCmd.PersistentFlags().IntVar(&gql.Retries, "retries", gql.Retries, "number of retries of a gql request on network or server error")
+	rootCmd.PersistentFlags().DurationVar(&gql.RetryWait, "retry-wait", gql.RetryWait, "initial wait before retrying a gql request, doubled on each retry")
+	rootCmd.PersistentFlags().StringVar(&requestTag, "request-tag", requestTag, "tag of all requests to the api server, e.g. the team or pipeline for attributing and rate-limiting, default is by ALTI_REQUEST_TAG")
+	rootCmd.PersistentFlags().DurationVar(&netOpt.Timeout, "api-timeout", netOpt.Timeout, "timeout of each gql request and of waiting the response of each cloud request, e.g. 30s, zero means no timeout")
+	rootCmd.PersistentFlags().StringVar(&tableStyle, "style", tableStyle, "style of the tables: 'table', 'plain', 'markdown' or 'csv'")
+	rootCmd.PersistentFlags().StringSliceVar(&tableColumns, "columns", tableColumns, "columns of the tables to print, by their headers, e.g. ID,Name,TaskState")
+	rootCmd.PersistentFlags().StringVar(&onInterrupt, "on-interrupt", onInterrupt, "on ctrl+c or SIGTERM, 'graceful' stops the long-running operations with partial results and aborts on another one, 'abort' cleans up and quits immediately")
+	rootCmd.PersistentFlags().StringVar(&notifyURL, "notify-url", notifyURL, "post a json summary to the url when the long-running operations, e.g. imports, downloads and watch, finish, fail or are interrupted")
+	rootCmd.PersistentFlags().BoolVar(&notifyDesktop, "notify-desktop", notifyDesktop, "show a desktop notification when the long-running operations finish, fail or are interrupted")
+	rootCmd.PersistentFlags().DurationVar(&deadline, "deadline", deadline, "stop the long-running operations, e.g. uploads, state checking and downloads, after the duration with partial results, e.g. 2h")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -81,3 +149,133 @@ func initConfig() {
 	err := viper.ReadInConfig()
 	errors.Must(err)
 }
+
+// applyDefaults sets the flags of cmd that are not given to the defaults
+// of the active profile.
+func applyDefaults(cmd *cobra.Command) {
+	defaults := config.Load().GetDefaults()
+	for k, v := range defaults {
+		f := cmd.Flags().Lookup(k)
+		if f == nil || f.Changed {
+			continue
+		}
+		if err := f.Value.Set(v); err != nil {
+			logging.Warnf("Invalid default %s = %q: %v\n", k, v, err)
+		}
+	}
+}
+
+// setupTrace traces the gql requests to stderr or the file of '--trace-gql'.
+func setupTrace() {
+	switch traceGQL {
+	case "":
+	case "-":
+		gql.Trace = os.Stderr
+	default:
+		f, err := os.OpenFile(traceGQL, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		errors.Must(err)
+		gql.Trace = f
+	}
+}
+
+// setupLog sets the level, format and output of logs by the log flags.
+func setupLog() {
+	l, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		fmt.Printf("Unknown log level: %q, valid levels are: %q\n", logLevel, strings.Join(logging.Levels, ", "))
+		errors.Exit(err)
+	}
+	logging.SetLevel(l)
+	logging.SetJSON(logJSON)
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		errors.Must(err)
+		log.SetOutput(f)
+	}
+}
+
+// checkTableStyle exits if the style of '--style' is not supported.
+func checkTableStyle() {
+	tableStyle = strings.ToLower(tableStyle)
+	if _, ok := text.Contains(render.Styles, tableStyle); !ok {
+		logging.Errorf("Unknown table style: %q, valid styles are: %q\n", tableStyle, strings.Join(render.Styles, ", "))
+		errors.Exit(errors.ErrInvalidInput)
+	}
+}
+
+// checkOnInterrupt exits if the mode of '--on-interrupt' is not supported.
+func checkOnInterrupt() {
+	onInterrupt = strings.ToLower(onInterrupt)
+	if _, ok := text.Contains(lifecycle.Modes, onInterrupt); !ok {
+		logging.Errorf("Unknown --on-interrupt: %q, valid modes are: %q\n", onInterrupt, strings.Join(lifecycle.Modes, ", "))
+		errors.Exit(errors.ErrInvalidInput)
+	}
+}
+
+// checkInterface exits if the network interface of '--interface' is not found.
+func checkInterface() {
+	if web.DirectInterface == "" {
+		return
+	}
+	if _, err := web.LocalAddrs(web.DirectInterface); err != nil {
+		logging.Errorf("Unknown --interface: %v\n", err)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+}
+
+// setupNetwork applies the proxy, TLS and timeout flags, which fall back to the
+// env vars if they are neither given nor set as profile defaults.
+func setupNetwork() {
+	if netOpt.Proxy == "" {
+		netOpt.Proxy = os.Getenv(config.AltiProxy)
+	}
+	if netOpt.CACert == "" {
+		netOpt.CACert = os.Getenv(config.AltiCACert)
+	}
+	if !netOpt.Insecure {
+		netOpt.Insecure, _ = strconv.ParseBool(os.Getenv(config.AltiInsecure))
+	}
+	if netOpt.Timeout == 0 {
+		if v := os.Getenv(config.AltiAPITimeout); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				logging.Errorf("Invalid %s: %q\n", config.AltiAPITimeout, v)
+				errors.Exit(errors.ErrInvalidInput)
+			}
+			netOpt.Timeout = d
+		}
+	}
+	netOpt.Attribution = attribution()
+	if err := config.SetNetwork(netOpt); err != nil {
+		errors.Exit(err)
+	}
+	gql.Timeout = netOpt.Timeout
+	if netOpt.Insecure {
+		logging.Warnln("TLS verification is skipped")
+	}
+}
+
+// attribution gives the user agent of this version, the client id and the tag
+// of '--request-tag' or ALTI_REQUEST_TAG, sent with the requests.
+func attribution() config.Attribution {
+	if requestTag == "" {
+		requestTag = os.Getenv(config.AltiRequestTag)
+	}
+	if requestTag != "" && !config.ValidRequestTag(requestTag) {
+		logging.Errorf("Invalid request tag: %q, expect 1 to 128 letters, digits or any of '._:/@+=-'\n", requestTag)
+		errors.Exit(errors.ErrInvalidInput)
+	}
+	a := config.Attribution{
+		UserAgent:  fmt.Sprintf("alti-cli/%s (%s/%s)", Version, runtime.GOOS, runtime.GOARCH),
+		RequestTag: requestTag,
+	}
+	if u, err := url.Parse(config.Load().GetActive().Endpoint); err == nil {
+		a.Host = u.Hostname()
+	}
+	id, err := config.ClientID()
+	if err != nil {
+		logging.Debugln("Client id is not sent:", err)
+	}
+	a.ClientID = id
+	return a
+}
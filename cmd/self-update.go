@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/text"
+	"github.com/jackytck/alti-cli/update"
+	"github.com/spf13/cobra"
+)
+
+// ReleasePublicKey is the base64 ed25519 public key verifying the signed
+// checksums of the releases, set by ldflags on release.
+var ReleasePublicKey string
+
+var updateChannel = update.ChannelStable
+var checkOnly bool
+var forceUpdate bool
+var skipSignature bool
+
+// selfUpdateCmd represents the self-update command
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update alti-cli to the latest release",
+	Long: `Check the latest GitHub release of the channel, verify its signed checksums,
+download the binary of this OS and arch, and replace the running one atomically.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		updateChannel = strings.ToLower(updateChannel)
+		if _, ok := text.Contains(update.Channels, updateChannel); !ok {
+			logging.Errorf("Unknown channel: %q, valid channels are: %q\n", updateChannel, strings.Join(update.Channels, ", "))
+			errors.Exit(errors.ErrInvalidInput)
+		}
+
+		logging.Infof("Checking the latest %s release...\n", updateChannel)
+		r, err := update.Latest(updateChannel)
+		if err != nil {
+			errors.Exit(err)
+		}
+		if !update.Newer(r.Tag, Version) && !forceUpdate {
+			logging.Infof("Already up to date: %s (latest: %s)\n", Version, r.Tag)
+			return
+		}
+		a := r.CurrentArchive()
+		if a == nil {
+			logging.Errorf("No binary of %s/%s in release %s\n", runtime.GOOS, runtime.GOARCH, r.Tag)
+			errors.Exit(errors.ErrReleaseNotFound)
+		}
+		logging.Infof("New release: %s -> %s (%s, %s)\n", Version, r.Tag, a.Name, humanize.IBytes(uint64(a.Size)))
+		if checkOnly {
+			return
+		}
+
+		// the checksums are verified by the signature before trusting them
+		sums := downloadAsset(r, update.ChecksumsName)
+		if skipSignature {
+			logging.Warnln("Signature is not verified, only the checksum is.")
+		} else {
+			if ReleasePublicKey == "" {
+				logging.Errorln("This build has no release public key. Install a release build or add '--skip-signature'.")
+				errors.Exit(errors.ErrReleaseKeyInvalid)
+			}
+			sig := downloadAsset(r, update.SignatureName)
+			if err = update.VerifySignature(sums, sig, ReleasePublicKey); err != nil {
+				errors.Exit(err)
+			}
+			logging.Infoln("Signature is verified.")
+		}
+
+		fmt.Printf("Update alti-cli from %s to %s or not? (Y/N): ", Version, r.Tag)
+		if assumeYes {
+			fmt.Println("Yes")
+		} else {
+			var ans string
+			fmt.Scanln(&ans)
+			ans = strings.ToUpper(ans)
+			if ans != "Y" && ans != service.Yes {
+				logging.Infoln("Cancelled.")
+				return
+			}
+		}
+
+		archive, err := update.Download(a.URL)
+		if err != nil {
+			errors.Exit(err)
+		}
+		if err = update.VerifyChecksum(sums, a.Name, archive); err != nil {
+			errors.Exit(err)
+		}
+		bin, err := update.ExtractBinary(a.Name, archive, update.BinaryName(runtime.GOOS))
+		if err != nil {
+			errors.Exit(err)
+		}
+		exe, err := os.Executable()
+		errors.Must(err)
+		checkDiskSpace(filepath.Dir(exe), int64(len(bin)))
+		if err = update.Replace(exe, bin); err != nil {
+			logging.Errorf("Could not replace %q: %v\n", exe, err)
+			errors.Exit(err)
+		}
+		logging.Infof("Updated to %s\n", r.Tag)
+	},
+}
+
+// downloadAsset downloads the asset of name of release r, or exits if not found.
+func downloadAsset(r *update.Release, name string) []byte {
+	a := r.Asset(name)
+	if a == nil {
+		logging.Errorf("No %s in release %s\n", name, r.Tag)
+		errors.Exit(errors.ErrReleaseUnverified)
+	}
+	b, err := update.Download(a.URL)
+	if err != nil {
+		errors.Exit(err)
+	}
+	return b
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+	selfUpdateCmd.Flags().StringVar(&updateChannel, "channel", updateChannel, "Release channel: 'stable' or 'beta' (including pre-releases)")
+	selfUpdateCmd.Flags().BoolVar(&checkOnly, "check", checkOnly, "Only check if there is a newer release")
+	selfUpdateCmd.Flags().BoolVar(&forceUpdate, "force", forceUpdate, "Install the latest release even if it is not newer")
+	selfUpdateCmd.Flags().BoolVar(&skipSignature, "skip-signature", skipSignature, "Only verify the checksum, e.g. for a build without the release public key")
+	selfUpdateCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
+}
@@ -1,12 +1,12 @@
 package cmd
 
 import (
-	"log"
 	"time"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
@@ -23,7 +23,7 @@ var setProfileFaceCmd = &cobra.Command{
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
@@ -34,22 +34,21 @@ var setProfileFaceCmd = &cobra.Command{
 			service.CheckIsLogin(),
 			service.CheckFile(face),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		// check image
 		isImg, err := file.IsImageFile(face)
 		errors.Must(err)
 		if !isImg {
-			log.Printf("%q is not an image!", face)
+			logging.Errorf("%q is not an image!", face)
 			return
 		}
 		bytes, err := file.Filesize(face)
 		errors.Must(err)
 		size := file.BytesToMB(bytes)
 		if size > 20 {
-			log.Printf("%q (with %.2fMB) is too large, max is 20MB!", face, size)
+			logging.Errorf("%q (with %.2fMB) is too large, max is 20MB!", face, size)
 			return
 		}
 
@@ -62,11 +61,11 @@ var setProfileFaceCmd = &cobra.Command{
 		res, err := gql.SetProfileFace(imgStr)
 		errors.Must(err)
 		if res != "Success" {
-			log.Println("Unknown error! Please try again later.")
+			logging.Errorln("Unknown error! Please try again later.")
 			return
 		}
 
-		log.Println("Profile image is successfully set!")
+		logging.Infoln("Profile image is successfully set!")
 	},
 }
 
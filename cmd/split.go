@@ -2,13 +2,13 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/service"
 	"github.com/spf13/cobra"
 )
@@ -26,7 +26,7 @@ var splitCmd = &cobra.Command{
 		defer func() {
 			if verbose {
 				elapsed := time.Since(start)
-				log.Println("Took", elapsed)
+				logging.Infoln("Took", elapsed)
 			}
 		}()
 
@@ -42,8 +42,7 @@ var splitCmd = &cobra.Command{
 			service.CheckFile(model),
 			service.CheckDir(outDir),
 		); err != nil {
-			log.Println(err)
-			return
+			errors.Exit(err)
 		}
 
 		// warn if too many parts
@@ -56,7 +55,7 @@ var splitCmd = &cobra.Command{
 			fmt.Scanln(&ans)
 			ans = strings.ToUpper(ans)
 			if ans != "Y" && ans != service.Yes {
-				log.Println("Cancelled.")
+				logging.Infoln("Cancelled.")
 				return
 			}
 		}
@@ -69,7 +68,7 @@ var splitCmd = &cobra.Command{
 		if len(parts) > 1 {
 			p += "s"
 		}
-		log.Printf("Written %d %s.\n", len(parts), p)
+		logging.Infof("Written %d %s.\n", len(parts), p)
 	},
 }
 
@@ -0,0 +1,383 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/jackytck/alti-cli/web"
+	"github.com/spf13/cobra"
+)
+
+var prune bool
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync images from a directory into a project",
+	Long:  "Compare local images with the project, upload only the new or changed ones, and optionally prune the remote images missing locally.",
+	Run: func(cmd *cobra.Command, args []string) {
+		start := time.Now()
+		defer func() {
+			if verbose {
+				elapsed := time.Since(start)
+				logging.Infoln("Took", elapsed)
+			}
+		}()
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+
+		// preview the walked paths only
+		if listOnly {
+			if err := service.Check(nil, service.CheckDir(dir)); err != nil {
+				errors.Exit(err)
+			}
+			listPaths(file.WalkFilesBy(ctx, dir, pathFilter()))
+			return
+		}
+
+		// pre-checks general
+		meth, mOK := service.SuggestUploadMethod(method, "image")
+		if err := service.Check(
+			nil,
+			service.CheckAPIServer(),
+			service.CheckUploadMethod("image", meth, ip, port, mOK),
+			service.CheckPID("image", id),
+			service.CheckDir(dir),
+		); err != nil {
+			errors.Exit(err)
+		}
+		checkWaitStrategy()
+		checkAdaptive(meth)
+
+		// get pid
+		p, _ := gql.SearchProjectID(id, true)
+
+		// setup direct upload server
+		var baseURL string
+		if meth == service.DirectUploadMethod {
+			bu, done, err := web.StartLocalServer(dir, ip, port, false)
+			errors.Must(err)
+			defer done()
+			baseURL = bu
+		}
+
+		// set bucket
+		suggestImageBucket(meth)
+
+		// digest local images
+		logging.Infof("Checking %s...\n", dir)
+		paths, errc := file.WalkFilesBy(ctx, dir, pathFilter())
+		result := make(chan file.ImageDigest)
+		digester := file.ImageDigester{
+			Root:   dir,
+			PID:    p.ID,
+			Ctx:    ctx,
+			Paths:  paths,
+			Result: result,
+		}
+		threads := digester.Run(thread)
+		logging.Debugf("Working in %d thread(s)...", threads)
+
+		var local []file.ImageDigest
+		for r := range result {
+			if r.Error != nil {
+				logging.Warnf("Invalid image: %q, Reason: %v", r.Path, r.Error)
+				continue
+			}
+			local = append(local, r)
+		}
+
+		// check whether the Walk failed
+		if err := <-errc; err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			panic(err)
+		}
+
+		// list remote images
+		logging.Infoln("Listing project images...")
+		remote, err := listRemoteImages(p.ID)
+		if msg := errors.MustGQL(err, ""); msg != "" {
+			logging.Errorln(msg)
+			return
+		}
+
+		diff := file.DiffImages(local, remote)
+		toUpload := append(diff.New, diff.Changed...)
+		toRemove := append(diff.Missing, diff.Replaced...)
+		if verbose {
+			for _, d := range diff.New {
+				logging.Infof("New: %q\n", d.Path)
+			}
+			for _, d := range diff.Changed {
+				logging.Infof("Changed: %q\n", d.Path)
+			}
+			for _, r := range diff.Missing {
+				logging.Infof("Missing locally: %q\n", r.Name)
+			}
+		}
+		logging.Infof("Unchanged: %d, New: %d, Changed: %d, Missing locally: %d\n",
+			len(diff.Unchanged), len(diff.New), len(diff.Changed), len(diff.Missing))
+
+		if !prune {
+			toRemove = nil
+			if len(diff.Missing) > 0 {
+				logging.Infoln("Remove the images missing locally by adding '--prune'")
+			}
+		}
+		if len(toUpload) == 0 && len(toRemove) == 0 {
+			logging.Infoln("Already in sync!")
+			return
+		}
+
+		// ask user to proceed or not
+		var totalGP float64
+		var totalByte datasize.ByteSize
+		for _, d := range toUpload {
+			totalGP += d.GP
+			totalByte += datasize.ByteSize(d.Filesize)
+		}
+		service.Check(nil, service.CheckGPQuota(totalGP))
+		fmt.Printf("Upload %d images (%.2f GP, %s) and remove %d images.\n", len(toUpload), totalGP, totalByte.HumanReadable(), len(toRemove))
+		fmt.Print("Continue to sync or not? (Y/N): ")
+		if assumeYes {
+			fmt.Println("Yes")
+		} else {
+			var ans string
+			fmt.Scanln(&ans)
+			ans = strings.ToUpper(ans)
+			if ans != "Y" && ans != service.Yes {
+				logging.Infoln("Cancelled.")
+				return
+			}
+		}
+
+		if len(toUpload) > 0 {
+			mf := db.NewManifest(p.ID, meth, bucket)
+			uploadDigests(ctx, p.ID, meth, baseURL, toUpload, mf)
+			if ctx.Err() != nil {
+				return
+			}
+			saveManifest(mf, manifestFile(p.ID))
+		}
+
+		// remove after upload, so that the project is never left with less images
+		removeImages(p.ID, toRemove)
+		logging.Infof("To inspect more, type: 'alti-cli myproj inspect -p %v'\n", id)
+	},
+}
+
+// listRemoteImages pages through all of the images of project pid.
+func listRemoteImages(pid string) ([]types.ProjectImage, error) {
+	var ret []types.ProjectImage
+	after := ""
+	for {
+		imgs, page, _, err := gql.AllProjectImages(context.Background(), pid, 50, 0, "", after)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, imgs...)
+		if !page.HasNextPage {
+			break
+		}
+		after = page.EndCursor
+	}
+	return ret, nil
+}
+
+// uploadDigests registers, uploads and checks the state of the given images
+// via a temporary local db, until ctx is canceled.
+// The final states are recorded in manifest m.
+func uploadDigests(ctx context.Context, pid, meth, baseURL string, digests []file.ImageDigest, m *db.Manifest) {
+	dbPath, err := db.OpenPath()
+	if err != nil {
+		panic(err)
+	}
+	localDB, err := db.OpenDB(dbPath)
+	if err != nil {
+		panic(err)
+	}
+	err = localDB.Init(&db.Image{})
+	if err != nil {
+		panic(err)
+	}
+	closeDB := func() {
+		err2 := localDB.Close()
+		if err2 != nil {
+			panic(err2)
+		}
+		err2 = os.Remove(dbPath)
+		if err2 != nil {
+			panic(err2)
+		}
+	}
+	defer closeDB()
+
+	for _, r := range digests {
+		img := db.Image{
+			PID:       pid,
+			Filename:  r.Filename,
+			Filetype:  types.ConvertToImageType(r.Filetype),
+			URL:       r.URL,
+			LocalPath: r.Path,
+			Hash:      r.SHA1,
+			Width:     r.Width,
+			Height:    r.Height,
+			GP:        r.GP,
+			Meta:      r.Exif.ImageMeta(),
+		}
+		err = localDB.Save(&img)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// read from local db, register and upload
+	var totalByte int64
+	for _, r := range digests {
+		totalByte += r.Filesize
+	}
+	imgc, errc := db.AllImage(localDB)
+	ruRes := make(chan db.Image)
+	pr := newProgressReporter(len(digests), totalByte)
+	ruDigester := cloud.ImageRegUploader{
+		Method:   meth,
+		Bucket:   bucket,
+		Buckets:  imageBuckets,
+		BaseURL:  baseURL,
+		Images:   imgc,
+		Ctx:      ctx,
+		Result:   ruRes,
+		Verbose:  verbose,
+		Progress: pr,
+		Throttle: uploadThrottle(meth),
+		Schedule: uploadSchedule,
+	}
+	if meth == "oss" {
+		err2 := ruDigester.WithOSSUploader(pid)
+		if err2 != nil {
+			panic(err2)
+		}
+	}
+	ruDigester.Run(thread)
+
+	for img := range ruRes {
+		err = localDB.Save(&img)
+		if verbose {
+			if img.Error != "" {
+				logging.Warnf("Registration failed: %q\n", img.Error)
+			} else {
+				logging.Infof("Registered %q\n", img.Filename)
+			}
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+	pr.Close()
+	if err = <-errc; err != nil {
+		panic(err)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	saveThrottle(meth, ruDigester.Throttle)
+
+	// check for image state: Ready / Invalid / Client timeout
+	if waitStrategy == cloud.WaitNone {
+		logging.Infoln("Skipped checking image states, verify them later by 'alti-cli verify'")
+	} else {
+		logging.Infoln("Checking image states....")
+	}
+	imgc, errc = db.UnverifiedImage(localDB)
+	checkerRes := make(chan db.Image)
+	checker := cloud.ImageStateChecker{
+		Images:   imgc,
+		Ctx:      ctx,
+		Result:   checkerRes,
+		Timeout:  stateTimeout(),
+		Strategy: waitStrategy,
+		Interval: time.Second * time.Duration(pollInterval),
+		Verify:   verifyUpload,
+	}
+	checker.Run(thread)
+
+	var okCnt, errCnt int
+	for img := range checkerRes {
+		err = localDB.Save(&img)
+		m.AddImage(img)
+		warnChecksumMismatch(img)
+		if sr, ok := pr.(service.StateReporter); ok {
+			sr.State(img.LocalPath, img.State, img.Error)
+		}
+		if img.Error != "" || img.State == "Invalid" {
+			errCnt++
+			if verbose {
+				logging.Warnf("Image upload error: %q\n", img.Error)
+			}
+		} else {
+			okCnt++
+			if verbose {
+				logging.Infof("Image %q is %q\n", img.Filename, img.State)
+			}
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+	if err = <-errc; err != nil {
+		panic(err)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	logging.Infof("%d out of %d images are uploaded and ready.", okCnt, len(digests))
+	if errCnt > 0 {
+		logging.Warnf("%d images failed. Please try again later.", errCnt)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringVarP(&id, "id", "p", id, "Project id")
+	syncCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory path")
+	syncCmd.Flags().StringVarP(&skip, "skip", "s", skip, "Regular expression to skip paths")
+	syncCmd.Flags().StringArrayVar(&includes, "include", includes, "Glob of paths to include, e.g. '*.jpg' or 'raw/**', or a regular expression prefixed by 're:'; repeatable")
+	syncCmd.Flags().StringArrayVar(&excludes, "exclude", excludes, "Glob of paths to exclude, taking precedence over --include; repeatable")
+	syncCmd.Flags().StringVar(&excludeFrom, "exclude-from", excludeFrom, "File of the paths relative to '--dir' to exclude, one per line, e.g. written by 'check image --dupes skip'")
+	syncCmd.Flags().BoolVar(&withHidden, "hidden", withHidden, "Include the dotfiles and OS junk files, e.g. Thumbs.db and __MACOSX")
+	syncCmd.Flags().BoolVar(&listOnly, "list-only", listOnly, "List the paths that would be processed, without processing them")
+	syncCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct', 's3', 'gcs' or 'oss'")
+	syncCmd.Flags().IntVarP(&timeout, "timeout", "t", timeout, "Timeout of checking upload state in seconds")
+	syncCmd.Flags().StringVar(&waitStrategy, "wait-strategy", waitStrategy, "Strategy of waiting for the upload states: 'fixed', 'backoff' or 'none' (not waiting)")
+	syncCmd.Flags().IntVar(&pollInterval, "poll-interval", pollInterval, "Interval of polling the upload states in seconds, the initial one of 'backoff'")
+	syncCmd.Flags().BoolVar(&verifyUpload, "verify", verifyUpload, "Compare the checksum computed by the server of each uploaded image with the local one")
+	syncCmd.Flags().StringVar(&ip, "ip", ip, "IP address of ad-hoc local server for direct upload.")
+	syncCmd.Flags().StringVar(&port, "port", port, "Port of ad-hoc local server for direct upload.")
+	syncCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload for method: 's3', 'gcs' or 'oss'")
+	syncCmd.Flags().BoolVar(&autoBucket, "auto-bucket", autoBucket, "Choose the bucket of the lowest measured upload latency if bucket is not given, cached for 24 hours")
+	syncCmd.Flags().StringVar(&spreadBuckets, "buckets", spreadBuckets, "Spread the uploads across the comma separated buckets, each optionally weighted, e.g. 'a,b:2'")
+	syncCmd.Flags().StringVar(&bucketStrategy, "bucket-strategy", bucketStrategy, "Spread the uploads across '--buckets', or all of the buckets if not given: 'round-robin', 'random' or 'fastest' (weighted by latency)")
+	syncCmd.Flags().StringVar(&manifestPath, "manifest", manifestPath, "Path of the json manifest of uploaded files, default is under ~/.altizure/manifests")
+	syncCmd.Flags().BoolVar(&prune, "prune", prune, "Remove the project images that are missing or changed locally")
+	syncCmd.Flags().BoolVarP(&assumeYes, "assumeyes", "y", assumeYes, "Assume yes; assume that the answer to any question which would be asked is yes")
+	syncCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display individual image info")
+	syncCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads to process, default is number of cores x 4")
+	syncCmd.Flags().BoolVar(&adaptive, "adaptive", adaptive, "Adapt the number of concurrent uploads to the measured throughput, starting from the one learned in the last run")
+	errors.Must(syncCmd.MarkFlagRequired("id"))
+	errors.Must(syncCmd.MarkFlagRequired("dir"))
+}
@@ -3,12 +3,10 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
 	"github.com/jackytck/alti-cli/supergql"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
@@ -47,7 +45,7 @@ var tokenCmd = &cobra.Command{
 				token,
 			}
 
-			table := tablewriter.NewWriter(os.Stdout)
+			table := newTable()
 			table.SetHeader(header)
 			table.Append(row)
 			table.Render()
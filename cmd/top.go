@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/spf13/cobra"
+)
+
+// topPageSize is the number of projects queried at a time by top.
+const topPageSize = 50
+
+var topLimit = 200
+var topOnce bool
+
+// topCmd represents the top command
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Live overview of the running tasks, balance and quota",
+	Long: `Periodically refresh a view of the running tasks of my projects, with their queue positions, progress and ETA, plus the coin balance and the quota, like 'kubectl top'.
+The ETA is estimated by the average time of the finished steps. Press ctrl+c to quit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := service.Check(
+			nil,
+			service.CheckAPIServerLite(),
+			service.CheckIsLogin(),
+		); err != nil {
+			errors.Exit(err)
+		}
+		if interval <= 0 {
+			interval = 1
+		}
+		tty := service.IsTerminal(os.Stdout)
+		ticker := time.NewTicker(time.Second * time.Duration(interval))
+		defer ticker.Stop()
+
+		for {
+			_, user, userErr := gql.MySelf()
+			tasks, taskErr := runningTasks(topLimit)
+			now := time.Now()
+			if tty && !topOnce {
+				// clear the screen
+				fmt.Print("\033[H\033[2J")
+			}
+			fmt.Printf("alti-cli top - %s, every %ds\n", now.Format("15:04:05"), interval)
+			if userErr != nil {
+				fmt.Println("Account could not be queried:", userErr)
+			} else {
+				printTopAccount(user, len(tasks), now)
+			}
+			if taskErr != nil {
+				fmt.Println("Tasks could not be queried:", taskErr)
+			} else {
+				printTopTasks(tasks, now)
+			}
+			if topOnce {
+				return
+			}
+			if !tty {
+				fmt.Println()
+			}
+			<-ticker.C
+		}
+	},
+}
+
+// runningTasks gives the tasks that have not ended of my most recent limit
+// projects, or of all projects if limit is not positive, the processing ones
+// first, then by the queue positions.
+func runningTasks(limit int) ([]types.ProjectTask, error) {
+	var ret []types.ProjectTask
+	after := ""
+	for n := 0; limit <= 0 || n < limit; n += topPageSize {
+		first := topPageSize
+		if limit > 0 && limit-n < first {
+			first = limit - n
+		}
+		tasks, page, err := gql.MyProjectTasks(first, after)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tasks {
+			if !t.Task.Ended() {
+				ret = append(ret, t)
+			}
+		}
+		if !page.HasNextPage {
+			break
+		}
+		after = page.EndCursor
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		a, b := ret[i].Task, ret[j].Task
+		if (a.State == service.TaskProcessing) != (b.State == service.TaskProcessing) {
+			return a.State == service.TaskProcessing
+		}
+		return a.Queueing < b.Queueing
+	})
+	return ret, nil
+}
+
+// printTopAccount prints the balance and the quota of user, with the number
+// of running tasks against the concurrent task limit.
+func printTopAccount(u *types.User, running int, now time.Time) {
+	q := types.NewQuota(u)
+	tasks := strconv.Itoa(running)
+	if q.ConcurrentTask > 0 {
+		tasks += "/" + strconv.Itoa(q.ConcurrentTask)
+	}
+	fmt.Printf("User: %s\tPlan: %s (%s)\tCoins: %.2f\tGP Quota: %.2f\n", u.NameOrEmail(), q.Plan, q.State, u.Balance, q.GPQuota)
+	storage := fmt.Sprintf("%s / %s", humanize.IBytes(uint64(q.StorageUsage*1048576)), humanize.IBytes(uint64(q.Storage*1048576)))
+	expiry := "-"
+	if !q.Expiry.IsZero() {
+		expiry = fmt.Sprintf("%s (%d days left)", q.Expiry.Format("2006-01-02"), q.DaysLeft(now))
+	}
+	fmt.Printf("Storage: %s\tTasks: %s\tExpiry: %s\n", storage, tasks, expiry)
+}
+
+// printTopTasks prints a table of the running tasks.
+func printTopTasks(tasks []types.ProjectTask, now time.Time) {
+	if len(tasks) == 0 {
+		fmt.Println("No running task.")
+		return
+	}
+	table := newTable()
+	table.SetHeader([]string{"Project ID", "Name", "Task", "State", "Queue", "Progress", "Elapsed", "ETA"})
+	for _, pt := range tasks {
+		t := pt.Task
+		queue, progress, elapsed, eta := "-", "-", "-", "-"
+		if t.Queueing > 0 {
+			queue = strconv.Itoa(t.Queueing)
+		}
+		if t.TotalSteps > 0 {
+			progress = fmt.Sprintf("%d/%d (%.0f%%)", t.Step, t.TotalSteps, t.Progress()*100)
+		}
+		if !t.StartDate.IsZero() {
+			elapsed = now.Sub(t.StartDate).Round(time.Second).String()
+		}
+		if d := t.ETA(now); d > 0 {
+			eta = d.Round(time.Second).String()
+		}
+		table.Append([]string{pt.PID, pt.Name, t.TaskType, t.State, queue, progress, elapsed, eta})
+	}
+	table.Render()
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+	topCmd.Flags().IntVarP(&interval, "interval", "i", interval, "Refresh interval in seconds")
+	topCmd.Flags().IntVarP(&topLimit, "limit", "l", topLimit, "Number of the most recent projects to scan for running tasks, 0 for all")
+	topCmd.Flags().BoolVar(&topOnce, "once", topOnce, "Print the overview once and exit, e.g. for scripts")
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/file"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/jackytck/alti-cli/ui"
+	"github.com/jackytck/alti-cli/web"
+	tb "github.com/nsf/termbox-go"
+	"github.com/spf13/cobra"
+)
+
+// uiProjectCount is the number of latest projects listed in the dashboard.
+const uiProjectCount = 50
+
+// uiCmd represents the ui command
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Interactive dashboard of projects and uploads",
+	Long: `Launch a terminal dashboard of my projects. Select a project by up/down and press u to upload the images of '-d'.
+The active upload workers with the progress of each file, the image states and the errors are shown in panes.
+Refresh the projects by r. Exit by q or Esc.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// pre-checks
+		meth, mOK := service.SuggestUploadMethod(method, "image")
+		checks := []service.CheckFn{apiServerCheck(dir == "")}
+		if dir != "" {
+			checks = append(checks,
+				service.CheckUploadMethod("image", meth, ip, port, mOK),
+				service.CheckDir(dir),
+			)
+		}
+		if err := service.Check(nil, checks...); err != nil {
+			errors.Exit(err)
+		}
+		checkChecksumAlgo()
+		checkWaitStrategy()
+
+		_, user, err := gql.MySelf()
+		if msg := errors.MustGQL(err, ""); msg != "" {
+			fmt.Println(msg)
+			return
+		}
+
+		// setup direct upload server and bucket before the dashboard takes the terminal
+		var baseURL string
+		if dir != "" {
+			if meth == service.DirectUploadMethod {
+				bu, done, err := web.StartLocalServer(dir, ip, port, false)
+				errors.Must(err)
+				defer done()
+				baseURL = bu
+			}
+			b, err := service.SuggestBucket(meth, bucket, "image", autoBucket)
+			if err != nil {
+				errors.Exit(err)
+			}
+			bucket = b
+		}
+
+		projs, err := uiProjects()
+		if msg := errors.MustGQL(err, ""); msg != "" {
+			fmt.Println(msg)
+			return
+		}
+
+		d, err := ui.New(fmt.Sprintf("alti-cli ui - %s", user.NameOrEmail()))
+		errors.Must(err)
+		logOut := log.Writer()
+		log.SetOutput(d)
+		newProgressReporter = func(count int, total int64) service.ProgressReporter {
+			d.Begin(count, total)
+			return d
+		}
+		d.SetProjects(projs)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+			d.Quit()
+			log.SetOutput(logOut)
+		}()
+
+		for ev := range d.Events() {
+			if ev.Type != tb.EventKey {
+				continue
+			}
+			switch {
+			case ev.Ch == 'q' || ev.Key == tb.KeyEsc || ev.Key == tb.KeyCtrlC:
+				return
+			case ev.Key == tb.KeyArrowUp || ev.Ch == 'k':
+				d.Move(-1)
+			case ev.Key == tb.KeyArrowDown || ev.Ch == 'j':
+				d.Move(1)
+			case ev.Ch == 'r':
+				go func() {
+					ps, err := uiProjects()
+					if err != nil {
+						logging.Errorln("Could not list projects:", err)
+						return
+					}
+					d.SetProjects(ps)
+				}()
+			case ev.Ch == 'u':
+				p := d.Selected()
+				switch {
+				case dir == "":
+					logging.Warnln("No directory to upload, restart with 'alti-cli ui -d DIR'")
+				case p == nil || d.Uploading():
+				case p.IsImported:
+					logging.Warnf("Project %q is not a reconstruction project", p.Name)
+				default:
+					d.Begin(0, 0)
+					wg.Add(1)
+					go func(pid string) {
+						defer wg.Done()
+						defer d.End()
+						uiUpload(ctx, pid, meth, baseURL)
+					}(p.ID)
+				}
+			}
+		}
+	},
+}
+
+// uiProjects lists the latest projects of the current user.
+func uiProjects() ([]types.Project, error) {
+	projs, _, _, err := gql.MyProjects(uiProjectCount, 0, "", "", search)
+	return projs, err
+}
+
+// uiUpload uploads the images of dir that are not yet in project pid by the
+// same pipeline of 'import image', until ctx is canceled.
+func uiUpload(ctx context.Context, pid, meth, baseURL string) {
+	logging.Infof("Checking %s...\n", dir)
+	pc, errc := file.WalkFilesBy(ctx, dir, pathFilter())
+	var paths []string
+	for p := range pc {
+		paths = append(paths, p)
+	}
+	if err := <-errc; err != nil {
+		if ctx.Err() == nil {
+			logging.Errorln("Could not walk the directory:", err)
+		}
+		return
+	}
+
+	mf := db.NewManifest(pid, meth, bucket)
+	if importPaths(ctx, pid, meth, baseURL, paths, make(map[string]bool), mf) && ctx.Err() == nil {
+		saveManifest(mf, manifestFile(pid))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+	uiCmd.Flags().StringVarP(&dir, "dir", "d", dir, "Directory of images to upload")
+	uiCmd.Flags().StringVarP(&skip, "skip", "s", skip, "Regular expression to skip paths")
+	uiCmd.Flags().StringArrayVar(&includes, "include", includes, "Glob of paths to include, e.g. '*.jpg' or 'raw/**', or a regular expression prefixed by 're:'; repeatable")
+	uiCmd.Flags().StringArrayVar(&excludes, "exclude", excludes, "Glob of paths to exclude, taking precedence over --include; repeatable")
+	uiCmd.Flags().BoolVar(&withHidden, "hidden", withHidden, "Include the dotfiles and OS junk files, e.g. Thumbs.db and __MACOSX")
+	uiCmd.Flags().StringVarP(&search, "search", "q", search, "Display name of projects to search")
+	uiCmd.Flags().StringVarP(&method, "method", "m", method, "Desired method of upload: 'direct', 's3', 'gcs' or 'oss'")
+	uiCmd.Flags().StringVar(&ip, "ip", ip, "IP address of ad-hoc local server for direct upload.")
+	uiCmd.Flags().StringVar(&port, "port", port, "Port of ad-hoc local server for direct upload.")
+	uiCmd.Flags().StringVarP(&bucket, "bucket", "b", bucket, "Desired bucket to upload for method: 's3', 'gcs' or 'oss'")
+	uiCmd.Flags().BoolVar(&autoBucket, "auto-bucket", autoBucket, "Choose the bucket of the lowest measured upload latency if bucket is not given, cached for 24 hours")
+	uiCmd.Flags().StringVar(&checksumAlgo, "checksum", checksumAlgo, "Checksum algorithm: 'sha1', 'sha256' or 'xxh64'")
+	uiCmd.Flags().IntVarP(&timeout, "timeout", "t", timeout, "Timeout of checking upload state in seconds")
+	uiCmd.Flags().StringVar(&waitStrategy, "wait-strategy", waitStrategy, "Strategy of waiting for the upload states: 'fixed', 'backoff' or 'none' (not waiting)")
+	uiCmd.Flags().IntVar(&pollInterval, "poll-interval", pollInterval, "Interval of polling the upload states in seconds, the initial one of 'backoff'")
+	uiCmd.Flags().IntVarP(&thread, "thread", "n", thread, "Number of threads to process, default is number of cores x 4")
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"runtime"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/jackytck/alti-cli/cloud"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+)
+
+var adaptive bool
+
+// adaptiveMax is the max number of concurrent uploads of '--adaptive',
+// unless '--thread' is larger.
+const adaptiveMax = 32
+
+// checkAdaptive exits if '--adaptive' is given for direct upload, which is
+// pulled by the api server instead.
+func checkAdaptive(meth string) {
+	if adaptive && meth == service.DirectUploadMethod {
+		logging.Errorln("--adaptive is not supported by direct upload, as the images are pulled by the api server")
+		errors.Exit(errors.ErrInvalidInput)
+	}
+}
+
+// uploadThrottle gives the throttle of '--adaptive' uploads by meth, nil if
+// not adaptive. It starts from the limit learned in the last run, otherwise
+// from '--thread'.
+func uploadThrottle(meth string) *cloud.Throttle {
+	if !adaptive {
+		return nil
+	}
+	n := thread
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	max := adaptiveMax
+	if n > max {
+		max = n
+	}
+	if m, ok := service.LoadUploadMetric(meth); ok && m.Limit > 0 {
+		logging.Infof("Starting from %d concurrent uploads, %s/s in the last run", m.Limit, humanize.IBytes(uint64(m.Speed)))
+		n = m.Limit
+	}
+	return cloud.NewThrottle(n, 1, max)
+}
+
+// saveThrottle reports the throughput of t and saves its learned limit for
+// the next run.
+func saveThrottle(meth string, t *cloud.Throttle) {
+	if t == nil {
+		return
+	}
+	if verbose {
+		for _, w := range t.Stats() {
+			logging.Infof("Worker %d: %d file(s), %s in %s, %s/s\n", w.Worker, w.Files, humanize.IBytes(uint64(w.Bytes)), w.Elapsed.Round(time.Second), humanize.IBytes(uint64(w.Speed())))
+		}
+	}
+	speed := t.Speed()
+	if speed <= 0 {
+		// not a single round is finished
+		return
+	}
+	logging.Infof("Settled at %d concurrent uploads, %s/s\n", t.Limit(), humanize.IBytes(uint64(speed)))
+	m := service.UploadMetric{Limit: t.Limit(), Speed: speed, Time: time.Now()}
+	if err := service.SaveUploadMetric(meth, m); err != nil {
+		logging.Warnln("Upload metrics could not be saved:", err)
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackytck/alti-cli/db"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/service"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the uploaded files of a manifest",
+	Long:  "Re-check that all of the files recorded in an upload manifest are ready on the server. Exit with non-zero status if any of them is not.",
+	Run: func(cmd *cobra.Command, args []string) {
+		// pre-checks
+		if err := service.Check(
+			nil,
+			service.CheckAPIServerLite(),
+			service.CheckFile(manifestPath),
+		); err != nil {
+			errors.Exit(err)
+		}
+		m, err := db.LoadManifest(manifestPath)
+		if err != nil {
+			errors.Exit(err)
+		}
+
+		ctx, cancel := interruptContext()
+		defer exitIfInterrupted(ctx, cancel)
+		table := newTable()
+		table.SetHeader([]string{"Kind", "Filename", "ID", "State", "Error"})
+		var okCnt, checked int
+		for _, e := range m.Entries {
+			if ctx.Err() != nil {
+				break
+			}
+			state, err := entryState(ctx, m.PID, e)
+			if ctx.Err() != nil {
+				break
+			}
+			checked++
+			msg := ""
+			if err != nil {
+				msg = err.Error()
+			}
+			if state == service.Ready {
+				okCnt++
+				if !verbose {
+					continue
+				}
+			}
+			table.Append([]string{e.Kind, e.Filename, e.ID, state, msg})
+		}
+		if table.NumLines() > 0 {
+			table.Render()
+		}
+		if checked < len(m.Entries) {
+			fmt.Printf("%d out of %d checked are ready, %d are not checked.\n", okCnt, checked, len(m.Entries)-checked)
+			return
+		}
+		fmt.Printf("%d out of %d are ready.\n", okCnt, len(m.Entries))
+		if okCnt < len(m.Entries) {
+			errors.Exit(errors.ErrManifestNotReady)
+		}
+	},
+}
+
+// entryState queries the current state of manifest entry e of project pid.
+func entryState(ctx context.Context, pid string, e db.ManifestEntry) (string, error) {
+	switch e.Kind {
+	case "image":
+		if e.ID == "" {
+			return "", errors.ErrImgReg
+		}
+		img, err := gql.ProjectImage(ctx, pid, e.ID)
+		if err != nil {
+			return "", err
+		}
+		return img.State, nil
+	case "meta":
+		if e.ID == "" {
+			return "", errors.ErrMetaReg
+		}
+		mf, err := gql.ProjectMetaFile(ctx, pid, e.ID)
+		if err != nil {
+			return "", err
+		}
+		return mf.State, nil
+	case "model":
+		p, err := gql.Project(e.ID)
+		if err != nil {
+			return "", err
+		}
+		return p.ImportedState, nil
+	}
+	logging.Warnf("Unknown kind %q of %q\n", e.Kind, e.Filename)
+	return "", errors.ErrInvalidInput
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringVar(&manifestPath, "manifest", manifestPath, "Path of the json manifest of uploaded files")
+	verifyCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Display the ready files too")
+	errors.Must(verifyCmd.MarkFlagRequired("manifest"))
+}
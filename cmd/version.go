@@ -6,6 +6,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// Version is the version of alti-cli, set by ldflags on release.
+var Version = "v1.0.0"
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
 }
@@ -15,6 +18,6 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Version info.",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("v1.0.0")
+		fmt.Println(Version)
 	},
 }
@@ -2,12 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
 	"github.com/jackytck/alti-cli/types"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
@@ -32,7 +30,7 @@ var whoamiCmd = &cobra.Command{
 		header = append(header, types.UserHeaderString()...)
 		row = append(row, user.RowString()...)
 
-		table := tablewriter.NewWriter(os.Stdout)
+		table := newTable()
 		table.SetHeader(header)
 		table.Append(row)
 		table.Render()
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jackytck/alti-cli/rand"
+)
+
+// Headers attributing the requests to a client and a team or pipeline.
+const (
+	HeaderClientID   = "X-Alti-Client-Id"
+	HeaderRequestTag = "X-Alti-Request-Tag"
+)
+
+// clientIDFile is the file in the config directory storing the client id.
+const clientIDFile = "client-id"
+
+var requestTagRegex = regexp.MustCompile(`^[A-Za-z0-9._:/@+=-]{1,128}$`)
+
+// Attribution is the headers identifying the requests of this cli, so that
+// the admins of a self-hosted server could attribute and rate-limit them.
+type Attribution struct {
+	UserAgent  string // sent to all hosts
+	ClientID   string // id of this installation
+	RequestTag string // optional tag of the team or pipeline
+	Host       string // hostname receiving ClientID and RequestTag, i.e. of the api server
+}
+
+// ValidRequestTag tells if the tag is 1 to 128 letters, digits or any of '._:/@+=-'.
+func ValidRequestTag(tag string) bool {
+	return requestTagRegex.MatchString(tag)
+}
+
+// ClientID gives the id of this installation by the env var ALTI_CLIENT_ID,
+// or stored in the config directory, which is generated on first use.
+func ClientID() (string, error) {
+	if id := os.Getenv(AltiClientID); id != "" {
+		return id, nil
+	}
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	p := filepath.Join(dir, clientIDFile)
+	if b, err := ioutil.ReadFile(p); err == nil {
+		if id := strings.TrimSpace(string(b)); id != "" {
+			return id, nil
+		}
+	}
+	id, err := rand.String(16)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return id, ioutil.WriteFile(p, []byte(id+"\n"), 0644)
+}
+
+// attributionTransport sets the attribution headers of each request.
+type attributionTransport struct {
+	base http.RoundTripper
+	a    Attribution
+}
+
+func (t attributionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.a.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", t.a.UserAgent)
+	}
+	if t.a.Host != "" && strings.EqualFold(req.URL.Hostname(), t.a.Host) {
+		if t.a.ClientID != "" {
+			req.Header.Set(HeaderClientID, t.a.ClientID)
+		}
+		if t.a.RequestTag != "" {
+			req.Header.Set(HeaderRequestTag, t.a.RequestTag)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
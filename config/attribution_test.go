@@ -0,0 +1,72 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAttributionTransport(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL)
+
+	tests := []struct {
+		name         string
+		host         string
+		ua           string // explicit user agent of the request
+		wantUA       string
+		wantClientID string
+		wantTag      string
+	}{
+		{"api server", u.Hostname(), "", "alti-cli/v1.2.3", "c1", "team-a"},
+		{"other host", "api.example.com", "", "alti-cli/v1.2.3", "", ""},
+		{"explicit user agent", u.Hostname(), "sdk/1.0", "sdk/1.0", "c1", "team-a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Attribution{UserAgent: "alti-cli/v1.2.3", ClientID: "c1", RequestTag: "team-a", Host: tt.host}
+			c := &http.Client{Transport: attributionTransport{http.DefaultTransport, a}}
+			req, _ := http.NewRequest("GET", ts.URL, nil)
+			if tt.ua != "" {
+				req.Header.Set("User-Agent", tt.ua)
+			}
+			res, err := c.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if ua := got.Get("User-Agent"); ua != tt.wantUA {
+				t.Errorf("User-Agent = %q, want %q", ua, tt.wantUA)
+			}
+			if id := got.Get(HeaderClientID); id != tt.wantClientID {
+				t.Errorf("%s = %q, want %q", HeaderClientID, id, tt.wantClientID)
+			}
+			if tag := got.Get(HeaderRequestTag); tag != tt.wantTag {
+				t.Errorf("%s = %q, want %q", HeaderRequestTag, tag, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestValidRequestTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"team-a", true},
+		{"ci/nightly@build.42", true},
+		{"", false},
+		{"has space", false},
+		{"line\nbreak", false},
+	}
+	for _, tt := range tests {
+		if got := ValidRequestTag(tt.tag); got != tt.want {
+			t.Errorf("ValidRequestTag(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"net/url"
+	"sort"
+
+	"github.com/jackytck/alti-cli/errors"
+	"golang.org/x/crypto/scrypt"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Parts of the profiles that could be redacted.
+const (
+	RedactSecrets  = "secrets"  // keys and tokens
+	RedactUser     = "user"     // names and emails
+	RedactEndpoint = "endpoint" // hostnames of the non-public endpoints
+)
+
+// RedactParts are the parts of the profiles that could be redacted.
+var RedactParts = []string{RedactSecrets, RedactUser, RedactEndpoint}
+
+// Redacted replaces the redacted values.
+const Redacted = "REDACTED"
+
+// bundleMagic starts every encrypted bundle, followed by the salt and nonce.
+const bundleMagic = "ALTICFG1"
+
+const (
+	bundleSaltSize = 16
+	bundleKeySize  = 32
+)
+
+// Select gives a copy of the config with only the profiles closest to the ids,
+// all of them if ids is empty.
+func (c Config) Select(ids []string) (Config, error) {
+	keep := make(map[string]bool)
+	for _, id := range ids {
+		p, err := c.GetProfile(id)
+		if err != nil {
+			return Config{}, err
+		}
+		keep[p.ID] = true
+	}
+	ret := Config{Scopes: make(map[string]Scope), Active: c.Active}
+	for k, s := range c.Scopes {
+		var ps []Profile
+		for _, p := range s.Profiles {
+			if len(ids) == 0 || keep[p.ID] {
+				ps = append(ps, p)
+			}
+		}
+		if len(ps) > 0 {
+			ret.Scopes[k] = Scope{Endpoint: s.Endpoint, Profiles: ps}
+		}
+	}
+	if len(ids) > 0 && !keep[c.Active] {
+		ret.Active = ""
+	}
+	return ret, nil
+}
+
+// Redact gives a copy of the config with the parts replaced by Redacted, e.g.
+// for sharing it in bug reports.
+func (c Config) Redact(parts []string) Config {
+	has := make(map[string]bool)
+	for _, p := range parts {
+		has[p] = true
+	}
+	ret := Config{Scopes: make(map[string]Scope), Active: c.Active}
+	for k, s := range c.Scopes {
+		ep := s.Endpoint
+		if has[RedactEndpoint] {
+			ep = redactEndpoint(ep)
+			k = endpointToKey(ep)
+		}
+		ps := make([]Profile, len(s.Profiles))
+		for i, p := range s.Profiles {
+			if has[RedactSecrets] {
+				p.Key = redact(p.Key)
+				p.Token = redact(p.Token)
+				p.Defaults = redactProxy(p.Defaults)
+			}
+			if has[RedactUser] {
+				p.Name = redact(p.Name)
+				p.Email = redact(p.Email)
+			}
+			ps[i] = p
+		}
+		prev := ret.Scopes[k]
+		ret.Scopes[k] = Scope{Endpoint: ep, Profiles: append(prev.Profiles, ps...)}
+	}
+	return ret
+}
+
+// IsRedacted tells if the key or token of the profile is redacted.
+func (p Profile) IsRedacted() bool {
+	return p.Key == Redacted || p.Token == Redacted
+}
+
+// Merge adds the profiles of o to c. Profiles of the same ids are replaced if
+// overwrite is set, otherwise skipped, as are the ones already in c under
+// other ids and the redacted ones. Return the ids of the added, replaced and
+// skipped profiles.
+func (c *Config) Merge(o Config, overwrite bool) (added, replaced, skipped []string) {
+	if c.Scopes == nil {
+		c.Scopes = make(map[string]Scope)
+	}
+	for _, k := range o.ScopeKeys() {
+		src := o.Scopes[k]
+		k = endpointToKey(src.Endpoint)
+		s, ok := c.Scopes[k]
+		if !ok {
+			s = Scope{Endpoint: src.Endpoint}
+		}
+		for _, p := range src.Profiles {
+			if p.IsRedacted() {
+				skipped = append(skipped, p.ID)
+				continue
+			}
+			if i := indexOfProfile(s.Profiles, p.ID); i >= 0 {
+				if !overwrite {
+					skipped = append(skipped, p.ID)
+					continue
+				}
+				s.Profiles[i] = p
+				replaced = append(replaced, p.ID)
+				continue
+			}
+			if c.hasProfile(p.ID) || hasEqualProfile(s.Profiles, p) {
+				skipped = append(skipped, p.ID)
+				continue
+			}
+			s.Profiles = append(s.Profiles, p)
+			added = append(added, p.ID)
+		}
+		if len(s.Profiles) > 0 {
+			c.Scopes[k] = s
+		}
+	}
+	return
+}
+
+// EncryptBundle encrypts the config by a key derived from password by scrypt,
+// in AES-256-GCM, for moving the profiles to another machine.
+func EncryptBundle(c Config, password string) ([]byte, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, bundleSaltSize)
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	gcm, err := bundleCipher(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	header := append(append([]byte(bundleMagic), salt...), nonce...)
+	return gcm.Seal(header, nonce, data, []byte(bundleMagic)), nil
+}
+
+// DecryptBundle decrypts the config encrypted by EncryptBundle, or reads it
+// as is if it is not encrypted, e.g. a redacted one.
+// A wrong password or a tampered bundle gives ErrDecrypt.
+func DecryptBundle(b []byte, password string) (Config, error) {
+	var c Config
+	if !IsEncryptedBundle(b) {
+		if err := yaml.Unmarshal(b, &c); err != nil || c.Scopes == nil {
+			return c, errors.ErrProfileBundleInvalid
+		}
+		return c, nil
+	}
+	b = b[len(bundleMagic):]
+	if len(b) < bundleSaltSize {
+		return c, errors.ErrDecrypt
+	}
+	salt, b := b[:bundleSaltSize], b[bundleSaltSize:]
+	gcm, err := bundleCipher(password, salt)
+	if err != nil {
+		return c, err
+	}
+	if len(b) < gcm.NonceSize() {
+		return c, errors.ErrDecrypt
+	}
+	data, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], []byte(bundleMagic))
+	if err != nil {
+		return c, errors.ErrDecrypt
+	}
+	if err = yaml.Unmarshal(data, &c); err != nil || c.Scopes == nil {
+		return c, errors.ErrProfileBundleInvalid
+	}
+	return c, nil
+}
+
+// IsEncryptedBundle tells if b is encrypted by EncryptBundle.
+func IsEncryptedBundle(b []byte) bool {
+	return bytes.HasPrefix(b, []byte(bundleMagic))
+}
+
+func bundleCipher(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(password), salt, 1<<15, 8, 1, bundleKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// redact gives Redacted if s is not empty.
+func redact(s string) string {
+	if s == "" {
+		return s
+	}
+	return Redacted
+}
+
+// redactProxy gives a copy of the defaults with the credentials of the proxy
+// redacted.
+func redactProxy(defaults map[string]string) map[string]string {
+	u, err := url.Parse(defaults["proxy"])
+	if err != nil || u.User == nil {
+		return defaults
+	}
+	ret := make(map[string]string)
+	for k, v := range defaults {
+		ret[k] = v
+	}
+	u.User = url.User(Redacted)
+	ret["proxy"] = u.String()
+	return ret
+}
+
+// redactEndpoint replaces the hostname of the endpoint unless it is public.
+func redactEndpoint(ep string) string {
+	u, err := url.Parse(ep)
+	if err != nil {
+		return Redacted
+	}
+	switch u.Hostname() {
+	case DefaultHostName1, DefaultHostName2:
+		return ep
+	}
+	host := "redacted.invalid"
+	if u.Port() != "" {
+		host += ":" + u.Port()
+	}
+	u.Host = host
+	return u.String()
+}
+
+func (c Config) hasProfile(id string) bool {
+	for _, s := range c.Scopes {
+		if indexOfProfile(s.Profiles, id) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOfProfile(ps []Profile, id string) int {
+	for i, p := range ps {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasEqualProfile(ps []Profile, p Profile) bool {
+	for _, o := range ps {
+		if o.Equal(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeKeys gives the sorted keys of the scopes.
+func (c Config) ScopeKeys() []string {
+	var ret []string
+	for k := range c.Scopes {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
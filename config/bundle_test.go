@@ -0,0 +1,115 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+func testBundleConfig() Config {
+	return Config{
+		Scopes: map[string]Scope{
+			endpointToKey(DefaultEndpoint): {
+				Endpoint: DefaultEndpoint,
+				Profiles: []Profile{
+					{ID: DefaultProfileID, Key: DefaultAppKey},
+					{ID: "alice", Name: "Alice", Email: "a@example.com", Key: "k1", Token: "t1"},
+				},
+			},
+			endpointToKey("https://alti.corp.example:8443"): {
+				Endpoint: "https://alti.corp.example:8443",
+				Profiles: []Profile{
+					{ID: "ci", Key: "k2", Token: "t2", Defaults: map[string]string{"proxy": "http://u:p@proxy:3128", "method": "s3"}},
+				},
+			},
+		},
+		Active: "ci",
+	}
+}
+
+func TestBundle(t *testing.T) {
+	c := testBundleConfig()
+	b, err := EncryptBundle(c, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncryptedBundle(b) {
+		t.Error("IsEncryptedBundle() = false, want true")
+	}
+	got, err := DecryptBundle(b, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("DecryptBundle() = %v, want %v", got, c)
+	}
+	if _, err = DecryptBundle(b, "wrong"); err != errors.ErrDecrypt {
+		t.Errorf("DecryptBundle() of wrong password error = %v, want %v", err, errors.ErrDecrypt)
+	}
+	b[len(b)-1] ^= 1
+	if _, err = DecryptBundle(b, "secret"); err != errors.ErrDecrypt {
+		t.Errorf("DecryptBundle() of tampered bundle error = %v, want %v", err, errors.ErrDecrypt)
+	}
+	if _, err = DecryptBundle([]byte("not: [a bundle"), ""); err != errors.ErrProfileBundleInvalid {
+		t.Errorf("DecryptBundle() of invalid file error = %v, want %v", err, errors.ErrProfileBundleInvalid)
+	}
+}
+
+func TestConfig_Redact(t *testing.T) {
+	c := testBundleConfig().Redact([]string{RedactSecrets, RedactUser, RedactEndpoint})
+	alice := c.Scopes[endpointToKey(DefaultEndpoint)].Profiles[1]
+	if alice.Key != Redacted || alice.Token != Redacted || alice.Name != Redacted || alice.Email != Redacted {
+		t.Errorf("redacted profile = %+v", alice)
+	}
+	s, ok := c.Scopes[endpointToKey("https://redacted.invalid:8443")]
+	if !ok {
+		t.Fatalf("redacted scopes = %v", c.Scopes)
+	}
+	if p := s.Profiles[0].Defaults["proxy"]; p != "http://REDACTED@proxy:3128" {
+		t.Errorf("redacted proxy = %q", p)
+	}
+	orig := testBundleConfig()
+	if p := orig.Scopes[endpointToKey("https://alti.corp.example:8443")].Profiles[0].Defaults["proxy"]; p != "http://u:p@proxy:3128" {
+		t.Errorf("original proxy is changed to %q", p)
+	}
+}
+
+func TestConfig_Merge(t *testing.T) {
+	tests := []struct {
+		name                     string
+		overwrite                bool
+		added, replaced, skipped []string
+	}{
+		{"skip existing", false, []string{"ci"}, nil, []string{"alice", "bob"}},
+		{"overwrite", true, []string{"ci"}, []string{"alice"}, []string{"bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Config{
+				Scopes: map[string]Scope{
+					endpointToKey(DefaultEndpoint): {
+						Endpoint: DefaultEndpoint,
+						Profiles: []Profile{{ID: "alice", Key: "old", Token: "old"}},
+					},
+				},
+				Active: "alice",
+			}
+			o := testBundleConfig()
+			o.Scopes[endpointToKey(DefaultEndpoint)] = Scope{
+				Endpoint: DefaultEndpoint,
+				Profiles: []Profile{
+					{ID: "alice", Key: "k1", Token: "t1"},
+					{ID: "bob", Key: Redacted, Token: Redacted},
+				},
+			}
+			added, replaced, skipped := c.Merge(o, tt.overwrite)
+			if !reflect.DeepEqual(added, tt.added) || !reflect.DeepEqual(replaced, tt.replaced) || !reflect.DeepEqual(skipped, tt.skipped) {
+				t.Errorf("Merge() = %v %v %v, want %v %v %v", added, replaced, skipped, tt.added, tt.replaced, tt.skipped)
+			}
+			if _, err := c.GetProfile("ci"); err != nil {
+				t.Error("merged profile is not found")
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/rand"
@@ -21,6 +22,7 @@ func FromEnv() (Config, bool) {
 		Key:      os.Getenv(AltiKey),
 		Token:    os.Getenv(AltiToken),
 	}
+	ap.Expiry = TokenExpiry(ap.Token)
 	if ap.Endpoint == "" || ap.Key == "" || ap.Token == "" {
 		return c, false
 	}
@@ -64,7 +66,18 @@ func EnvOrDefault(envKey, defaultVal string) string {
 // Load loads config from env var first.
 // If not exists, load from default path.
 // If not found in default path, load from default config.
+// Only the secrets of the active profile are got from Secrets.
 func Load() Config {
+	return load(false)
+}
+
+// LoadAll loads config like Load, with the secrets of all profiles, e.g. for
+// listing or exporting them.
+func LoadAll() Config {
+	return load(true)
+}
+
+func load(all bool) Config {
 	// a. from env
 	ec, ok := FromEnv()
 	if ok {
@@ -77,6 +90,7 @@ func Load() Config {
 	if err != nil || c.Scopes == nil {
 		return DefaultConfig()
 	}
+	c.unseal(all)
 	return c
 }
 
@@ -110,6 +124,7 @@ func (c Config) GetActive() APoint {
 				ret.Endpoint = v.Endpoint
 				ret.Key = p.Key
 				ret.Token = p.Token
+				ret.Expiry = p.Expiry
 				return ret
 			}
 		}
@@ -139,6 +154,18 @@ func (c Config) GetProfile(id string) (*Profile, error) {
 	return &ret, nil
 }
 
+// GetAPoint gives the endpoint and profile of the closest profile that
+// matches the given id. If no match, return ErrProfileNotFound
+func (c Config) GetAPoint(id string) (*APoint, error) {
+	p, err := c.GetProfile(id)
+	if err != nil {
+		return nil, err
+	}
+	c.Active = p.ID
+	ret := c.GetActive()
+	return &ret, nil
+}
+
 // AddProfile adds a profile under its endpoint and set it as active.
 // Existing values would be replaced.
 func (c *Config) AddProfile(ap APoint) error {
@@ -149,9 +176,10 @@ func (c *Config) AddProfile(ap APoint) error {
 		return err
 	}
 	p := Profile{
-		ID:    uid,
-		Key:   ap.Key,
-		Token: ap.Token,
+		ID:     uid,
+		Key:    ap.Key,
+		Token:  ap.Token,
+		Expiry: ap.Expiry,
 	}
 	if ok {
 		// scope already exists
@@ -205,6 +233,8 @@ func (c Config) RemoveProfile(id string, save bool) (*APoint, error) {
 	if profile.ID == DefaultProfileID {
 		return nil, errors.ErrProfileNotRemovable
 	}
+	// not found if it is saved in plaintext
+	deleteSecret(profile.ID)
 
 	// b. set new scope with removed profile
 	pSlice := c.Scopes[scope].Profiles
@@ -228,6 +258,60 @@ func (c Config) RemoveProfile(id string, save bool) (*APoint, error) {
 	return &ret, nil
 }
 
+// RenameProfile changes the id of the closest profile that matches the given id
+// to newID, which must not be empty or taken by another profile.
+// If no match, return ErrProfileNotFound
+// Default profile could not be renamed.
+// Return the renamed profile if succeed.
+func (c *Config) RenameProfile(id, newID string, save bool) (*Profile, error) {
+	if newID == "" || strings.ContainsAny(newID, " \t\n") || newID == DefaultProfileID {
+		return nil, errors.ErrProfileIDInvalid
+	}
+	p, err := c.GetProfile(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.ID == DefaultProfileID {
+		return nil, errors.ErrProfileNotRemovable
+	}
+	for _, v := range c.Scopes {
+		for _, o := range v.Profiles {
+			if o.ID == newID && o.ID != p.ID {
+				return nil, errors.ErrProfileIDInvalid
+			}
+		}
+	}
+
+	oldID := p.ID
+	for _, v := range c.Scopes {
+		for i := range v.Profiles {
+			if v.Profiles[i].ID == oldID {
+				// the secrets are moved to the new id by Save
+				if v.Profiles[i].Key == "" {
+					if key, token, err := getSecret(oldID); err == nil {
+						v.Profiles[i].Key = key
+						v.Profiles[i].Token = token
+					}
+				}
+				v.Profiles[i].ID = newID
+				p = &v.Profiles[i]
+			}
+		}
+	}
+	if c.Active == oldID {
+		c.Active = newID
+	}
+
+	if save {
+		if err = c.Save(); err != nil {
+			return nil, err
+		}
+		// the secrets are stored under the new id by Save
+		deleteSecret(oldID)
+	}
+	return p, nil
+}
+
 // ClearActiveToken clears the token of active profile.
 func (c *Config) ClearActiveToken(save bool) error {
 	var s string
@@ -236,6 +320,7 @@ func (c *Config) ClearActiveToken(save bool) error {
 			if p.ID == c.Active {
 				s = k
 				v.Profiles[i].Token = ""
+				v.Profiles[i].Expiry = 0
 			}
 		}
 	}
@@ -252,6 +337,23 @@ func (c *Config) ClearActiveToken(save bool) error {
 	return nil
 }
 
+// SetActiveToken replaces the token of the active profile by the refreshed
+// token expiring at the unix time expiry.
+func (c *Config) SetActiveToken(token string, expiry int64, save bool) error {
+	for _, v := range c.Scopes {
+		for i, p := range v.Profiles {
+			if p.ID == c.Active {
+				v.Profiles[i].Token = token
+				v.Profiles[i].Expiry = expiry
+			}
+		}
+	}
+	if save {
+		return c.Save()
+	}
+	return nil
+}
+
 // Size counts the number of profiles.
 func (c *Config) Size() int {
 	var ret int
@@ -278,8 +380,9 @@ func (c *Config) SetActiveUserInfo(name, email string, save bool) error {
 }
 
 // Save saves the config in default path: '~/.altizure/config'.
+// The keys and tokens are stored in Secrets unless NoKeychain is set.
 func (c Config) Save() error {
-	data, err := yaml.Marshal(c)
+	data, err := yaml.Marshal(c.seal())
 	if err != nil {
 		return err
 	}
@@ -322,11 +425,13 @@ func (s *Scope) Add(p Profile) Profile {
 
 // Profile represents the login profile of a user of a certain endpoint.
 type Profile struct {
-	ID    string `yaml:"id"`
-	Name  string `yaml:"name"`
-	Email string `yaml:"email"`
-	Key   string `yaml:"key"`
-	Token string `yaml:"token"`
+	ID       string            `yaml:"id"`
+	Name     string            `yaml:"name"`
+	Email    string            `yaml:"email"`
+	Key      string            `yaml:"key"` // empty if stored in Secrets
+	Token    string            `yaml:"token"`
+	Defaults map[string]string `yaml:"defaults,omitempty"` // default flag values
+	Expiry   int64             `yaml:"expiry,omitempty"`   // unix time of token expiry, 0 if unknown
 }
 
 // Equal commpares if two profiles are equal, ignoring id.
@@ -341,4 +446,5 @@ type APoint struct {
 	Email    string `yaml:"email" json:"email"`
 	Key      string `yaml:"key" json:"key"`
 	Token    string `yaml:"token" json:"token"`
+	Expiry   int64  `yaml:"expiry,omitempty" json:"expiry,omitempty"` // unix time of token expiry, 0 if unknown
 }
@@ -33,7 +33,7 @@ func TestConfig_GetActive(t *testing.T) {
 		fields fields
 		want   APoint
 	}{
-		{"default active", fields{DefaultConfig().Scopes, DefaultConfig().Active}, APoint{DefaultEndpoint, "", "", DefaultAppKey, ""}},
+		{"default active", fields{DefaultConfig().Scopes, DefaultConfig().Active}, APoint{DefaultEndpoint, "", "", DefaultAppKey, "", 0}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -63,7 +63,7 @@ func TestConfig_GetProfile(t *testing.T) {
 		want    *Profile
 		wantErr bool
 	}{
-		{"partial match", fields{DefaultConfig().Scopes, DefaultConfig().Active}, args{"def"}, &Profile{"default", "", "", DefaultAppKey, ""}, false},
+		{"partial match", fields{DefaultConfig().Scopes, DefaultConfig().Active}, args{"def"}, &Profile{"default", "", "", DefaultAppKey, "", nil, 0}, false},
 		{"not found", fields{DefaultConfig().Scopes, DefaultConfig().Active}, args{"nat"}, nil, true},
 	}
 	for _, tt := range tests {
@@ -84,6 +84,39 @@ func TestConfig_GetProfile(t *testing.T) {
 	}
 }
 
+func TestConfig_GetAPoint(t *testing.T) {
+	c := DefaultConfig()
+	c.Scopes["example"] = Scope{
+		Endpoint: "https://example.com",
+		Profiles: []Profile{{ID: "site", Name: "Site", Key: "k", Token: "t"}},
+	}
+	tests := []struct {
+		name    string
+		id      string
+		want    *APoint
+		wantErr bool
+	}{
+		{"default", "def", &APoint{DefaultEndpoint, "", "", DefaultAppKey, "", 0}, false},
+		{"other endpoint", "si", &APoint{"https://example.com", "Site", "", "k", "t", 0}, false},
+		{"not found", "nat", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GetAPoint(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.GetAPoint() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.GetAPoint() = %v, want %v", got, tt.want)
+			}
+			if c.Active != DefaultProfileID {
+				t.Errorf("Config.GetAPoint() changed the active profile to %q", c.Active)
+			}
+		})
+	}
+}
+
 func TestConfig_AddProfile(t *testing.T) {
 	c := Load()
 	errors.Must(c.AddProfile(APoint{
@@ -107,6 +140,59 @@ func TestConfig_AddProfile(t *testing.T) {
 	}
 }
 
+func TestConfig_RenameProfile(t *testing.T) {
+	newConfig := func() Config {
+		return Config{
+			Scopes: map[string]Scope{
+				"api*altizure*com": {
+					Endpoint: "https://api.altizure.com",
+					Profiles: []Profile{
+						{ID: DefaultProfileID},
+						{ID: "natid", Key: "k1", Token: "t1"},
+						{ID: "other", Key: "k2", Token: "t2"},
+					},
+				},
+			},
+			Active: "natid",
+		}
+	}
+	tests := []struct {
+		name       string
+		id         string
+		newID      string
+		wantActive string
+		wantErr    error
+	}{
+		{"rename active", "natid", "work", "work", nil},
+		{"partial id", "oth", "home", "natid", nil},
+		{"same id", "natid", "natid", "natid", nil},
+		{"taken", "natid", "other", "natid", errors.ErrProfileIDInvalid},
+		{"empty", "natid", "", "natid", errors.ErrProfileIDInvalid},
+		{"space", "natid", "my work", "natid", errors.ErrProfileIDInvalid},
+		{"default", "default", "work", "natid", errors.ErrProfileNotRemovable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConfig()
+			p, err := c.RenameProfile(tt.id, tt.newID, false)
+			if err != tt.wantErr {
+				t.Errorf("Config.RenameProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if c.Active != tt.wantActive {
+				t.Errorf("Config.Active = %v, want %v", c.Active, tt.wantActive)
+			}
+			if err != nil {
+				return
+			}
+			got, err := c.GetProfile(tt.newID)
+			if err != nil || got.ID != tt.newID || got.Key != p.Key {
+				t.Errorf("Config.GetProfile() = %v, %v, want id %v", got, err, tt.newID)
+			}
+		})
+	}
+}
+
 func TestConfig_String(t *testing.T) {
 	type fields struct {
 		Scopes map[string]Scope
@@ -156,8 +242,8 @@ func TestScope_Add(t *testing.T) {
 		args   args
 		want   Profile
 	}{
-		{"empty", fields{"nat-endpoint", []Profile{}}, args{Profile{"natid", "Nat", "nat@nat.com", "nat-key", "nat-token"}}, Profile{"natid", "Nat", "nat@nat.com", "nat-key", "nat-token"}},
-		{"exists", fields{"nat-endpoint", []Profile{{"aid", "Nat", "nat@nat.com", "nat-key", "nat-token"}}}, args{Profile{"natid", "Nat", "nat@nat.com", "nat-key", "nat-token"}}, Profile{"aid", "Nat", "nat@nat.com", "nat-key", "nat-token"}},
+		{"empty", fields{"nat-endpoint", []Profile{}}, args{Profile{"natid", "Nat", "nat@nat.com", "nat-key", "nat-token", nil, 0}}, Profile{"natid", "Nat", "nat@nat.com", "nat-key", "nat-token", nil, 0}},
+		{"exists", fields{"nat-endpoint", []Profile{{"aid", "Nat", "nat@nat.com", "nat-key", "nat-token", nil, 0}}}, args{Profile{"natid", "Nat", "nat@nat.com", "nat-key", "nat-token", nil, 0}}, Profile{"aid", "Nat", "nat@nat.com", "nat-key", "nat-token", nil, 0}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -192,9 +278,9 @@ func TestProfile_Equal(t *testing.T) {
 		args   args
 		want   bool
 	}{
-		{"equal", fields{"anyid", "Nat1", "nat@nat.com", "nat-key", "nat-token"}, args{Profile{"anoterid", "Nat2", "nat2@nat.com", "nat-key", "nat-token"}}, true},
-		{"different key", fields{"anyid", "Nat", "nat@nat.com", "nat-key", "nat-token"}, args{Profile{"anoterid", "Nat", "nat@nat.com", "a-key", "nat-token"}}, false},
-		{"different token", fields{"anyid", "Nat", "nat@nat.com", "nat-key", "nat-token"}, args{Profile{"anyid", "Nat", "nat@nat.com", "nat-key", "a-token"}}, false},
+		{"equal", fields{"anyid", "Nat1", "nat@nat.com", "nat-key", "nat-token"}, args{Profile{"anoterid", "Nat2", "nat2@nat.com", "nat-key", "nat-token", nil, 0}}, true},
+		{"different key", fields{"anyid", "Nat", "nat@nat.com", "nat-key", "nat-token"}, args{Profile{"anoterid", "Nat", "nat@nat.com", "a-key", "nat-token", nil, 0}}, false},
+		{"different token", fields{"anyid", "Nat", "nat@nat.com", "nat-key", "nat-token"}, args{Profile{"anyid", "Nat", "nat@nat.com", "nat-key", "a-token", nil, 0}}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
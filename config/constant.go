@@ -32,3 +32,24 @@ const AltiKey = "ALTI_KEY"
 
 // AltiToken is the key of environment variable of user token.
 const AltiToken = "ALTI_TOKEN"
+
+// AltiProxy is the key of environment variable of the http, https or socks5 proxy url.
+const AltiProxy = "ALTI_PROXY"
+
+// AltiCACert is the key of environment variable of the path of extra trusted CA bundle.
+const AltiCACert = "ALTI_CA_CERT"
+
+// AltiInsecure is the key of environment variable of skipping TLS verification.
+const AltiInsecure = "ALTI_INSECURE"
+
+// AltiAPITimeout is the key of environment variable of the timeout of each request, e.g. 30s.
+const AltiAPITimeout = "ALTI_API_TIMEOUT"
+
+// AltiClientID is the key of environment variable of the client id sent to the api server.
+const AltiClientID = "ALTI_CLIENT_ID"
+
+// AltiRequestTag is the key of environment variable of the tag of all requests to the api server.
+const AltiRequestTag = "ALTI_REQUEST_TAG"
+
+// AltiConfigPassword is the key of environment variable of the password of the exported or imported profiles.
+const AltiConfigPassword = "ALTI_CONFIG_PASSWORD"
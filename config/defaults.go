@@ -0,0 +1,74 @@
+package config
+
+import (
+	"sort"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// DefaultKeys are the flags that could be given defaults per profile.
+var DefaultKeys = []string{"method", "bucket", "thread", "skip", "output", "proxy", "ca-cert", "insecure", "direct-tls", "interface", "prefer-ipv6", "auto-bucket", "channel", "wait-strategy", "poll-interval", "notify-url", "notify-desktop"}
+
+// IsDefaultKey tells if key is one of DefaultKeys.
+func IsDefaultKey(key string) bool {
+	for _, k := range DefaultKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDefaults returns the default flag values of the active profile.
+func (c Config) GetDefaults() map[string]string {
+	for _, v := range c.Scopes {
+		for _, p := range v.Profiles {
+			if p.ID == c.Active {
+				return p.Defaults
+			}
+		}
+	}
+	return nil
+}
+
+// SetDefault sets the default value of flag key of the active profile.
+// An empty value unsets it.
+func (c *Config) SetDefault(key, value string, save bool) error {
+	if !IsDefaultKey(key) {
+		return errors.ErrInvalidInput
+	}
+	found := false
+	for _, v := range c.Scopes {
+		for i, p := range v.Profiles {
+			if p.ID != c.Active {
+				continue
+			}
+			found = true
+			if value == "" {
+				delete(p.Defaults, key)
+				continue
+			}
+			if p.Defaults == nil {
+				v.Profiles[i].Defaults = make(map[string]string)
+			}
+			v.Profiles[i].Defaults[key] = value
+		}
+	}
+	if !found {
+		return errors.ErrProfileNotFound
+	}
+	if save {
+		return c.Save()
+	}
+	return nil
+}
+
+// SortedDefaultKeys gives the keys of defaults in order.
+func SortedDefaultKeys(defaults map[string]string) []string {
+	var ret []string
+	for k := range defaults {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfig_SetDefault(t *testing.T) {
+	newConfig := func() Config {
+		return Config{
+			Scopes: map[string]Scope{"s": {"nat-endpoint", []Profile{{"natid", "Nat", "nat@nat.com", "nat-key", "nat-token", nil, 0}}}},
+			Active: "natid",
+		}
+	}
+	type args struct {
+		key   string
+		value string
+	}
+	tests := []struct {
+		name    string
+		args    []args
+		want    map[string]string
+		wantErr bool
+	}{
+		{"set", []args{{"method", "s3"}}, map[string]string{"method": "s3"}, false},
+		{"replace", []args{{"method", "s3"}, {"method", "gcs"}}, map[string]string{"method": "gcs"}, false},
+		{"unset", []args{{"method", "s3"}, {"thread", "8"}, {"method", ""}}, map[string]string{"thread": "8"}, false},
+		{"invalid key", []args{{"color", "red"}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConfig()
+			var err error
+			for _, a := range tt.args {
+				err = c.SetDefault(a.key, a.value, false)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetDefault() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := c.GetDefaults(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.GetDefaults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
@@ -20,6 +22,26 @@ func endpointToKey(ep string) string {
 	return strings.Replace(str, ".", "*", -1)
 }
 
+// TokenExpiry gives the unix time of the 'exp' claim of token if it is a
+// JWT, 0 if unknown.
+func TokenExpiry(token string) int64 {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return 0
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(b, &claims) != nil {
+		return 0
+	}
+	return claims.Exp
+}
+
 // uniqueProfile returns a new unique set of profiles.
 func uniqueProfile(ps []Profile) []Profile {
 	var ret []Profile
@@ -39,7 +39,7 @@ func Test_uniqueProfile(t *testing.T) {
 		args args
 		want []Profile
 	}{
-		{"simple", args{[]Profile{{"id1", "n1", "e1", "k1", "t1"}, {"id2", "n1", "e1", "k2", "t2"}, {"id1", "n1", "e1", "k1", "t1"}}}, []Profile{{"id1", "n1", "e1", "k1", "t1"}, {"id2", "n1", "e1", "k2", "t2"}}},
+		{"simple", args{[]Profile{{"id1", "n1", "e1", "k1", "t1", nil, 0}, {"id2", "n1", "e1", "k2", "t2", nil, 0}, {"id1", "n1", "e1", "k1", "t1", nil, 0}}}, []Profile{{"id1", "n1", "e1", "k1", "t1", nil, 0}, {"id2", "n1", "e1", "k2", "t2", nil, 0}}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -94,3 +94,24 @@ func Test_min(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenExpiry(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  int64
+	}{
+		{"jwt", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJ1MSIsImV4cCI6MTcwMDAwMDAwMH0.c2ln", 1700000000},
+		{"jwt without exp", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJ1MSJ9.c2ln", 0},
+		{"opaque", "5d37e0a1b2c3d4e5f6a7b8c9", 0},
+		{"invalid payload", "a.b!c.d", 0},
+		{"empty", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TokenExpiry(tt.token); got != tt.want {
+				t.Errorf("TokenExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
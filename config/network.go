@@ -0,0 +1,89 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// Network is the proxy, TLS and timeout settings of all the http traffic,
+// i.e. gql requests, cloud uploads and downloads.
+type Network struct {
+	Proxy    string        // http, https or socks5 url, empty to use HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	CACert   string        // path of the pem bundle of extra trusted CAs, e.g. of a self-hosted api server
+	Insecure bool          // skip TLS verification
+	Timeout  time.Duration // max wait of the response headers of each request, zero means no timeout
+
+	Attribution Attribution // user agent, client id and request tag of each request
+}
+
+var (
+	transportMu sync.RWMutex
+	transport   http.RoundTripper = http.DefaultTransport
+)
+
+// SetNetwork applies the network settings to the transport of all the
+// subsequent requests.
+func SetNetwork(n Network) error {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ResponseHeaderTimeout = n.Timeout
+	if n.Proxy != "" {
+		u, err := url.Parse(n.Proxy)
+		if err != nil || u.Host == "" {
+			return errors.ErrProxyInvalid
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return errors.ErrProxyInvalid
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	if n.CACert != "" || n.Insecure {
+		tc := &tls.Config{InsecureSkipVerify: n.Insecure}
+		if n.CACert != "" {
+			pem, err := ioutil.ReadFile(n.CACert)
+			if err != nil {
+				return err
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return errors.ErrCACertInvalid
+			}
+			tc.RootCAs = pool
+		}
+		t.TLSClientConfig = tc
+	}
+
+	var rt http.RoundTripper = t
+	if n.Attribution != (Attribution{}) {
+		rt = attributionTransport{t, n.Attribution}
+	}
+
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	transport = rt
+	return nil
+}
+
+// Transport returns the http transport of the network settings.
+func Transport() http.RoundTripper {
+	transportMu.RLock()
+	defer transportMu.RUnlock()
+	return transport
+}
+
+// HTTPClient returns a http client of the network settings.
+// Zero timeout means no timeout.
+func HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: Transport(), Timeout: timeout}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"sync"
+
+	keyring "github.com/zalando/go-keyring"
+)
+
+// SecretStore stores the key and token of profiles out of the config file.
+type SecretStore interface {
+	Get(id string) (key, token string, err error)
+	Set(id, key, token string) error
+	Delete(id string) error
+}
+
+// Secrets is the store of the profile secrets, the OS keychain by default.
+var Secrets SecretStore = keychainStore{}
+
+// NoKeychain disables storing new secrets in Secrets, e.g. for headless machines.
+// The secrets are then saved in plaintext in the config file.
+var NoKeychain bool
+
+// keychainService is the service name of the secrets in the OS keychain.
+const keychainService = "alti-cli"
+
+// keychainStore stores secrets in macOS Keychain, Windows Credential Manager
+// or Linux secret service.
+type keychainStore struct{}
+
+type secret struct {
+	Key   string `json:"key"`
+	Token string `json:"token"`
+}
+
+func (keychainStore) Get(id string) (string, string, error) {
+	v, err := keyring.Get(keychainService, id)
+	if err != nil {
+		return "", "", err
+	}
+	var s secret
+	if err = json.Unmarshal([]byte(v), &s); err != nil {
+		return "", "", err
+	}
+	return s.Key, s.Token, nil
+}
+
+func (keychainStore) Set(id, key, token string) error {
+	v, err := json.Marshal(secret{key, token})
+	if err != nil {
+		return err
+	}
+	return keyring.Set(keychainService, id, string(v))
+}
+
+func (keychainStore) Delete(id string) error {
+	return keyring.Delete(keychainService, id)
+}
+
+// secretCache caches the secrets of Secrets for the process lifetime, as each
+// query of the OS keychain is slow and may even prompt the user.
+var secretCache = struct {
+	sync.Mutex
+	m map[string]cachedSecret
+}{m: make(map[string]cachedSecret)}
+
+type cachedSecret struct {
+	secret
+	err error
+}
+
+// getSecret gets the key and token of profile id from Secrets once.
+func getSecret(id string) (string, string, error) {
+	secretCache.Lock()
+	defer secretCache.Unlock()
+	if s, ok := secretCache.m[id]; ok {
+		return s.Key, s.Token, s.err
+	}
+	key, token, err := Secrets.Get(id)
+	secretCache.m[id] = cachedSecret{secret{key, token}, err}
+	return key, token, err
+}
+
+// setSecret stores the key and token of profile id in Secrets.
+func setSecret(id, key, token string) error {
+	secretCache.Lock()
+	defer secretCache.Unlock()
+	if err := Secrets.Set(id, key, token); err != nil {
+		return err
+	}
+	secretCache.m[id] = cachedSecret{secret: secret{key, token}}
+	return nil
+}
+
+// deleteSecret removes the key and token of profile id from Secrets.
+func deleteSecret(id string) error {
+	secretCache.Lock()
+	defer secretCache.Unlock()
+	delete(secretCache.m, id)
+	return Secrets.Delete(id)
+}
+
+// seal returns a copy of the config with the secrets of each profile moved
+// into Secrets. Profiles fail to be stored are kept in plaintext.
+// Profiles without key are already in Secrets, i.e. not unsealed.
+func (c Config) seal() Config {
+	ret := Config{
+		Scopes: make(map[string]Scope),
+		Active: c.Active,
+	}
+	for k, s := range c.Scopes {
+		ps := make([]Profile, len(s.Profiles))
+		for i, p := range s.Profiles {
+			if !NoKeychain && p.ID != DefaultProfileID && p.Key != "" && setSecret(p.ID, p.Key, p.Token) == nil {
+				p.Key = ""
+				p.Token = ""
+			}
+			ps[i] = p
+		}
+		ret.Scopes[k] = Scope{
+			Endpoint: s.Endpoint,
+			Profiles: ps,
+		}
+	}
+	return ret
+}
+
+// unseal fills in the secrets of the active profile, or of all profiles if
+// all is set, that are stored in Secrets, i.e. the ones without key in the
+// config file. Secrets could not be retrieved are left empty.
+func (c Config) unseal(all bool) {
+	for _, s := range c.Scopes {
+		for i, p := range s.Profiles {
+			if p.ID == DefaultProfileID || p.Key != "" || (!all && p.ID != c.Active) {
+				continue
+			}
+			key, token, err := getSecret(p.ID)
+			if err != nil {
+				continue
+			}
+			s.Profiles[i].Key = key
+			s.Profiles[i].Token = token
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type mapStore map[string][2]string
+
+func (m mapStore) Get(id string) (string, string, error) {
+	v, ok := m[id]
+	if !ok {
+		return "", "", errors.New("not found")
+	}
+	return v[0], v[1], nil
+}
+
+func (m mapStore) Set(id, key, token string) error {
+	m[id] = [2]string{key, token}
+	return nil
+}
+
+func (m mapStore) Delete(id string) error {
+	delete(m, id)
+	return nil
+}
+
+func TestSealUnseal(t *testing.T) {
+	defer func(s SecretStore, n bool) {
+		Secrets = s
+		NoKeychain = n
+	}(Secrets, NoKeychain)
+
+	nat := Profile{"natid", "Nat", "nat@nat.com", "nat-key", "nat-token", nil, 0}
+	def := Profile{DefaultProfileID, "", "", DefaultAppKey, "", nil, 0}
+	newConfig := func() Config {
+		return Config{
+			Scopes: map[string]Scope{"s": {"nat-endpoint", []Profile{def, nat}}},
+			Active: "natid",
+		}
+	}
+
+	tests := []struct {
+		name       string
+		noKeychain bool
+		want       Profile
+	}{
+		{"keychain", false, Profile{"natid", "Nat", "nat@nat.com", "", "", nil, 0}},
+		{"no keychain", true, nat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mapStore{}
+			Secrets = store
+			secretCache.m = make(map[string]cachedSecret)
+			NoKeychain = tt.noKeychain
+
+			c := newConfig()
+			sealed := c.seal()
+			if got := sealed.Scopes["s"].Profiles; !reflect.DeepEqual(got, []Profile{def, tt.want}) {
+				t.Errorf("seal() = %v, want %v", got, []Profile{def, tt.want})
+			}
+			if !reflect.DeepEqual(c, newConfig()) {
+				t.Errorf("seal() modified the config: %v", c)
+			}
+
+			sealed.unseal(true)
+			if got := sealed.Scopes["s"].Profiles; !reflect.DeepEqual(got, []Profile{def, nat}) {
+				t.Errorf("unseal() = %v, want %v", got, []Profile{def, nat})
+			}
+		})
+	}
+}
+
+// countStore counts the gets of each id.
+type countStore struct {
+	mapStore
+	gets map[string]int
+}
+
+func (s countStore) Get(id string) (string, string, error) {
+	s.gets[id]++
+	return s.mapStore.Get(id)
+}
+
+func TestUnsealActive(t *testing.T) {
+	defer func(s SecretStore) { Secrets = s }(Secrets)
+
+	store := countStore{mapStore{"nat": {"nat-key", "nat-token"}, "bob": {"bob-key", "bob-token"}}, make(map[string]int)}
+	Secrets = store
+	secretCache.m = make(map[string]cachedSecret)
+	newConfig := func(active string) Config {
+		return Config{
+			Scopes: map[string]Scope{"s": {"endpoint", []Profile{{ID: "nat"}, {ID: "bob"}}}},
+			Active: active,
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		c := newConfig("nat")
+		c.unseal(false)
+		if got := c.GetActive(); got.Key != "nat-key" || got.Token != "nat-token" {
+			t.Errorf("unseal(false) active = %q %q, want the secrets of nat", got.Key, got.Token)
+		}
+		if p := c.Scopes["s"].Profiles[1]; p.Key != "" {
+			t.Errorf("unseal(false) unsealed the inactive profile %q", p.ID)
+		}
+	}
+	if want := map[string]int{"nat": 1}; !reflect.DeepEqual(store.gets, want) {
+		t.Errorf("Secrets.Get() calls = %v, want %v", store.gets, want)
+	}
+
+	c := newConfig("nat")
+	c.unseal(true)
+	if p := c.Scopes["s"].Profiles[1]; p.Key != "bob-key" {
+		t.Errorf("unseal(true) key of %q = %q, want %q", p.ID, p.Key, "bob-key")
+	}
+
+	// the profiles not unsealed are not stored again
+	store.mapStore["bob"] = [2]string{"new-key", "new-token"}
+	c = newConfig("nat")
+	c.unseal(false)
+	c.seal()
+	if got := store.mapStore["bob"]; got != [2]string{"new-key", "new-token"} {
+		t.Errorf("seal() overwrote the secrets of the inactive profile: %v", got)
+	}
+}
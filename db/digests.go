@@ -0,0 +1,104 @@
+package db
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/jackytck/alti-cli/config"
+)
+
+// Digest represents the cached digest of a local image file.
+// It is valid as long as the size and modification time of the file are unchanged.
+type Digest struct {
+	Path        string `storm:"id"` // absolute path
+	Size        int64
+	ModTime     time.Time
+	Filetype    string
+	Width       int
+	Height      int
+	Orientation int  // EXIF orientation, 0 if unknown
+	Oriented    bool // Width and Height account for Orientation
+	Checksum    string
+	Algorithm   string // checksum algorithm
+	SHA1        string // sent to the server, empty if not computed
+}
+
+// DigestCache caches the digests of image files across runs,
+// so that unchanged files are not hashed again.
+type DigestCache struct {
+	db *storm.DB
+}
+
+// CachePath gives the path of the digest cache db under the config directory.
+func CachePath() (string, error) {
+	confDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(confDir, "digests.db"), nil
+}
+
+// OpenDigestCache opens the digest cache db.
+func OpenDigestCache() (*DigestCache, error) {
+	p, err := CachePath()
+	if err != nil {
+		return nil, err
+	}
+	db, err := OpenDB(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Init(&Digest{}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &DigestCache{db}, nil
+}
+
+// ClearDigestCache removes the digest cache db.
+func ClearDigestCache() error {
+	p, err := CachePath()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Get gets the digest of file p if its size and modification time are unchanged,
+// and its checksum is of algorithm algo.
+func (c *DigestCache) Get(p string, size int64, modTime time.Time, algo string) (Digest, bool) {
+	var d Digest
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return d, false
+	}
+	if err = c.db.One("Path", abs, &d); err != nil {
+		return d, false
+	}
+	if d.Size != size || !d.ModTime.Equal(modTime) || d.Algorithm != algo {
+		return d, false
+	}
+	return d, true
+}
+
+// Put saves the digest, replacing the previous one of the same path.
+func (c *DigestCache) Put(d Digest) error {
+	abs, err := filepath.Abs(d.Path)
+	if err != nil {
+		return err
+	}
+	d.Path = abs
+	return c.db.Save(&d)
+}
+
+// Close closes the cache db.
+func (c *DigestCache) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDigestCache(t *testing.T) {
+	p, remove := tempDB(t)
+	defer remove()
+	db, err := OpenDB(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Init(&Digest{}); err != nil {
+		t.Fatal(err)
+	}
+	c := &DigestCache{db}
+	defer c.Close()
+
+	mod := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := Digest{Path: "a.jpg", Size: 10, ModTime: mod, Checksum: "abc", Algorithm: "sha1"}
+	if err = c.Put(d); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		size    int64
+		modTime time.Time
+		algo    string
+		want    bool
+	}{
+		{"unchanged", "a.jpg", 10, mod, "sha1", true},
+		{"resized", "a.jpg", 11, mod, "sha1", false},
+		{"modified", "a.jpg", 10, mod.Add(time.Second), "sha1", false},
+		{"other algorithm", "a.jpg", 10, mod, "xxh64", false},
+		{"unknown", "b.jpg", 10, mod, "sha1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.Get(tt.path, tt.size, tt.modTime, tt.algo)
+			if ok != tt.want {
+				t.Fatalf("Get() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && got.Checksum != d.Checksum {
+				t.Errorf("Get() checksum = %q, want %q", got.Checksum, d.Checksum)
+			}
+		})
+	}
+}
@@ -1,14 +1,27 @@
 package db
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
 	"path"
+	"path/filepath"
+	"time"
 
 	"github.com/asdine/storm"
 	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/rand"
+	bolt "go.etcd.io/bbolt"
 )
 
-// OpenDB opens a storm db from path.
+// lockTimeout is how long to wait for the lock of a db held by another
+// alti-cli, e.g. the shared digest cache of a concurrent import.
+var lockTimeout = time.Second
+
+// OpenDB opens a storm db from path. Return ErrDBLocked if it is held by
+// another alti-cli until lockTimeout.
 func OpenDB(path string) (*storm.DB, error) {
 	if path == "" {
 		p, err := OpenPath()
@@ -17,7 +30,10 @@ func OpenDB(path string) (*storm.DB, error) {
 		}
 		path = p
 	}
-	db, err := storm.Open(path)
+	db, err := storm.Open(path, storm.BoltOptions(0600, &bolt.Options{Timeout: lockTimeout}))
+	if err == bolt.ErrTimeout {
+		return nil, errors.ErrDBLocked
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -41,8 +57,45 @@ func OpenPath() (string, error) {
 	return dbFile, nil
 }
 
+// ResumePath infers a deterministic path under the config directory
+// for storing the upload state of importing dir into project pid.
+// The same pid and dir always give the same path, so that an interrupted
+// import could be resumed.
+func ResumePath(pid, dir string) (string, error) {
+	confDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	resumeDir := path.Join(confDir, "resume")
+	if err := os.MkdirAll(resumeDir, 0755); err != nil {
+		return "", err
+	}
+
+	h := sha1.Sum([]byte(abs))
+	dbFile := fmt.Sprintf("%s-%s.db", pid, hex.EncodeToString(h[:])[:8])
+	return path.Join(resumeDir, dbFile), nil
+}
+
 // AllImage returns  all images via channel.
 func AllImage(db *storm.DB) (<-chan Image, <-chan error) {
+	return filterImage(db, nil)
+}
+
+// UnverifiedImage returns all images that are not yet verified via channel.
+func UnverifiedImage(db *storm.DB) (<-chan Image, <-chan error) {
+	return filterImage(db, func(img Image) bool {
+		return img.Stage != StageVerified
+	})
+}
+
+// filterImage returns images that pass the keep func via channel.
+// If keep is nil, all images are returned.
+func filterImage(db *storm.DB, keep func(Image) bool) (<-chan Image, <-chan error) {
 	ret := make(chan Image)
 	errc := make(chan error, 1)
 
@@ -62,6 +115,9 @@ func AllImage(db *storm.DB) (<-chan Image, <-chan error) {
 				break
 			}
 			for _, img := range imgs {
+				if keep != nil && !keep(img) {
+					continue
+				}
 				ret <- img
 			}
 			skip += limit
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// tempDB gives the path of a db in a new temp dir, and the func removing it.
+func tempDB(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "test.db"), func() { os.RemoveAll(dir) }
+}
+
+func TestOpenDBLocked(t *testing.T) {
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 50 * time.Millisecond
+
+	p, remove := tempDB(t)
+	defer remove()
+	db, err := OpenDB(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err = OpenDB(p); err != errors.ErrDBLocked {
+		t.Errorf("OpenDB() of a locked db error = %v, want %v", err, errors.ErrDBLocked)
+	}
+	if d := time.Since(start); d > 10*lockTimeout {
+		t.Errorf("OpenDB() of a locked db took %v", d)
+	}
+
+	db.Close()
+	db, err = OpenDB(p)
+	if err != nil {
+		t.Fatalf("OpenDB() after close error = %v", err)
+	}
+	db.Close()
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/asdine/storm"
+	"github.com/jackytck/alti-cli/config"
+)
+
+// entryReady is the state of a successfully uploaded file.
+const entryReady = "Ready"
+
+// Session is the record of an upload session in the history db.
+type Session struct {
+	ID int `storm:"id,increment"`
+	Manifest
+	Path   string // path of the json manifest, if written
+	Total  int
+	Failed int
+}
+
+// Upload is the record of an uploaded file of a session in the history db.
+type Upload struct {
+	ID      int `storm:"id,increment"`
+	Session int `storm:"index"`
+	ManifestEntry
+}
+
+// Failed tells if the file is not uploaded and ready.
+func (e ManifestEntry) Failed() bool {
+	return e.Error != "" || e.State != entryReady
+}
+
+// History is the persistent per-user store of all upload sessions.
+type History struct {
+	db *storm.DB
+}
+
+// HistoryPath gives the path of the history db under the config directory.
+func HistoryPath() (string, error) {
+	confDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(confDir, "history.db"), nil
+}
+
+// OpenHistory opens the history db.
+func OpenHistory() (*History, error) {
+	p, err := HistoryPath()
+	if err != nil {
+		return nil, err
+	}
+	db, err := OpenDB(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range []interface{}{&Session{}, &Upload{}} {
+		if err = db.Init(t); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return &History{db}, nil
+}
+
+// Record saves the session of manifest m, written to p, and all of its entries
+// with absolute paths, so that the failed files could be retried from anywhere.
+func (h *History) Record(m *Manifest, p string) (*Session, error) {
+	s := Session{Manifest: *m, Path: p, Total: len(m.Entries)}
+	s.Entries = nil
+	for _, e := range m.Entries {
+		if e.Failed() {
+			s.Failed++
+		}
+	}
+
+	tx, err := h.db.Begin(true)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err = tx.Save(&s); err != nil {
+		return nil, err
+	}
+	for _, e := range m.Entries {
+		if e.Path != "" {
+			if abs, err2 := filepath.Abs(e.Path); err2 == nil {
+				e.Path = abs
+			}
+		}
+		u := Upload{Session: s.ID, ManifestEntry: e}
+		if err = tx.Save(&u); err != nil {
+			return nil, err
+		}
+	}
+	return &s, tx.Commit()
+}
+
+// Sessions gives the latest n sessions, newest first. All sessions are
+// given if n is not positive. Only the sessions of the projects with id
+// prefixed by pid are given.
+func (h *History) Sessions(pid string, n int) ([]Session, error) {
+	var all []Session
+	if err := h.db.All(&all, storm.Reverse()); err != nil {
+		return nil, err
+	}
+	var ret []Session
+	for _, s := range all {
+		if !strings.HasPrefix(s.PID, pid) {
+			continue
+		}
+		ret = append(ret, s)
+		if len(ret) == n {
+			break
+		}
+	}
+	return ret, nil
+}
+
+// Session gives the session of id with all of its entries.
+func (h *History) Session(id int) (*Session, error) {
+	var s Session
+	if err := h.db.One("ID", id, &s); err != nil {
+		return nil, err
+	}
+	var ups []Upload
+	err := h.db.Find("Session", id, &ups)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	for _, u := range ups {
+		s.Entries = append(s.Entries, u.ManifestEntry)
+	}
+	return &s, nil
+}
+
+// Close closes the history db.
+func (h *History) Close() error {
+	return h.db.Close()
+}
@@ -1,5 +1,16 @@
 package db
 
+import "github.com/jackytck/alti-cli/types"
+
+// StageRegistered represents an image registered in the api server.
+const StageRegistered = "Registered"
+
+// StageUploaded represents an image uploaded to the cloud or served for direct upload.
+const StageUploaded = "Uploaded"
+
+// StageVerified represents an image verified as Ready by the api server.
+const StageVerified = "Verified"
+
 // Image represents an image in the db.
 type Image struct {
 	SID       int `storm:"id,increment"`
@@ -10,10 +21,17 @@ type Image struct {
 	Filetype  string
 	URL       string
 	LocalPath string
-	Hash      string
+	Hash      string `storm:"index"`
 	State     string
+	Stage     string // stage reached in the upload pipeline
 	Width     int
 	Height    int
 	GP        float64
+	Meta      *types.ImageMeta // exif metadata sent along the registration
 	Error     string
 }
+
+// IsUploaded tells if the image has been uploaded, verified or not.
+func (i Image) IsUploaded() bool {
+	return i.Stage == StageUploaded || i.Stage == StageVerified
+}
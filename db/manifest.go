@@ -0,0 +1,97 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+)
+
+// ManifestEntry is the record of an uploaded file.
+type ManifestEntry struct {
+	Kind     string `json:"kind"` // image, meta or model
+	Path     string `json:"path"`
+	Filename string `json:"filename"`
+	Checksum string `json:"checksum,omitempty"`
+	ID       string `json:"id"` // iid of image, mid of meta or pid of model
+	State    string `json:"state"`
+	Error    string `json:"error,omitempty"`
+
+	// client-side encryption of the uploaded file, empty if not encrypted
+	Encryption string `json:"encryption,omitempty"`
+	KeyID      string `json:"keyId,omitempty"` // fingerprint of the key
+}
+
+// Manifest is the machine-readable record of an upload session.
+type Manifest struct {
+	PID     string          `json:"pid"`
+	Method  string          `json:"method"`
+	Bucket  string          `json:"bucket,omitempty"`
+	Start   time.Time       `json:"start"`
+	End     time.Time       `json:"end"`
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// NewManifest starts the manifest of an upload session.
+func NewManifest(pid, method, bucket string) *Manifest {
+	return &Manifest{
+		PID:    pid,
+		Method: method,
+		Bucket: bucket,
+		Start:  time.Now(),
+	}
+}
+
+// AddImage adds the record of an uploaded image.
+func (m *Manifest) AddImage(img Image) {
+	m.Entries = append(m.Entries, ManifestEntry{
+		Kind:     "image",
+		Path:     img.LocalPath,
+		Filename: img.Filename,
+		Checksum: img.Hash,
+		ID:       img.IID,
+		State:    img.State,
+		Error:    img.Error,
+	})
+}
+
+// ManifestPath gives the default path of the manifest of project pid,
+// under the config directory.
+func ManifestPath(pid string) (string, error) {
+	confDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s.json", pid, time.Now().Format("20060102-150405"))
+	return path.Join(confDir, "manifests", name), nil
+}
+
+// Save ends the session and writes the manifest to p.
+func (m *Manifest) Save(p string) error {
+	m.End = time.Now()
+	if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, b, 0644)
+}
+
+// LoadManifest reads the manifest of p.
+func LoadManifest(p string) (*Manifest, error) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
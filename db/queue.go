@@ -0,0 +1,208 @@
+package db
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+)
+
+// Stages of the import pipeline, each fed by a queue.
+const (
+	QueueUpload = "upload"
+	QueueCheck  = "check"
+)
+
+// States of a job in a queue.
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// queueBatch is the number of jobs taken from the db at a time.
+const queueBatch = 50
+
+// Job is a unit of work of a stage in the queue, keyed by e.g. the checksum
+// of an image.
+type Job struct {
+	ID       int `storm:"id,increment"`
+	Stage    string
+	Key      string
+	StageKey string `storm:"unique"` // stage/key
+	Slot     string `storm:"index"`  // stage/state
+	State    string
+	Attempts int
+	Error    string
+	Updated  time.Time
+}
+
+// Queue is a disk-backed job queue of a stage of the pipeline, so that the
+// stage is never buffered in memory and could restart from where it left off.
+type Queue struct {
+	db    *storm.DB
+	stage string
+}
+
+// OpenQueue opens the queue of stage in db. The jobs left running by a
+// crashed or interrupted run are put back to pending.
+func OpenQueue(db *storm.DB, stage string) (*Queue, error) {
+	if err := db.Init(&Job{}); err != nil {
+		return nil, err
+	}
+	q := &Queue{db, stage}
+	for {
+		jobs, err := q.find(JobRunning, queueBatch)
+		if err != nil {
+			return nil, err
+		}
+		if len(jobs) == 0 {
+			return q, nil
+		}
+		if err = q.save(jobs, JobPending, ""); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Push enqueues key as pending. A done or failed job of the same key is
+// queued again.
+func (q *Queue) Push(key string) error {
+	var j Job
+	err := q.db.One("StageKey", q.stage+"/"+key, &j)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	if err == storm.ErrNotFound {
+		j = Job{Stage: q.stage, Key: key, StageKey: q.stage + "/" + key}
+	} else if j.State == JobPending {
+		return nil
+	}
+	return q.set(j, JobPending, "")
+}
+
+// Next takes at most n pending jobs and marks them running.
+func (q *Queue) Next(n int) ([]Job, error) {
+	jobs, err := q.find(JobPending, n)
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	for i := range jobs {
+		jobs[i].Attempts++
+	}
+	if err = q.save(jobs, JobRunning, ""); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Done marks the job of key done.
+func (q *Queue) Done(key string) error {
+	return q.finish(key, JobDone, "")
+}
+
+// Fail marks the job of key failed with reason.
+func (q *Queue) Fail(key, reason string) error {
+	return q.finish(key, JobFailed, reason)
+}
+
+// Count gives the number of jobs in state.
+func (q *Queue) Count(state string) (int, error) {
+	return countSlot(q.db, q.stage+"/"+state)
+}
+
+// Images returns the images of the pending jobs via channel, marking them
+// running. The images must be marked by Done or Fail after processing.
+func (q *Queue) Images() (<-chan Image, <-chan error) {
+	ret := make(chan Image)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(ret)
+		defer close(errc)
+
+		for {
+			jobs, err := q.Next(queueBatch)
+			if err != nil {
+				errc <- err
+				return
+			}
+			if len(jobs) == 0 {
+				break
+			}
+			for _, j := range jobs {
+				var img Image
+				err = q.db.One("Hash", j.Key, &img)
+				if err == storm.ErrNotFound {
+					// the image is gone, e.g. by a previous run
+					if err = q.Fail(j.Key, "image not found"); err != nil {
+						errc <- err
+						return
+					}
+					continue
+				}
+				if err != nil {
+					errc <- err
+					return
+				}
+				ret <- img
+			}
+		}
+	}()
+
+	return ret, errc
+}
+
+func (q *Queue) finish(key, state, reason string) error {
+	var j Job
+	if err := q.db.One("StageKey", q.stage+"/"+key, &j); err != nil {
+		return err
+	}
+	return q.set(j, state, reason)
+}
+
+// find gives at most n jobs in state, all of them if n is not positive.
+func (q *Queue) find(state string, n int) ([]Job, error) {
+	var jobs []Job
+	var err error
+	if n > 0 {
+		err = q.db.Find("Slot", q.stage+"/"+state, &jobs, storm.Limit(n))
+	} else {
+		err = q.db.Find("Slot", q.stage+"/"+state, &jobs)
+	}
+	if err == storm.ErrNotFound {
+		err = nil
+	}
+	return jobs, err
+}
+
+// countSlot counts the jobs of slot in db without loading them.
+func countSlot(db *storm.DB, slot string) (int, error) {
+	return db.Select(q.Eq("Slot", slot)).Count(&Job{})
+}
+
+func (q *Queue) set(j Job, state, reason string) error {
+	return q.save([]Job{j}, state, reason)
+}
+
+// save moves jobs to state in a single transaction.
+func (q *Queue) save(jobs []Job, state, reason string) error {
+	tx, err := q.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	now := time.Now()
+	for i := range jobs {
+		j := &jobs[i]
+		j.State = state
+		j.Slot = q.stage + "/" + state
+		j.Error = reason
+		j.Updated = now
+		if err = tx.Save(j); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/asdine/storm"
+)
+
+// tempQueue opens the upload queue of a new temp db, and the func closing
+// and removing it.
+func tempQueue(t *testing.T) (*storm.DB, *Queue, func()) {
+	p, remove := tempDB(t)
+	db, err := OpenDB(p)
+	if err != nil {
+		remove()
+		t.Fatal(err)
+	}
+	q, err := OpenQueue(db, QueueUpload)
+	if err != nil {
+		db.Close()
+		remove()
+		t.Fatal(err)
+	}
+	return db, q, func() {
+		db.Close()
+		remove()
+	}
+}
+
+// wantCounts fails t if the number of jobs of each state of q differs.
+func wantCounts(t *testing.T, q *Queue, want map[string]int) {
+	t.Helper()
+	for _, s := range []string{JobPending, JobRunning, JobDone, JobFailed} {
+		n, err := q.Count(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != want[s] {
+			t.Errorf("Count(%q) = %d, want %d", s, n, want[s])
+		}
+	}
+}
+
+func TestOpenQueueRequeue(t *testing.T) {
+	db, q, done := tempQueue(t)
+	defer done()
+
+	for _, k := range []string{"a", "b", "c"} {
+		if err := q.Push(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobs, err := q.Next(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("Next(2) gives %d jobs, want 2", len(jobs))
+	}
+	if err = q.Done(jobs[0].Key); err != nil {
+		t.Fatal(err)
+	}
+	wantCounts(t, q, map[string]int{JobPending: 1, JobRunning: 1, JobDone: 1})
+
+	// the running job of an interrupted run is pending again
+	q, err = OpenQueue(db, QueueUpload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCounts(t, q, map[string]int{JobPending: 2, JobDone: 1})
+
+	// the queue of another stage is untouched
+	check, err := OpenQueue(db, QueueCheck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCounts(t, check, nil)
+}
+
+func TestQueuePush(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string
+	}{
+		{"pending", JobPending},
+		{"running", JobRunning},
+		{"done", JobDone},
+		{"failed", JobFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, q, done := tempQueue(t)
+			defer done()
+
+			if err := q.Push("a"); err != nil {
+				t.Fatal(err)
+			}
+			if tt.state != JobPending {
+				if _, err := q.Next(1); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var err error
+			switch tt.state {
+			case JobDone:
+				err = q.Done("a")
+			case JobFailed:
+				err = q.Fail("a", "reason")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = q.Push("a"); err != nil {
+				t.Fatalf("Push() error = %v", err)
+			}
+			wantCounts(t, q, map[string]int{JobPending: 1})
+			jobs, err := q.Next(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs) != 1 || jobs[0].Error != "" {
+				t.Errorf("Next(1) after Push() = %+v, want the job without error", jobs)
+			}
+		})
+	}
+}
+
+func TestQueueImages(t *testing.T) {
+	db, q, done := tempQueue(t)
+	defer done()
+
+	if err := db.Init(&Image{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Save(&Image{Filename: "a.jpg", Hash: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"a", "gone"} {
+		if err := q.Push(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	imgc, errc := q.Images()
+	var got []string
+	for img := range imgc {
+		got = append(got, img.Filename)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "a.jpg" {
+		t.Errorf("Images() = %v, want [a.jpg]", got)
+	}
+	// the job of the missing image fails instead of blocking the queue
+	wantCounts(t, q, map[string]int{JobRunning: 1, JobFailed: 1})
+}
@@ -13,16 +13,60 @@ const (
 	ErrErrorCodeInvalid AppError = "app: invalid error code"
 	// ErrInvalidInput is returned when the input value is invalid.
 	ErrInvalidInput AppError = "app: invalid input"
+	// ErrReleaseNotFound is returned when there is no release or binary to update to.
+	ErrReleaseNotFound AppError = "app: release not found"
+	// ErrReleaseUnverified is returned when the signature or checksum of a release is invalid.
+	ErrReleaseUnverified AppError = "app: release signature or checksum mismatch"
+	// ErrReleaseKeyInvalid is returned when the release public key is missing or invalid.
+	ErrReleaseKeyInvalid AppError = "app: invalid release public key"
+	// ErrDoctorFailed is returned when any of the diagnostic checks of doctor fails.
+	ErrDoctorFailed AppError = "app: diagnostic checks failed"
+	// ErrBatchFailed is returned when any of the jobs of a batch fails.
+	ErrBatchFailed AppError = "app: batch jobs failed"
+	// ErrDeadlineExceeded is returned when a command is stopped by its deadline with partial results.
+	ErrDeadlineExceeded AppError = "app: deadline exceeded"
+	// ErrFFmpegNotFound is returned when ffmpeg is not found for extracting the frames of a video.
+	ErrFFmpegNotFound AppError = "app: ffmpeg not found"
+	// ErrDcrawNotFound is returned when dcraw is not found for converting the RAW images.
+	ErrDcrawNotFound AppError = "app: dcraw not found"
+	// ErrDBLocked is returned when a local db is held by another alti-cli.
+	ErrDBLocked AppError = "app: db is in use by another alti-cli"
+	// ErrAuthPending is returned when the device code is not yet confirmed by user.
+	ErrAuthPending LoginError = "login: authorization pending"
+	// ErrSlowDown is returned when the device code is polled too frequently.
+	ErrSlowDown LoginError = "login: slow down"
+	// ErrDeviceCodeExpired is returned when the device code is expired before confirmation.
+	ErrDeviceCodeExpired LoginError = "login: device code expired"
+	// ErrAccessDenied is returned when user denies the login request.
+	ErrAccessDenied LoginError = "login: access denied"
+	// ErrTokenExpired is returned when the user token is expired or rejected, and could not be refreshed.
+	ErrTokenExpired LoginError = "login: token expired, please login again"
 	// ErrProfileNotFound is returned when the queried profile is not found.
 	ErrProfileNotFound ConfigError = "config: profile not found"
 	// ErrProfileNotRemovable is returned when the default profile is chosen to be removed.
 	ErrProfileNotRemovable ConfigError = "config: default profile not removable"
+	// ErrProfileIDInvalid is returned when the new id of a profile is empty, taken or the default one.
+	ErrProfileIDInvalid ConfigError = "config: invalid profile id"
+	// ErrProxyInvalid is returned when the proxy is not a http, https or socks5 url.
+	ErrProxyInvalid ConfigError = "config: invalid proxy"
+	// ErrCACertInvalid is returned when no certificate could be parsed from the CA bundle.
+	ErrCACertInvalid ConfigError = "config: invalid ca cert"
+	// ErrProfileBundleInvalid is returned when an imported file is not a bundle of profiles.
+	ErrProfileBundleInvalid ConfigError = "config: invalid profile bundle"
 	// ErrClientInvisible is returned when the client is invisible to the api server.
 	ErrClientInvisible ConfigError = "client: invisible"
 	// ErrOffline is returned when the server is offline.
 	ErrOffline ServerError = "server: offline"
 	// ErrReadOnly is returned when the server is read-only.
 	ErrReadOnly ServerError = "server: read-only"
+	// ErrClockSkew is returned when the local clock differs too much from the server clock.
+	ErrClockSkew ServerError = "server: clock skew"
+	// ErrSubscriptionUnsupported is returned when the server does not support the gql subscription.
+	ErrSubscriptionUnsupported ServerError = "server: subscription unsupported"
+	// ErrAPITimeout is returned when a gql request is not responded within the api timeout.
+	ErrAPITimeout ServerError = "server: request timeout"
+	// ErrFeatureUnsupported is returned when an optional feature is not supported by the server.
+	ErrFeatureUnsupported ServerError = "server: feature unsupported"
 	// ErrProjCreate is returned when a new project could not be created.
 	ErrProjCreate ProjectError = "project: create"
 	// ErrProjRemove is returned when a project could not be removed.
@@ -31,14 +75,24 @@ const (
 	ErrProjNotFound ProjectError = "project: project not found"
 	// ErrImgNotFound is returned when an image could not be founded in the project.
 	ErrImgNotFound ProjectError = "project: image not found"
+	// ErrImgRemove is returned when an image could not be removed from the project.
+	ErrImgRemove ProjectError = "project: image remove"
 	// ErrMetaNotFound is returned when a meta file could not be founded in the project.
 	ErrMetaNotFound ProjectError = "project: meta file not found"
 	// ErrMetaMisc is returned when a meta file is invalid or duplicated.
 	ErrMetaMisc ProjectError = "project: meta file is invalid or duplicated"
 	// ErrReportProj is returned when a project could not be reported.
 	ErrReportProj ProjectError = "project: report error"
+	// ErrProjUpdate is returned when the info of a project could not be updated.
+	ErrProjUpdate ProjectError = "project: update failed"
 	// ErrTransferProject is returned when transferring a project gives error.
 	ErrTransferProject ProjectError = "project: transfer project failed"
+	// ErrShareProject is returned when sharing a project or revoking its share fails.
+	ErrShareProject ProjectError = "project: share project failed"
+	// ErrDownloadNotFound is returned when a downloadable of the desired format is not found.
+	ErrDownloadNotFound ProjectError = "project: downloadable not found"
+	// ErrDownloadFailed is returned when some downloadables could not be downloaded.
+	ErrDownloadFailed ProjectError = "project: download failed"
 	// ErrFileNotImage is returned when a file is not a supported image.
 	ErrFileNotImage FileError = "file: not image"
 	// ErrFileNotZip is returned when a file is not a zip file.
@@ -53,10 +107,30 @@ const (
 	ErrFileImageDim FileError = "file: unknown image dimension"
 	// ErrFileChecksum is returned when the checksum of a file could not be computed.
 	ErrFileChecksum FileError = "file: unknown checksum"
+	// ErrFileSizeMismatch is returned when the size of a downloaded file is not as expected.
+	ErrFileSizeMismatch FileError = "file: size mismatch"
 	// ErrMetaFilenameInvalid is returned when the filename of meta file is invalid.
 	ErrMetaFilenameInvalid FileError = "file: invalid meta filename"
 	// ErrModelFilenameInvalid is returned when the filename of model file is invalid.
 	ErrModelFilenameInvalid FileError = "file: invalid model filename"
+	// ErrModelInvalid is returned when a model file has no model or misses its references.
+	ErrModelInvalid FileError = "file: invalid model"
+	// ErrDiskSpace is returned when the free disk space is insufficient.
+	ErrDiskSpace FileError = "file: insufficient disk space"
+	// ErrImageFilenameDuplicate is returned when local images of different paths share a filename.
+	ErrImageFilenameDuplicate FileError = "file: duplicate image filename"
+	// ErrEncryptKeyInvalid is returned when the encryption key file is not a 256-bit key.
+	ErrEncryptKeyInvalid FileError = "file: invalid encryption key"
+	// ErrDecrypt is returned when a file could not be decrypted by the key.
+	ErrDecrypt FileError = "file: decryption failed"
+	// ErrRawNotConverted is returned when a RAW image is found without converting it to a standard format.
+	ErrRawNotConverted FileError = "file: RAW image is not converted"
+	// ErrCameraFileInvalid is returned when a camera file of Pix4D, Metashape or OPK csv could not be converted.
+	ErrCameraFileInvalid FileError = "file: invalid camera file"
+	// ErrMetaInvalid is returned when the content of a meta file is invalid.
+	ErrMetaInvalid FileError = "file: invalid meta file"
+	// ErrFileTooLarge is returned when a file exceeds the max file size of the server.
+	ErrFileTooLarge FileError = "file: too large for the server"
 	// ErrImgReg is returned when an image could not be registered for uploading.
 	ErrImgReg UploadError = "upload: cannot register upload image"
 	// ErrImgInvalid is returned when an image is regarded as invalid by the server.
@@ -71,6 +145,8 @@ const (
 	ErrS3Error UploadError = "upload: s3 error"
 	// ErrMinioError is returned when file upload operation could not result in ok status code.
 	ErrMinioError UploadError = "upload: minio error"
+	// ErrGCSError is returned when file upload operation could not result in ok status code.
+	ErrGCSError UploadError = "upload: gcs error"
 	// ErrBucketInvalid is returned when the provided bucket is invalid.
 	ErrBucketInvalid UploadError = "upload: invalid bucket"
 	// ErrNOSTS is returned when a new STS could not be obtained.
@@ -87,10 +163,20 @@ const (
 	ErrMetaReg UploadError = "upload: cannot register meta file"
 	// ErrMetaExisted is returned when a duplicated meta file is attempted to upload.
 	ErrMetaExisted UploadError = "upload: meta file alreay existed"
+	// ErrManifestNotReady is returned when not all of the entries of an upload manifest are ready.
+	ErrManifestNotReady UploadError = "upload: manifest entries not ready"
+	// ErrSessionNotFound is returned when an upload session is not found in the history.
+	ErrSessionNotFound UploadError = "upload: session not found"
+	// ErrChecksumMismatch is returned when the checksum computed by the server differs from the local one.
+	ErrChecksumMismatch UploadError = "upload: checksum mismatch"
+	// ErrMultipartUpload is returned when a multipart upload session could not be started or completed.
+	ErrMultipartUpload UploadError = "upload: multipart upload failed"
 	// ErrTaskStop is returned when a task could not be stopped
 	ErrTaskStop TaskError = "task: task could not be stopped"
 	// ErrTaskTypeInvalid is returned when the provided task type is invalid.
 	ErrTaskTypeInvalid TaskError = "task: invalid task type"
+	// ErrTaskNotFound is returned when the project has no task.
+	ErrTaskNotFound TaskError = "task: task not found"
 	// ErrClientQuery is returned when the input gql query file is not found.
 	ErrClientQuery ClientError = "client: query file not found"
 	// ErrClientVar is returned when the input gql variable file is not found.
@@ -112,6 +198,13 @@ func (e AppError) Error() string {
 	return string(e)
 }
 
+// LoginError is the login specific error.
+type LoginError string
+
+func (e LoginError) Error() string {
+	return string(e)
+}
+
 // ConfigError is the config specific error.
 type ConfigError string
 
@@ -0,0 +1,209 @@
+package errors
+
+import (
+	"log"
+	"os"
+
+	"github.com/jackytck/alti-cli/lifecycle"
+)
+
+// ExitCodeInfo describes the process exit code of an error.
+// Err is nil for the code of an error type.
+type ExitCodeInfo struct {
+	Code int
+	Name string
+	Err  error
+}
+
+// Process exit codes by error type, for errors without a specific code.
+// The specific codes of each type follow its type code up to the next type
+// code, or 99 for NetworkError. Once they are all taken, they continue at the
+// same codes plus 100, e.g. 141 to 154 for FileError.
+const (
+	ExitGeneric = 1
+	ExitApp     = 10
+	ExitLogin   = 16
+	ExitConfig  = 21
+	ExitServer  = 25
+	ExitProject = 30
+	ExitFile    = 41
+	ExitUpload  = 55
+	ExitTask    = 75
+	ExitClient  = 80
+	ExitBank    = 85
+	ExitNetwork = 90
+)
+
+// exitCodes lists the specific exit code of each known error.
+// Codes are never reused, new errors take the next free code of its type.
+var exitCodes = []ExitCodeInfo{
+	{11, "ErrNotImplemented", ErrNotImplemented},
+	{12, "ErrNoConfig", ErrNoConfig},
+	{13, "ErrNotLogin", ErrNotLogin},
+	{14, "ErrErrorCodeInvalid", ErrErrorCodeInvalid},
+	{15, "ErrInvalidInput", ErrInvalidInput},
+	{17, "ErrAuthPending", ErrAuthPending},
+	{18, "ErrSlowDown", ErrSlowDown},
+	{19, "ErrDeviceCodeExpired", ErrDeviceCodeExpired},
+	{20, "ErrAccessDenied", ErrAccessDenied},
+	{22, "ErrProfileNotFound", ErrProfileNotFound},
+	{23, "ErrProfileNotRemovable", ErrProfileNotRemovable},
+	{24, "ErrClientInvisible", ErrClientInvisible},
+	{26, "ErrOffline", ErrOffline},
+	{27, "ErrReadOnly", ErrReadOnly},
+	{28, "ErrClockSkew", ErrClockSkew},
+	{29, "ErrSubscriptionUnsupported", ErrSubscriptionUnsupported},
+	{31, "ErrProjCreate", ErrProjCreate},
+	{32, "ErrProjRemove", ErrProjRemove},
+	{33, "ErrProjNotFound", ErrProjNotFound},
+	{34, "ErrImgNotFound", ErrImgNotFound},
+	{35, "ErrImgRemove", ErrImgRemove},
+	{36, "ErrMetaNotFound", ErrMetaNotFound},
+	{37, "ErrMetaMisc", ErrMetaMisc},
+	{38, "ErrReportProj", ErrReportProj},
+	{39, "ErrTransferProject", ErrTransferProject},
+	{40, "ErrDownloadNotFound", ErrDownloadNotFound},
+	{42, "ErrFileNotImage", ErrFileNotImage},
+	{43, "ErrFileNotZip", ErrFileNotZip},
+	{44, "ErrFileNotDir", ErrFileNotDir},
+	{45, "ErrFileNotDirOrZip", ErrFileNotDirOrZip},
+	{46, "ErrFilesize", ErrFilesize},
+	{47, "ErrFileImageDim", ErrFileImageDim},
+	{48, "ErrFileChecksum", ErrFileChecksum},
+	{49, "ErrFileSizeMismatch", ErrFileSizeMismatch},
+	{50, "ErrMetaFilenameInvalid", ErrMetaFilenameInvalid},
+	{51, "ErrModelFilenameInvalid", ErrModelFilenameInvalid},
+	{52, "ErrModelInvalid", ErrModelInvalid},
+	{53, "ErrDiskSpace", ErrDiskSpace},
+	{54, "ErrImageFilenameDuplicate", ErrImageFilenameDuplicate},
+	{56, "ErrImgReg", ErrImgReg},
+	{57, "ErrImgInvalid", ErrImgInvalid},
+	{58, "ErrClientTimeout", ErrClientTimeout},
+	{59, "ErrUploadMethodInvalid", ErrUploadMethodInvalid},
+	{60, "ErrNoBucketSuggestion", ErrNoBucketSuggestion},
+	{61, "ErrS3Error", ErrS3Error},
+	{62, "ErrMinioError", ErrMinioError},
+	{63, "ErrGCSError", ErrGCSError},
+	{64, "ErrBucketInvalid", ErrBucketInvalid},
+	{65, "ErrNOSTS", ErrNOSTS},
+	{66, "ErrOSSUploaderNotFound", ErrOSSUploaderNotFound},
+	{67, "ErrImgMutateState", ErrImgMutateState},
+	{68, "ErrModelMutateState", ErrModelMutateState},
+	{69, "ErrModelReg", ErrModelReg},
+	{70, "ErrMetaReg", ErrMetaReg},
+	{71, "ErrMetaExisted", ErrMetaExisted},
+	{72, "ErrManifestNotReady", ErrManifestNotReady},
+	{73, "ErrSessionNotFound", ErrSessionNotFound},
+	{74, "ErrChecksumMismatch", ErrChecksumMismatch},
+	{76, "ErrTaskStop", ErrTaskStop},
+	{77, "ErrTaskTypeInvalid", ErrTaskTypeInvalid},
+	{78, "ErrTaskNotFound", ErrTaskNotFound},
+	{81, "ErrClientQuery", ErrClientQuery},
+	{82, "ErrClientVar", ErrClientVar},
+	{83, "ErrClientVarInvalid", ErrClientVarInvalid},
+	{86, "ErrCurrencyInvalid", ErrCurrencyInvalid},
+	{87, "ErrTransferCoins", ErrTransferCoins},
+	{88, "ErrInsufficientCoins", ErrInsufficientCoins},
+	{110, "ErrDcrawNotFound", ErrDcrawNotFound},
+	{111, "ErrDBLocked", ErrDBLocked},
+	{121, "ErrProfileBundleInvalid", ErrProfileBundleInvalid},
+	{125, "ErrFeatureUnsupported", ErrFeatureUnsupported},
+	{141, "ErrRawNotConverted", ErrRawNotConverted},
+	{142, "ErrCameraFileInvalid", ErrCameraFileInvalid},
+	{143, "ErrMetaInvalid", ErrMetaInvalid},
+	{144, "ErrFileTooLarge", ErrFileTooLarge},
+	{145, "ErrEncryptKeyInvalid", ErrEncryptKeyInvalid},
+	{146, "ErrDecrypt", ErrDecrypt},
+	{155, "ErrMultipartUpload", ErrMultipartUpload},
+}
+
+// ExitCodes returns the type and specific exit codes of all known errors.
+func ExitCodes() []ExitCodeInfo {
+	ret := []ExitCodeInfo{
+		{ExitGeneric, "unknown", nil},
+		{ExitApp, "AppError", nil},
+		{ExitLogin, "LoginError", nil},
+		{ExitConfig, "ConfigError", nil},
+		{ExitServer, "ServerError", nil},
+		{ExitProject, "ProjectError", nil},
+		{ExitFile, "FileError", nil},
+		{ExitUpload, "UploadError", nil},
+		{ExitTask, "TaskError", nil},
+		{ExitClient, "ClientError", nil},
+		{ExitBank, "BankError", nil},
+		{ExitNetwork, "NetworkError", nil},
+	}
+	return append(ret, exitCodes...)
+}
+
+// ExitCode gives the process exit code of err, 0 if err is nil.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	for _, e := range exitCodes {
+		if e.Err == err {
+			return e.Code
+		}
+	}
+	return typeExitCode(err)
+}
+
+// typeExitCode gives the exit code of the type of err.
+func typeExitCode(err error) int {
+	switch err.(type) {
+	case AppError:
+		return ExitApp
+	case LoginError:
+		return ExitLogin
+	case ConfigError:
+		return ExitConfig
+	case ServerError:
+		return ExitServer
+	case ProjectError:
+		return ExitProject
+	case FileError:
+		return ExitFile
+	case UploadError:
+		return ExitUpload
+	case TaskError:
+		return ExitTask
+	case ClientError:
+		return ExitClient
+	case BankError:
+		return ExitBank
+	case NetworkError:
+		return ExitNetwork
+	default:
+		return ExitGeneric
+	}
+}
+
+// exiting is the error of the ongoing Exit.
+var exiting error
+
+// Exiting gives the error that the process is exiting with by Exit, e.g. for
+// the cleanup hooks to report it, nil if it is not exiting by Exit.
+func Exiting() error {
+	return exiting
+}
+
+// exitLogger logs the error of Exit.
+var exitLogger = func(err error) {
+	log.Println(err)
+}
+
+// SetExitLogger sets the logger of the error of Exit, e.g. by the logging
+// package, which could not be imported here.
+func SetExitLogger(fn func(err error)) {
+	exitLogger = fn
+}
+
+// Exit logs err, runs the registered cleanup hooks and exits the process with
+// the exit code of err.
+func Exit(err error) {
+	exitLogger(err)
+	exiting = err
+	lifecycle.Cleanup()
+	os.Exit(ExitCode(err))
+}
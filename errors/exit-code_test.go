@@ -0,0 +1,38 @@
+package errors
+
+import "testing"
+
+func TestExitCodes(t *testing.T) {
+	// type codes in ascending order, each followed by its specific codes
+	types := []int{ExitApp, ExitLogin, ExitConfig, ExitServer, ExitProject, ExitFile, ExitUpload, ExitTask, ExitClient, ExitBank, ExitNetwork, 100}
+	inRange := func(code, typeCode int) bool {
+		for i := 0; i < len(types)-1; i++ {
+			if types[i] != typeCode {
+				continue
+			}
+			lo, hi := types[i], types[i+1]
+			return (code > lo && code < hi) || (code >= lo+100 && code < hi+100)
+		}
+		return false
+	}
+
+	seen := make(map[int]string)
+	for _, e := range ExitCodes() {
+		if name, ok := seen[e.Code]; ok {
+			t.Errorf("exit code %d of %s is taken by %s", e.Code, e.Name, name)
+		}
+		seen[e.Code] = e.Name
+		if e.Err == nil {
+			continue
+		}
+		if tc := typeExitCode(e.Err); !inRange(e.Code, tc) {
+			t.Errorf("exit code %d of %s is out of the range of its type code %d", e.Code, e.Name, tc)
+		}
+		if got := ExitCode(e.Err); got != e.Code {
+			t.Errorf("ExitCode(%s) = %d, want %d", e.Name, got, e.Code)
+		}
+		if e.Code > 255 {
+			t.Errorf("exit code %d of %s exceeds 255", e.Code, e.Name)
+		}
+	}
+}
@@ -21,11 +21,15 @@ func MustGQL(err error, endpoint string) string {
 		return fmt.Sprintf("Config not found.\nLogin with 'alti-cli login'")
 	case ErrNotLogin:
 		return fmt.Sprintf("You are not login in!\nLogin with 'alti-cli login' or\nSwith account with 'alti-cli account use XXX'")
+	case ErrTokenExpired:
+		return fmt.Sprintf("Your login has expired!\nLogin again with 'alti-cli login'")
 	case ErrOffline:
 		if endpoint == "" {
 			endpoint = "Endpoint"
 		}
 		return fmt.Sprintf("%s is offline\nCheck status with 'alti-cli account'", endpoint)
+	case ErrAPITimeout:
+		return fmt.Sprintf("Request timeout!\nTry again later or with a longer '--api-timeout'")
 	default:
 		panic(err)
 	}
@@ -0,0 +1,51 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+func init() {
+	Register("csv", NewCSV)
+}
+
+// csvExporter writes the images as rows of filename, hashed name, state and url.
+type csvExporter struct {
+	f *os.File
+	w *csv.Writer
+}
+
+// NewCSV creates a csv exporter writing to path p.
+func NewCSV(p string) (Exporter, error) {
+	f, err := os.Create(p)
+	if err != nil {
+		return nil, err
+	}
+	w := csv.NewWriter(f)
+	if err = w.Write([]string{"Filename", "Hashed Name", "State", "URL"}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &csvExporter{f, w}, nil
+}
+
+func (e *csvExporter) Write(imgs []types.ProjectImage) error {
+	for _, img := range imgs {
+		if err := e.w.Write([]string{img.Name, img.Filename, img.State, img.URL}); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExporter) Close() error {
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
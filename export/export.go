@@ -0,0 +1,50 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+// Exporter writes the images of a project in a format.
+type Exporter interface {
+	// Write writes a batch of images.
+	Write(imgs []types.ProjectImage) error
+	// Close flushes and closes the output.
+	Close() error
+}
+
+// NewFunc creates an exporter writing to path p.
+type NewFunc func(p string) (Exporter, error)
+
+var exporters = make(map[string]NewFunc)
+
+// Register makes an exporter of format available. It panics if format is
+// registered twice.
+func Register(format string, fn NewFunc) {
+	if _, ok := exporters[format]; ok {
+		panic(fmt.Sprintf("export: format %q is registered twice", format))
+	}
+	exporters[format] = fn
+}
+
+// Formats gives the registered formats in order.
+func Formats() []string {
+	var ret []string
+	for f := range exporters {
+		ret = append(ret, f)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// New creates an exporter of format writing to path p.
+func New(format, p string) (Exporter, error) {
+	fn, ok := exporters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("export: unknown format %q", format)
+	}
+	return fn(p)
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+func TestExporters(t *testing.T) {
+	imgs := []types.ProjectImage{
+		{ID: "1", Name: "a.jpg", Filename: "h1.jpg", State: "Ready", URL: "https://a/1"},
+		{ID: "2", Name: "b,c.jpg", Filename: "h2.jpg", State: "Invalid", Grounded: true},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"csv", "Filename,Hashed Name,State,URL\na.jpg,h1.jpg,Ready,https://a/1\n\"b,c.jpg\",h2.jpg,Invalid,\n"},
+		{"jsonl", `{"ID":"1","State":"Ready","URL":"https://a/1","Grounded":false,"Name":"a.jpg","Filename":"h1.jpg"}` + "\n" +
+			`{"ID":"2","State":"Invalid","URL":"","Grounded":true,"Name":"b,c.jpg","Filename":"h2.jpg"}` + "\n"},
+	}
+	dir, err := ioutil.TempDir("", "export-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			p := filepath.Join(dir, "images."+tt.format)
+			e, err := New(tt.format, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, img := range imgs {
+				if err = e.Write([]types.ProjectImage{img}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err = e.Close(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadFile(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("exported %s = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_unknown(t *testing.T) {
+	if _, err := New("xlsx", "images.xlsx"); err == nil {
+		t.Error("New() of unknown format gives no error")
+	}
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+func init() {
+	Register("jsonl", NewJSONL)
+}
+
+// jsonlExporter writes each image as a json object per line.
+type jsonlExporter struct {
+	f   *os.File
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewJSONL creates a json lines exporter writing to path p.
+func NewJSONL(p string) (Exporter, error) {
+	f, err := os.Create(p)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	return &jsonlExporter{f, buf, json.NewEncoder(buf)}, nil
+}
+
+func (e *jsonlExporter) Write(imgs []types.ProjectImage) error {
+	for _, img := range imgs {
+		if err := e.enc.Encode(img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *jsonlExporter) Close() error {
+	if err := e.buf.Flush(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
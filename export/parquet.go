@@ -0,0 +1,67 @@
+package export
+
+import (
+	"os"
+
+	"github.com/jackytck/alti-cli/types"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+func init() {
+	Register("parquet", NewParquet)
+}
+
+// parquetImage is the parquet schema of an image.
+type parquetImage struct {
+	ID       string `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Name     string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Filename string `parquet:"name=filename, type=BYTE_ARRAY, convertedtype=UTF8"`
+	State    string `parquet:"name=state, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Grounded bool   `parquet:"name=grounded, type=BOOLEAN"`
+	URL      string `parquet:"name=url, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// parquetExporter writes the images as a snappy compressed parquet file.
+type parquetExporter struct {
+	f  *os.File
+	pw *writer.ParquetWriter
+}
+
+// NewParquet creates a parquet exporter writing to path p.
+func NewParquet(p string) (Exporter, error) {
+	f, err := os.Create(p)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := writer.NewParquetWriterFromWriter(f, new(parquetImage), 4)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &parquetExporter{f, pw}, nil
+}
+
+func (e *parquetExporter) Write(imgs []types.ProjectImage) error {
+	for _, img := range imgs {
+		err := e.pw.Write(parquetImage{
+			ID:       img.ID,
+			Name:     img.Name,
+			Filename: img.Filename,
+			State:    img.State,
+			Grounded: img.Grounded,
+			URL:      img.URL,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *parquetExporter) Close() error {
+	if err := e.pw.WriteStop(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
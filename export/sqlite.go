@@ -0,0 +1,78 @@
+//go:build cgo
+// +build cgo
+
+package export
+
+import (
+	"database/sql"
+	"os"
+
+	"github.com/jackytck/alti-cli/types"
+	// register the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqlite is registered only in the builds with cgo, as the driver is in C.
+func init() {
+	Register("sqlite", NewSQLite)
+}
+
+// sqliteSchema is the table of images, indexed by state.
+const sqliteSchema = `
+CREATE TABLE images (
+	id TEXT PRIMARY KEY,
+	name TEXT,
+	filename TEXT,
+	state TEXT,
+	grounded INTEGER,
+	url TEXT
+);
+CREATE INDEX images_state ON images (state);
+`
+
+// sqliteExporter writes the images into table 'images' of a sqlite db,
+// a transaction per batch.
+type sqliteExporter struct {
+	db *sql.DB
+}
+
+// NewSQLite creates a sqlite exporter writing to a new db at path p.
+// The existing file of p is replaced.
+func NewSQLite(p string) (Exporter, error) {
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite3", p)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteExporter{db}, nil
+}
+
+func (e *sqliteExporter) Write(imgs []types.ProjectImage) error {
+	tx, err := e.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("INSERT OR REPLACE INTO images (id, name, filename, state, grounded, url) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, img := range imgs {
+		if _, err = stmt.Exec(img.ID, img.Name, img.Filename, img.State, img.Grounded, img.URL); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (e *sqliteExporter) Close() error {
+	return e.db.Close()
+}
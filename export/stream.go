@@ -0,0 +1,90 @@
+package export
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+// PageFetcher fetches the images of the page of index i.
+type PageFetcher func(ctx context.Context, i int) ([]types.ProjectImage, error)
+
+type fetched struct {
+	i    int
+	imgs []types.ProjectImage
+	err  error
+}
+
+// Stream fetches the pages [0, n) by the workers concurrently, and writes them
+// in order of their indexes. At most window pages are fetched but not yet
+// written, bounding the memory used. Stream stops at the first error of fetch
+// or write, or when ctx is done.
+func Stream(ctx context.Context, n, workers, window int, fetch PageFetcher, write func([]types.ProjectImage) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if window < workers {
+		window = workers
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// pages are dispatched in order, each holding a slot until written
+	slots := make(chan struct{}, window)
+	pages := make(chan int)
+	go func() {
+		defer close(pages)
+		for i := 0; i < n; i++ {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case pages <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make(chan fetched)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range pages {
+				imgs, err := fetch(ctx, i)
+				select {
+				case results <- fetched{i, imgs, err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	pending := make(map[int][]types.ProjectImage)
+	for next := 0; next < n; {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				return r.err
+			}
+			pending[r.i] = r.imgs
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		for {
+			imgs, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if err := write(imgs); err != nil {
+				return err
+			}
+			<-slots
+			next++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name            string
+		n, workers, win int
+	}{
+		{"serial", 20, 1, 1},
+		{"concurrent", 50, 8, 16},
+		{"small window", 50, 8, 2},
+		{"no page", 0, 4, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var inFlight, maxInFlight int
+			fetch := func(ctx context.Context, i int) ([]types.ProjectImage, error) {
+				mu.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mu.Unlock()
+				time.Sleep(time.Duration(rand.Intn(2000)) * time.Microsecond)
+				return []types.ProjectImage{{ID: strconv.Itoa(i)}}, nil
+			}
+			var got []string
+			write := func(imgs []types.ProjectImage) error {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				got = append(got, imgs[0].ID)
+				return nil
+			}
+			if err := Stream(context.Background(), tt.n, tt.workers, tt.win, fetch, write); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.n {
+				t.Fatalf("written %d pages, want %d", len(got), tt.n)
+			}
+			for i, id := range got {
+				if id != strconv.Itoa(i) {
+					t.Fatalf("page %d is written as %s", i, id)
+				}
+			}
+			win := tt.win
+			if win < tt.workers {
+				win = tt.workers
+			}
+			if maxInFlight > win {
+				t.Errorf("%d pages are buffered, want at most %d", maxInFlight, win)
+			}
+		})
+	}
+}
+
+func TestStream_error(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	fetch := func(ctx context.Context, i int) ([]types.ProjectImage, error) {
+		if i == 7 {
+			return nil, errFetch
+		}
+		return []types.ProjectImage{{}}, nil
+	}
+	var written int
+	write := func(imgs []types.ProjectImage) error {
+		written++
+		return nil
+	}
+	if err := Stream(context.Background(), 100, 4, 8, fetch, write); err != errFetch {
+		t.Errorf("Stream() error = %v, want %v", err, errFetch)
+	}
+	if written > 7 {
+		t.Errorf("written %d pages, want at most 7", written)
+	}
+}
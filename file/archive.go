@@ -0,0 +1,249 @@
+package file
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ArchiveSep separates the path of a zip archive and the name of a file in
+// it, e.g. 'card.zip!/DCIM/IMG_0001.JPG'.
+const ArchiveSep = "!/"
+
+// ReadSeekCloser is the file opened by OpenFile.
+type ReadSeekCloser interface {
+	io.Reader
+	io.Seeker
+	io.Closer
+}
+
+// archive is an opened zip archive with its files indexed by name.
+type archive struct {
+	f     *os.File
+	files map[string]*zip.File
+	names []string
+}
+
+// archives caches the opened zip archives by path, as the central directory
+// is read only once for all the files in it.
+var archives = struct {
+	sync.Mutex
+	m map[string]*archive
+}{m: make(map[string]*archive)}
+
+// ArchivePath gives the path of the file of name in the zip archive of zipPath.
+func ArchivePath(zipPath, name string) string {
+	return zipPath + ArchiveSep + name
+}
+
+// SplitArchivePath splits p into the path of the zip archive and the name of
+// the file in it. ok is false if p is not a path in an archive.
+func SplitArchivePath(p string) (zipPath, name string, ok bool) {
+	i := strings.Index(strings.ToLower(p), ".zip"+ArchiveSep)
+	if i < 0 {
+		return "", "", false
+	}
+	i += len(".zip")
+	return p[:i], p[i+len(ArchiveSep):], true
+}
+
+// IsZipName tells if the file p is named as a zip file.
+func IsZipName(p string) bool {
+	return strings.EqualFold(filepath.Ext(p), ".zip")
+}
+
+// OpenFile opens the regular file or the file in a zip archive of path p.
+// Files stored without compression are read from the archive directly,
+// compressed ones are decompressed into memory.
+func OpenFile(p string) (ReadSeekCloser, error) {
+	zp, name, ok := SplitArchivePath(p)
+	if !ok {
+		return os.Open(LongPath(p))
+	}
+	a, f, err := archiveEntry(zp, name)
+	if err != nil {
+		return nil, err
+	}
+	if f.Method == zip.Store {
+		if off, err := f.DataOffset(); err == nil {
+			return nopSeekCloser{io.NewSectionReader(a.f, off, int64(f.UncompressedSize64))}, nil
+		}
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	return nopSeekCloser{bytes.NewReader(b)}, nil
+}
+
+// StatFile gives the file info of the regular file or the file in a zip
+// archive of path p.
+func StatFile(p string) (os.FileInfo, error) {
+	zp, name, ok := SplitArchivePath(p)
+	if !ok {
+		return os.Stat(LongPath(p))
+	}
+	_, f, err := archiveEntry(zp, name)
+	if err != nil {
+		return nil, err
+	}
+	return f.FileInfo(), nil
+}
+
+// CloseArchives closes all the zip archives opened by OpenFile and StatFile.
+func CloseArchives() {
+	archives.Lock()
+	defer archives.Unlock()
+	for p, a := range archives.m {
+		a.f.Close()
+		delete(archives.m, p)
+	}
+}
+
+// WalkArchives is WalkFiles that also walks into the zip archives, including
+// root itself. The files in an archive are sent by their ArchivePath.
+// Archives in archives are sent as is.
+func WalkArchives(ctx context.Context, root string, skip string) (<-chan string, <-chan error) {
+	f, err := NewPathFilter(skip, nil, nil, true)
+	if err != nil {
+		paths := make(chan string)
+		errc := make(chan error, 1)
+		errc <- err
+		return paths, errc
+	}
+	return WalkArchivesBy(ctx, root, f)
+}
+
+// zipNameRe matches the name of a zip.
+var zipNameRe = regexp.MustCompile(`(?i)\.zip$`)
+
+// WalkArchivesBy is WalkArchives that selects the files and the entries of
+// the archives by the filter f. The archives are walked unless excluded.
+func WalkArchivesBy(ctx context.Context, root string, f *PathFilter) (<-chan string, <-chan error) {
+	paths := make(chan string)
+	errc := make(chan error, 1)
+
+	send := func(p string) error {
+		if !f.Match(root, p) {
+			return nil
+		}
+		select {
+		case paths <- p:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	// the archives are included for their entries
+	wf := f
+	if f != nil && len(f.Include) > 0 {
+		c := *f
+		c.Include = append(append([]*regexp.Regexp{}, f.Include...), zipNameRe)
+		wf = &c
+	}
+
+	go func() {
+		defer close(paths)
+		files, ferrc := WalkFilesBy(ctx, root, wf)
+		for p := range files {
+			if !IsZipName(p) {
+				if err := send(p); err != nil {
+					errc <- err
+					return
+				}
+				continue
+			}
+			if err := walkArchive(p, send); err != nil {
+				// let the walk end
+				go func() {
+					for range files {
+					}
+				}()
+				errc <- err
+				return
+			}
+		}
+		errc <- <-ferrc
+	}()
+
+	return paths, errc
+}
+
+// walkArchive sends the path of each regular file in the zip archive p.
+func walkArchive(p string, send func(string) error) error {
+	a, err := openArchive(p)
+	if err != nil {
+		return err
+	}
+	for _, name := range a.names {
+		if err := send(ArchivePath(p, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openArchive opens the zip archive of path p, or gets it from the cache.
+func openArchive(p string) (*archive, error) {
+	archives.Lock()
+	defer archives.Unlock()
+	if a, ok := archives.m[p]; ok {
+		return a, nil
+	}
+	f, err := os.Open(LongPath(p))
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	a := &archive{f: f, files: make(map[string]*zip.File)}
+	for _, zf := range zr.File {
+		if zf.Mode().IsRegular() {
+			a.files[zf.Name] = zf
+			a.names = append(a.names, zf.Name)
+		}
+	}
+	archives.m[p] = a
+	return a, nil
+}
+
+// archiveEntry gets the file of name in the zip archive of zipPath.
+func archiveEntry(zipPath, name string) (*archive, *zip.File, error) {
+	a, err := openArchive(zipPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, ok := a.files[name]
+	if !ok {
+		return nil, nil, os.ErrNotExist
+	}
+	return a, f, nil
+}
+
+// nopSeekCloser adds a no-op Close to an io.ReadSeeker.
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error { return nil }
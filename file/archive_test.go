@@ -0,0 +1,110 @@
+package file
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestSplitArchivePath(t *testing.T) {
+	tests := []struct {
+		p, zip, name string
+		ok           bool
+	}{
+		{"a/card.zip!/DCIM/1.jpg", "a/card.zip", "DCIM/1.jpg", true},
+		{"a/CARD.ZIP!/1.jpg", "a/CARD.ZIP", "1.jpg", true},
+		{"a/card.zip", "", "", false},
+		{"a/b!/1.jpg", "", "", false},
+	}
+	for _, tt := range tests {
+		z, n, ok := SplitArchivePath(tt.p)
+		if z != tt.zip || n != tt.name || ok != tt.ok {
+			t.Errorf("SplitArchivePath(%q) = %q, %q, %v", tt.p, z, n, ok)
+		}
+	}
+}
+
+func TestWalkArchives(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer CloseArchives()
+
+	var img bytes.Buffer
+	if err = png.Encode(&img, image.NewGray(image.Rect(0, 0, 40, 30))); err != nil {
+		t.Fatal(err)
+	}
+	card := filepath.Join(dir, "card.zip")
+	f, err := os.Create(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, e := range []struct {
+		name   string
+		method uint16
+	}{
+		{"DCIM/stored.png", zip.Store},
+		{"DCIM/deflated.png", zip.Deflate},
+		{"DCIM/", zip.Store},
+	} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: e.name, Method: e.method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.name != "DCIM/" {
+			w.Write(img.Bytes())
+		}
+	}
+	zw.Close()
+	f.Close()
+	if err = ioutil.WriteFile(filepath.Join(dir, "plain.png"), img.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, errc := WalkArchives(context.Background(), dir, "")
+	var got []string
+	for p := range paths {
+		got = append(got, p)
+	}
+	if err = <-errc; err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	want := []string{
+		ArchivePath(card, "DCIM/deflated.png"),
+		ArchivePath(card, "DCIM/stored.png"),
+		filepath.Join(dir, "plain.png"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("WalkArchives() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("WalkArchives()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	for _, p := range want {
+		w, h, err := GetImageSize(p)
+		if err != nil || w != 40 || h != 30 {
+			t.Errorf("GetImageSize(%q) = %d, %d, %v", p, w, h, err)
+		}
+		size, err := Filesize(p)
+		if err != nil || size != int64(img.Len()) {
+			t.Errorf("Filesize(%q) = %d, %v, want %d", p, size, err, img.Len())
+		}
+	}
+	if _, err = OpenFile(ArchivePath(card, "missing.png")); !os.IsNotExist(err) {
+		t.Errorf("OpenFile of missing file gives %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package file
+
+import "fmt"
+
+// Usage is the giga-pixel and number of images of a project.
+type Usage struct {
+	GP     float64
+	Images int
+}
+
+// SplitByBudget splits imgs, in order, into consecutive parts so that each
+// project stays within maxGP and maxImages, zero means unlimited. The projects
+// of used are filled one by one, followed by new projects, i.e. part i is of
+// project i and the first len(used) parts are of the existing projects.
+// It fails if an image alone exceeds maxGP.
+func SplitByBudget(imgs []ImageDigest, maxGP float64, maxImages int, used []Usage) ([][]ImageDigest, error) {
+	fits := func(u Usage, img ImageDigest) bool {
+		if maxGP > 0 && u.GP+img.GP > maxGP+1e-9 {
+			return false
+		}
+		return maxImages <= 0 || u.Images+1 <= maxImages
+	}
+
+	ret := [][]ImageDigest{nil}
+	var cur Usage
+	if len(used) > 0 {
+		cur = used[0]
+	}
+	for _, img := range imgs {
+		if maxGP > 0 && img.GP > maxGP {
+			return nil, fmt.Errorf("image %q of %.2f GP exceeds the max of %.2f GP", img.Path, img.GP, maxGP)
+		}
+		for !fits(cur, img) {
+			ret = append(ret, nil)
+			cur = Usage{}
+			if i := len(ret) - 1; i < len(used) {
+				cur = used[i]
+			}
+		}
+		ret[len(ret)-1] = append(ret[len(ret)-1], img)
+		cur.GP += img.GP
+		cur.Images++
+	}
+	return ret, nil
+}
@@ -0,0 +1,49 @@
+package file
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitByBudget(t *testing.T) {
+	imgs := []ImageDigest{
+		{Path: "a", GP: 0.4},
+		{Path: "b", GP: 0.4},
+		{Path: "c", GP: 0.4},
+		{Path: "d", GP: 0.2},
+	}
+	tests := []struct {
+		name      string
+		maxGP     float64
+		maxImages int
+		used      []Usage
+		want      [][]string
+		wantErr   bool
+	}{
+		{"unlimited", 0, 0, nil, [][]string{{"a", "b", "c", "d"}}, false},
+		{"gp", 1, 0, nil, [][]string{{"a", "b"}, {"c", "d"}}, false},
+		{"images", 0, 3, nil, [][]string{{"a", "b", "c"}, {"d"}}, false},
+		{"used", 1, 0, []Usage{{GP: 0.5}}, [][]string{{"a"}, {"b", "c", "d"}}, false},
+		{"full", 0, 2, []Usage{{Images: 2}, {Images: 1}}, [][]string{nil, {"a"}, {"b", "c"}, {"d"}}, false},
+		{"too large", 0.3, 0, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := SplitByBudget(imgs, tt.maxGP, tt.maxImages, tt.used)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitByBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got [][]string
+			for _, p := range parts {
+				var ps []string
+				for _, img := range p {
+					ps = append(ps, img.Path)
+				}
+				got = append(got, ps)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitByBudget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
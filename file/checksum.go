@@ -0,0 +1,108 @@
+package file
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/cespare/xxhash"
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// Checksum algorithms of files.
+const (
+	ChecksumSHA1   = "sha1"
+	ChecksumSHA256 = "sha256"
+	ChecksumXXH64  = "xxh64"
+)
+
+// ChecksumAlgorithms are the supported checksum algorithms.
+// xxh64 is the fastest for huge datasets, but is not cryptographic.
+var ChecksumAlgorithms = []string{ChecksumSHA1, ChecksumSHA256, ChecksumXXH64}
+
+// NewHash returns a new hash of the checksum algorithm algo, sha1 if empty.
+func NewHash(algo string) (hash.Hash, error) {
+	switch algo {
+	case "", ChecksumSHA1:
+		return sha1.New(), nil
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	case ChecksumXXH64:
+		return xxhash.New(), nil
+	}
+	return nil, errors.ErrInvalidInput
+}
+
+// Checksum computes the hex checksum of the file by algorithm algo, sha1 if empty.
+func Checksum(file, algo string) (string, error) {
+	sums, err := Checksums(file, algo)
+	if err != nil {
+		return "", err
+	}
+	return sums[0], nil
+}
+
+// Checksums computes the hex checksums of the file by each of algos, sha1 if
+// empty, reading it only once.
+func Checksums(file string, algos ...string) ([]string, error) {
+	hs := make([]hash.Hash, len(algos))
+	ws := make([]io.Writer, len(algos))
+	for i, algo := range algos {
+		h, err := NewHash(algo)
+		if err != nil {
+			return nil, err
+		}
+		hs[i], ws[i] = h, h
+	}
+	f, err := OpenFile(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := io.Copy(io.MultiWriter(ws...), f); err != nil {
+		return nil, err
+	}
+	ret := make([]string, len(hs))
+	for i, h := range hs {
+		ret[i] = hex.EncodeToString(h.Sum(nil))
+	}
+	return ret, nil
+}
+
+// ChecksumAlgorithmOf guesses the algorithm of the hex checksum sum by its
+// length. Return empty if unknown.
+func ChecksumAlgorithmOf(sum string) string {
+	switch len(sum) {
+	case 2 * sha1.Size:
+		return ChecksumSHA1
+	case 2 * sha256.Size:
+		return ChecksumSHA256
+	case 16:
+		return ChecksumXXH64
+	}
+	return ""
+}
+
+// VerifyChecksum compares the local checksum of file p with the checksum
+// remote computed elsewhere. If remote is of another algorithm, p is
+// digested again by it. Return ErrChecksumMismatch if they differ.
+func VerifyChecksum(p, local, remote string) error {
+	if len(local) != len(remote) {
+		algo := ChecksumAlgorithmOf(remote)
+		if algo == "" {
+			return errors.ErrChecksumMismatch
+		}
+		sum, err := Checksum(p, algo)
+		if err != nil {
+			return err
+		}
+		local = sum
+	}
+	if !strings.EqualFold(local, remote) {
+		return errors.ErrChecksumMismatch
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "abc.txt")
+	if err = ioutil.WriteFile(p, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		algo    string
+		want    string
+		wantErr bool
+	}{
+		{"", "a9993e364706816aba3e25717850c26c9cd0d89d", false},
+		{ChecksumSHA1, "a9993e364706816aba3e25717850c26c9cd0d89d", false},
+		{ChecksumSHA256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", false},
+		{ChecksumXXH64, "44bc2cf5ad770999", false},
+		{"md5", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algo, func(t *testing.T) {
+			got, err := Checksum(p, tt.algo)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Checksum() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Checksum() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "abc.txt")
+	if err = ioutil.WriteFile(p, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Checksums(p, ChecksumXXH64, ChecksumSHA1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"44bc2cf5ad770999", "a9993e364706816aba3e25717850c26c9cd0d89d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Checksums() = %v, want %v", got, want)
+	}
+	if _, err = Checksums(p, ChecksumSHA1, "md5"); err == nil {
+		t.Error("Checksums() of an unknown algorithm error = nil")
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "abc.txt")
+	if err = ioutil.WriteFile(p, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sha1sum := "a9993e364706816aba3e25717850c26c9cd0d89d"
+
+	tests := []struct {
+		local   string
+		remote  string
+		wantErr bool
+	}{
+		{sha1sum, sha1sum, false},
+		{sha1sum, strings.ToUpper(sha1sum), false},
+		{sha1sum, "a9993e364706816aba3e25717850c26c9cd0d89e", true},
+		{"44bc2cf5ad770999", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", false},
+		{sha1sum, "44bc2cf5ad770998", true},
+		{sha1sum, "abc", true},
+	}
+	for _, tt := range tests {
+		err := VerifyChecksum(p, tt.local, tt.remote)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("VerifyChecksum(%q, %q) error = %v, wantErr %v", tt.local, tt.remote, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package file
+
+import "syscall"
+
+// DiskFree gives the free bytes available to the user on the disk of path p.
+func DiskFree(p string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(p, &st); err != nil {
+		return 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
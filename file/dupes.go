@@ -0,0 +1,62 @@
+package file
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dupes are the images of the same checksum, sorted by path. The first one is
+// kept, and the rest are its duplicates.
+type Dupes []ImageDigest
+
+// FindDupes groups imgs by checksum, giving the groups of more than one image,
+// sorted by the path of the kept one.
+func FindDupes(imgs []ImageDigest) []Dupes {
+	bySum := make(map[string]Dupes)
+	for _, img := range imgs {
+		if img.Checksum == "" {
+			continue
+		}
+		bySum[img.Checksum] = append(bySum[img.Checksum], img)
+	}
+	var ret []Dupes
+	for _, d := range bySum {
+		if len(d) < 2 {
+			continue
+		}
+		sort.Slice(d, func(i, j int) bool {
+			return d[i].Path < d[j].Path
+		})
+		ret = append(ret, d)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i][0].Path < ret[j][0].Path
+	})
+	return ret
+}
+
+// DuplicateFilenames gives the filenames shared by more than one of paths,
+// compared case-insensitively as on Windows and most NAS shares, e.g.
+// IMG_0001.JPG and img_0001.jpg of different directories. Each is named as of
+// its first path, sorted.
+func DuplicateFilenames(paths []string) []string {
+	first := make(map[string]string)
+	count := make(map[string]int)
+	for _, p := range paths {
+		n := filepath.Base(p)
+		k := strings.ToLower(n)
+		if _, ok := first[k]; !ok {
+			first[k] = n
+		}
+		count[k]++
+	}
+	var ret []string
+	for k, c := range count {
+		if c > 1 {
+			ret = append(ret, first[k])
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
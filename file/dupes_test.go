@@ -0,0 +1,53 @@
+package file
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDupes(t *testing.T) {
+	imgs := []ImageDigest{
+		{Path: "d/4.jpg", Checksum: "b"},
+		{Path: "c/3.jpg", Checksum: "a"},
+		{Path: "a/1.jpg", Checksum: "a"},
+		{Path: "b/2.jpg", Checksum: "c"},
+		{Path: "b/5.jpg", Checksum: "b"},
+		{Path: "e/6.jpg", Checksum: "a"},
+		{Path: "f/7.jpg"},
+		{Path: "f/8.jpg"},
+	}
+	var got [][]string
+	for _, d := range FindDupes(imgs) {
+		var ps []string
+		for _, img := range d {
+			ps = append(ps, img.Path)
+		}
+		got = append(got, ps)
+	}
+	want := [][]string{
+		{"a/1.jpg", "c/3.jpg", "e/6.jpg"},
+		{"b/5.jpg", "d/4.jpg"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDupes() = %v, want %v", got, want)
+	}
+}
+
+func TestDuplicateFilenames(t *testing.T) {
+	paths := []string{
+		"nadir/IMG_0001.JPG",
+		"oblique/img_0001.jpg",
+		"nadir/IMG_0002.JPG",
+		"oblique/IMG_0003.JPG",
+		"extra/IMG_0003.JPG",
+		"extra/IMG_0004.JPG",
+	}
+	got := DuplicateFilenames(paths)
+	want := []string{"IMG_0001.JPG", "IMG_0003.JPG"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DuplicateFilenames() = %q, want %q", got, want)
+	}
+	if got := DuplicateFilenames(paths[:1]); got != nil {
+		t.Errorf("DuplicateFilenames() = %q, want none", got)
+	}
+}
@@ -0,0 +1,196 @@
+package file
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// EncryptAlgorithm is the algorithm of the client-side encryption.
+const EncryptAlgorithm = "AES-256-GCM"
+
+// encryptMagic starts every encrypted file, followed by the nonce prefix.
+const encryptMagic = "ALTIENC1"
+
+// encryptChunk is the size of the plaintext chunks, each sealed separately so
+// that large models are streamed instead of loaded into memory.
+const encryptChunk = 1 << 20
+
+// ReadKeyFile reads the 256-bit key of p, given as 32 raw bytes, 64 hex digits
+// or base64.
+func ReadKeyFile(p string) ([]byte, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 32 {
+		return b, nil
+	}
+	s := strings.TrimSpace(string(b))
+	if k, err := hex.DecodeString(s); err == nil && len(k) == 32 {
+		return k, nil
+	}
+	if k, err := base64.StdEncoding.DecodeString(s); err == nil && len(k) == 32 {
+		return k, nil
+	}
+	return nil, errors.ErrEncryptKeyInvalid
+}
+
+// KeyID gives the fingerprint of key, i.e. the first 8 bytes of its sha256,
+// for telling which key a file is encrypted with without revealing it.
+func KeyID(key []byte) string {
+	h := sha256.Sum256(key)
+	return hex.EncodeToString(h[:8])
+}
+
+// IsEncrypted tells if the file p is encrypted by EncryptFile.
+func IsEncrypted(p string) (bool, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	b := make([]byte, len(encryptMagic))
+	if _, err = io.ReadFull(f, b); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return string(b) == encryptMagic, nil
+}
+
+// EncryptFile encrypts src into dst by key in AES-256-GCM.
+func EncryptFile(src, dst string, key []byte) error {
+	return transformFile(src, dst, key, Encrypt)
+}
+
+// DecryptFile decrypts src, which is encrypted by EncryptFile, into dst by key.
+// A wrong key or a tampered or truncated file gives ErrDecrypt.
+func DecryptFile(src, dst string, key []byte) error {
+	return transformFile(src, dst, key, Decrypt)
+}
+
+// transformFile writes the output of fn on src into dst, removing dst on error.
+func transformFile(src, dst string, key []byte, fn func(io.Writer, io.Reader, []byte) error) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	err = fn(out, in, key)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+	}
+	return err
+}
+
+// Encrypt writes the encryption of r by key into w. The plaintext is sealed in
+// chunks, each with the nonce of its index and tagged if it is the last, so
+// that chunks could not be reordered or dropped. The last chunk is shorter
+// than a full one, and is empty if the plaintext fills the last full one.
+func Encrypt(w io.Writer, r io.Reader, key []byte) error {
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	prefix := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(prefix); err != nil {
+		return err
+	}
+	if _, err = w.Write(append([]byte(encryptMagic), prefix...)); err != nil {
+		return err
+	}
+
+	buf := make([]byte, encryptChunk)
+	var out []byte
+	for i := uint64(0); ; i++ {
+		n, err := io.ReadFull(r, buf)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return err
+		}
+		out = aead.Seal(out[:0], chunkNonce(prefix, i), buf[:n], chunkAD(last))
+		if _, err = w.Write(out); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// Decrypt writes the decryption of r, which is written by Encrypt, into w.
+func Decrypt(w io.Writer, r io.Reader, key []byte) error {
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	head := make([]byte, len(encryptMagic)+aead.NonceSize())
+	if _, err = io.ReadFull(r, head); err != nil || string(head[:len(encryptMagic)]) != encryptMagic {
+		return errors.ErrDecrypt
+	}
+	prefix := head[len(encryptMagic):]
+
+	buf := make([]byte, encryptChunk+aead.Overhead())
+	var out []byte
+	for i := uint64(0); ; i++ {
+		n, err := io.ReadFull(r, buf)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return err
+		}
+		out, err = aead.Open(out[:0], chunkNonce(prefix, i), buf[:n], chunkAD(last))
+		if err != nil {
+			return errors.ErrDecrypt
+		}
+		if _, err = w.Write(out); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil || len(key) != 32 {
+		return nil, errors.ErrEncryptKeyInvalid
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce gives the nonce of the i-th chunk, i.e. prefix xor i.
+func chunkNonce(prefix []byte, i uint64) []byte {
+	nonce := append([]byte(nil), prefix...)
+	var c [8]byte
+	binary.BigEndian.PutUint64(c[:], i)
+	for j := range c {
+		nonce[len(nonce)-8+j] ^= c[j]
+	}
+	return nonce
+}
+
+// chunkAD gives the additional data of a chunk, telling if it is the last.
+func chunkAD(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
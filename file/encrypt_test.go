@@ -0,0 +1,53 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+func TestEncrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"empty", 0},
+		{"small", 10},
+		{"one chunk", encryptChunk},
+		{"chunks", 2*encryptChunk + 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain := bytes.Repeat([]byte("a"), tt.size)
+			var enc bytes.Buffer
+			if err := Encrypt(&enc, bytes.NewReader(plain), key); err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(enc.Bytes(), []byte("aaaaaaaa")) {
+				t.Error("Encrypt() leaks the plaintext")
+			}
+			var dec bytes.Buffer
+			if err := Decrypt(&dec, bytes.NewReader(enc.Bytes()), key); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(dec.Bytes(), plain) {
+				t.Errorf("Decrypt() gives %d bytes, want %d", dec.Len(), len(plain))
+			}
+
+			wrong := bytes.Repeat([]byte{8}, 32)
+			if err := Decrypt(&bytes.Buffer{}, bytes.NewReader(enc.Bytes()), wrong); err != errors.ErrDecrypt {
+				t.Errorf("Decrypt() by wrong key error = %v, want %v", err, errors.ErrDecrypt)
+			}
+			truncated := enc.Bytes()[:enc.Len()-1]
+			if err := Decrypt(&bytes.Buffer{}, bytes.NewReader(truncated), key); err != errors.ErrDecrypt {
+				t.Errorf("Decrypt() of truncated error = %v, want %v", err, errors.ErrDecrypt)
+			}
+		})
+	}
+
+	if err := Encrypt(&bytes.Buffer{}, bytes.NewReader(nil), []byte("short")); err != errors.ErrEncryptKeyInvalid {
+		t.Errorf("Encrypt() by short key error = %v, want %v", err, errors.ErrEncryptKeyInvalid)
+	}
+}
@@ -0,0 +1,97 @@
+package file
+
+import (
+	"time"
+
+	"github.com/jackytck/alti-cli/types"
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// ExifInfo represents the EXIF of an image used for generating meta files.
+type ExifInfo struct {
+	HasGPS      bool
+	Lat         float64
+	Lng         float64
+	Alt         float64   // in meters, 0 if unknown
+	FocalLength float64   // in mm, 0 if unknown
+	Orientation int       // 1-8, 0 if unknown
+	Time        time.Time // zero if unknown
+}
+
+// ReadExif parses the GPS, focal length, orientation and timestamp from the EXIF of an image.
+// Missing fields are left as zero.
+func ReadExif(path string) (*ExifInfo, error) {
+	f, err := OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	x, err := exif.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret ExifInfo
+	if lat, lng, err := x.LatLong(); err == nil {
+		ret.HasGPS = true
+		ret.Lat = lat
+		ret.Lng = lng
+	}
+	if alt, err := ratTag(x, exif.GPSAltitude); err == nil {
+		// 1 means below sea level
+		if ref, err := x.Get(exif.GPSAltitudeRef); err == nil {
+			if r, err := ref.Int(0); err == nil && r == 1 {
+				alt = -alt
+			}
+		}
+		ret.Alt = alt
+	}
+	if fl, err := ratTag(x, exif.FocalLength); err == nil {
+		ret.FocalLength = fl
+	}
+	if tag, err := x.Get(exif.Orientation); err == nil {
+		if o, err := tag.Int(0); err == nil {
+			ret.Orientation = o
+		}
+	}
+	if t, err := x.DateTime(); err == nil {
+		ret.Time = t
+	}
+	return &ret, nil
+}
+
+// ImageMeta gives the metadata of the image to send along its registration,
+// nil if none is known.
+func (e *ExifInfo) ImageMeta() *types.ImageMeta {
+	if e == nil {
+		return nil
+	}
+	var ret types.ImageMeta
+	if e.HasGPS {
+		ret.GPS = &types.GPS{Lat: e.Lat, Lng: e.Lng, Alt: e.Alt}
+	}
+	ret.Orientation = e.Orientation
+	if !e.Time.IsZero() {
+		t := e.Time
+		ret.CaptureTime = &t
+	}
+	if ret.GPS == nil && ret.Orientation == 0 && ret.CaptureTime == nil {
+		return nil
+	}
+	return &ret
+}
+
+// ratTag gets the first rational value of the tag as float.
+func ratTag(x *exif.Exif, name exif.FieldName) (float64, error) {
+	tag, err := x.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	r, err := tag.Rat(0)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := r.Float64()
+	return f, nil
+}
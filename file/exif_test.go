@@ -0,0 +1,34 @@
+package file
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+func TestExifInfoImageMeta(t *testing.T) {
+	at := time.Date(2019, 7, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		exif *ExifInfo
+		want *types.ImageMeta
+	}{
+		{"nil", nil, nil},
+		{"empty", &ExifInfo{FocalLength: 24}, nil},
+		{"orientation", &ExifInfo{Orientation: 6}, &types.ImageMeta{Orientation: 6}},
+		{
+			"all",
+			&ExifInfo{HasGPS: true, Lat: 22.3, Lng: 114.2, Alt: 80, Orientation: 1, Time: at},
+			&types.ImageMeta{GPS: &types.GPS{Lat: 22.3, Lng: 114.2, Alt: 80}, Orientation: 1, CaptureTime: &at},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.exif.ImageMeta(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ImageMeta() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package file
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ImageGroup is a capture group of the images of a top-level subdirectory,
+// e.g. of a nadir or an oblique flight.
+type ImageGroup struct {
+	ID     int
+	Name   string   // subdirectory, empty for the root
+	Images []string // filenames, sorted
+}
+
+// GroupBySubdir groups the images by the top-level subdirectories of their
+// relative urls, numbered from 0 in the order of names.
+// It fails if two images are of the same filename, ignoring case, which is
+// ambiguous in group.txt, or if a filename has spaces.
+func GroupBySubdir(imgs []ImageDigest) ([]ImageGroup, error) {
+	byName := make(map[string]*ImageGroup)
+	taken := make(map[string]string)
+	for _, img := range imgs {
+		rel := strings.TrimPrefix(filepath.ToSlash(strings.Replace(img.URL, "%20", " ", -1)), "/")
+		var name string
+		if dir := path.Dir(rel); dir != "." {
+			name = strings.SplitN(dir, "/", 2)[0]
+		}
+		if strings.ContainsAny(img.Filename, " \t") {
+			return nil, fmt.Errorf("filename %q has spaces, which is invalid in group.txt", rel)
+		}
+		// filenames differing only by case collide on Windows and most NAS shares
+		key := strings.ToLower(img.Filename)
+		if other, ok := taken[key]; ok {
+			return nil, fmt.Errorf("filename %q is in both %q and %q", img.Filename, other, rel)
+		}
+		taken[key] = rel
+
+		g, ok := byName[name]
+		if !ok {
+			g = &ImageGroup{Name: name}
+			byName[name] = g
+		}
+		g.Images = append(g.Images, img.Filename)
+	}
+
+	var ret []ImageGroup
+	for _, g := range byName {
+		sort.Strings(g.Images)
+		ret = append(ret, *g)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	for i := range ret {
+		ret[i].ID = i
+	}
+	return ret, nil
+}
+
+// WriteGroup writes the groups in the format of group.txt, i.e. one
+// 'filename group' per line, sorted by filename.
+func WriteGroup(w io.Writer, groups []ImageGroup) error {
+	ids := make(map[string]int)
+	var names []string
+	for _, g := range groups {
+		for _, f := range g.Images {
+			ids[f] = g.ID
+			names = append(names, f)
+		}
+	}
+	sort.Strings(names)
+	for _, f := range names {
+		if _, err := fmt.Fprintf(w, "%s %d\n", f, ids[f]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadGroup reads the group of each filename of group.txt, i.e. one
+// 'filename group' per line. Blank lines are skipped.
+func ReadGroup(r io.Reader) (map[string]string, error) {
+	ret := make(map[string]string)
+	s := bufio.NewScanner(r)
+	for i := 1; s.Scan(); i++ {
+		toks := strings.Fields(s.Text())
+		if len(toks) == 0 {
+			continue
+		}
+		if len(toks) != 2 {
+			return nil, fmt.Errorf("invalid line %d of group.txt: %q", i, s.Text())
+		}
+		ret[toks[0]] = toks[1]
+	}
+	return ret, s.Err()
+}
@@ -0,0 +1,71 @@
+package file
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGroupBySubdir(t *testing.T) {
+	imgs := []ImageDigest{
+		{URL: "/oblique/north/c.jpg", Filename: "c.jpg"},
+		{URL: "/nadir/b.jpg", Filename: "b.jpg"},
+		{URL: "/root.jpg", Filename: "root.jpg"},
+		{URL: "/nadir/a.jpg", Filename: "a.jpg"},
+		{URL: "/oblique%20south/d.jpg", Filename: "d.jpg"},
+	}
+	groups, err := GroupBySubdir(imgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ImageGroup{
+		{0, "", []string{"root.jpg"}},
+		{1, "nadir", []string{"a.jpg", "b.jpg"}},
+		{2, "oblique", []string{"c.jpg"}},
+		{3, "oblique south", []string{"d.jpg"}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("GroupBySubdir() = %v, want %v", groups, want)
+	}
+
+	var b bytes.Buffer
+	if err = WriteGroup(&b, groups); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "a.jpg 1\nb.jpg 1\nc.jpg 2\nd.jpg 3\nroot.jpg 0\n" {
+		t.Errorf("WriteGroup() = %q", got)
+	}
+
+	dup := append(imgs, ImageDigest{URL: "/oblique/a.jpg", Filename: "a.jpg"})
+	if _, err = GroupBySubdir(dup); err == nil {
+		t.Error("GroupBySubdir() of duplicated filenames expects error")
+	}
+	dup = append(imgs, ImageDigest{URL: "/oblique/B.JPG", Filename: "B.JPG"})
+	if _, err = GroupBySubdir(dup); err == nil {
+		t.Error("GroupBySubdir() of filenames differing by case expects error")
+	}
+}
+
+func TestReadGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"groups", "a.jpg 0\n\nb.jpg  1\n", map[string]string{"a.jpg": "0", "b.jpg": "1"}, false},
+		{"empty", "", map[string]string{}, false},
+		{"invalid", "a.jpg\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadGroup(bytes.NewBufferString(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadGroup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadGroup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,25 +2,23 @@ package file
 
 import (
 	"bufio"
-	"crypto/sha1"
+	"context"
 	"encoding/base64"
-	"encoding/hex"
-	"errors"
 	"fmt"
 	"image"
+	"io"
 	"io/ioutil"
-	"log"
 	"math"
-	"regexp"
 
 	// for image.DecodeConfig
 	_ "image/jpeg"
 	_ "image/png"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/jackytck/alti-cli/logging"
 )
 
 // DimToGigaPixel computes the giga-pixel from width and height.
@@ -36,19 +34,26 @@ func max(x, y int) int {
 }
 
 // GetImageSize decodes the width and height of an image.
+// WebP and HEIC/HEIF are decoded from their headers.
 func GetImageSize(img string) (int, int, error) {
-	valid, err := IsImageFile(img)
+	t, err := GuessFileType(img)
 	if err != nil {
 		return 0, 0, err
 	}
-	if !valid {
+	if !strings.Contains(t, "image/") {
 		return 0, 0, nil
 	}
-	f, err := os.Open(img)
+	f, err := OpenFile(img)
 	if err != nil {
 		return 0, 0, err
 	}
 	defer f.Close()
+	switch t {
+	case "image/webp":
+		return webpSize(f)
+	case "image/heic", "image/heif", "image/heic-sequence", "image/heif-sequence":
+		return heifSize(f)
+	}
 	i, _, err := image.DecodeConfig(f)
 	if err != nil {
 		return 0, 0, err
@@ -114,41 +119,32 @@ func IsZipFile(f string) (bool, error) {
 // GuessFileType guesses the type of file.
 func GuessFileType(file string) (string, error) {
 	buff := make([]byte, 512)
-	f, err := os.Open(file)
+	f, err := OpenFile(file)
 	if err != nil {
 		return "", err
 	}
 	defer f.Close()
-	_, err = f.Read(buff)
+	n, err := f.Read(buff)
 	if err != nil {
 		return "", err
 	}
+	if t := heifType(buff[:n]); t != "" {
+		return t, nil
+	}
+	if t := rawType(buff[:n], filepath.Ext(file)); t != "" {
+		return t, nil
+	}
 	return http.DetectContentType(buff), nil
 }
 
 // Sha1sum computes the sha1sum of the file.
 func Sha1sum(file string) (string, error) {
-	f, err := os.Open(file)
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
-	h := sha1.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(h.Sum(nil)), nil
+	return Checksum(file, ChecksumSHA1)
 }
 
-// Filesize returns the filesize in bytes of a file.
+// Filesize returns the filesize in bytes of a file, or of a file in a zip archive.
 func Filesize(file string) (int64, error) {
-	f, err := os.Open(file)
-	if err != nil {
-		return 0, err
-	}
-	defer f.Close()
-
-	stat, err := f.Stat()
+	stat, err := StatFile(file)
 	if err != nil {
 		return 0, err
 	}
@@ -164,33 +160,51 @@ func BytesToMB(bytes int64) float64 {
 
 // WalkFiles starts a goroutine to walk the directory tree at root and send the
 // path of each regular file on the string channel.  It sends the result of the
-// walk on the error channel. If done is closed, walkFiles abandons its work.
+// walk on the error channel. If ctx is canceled, WalkFiles abandons its work
+// and sends the error of ctx.
 // skip is a regular expression pattern used for skipping paths. Would not skip
 // if it is an empty string.
-func WalkFiles(done <-chan struct{}, root string, skip string) (<-chan string, <-chan error) {
-	paths := make(chan string)
-	errc := make(chan error, 1)
-
-	r, err := regexp.Compile(skip)
+func WalkFiles(ctx context.Context, root string, skip string) (<-chan string, <-chan error) {
+	f, err := NewPathFilter(skip, nil, nil, true)
 	if err != nil {
+		paths := make(chan string)
+		errc := make(chan error, 1)
 		errc <- err
 		return paths, errc
 	}
+	return WalkFilesBy(ctx, root, f)
+}
+
+// WalkFilesBy is WalkFiles that selects the paths by the filter f, skipping
+// the directories excluded by f. A nil f selects all.
+// On Windows, the extended-length form of root is walked, so that the deep
+// directories of the NAS shares are not cut by MAX_PATH, but the paths are
+// still sent under root as given.
+func WalkFilesBy(ctx context.Context, root string, f *PathFilter) (<-chan string, <-chan error) {
+	paths := make(chan string)
+	errc := make(chan error, 1)
+	walkRoot := LongPath(root)
 
 	onWalk := func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if walkRoot != root {
+			path = underRoot(root, walkRoot, path)
+		}
+		if info.IsDir() && f.SkipDir(root, path) {
+			return filepath.SkipDir
+		}
 		if !info.Mode().IsRegular() {
 			return nil
 		}
-		if skip != "" && r.MatchString(path) {
+		if !f.Match(root, path) {
 			return nil
 		}
 		select {
 		case paths <- path:
-		case <-done:
-			return errors.New("walk canceled")
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 		return nil
 	}
@@ -199,12 +213,21 @@ func WalkFiles(done <-chan struct{}, root string, skip string) (<-chan string, <
 		// Close the paths channel after Walk returns.
 		defer close(paths)
 		// No select needed for this send, since errc is buffered.
-		errc <- filepath.Walk(root, onWalk)
+		errc <- filepath.Walk(walkRoot, onWalk)
 	}()
 
 	return paths, errc
 }
 
+// underRoot gives the path p walked from walkRoot as if it is walked from root.
+func underRoot(root, walkRoot, p string) string {
+	rel := strings.TrimLeft(strings.TrimPrefix(p, walkRoot), `\/`)
+	if rel == "" {
+		return root
+	}
+	return filepath.Join(root, rel)
+}
+
 // SplitFile splits the file into parts and put it in the outDir.
 // Each part would have chunkSize number of bytes.
 // If chunkSize is larger than filesize, do nothing.
@@ -256,7 +279,7 @@ func SplitFile(file, outDir string, chunkSize int64, verbose bool) ([]string, er
 		partPath := fmt.Sprintf("%s/%s", outDir, partName)
 
 		if verbose {
-			log.Printf("Writing %q\n", partName)
+			logging.Infof("Writing %q\n", partName)
 		}
 
 		err = ioutil.WriteFile(partPath, buf, 0644)
@@ -317,6 +340,35 @@ func EnsureDir(p string, perm os.FileMode) error {
 	return nil
 }
 
+// writeAtomic writes dst by write, via a unique temp file in the same
+// directory renamed to dst once written, so that a partial dst is never read,
+// even if dst is written concurrently.
+func writeAtomic(dst string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	err = write(f)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
 // ReadFile reads a text file from path.
 func ReadFile(path string) ([]string, error) {
 	var ret []string
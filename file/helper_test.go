@@ -3,6 +3,7 @@ package file
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"image"
 	"image/color"
 	"image/draw"
@@ -10,6 +11,7 @@ import (
 	_ "image/jpeg"
 	"image/png"
 	_ "image/png"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -208,8 +210,7 @@ func TestSha1sum(t *testing.T) {
 }
 
 func TestWalkFiles(t *testing.T) {
-	done := make(chan struct{})
-	paths, errc := WalkFiles(done, testImgDir, "")
+	paths, errc := WalkFiles(context.Background(), testImgDir, "")
 	var got []string
 	for p := range paths {
 		got = append(got, p)
@@ -224,8 +225,7 @@ func TestWalkFiles(t *testing.T) {
 }
 
 func TestWalkFilesSkip(t *testing.T) {
-	done := make(chan struct{})
-	paths, errc := WalkFiles(done, testImgDir, "\\w*.png")
+	paths, errc := WalkFiles(context.Background(), testImgDir, "\\w*.png")
 	var got []string
 	for p := range paths {
 		got = append(got, p)
@@ -451,3 +451,56 @@ func TestGetBase64String(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dst := filepath.Join(dir, "sub", "a.jpg")
+
+	// concurrent writers of the same dst never leave a partial one
+	want := bytes.Repeat([]byte("a"), 1<<16)
+	errc := make(chan error)
+	for i := 0; i < 8; i++ {
+		go func() {
+			errc <- writeAtomic(dst, func(w io.Writer) error {
+				for _, b := range want {
+					if _, err := w.Write([]byte{b}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := ioutil.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("writeAtomic() writes %d bytes, want %d", len(got), len(want))
+	}
+
+	// a failed write keeps dst and removes its temp file
+	failed := errors.ErrInvalidInput
+	if err = writeAtomic(dst, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return failed
+	}); err != failed {
+		t.Errorf("writeAtomic() error = %v, want %v", err, failed)
+	}
+	if got, _ = ioutil.ReadFile(dst); !bytes.Equal(got, want) {
+		t.Error("writeAtomic() changes dst on failure")
+	}
+	files, _ := ioutil.ReadDir(filepath.Dir(dst))
+	if len(files) != 1 {
+		t.Errorf("%d files are left, want only dst", len(files))
+	}
+}
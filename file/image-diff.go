@@ -0,0 +1,89 @@
+package file
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+// ImageDiff is the difference between the local digested images and the
+// remote images of a project.
+type ImageDiff struct {
+	New       []ImageDigest        // local images not found in remote
+	Changed   []ImageDigest        // local images whose content differ from the remote one of the same name
+	Unchanged []ImageDigest        // local images already existed in remote
+	Replaced  []types.ProjectImage // remote images superseded by the changed local images
+	Missing   []types.ProjectImage // remote images not found locally
+}
+
+// DiffImages compares the local digests against the remote project images.
+// A local image is unchanged if its checksum exists in remote, changed if
+// a remote image of the same filename has different checksum, otherwise new.
+// Digests with error or not being image are ignored.
+func DiffImages(local []ImageDigest, remote []types.ProjectImage) ImageDiff {
+	var ret ImageDiff
+
+	// remote images that are referenced by local checksums
+	existed := make(map[string]bool)
+	for _, d := range local {
+		if d.Error == nil && d.Existed {
+			existed[d.IID] = true
+		}
+	}
+
+	// candidates of replaced images by name
+	byName := make(map[string][]types.ProjectImage)
+	for _, r := range remote {
+		if existed[r.ID] {
+			continue
+		}
+		byName[r.Name] = append(byName[r.Name], r)
+	}
+
+	replaced := make(map[string]bool)
+	for _, d := range local {
+		if d.Error != nil || !d.IsImage {
+			continue
+		}
+		if d.Existed {
+			ret.Unchanged = append(ret.Unchanged, d)
+			continue
+		}
+		rs, ok := byName[d.Filename]
+		if !ok {
+			ret.New = append(ret.New, d)
+			continue
+		}
+		ret.Changed = append(ret.Changed, d)
+		for _, r := range rs {
+			if !replaced[r.ID] {
+				replaced[r.ID] = true
+				ret.Replaced = append(ret.Replaced, r)
+			}
+		}
+	}
+
+	for _, r := range remote {
+		if existed[r.ID] || replaced[r.ID] {
+			continue
+		}
+		ret.Missing = append(ret.Missing, r)
+	}
+
+	return ret
+}
+
+// SuffixFilename gives the first name of "base-n.ext", n = 1, 2, ..., that
+// is not taken, and marks it as taken. name is returned as is if not taken.
+func SuffixFilename(name string, taken map[string]bool) string {
+	ret := name
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; taken[ret]; i++ {
+		ret = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	taken[ret] = true
+	return ret
+}
@@ -0,0 +1,85 @@
+package file
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+func TestSuffixFilename(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		taken []string
+		want  string
+	}{
+		{"not taken", "a.jpg", []string{"b.jpg"}, "a.jpg"},
+		{"taken", "a.jpg", []string{"a.jpg"}, "a-1.jpg"},
+		{"suffix taken", "a.jpg", []string{"a.jpg", "a-1.jpg", "a-2.jpg"}, "a-3.jpg"},
+		{"no ext", "a", []string{"a"}, "a-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taken := make(map[string]bool)
+			for _, s := range tt.taken {
+				taken[s] = true
+			}
+			if got := SuffixFilename(tt.file, taken); got != tt.want {
+				t.Errorf("SuffixFilename() = %v, want %v", got, tt.want)
+			}
+			if !taken[tt.want] {
+				t.Errorf("SuffixFilename() does not mark %v as taken", tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffImages(t *testing.T) {
+	same := ImageDigest{IsImage: true, Filename: "a.jpg", Checksum: "1", Existed: true, IID: "r1"}
+	fresh := ImageDigest{IsImage: true, Filename: "b.jpg", Checksum: "2"}
+	edited := ImageDigest{IsImage: true, Filename: "c.jpg", Checksum: "3"}
+	invalid := ImageDigest{IsImage: true, Filename: "d.jpg", Error: errors.New("bad")}
+	other := ImageDigest{Filename: "log.txt"}
+
+	r1 := types.ProjectImage{ID: "r1", Name: "a.jpg"}
+	r2 := types.ProjectImage{ID: "r2", Name: "c.jpg"}
+	r3 := types.ProjectImage{ID: "r3", Name: "e.jpg"}
+
+	type args struct {
+		local  []ImageDigest
+		remote []types.ProjectImage
+	}
+	tests := []struct {
+		name string
+		args args
+		want ImageDiff
+	}{
+		{"empty", args{nil, nil}, ImageDiff{}},
+		{"all new", args{[]ImageDigest{fresh}, nil}, ImageDiff{New: []ImageDigest{fresh}}},
+		{"all missing", args{nil, []types.ProjectImage{r3}}, ImageDiff{Missing: []types.ProjectImage{r3}}},
+		{"ignored", args{[]ImageDigest{invalid, other}, nil}, ImageDiff{}},
+		{
+			"mixed",
+			args{
+				[]ImageDigest{same, fresh, edited, invalid},
+				[]types.ProjectImage{r1, r2, r3},
+			},
+			ImageDiff{
+				New:       []ImageDigest{fresh},
+				Changed:   []ImageDigest{edited},
+				Unchanged: []ImageDigest{same},
+				Replaced:  []types.ProjectImage{r2},
+				Missing:   []types.ProjectImage{r3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffImages(tt.args.local, tt.args.remote); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffImages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package file
 
 import (
+	"context"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
 
+	"github.com/jackytck/alti-cli/db"
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
 )
@@ -18,12 +21,23 @@ type ImageDigest struct {
 	Filename string
 	Filetype string
 	Filesize int64 // in bytes
-	Width    int
-	Height   int
+	Width    int   // displayed width, i.e. accounts for the EXIF orientation
+	Height   int   // displayed height, i.e. accounts for the EXIF orientation
 	GP       float64
-	SHA1     string
-	Existed  bool // existed in altizure or not
-	Error    error
+	Checksum string        // by the Checksum algorithm of the digester, for local use
+	SHA1     string        // sent to the server as the checksum, only computed with PID
+	Existed  bool          // existed in altizure or not
+	IID      string        // id of the existed image in altizure
+	Exif     *ExifInfo     // nil if not parsed or not available
+	Quality  *ImageQuality // nil if not analyzed or not decodable
+	Issues   []string      // quality issues, see QualityFilter.Issues
+	// Orientation is the EXIF orientation of a JPEG, 0 if unknown.
+	Orientation int
+	// Source is the original image if Path is its upright or JPEG copy, empty otherwise.
+	Source string
+	// Thumb is the path of the thumbnail, empty if not written.
+	Thumb string
+	Error error
 }
 
 // ImageDigester reads path names from paths.
@@ -32,20 +46,44 @@ type ImageDigester struct {
 	Root      string
 	PID       string
 	LightWork bool
-	Done      <-chan struct{}
-	Paths     <-chan string
-	Result    chan<- ImageDigest
+	WithExif  bool            // parse the EXIF of each image
+	Checksum  string          // checksum algorithm of the local digests, see ChecksumAlgorithms, sha1 if empty
+	Quality   *QualityFilter  // analyze the quality of each image, nil to disable
+	Cache     *db.DigestCache // skip re-hashing unchanged files, nil to disable
+	// UprightDir is the directory of the upright copies of the rotated JPEGs,
+	// which are digested instead of the originals. Empty to disable.
+	UprightDir string
+	// RawDir is the directory of the JPEG copies of the RAW images, which are
+	// converted by Dcraw at RawQuality and digested instead. Empty to reject
+	// the RAW images.
+	RawDir     string
+	RawQuality int    // DefaultRawQuality if not positive
+	Dcraw      string // path of dcraw
+	// ThumbDir is the directory of the thumbnails, whose longer side is
+	// ThumbSize pixels. Empty to disable.
+	ThumbDir  string
+	ThumbSize int
+	// ResizeDir is the directory of the copies of the images whose longer side
+	// exceeds MaxDimension, downsized at JPEGQuality and digested instead.
+	// Empty to disable.
+	ResizeDir    string
+	MaxDimension int
+	JPEGQuality  int // DefaultJPEGQuality if not positive
+	Ctx          context.Context
+	Paths        <-chan string
+	Result       chan<- ImageDigest
 }
 
 // Digest reads path names from Paths and sends digests of the corresponding
-// files on Result until either Paths or Done is closed.
+// files on Result until Paths is closed. Once Ctx is canceled, the remaining
+// paths are sent with the error of Ctx without being digested.
 func (id *ImageDigester) Digest() {
 	for path := range id.Paths {
-		select {
-		case id.Result <- work(id.PID, id.Root, path, id.LightWork):
-		case <-id.Done:
-			return
+		if err := id.Ctx.Err(); err != nil {
+			id.Result <- ImageDigest{Path: path, Error: err}
+			continue
 		}
+		id.Result <- id.work(path)
 	}
 }
 
@@ -75,11 +113,14 @@ func (id *ImageDigester) Run(n int) int {
 }
 
 // work checks the specified image file
-// and get its name, size, width, height, gp and sha1.
-func work(pid, r, p string, light bool) ImageDigest {
+// and get its name, size, width, height, gp, checksum and optionally exif and quality.
+// The type, dimension and checksum are read from cache if the file is unchanged.
+func (id *ImageDigester) work(p string) ImageDigest {
+	algo := id.algorithm()
+	cache := id.Cache
 	ret := ImageDigest{
 		Path: p,
-		URL:  strings.Replace(p[len(r):], " ", "%20", -1),
+		URL:  strings.Replace(p[len(id.Root):], " ", "%20", -1),
 	}
 
 	// a. is image?
@@ -98,31 +139,86 @@ func work(pid, r, p string, light bool) ImageDigest {
 	// b. filename
 	ret.Filename = filepath.Base(p)
 
-	if light {
+	if id.LightWork {
 		return ret
 	}
 
+	// RAW images are digested by their JPEG copies
+	if t, err := GuessFileType(p); err == nil && IsRawType(t) {
+		return id.convert(ret)
+	}
+
+	// c-g. filetype, filesize, dimension, gp and checksum
+	var info os.FileInfo
+	if cache != nil {
+		info, err = StatFile(p)
+		if err != nil {
+			ret.Error = errors.ErrFilesize
+			return ret
+		}
+		// digests cached before the orientation was taken into account are stale
+		if d, ok := cache.Get(p, info.Size(), info.ModTime(), algo); ok && d.Oriented && (d.SHA1 != "" || !id.withSHA1()) {
+			ret.Filetype = d.Filetype
+			ret.Filesize = d.Size
+			ret.Width = d.Width
+			ret.Height = d.Height
+			ret.GP = DimToGigaPixel(d.Width, d.Height)
+			ret.Checksum = d.Checksum
+			ret.SHA1 = d.SHA1
+			ret.Orientation = d.Orientation
+			return id.finish(ret)
+		}
+	}
+	if err = digest(&ret, algo, id.withSHA1()); err != nil {
+		ret.Error = err
+		return ret
+	}
+	if cache != nil {
+		// a failed cache only costs the next run
+		cache.Put(db.Digest{
+			Path:        p,
+			Size:        info.Size(),
+			ModTime:     info.ModTime(),
+			Filetype:    ret.Filetype,
+			Width:       ret.Width,
+			Height:      ret.Height,
+			Orientation: ret.Orientation,
+			Oriented:    true,
+			Checksum:    ret.Checksum,
+			Algorithm:   algo,
+			SHA1:        ret.SHA1,
+		})
+	}
+	return id.finish(ret)
+}
+
+// digest computes the filetype, filesize, dimension, gp and checksum of algo of
+// ret.Path, and its sha1 if withSHA1 is set or algo is sha1.
+func digest(ret *ImageDigest, algo string, withSHA1 bool) error {
+	p := ret.Path
+
 	// c. filetype
 	t, err := GuessFileType(p)
 	if err != nil {
-		ret.Error = err
-		return ret
+		return err
 	}
 	ret.Filetype = t
 
 	// d. filesize
 	bytes, err := Filesize(p)
 	if err != nil {
-		ret.Error = errors.ErrFilesize
-		return ret
+		return errors.ErrFilesize
 	}
 	ret.Filesize = bytes
 
-	// e. image dimension
+	// e. image dimension, as displayed by the EXIF orientation
 	w, h, err := GetImageSize(p)
 	if err != nil {
-		ret.Error = errors.ErrFileImageDim
-		return ret
+		return errors.ErrFileImageDim
+	}
+	if t == "image/jpeg" {
+		ret.Orientation = ReadOrientation(p)
+		w, h = OrientedSize(w, h, ret.Orientation)
 	}
 	ret.Width = w
 	ret.Height = h
@@ -131,19 +227,216 @@ func work(pid, r, p string, light bool) ImageDigest {
 	ret.GP = DimToGigaPixel(w, h)
 
 	// g. checksum
-	sha1, err := Sha1sum(p)
+	algos := []string{algo}
+	if withSHA1 && algo != ChecksumSHA1 {
+		algos = append(algos, ChecksumSHA1)
+	}
+	sums, err := Checksums(p, algos...)
 	if err != nil {
-		ret.Error = errors.ErrFileChecksum
-		return ret
+		return errors.ErrFileChecksum
+	}
+	ret.Checksum = sums[0]
+	if algo == ChecksumSHA1 {
+		ret.SHA1 = sums[0]
+	} else if withSHA1 {
+		ret.SHA1 = sums[1]
+	}
+	return nil
+}
+
+// finish replaces the rotated image of ret by its upright copy, reads the
+// optional exif and quality, and checks if the image of ret is already
+// uploaded to the project.
+func (id *ImageDigester) finish(ret ImageDigest) ImageDigest {
+	p := ret.Path
+	var err error
+	// the exif of a converted RAW image is only in the original
+	exifPath := p
+	if ret.Source != "" {
+		exifPath = ret.Source
+	}
+
+	// h. upright copy
+	if id.UprightDir != "" && ret.Orientation > 1 {
+		if ret, err = id.upright(ret); err != nil {
+			ret.Error = err
+			return ret
+		}
+	}
+
+	// i. downsized copy
+	if id.ResizeDir != "" && id.MaxDimension > 0 && max(ret.Width, ret.Height) > id.MaxDimension {
+		if ret, err = id.resize(ret); err != nil {
+			ret.Error = err
+			return ret
+		}
 	}
-	ret.SHA1 = sha1
 
-	// h. check if already uploaded
-	ret.Existed, err = gql.HasImage(pid, sha1)
+	// j. exif of the original, images without exif are still valid
+	if id.WithExif {
+		if e, err := ReadExif(exifPath); err == nil {
+			if ret.Source != "" {
+				// the upright copy is not rotated
+				e.Orientation = 1
+			}
+			ret.Exif = e
+		}
+	}
+
+	// k. quality, images that could not be decoded are only checked by size
+	if qf := id.Quality; qf != nil {
+		if q, err := AnalyzeQuality(p); err == nil {
+			ret.Quality = q
+		}
+		ret.Issues = qf.Issues(ret.Width, ret.Height, ret.Quality)
+	}
+
+	// l. thumbnail, images that could not be decoded have none
+	if id.ThumbDir != "" {
+		if t, err := id.thumbnail(p, ret); err == nil {
+			ret.Thumb = t
+		}
+	}
+
+	// m. check if already uploaded
+	ret.IID, err = gql.FindImage(id.PID, ret.SHA1)
 	if err != nil {
 		ret.Error = err
 		return ret
 	}
+	ret.Existed = ret.IID != ""
 
 	return ret
 }
+
+// upright digests the upright copy of the rotated image of ret instead,
+// named by the checksum of the original so that it is written only once.
+func (id *ImageDigester) upright(ret ImageDigest) (ImageDigest, error) {
+	dst := filepath.Join(id.UprightDir, ret.Checksum+".jpg")
+	if _, err := os.Stat(dst); err != nil {
+		if err = WriteUpright(ret.Path, ret.Orientation, dst); err != nil {
+			return ret, err
+		}
+	}
+	up := ImageDigest{
+		IsImage:  true,
+		Path:     dst,
+		URL:      ret.URL,
+		Filename: ret.Filename,
+		Source:   ret.Path,
+	}
+	if err := digest(&up, id.algorithm(), id.withSHA1()); err != nil {
+		return ret, err
+	}
+	// keep the orientation of the original for reporting
+	up.Orientation = ret.Orientation
+	return up, nil
+}
+
+// resize digests the upright JPEG copy of the image of ret downsized to
+// MaxDimension, named by its checksum, size and quality so that it is written
+// only once.
+func (id *ImageDigester) resize(ret ImageDigest) (ImageDigest, error) {
+	quality := id.JPEGQuality
+	if quality <= 0 {
+		quality = DefaultJPEGQuality
+	}
+	// copies are upright already
+	o := ret.Orientation
+	if ret.Source != "" {
+		o = 1
+	}
+	dst := filepath.Join(id.ResizeDir, ResizedName(ret.Checksum, id.MaxDimension, quality))
+	if _, err := os.Stat(dst); err != nil {
+		if err = WriteResized(ret.Path, o, id.MaxDimension, quality, dst); err != nil {
+			return ret, err
+		}
+	}
+	cp := ImageDigest{
+		IsImage:  true,
+		Path:     dst,
+		URL:      ret.URL,
+		Filename: ret.Filename,
+		Source:   ret.Path,
+	}
+	if ret.Filetype != "image/jpeg" {
+		cp.URL, cp.Filename = JPEGName(ret.URL), JPEGName(ret.Filename)
+	}
+	if ret.Source != "" {
+		cp.Source = ret.Source
+	}
+	if err := digest(&cp, id.algorithm(), id.withSHA1()); err != nil {
+		return ret, err
+	}
+	// keep the orientation of the original for reporting
+	cp.Orientation = ret.Orientation
+	return cp, nil
+}
+
+// convert digests the JPEG copy of the RAW image of ret instead, named by the
+// checksum of the original and the quality so that it is written only once.
+func (id *ImageDigester) convert(ret ImageDigest) ImageDigest {
+	if id.RawDir == "" {
+		ret.Error = errors.ErrRawNotConverted
+		return ret
+	}
+	sum, err := Checksum(ret.Path, id.algorithm())
+	if err != nil {
+		ret.Error = errors.ErrFileChecksum
+		return ret
+	}
+	quality := id.RawQuality
+	if quality <= 0 {
+		quality = DefaultRawQuality
+	}
+	dst := filepath.Join(id.RawDir, RawCopyName(sum, quality))
+	if _, err = os.Stat(dst); err != nil {
+		if err = ConvertRaw(id.Ctx, id.Dcraw, ret.Path, dst, quality); err != nil {
+			ret.Error = err
+			return ret
+		}
+	}
+	cp := ImageDigest{
+		IsImage:  true,
+		Path:     dst,
+		URL:      JPEGName(ret.URL),
+		Filename: JPEGName(ret.Filename),
+		Source:   ret.Path,
+	}
+	if err = digest(&cp, id.algorithm(), id.withSHA1()); err != nil {
+		ret.Error = err
+		return ret
+	}
+	return id.finish(cp)
+}
+
+// thumbnail writes the thumbnail of the image p of ret, named by its checksum
+// and size so that it is written only once. Return its path.
+func (id *ImageDigester) thumbnail(p string, ret ImageDigest) (string, error) {
+	side := id.ThumbSize
+	if side <= 0 {
+		side = DefaultThumbSize
+	}
+	dst := filepath.Join(id.ThumbDir, ThumbName(ret.Checksum, side))
+	if _, err := os.Stat(dst); err == nil {
+		return dst, nil
+	}
+	if err := WriteThumbnail(p, ret.Orientation, side, dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// withSHA1 tells if the sha1 of each image is computed for the server, which
+// only knows the images by their sha1 whatever the local algorithm is.
+func (id *ImageDigester) withSHA1() bool {
+	return id.PID != ""
+}
+
+// algorithm gives the checksum algorithm, sha1 if not set.
+func (id *ImageDigester) algorithm() string {
+	if id.Checksum == "" {
+		return ChecksumSHA1
+	}
+	return id.Checksum
+}
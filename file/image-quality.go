@@ -0,0 +1,129 @@
+package file
+
+import (
+	"image"
+)
+
+// qualitySide is the max side of the downscaled image being analyzed,
+// so that the sharpness of images of different sizes are comparable.
+const qualitySide = 512
+
+// ImageQuality is the measured quality of an image.
+type ImageQuality struct {
+	Sharpness  float64 // variance of the Laplacian of the downscaled gray image
+	Brightness float64 // mean luma in [0, 255]
+}
+
+// QualityFilter is the thresholds of flagging an image as low quality.
+type QualityFilter struct {
+	MinSharpness  float64 // blurred if below
+	MinBrightness float64 // under-exposed if below
+	MaxBrightness float64 // over-exposed if above
+	MinSide       int     // too small if the shorter side is below
+}
+
+// DefaultQualityFilter is the default thresholds of flagging low quality images.
+var DefaultQualityFilter = QualityFilter{
+	MinSharpness:  100,
+	MinBrightness: 40,
+	MaxBrightness: 215,
+	MinSide:       640,
+}
+
+// Issues lists the issues of an image of width w and height h, i.e.
+// "blurred", "under-exposed", "over-exposed" or "small".
+// The issues of q are skipped if it is nil, e.g. the image could not be decoded.
+func (f QualityFilter) Issues(w, h int, q *ImageQuality) []string {
+	var ret []string
+	if q != nil {
+		if q.Sharpness < f.MinSharpness {
+			ret = append(ret, "blurred")
+		}
+		if q.Brightness < f.MinBrightness {
+			ret = append(ret, "under-exposed")
+		}
+		if q.Brightness > f.MaxBrightness {
+			ret = append(ret, "over-exposed")
+		}
+	}
+	if w < f.MinSide || h < f.MinSide {
+		ret = append(ret, "small")
+	}
+	return ret
+}
+
+// AnalyzeQuality decodes the image of path p and measures its quality.
+// Only the formats registered in package image are supported.
+func AnalyzeQuality(p string) (*ImageQuality, error) {
+	f, err := OpenFile(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	q := measureQuality(img)
+	return &q, nil
+}
+
+// measureQuality computes the sharpness and brightness of img.
+func measureQuality(img image.Image) ImageQuality {
+	g, w, h := grayGrid(img)
+	var ret ImageQuality
+	if w == 0 || h == 0 {
+		return ret
+	}
+
+	var sum float64
+	for _, v := range g {
+		sum += v
+	}
+	ret.Brightness = sum / float64(len(g))
+
+	// variance of the 4-neighbour laplacian
+	if w < 3 || h < 3 {
+		return ret
+	}
+	var lSum, lSq float64
+	n := float64((w - 2) * (h - 2))
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			l := g[i-1] + g[i+1] + g[i-w] + g[i+w] - 4*g[i]
+			lSum += l
+			lSq += l * l
+		}
+	}
+	mean := lSum / n
+	ret.Sharpness = lSq/n - mean*mean
+	return ret
+}
+
+// grayGrid samples the luma of img in a grid of at most qualitySide
+// pixels per side. Return the row-major grid, its width and height.
+func grayGrid(img image.Image) ([]float64, int, int) {
+	b := img.Bounds()
+	step := (max(b.Dx(), b.Dy()) + qualitySide - 1) / qualitySide
+	if step < 1 {
+		step = 1
+	}
+	w := (b.Dx() + step - 1) / step
+	h := (b.Dy() + step - 1) / step
+	ret := make([]float64, 0, w*h)
+
+	yc, isYCbCr := img.(*image.YCbCr)
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			if isYCbCr {
+				ret = append(ret, float64(yc.Y[yc.YOffset(x, y)]))
+				continue
+			}
+			r, g, bl, _ := img.At(x, y).RGBA()
+			// ITU-R BT.601 luma of the 16-bit channels
+			ret = append(ret, (0.299*float64(r)+0.587*float64(g)+0.114*float64(bl))/257)
+		}
+	}
+	return ret, w, h
+}
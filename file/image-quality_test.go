@@ -0,0 +1,70 @@
+package file
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestQualityFilter_Issues(t *testing.T) {
+	f := DefaultQualityFilter
+	type args struct {
+		w int
+		h int
+		q *ImageQuality
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{"good", args{4000, 3000, &ImageQuality{Sharpness: 500, Brightness: 120}}, nil},
+		{"blurred", args{4000, 3000, &ImageQuality{Sharpness: 20, Brightness: 120}}, []string{"blurred"}},
+		{"dark", args{4000, 3000, &ImageQuality{Sharpness: 500, Brightness: 10}}, []string{"under-exposed"}},
+		{"bright small", args{320, 240, &ImageQuality{Sharpness: 500, Brightness: 250}}, []string{"over-exposed", "small"}},
+		{"not decoded", args{4000, 3000, nil}, nil},
+		{"not decoded small", args{4000, 100, nil}, []string{"small"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Issues(tt.args.w, tt.args.h, tt.args.q); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QualityFilter.Issues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeasureQuality(t *testing.T) {
+	flat := image.NewGray(image.Rect(0, 0, 64, 64))
+	checker := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			flat.SetGray(x, y, color.Gray{Y: 128})
+			if (x+y)%2 == 0 {
+				checker.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	tests := []struct {
+		name           string
+		img            image.Image
+		wantBrightness float64
+		wantSharp      bool
+	}{
+		{"flat", flat, 128, false},
+		{"checker", checker, 127.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := measureQuality(tt.img)
+			if math.Abs(got.Brightness-tt.wantBrightness) > 0.01 {
+				t.Errorf("measureQuality() Brightness = %v, want %v", got.Brightness, tt.wantBrightness)
+			}
+			if sharp := got.Sharpness >= DefaultQualityFilter.MinSharpness; sharp != tt.wantSharp {
+				t.Errorf("measureQuality() Sharpness = %v, want sharp %v", got.Sharpness, tt.wantSharp)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// heifBrands maps the major brands of the ISO BMFF 'ftyp' box to the mime
+// types of HEIC and HEIF images.
+var heifBrands = map[string]string{
+	"heic": "image/heic",
+	"heix": "image/heic",
+	"hevc": "image/heic-sequence",
+	"hevx": "image/heic-sequence",
+	"heim": "image/heic",
+	"heis": "image/heic",
+	"mif1": "image/heif",
+	"msf1": "image/heif-sequence",
+}
+
+// heifType gives the mime type of the HEIC or HEIF header, empty if it is not.
+func heifType(header []byte) string {
+	if len(header) < 12 || string(header[4:8]) != "ftyp" {
+		return ""
+	}
+	return heifBrands[string(header[8:12])]
+}
+
+// webpSize decodes the dimension of a WebP image from its header,
+// supporting the lossy (VP8), lossless (VP8L) and extended (VP8X) formats.
+func webpSize(r io.Reader) (int, int, error) {
+	b := make([]byte, 30)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, 0, err
+	}
+	if string(b[0:4]) != "RIFF" || string(b[8:12]) != "WEBP" {
+		return 0, 0, errors.ErrFileImageDim
+	}
+	switch string(b[12:16]) {
+	case "VP8 ":
+		if b[23] != 0x9d || b[24] != 0x01 || b[25] != 0x2a {
+			return 0, 0, errors.ErrFileImageDim
+		}
+		w := binary.LittleEndian.Uint16(b[26:28]) & 0x3fff
+		h := binary.LittleEndian.Uint16(b[28:30]) & 0x3fff
+		return int(w), int(h), nil
+	case "VP8L":
+		if b[20] != 0x2f {
+			return 0, 0, errors.ErrFileImageDim
+		}
+		bits := binary.LittleEndian.Uint32(b[21:25])
+		w := bits&0x3fff + 1
+		h := (bits>>14)&0x3fff + 1
+		return int(w), int(h), nil
+	case "VP8X":
+		w := uint32(b[24]) | uint32(b[25])<<8 | uint32(b[26])<<16
+		h := uint32(b[27]) | uint32(b[28])<<8 | uint32(b[29])<<16
+		return int(w + 1), int(h + 1), nil
+	}
+	return 0, 0, errors.ErrFileImageDim
+}
+
+// heifSize decodes the dimension of a HEIC or HEIF image from the 'ispe'
+// properties of its 'meta' box. The largest one is taken, as the thumbnails
+// and the tiles of a grid image have their own.
+func heifSize(r io.ReadSeeker) (int, int, error) {
+	for {
+		typ, body, err := nextBox(r)
+		if err != nil {
+			if err == io.EOF {
+				err = errors.ErrFileImageDim
+			}
+			return 0, 0, err
+		}
+		if typ != "meta" {
+			if _, err = r.Seek(body, io.SeekCurrent); err != nil {
+				return 0, 0, err
+			}
+			continue
+		}
+		meta := make([]byte, body)
+		if _, err = io.ReadFull(r, meta); err != nil {
+			return 0, 0, err
+		}
+		// skip the version and flags of the full box
+		if len(meta) < 4 {
+			return 0, 0, errors.ErrFileImageDim
+		}
+		w, h := largestIspe(meta[4:])
+		if w == 0 || h == 0 {
+			return 0, 0, errors.ErrFileImageDim
+		}
+		return w, h, nil
+	}
+}
+
+// largestIspe finds the largest 'ispe' in the 'iprp/ipco' boxes of meta.
+func largestIspe(meta []byte) (int, int) {
+	var w, h int
+	iprp := childBox(meta, "iprp")
+	ipco := childBox(iprp, "ipco")
+	r := bytes.NewReader(ipco)
+	for {
+		typ, body, err := nextBox(r)
+		if err != nil {
+			return w, h
+		}
+		b := make([]byte, body)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return w, h
+		}
+		if typ != "ispe" || len(b) < 12 {
+			continue
+		}
+		iw := int(binary.BigEndian.Uint32(b[4:8]))
+		ih := int(binary.BigEndian.Uint32(b[8:12]))
+		if iw*ih > w*h {
+			w, h = iw, ih
+		}
+	}
+}
+
+// childBox gives the body of the first child box of type typ in data.
+func childBox(data []byte, typ string) []byte {
+	r := bytes.NewReader(data)
+	for {
+		t, body, err := nextBox(r)
+		if err != nil {
+			return nil
+		}
+		b := make([]byte, body)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil
+		}
+		if t == typ {
+			return b
+		}
+	}
+}
+
+// nextBox reads the header of the next ISO BMFF box of r.
+// Return its type and the size of its body.
+func nextBox(r io.Reader) (string, int64, error) {
+	hdr := make([]byte, 8)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return "", 0, err
+	}
+	size := int64(binary.BigEndian.Uint32(hdr[0:4]))
+	typ := string(hdr[4:8])
+	hdrSize := int64(8)
+	if size == 1 {
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return "", 0, err
+		}
+		size = int64(binary.BigEndian.Uint64(ext))
+		hdrSize = 16
+	}
+	// size 0 means the box extends to the end, which is never the meta box
+	if size < hdrSize {
+		return "", 0, io.EOF
+	}
+	return typ, size - hdrSize, nil
+}
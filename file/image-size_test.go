@@ -0,0 +1,113 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// box builds an ISO BMFF box of typ with body.
+func box(typ string, body ...[]byte) []byte {
+	b := bytes.Join(body, nil)
+	hdr := make([]byte, 8)
+	binary.BigEndian.PutUint32(hdr, uint32(len(b)+8))
+	copy(hdr[4:], typ)
+	return append(hdr, b...)
+}
+
+// ispe builds an 'ispe' box of w x h.
+func ispe(w, h uint32) []byte {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint32(b[4:], w)
+	binary.BigEndian.PutUint32(b[8:], h)
+	return box("ispe", b)
+}
+
+func TestHeifType(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"heic", box("ftyp", []byte("heic\x00\x00\x00\x00mif1heic")), "image/heic"},
+		{"heif", box("ftyp", []byte("mif1\x00\x00\x00\x00")), "image/heif"},
+		{"mp4", box("ftyp", []byte("isom\x00\x00\x00\x00")), ""},
+		{"short", []byte("RIFF"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := heifType(tt.header); got != tt.want {
+				t.Errorf("heifType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebpSize(t *testing.T) {
+	riff := func(chunk string, data []byte) []byte {
+		b := append([]byte("RIFF\x00\x00\x00\x00WEBP"+chunk+"\x00\x00\x00\x00"), data...)
+		return append(b, make([]byte, 30)...)
+	}
+	vp8 := []byte{0, 0, 0, 0x9d, 0x01, 0x2a, 0x80, 0x07, 0x38, 0x04}
+	vp8l := []byte{0x2f, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(vp8l[1:], (4000-1)|(3000-1)<<14)
+	vp8x := []byte{0, 0, 0, 0, 0x9f, 0x0f, 0, 0xb7, 0x0b, 0}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		w, h    int
+		wantErr bool
+	}{
+		{"lossy", riff("VP8 ", vp8), 1920, 1080, false},
+		{"lossless", riff("VP8L", vp8l), 4000, 3000, false},
+		{"extended", riff("VP8X", vp8x), 4000, 3000, false},
+		{"not webp", riff("ABCD", nil), 0, 0, true},
+		{"short", []byte("RIFF"), 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h, err := webpSize(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("webpSize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if w != tt.w || h != tt.h {
+				t.Errorf("webpSize() = %d x %d, want %d x %d", w, h, tt.w, tt.h)
+			}
+		})
+	}
+}
+
+func TestHeifSize(t *testing.T) {
+	ftyp := box("ftyp", []byte("heic\x00\x00\x00\x00mif1heic"))
+	meta := func(props ...[]byte) []byte {
+		ipco := box("ipco", props...)
+		return box("meta", []byte{0, 0, 0, 0}, box("hdlr", make([]byte, 24)), box("iprp", ipco))
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		w, h    int
+		wantErr bool
+	}{
+		{"single", bytes.Join([][]byte{ftyp, meta(ispe(4032, 3024)), box("mdat")}, nil), 4032, 3024, false},
+		{"grid", bytes.Join([][]byte{ftyp, meta(ispe(512, 512), ispe(4032, 3024), ispe(320, 240))}, nil), 4032, 3024, false},
+		{"mdat first", bytes.Join([][]byte{ftyp, box("mdat", make([]byte, 100)), meta(ispe(640, 480))}, nil), 640, 480, false},
+		{"no ispe", bytes.Join([][]byte{ftyp, meta()}, nil), 0, 0, true},
+		{"no meta", ftyp, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h, err := heifSize(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("heifSize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if w != tt.w || h != tt.h {
+				t.Errorf("heifSize() = %d x %d, want %d x %d", w, h, tt.w, tt.h)
+			}
+		})
+	}
+}
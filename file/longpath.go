@@ -0,0 +1,90 @@
+package file
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// longPathPrefix is the prefix of the extended-length paths of Windows, which
+// lift the limit of 260 characters of MAX_PATH.
+const longPathPrefix = `\\?\`
+
+// uncPrefix follows longPathPrefix in the extended-length paths of the UNC
+// network shares.
+const uncPrefix = `UNC\`
+
+// IsUNC tells if p is a Windows UNC path of a network share, e.g.
+// \\nas\drone\flight1, or its extended-length form.
+func IsUNC(p string) bool {
+	if strings.HasPrefix(p, longPathPrefix) {
+		rest := p[len(longPathPrefix):]
+		return len(rest) >= len(uncPrefix) && strings.EqualFold(rest[:len(uncPrefix)], uncPrefix)
+	}
+	return len(p) > 2 && isWinSep(p[0]) && isWinSep(p[1]) && !isWinSep(p[2]) && p[2] != '?' && p[2] != '.'
+}
+
+// LongPath gives the extended-length form of the path p on Windows, e.g.
+// \\?\C:\data\img.jpg or \\?\UNC\nas\drone\img.jpg, so that the files of the
+// deep directories, commonly of the NAS shares, could be opened. Relative
+// paths are made absolute. It is p as is on the other platforms.
+func LongPath(p string) string {
+	if runtime.GOOS != "windows" {
+		return p
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	return longPath(p)
+}
+
+// longPath is LongPath of the absolute Windows path p. The extended-length
+// paths are not normalized by Windows, so the slashes, '.' and '..' are
+// resolved here. Relative paths are kept as is.
+func longPath(p string) string {
+	if strings.HasPrefix(p, longPathPrefix) || strings.HasPrefix(p, `\\.\`) {
+		return p
+	}
+	p = strings.Replace(p, "/", `\`, -1)
+	switch {
+	case IsUNC(p):
+		return longPathPrefix + uncPrefix + cleanWinPath(p[2:], 2)
+	case len(p) >= 3 && p[1] == ':' && p[2] == '\\':
+		return longPathPrefix + p[:3] + cleanWinPath(p[3:], 0)
+	}
+	return p
+}
+
+// ShortPath gives p without the extended-length prefix of LongPath, e.g. for
+// display.
+func ShortPath(p string) string {
+	if !strings.HasPrefix(p, longPathPrefix) {
+		return p
+	}
+	if IsUNC(p) {
+		return `\\` + p[len(longPathPrefix)+len(uncPrefix):]
+	}
+	return p[len(longPathPrefix):]
+}
+
+// cleanWinPath resolves the '.' and '..' of the backslash separated path p,
+// never above its first keep elements, e.g. the server and the share of UNC.
+func cleanWinPath(p string, keep int) string {
+	var elems []string
+	for _, e := range strings.Split(p, `\`) {
+		switch e {
+		case "", ".":
+		case "..":
+			if len(elems) > keep {
+				elems = elems[:len(elems)-1]
+			}
+		default:
+			elems = append(elems, e)
+		}
+	}
+	return strings.Join(elems, `\`)
+}
+
+func isWinSep(c byte) bool {
+	return c == '\\' || c == '/'
+}
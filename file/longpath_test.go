@@ -0,0 +1,86 @@
+package file
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLongPath(t *testing.T) {
+	tests := []struct {
+		name string
+		p    string
+		want string
+	}{
+		{"drive", `C:\data\flight1\img.jpg`, `\\?\C:\data\flight1\img.jpg`},
+		{"drive root", `D:\`, `\\?\D:\`},
+		{"slashes", `C:/data/flight1/../flight2/./img.jpg`, `\\?\C:\data\flight2\img.jpg`},
+		{"above drive root", `C:\..\data`, `\\?\C:\data`},
+		{"unc", `\\nas\drone\flight1\img.jpg`, `\\?\UNC\nas\drone\flight1\img.jpg`},
+		{"unc keeps share", `\\nas\drone\..\..\img.jpg`, `\\?\UNC\nas\drone\img.jpg`},
+		{"already long", `\\?\C:\data`, `\\?\C:\data`},
+		{"already long unc", `\\?\UNC\nas\drone`, `\\?\UNC\nas\drone`},
+		{"device", `\\.\COM1`, `\\.\COM1`},
+		{"relative", `data\img.jpg`, `data\img.jpg`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := longPath(tt.p); got != tt.want {
+				t.Errorf("longPath(%q) = %q, want %q", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortPath(t *testing.T) {
+	tests := []struct {
+		p    string
+		want string
+	}{
+		{`\\?\C:\data\img.jpg`, `C:\data\img.jpg`},
+		{`\\?\UNC\nas\drone\img.jpg`, `\\nas\drone\img.jpg`},
+		{`\\?\unc\nas\drone`, `\\nas\drone`},
+		{`C:\data`, `C:\data`},
+		{"/home/me/img.jpg", "/home/me/img.jpg"},
+	}
+	for _, tt := range tests {
+		if got := ShortPath(tt.p); got != tt.want {
+			t.Errorf("ShortPath(%q) = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestIsUNC(t *testing.T) {
+	tests := []struct {
+		p    string
+		want bool
+	}{
+		{`\\nas\drone`, true},
+		{`//nas/drone`, true},
+		{`\\?\UNC\nas\drone`, true},
+		{`\\?\C:\data`, false},
+		{`\\.\COM1`, false},
+		{`C:\data`, false},
+		{"/home/me", false},
+	}
+	for _, tt := range tests {
+		if got := IsUNC(tt.p); got != tt.want {
+			t.Errorf("IsUNC(%q) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestUnderRoot(t *testing.T) {
+	tests := []struct {
+		root, walkRoot, p string
+		want              string
+	}{
+		{"img", `\\?\C:\data\img`, `\\?\C:\data\img`, "img"},
+		{"img", `\\?\C:\data\img`, `\\?\C:\data\img\a.jpg`, "img/a.jpg"},
+		{"img/", `\\?\C:\data\img`, `\\?\C:\data\img\a.jpg`, "img/a.jpg"},
+	}
+	for _, tt := range tests {
+		if got := underRoot(tt.root, tt.walkRoot, tt.p); got != filepath.FromSlash(tt.want) {
+			t.Errorf("underRoot(%q, %q, %q) = %q, want %q", tt.root, tt.walkRoot, tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package file
+
+import (
+	"archive/zip"
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ModelFormats are the extensions of the supported model files.
+var ModelFormats = []string{".obj", ".ply", ".fbx"}
+
+// ModelStat is the statistics of a model file, standalone or inside a zip.
+type ModelStat struct {
+	Path      string // path of the file, or the path inside the zip
+	Format    string // obj, ply or fbx
+	Size      int64
+	Vertices  int      // -1 if unknown
+	Faces     int      // -1 if unknown
+	Materials []string // referenced material libraries
+	Textures  []string // referenced textures of the materials
+	Missing   []string // referenced files that could not be found
+}
+
+// ModelReport is the result of checking a model input.
+type ModelReport struct {
+	Path   string
+	IsZip  bool
+	Size   int64 // estimated upload size, i.e. the zip or the model with its references
+	Models []ModelStat
+}
+
+// Missing gives all of the missing references of the models.
+func (r ModelReport) Missing() []string {
+	var ret []string
+	for _, m := range r.Models {
+		ret = append(ret, m.Missing...)
+	}
+	return ret
+}
+
+// modelFormat gives the format of the model file p, empty if not supported.
+func modelFormat(p string) string {
+	ext := strings.ToLower(path.Ext(p))
+	for _, f := range ModelFormats {
+		if ext == f {
+			return ext[1:]
+		}
+	}
+	return ""
+}
+
+// CheckModel reads the model file or the model files inside the zip of path p,
+// counts their vertices and faces and finds their missing references.
+func CheckModel(p string) (*ModelReport, error) {
+	isZip, err := IsZipFile(p)
+	if err != nil {
+		return nil, err
+	}
+	if isZip {
+		return checkModelZip(p)
+	}
+
+	size, err := Filesize(p)
+	if err != nil {
+		return nil, err
+	}
+	ret := ModelReport{Path: p, Size: size}
+	if modelFormat(p) == "" {
+		return &ret, nil
+	}
+	open := func(name string) (io.ReadCloser, int64, error) {
+		fp := filepath.Join(filepath.Dir(p), filepath.FromSlash(name))
+		s, err := Filesize(fp)
+		if err != nil {
+			return nil, 0, err
+		}
+		f, err := os.Open(fp)
+		return f, s, err
+	}
+	m, refSize, err := checkModel(filepath.Base(p), size, open)
+	if err != nil {
+		return nil, err
+	}
+	m.Path = p
+	ret.Size += refSize
+	ret.Models = append(ret.Models, m)
+	return &ret, nil
+}
+
+// checkModelZip checks all of the model files inside the zip of path p.
+func checkModelZip(p string) (*ModelReport, error) {
+	z, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+	size, err := Filesize(p)
+	if err != nil {
+		return nil, err
+	}
+	ret := ModelReport{Path: p, IsZip: true, Size: size}
+
+	entries := make(map[string]*zip.File)
+	for _, f := range z.File {
+		entries[f.Name] = f
+	}
+	for _, f := range z.File {
+		if f.FileInfo().IsDir() || modelFormat(f.Name) == "" {
+			continue
+		}
+		dir := path.Dir(f.Name)
+		open := func(name string) (io.ReadCloser, int64, error) {
+			e, ok := entries[path.Join(dir, name)]
+			if !ok {
+				return nil, 0, os.ErrNotExist
+			}
+			rc, err := e.Open()
+			return rc, int64(e.UncompressedSize64), err
+		}
+		m, _, err := checkModel(f.Name, int64(f.UncompressedSize64), open)
+		if err != nil {
+			return nil, err
+		}
+		ret.Models = append(ret.Models, m)
+	}
+	return &ret, nil
+}
+
+// checkModel checks the model of name, whose references are opened by open
+// relative to the model. Return the sum of sizes of the found references.
+func checkModel(name string, size int64, open func(string) (io.ReadCloser, int64, error)) (ModelStat, int64, error) {
+	ret := ModelStat{
+		Path:     name,
+		Format:   modelFormat(name),
+		Size:     size,
+		Vertices: -1,
+		Faces:    -1,
+	}
+	if ret.Format == "fbx" {
+		// binary fbx is not parsed
+		return ret, 0, nil
+	}
+
+	rc, _, err := open(path.Base(name))
+	if err != nil {
+		return ret, 0, err
+	}
+	defer rc.Close()
+	switch ret.Format {
+	case "obj":
+		ret.Vertices, ret.Faces, ret.Materials, err = CountOBJ(rc)
+	case "ply":
+		ret.Vertices, ret.Faces, err = CountPLY(rc)
+	}
+	if err != nil {
+		return ret, 0, err
+	}
+
+	// materials and their textures
+	var refSize int64
+	for _, mtl := range ret.Materials {
+		mrc, s, err := open(mtl)
+		if err != nil {
+			ret.Missing = append(ret.Missing, mtl)
+			continue
+		}
+		refSize += s
+		textures, err := ParseMTL(mrc)
+		mrc.Close()
+		if err != nil {
+			return ret, 0, err
+		}
+		mtlDir := path.Dir(mtl)
+		for _, t := range textures {
+			t = path.Join(mtlDir, t)
+			ret.Textures = append(ret.Textures, t)
+			trc, s, err := open(t)
+			if err != nil {
+				ret.Missing = append(ret.Missing, t)
+				continue
+			}
+			trc.Close()
+			refSize += s
+		}
+	}
+	return ret, refSize, nil
+}
+
+// CountOBJ counts the vertices and faces of a Wavefront obj,
+// and lists its material libraries.
+func CountOBJ(r io.Reader) (int, int, []string, error) {
+	var v, f int
+	var mtls []string
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "v":
+			v++
+		case "f":
+			f++
+		case "mtllib":
+			for _, m := range fields[1:] {
+				mtls = append(mtls, filepath.ToSlash(m))
+			}
+		}
+	}
+	return v, f, mtls, s.Err()
+}
+
+// ParseMTL lists the texture maps of a Wavefront mtl.
+func ParseMTL(r io.Reader) ([]string, error) {
+	var ret []string
+	seen := make(map[string]bool)
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		k := strings.ToLower(fields[0])
+		if !strings.HasPrefix(k, "map_") && k != "bump" && k != "disp" && k != "decal" && k != "refl" {
+			continue
+		}
+		// the filename is the last field, after the options
+		t := filepath.ToSlash(fields[len(fields)-1])
+		if !seen[t] {
+			seen[t] = true
+			ret = append(ret, t)
+		}
+	}
+	return ret, s.Err()
+}
+
+// CountPLY reads the number of vertices and faces from the header of a ply.
+func CountPLY(r io.Reader) (int, int, error) {
+	v, f := -1, -1
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 1 && fields[0] == "end_header" {
+			break
+		}
+		if len(fields) != 3 || fields[0] != "element" {
+			continue
+		}
+		n, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return v, f, err
+		}
+		switch fields[1] {
+		case "vertex":
+			v = n
+		case "face":
+			f = n
+		}
+	}
+	return v, f, s.Err()
+}
@@ -0,0 +1,77 @@
+package file
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCountOBJ(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      string
+		wantV    int
+		wantF    int
+		wantMtls []string
+	}{
+		{"empty", "", 0, 0, nil},
+		{"triangle", "mtllib a.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nvt 0 0\nf 1 2 3\n", 3, 1, []string{"a.mtl"}},
+		{"comments", "# v 1 1 1\n  v 0 0 0\nvn 0 0 1\nmtllib a.mtl tex\\b.mtl\n", 1, 0, []string{"a.mtl", "tex\\b.mtl"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, f, mtls, err := CountOBJ(strings.NewReader(tt.obj))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != tt.wantV || f != tt.wantF || !reflect.DeepEqual(mtls, tt.wantMtls) {
+				t.Errorf("CountOBJ() = %v, %v, %v, want %v, %v, %v", v, f, mtls, tt.wantV, tt.wantF, tt.wantMtls)
+			}
+		})
+	}
+}
+
+func TestParseMTL(t *testing.T) {
+	tests := []struct {
+		name string
+		mtl  string
+		want []string
+	}{
+		{"none", "newmtl a\nKd 1 1 1\n", nil},
+		{"maps", "newmtl a\nmap_Kd a.jpg\nmap_Bump -bm 0.5 n.png\nnewmtl b\nmap_Kd a.jpg\n", []string{"a.jpg", "n.png"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMTL(strings.NewReader(tt.mtl))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountPLY(t *testing.T) {
+	tests := []struct {
+		name  string
+		ply   string
+		wantV int
+		wantF int
+	}{
+		{"mesh", "ply\nformat ascii 1.0\nelement vertex 8\nproperty float x\nelement face 12\nend_header\n", 8, 12},
+		{"point cloud", "ply\nformat binary_little_endian 1.0\nelement vertex 100\nend_header\nelement face 1\n", 100, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, f, err := CountPLY(strings.NewReader(tt.ply))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != tt.wantV || f != tt.wantF {
+				t.Errorf("CountPLY() = %v, %v, want %v, %v", v, f, tt.wantV, tt.wantF)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package file
+
+import (
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// OrientedSize gives the displayed width and height of an image of stored
+// dimension w x h and EXIF orientation o. Orientations 5 to 8 are rotated by
+// 90 degrees, i.e. the width and height are swapped.
+func OrientedSize(w, h, o int) (int, int) {
+	if o >= 5 && o <= 8 {
+		return h, w
+	}
+	return w, h
+}
+
+// ReadOrientation reads the EXIF orientation of an image, 0 if unknown.
+func ReadOrientation(p string) int {
+	f, err := OpenFile(p)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	x, err := exif.Decode(f)
+	if err != nil {
+		return 0
+	}
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return 0
+	}
+	o, err := tag.Int(0)
+	if err != nil || o < 1 || o > 8 {
+		return 0
+	}
+	return o
+}
+
+// Upright transforms img of EXIF orientation o to orientation 1.
+func Upright(img image.Image, o int) image.Image {
+	if o <= 1 || o > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	src := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	dw, dh := OrientedSize(w, h, o)
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch o {
+			case 2: // flip horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotate 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // flip vertically
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			i := src.PixOffset(x, y)
+			j := dst.PixOffset(dx, dy)
+			copy(dst.Pix[j:j+4], src.Pix[i:i+4])
+		}
+	}
+	return dst
+}
+
+// WriteUpright writes the upright copy of the JPEG image p of EXIF
+// orientation o to dst. The EXIF is not copied.
+func WriteUpright(p string, o int, dst string) error {
+	f, err := OpenFile(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	if err != nil {
+		return err
+	}
+
+	// written atomically, so that a partial one is never digested
+	return writeAtomic(dst, func(w io.Writer) error {
+		return jpeg.Encode(w, Upright(img, o), &jpeg.Options{Quality: 95})
+	})
+}
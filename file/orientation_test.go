@@ -0,0 +1,61 @@
+package file
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOrientedSize(t *testing.T) {
+	tests := []struct {
+		o     int
+		wantW int
+		wantH int
+	}{
+		{0, 4000, 3000},
+		{1, 4000, 3000},
+		{3, 4000, 3000},
+		{5, 3000, 4000},
+		{6, 3000, 4000},
+		{8, 3000, 4000},
+		{9, 4000, 3000},
+	}
+	for _, tt := range tests {
+		w, h := OrientedSize(4000, 3000, tt.o)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("OrientedSize(%d) = %d x %d, want %d x %d", tt.o, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestUpright(t *testing.T) {
+	// 3 x 2 image with the stored top-left pixel marked
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	red := color.NRGBA{255, 0, 0, 255}
+	img.Set(0, 0, red)
+
+	tests := []struct {
+		o    int
+		want image.Point // upright position of the marked pixel
+		size image.Point
+	}{
+		{1, image.Pt(0, 0), image.Pt(3, 2)},
+		{2, image.Pt(2, 0), image.Pt(3, 2)},
+		{3, image.Pt(2, 1), image.Pt(3, 2)},
+		{4, image.Pt(0, 1), image.Pt(3, 2)},
+		{5, image.Pt(0, 0), image.Pt(2, 3)},
+		{6, image.Pt(1, 0), image.Pt(2, 3)},
+		{7, image.Pt(1, 2), image.Pt(2, 3)},
+		{8, image.Pt(0, 2), image.Pt(2, 3)},
+	}
+	for _, tt := range tests {
+		got := Upright(img, tt.o)
+		if s := got.Bounds().Size(); s != tt.size {
+			t.Errorf("Upright(%d) size = %v, want %v", tt.o, s, tt.size)
+			continue
+		}
+		if c := color.NRGBAModel.Convert(got.At(tt.want.X, tt.want.Y)); c != red {
+			t.Errorf("Upright(%d) marked pixel is not at %v", tt.o, tt.want)
+		}
+	}
+}
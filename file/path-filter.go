@@ -0,0 +1,220 @@
+package file
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// JunkNames are the names of the files and directories created by the OS,
+// excluded by default.
+var JunkNames = []string{"Thumbs.db", "ehthumbs.db", "desktop.ini", ".DS_Store", "__MACOSX", "$RECYCLE.BIN", "System Volume Information"}
+
+// RegexPrefix prefixes a pattern of PathFilter that is a regular expression
+// instead of a glob.
+const RegexPrefix = "re:"
+
+// PathFilter selects the paths of a walk by the precedence:
+//  1. paths matching Skip or any of the exclude patterns are excluded
+//  2. dotfiles and junk files are excluded unless Hidden is set
+//  3. if any include pattern is given, only the paths matching any of them
+//     are included
+//  4. the other paths are included
+//
+// A pattern is a glob, e.g. '*.jpg', 'raw/**/*.JPG' or '.small', or a
+// regular expression prefixed by RegexPrefix, e.g. 're:DJI_\d+'. A glob
+// without '/' matches the name of the file or any of its parent directories,
+// otherwise the path relative to the root, case-insensitively. A regular
+// expression matches anywhere in the path relative to the root.
+type PathFilter struct {
+	Skip    *regexp.Regexp // legacy regular expression of the full path to skip, nil if none
+	Include []*regexp.Regexp
+	Exclude []*regexp.Regexp
+	Hidden  bool // include the dotfiles and junk files
+}
+
+// NewPathFilter compiles the skip regular expression, the include and
+// exclude patterns.
+func NewPathFilter(skip string, include, exclude []string, hidden bool) (*PathFilter, error) {
+	f := PathFilter{Hidden: hidden}
+	if skip != "" {
+		r, err := regexp.Compile(skip)
+		if err != nil {
+			return nil, err
+		}
+		f.Skip = r
+	}
+	for _, p := range include {
+		r, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		f.Include = append(f.Include, r)
+	}
+	for _, p := range exclude {
+		r, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		f.Exclude = append(f.Exclude, r)
+	}
+	return &f, nil
+}
+
+// compilePattern compiles a glob or a regular expression pattern into a
+// regular expression matching the slash path relative to the root.
+func compilePattern(p string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(p, RegexPrefix) {
+		return regexp.Compile(strings.TrimPrefix(p, RegexPrefix))
+	}
+	var b strings.Builder
+	b.WriteString(`(?i)`)
+	p = strings.TrimPrefix(p, "./")
+	if !strings.Contains(strings.TrimSuffix(p, "/"), "/") {
+		// name of the file or any parent
+		b.WriteString(`(^|/)`)
+	} else {
+		b.WriteString(`^`)
+	}
+	p = strings.TrimSuffix(strings.TrimPrefix(p, "/"), "/")
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString(`(.*/)?`)
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(`.*`)
+			i++
+		case c == '*':
+			b.WriteString(`[^/]*`)
+		case c == '?':
+			b.WriteString(`[^/]`)
+		case c == '[':
+			j := strings.IndexByte(p[i:], ']')
+			if j < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := p[i+1 : i+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += j
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	// a directory matches all of its files
+	b.WriteString(`(/|$)`)
+	return regexp.Compile(b.String())
+}
+
+// Match tells if the path p under root is selected.
+func (f *PathFilter) Match(root, p string) bool {
+	if f == nil {
+		return true
+	}
+	if f.Skip != nil && f.Skip.MatchString(p) {
+		return false
+	}
+	rel := relSlash(root, p)
+	if anyMatch(f.Exclude, rel) {
+		return false
+	}
+	// root itself is a file
+	if rel == "." {
+		return true
+	}
+	if !f.Hidden && IsHiddenPath(rel) {
+		return false
+	}
+	return len(f.Include) == 0 || anyMatch(f.Include, rel)
+}
+
+// SkipDir tells if the whole directory p under root is excluded, i.e. by
+// an exclude pattern, or being hidden.
+func (f *PathFilter) SkipDir(root, p string) bool {
+	if f == nil {
+		return false
+	}
+	rel := relSlash(root, p)
+	if rel == "." {
+		return false
+	}
+	if anyMatch(f.Exclude, rel) {
+		return true
+	}
+	return !f.Hidden && IsHiddenPath(rel)
+}
+
+// IsHiddenPath tells if any part of the slash path rel is a dotfile or junk.
+func IsHiddenPath(rel string) bool {
+	for _, s := range strings.Split(rel, "/") {
+		if s == "." || s == ".." {
+			continue
+		}
+		if strings.HasPrefix(s, ".") {
+			return true
+		}
+		for _, j := range JunkNames {
+			if strings.EqualFold(s, j) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// relSlash gives the slash path of p relative to root, p itself if not
+// under root. The entries of a root zip are relative to the zip.
+func relSlash(root, p string) string {
+	if strings.HasPrefix(p, root+ArchiveSep) {
+		return strings.TrimPrefix(p, root+ArchiveSep)
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		rel = p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// WritePathList writes the slash paths of ps relative to root, one per line,
+// as read by ReadPathList.
+func WritePathList(w io.Writer, root string, ps []string) error {
+	for _, p := range ps {
+		if _, err := fmt.Fprintln(w, relSlash(root, p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadPathList reads the slash paths relative to the root, one per line, as
+// the exclude patterns matching exactly them. Blank lines and the lines
+// starting with '#' are skipped.
+func ReadPathList(r io.Reader) ([]string, error) {
+	var ret []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		l := strings.TrimSpace(scanner.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		ret = append(ret, RegexPrefix+"^"+regexp.QuoteMeta(l)+"$")
+	}
+	return ret, scanner.Err()
+}
+
+func anyMatch(rs []*regexp.Regexp, s string) bool {
+	for _, r := range rs {
+		if r.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
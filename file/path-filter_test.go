@@ -0,0 +1,120 @@
+package file
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathFilterMatch(t *testing.T) {
+	root := filepath.FromSlash("/data")
+	tests := []struct {
+		skip    string
+		include []string
+		exclude []string
+		hidden  bool
+		path    string
+		want    bool
+	}{
+		{"", nil, nil, false, "a/b.jpg", true},
+		{"", nil, nil, false, "a/.b.jpg", false},
+		{"", nil, nil, true, "a/.b.jpg", true},
+		{"", nil, nil, false, ".thumbs/b.jpg", false},
+		{"", nil, nil, false, "a/Thumbs.db", false},
+		{"", nil, nil, false, "x.zip!/__MACOSX/b.jpg", false},
+		{"", []string{"*.jpg"}, nil, false, "a/b.JPG", true},
+		{"", []string{"*.jpg"}, nil, false, "a/b.png", false},
+		{"", []string{"*.jpg"}, nil, false, "a/.b.jpg", false},
+		{"", []string{"*.jpg"}, nil, true, "a/.b.jpg", true},
+		{"", []string{"*.jpg"}, nil, false, "x.zip!/b.jpg", true},
+		{"", []string{"raw/**/*.jpg"}, nil, false, "raw/b.jpg", true},
+		{"", []string{"raw/**/*.jpg"}, nil, false, "raw/c/d/b.jpg", true},
+		{"", []string{"raw/**/*.jpg"}, nil, false, "a/raw/b.jpg", false},
+		{"", []string{"raw"}, nil, false, "a/raw/b.png", true},
+		{"", []string{"IMG_00[0-4]?.jpg"}, nil, false, "IMG_0042.jpg", true},
+		{"", []string{"IMG_00[!0-4]?.jpg"}, nil, false, "IMG_0042.jpg", false},
+		{"", []string{`re:DJI_\d+`}, nil, false, "a/DJI_0001.JPG", true},
+		{"", []string{`re:DJI_\d+`}, nil, false, "a/dji_0001.JPG", false},
+		{"", []string{"*.jpg"}, []string{"small"}, false, "small/b.jpg", false},
+		{"", nil, []string{"*.png"}, false, "a/b.png", false},
+		{"", nil, []string{"*.png"}, false, "a/b.jpg", true},
+		{"b", []string{"*.jpg"}, nil, false, "a/b.jpg", false},
+	}
+	for _, tt := range tests {
+		f, err := NewPathFilter(tt.skip, tt.include, tt.exclude, tt.hidden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := filepath.Join(root, filepath.FromSlash(tt.path))
+		if got := f.Match(root, p); got != tt.want {
+			t.Errorf("Match(%q) by %q %q = %v, want %v", tt.path, tt.include, tt.exclude, got, tt.want)
+		}
+	}
+}
+
+func TestPathFilterSkipDir(t *testing.T) {
+	root := filepath.FromSlash("/data")
+	tests := []struct {
+		include []string
+		exclude []string
+		path    string
+		want    bool
+	}{
+		{nil, nil, ".", false},
+		{nil, nil, "a", false},
+		{nil, nil, ".git", true},
+		{nil, nil, "__MACOSX", true},
+		{[]string{".git/*.jpg"}, nil, ".git", true},
+		{nil, []string{"small"}, "a/small", true},
+		{nil, []string{"*.jpg"}, "a", false},
+	}
+	for _, tt := range tests {
+		f, err := NewPathFilter("", tt.include, tt.exclude, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := filepath.Join(root, filepath.FromSlash(tt.path))
+		if got := f.SkipDir(root, p); got != tt.want {
+			t.Errorf("SkipDir(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPathList(t *testing.T) {
+	root := filepath.FromSlash("/data")
+	var buf bytes.Buffer
+	ps := []string{"a/b (1).jpg", "c.jpg", "x.zip!/d.jpg"}
+	var abs []string
+	for _, p := range ps {
+		abs = append(abs, filepath.Join(root, filepath.FromSlash(p)))
+	}
+	if err := WritePathList(&buf, root, abs); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteString("# comment\n\n")
+	exclude, err := ReadPathList(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewPathFilter("", nil, exclude, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"a/b (1).jpg", false},
+		{"c.jpg", false},
+		{"x.zip!/d.jpg", false},
+		{"a/b (1).jpg.png", true},
+		{"a/c.jpg", true},
+		{"a/b.jpg", true},
+	}
+	for _, tt := range tests {
+		p := filepath.Join(root, filepath.FromSlash(tt.path))
+		if got := f.Match(root, p); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package file
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// WritePose writes the GPS of the geotagged images in the format of pose.txt,
+// i.e. one 'filename latitude longitude altitude' per line, sorted by filename.
+// Images without GPS are skipped.
+// Return the number of written images.
+func WritePose(w io.Writer, imgs []ImageDigest) (int, error) {
+	var geo []ImageDigest
+	for _, img := range imgs {
+		if img.Exif != nil && img.Exif.HasGPS {
+			geo = append(geo, img)
+		}
+	}
+	sort.Slice(geo, func(i, j int) bool {
+		return geo[i].Filename < geo[j].Filename
+	})
+
+	for _, img := range geo {
+		e := img.Exif
+		_, err := fmt.Fprintf(w, "%s %.8f %.8f %.3f\n", img.Filename, e.Lat, e.Lng, e.Alt)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(geo), nil
+}
@@ -0,0 +1,45 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWritePose(t *testing.T) {
+	a := ImageDigest{Filename: "a.jpg", Exif: &ExifInfo{HasGPS: true, Lat: 22.3361, Lng: 114.2654, Alt: 100.5}}
+	b := ImageDigest{Filename: "b.jpg", Exif: &ExifInfo{HasGPS: true, Lat: -33.8688, Lng: 151.2093, Alt: -2}}
+	noGPS := ImageDigest{Filename: "c.jpg", Exif: &ExifInfo{FocalLength: 35}}
+	noExif := ImageDigest{Filename: "d.jpg"}
+
+	tests := []struct {
+		name  string
+		imgs  []ImageDigest
+		want  string
+		wantN int
+	}{
+		{"empty", nil, "", 0},
+		{"skip no gps", []ImageDigest{noGPS, noExif}, "", 0},
+		{
+			"sorted",
+			[]ImageDigest{b, noExif, a},
+			"a.jpg 22.33610000 114.26540000 100.500\nb.jpg -33.86880000 151.20930000 -2.000\n",
+			2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := WritePose(&buf, tt.imgs)
+			if err != nil {
+				t.Errorf("WritePose() error = %v", err)
+				return
+			}
+			if n != tt.wantN {
+				t.Errorf("WritePose() = %v, want %v", n, tt.wantN)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WritePose() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
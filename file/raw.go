@@ -0,0 +1,190 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// RawJPEG is the format that the RAW images are converted to.
+const RawJPEG = "jpeg"
+
+// DefaultRawQuality is the default JPEG quality of the converted RAW images.
+const DefaultRawQuality = 95
+
+// rawExts maps the extensions of the TIFF based RAW images to their mime types.
+var rawExts = map[string]string{
+	".dng": "image/x-adobe-dng",
+	".cr2": "image/x-canon-cr2",
+	".nef": "image/x-nikon-nef",
+	".arw": "image/x-sony-arw",
+}
+
+// rawType gives the mime type of the RAW image of header and extension ext,
+// empty if it is not. DNG, NEF and ARW are plain TIFF, so they are only told
+// apart by the extension.
+func rawType(header []byte, ext string) string {
+	if len(header) < 10 {
+		return ""
+	}
+	tiff := bytes.HasPrefix(header, []byte("II*\x00")) || bytes.HasPrefix(header, []byte("MM\x00*"))
+	if !tiff {
+		return ""
+	}
+	if string(header[8:10]) == "CR" {
+		return rawExts[".cr2"]
+	}
+	return rawExts[strings.ToLower(ext)]
+}
+
+// IsRawType tells if the mime type t is of a RAW image.
+func IsRawType(t string) bool {
+	for _, r := range rawExts {
+		if t == r {
+			return true
+		}
+	}
+	return false
+}
+
+// RawCopyName gives the filename of the JPEG copy of quality of the RAW image
+// of checksum sum.
+func RawCopyName(sum string, quality int) string {
+	return fmt.Sprintf("%s_q%d.jpg", sum, quality)
+}
+
+// JPEGName gives the filename of name with its extension replaced by '.jpg'.
+func JPEGName(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ".jpg"
+}
+
+// DcrawPath gives the path of the dcraw executable in PATH.
+func DcrawPath() (string, error) {
+	p, err := exec.LookPath("dcraw")
+	if err != nil {
+		return "", errors.ErrDcrawNotFound
+	}
+	return p, nil
+}
+
+// ConvertRaw converts the RAW image raw to the JPEG dst of quality by dcraw,
+// which demosaics it by the camera white balance and rotates it upright.
+// RAW images in zip archives are extracted to a temp file first.
+func ConvertRaw(ctx context.Context, dcraw, raw, dst string, quality int) error {
+	if _, _, ok := SplitArchivePath(raw); ok {
+		tmp, err := extractTemp(raw)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp)
+		raw = tmp
+	}
+	c := exec.CommandContext(ctx, dcraw, "-c", "-w", raw)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = c.Start(); err != nil {
+		return err
+	}
+	img, decErr := decodePPM(bufio.NewReader(out))
+	// drain the rest, so that dcraw is not blocked on writing
+	io.Copy(io.Discard, out)
+	if err = c.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	if decErr != nil {
+		return decErr
+	}
+
+	// written atomically, so that a partial one is never digested
+	return writeAtomic(dst, func(w io.Writer) error {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	})
+}
+
+// extractTemp extracts the file p of a zip archive to a temp file of the same
+// extension. Return its path.
+func extractTemp(p string) (string, error) {
+	src, err := OpenFile(p)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	f, err := os.CreateTemp("", "alti-raw-*"+filepath.Ext(p))
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(f, src); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// decodePPM decodes the binary PPM (P6) of 8 or 16 bits per sample.
+func decodePPM(r *bufio.Reader) (image.Image, error) {
+	var magic string
+	var w, h, max int
+	if _, err := fmt.Fscan(r, &magic, &w, &h, &max); err != nil {
+		return nil, fmt.Errorf("invalid ppm header: %v", err)
+	}
+	if magic != "P6" || w <= 0 || h <= 0 || max <= 0 || max > 65535 {
+		return nil, fmt.Errorf("unsupported ppm: %s %dx%d max %d", magic, w, h, max)
+	}
+	// a single whitespace separates the header from the samples
+	if _, err := r.ReadByte(); err != nil {
+		return nil, err
+	}
+
+	size := 1
+	if max > 255 {
+		size = 2
+	}
+	row := make([]byte, w*3*size)
+	// scaled to 8 bits, as the JPEG is of 8 bits anyway
+	sample := func(b []byte) uint8 {
+		v := uint32(b[0])
+		if size == 2 {
+			v = v<<8 | uint32(b[1])
+		}
+		return uint8(v * 255 / uint32(max))
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		if _, err := io.ReadFull(r, row); err != nil {
+			return nil, err
+		}
+		for x := 0; x < w; x++ {
+			px := row[x*3*size:]
+			img.SetRGBA(x, y, color.RGBA{
+				R: sample(px),
+				G: sample(px[size:]),
+				B: sample(px[2*size:]),
+				A: 0xff,
+			})
+		}
+	}
+	return img, nil
+}
@@ -0,0 +1,84 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestRawType(t *testing.T) {
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x00\x00")
+	tests := []struct {
+		name   string
+		header []byte
+		ext    string
+		want   string
+	}{
+		{"dng", tiff, ".DNG", "image/x-adobe-dng"},
+		{"nef", []byte("MM\x00*\x00\x00\x00\x08\x00\x00"), ".nef", "image/x-nikon-nef"},
+		{"arw", tiff, ".arw", "image/x-sony-arw"},
+		{"cr2", []byte("II*\x00\x10\x00\x00\x00CR\x02\x00"), ".jpg", "image/x-canon-cr2"},
+		{"tiff", tiff, ".tif", ""},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), ".nef", ""},
+		{"short", []byte("II*\x00"), ".dng", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rawType(tt.header, tt.ext)
+			if got != tt.want {
+				t.Errorf("rawType() = %v, want %v", got, tt.want)
+			}
+			if got != "" && !IsRawType(got) {
+				t.Errorf("IsRawType(%v) = false", got)
+			}
+		})
+	}
+}
+
+func TestDecodePPM(t *testing.T) {
+	tests := []struct {
+		name    string
+		ppm     string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{"8 bits", "P6\n2 1\n255\n\x10\x20\x30\x40\x50\x60", color.RGBA{0x40, 0x50, 0x60, 0xff}, false},
+		{"16 bits", "P6 2 1 65535\n\x00\x00\x00\x00\x00\x00\xff\xff\x80\x80\x00\x00", color.RGBA{0xff, 0x80, 0x00, 0xff}, false},
+		{"ascii", "P3\n2 1\n255\n1 2 3 4 5 6", color.RGBA{}, true},
+		{"truncated", "P6\n2 1\n255\n\x10\x20\x30", color.RGBA{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := decodePPM(bufio.NewReader(bytes.NewBufferString(tt.ppm)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodePPM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if b := img.Bounds(); b.Dx() != 2 || b.Dy() != 1 {
+				t.Fatalf("decodePPM() bounds = %v, want 2x1", b)
+			}
+			if got := color.RGBAModel.Convert(img.At(1, 0)); got != tt.want {
+				t.Errorf("decodePPM() At(1, 0) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJPEGName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"IMG_0001.CR2", "IMG_0001.jpg"},
+		{"a/b.dng", "a/b.jpg"},
+		{"noext", "noext.jpg"},
+	}
+	for _, tt := range tests {
+		if got := JPEGName(tt.name); got != tt.want {
+			t.Errorf("JPEGName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
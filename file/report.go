@@ -0,0 +1,147 @@
+package file
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Formats of the digest report.
+const (
+	ReportCSV  = "csv"
+	ReportJSON = "json"
+)
+
+// ReportFormat gives the format of the digest report of path p by its
+// extension, i.e. '.csv' or '.json'.
+func ReportFormat(p string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(p)); ext {
+	case ".csv":
+		return ReportCSV, nil
+	case ".json":
+		return ReportJSON, nil
+	default:
+		return "", fmt.Errorf("unknown report format %q of %q, expect .csv or .json", ext, p)
+	}
+}
+
+// DigestRecord is the properties of a digested file in the report.
+type DigestRecord struct {
+	Path     string   `json:"path"`
+	Filename string   `json:"filename"`
+	Type     string   `json:"type"`
+	Width    int      `json:"width"`
+	Height   int      `json:"height"`
+	GP       float64  `json:"gp"`
+	Size     int64    `json:"size"`
+	Checksum string   `json:"checksum"`
+	Issues   []string `json:"issues,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// NewDigestRecord gives the record of the digest d.
+func NewDigestRecord(d ImageDigest) DigestRecord {
+	r := DigestRecord{
+		Path:     d.Path,
+		Filename: d.Filename,
+		Type:     d.Filetype,
+		Width:    d.Width,
+		Height:   d.Height,
+		GP:       d.GP,
+		Size:     d.Filesize,
+		Checksum: d.Checksum,
+		Issues:   d.Issues,
+	}
+	if r.Filename == "" {
+		r.Filename = filepath.Base(d.Path)
+	}
+	if d.Error != nil {
+		r.Error = d.Error.Error()
+	}
+	return r
+}
+
+// digestHeader is the header of the csv report.
+var digestHeader = []string{"Path", "Filename", "Type", "Width", "Height", "GP", "Size", "Checksum", "Issues", "Error"}
+
+// DigestReport writes the records of the digested files as they come, in
+// csv of a header row, or in a json array.
+type DigestReport struct {
+	w      io.Writer
+	format string
+	csv    *csv.Writer
+	n      int
+}
+
+// NewDigestReport creates a report of format writing to w.
+func NewDigestReport(w io.Writer, format string) (*DigestReport, error) {
+	r := &DigestReport{w: w, format: format}
+	switch format {
+	case ReportCSV:
+		r.csv = csv.NewWriter(w)
+		if err := r.csv.Write(digestHeader); err != nil {
+			return nil, err
+		}
+	case ReportJSON:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+	return r, nil
+}
+
+// Write writes the record of the digest d.
+func (r *DigestReport) Write(d ImageDigest) error {
+	rec := NewDigestRecord(d)
+	r.n++
+	if r.csv != nil {
+		return r.csv.Write([]string{
+			rec.Path,
+			rec.Filename,
+			rec.Type,
+			strconv.Itoa(rec.Width),
+			strconv.Itoa(rec.Height),
+			strconv.FormatFloat(rec.GP, 'f', -1, 64),
+			strconv.FormatInt(rec.Size, 10),
+			rec.Checksum,
+			strings.Join(rec.Issues, "; "),
+			rec.Error,
+		})
+	}
+	b, err := json.MarshalIndent(rec, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if r.n == 1 {
+		sep = "\n  "
+	}
+	_, err = fmt.Fprintf(r.w, "%s%s", sep, b)
+	return err
+}
+
+// Len gives the number of the written records.
+func (r *DigestReport) Len() int {
+	return r.n
+}
+
+// Close flushes the report, and closes the json array. The underlying writer
+// is not closed.
+func (r *DigestReport) Close() error {
+	if r.csv != nil {
+		r.csv.Flush()
+		return r.csv.Error()
+	}
+	end := "]\n"
+	if r.n > 0 {
+		end = "\n]\n"
+	}
+	_, err := io.WriteString(r.w, end)
+	return err
+}
@@ -0,0 +1,89 @@
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReportFormat(t *testing.T) {
+	tests := []struct {
+		p       string
+		want    string
+		wantErr bool
+	}{
+		{"report.csv", ReportCSV, false},
+		{"out/Report.JSON", ReportJSON, false},
+		{"report.txt", "", true},
+		{"report", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ReportFormat(tt.p)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ReportFormat(%q) = %q, %v, want %q", tt.p, got, err, tt.want)
+		}
+	}
+}
+
+var reportDigests = []ImageDigest{
+	{Path: "a/1.jpg", Filename: "1.jpg", Filetype: "image/jpeg", Width: 4000, Height: 3000, GP: 0.012, Filesize: 1024, Checksum: "abc", Issues: []string{"blurred", "dark"}},
+	{Path: "a/notes.txt", Error: errors.New("not image")},
+}
+
+func TestDigestReport_CSV(t *testing.T) {
+	var b bytes.Buffer
+	r, err := NewDigestReport(&b, ReportCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range reportDigests {
+		if err = r.Write(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "Path,Filename,Type,Width,Height,GP,Size,Checksum,Issues,Error\n" +
+		"a/1.jpg,1.jpg,image/jpeg,4000,3000,0.012,1024,abc,blurred; dark,\n" +
+		"a/notes.txt,notes.txt,,0,0,0,0,,,not image\n"
+	if got := b.String(); got != want {
+		t.Errorf("csv report = %q, want %q", got, want)
+	}
+}
+
+func TestDigestReport_JSON(t *testing.T) {
+	for _, n := range []int{0, len(reportDigests)} {
+		var b bytes.Buffer
+		r, err := NewDigestReport(&b, ReportJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range reportDigests[:n] {
+			if err = r.Write(d); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		var got []DigestRecord
+		if err = json.Unmarshal(b.Bytes(), &got); err != nil {
+			t.Fatalf("json report of %d records is invalid: %v\n%s", n, err, b.String())
+		}
+		if len(got) != n {
+			t.Fatalf("json report has %d records, want %d", len(got), n)
+		}
+		for i, d := range reportDigests[:n] {
+			if want := NewDigestRecord(d); !reflect.DeepEqual(got[i], want) {
+				t.Errorf("record %d = %+v, want %+v", i, got[i], want)
+			}
+		}
+		if n > 0 && !strings.Contains(b.String(), `"error": "not image"`) {
+			t.Errorf("json report misses the error: %s", b.String())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+)
+
+// DefaultJPEGQuality is the default JPEG quality of the resized images.
+const DefaultJPEGQuality = 92
+
+// ResizedName gives the filename of the copy of an image of checksum sum,
+// resized to maxDim at quality.
+func ResizedName(sum string, maxDim, quality int) string {
+	return fmt.Sprintf("%s_%d_q%d.jpg", sum, maxDim, quality)
+}
+
+// WriteResized writes the JPEG copy of the image p of orientation o to dst,
+// downsized so that its longer side is at most maxDim. The copy is upright.
+// The EXIF of a JPEG that is not rotated is kept, e.g. for its GPS.
+func WriteResized(p string, o, maxDim, quality int, dst string) error {
+	f, err := OpenFile(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, format, err := image.Decode(f)
+	if err != nil {
+		return err
+	}
+	var exif []byte
+	if format == "jpeg" && o <= 1 {
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if exif, err = exifSegment(f); err != nil {
+			return err
+		}
+	}
+
+	var b bytes.Buffer
+	if err = jpeg.Encode(&b, Upright(Thumbnail(img, maxDim), o), &jpeg.Options{Quality: quality}); err != nil {
+		return err
+	}
+	// written atomically, so that a partial one is never digested
+	return writeAtomic(dst, func(w io.Writer) error {
+		_, err := w.Write(withSegment(b.Bytes(), exif))
+		return err
+	})
+}
+
+// exifSegment gives the APP1 segment of the EXIF of the JPEG r, including its
+// marker and length, nil if there is none.
+func exifSegment(r io.Reader) ([]byte, error) {
+	br := bufio.NewReader(r)
+	var soi [2]byte
+	if _, err := io.ReadFull(br, soi[:]); err != nil {
+		return nil, err
+	}
+	if soi != [2]byte{0xff, 0xd8} {
+		return nil, fmt.Errorf("not a jpeg")
+	}
+	for {
+		var hdr [4]byte
+		if _, err := io.ReadFull(br, hdr[:2]); err != nil {
+			return nil, err
+		}
+		if hdr[0] != 0xff {
+			return nil, fmt.Errorf("invalid jpeg marker %#x", hdr[0])
+		}
+		// no more metadata after the start of scan
+		if hdr[1] == 0xda || hdr[1] == 0xd9 {
+			return nil, nil
+		}
+		if _, err := io.ReadFull(br, hdr[2:]); err != nil {
+			return nil, err
+		}
+		n := int(binary.BigEndian.Uint16(hdr[2:]))
+		if n < 2 {
+			return nil, fmt.Errorf("invalid jpeg segment length %d", n)
+		}
+		seg := make([]byte, 2+n)
+		copy(seg, hdr[:])
+		if _, err := io.ReadFull(br, seg[4:]); err != nil {
+			return nil, err
+		}
+		if hdr[1] == 0xe1 && bytes.HasPrefix(seg[4:], []byte("Exif\x00\x00")) {
+			return seg, nil
+		}
+	}
+}
+
+// withSegment inserts the segment seg right after the SOI of the JPEG jpg.
+func withSegment(jpg, seg []byte) []byte {
+	if len(seg) == 0 || len(jpg) < 2 {
+		return jpg
+	}
+	ret := make([]byte, 0, len(jpg)+len(seg))
+	ret = append(ret, jpg[:2]...)
+	ret = append(ret, seg...)
+	return append(ret, jpg[2:]...)
+}
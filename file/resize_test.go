@@ -0,0 +1,101 @@
+package file
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExifSegment(t *testing.T) {
+	exif := []byte("\xff\xe1\x00\x0aExif\x00\x00MM")
+	jfif := []byte("\xff\xe0\x00\x07JFIF\x00")
+	tests := []struct {
+		name    string
+		jpg     []byte
+		want    []byte
+		wantErr bool
+	}{
+		{"exif", concat("\xff\xd8", exif, "\xff\xda"), exif, false},
+		{"after jfif", concat("\xff\xd8", jfif, exif, "\xff\xda"), exif, false},
+		{"none", concat("\xff\xd8", jfif, "\xff\xda"), nil, false},
+		{"not jpeg", []byte("\x89PNG"), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exifSegment(bytes.NewReader(tt.jpg))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("exifSegment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("exifSegment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteResized(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var b bytes.Buffer
+	if err = jpeg.Encode(&b, image.NewGray(image.Rect(0, 0, 40, 20)), nil); err != nil {
+		t.Fatal(err)
+	}
+	exif := []byte("\xff\xe1\x00\x0aExif\x00\x00MM")
+	src := filepath.Join(dir, "src.jpg")
+	if err = ioutil.WriteFile(src, withSegment(b.Bytes(), exif), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		o        int
+		size     image.Point
+		wantExif bool
+	}{
+		{1, image.Pt(10, 5), true},
+		{6, image.Pt(5, 10), false},
+	}
+	for _, tt := range tests {
+		dst := filepath.Join(dir, ResizedName("sum", 10, tt.o))
+		if err := WriteResized(src, tt.o, 10, 90, dst); err != nil {
+			t.Fatalf("WriteResized(%d) error = %v", tt.o, err)
+		}
+		f, err := os.Open(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := jpeg.DecodeConfig(f)
+		f.Seek(0, 0)
+		seg, _ := exifSegment(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := image.Pt(c.Width, c.Height); s != tt.size {
+			t.Errorf("WriteResized(%d) size = %v, want %v", tt.o, s, tt.size)
+		}
+		if got := bytes.Equal(seg, exif); got != tt.wantExif {
+			t.Errorf("WriteResized(%d) kept exif = %v, want %v", tt.o, got, tt.wantExif)
+		}
+	}
+}
+
+func concat(segs ...interface{}) []byte {
+	var b []byte
+	for _, s := range segs {
+		switch v := s.(type) {
+		case string:
+			b = append(b, v...)
+		case []byte:
+			b = append(b, v...)
+		}
+	}
+	return b
+}
@@ -0,0 +1,87 @@
+package file
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+)
+
+// DefaultThumbSize is the default longer side of the thumbnails in pixels.
+const DefaultThumbSize = 256
+
+// ThumbSize gives the dimension of the thumbnail of an image of w x h, whose
+// longer side is at most side. The aspect ratio is kept and no side is zero.
+func ThumbSize(w, h, side int) (int, int) {
+	if w <= side && h <= side {
+		return w, h
+	}
+	if w >= h {
+		return side, max(1, (h*side+w/2)/w)
+	}
+	return max(1, (w*side+h/2)/h), side
+}
+
+// ThumbName gives the filename of the thumbnail of an image of checksum sum.
+func ThumbName(sum string, side int) string {
+	return fmt.Sprintf("%s-%d.jpg", sum, side)
+}
+
+// Thumbnail downsizes img by averaging the pixels of each box, so that its
+// longer side is at most side.
+func Thumbnail(img image.Image, side int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	src := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	dw, dh := ThumbSize(w, h, side)
+	if dw == w && dh == h {
+		return src
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := dy*h/dh, max((dy+1)*h/dh, dy*h/dh+1)
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := dx*w/dw, max((dx+1)*w/dw, dx*w/dw+1)
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				i := src.PixOffset(x0, y)
+				for x := x0; x < x1; x++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[i+c])
+					}
+					i += 4
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			j := dst.PixOffset(dx, dy)
+			for c := 0; c < 4; c++ {
+				dst.Pix[j+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// WriteThumbnail writes the upright JPEG thumbnail of the image p of EXIF
+// orientation o to dst, whose longer side is at most side.
+// Only the formats registered in package image are supported.
+func WriteThumbnail(p string, o, side int, dst string) error {
+	f, err := OpenFile(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return err
+	}
+
+	// written atomically, so that a partial one is never reused
+	thumb := Upright(Thumbnail(img, side), o)
+	return writeAtomic(dst, func(w io.Writer) error {
+		return jpeg.Encode(w, thumb, &jpeg.Options{Quality: 85})
+	})
+}
@@ -0,0 +1,60 @@
+package file
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbSize(t *testing.T) {
+	tests := []struct {
+		w, h, side   int
+		wantW, wantH int
+	}{
+		{4000, 3000, 256, 256, 192},
+		{3000, 4000, 256, 192, 256},
+		{200, 100, 256, 200, 100},
+		{256, 256, 256, 256, 256},
+		{10000, 10, 256, 256, 1},
+	}
+	for _, tt := range tests {
+		w, h := ThumbSize(tt.w, tt.h, tt.side)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("ThumbSize(%d, %d, %d) = %d x %d, want %d x %d", tt.w, tt.h, tt.side, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// 4 x 2 image of black left half and white right half
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			c := uint8(0)
+			if x >= 2 {
+				c = 255
+			}
+			img.Set(x, y, color.NRGBA{c, c, c, 255})
+		}
+	}
+
+	tests := []struct {
+		side int
+		size image.Point
+		left uint8 // red of the top-left pixel
+	}{
+		{8, image.Pt(4, 2), 0},
+		{2, image.Pt(2, 1), 0},
+		{1, image.Pt(1, 1), 127},
+	}
+	for _, tt := range tests {
+		got := Thumbnail(img, tt.side).(*image.NRGBA)
+		if s := got.Bounds().Size(); s != tt.size {
+			t.Errorf("Thumbnail(%d) size = %v, want %v", tt.side, s, tt.size)
+			continue
+		}
+		if r := got.NRGBAAt(0, 0).R; r != tt.left {
+			t.Errorf("Thumbnail(%d) top-left = %d, want %d", tt.side, r, tt.left)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// FFmpegPath gives the path of the ffmpeg executable in PATH.
+func FFmpegPath() (string, error) {
+	p, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return "", errors.ErrFFmpegNotFound
+	}
+	return p, nil
+}
+
+// FramePrefix gives the filename prefix of the frames of a video, i.e. its
+// name without extension, so that frames of different videos are not mixed.
+func FramePrefix(video string) string {
+	base := filepath.Base(video)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// ExtractFrames extracts the frames of video at fps frames per second into
+// dir by ffmpeg, as the JPEGs named by FramePrefix and a 6-digit sequence.
+// Return the sorted paths of the frames.
+func ExtractFrames(ctx context.Context, ffmpeg, video, dir string, fps float64) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	prefix := FramePrefix(video)
+	out := filepath.Join(dir, prefix+"_%06d.jpg")
+	c := exec.CommandContext(ctx, ffmpeg,
+		"-hide_banner", "-loglevel", "error", "-nostdin", "-y",
+		"-i", video,
+		"-vf", fmt.Sprintf("fps=%g", fps),
+		"-qscale:v", "2",
+		out,
+	)
+	if msg, err := c.CombinedOutput(); err != nil {
+		if len(msg) > 0 {
+			return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(string(msg)))
+		}
+		return nil, err
+	}
+	return framePaths(dir, prefix)
+}
+
+// framePaths gives the sorted paths of the frames of prefix in dir.
+func framePaths(dir, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for _, e := range entries {
+		n := e.Name()
+		if !e.IsDir() && strings.HasPrefix(n, prefix+"_") && strings.HasSuffix(n, ".jpg") {
+			ret = append(ret, filepath.Join(dir, n))
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// KeepSharpest keeps the sharpest frame of each run of n consecutive frames
+// and removes the others. Frames that could not be analyzed are the least
+// sharp. Return the kept paths.
+func KeepSharpest(paths []string, n int) ([]string, error) {
+	scores := make([]float64, len(paths))
+	for i, p := range paths {
+		scores[i] = -1
+		if q, err := AnalyzeQuality(p); err == nil {
+			scores[i] = q.Sharpness
+		}
+	}
+	var ret []string
+	keep := sharpestOfEach(scores, n)
+	for i, p := range paths {
+		if keep[i] {
+			ret = append(ret, p)
+			continue
+		}
+		if err := os.Remove(p); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// sharpestOfEach marks the index of the highest score of each run of n
+// consecutive scores, the first one if tied.
+func sharpestOfEach(scores []float64, n int) []bool {
+	if n < 1 {
+		n = 1
+	}
+	ret := make([]bool, len(scores))
+	for i := 0; i < len(scores); i += n {
+		best := i
+		for j := i + 1; j < i+n && j < len(scores); j++ {
+			if scores[j] > scores[best] {
+				best = j
+			}
+		}
+		ret[best] = true
+	}
+	return ret
+}
@@ -0,0 +1,43 @@
+package file
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSharpestOfEach(t *testing.T) {
+	tests := []struct {
+		scores []float64
+		n      int
+		want   []bool
+	}{
+		{[]float64{1, 3, 2, 5, 4}, 2, []bool{false, true, false, true, true}},
+		{[]float64{1, 3, 2, 5, 4}, 3, []bool{false, true, false, true, false}},
+		{[]float64{1, 3, 2}, 1, []bool{true, true, true}},
+		{[]float64{-1, -1, 2}, 3, []bool{false, false, true}},
+		{[]float64{2, 2}, 2, []bool{true, false}},
+		{nil, 2, []bool{}},
+	}
+	for _, tt := range tests {
+		got := sharpestOfEach(tt.scores, tt.n)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sharpestOfEach(%v, %d) = %v, want %v", tt.scores, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFramePrefix(t *testing.T) {
+	tests := []struct {
+		video string
+		want  string
+	}{
+		{"/data/flight 01.mp4", "flight 01"},
+		{"clip.MOV", "clip"},
+		{"noext", "noext"},
+	}
+	for _, tt := range tests {
+		if got := FramePrefix(tt.video); got != tt.want {
+			t.Errorf("FramePrefix(%q) = %q, want %q", tt.video, got, tt.want)
+		}
+	}
+}
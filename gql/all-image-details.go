@@ -0,0 +1,82 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// AllImageDetails queries the project images by cursor, along with their
+// upload dates, giga-pixels and errors.
+func AllImageDetails(pid string, first int, after string) ([]types.ImageDetail, *types.PageInfo, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($id: ID!, $first: Int, $after: String) {
+			project(id: $id) {
+				allImages(first: $first, after: $after) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+					edges {
+						node {
+							id
+							name
+							filename
+							state
+							url
+							date
+							gigaPixel
+							error
+						}
+					}
+				}
+			}
+		}
+	`)
+	req.Var("id", pid)
+	if first > 0 {
+		req.Var("first", first)
+	}
+	req.Var("after", after)
+
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// run it and capture the response
+	var res allImgDetailsRes
+	if err := client.Run(context.Background(), req, &res); err != nil {
+		switch err.(type) {
+		case *url.Error:
+			return nil, nil, errors.ErrOffline
+		default:
+			return nil, nil, err
+		}
+	}
+
+	var ret []types.ImageDetail
+	for _, e := range res.Project.AllImages.Edges {
+		ret = append(ret, e.Node)
+	}
+	pi := res.Project.AllImages.PageInfo
+	return ret, &pi, nil
+}
+
+type allImgDetailsRes struct {
+	Project struct {
+		AllImages struct {
+			PageInfo types.PageInfo
+			Edges    []struct {
+				Node types.ImageDetail
+			}
+		}
+	}
+}
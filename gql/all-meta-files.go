@@ -0,0 +1,73 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// AllMetaFiles queries all of the meta files of a project.
+func AllMetaFiles(pid string) ([]types.MetaFile, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($id: ID!) {
+			project(id: $id) {
+				allMetaFiles {
+					edges {
+						node {
+							id
+							state
+							name
+							filename
+							filesize
+							date
+							checksum
+							url
+						}
+					}
+				}
+			}
+		}
+	`)
+	req.Var("id", pid)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// define a Context for the request
+	ctx := context.Background()
+
+	// run it and capture the response
+	var res allMetaFilesRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		switch err.(type) {
+		case *url.Error:
+			return nil, errors.ErrOffline
+		default:
+			return nil, err
+		}
+	}
+
+	var ret []types.MetaFile
+	for _, e := range res.Project.AllMetaFiles.Edges {
+		ret = append(ret, e.Node)
+	}
+	return ret, nil
+}
+
+type allMetaFilesRes struct {
+	Project struct {
+		AllMetaFiles struct {
+			Edges []struct {
+				Node types.MetaFile
+			}
+		}
+	}
+}
@@ -11,10 +11,10 @@ import (
 )
 
 // AllProjectImages queries all of the project images by cursor.
-func AllProjectImages(pid string, first, last int, before, after string) ([]types.ProjectImage, *types.PageInfo, int, error) {
+func AllProjectImages(ctx context.Context, pid string, first, last int, before, after string) ([]types.ProjectImage, *types.PageInfo, int, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -55,9 +55,6 @@ func AllProjectImages(pid string, first, last int, before, after string) ([]type
 	req.Header.Set("key", active.Key)
 	req.Header.Set("altitoken", active.Token)
 
-	// define a Context for the request
-	ctx := context.Background()
-
 	// run it and capture the response
 	var res allImgsRes
 	if err := client.Run(ctx, req, &res); err != nil {
@@ -3,6 +3,7 @@ package gql
 import (
 	"context"
 	"encoding/json"
+	"strings"
 
 	"github.com/jackytck/alti-cli/config"
 	"github.com/machinebox/graphql"
@@ -12,7 +13,7 @@ import (
 func Arbitrary(query string, vars map[string]interface{}) (string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(query)
 
@@ -25,7 +26,11 @@ func Arbitrary(query string, vars map[string]interface{}) (string, error) {
 
 	ctx := context.Background()
 	var res json.RawMessage
-	if err := client.Run(ctx, req, &res); err != nil {
+	run := client.Run
+	if strings.HasPrefix(operationName(query), "mutation") {
+		run = client.RunMutation
+	}
+	if err := run(ctx, req, &res); err != nil {
 		return "", err
 	}
 	return PrettyPrint(res)
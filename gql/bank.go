@@ -11,7 +11,7 @@ import (
 func CoinsToMoney(coins float64, currency string) (float64, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -37,7 +37,7 @@ func CoinsToMoney(coins float64, currency string) (float64, error) {
 func MoneyToCoins(money float64, currency string) (float64, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -1,31 +1,36 @@
 package gql
 
 import (
-	"strings"
-
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/text"
 )
 
+// bucketType is the known bucket types of each kind and cloud, used if the
+// schema of the api server could not be queried.
 var bucketType = map[string]map[string]string{
 	"image": {
 		"s3":    "BucketS3",
 		"oss":   "BucketOSS",
 		"minio": "BucketMinio",
+		"gcs":   "BucketGCS",
 	},
 	"model": {
 		"s3":    "BucketS3Model",
+		"oss":   "BucketOSSModel",
 		"minio": "BucketMinioModel",
+		"gcs":   "BucketGCSModel",
 	},
 	"meta": {
 		"s3":    "BucketS3",
+		"oss":   "BucketOSSMeta",
 		"minio": "BucketMinioMeta",
+		"gcs":   "BucketGCSMeta",
 	},
 }
 
 // QueryBucket infers the exact bucket name from query string bucket.
 // kind is "image", "model" or "meta".
-// cloud is "s3", "oss" or "minio".
+// cloud is "s3", "oss", "minio" or "gcs".
 func QueryBucket(kind, cloud, bucket string) (string, []string, error) {
 	list, err := BucketList(kind, cloud)
 	if err != nil {
@@ -40,20 +45,11 @@ func QueryBucket(kind, cloud, bucket string) (string, []string, error) {
 
 // BucketList returns a list of available buckets supported by the api server.
 // kind is "image", "model" or "meta".
-// cloud is "s3", "oss" or "minio".
+// cloud is "s3", "oss", "minio" or "gcs".
 func BucketList(kind, cloud string) ([]string, error) {
-	var ret []string
-
-	kind = strings.ToLower(kind)
-	cloud = strings.ToLower(cloud)
-	b, ok := bucketType[kind]
-	if !ok {
-		return ret, errors.ErrBucketInvalid
-	}
-	t, ok := b[cloud]
-	if !ok {
-		return ret, errors.ErrBucketInvalid
+	t, err := BucketType(kind, cloud)
+	if err != nil {
+		return nil, err
 	}
-
 	return EnumValues(t)
 }
@@ -0,0 +1,136 @@
+package gql
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// uploadKinds and uploadClouds name the upload mutations of each kind and
+// cloud, e.g. uploadModelOSS.
+var uploadKinds = map[string]string{
+	"image": "Image",
+	"model": "Model",
+	"meta":  "MetaFile",
+}
+
+var uploadClouds = map[string]string{
+	"s3":    "S3",
+	"oss":   "OSS",
+	"minio": "Minio",
+	"gcs":   "GCS",
+}
+
+var (
+	uploadBucketsMu sync.Mutex
+	uploadBuckets   map[string]string // upload mutation => bucket type
+)
+
+// BucketType gives the gql enum type of the buckets of kind and cloud, by
+// the bucket argument of its upload mutation in the schema of the api server.
+// It falls back to the known types if the schema could not be queried.
+// kind is "image", "model" or "meta".
+// cloud is "s3", "oss", "minio" or "gcs".
+func BucketType(kind, cloud string) (string, error) {
+	kind = strings.ToLower(kind)
+	cloud = strings.ToLower(cloud)
+	k, ok := uploadKinds[kind]
+	c, ok2 := uploadClouds[cloud]
+	if !ok || !ok2 {
+		return "", errors.ErrBucketInvalid
+	}
+	if bs, err := uploadBucketTypes(); err == nil {
+		if t, ok := bs["upload"+k+c]; ok {
+			return t, nil
+		}
+		return "", errors.ErrBucketInvalid
+	}
+	if t, ok := bucketType[kind][cloud]; ok {
+		return t, nil
+	}
+	return "", errors.ErrBucketInvalid
+}
+
+// uploadBucketTypes queries the bucket type of each upload mutation once.
+func uploadBucketTypes() (map[string]string, error) {
+	uploadBucketsMu.Lock()
+	defer uploadBucketsMu.Unlock()
+	if uploadBuckets != nil {
+		return uploadBuckets, nil
+	}
+
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		{
+			__schema {
+				mutationType {
+					fields {
+						name
+						args {
+							name
+							type {
+								name
+								ofType {
+									name
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+
+	ctx := context.Background()
+	var res mutationTypeRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]string)
+	for _, f := range res.Schema.MutationType.Fields {
+		if !strings.HasPrefix(f.Name, "upload") {
+			continue
+		}
+		for _, a := range f.Args {
+			if a.Name != "bucket" {
+				continue
+			}
+			t := a.Type.Name
+			if t == "" {
+				// non-null
+				t = a.Type.OfType.Name
+			}
+			ret[f.Name] = t
+		}
+	}
+	uploadBuckets = ret
+	return ret, nil
+}
+
+type mutationTypeRes struct {
+	Schema struct {
+		MutationType struct {
+			Fields []struct {
+				Name string
+				Args []struct {
+					Name string
+					Type struct {
+						Name   string
+						OfType struct {
+							Name string
+						}
+					}
+				}
+			}
+		}
+	} `json:"__schema"`
+}
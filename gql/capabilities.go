@@ -0,0 +1,216 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// CapabilitiesTTL is how long the capabilities of a profile are reused.
+var CapabilitiesTTL = time.Hour
+
+// uploadKindList are the kinds of upload.
+var uploadKindList = []string{"image", "model", "meta"}
+
+var (
+	capsMu sync.Mutex
+	caps   map[string]*types.Capabilities // profile id => queried capabilities
+)
+
+// QueryCapabilities queries the capabilities of the api server of the active
+// profile, cached per profile for CapabilitiesTTL. If refresh is set, the
+// cache is ignored.
+func QueryCapabilities(refresh bool) (*types.Capabilities, error) {
+	capsMu.Lock()
+	defer capsMu.Unlock()
+
+	conf := config.Load()
+	active := conf.GetActive()
+	if caps == nil {
+		caps = loadCapabilities()
+	}
+	if c, ok := caps[conf.Active]; ok && !refresh && c.Endpoint == active.Endpoint && time.Since(c.Time) < CapabilitiesTTL {
+		return c, nil
+	}
+
+	c, err := queryCapabilities(active.Endpoint, active.Key)
+	if err != nil {
+		return nil, err
+	}
+	caps[conf.Active] = c
+	saveCapabilities(caps)
+	return c, nil
+}
+
+// Capabilities gives the cached capabilities of the api server of the active
+// profile, querying them if they are not cached or expired.
+func Capabilities() (*types.Capabilities, error) {
+	return QueryCapabilities(false)
+}
+
+// ClearCapabilities removes the cached capabilities of all profiles.
+func ClearCapabilities() error {
+	capsMu.Lock()
+	defer capsMu.Unlock()
+	caps = nil
+	p, err := capabilitiesPath()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// queryCapabilities queries the capabilities of endpoint, falling back to
+// the version and the supported clouds of the older api servers, which reject
+// the query of the unknown capabilities field. Other errors are returned.
+func queryCapabilities(endpoint, key string) (*types.Capabilities, error) {
+	client := NewClient(endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		{
+			versions {
+				api
+			}
+			support {
+				capabilities {
+					clouds {
+						kind
+						clouds
+					}
+					maxFileSizes {
+						kind
+						size
+					}
+					features
+				}
+			}
+		}
+	`)
+	req.Header.Set("key", key)
+
+	ctx := context.Background()
+	var res capabilitiesRes
+	ret := types.Capabilities{
+		Endpoint:     endpoint,
+		Clouds:       make(map[string][]string),
+		MaxFileSizes: make(map[string]int64),
+		Time:         time.Now(),
+	}
+	if err := client.Run(ctx, req, &res); err != nil {
+		if !isUnknownField(err, "capabilities") {
+			if _, ok := err.(*url.Error); ok {
+				return nil, errors.ErrOffline
+			}
+			return nil, err
+		}
+		ver, _ := Version(endpoint, key)
+		if ver == "Offline" {
+			return nil, errors.ErrOffline
+		}
+		ret.Version = ver
+		for _, k := range uploadKindList {
+			ret.Clouds[k] = SupportedCloud(endpoint, key, k)
+		}
+		return &ret, nil
+	}
+
+	c := res.Support.Capabilities
+	ret.Version = res.Versions.API
+	for _, k := range c.Clouds {
+		ret.Clouds[strings.ToLower(k.Kind)] = k.Clouds
+	}
+	for _, s := range c.MaxFileSizes {
+		ret.MaxFileSizes[strings.ToLower(s.Kind)] = s.Size
+	}
+	ret.Features = append([]string{}, c.Features...)
+	return &ret, nil
+}
+
+// isUnknownField tells if err is the validation error of the api server for
+// querying the unknown field, e.g. a field that is added in a later version.
+func isUnknownField(err error, field string) bool {
+	msg := err.Error()
+	if !strings.Contains(msg, `"`+field+`"`) {
+		return false
+	}
+	for _, s := range []string{"Cannot query field", "Unknown field"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// capabilitiesPath gives the path of the cached capabilities.
+func capabilitiesPath() (string, error) {
+	confDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(confDir, "capabilities.json"), nil
+}
+
+// loadCapabilities reads the cached capabilities, empty if not cached.
+func loadCapabilities() map[string]*types.Capabilities {
+	ret := make(map[string]*types.Capabilities)
+	p, err := capabilitiesPath()
+	if err != nil {
+		return ret
+	}
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return ret
+	}
+	json.Unmarshal(b, &ret)
+	return ret
+}
+
+// saveCapabilities writes the cached capabilities, best effort.
+func saveCapabilities(c map[string]*types.Capabilities) {
+	p, err := capabilitiesPath()
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return
+	}
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	ioutil.WriteFile(p, b, 0644)
+}
+
+type capabilitiesRes struct {
+	Versions struct {
+		API string
+	}
+	Support struct {
+		Capabilities struct {
+			Clouds []struct {
+				Kind   string
+				Clouds []string
+			}
+			MaxFileSizes []struct {
+				Kind string
+				Size int64
+			}
+			Features []string
+		}
+	}
+}
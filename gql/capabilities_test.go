@@ -0,0 +1,30 @@
+package gql
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	altiErrors "github.com/jackytck/alti-cli/errors"
+)
+
+func TestIsUnknownField(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unknown field", errors.New(`graphql: Cannot query field "capabilities" on type "Support".`), true},
+		{"unknown other field", errors.New(`graphql: Cannot query field "features" on type "Capabilities".`), false},
+		{"server error", altiErrors.NetworkError{Code: 502, Message: "Bad Gateway"}, false},
+		{"offline", &url.Error{Op: "Post", URL: "http://a/graphql", Err: errors.New("connection refused")}, false},
+		{"auth", errors.New("graphql: unauthorized"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUnknownField(tt.err, "capabilities"); got != tt.want {
+				t.Errorf("isUnknownField() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,14 +4,26 @@ import (
 	"context"
 
 	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
 	"github.com/machinebox/graphql"
 )
 
 // CheckDirectNetwork tests if the api server could reach this client.
 func CheckDirectNetwork(url string) bool {
+	res, err := DirectNetworkTest(url)
+	return err == nil && res == "Success"
+}
+
+// DirectNetworkTest gives the result of the api server reaching url, i.e.
+// 'Success' or the reason of failure.
+func DirectNetworkTest(url string) (string, error) {
+	if c, err := Capabilities(); err == nil && !c.Has(types.FeatureNetworkTest) {
+		return "", errors.ErrFeatureUnsupported
+	}
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		query ($url: String!) {
@@ -27,10 +39,9 @@ func CheckDirectNetwork(url string) bool {
 	ctx := context.Background()
 	var res networkTestRes
 	if err := client.Run(ctx, req, &res); err != nil {
-		return false
+		return "", err
 	}
-	success := res.Support.NetworkTest
-	return success == "Success"
+	return res.Support.NetworkTest, nil
 }
 
 type networkTestRes struct {
@@ -0,0 +1,163 @@
+package gql
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// Retries is the number of retries of a transient failed request.
+var Retries = 3
+
+// RetryWait is the initial wait before retrying, doubled on each retry.
+var RetryWait = time.Second
+
+// Timeout is the timeout of each trial of a request, zero means no timeout.
+var Timeout time.Duration
+
+// Client wraps a gql client with retry of transient errors,
+// i.e. network errors and 5xx responses. Mutations are only retried if they
+// are not sent, see RunMutation.
+type Client struct {
+	client    *graphql.Client
+	Retries   int
+	RetryWait time.Duration
+	Timeout   time.Duration
+}
+
+// NewClient returns a new client of the gql endpoint url with the
+// global retry policy and timeout.
+func NewClient(endpoint string) *Client {
+	var rt http.RoundTripper = statusTransport{config.Transport()}
+	if Trace != nil {
+		rt = traceTransport{rt}
+	}
+	hc := &http.Client{Transport: rt}
+	return &Client{
+		client:    graphql.NewClient(endpoint, graphql.WithHTTPClient(hc)),
+		Retries:   Retries,
+		RetryWait: RetryWait,
+		Timeout:   Timeout,
+	}
+}
+
+// Run runs the request and decodes the response into resp,
+// retrying with exponential backoff and jitter on transient errors.
+// Each trial taking longer than Timeout is retried, and gives ErrAPITimeout
+// if it is the last one. The active token is refreshed before it expires. Return ErrTokenExpired
+// if the token is rejected.
+func (c *Client) Run(ctx context.Context, req *graphql.Request, resp interface{}) error {
+	return c.run(ctx, req, resp, false)
+}
+
+// RunMutation runs the mutation request like Run, but only retries it if it
+// could not be sent, as a mutation that failed or timed out after reaching the
+// server may have taken effect, e.g. transferring coins or registering images.
+func (c *Client) RunMutation(ctx context.Context, req *graphql.Request, resp interface{}) error {
+	return c.run(ctx, req, resp, true)
+}
+
+func (c *Client) run(ctx context.Context, req *graphql.Request, resp interface{}, mutation bool) error {
+	if err := freshToken(ctx, req); err != nil {
+		return err
+	}
+	var err error
+	var timedOut bool
+	for i := 0; ; i++ {
+		timedOut, err = c.runOnce(ctx, req, resp)
+		if err == nil || !retriable(timedOut, err, mutation) || i >= c.Retries {
+			break
+		}
+		select {
+		case <-time.After(backoff(c.RetryWait, i)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if timedOut {
+		return errors.ErrAPITimeout
+	}
+	if err != nil && req.Header.Get("altitoken") != "" && isAuthError(err) {
+		return errors.ErrTokenExpired
+	}
+
+	// server error is not an offline error
+	if ue, ok := err.(*url.Error); ok {
+		if ne, ok := ue.Err.(errors.NetworkError); ok {
+			return ne
+		}
+	}
+	return err
+}
+
+// runOnce runs a trial of the request within Timeout, telling if it is
+// timed out.
+func (c *Client) runOnce(ctx context.Context, req *graphql.Request, resp interface{}) (bool, error) {
+	if c.Timeout <= 0 {
+		return false, c.client.Run(ctx, req, resp)
+	}
+	tctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	err := c.client.Run(tctx, req, resp)
+	return err != nil && ctx.Err() == nil && tctx.Err() == context.DeadlineExceeded, err
+}
+
+// retriable tells if a failed trial is worth retrying. A mutation is only
+// retried if it is not sent.
+func retriable(timedOut bool, err error, mutation bool) bool {
+	if mutation {
+		return !timedOut && isUnsent(err)
+	}
+	return timedOut || isTransient(err)
+}
+
+// isTransient tells if the error is worth retrying.
+func isTransient(err error) bool {
+	_, ok := err.(*url.Error)
+	return ok
+}
+
+// isUnsent tells if the request of the error never reached the server, i.e.
+// the connection could not be made.
+func isUnsent(err error) bool {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return false
+	}
+	oe, ok := ue.Err.(*net.OpError)
+	return ok && oe.Op == "dial"
+}
+
+// backoff gives the wait before the i-th retry, i.e. wait * 2^i
+// randomized by a factor in [0.5, 1.5).
+func backoff(wait time.Duration, i int) time.Duration {
+	d := wait << uint(i)
+	return time.Duration(float64(d) * (0.5 + rand.Float64()))
+}
+
+// statusTransport turns the 5xx responses into errors.
+type statusTransport struct {
+	base http.RoundTripper
+}
+
+func (t statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= http.StatusInternalServerError {
+		res.Body.Close()
+		return nil, errors.NetworkError{Code: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+	}
+	return res, nil
+}
@@ -0,0 +1,74 @@
+package gql
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	altiErrors "github.com/jackytck/alti-cli/errors"
+)
+
+func TestRetriable(t *testing.T) {
+	dial := &url.Error{Op: "Post", URL: "http://a/graphql", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	reset := &url.Error{Op: "Post", URL: "http://a/graphql", Err: &net.OpError{Op: "read", Err: errors.New("connection reset")}}
+	server := &url.Error{Op: "Post", URL: "http://a/graphql", Err: altiErrors.NetworkError{Code: 502, Message: "Bad Gateway"}}
+	timeout := &url.Error{Op: "Post", URL: "http://a/graphql", Err: errors.New("context deadline exceeded")}
+	gqlErr := errors.New("graphql: project not found")
+
+	for _, err := range []error{dial, reset, server, timeout} {
+		if !isTransient(err) {
+			t.Errorf("isTransient(%v) = false, want true", err)
+		}
+	}
+	if isTransient(gqlErr) {
+		t.Errorf("isTransient(%v) = true, want false", gqlErr)
+	}
+
+	tests := []struct {
+		name     string
+		timedOut bool
+		err      error
+		query    bool
+		mutation bool
+	}{
+		{"dial", false, dial, true, true},
+		{"reset", false, reset, true, false},
+		{"5xx", false, server, true, false},
+		{"timeout", true, timeout, true, false},
+		{"gql error", false, gqlErr, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retriable(tt.timedOut, tt.err, false); got != tt.query {
+				t.Errorf("retriable() of query = %v, want %v", got, tt.query)
+			}
+			if got := retriable(tt.timedOut, tt.err, true); got != tt.mutation {
+				t.Errorf("retriable() of mutation = %v, want %v", got, tt.mutation)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		wait time.Duration
+		i    int
+		min  time.Duration
+		max  time.Duration
+	}{
+		{time.Second, 0, 500 * time.Millisecond, 1500 * time.Millisecond},
+		{time.Second, 1, time.Second, 3 * time.Second},
+		{time.Second, 3, 4 * time.Second, 12 * time.Second},
+		{0, 2, 0, 0},
+	}
+	for _, tt := range tests {
+		for n := 0; n < 100; n++ {
+			got := backoff(tt.wait, tt.i)
+			if got < tt.min || got > tt.max {
+				t.Fatalf("backoff(%s, %d) = %s, want in [%s, %s]", tt.wait, tt.i, got, tt.min, tt.max)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// CoinTransactions queries the coin transactions of current user by cursor,
+// latest first.
+func CoinTransactions(first int, after string) ([]types.CoinTransaction, *types.PageInfo, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($first: Int, $after: String) {
+			my {
+				coinTransactions(first: $first, after: $after) {
+					pageInfo {
+						hasPreviousPage
+						hasNextPage
+						startCursor
+						endCursor
+					}
+					edges {
+						node {
+							id
+							date
+							type
+							amount
+							balance
+							description
+						}
+					}
+				}
+			}
+		}
+	`)
+	if first > 0 {
+		req.Var("first", first)
+	}
+	req.Var("after", after)
+
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// define a Context for the request
+	ctx := context.Background()
+
+	// run it and capture the response
+	var res coinTransRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		switch err.(type) {
+		case *url.Error:
+			return nil, nil, errors.ErrOffline
+		default:
+			return nil, nil, err
+		}
+	}
+
+	var ret []types.CoinTransaction
+	for _, e := range res.My.CoinTransactions.Edges {
+		ret = append(ret, e.Node)
+	}
+	pi := res.My.CoinTransactions.PageInfo
+	return ret, &pi, nil
+}
+
+type coinTransRes struct {
+	My struct {
+		CoinTransactions struct {
+			PageInfo types.PageInfo
+			Edges    []struct {
+				Node types.CoinTransaction
+			}
+		}
+	}
+}
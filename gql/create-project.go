@@ -13,7 +13,7 @@ import (
 func CreateProject(name, projType, modelType, visibility string) (string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -40,7 +40,7 @@ func CreateProject(name, projType, modelType, visibility string) (string, error)
 
 	// run it and capture the response
 	var res createProjRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", err
 	}
 	pid := res.CreateProject.ID
@@ -0,0 +1,73 @@
+package gql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// ReconOptions are the reconstruction options of a new project.
+// Empty options are left to the server defaults.
+type ReconOptions struct {
+	Quality string // model quality preset, e.g. high
+	GeoRef  string // geo-reference mode, e.g. gps
+	CRS     string // coordinate reference system of the geo-reference, e.g. EPSG:4326
+}
+
+// CreateReconProject creates a new empty reconstruction project with the
+// reconstruction options and returns the pid of the newly created project.
+func CreateReconProject(name, projType, visibility string, opt ReconOptions) (string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		mutation ($name: String!, $type: PROJECT_TYPE, $visibility: PROJECT_VISIBILITY, $recon: ReconOptionsInput) {
+			createProject(name: $name, type: $type, visibility: $visibility, recon: $recon) {
+				id
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set create project variables
+	req.Var("name", name)
+	req.Var("type", projType)
+	req.Var("visibility", visibility)
+	recon := make(map[string]interface{})
+	if opt.Quality != "" {
+		recon["quality"] = strings.ToUpper(opt.Quality)
+	}
+	if opt.GeoRef != "" || opt.CRS != "" {
+		geo := make(map[string]string)
+		if opt.GeoRef != "" {
+			geo["mode"] = strings.ToUpper(opt.GeoRef)
+		}
+		if opt.CRS != "" {
+			geo["crs"] = opt.CRS
+		}
+		recon["geoReference"] = geo
+	}
+	if len(recon) > 0 {
+		req.Var("recon", recon)
+	}
+
+	// define a Context for the request
+	ctx := context.Background()
+
+	// run it and capture the response
+	var res createProjRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return "", err
+	}
+	pid := res.CreateProject.ID
+	if pid == "" {
+		return "", errors.ErrProjCreate
+	}
+	return pid, nil
+}
@@ -28,7 +28,7 @@ func CurrencyList() ([]string, error) {
 
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		query ($type: String!) {
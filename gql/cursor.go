@@ -0,0 +1,26 @@
+package gql
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// OffsetCursor gives the cursor of offset in the same encoding of the relay's
+// array connection cursor c, i.e. base64 of 'arrayconnection:N', so that the
+// pages after it could be queried without walking through them.
+// Return false if c is not of this encoding.
+func OffsetCursor(c string, offset int) (string, bool) {
+	b, err := base64.StdEncoding.DecodeString(c)
+	if err != nil {
+		return "", false
+	}
+	i := strings.LastIndex(string(b), ":")
+	if i < 0 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(string(b[i+1:])); err != nil {
+		return "", false
+	}
+	return base64.StdEncoding.EncodeToString([]byte(string(b[:i+1]) + strconv.Itoa(offset))), true
+}
@@ -10,10 +10,10 @@ import (
 
 // DoneImageUpload signals the end of image uploading.
 // Return the new image state with error.
-func DoneImageUpload(iid string) (string, error) {
+func DoneImageUpload(ctx context.Context, iid string) (string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		mutation ($iid: ID!) {
@@ -29,12 +29,9 @@ func DoneImageUpload(iid string) (string, error) {
 	// set variables
 	req.Var("iid", iid)
 
-	// define a Context for the request
-	ctx := context.Background()
-
 	// run it and capture the response
 	var res doneImgUploadRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", err
 	}
 	id := res.DoneImageUpload.ID
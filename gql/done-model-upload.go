@@ -14,7 +14,7 @@ import (
 func DoneModelUpload(pid string, merge bool) (string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		mutation ($pid: ID!, $merge: Boolean) {
@@ -36,7 +36,7 @@ func DoneModelUpload(pid string, merge bool) (string, error) {
 
 	// run it and capture the response
 	var res doneModelUploadRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", err
 	}
 	id := res.DoneModelUpload.ID
@@ -0,0 +1,74 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// DownloadChecksum returns the checksum of the download of name of a project
+// computed by the server, empty if it is not computed.
+func DownloadChecksum(ctx context.Context, pid, name string) (string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($pid: ID!) {
+			project(id: $pid) {
+				id
+				downloads {
+					edges {
+						node {
+							name
+							checksum
+						}
+					}
+				}
+			}
+		}
+	`)
+	req.Var("pid", pid)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// run it and capture the response
+	var res downloadChecksumRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		switch err.(type) {
+		case *url.Error:
+			return "", errors.ErrOffline
+		default:
+			return "", err
+		}
+	}
+
+	p := res.Project
+	if p.ID == "" {
+		return "", errors.ErrProjNotFound
+	}
+	for _, e := range p.Downloads.Edges {
+		if e.Node.Name == name {
+			return e.Node.Checksum, nil
+		}
+	}
+	return "", errors.ErrDownloadNotFound
+}
+
+type downloadChecksumRes struct {
+	Project struct {
+		ID        string
+		Downloads struct {
+			Edges []struct {
+				Node struct {
+					Name     string
+					Checksum string
+				}
+			}
+		}
+	}
+}
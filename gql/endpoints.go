@@ -21,7 +21,7 @@ func WebEndpoint() string {
 
 // Endpoints gets the endpoints of altizure servers.
 func Endpoints(endpoint, key string) (*types.Endpoints, error) {
-	client := graphql.NewClient(endpoint + "/graphql")
+	client := NewClient(endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		{
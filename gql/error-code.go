@@ -13,7 +13,7 @@ import (
 func GetErrorCodeInfo(code, lang string) (*ErrorCodeInfo, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	if lang == "" {
 		lang = "en"
@@ -58,7 +58,7 @@ func AllErrorCodes() ([]string, error) {
 
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		{
@@ -8,7 +8,7 @@ import (
 
 // GetUserTokenByCode gets the self-issued by phone and one-time login code.
 func GetUserTokenByCode(endpoint, appKey, phone, code string) (string, error) {
-	client := graphql.NewClient(endpoint + "/graphql")
+	client := NewClient(endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		mutation ($phone: String!, $code: String!) {
@@ -24,7 +24,7 @@ func GetUserTokenByCode(endpoint, appKey, phone, code string) (string, error) {
 
 	// run it and capture the response
 	var res getUserTokenByCodeRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", err
 	}
 	return res.GetUserTokenByLoginCode, nil
@@ -0,0 +1,53 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// GetUserTokenByDeviceCode gets the self-issued token of a device code
+// confirmed by user. errors.ErrAuthPending is returned if it is not yet confirmed.
+func GetUserTokenByDeviceCode(endpoint, appKey, deviceCode string) (string, error) {
+	client := NewClient(endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		mutation ($code: String!) {
+			getUserTokenByDeviceCode(deviceCode: $code) {
+				status
+				token
+			}
+		}
+	`)
+	req.Header.Set("key", appKey)
+
+	req.Var("code", deviceCode)
+
+	ctx := context.Background()
+	var res getUserTokenByDeviceCodeRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return "", err
+	}
+
+	r := res.GetUserTokenByDeviceCode
+	switch r.Status {
+	case "SUCCESS":
+		return r.Token, nil
+	case "PENDING":
+		return "", errors.ErrAuthPending
+	case "SLOW_DOWN":
+		return "", errors.ErrSlowDown
+	case "DENIED":
+		return "", errors.ErrAccessDenied
+	default:
+		return "", errors.ErrDeviceCodeExpired
+	}
+}
+
+type getUserTokenByDeviceCodeRes struct {
+	GetUserTokenByDeviceCode struct {
+		Status string // PENDING, SLOW_DOWN, DENIED, EXPIRED, SUCCESS
+		Token  string
+	}
+}
@@ -9,7 +9,7 @@ import (
 // GetUserTokenByEmail gets the self-issued user token.
 func GetUserTokenByEmail(endpoint, appKey, email, password string, fresh bool) (string, error) {
 	// create a client (safe to share across requests)
-	client := graphql.NewClient(endpoint + "/graphql")
+	client := NewClient(endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -28,7 +28,7 @@ func GetUserTokenByEmail(endpoint, appKey, email, password string, fresh bool) (
 
 	// run it and capture the response
 	var res getUserTokenRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", err
 	}
 	return res.GetUserToken, nil
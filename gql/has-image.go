@@ -9,13 +9,23 @@ import (
 
 // HasImage asks if the project has the given image by hash.
 func HasImage(pid, checksum string) (bool, error) {
+	iid, err := FindImage(pid, checksum)
+	if err != nil {
+		return false, err
+	}
+	return iid != "", nil
+}
+
+// FindImage finds the id of the project image by hash.
+// Return an empty id if not found.
+func FindImage(pid, checksum string) (string, error) {
 	if pid == "" || checksum == "" {
-		return false, nil
+		return "", nil
 	}
 
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -35,13 +45,9 @@ func HasImage(pid, checksum string) (bool, error) {
 
 	var res hasImageRes
 	if err := client.Run(ctx, req, &res); err != nil {
-		return false, err
-	}
-	id := res.HasImage.ID
-	if id == "" {
-		return false, nil
+		return "", err
 	}
-	return true, nil
+	return res.HasImage.ID, nil
 }
 
 type hasImageRes struct {
@@ -8,14 +8,14 @@ import (
 )
 
 // HasMetaFile asks if the project has the given meta file by hash.
-func HasMetaFile(pid, checksum string) (bool, error) {
+func HasMetaFile(ctx context.Context, pid, checksum string) (bool, error) {
 	if pid == "" || checksum == "" {
 		return false, nil
 	}
 
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -33,8 +33,6 @@ func HasMetaFile(pid, checksum string) (bool, error) {
 	req.Var("pid", pid)
 	req.Var("checksum", checksum)
 
-	ctx := context.Background()
-
 	var res hasMetaRes
 	if err := client.Run(ctx, req, &res); err != nil {
 		return false, err
@@ -0,0 +1,61 @@
+package gql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/machinebox/graphql"
+)
+
+var (
+	subFieldsMu sync.Mutex
+	subFields   = make(map[string]map[string]bool) // endpoint -> subscription fields
+)
+
+// HasSubscription tells if the active api server advertises the
+// subscription field name by introspection. The fields are queried once
+// per endpoint.
+func HasSubscription(name string) bool {
+	client, endpoint, key, _ := ActiveClient("")
+
+	subFieldsMu.Lock()
+	defer subFieldsMu.Unlock()
+	fields, ok := subFields[endpoint]
+	if !ok {
+		req := graphql.NewRequest(`
+			{
+				__schema {
+					subscriptionType {
+						fields {
+							name
+						}
+					}
+				}
+			}
+		`)
+		req.Header.Set("key", key)
+
+		var res subFieldsRes
+		if err := client.Run(context.Background(), req, &res); err != nil {
+			return false
+		}
+		fields = make(map[string]bool)
+		if st := res.Schema.SubscriptionType; st != nil {
+			for _, f := range st.Fields {
+				fields[f.Name] = true
+			}
+		}
+		subFields[endpoint] = fields
+	}
+	return fields[name]
+}
+
+type subFieldsRes struct {
+	Schema struct {
+		SubscriptionType *struct {
+			Fields []struct {
+				Name string
+			}
+		}
+	} `json:"__schema"`
+}
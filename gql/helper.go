@@ -13,7 +13,7 @@ import (
 
 // ActiveClient constructs the gql client for the currently active profile.
 // Return the gql client, endpint, key and token.
-func ActiveClient(room string) (*graphql.Client, string, string, string) {
+func ActiveClient(room string) (*Client, string, string, string) {
 	if room == "" {
 		room = "graphql"
 	}
@@ -25,7 +25,7 @@ func ActiveClient(room string) (*graphql.Client, string, string, string) {
 	token := active.Token
 
 	url := fmt.Sprintf("%s/%s", endpoint, room)
-	client := graphql.NewClient(url)
+	client := NewClient(url)
 
 	return client, endpoint, key, token
 }
@@ -52,7 +52,7 @@ func EnumValues(typeName string) ([]string, error) {
 
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		query ($type: String!) {
@@ -0,0 +1,60 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// ImageChecksum returns the checksum of a project image computed by the
+// server, empty if it is not computed.
+func ImageChecksum(ctx context.Context, pid, iid string) (string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($pid: ID!, $iid: ID!) {
+			project(id: $pid) {
+				image(id: $iid) {
+					id
+					checksum
+				}
+			}
+		}
+	`)
+	req.Var("pid", pid)
+	req.Var("iid", iid)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// run it and capture the response
+	var res imageChecksumRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		switch err.(type) {
+		case *url.Error:
+			return "", errors.ErrOffline
+		default:
+			return "", err
+		}
+	}
+
+	i := res.Project.Image
+	if i.ID == "" {
+		return "", errors.ErrImgNotFound
+	}
+	return i.Checksum, nil
+}
+
+type imageChecksumRes struct {
+	Project struct {
+		Image struct {
+			ID       string
+			Checksum string
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+// ImageStatesField is the subscription field of the image states.
+const ImageStatesField = "imageStateChanged"
+
+// ImageStates subscribes the state changes of the images of project pid.
+// The images are sent on the returned channel until ctx is canceled or the
+// subscription ends, then it is closed.
+func ImageStates(ctx context.Context, pid string) (<-chan types.Image, error) {
+	sub, err := Subscribe(ctx, `
+		subscription ($pid: ID!) {
+			imageStateChanged(pid: $pid) {
+				id
+				state
+				name
+				filename
+				error
+			}
+		}
+	`, map[string]interface{}{"pid": pid})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(chan types.Image)
+	go func() {
+		defer close(ret)
+		defer sub.Close()
+		for {
+			var res imageStatesRes
+			if err := sub.Next(&res); err != nil {
+				return
+			}
+			select {
+			case ret <- res.ImageStateChanged:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ret, nil
+}
+
+type imageStatesRes struct {
+	ImageStateChanged types.Image
+}
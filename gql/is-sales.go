@@ -8,7 +8,7 @@ import (
 
 // IsSales checks if the provided creds is a sales.
 func IsSales(endpoint, key, token string) bool {
-	client := graphql.NewClient(endpoint + "/sales")
+	client := NewClient(endpoint + "/sales")
 
 	req := graphql.NewRequest(`
 		{
@@ -8,7 +8,7 @@ import (
 
 // IsSuper checks if the provided creds is a superuser.
 func IsSuper(endpoint, key, token string) bool {
-	client := graphql.NewClient(endpoint + "/super")
+	client := NewClient(endpoint + "/super")
 
 	req := graphql.NewRequest(`
 		{
@@ -0,0 +1,119 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// ListProjects queries a page of my projects by the server-side search,
+// sort, paging and scope of q. Only the arguments set in q are sent, so
+// plain listing works with the servers not supporting the others.
+func ListProjects(q types.ProjectQuery) ([]types.Project, *types.PageInfo, int, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	vars := make(map[string]interface{})
+	var decls, args []string
+	add := func(name, typ string, v interface{}) {
+		decls = append(decls, fmt.Sprintf("$%s: %s", name, typ))
+		args = append(args, fmt.Sprintf("%s: $%s", name, name))
+		vars[name] = v
+	}
+	if q.First > 0 {
+		add("first", "Int", q.First)
+	}
+	if q.After != "" {
+		add("after", "String", q.After)
+	}
+	if q.Search != "" {
+		add("search", "String", q.Search)
+	}
+	if q.TaskState != "" {
+		add("taskState", "String", q.TaskState)
+	}
+	if q.ProjectType != "" {
+		add("projectType", "String", q.ProjectType)
+	}
+	if q.SortBy != "" {
+		order := "asc"
+		if q.Desc {
+			order = "desc"
+		}
+		add("sortBy", "String", q.SortBy)
+		add("order", "String", order)
+	}
+	if q.Scope != "" {
+		add("scope", "String", q.Scope)
+	}
+	var decl, arg string
+	if len(decls) > 0 {
+		decl = "(" + strings.Join(decls, ", ") + ")"
+		arg = "(" + strings.Join(args, ", ") + ")"
+	}
+
+	// make a request
+	req := graphql.NewRequest(fmt.Sprintf(`
+		query %s {
+			my {
+				allProjects%s {
+					totalCount
+					pageInfo {
+						hasPreviousPage
+						hasNextPage
+						startCursor
+						endCursor
+					}
+					edges {
+						node {
+							id
+							name
+							isImported
+							projectType
+							numImage
+							gigaPixel
+							taskState
+							date
+							cloudPath {
+								key
+							}
+						}
+					}
+				}
+			}
+		}
+	`, decl, arg))
+	for k, v := range vars {
+		req.Var(k, v)
+	}
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// define a Context for the request
+	ctx := context.Background()
+
+	// run it and capture the response
+	var res myProjsRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		switch err.(type) {
+		case *url.Error:
+			return nil, nil, 0, errors.ErrOffline
+		default:
+			return nil, nil, 0, err
+		}
+	}
+
+	var ret []types.Project
+	for _, e := range res.My.AllProjects.Edges {
+		ret = append(ret, e.Node)
+	}
+	pi := res.My.AllProjects.PageInfo
+	return ret, &pi, res.My.AllProjects.TotalCount, nil
+}
@@ -11,10 +11,10 @@ import (
 )
 
 // ProjectMetaFile return the info of a project meta file.
-func ProjectMetaFile(pid, mid string) (*types.MetaFile, error) {
+func ProjectMetaFile(ctx context.Context, pid, mid string) (*types.MetaFile, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -38,9 +38,6 @@ func ProjectMetaFile(pid, mid string) (*types.MetaFile, error) {
 	req.Header.Set("key", active.Key)
 	req.Header.Set("altitoken", active.Token)
 
-	// define a Context for the request
-	ctx := context.Background()
-
 	// run it and capture the response
 	var res projMetaRes
 	if err := client.Run(ctx, req, &res); err != nil {
@@ -0,0 +1,55 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// ModelChecksum returns the checksum of the imported model of a project
+// computed by the server, empty if it is not computed.
+func ModelChecksum(ctx context.Context, pid string) (string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($pid: ID!) {
+			project(id: $pid) {
+				id
+				importedChecksum
+			}
+		}
+	`)
+	req.Var("pid", pid)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// run it and capture the response
+	var res modelChecksumRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		switch err.(type) {
+		case *url.Error:
+			return "", errors.ErrOffline
+		default:
+			return "", err
+		}
+	}
+
+	p := res.Project
+	if p.ID == "" {
+		return "", errors.ErrProjNotFound
+	}
+	return p.ImportedChecksum, nil
+}
+
+type modelChecksumRes struct {
+	Project struct {
+		ID               string
+		ImportedChecksum string
+	}
+}
@@ -0,0 +1,196 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// StartMultipartUpload starts a multipart upload session of the registered
+// file of id, i.e. a model or meta file uploaded to s3 or minio.
+// Return the id of the upload session.
+func StartMultipartUpload(ctx context.Context, fid string) (string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		mutation ($id: ID!) {
+			startMultipartUpload(id: $id) {
+				uploadId
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("id", fid)
+
+	// run it and capture the response
+	var res startMultipartUploadRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return "", err
+	}
+	uid := res.StartMultipartUpload.UploadID
+	if uid == "" {
+		return "", errors.ErrMultipartUpload
+	}
+	return uid, nil
+}
+
+type startMultipartUploadRes struct {
+	StartMultipartUpload struct {
+		UploadID string
+	}
+}
+
+// MultipartPartURL gets the signed url for uploading the part of partNumber,
+// starting from 1, of the upload session uid of the file of id.
+func MultipartPartURL(ctx context.Context, fid, uid string, partNumber int) (string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($id: ID!, $uploadId: String!, $partNumber: Int!) {
+			multipartPartUrl(id: $id, uploadId: $uploadId, partNumber: $partNumber)
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("id", fid)
+	req.Var("uploadId", uid)
+	req.Var("partNumber", partNumber)
+
+	// run it and capture the response
+	var res multipartPartURLRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		return "", err
+	}
+	if res.MultipartPartURL == "" {
+		return "", errors.ErrMultipartUpload
+	}
+	return res.MultipartPartURL, nil
+}
+
+type multipartPartURLRes struct {
+	MultipartPartURL string `json:"multipartPartUrl"`
+}
+
+// MultipartParts lists the parts stored by the cloud of the upload session
+// uid of the file of id. It fails if the session is completed or aborted.
+func MultipartParts(ctx context.Context, fid, uid string) ([]types.UploadedPart, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($id: ID!, $uploadId: String!) {
+			multipartParts(id: $id, uploadId: $uploadId) {
+				partNumber
+				etag
+				size
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("id", fid)
+	req.Var("uploadId", uid)
+
+	// run it and capture the response
+	var res multipartPartsRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return res.MultipartParts, nil
+}
+
+type multipartPartsRes struct {
+	MultipartParts []types.UploadedPart
+}
+
+// CompleteMultipartUpload completes the upload session uid of the file of id
+// by concatenating the parts in order.
+func CompleteMultipartUpload(ctx context.Context, fid, uid string, parts []types.UploadedPart) error {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		mutation ($id: ID!, $uploadId: String!, $parts: [CompletedPart!]!) {
+			completeMultipartUpload(id: $id, uploadId: $uploadId, parts: $parts) {
+				id
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	var completed []map[string]interface{}
+	for _, p := range parts {
+		completed = append(completed, map[string]interface{}{
+			"partNumber": p.PartNumber,
+			"etag":       p.ETag,
+		})
+	}
+	req.Var("id", fid)
+	req.Var("uploadId", uid)
+	req.Var("parts", completed)
+
+	// run it and capture the response
+	var res completeMultipartUploadRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return err
+	}
+	if res.CompleteMultipartUpload.ID == "" {
+		return errors.ErrMultipartUpload
+	}
+	return nil
+}
+
+type completeMultipartUploadRes struct {
+	CompleteMultipartUpload struct {
+		ID string
+	}
+}
+
+// AbortMultipartUpload aborts the upload session uid of the file of id,
+// removing its uploaded parts from the cloud.
+func AbortMultipartUpload(ctx context.Context, fid, uid string) error {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		mutation ($id: ID!, $uploadId: String!) {
+			abortMultipartUpload(id: $id, uploadId: $uploadId)
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("id", fid)
+	req.Var("uploadId", uid)
+
+	// run it and capture the response
+	var res struct {
+		AbortMultipartUpload bool
+	}
+	return client.RunMutation(ctx, req, &res)
+}
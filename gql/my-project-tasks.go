@@ -0,0 +1,88 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// MyProjectTasks queries the current task of each of my projects, a page of
+// first projects after the cursor after at a time. Projects without any task
+// are skipped.
+func MyProjectTasks(first int, after string) ([]types.ProjectTask, *types.PageInfo, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		query ($first: Int, $after: String) {
+			my {
+				allProjects(first: $first, after: $after) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+					edges {
+						node {
+							id
+							name
+							task {
+								id
+								taskType
+								state
+								startDate
+								endDate
+								totalSteps
+								step
+								queueing
+							}
+						}
+					}
+				}
+			}
+		}
+	`)
+	req.Var("first", first)
+	req.Var("after", after)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	ctx := context.Background()
+
+	var res myProjTasksRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		if _, ok := err.(*url.Error); ok {
+			return nil, nil, errors.ErrOffline
+		}
+		return nil, nil, err
+	}
+
+	var ret []types.ProjectTask
+	for _, e := range res.My.AllProjects.Edges {
+		if e.Node.Task.ID == "" {
+			continue
+		}
+		ret = append(ret, types.ProjectTask{PID: e.Node.ID, Name: e.Node.Name, Task: e.Node.Task})
+	}
+	pi := res.My.AllProjects.PageInfo
+	return ret, &pi, nil
+}
+
+type myProjTasksRes struct {
+	My struct {
+		AllProjects struct {
+			PageInfo types.PageInfo
+			Edges    []struct {
+				Node struct {
+					ID   string
+					Name string
+					Task types.Task
+				}
+			}
+		}
+	}
+}
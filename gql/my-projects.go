@@ -14,7 +14,7 @@ import (
 func MyProjects(first, last int, before, after, search string) ([]types.Project, *types.PageInfo, int, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
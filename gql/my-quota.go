@@ -0,0 +1,54 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// MyQuota queries the plan, usage and limits of current user.
+func MyQuota() (*types.Quota, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		query {
+			my {
+				self {
+					email
+					freeGPQuota
+					membershipState
+					membership {
+						planName
+						endDate
+						memberGPQuota
+						assetStorage
+						concurrentTask
+					}
+					modelUsage
+				}
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	ctx := context.Background()
+
+	var res mySelfRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		if _, ok := err.(*url.Error); ok {
+			return nil, errors.ErrOffline
+		}
+		return nil, err
+	}
+	if res.My.Self.Email == "" {
+		return nil, errors.ErrNotLogin
+	}
+	return types.NewQuota(&res.My.Self), nil
+}
@@ -19,7 +19,7 @@ func MySelf() (string, *types.User, error) {
 
 // MySelfByKeyToken queries simple info of a specific user.
 func MySelfByKeyToken(endpoint, key, token string) (string, *types.User, error) {
-	client := graphql.NewClient(endpoint + "/graphql")
+	client := NewClient(endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -52,6 +52,7 @@ func MySelfByKeyToken(endpoint, key, token string) (string, *types.User, error)
 						modelPerProject
 						collaboratorQuota
 						forceWatermark
+						concurrentTask
 					}
 					modelUsage
 					developer {
@@ -12,11 +12,11 @@ import (
 
 // SuggestedBucket returns the nearest bucket from api server.
 // kind is "image", "model" or "meta".
-// cloud is "s3", "oss" or "minio".
+// cloud is "s3", "oss", "minio" or "gcs".
 func SuggestedBucket(kind, cloud string) (string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(fmt.Sprintf(`
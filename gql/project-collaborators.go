@@ -0,0 +1,53 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// ProjectCollaborators returns the users and invited emails sharing the project pid.
+func ProjectCollaborators(pid string) ([]types.Collaborator, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		query ($id: ID!) {
+			project(id: $id) {
+				id
+				collaborators {
+					email
+					username
+					permission
+					state
+					date
+				}
+			}
+		}
+	`)
+	req.Var("id", pid)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	ctx := context.Background()
+
+	var res projCollabRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	if res.Project.ID == "" {
+		return nil, errors.ErrProjNotFound
+	}
+	return res.Project.Collaborators, nil
+}
+
+type projCollabRes struct {
+	Project struct {
+		ID            string
+		Collaborators []types.Collaborator
+	}
+}
@@ -11,10 +11,10 @@ import (
 )
 
 // ProjectImage return the info of a project image.
-func ProjectImage(pid, iid string) (*types.Image, error) {
+func ProjectImage(ctx context.Context, pid, iid string) (*types.Image, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -35,9 +35,6 @@ func ProjectImage(pid, iid string) (*types.Image, error) {
 	req.Header.Set("key", active.Key)
 	req.Header.Set("altitoken", active.Token)
 
-	// define a Context for the request
-	ctx := context.Background()
-
 	// run it and capture the response
 	var res projImgRes
 	if err := client.Run(ctx, req, &res); err != nil {
@@ -0,0 +1,63 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// ProjectTask returns the most current task of the project.
+func ProjectTask(pid string) (*types.Task, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($id: ID!) {
+			project(id: $id) {
+				task {
+					id
+					taskType
+					state
+					startDate
+					endDate
+					totalSteps
+					step
+					queueing
+				}
+			}
+		}
+	`)
+	req.Var("id", pid)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	ctx := context.Background()
+
+	var res projTaskRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		switch err.(type) {
+		case *url.Error:
+			return nil, errors.ErrOffline
+		default:
+			return nil, err
+		}
+	}
+
+	t := res.Project.Task
+	if t.ID == "" {
+		return nil, errors.ErrTaskNotFound
+	}
+	return &t, nil
+}
+
+type projTaskRes struct {
+	Project struct {
+		Task types.Task
+	}
+}
@@ -0,0 +1,72 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// ProjectTasks returns all of the past and current tasks of the project,
+// in the order of start date.
+func ProjectTasks(pid string) ([]types.Task, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		query ($id: ID!) {
+			project(id: $id) {
+				allTasks {
+					edges {
+						node {
+							id
+							taskType
+							state
+							startDate
+							endDate
+							totalSteps
+							step
+							queueing
+						}
+					}
+				}
+			}
+		}
+	`)
+	req.Var("id", pid)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	ctx := context.Background()
+
+	var res projTasksRes
+	if err := client.Run(ctx, req, &res); err != nil {
+		switch err.(type) {
+		case *url.Error:
+			return nil, errors.ErrOffline
+		default:
+			return nil, err
+		}
+	}
+
+	var ret []types.Task
+	for _, e := range res.Project.AllTasks.Edges {
+		ret = append(ret, e.Node)
+	}
+	return ret, nil
+}
+
+type projTasksRes struct {
+	Project struct {
+		AllTasks struct {
+			Edges []struct {
+				Node types.Task
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package gql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// RefreshUserToken exchanges the user token for a fresh one before it
+// expires. Return the new token and its expiry in unix time, 0 if unknown.
+func RefreshUserToken(endpoint, appKey, token string) (string, int64, error) {
+	client := NewClient(endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		mutation {
+			refreshUserToken {
+				token
+				expire
+			}
+		}
+	`)
+	req.Header.Set("key", appKey)
+	req.Header.Set("altitoken", token)
+
+	// not refreshing itself
+	ctx := context.WithValue(context.Background(), noRefreshKey{}, true)
+
+	var res refreshUserTokenRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return "", 0, err
+	}
+	t := res.RefreshUserToken.Token
+	if t == "" {
+		return "", 0, errors.ErrTokenExpired
+	}
+	expiry := config.TokenExpiry(t)
+	if e, err := time.Parse(time.RFC3339, res.RefreshUserToken.Expire); err == nil {
+		expiry = e.Unix()
+	}
+	return t, expiry, nil
+}
+
+type refreshUserTokenRes struct {
+	RefreshUserToken struct {
+		Token  string
+		Expire string
+	}
+}
@@ -0,0 +1,63 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// RegisterImageGCS registers a GCS image.
+// And get back the registered image and the signed url to GCS.
+func RegisterImageGCS(ctx context.Context, pid, bucket, filename, imageType, checksum string, meta *types.ImageMeta) (*types.Image, string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		mutation ($pid: ID!, $bucket: BucketGCS!, $filename: String!, $type: IMAGE_TYPE, $checksum: String, $meta: ImageMetaInput) {
+			uploadImageGCS(pid: $pid, bucket: $bucket, filename: $filename, type: $type, checksum: $checksum, meta: $meta) {
+				url
+				image {
+					id
+					state
+					name
+					filename
+				}
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("pid", pid)
+	req.Var("bucket", bucket)
+	req.Var("filename", filename)
+	req.Var("type", imageType)
+	req.Var("checksum", checksum)
+	req.Var("meta", meta)
+
+	// run it and capture the response
+	var res regImgGCSRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, "", err
+	}
+	iid := res.UploadImageGCS.Image.ID
+	url := res.UploadImageGCS.URL
+	if iid == "" || url == "" {
+		return nil, "", errors.ErrImgReg
+	}
+
+	return &res.UploadImageGCS.Image, url, nil
+}
+
+type regImgGCSRes struct {
+	UploadImageGCS struct {
+		URL   string
+		Image types.Image
+	}
+}
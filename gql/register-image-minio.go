@@ -11,15 +11,15 @@ import (
 
 // RegisterImageMinio registers a minio image.
 // And get back the registered image and the signed url to minio.
-func RegisterImageMinio(pid, bucket, filename, imageType, checksum string) (*types.Image, string, error) {
+func RegisterImageMinio(ctx context.Context, pid, bucket, filename, imageType, checksum string, meta *types.ImageMeta) (*types.Image, string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
-		mutation ($pid: ID!, $bucket: BucketMinio!, $filename: String!, $type: IMAGE_TYPE, $checksum: String) {
-			uploadImageMinio(pid: $pid, bucket: $bucket, filename: $filename, type: $type, checksum: $checksum) {
+		mutation ($pid: ID!, $bucket: BucketMinio!, $filename: String!, $type: IMAGE_TYPE, $checksum: String, $meta: ImageMetaInput) {
+			uploadImageMinio(pid: $pid, bucket: $bucket, filename: $filename, type: $type, checksum: $checksum, meta: $meta) {
 				url
 				image {
 					id
@@ -39,13 +39,11 @@ func RegisterImageMinio(pid, bucket, filename, imageType, checksum string) (*typ
 	req.Var("filename", filename)
 	req.Var("type", imageType)
 	req.Var("checksum", checksum)
-
-	// define a Context for the request
-	ctx := context.Background()
+	req.Var("meta", meta)
 
 	// run it and capture the response
 	var res regImgMinioRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, "", err
 	}
 	iid := res.UploadImageMinio.Image.ID
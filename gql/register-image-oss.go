@@ -21,7 +21,7 @@ func RefreshSTS(pid, bucket string) func() (*types.STS, error) {
 func GetSTS(pid, bucket string) (*types.STS, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		mutation ($pid: ID!, $bucket: BucketOSS!, $filename: String!) {
@@ -55,7 +55,7 @@ func GetSTS(pid, bucket string) (*types.STS, error) {
 
 	// run it and capture the response
 	var res stsRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, err
 	}
 	id := res.UploadImageOSS.STS.ID
@@ -77,15 +77,15 @@ func GetSTS(pid, bucket string) (*types.STS, error) {
 
 // RegisterImageOSS registers an OSS image, without getting the STS creds.
 // Return the registered image.
-func RegisterImageOSS(pid, bucket, filename, imageType, checksum string) (*types.Image, error) {
+func RegisterImageOSS(ctx context.Context, pid, bucket, filename, imageType, checksum string, meta *types.ImageMeta) (*types.Image, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
-		mutation ($pid: ID!, $bucket: BucketOSS!, $filename: String!, $type: IMAGE_TYPE, $checksum: String) {
-			uploadImageOSS(pid: $pid, bucket: $bucket, filename: $filename, type: $type, checksum: $checksum) {
+		mutation ($pid: ID!, $bucket: BucketOSS!, $filename: String!, $type: IMAGE_TYPE, $checksum: String, $meta: ImageMetaInput) {
+			uploadImageOSS(pid: $pid, bucket: $bucket, filename: $filename, type: $type, checksum: $checksum, meta: $meta) {
 				image {
 					id
 					state
@@ -104,13 +104,11 @@ func RegisterImageOSS(pid, bucket, filename, imageType, checksum string) (*types
 	req.Var("filename", filename)
 	req.Var("type", imageType)
 	req.Var("checksum", checksum)
-
-	// define a Context for the request
-	ctx := context.Background()
+	req.Var("meta", meta)
 
 	// run it and capture the response
 	var res regImgOSSRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, err
 	}
 	iid := res.UploadImageOSS.Image.ID
@@ -10,15 +10,15 @@ import (
 )
 
 // RegisterImageURL registers an to be uploaded image by url.
-func RegisterImageURL(pid, url, filename, checksum string) (*types.Image, error) {
+func RegisterImageURL(ctx context.Context, pid, url, filename, checksum string, meta *types.ImageMeta) (*types.Image, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
-		mutation ($pid: ID!, $url: String!, $filename: String, $checksum: String) {
-			uploadImageURL(pid: $pid, url: $url, filename: $filename, checksum: $checksum) {
+		mutation ($pid: ID!, $url: String!, $filename: String, $checksum: String, $meta: ImageMetaInput) {
+			uploadImageURL(pid: $pid, url: $url, filename: $filename, checksum: $checksum, meta: $meta) {
 				id
 				state
 				name
@@ -34,13 +34,11 @@ func RegisterImageURL(pid, url, filename, checksum string) (*types.Image, error)
 	req.Var("url", url)
 	req.Var("filename", filename)
 	req.Var("checksum", checksum)
-
-	// define a Context for the request
-	ctx := context.Background()
+	req.Var("meta", meta)
 
 	// run it and capture the response
 	var res regImgURLRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, err
 	}
 	iid := res.UploadImageURL.ID
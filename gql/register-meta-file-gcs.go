@@ -0,0 +1,60 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// RegisterMetaFileGCS registers a GCS meta file.
+// And get back the registered meta file and the signed url to GCS.
+func RegisterMetaFileGCS(ctx context.Context, pid, bucket, filename string) (*types.MetaFile, string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		mutation ($pid: ID!, $bucket: BucketGCSMeta!, $filename: String!) {
+			uploadMetaFileGCS(pid: $pid, bucket: $bucket, filename: $filename) {
+				url
+				file {
+					id
+					state
+					name
+					filename
+				}
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("pid", pid)
+	req.Var("bucket", bucket)
+	req.Var("filename", filename)
+
+	// run it and capture the response
+	var res regMetaGCSRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, "", err
+	}
+	mid := res.UploadMetaFileGCS.File.ID
+	url := res.UploadMetaFileGCS.URL
+	if mid == "" || url == "" {
+		return nil, "", errors.ErrMetaReg
+	}
+
+	return &res.UploadMetaFileGCS.File, url, nil
+}
+
+type regMetaGCSRes struct {
+	UploadMetaFileGCS struct {
+		URL  string
+		File types.MetaFile
+	}
+}
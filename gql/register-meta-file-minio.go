@@ -11,10 +11,10 @@ import (
 
 // RegisterMetaFileMinio registers a Minio meta file.
 // And get back the registered meta file and the signed url to Minio.
-func RegisterMetaFileMinio(pid, bucket, filename string) (*types.MetaFile, string, error) {
+func RegisterMetaFileMinio(ctx context.Context, pid, bucket, filename string) (*types.MetaFile, string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -38,12 +38,9 @@ func RegisterMetaFileMinio(pid, bucket, filename string) (*types.MetaFile, strin
 	req.Var("bucket", bucket)
 	req.Var("filename", filename)
 
-	// define a Context for the request
-	ctx := context.Background()
-
 	// run it and capture the response
 	var res regMetaMinioRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, "", err
 	}
 	mid := res.UploadMetaFileMinio.File.ID
@@ -0,0 +1,72 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// RegisterMetaFileOSS registers an OSS meta file.
+// And get back the registered meta file and the STS creds for uploading to OSS.
+func RegisterMetaFileOSS(ctx context.Context, pid, bucket, filename string) (*types.MetaFile, *types.STS, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	bt, err := BucketType("meta", "oss")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// make a request
+	req := graphql.NewRequest(fmt.Sprintf(`
+		mutation ($pid: ID!, $bucket: %s!, $filename: String!) {
+			uploadMetaFileOSS(pid: $pid, bucket: $bucket, filename: $filename) {
+				sts {
+					id
+					secret
+					token
+					bucket
+					endpoint
+					expire
+				}
+				file {
+					id
+					state
+					name
+					filename
+				}
+			}
+		}
+	`, bt))
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("pid", pid)
+	req.Var("bucket", bucket)
+	req.Var("filename", filename)
+
+	// run it and capture the response
+	var res regMetaOSSRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, nil, err
+	}
+	mid := res.UploadMetaFileOSS.File.ID
+	if mid == "" || res.UploadMetaFileOSS.STS.ID == "" {
+		return nil, nil, errors.ErrMetaReg
+	}
+
+	return &res.UploadMetaFileOSS.File, &res.UploadMetaFileOSS.STS, nil
+}
+
+type regMetaOSSRes struct {
+	UploadMetaFileOSS struct {
+		STS  types.STS
+		File types.MetaFile
+	}
+}
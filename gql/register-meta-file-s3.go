@@ -11,10 +11,10 @@ import (
 
 // RegisterMetaFileS3 registers a S3 meta file.
 // And get back the registered meta file and the signed url to S3.
-func RegisterMetaFileS3(pid, bucket, filename string) (*types.MetaFile, string, error) {
+func RegisterMetaFileS3(ctx context.Context, pid, bucket, filename string) (*types.MetaFile, string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -38,12 +38,9 @@ func RegisterMetaFileS3(pid, bucket, filename string) (*types.MetaFile, string,
 	req.Var("bucket", bucket)
 	req.Var("filename", filename)
 
-	// define a Context for the request
-	ctx := context.Background()
-
 	// run it and capture the response
 	var res regMetaS3Res
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, "", err
 	}
 	mid := res.UploadMetaFileS3.File.ID
@@ -10,10 +10,10 @@ import (
 )
 
 // RegisterMetaURL registers a to be uploaded meta file by url.
-func RegisterMetaURL(pid, url, filename, checksum string) (*types.MetaFile, error) {
+func RegisterMetaURL(ctx context.Context, pid, url, filename, checksum string) (*types.MetaFile, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -35,12 +35,9 @@ func RegisterMetaURL(pid, url, filename, checksum string) (*types.MetaFile, erro
 	req.Var("filename", filename)
 	req.Var("checksum", checksum)
 
-	// define a Context for the request
-	ctx := context.Background()
-
 	// run it and capture the response
 	var res regMetaURLRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, err
 	}
 	iid := res.UploadMetaURL.ID
@@ -0,0 +1,53 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// RegisterModelChunkedURL registers a to be uploaded model by the url of its
+// chunk manifest. The api server pulls and verifies each chunk, and re-requests
+// the failed ones only.
+func RegisterModelChunkedURL(pid, manifestURL, filename, checksum string) (*types.ImportedModel, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		mutation ($pid: ID!, $url: String!, $filename: String, $checksum: String) {
+			uploadModelURL(pid: $pid, url: $url, filename: $filename, checksum: $checksum, chunked: true) {
+				id
+				state
+				name
+				filename
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("pid", pid)
+	req.Var("url", manifestURL)
+	req.Var("filename", filename)
+	req.Var("checksum", checksum)
+
+	// define a Context for the request
+	ctx := context.Background()
+
+	// run it and capture the response
+	var res regModelURLRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	if res.UploadModelURL.ID == "" {
+		return nil, errors.ErrModelReg
+	}
+
+	return &res.UploadModelURL, nil
+}
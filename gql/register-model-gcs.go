@@ -0,0 +1,63 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// RegisterModelGCS registers a GCS model.
+// And get back the registered model and the signed url to GCS.
+func RegisterModelGCS(pid, bucket, filename string) (*types.Model, string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		mutation ($id: ID!, $bucket: BucketGCSModel!, $filename: String!) {
+			uploadModelGCS(id: $id, bucket: $bucket, filename: $filename) {
+				url
+				file {
+					id
+					state
+					name
+					filename
+				}
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("id", pid)
+	req.Var("bucket", bucket)
+	req.Var("filename", filename)
+
+	// define a Context for the request
+	ctx := context.Background()
+
+	// run it and capture the response
+	var res regModelGCSRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, "", err
+	}
+	mid := res.UploadModelGCS.File.ID
+	url := res.UploadModelGCS.URL
+	if mid == "" || url == "" {
+		return nil, "", errors.ErrModelReg
+	}
+
+	return &res.UploadModelGCS.File, url, nil
+}
+
+type regModelGCSRes struct {
+	UploadModelGCS struct {
+		URL  string
+		File types.Model
+	}
+}
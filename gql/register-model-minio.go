@@ -14,7 +14,7 @@ import (
 func RegisterModelMinio(pid, bucket, filename string) (*types.Model, string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -43,7 +43,7 @@ func RegisterModelMinio(pid, bucket, filename string) (*types.Model, string, err
 
 	// run it and capture the response
 	var res regModelMinioRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, "", err
 	}
 	mid := res.UploadModelMinio.File.ID
@@ -0,0 +1,75 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// RegisterModelOSS registers an OSS model.
+// And get back the registered model and the STS creds for uploading to OSS.
+func RegisterModelOSS(pid, bucket, filename string) (*types.Model, *types.STS, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	bt, err := BucketType("model", "oss")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// make a request
+	req := graphql.NewRequest(fmt.Sprintf(`
+		mutation ($id: ID!, $bucket: %s!, $filename: String!) {
+			uploadModelOSS(id: $id, bucket: $bucket, filename: $filename) {
+				sts {
+					id
+					secret
+					token
+					bucket
+					endpoint
+					expire
+				}
+				file {
+					id
+					state
+					name
+					filename
+				}
+			}
+		}
+	`, bt))
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("id", pid)
+	req.Var("bucket", bucket)
+	req.Var("filename", filename)
+
+	// define a Context for the request
+	ctx := context.Background()
+
+	// run it and capture the response
+	var res regModelOSSRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, nil, err
+	}
+	mid := res.UploadModelOSS.File.ID
+	if mid == "" || res.UploadModelOSS.STS.ID == "" {
+		return nil, nil, errors.ErrModelReg
+	}
+
+	return &res.UploadModelOSS.File, &res.UploadModelOSS.STS, nil
+}
+
+type regModelOSSRes struct {
+	UploadModelOSS struct {
+		STS  types.STS
+		File types.Model
+	}
+}
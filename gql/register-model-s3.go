@@ -14,7 +14,7 @@ import (
 func RegisterModelS3(pid, bucket, filename string) (*types.Model, string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -43,7 +43,7 @@ func RegisterModelS3(pid, bucket, filename string) (*types.Model, string, error)
 
 	// run it and capture the response
 	var res regModelS3Res
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, "", err
 	}
 	mid := res.UploadModelS3.File.ID
@@ -13,7 +13,7 @@ import (
 func RegisterModelURL(pid, url, filename, checksum string) (*types.ImportedModel, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -40,7 +40,7 @@ func RegisterModelURL(pid, url, filename, checksum string) (*types.ImportedModel
 
 	// run it and capture the response
 	var res regModelURLRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, err
 	}
 	iid := res.UploadModelURL.ID
@@ -0,0 +1,48 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// RemoveImage removes an image of a project by the given pid and iid.
+func RemoveImage(pid, iid string) (*types.Image, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	// make a request
+	req := graphql.NewRequest(`
+		mutation ($pid: ID!, $iid: ID!) {
+			removeImage(pid: $pid, iid: $iid) {
+				id
+				state
+				name
+				filename
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+	req.Var("pid", pid)
+	req.Var("iid", iid)
+
+	ctx := context.Background()
+
+	var res removeImgRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	if res.RemoveImage.ID == "" {
+		return nil, errors.ErrImgRemove
+	}
+	return &res.RemoveImage, nil
+}
+
+type removeImgRes struct {
+	RemoveImage types.Image
+}
@@ -13,7 +13,7 @@ import (
 func RemoveProject(pid string) (*types.Project, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -40,7 +40,7 @@ func RemoveProject(pid string) (*types.Project, error) {
 	ctx := context.Background()
 
 	var res removeProjRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, err
 	}
 	id := res.RemoveProject.ID
@@ -13,7 +13,7 @@ import (
 func ReportProject(pid, desc string) error {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		mutation ($pid: ID!, $desc: String!) {
@@ -30,7 +30,7 @@ func ReportProject(pid, desc string) error {
 
 	ctx := context.Background()
 	var res reportProjRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return err
 	}
 	if res.ReportProject.ID == "" {
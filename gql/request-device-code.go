@@ -0,0 +1,37 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// RequestDeviceCode requests a device code for logining in with browser.
+func RequestDeviceCode(endpoint, appKey string) (*types.DeviceCode, error) {
+	client := NewClient(endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		mutation {
+			requestDeviceCode {
+				deviceCode
+				userCode
+				verificationURI
+				expiresIn
+				interval
+			}
+		}
+	`)
+	req.Header.Set("key", appKey)
+
+	ctx := context.Background()
+	var res reqDeviceCodeRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res.RequestDeviceCode, nil
+}
+
+type reqDeviceCodeRes struct {
+	RequestDeviceCode types.DeviceCode
+}
@@ -9,7 +9,7 @@ import (
 
 // RequestLoginCode requests an one-time login code via sms.
 func RequestLoginCode(endpoint, appKey, phone string) error {
-	client := graphql.NewClient(endpoint + "/graphql")
+	client := NewClient(endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		mutation ($phone: String!) {
@@ -28,7 +28,7 @@ func RequestLoginCode(endpoint, appKey, phone string) error {
 
 	ctx := context.Background()
 	var res reqLoginCodeRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return err
 	}
 	if res.RequestLoginCode.Result != "Success" {
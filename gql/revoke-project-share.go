@@ -0,0 +1,58 @@
+package gql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackytck/alti-cli/config"
+	altiErrors "github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// RevokeProjectShare revokes the access of the user of email, or the
+// pending invitation of email, to project pid.
+func RevokeProjectShare(pid, email string) (string, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		mutation ($id: ID!, $email: String!) {
+			revokeProjectShare(id: $id, email: $email) {
+				error {
+					message
+				}
+				result
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+	req.Var("id", pid)
+	req.Var("email", email)
+
+	ctx := context.Background()
+
+	var res revokeShareRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return "", err
+	}
+	errMsg := res.RevokeProjectShare.Error.Message
+	result := res.RevokeProjectShare.Result
+	if errMsg != "" {
+		return result, errors.New(errMsg)
+	}
+	if result == "Fail" {
+		return result, altiErrors.ErrShareProject
+	}
+	return result, nil
+}
+
+type revokeShareRes struct {
+	RevokeProjectShare struct {
+		Error struct {
+			Message string
+		}
+		Result string
+	}
+}
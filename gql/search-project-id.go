@@ -14,7 +14,7 @@ import (
 func SearchProjectID(id string, myProj bool) (*types.Project, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -0,0 +1,21 @@
+package gql
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// ServerTime gets the current time of the active api server from the Date
+// header of its response.
+func ServerTime() (time.Time, error) {
+	_, endpoint, _, _ := ActiveClient("")
+	res, err := config.HTTPClient(10 * time.Second).Head(endpoint)
+	if err != nil {
+		return time.Time{}, errors.ErrOffline
+	}
+	res.Body.Close()
+	return http.ParseTime(res.Header.Get("Date"))
+}
@@ -12,7 +12,7 @@ import (
 func SetProfileFace(imgStr string) (string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		mutation ($imgStr: String!) {
@@ -30,7 +30,7 @@ func SetProfileFace(imgStr string) (string, error) {
 
 	// run it and capture the response
 	var res setProfileFaceRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", err
 	}
 	return res.SetProfileFace, nil
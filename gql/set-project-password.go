@@ -0,0 +1,65 @@
+package gql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackytck/alti-cli/config"
+	altiErrors "github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// SetProjectPassword sets the password of viewing project pid, or clears it
+// if password is empty. Return if the project is protected by a password.
+func SetProjectPassword(pid, password string) (bool, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		mutation ($id: ID!, $password: String) {
+			setProjectPassword(id: $id, password: $password) {
+				error {
+					message
+				}
+				project {
+					id
+					hasPassword
+				}
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+	req.Var("id", pid)
+	if password != "" {
+		req.Var("password", password)
+	}
+
+	ctx := context.Background()
+
+	var res setProjPasswordRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return false, err
+	}
+	if msg := res.SetProjectPassword.Error.Message; msg != "" {
+		return false, errors.New(msg)
+	}
+	p := res.SetProjectPassword.Project
+	if p.ID == "" {
+		return false, altiErrors.ErrProjUpdate
+	}
+	return p.HasPassword, nil
+}
+
+type setProjPasswordRes struct {
+	SetProjectPassword struct {
+		Error struct {
+			Message string
+		}
+		Project struct {
+			ID          string
+			HasPassword bool
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// ShareProject shares project pid with the users of emails at the given
+// permission, i.e. 'view', 'edit' or 'admin'. The permission of existing
+// collaborators is updated. Return all of the collaborators after sharing.
+func ShareProject(pid string, emails []string, permission string) ([]types.Collaborator, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		mutation ($id: ID!, $emails: [String!]!, $permission: SharePermission!) {
+			shareProject(id: $id, options: {emails: $emails, permission: $permission}) {
+				error {
+					message
+				}
+				collaborators {
+					email
+					username
+					permission
+					state
+					date
+				}
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+	req.Var("id", pid)
+	req.Var("emails", emails)
+	req.Var("permission", strings.ToUpper(permission))
+
+	ctx := context.Background()
+
+	var res shareProjRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	if msg := res.ShareProject.Error.Message; msg != "" {
+		return nil, errors.New(msg)
+	}
+	return res.ShareProject.Collaborators, nil
+}
+
+type shareProjRes struct {
+	ShareProject struct {
+		Error struct {
+			Message string
+		}
+		Collaborators []types.Collaborator
+	}
+}
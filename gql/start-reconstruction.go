@@ -13,7 +13,7 @@ import (
 func StartReconstruction(pid, taskType string) (*types.Task, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -41,7 +41,7 @@ func StartReconstruction(pid, taskType string) (*types.Task, error) {
 	ctx := context.Background()
 
 	var res startReconRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, err
 	}
 	err2 := res.StartReconstructionWithError.Error
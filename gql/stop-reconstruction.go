@@ -13,7 +13,7 @@ import (
 func StopReconstruction(pid string) (*types.Task, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	// make a request
 	req := graphql.NewRequest(`
@@ -34,7 +34,7 @@ func StopReconstruction(pid string) (*types.Task, error) {
 	ctx := context.Background()
 
 	var res stopReconRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return nil, err
 	}
 	if res.StopReconstruction.ID == "" {
@@ -0,0 +1,128 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+)
+
+// subProtocol is the websocket subprotocol of subscriptions-transport-ws.
+const subProtocol = "graphql-ws"
+
+// Subscription is a gql subscription over websocket.
+type Subscription struct {
+	conn      *wsConn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// subMessage is a message of the graphql-ws protocol.
+type subMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Subscribe starts the subscription of query with vars on the active
+// endpoint. It is closed once ctx is canceled.
+func Subscribe(ctx context.Context, query string, vars map[string]interface{}) (*Subscription, error) {
+	if c, err := Capabilities(); err == nil && !c.Has(types.FeatureSubscription) {
+		return nil, errors.ErrSubscriptionUnsupported
+	}
+	active := config.Load().GetActive()
+	conn, err := dialWS(ctx, active.Endpoint+"/graphql", subProtocol)
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription{conn: conn, done: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.done:
+		}
+	}()
+
+	// a. init with the same credentials of the http requests
+	init, _ := json.Marshal(map[string]string{"key": active.Key, "altitoken": active.Token})
+	if err = conn.WriteJSON(subMessage{Type: "connection_init", Payload: init}); err != nil {
+		s.Close()
+		return nil, err
+	}
+	for {
+		var m subMessage
+		if err = conn.ReadJSON(&m); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if m.Type == "connection_ack" {
+			break
+		}
+		if m.Type != "ka" {
+			s.Close()
+			return nil, errors.ErrSubscriptionUnsupported
+		}
+	}
+
+	// b. start
+	start, _ := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err = conn.WriteJSON(subMessage{ID: "1", Type: "start", Payload: start}); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Next decodes the data of the next result into v.
+// Return io.EOF if the subscription is completed by the server.
+func (s *Subscription) Next(v interface{}) error {
+	for {
+		var m subMessage
+		if err := s.conn.ReadJSON(&m); err != nil {
+			return err
+		}
+		switch m.Type {
+		case "data":
+			var res struct {
+				Data   json.RawMessage
+				Errors []struct {
+					Message string
+				}
+			}
+			if err := json.Unmarshal(m.Payload, &res); err != nil {
+				return err
+			}
+			if len(res.Errors) > 0 {
+				var msgs []string
+				for _, e := range res.Errors {
+					msgs = append(msgs, e.Message)
+				}
+				return fmt.Errorf("graphql: %s", strings.Join(msgs, "; "))
+			}
+			return json.Unmarshal(res.Data, v)
+		case "error", "connection_error":
+			return fmt.Errorf("graphql: %s", m.Payload)
+		case "complete":
+			return io.EOF
+		}
+	}
+}
+
+// Close stops the subscription and closes the connection.
+func (s *Subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.conn.WriteJSON(subMessage{ID: "1", Type: "stop"})
+		s.conn.WriteJSON(subMessage{Type: "connection_terminate"})
+		err = s.conn.Close()
+		close(s.done)
+	})
+	return err
+}
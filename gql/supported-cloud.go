@@ -2,6 +2,7 @@ package gql
 
 import (
 	"context"
+	"strings"
 
 	"github.com/jackytck/alti-cli/config"
 	"github.com/machinebox/graphql"
@@ -9,14 +10,24 @@ import (
 
 // SupportedCloud queries for the supported cloud of the given endpoint.
 // kind is "image" or "model" or "meta".
+// Clouds are returned in upper case, e.g. "S3", "OSS", "MINIO" or "GCS".
+// If endpoint and key are not given, the cached capabilities of the active
+// profile are used.
 func SupportedCloud(endpoint, key, kind string) []string {
+	if endpoint == "" && key == "" {
+		if c, err := Capabilities(); err == nil {
+			if cs, ok := c.Clouds[strings.ToLower(kind)]; ok {
+				return cs
+			}
+		}
+	}
 	if endpoint == "" || key == "" {
 		config := config.Load()
 		active := config.GetActive()
 		endpoint = active.Endpoint
 		key = active.Key
 	}
-	client := graphql.NewClient(endpoint + "/graphql")
+	client := NewClient(endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		query ($kind: UPLOAD_TYPE) {
@@ -25,7 +25,7 @@ func CheckSystemModeWithTimeout(endpoint, key string, timeout time.Duration) str
 
 // CheckSystemMode checks if the api server is in Normal, ReadOnly or Offline mode.
 func CheckSystemMode(endpoint, key string) string {
-	client := graphql.NewClient(endpoint + "/graphql")
+	client := NewClient(endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		{
@@ -0,0 +1,102 @@
+package gql
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+	"github.com/machinebox/graphql"
+)
+
+// RefreshBefore is how long before its expiry the active token is refreshed.
+var RefreshBefore = 10 * time.Minute
+
+// refreshRetryWait is the wait before retrying a failed refresh.
+const refreshRetryWait = time.Minute
+
+// noRefreshKey marks the context of a request not refreshing the token.
+type noRefreshKey struct{}
+
+var refresher = struct {
+	sync.Mutex
+	refreshed map[string]string // old -> new token
+	lastTry   time.Time
+}{refreshed: make(map[string]string)}
+
+// tokenExpiry caches the unix time of expiry of each token in the config,
+// 0 if it is never refreshed, so that most requests skip the lock and the
+// config.
+var tokenExpiry sync.Map
+
+// freshToken refreshes the active token carried by req if it expires
+// within RefreshBefore, saving the new one into the config. The tokens
+// given by env vars are not refreshed. Return ErrTokenExpired if it is
+// expired and could not be refreshed.
+func freshToken(ctx context.Context, req *graphql.Request) error {
+	token := req.Header.Get("altitoken")
+	if token == "" || ctx.Value(noRefreshKey{}) != nil {
+		return nil
+	}
+	if v, ok := tokenExpiry.Load(token); ok {
+		if exp := v.(int64); exp == 0 || time.Until(time.Unix(exp, 0)) > RefreshBefore {
+			return nil
+		}
+	}
+	refresher.Lock()
+	defer refresher.Unlock()
+
+	// refreshed by a previous request
+	if t, ok := refresher.refreshed[token]; ok {
+		req.Header.Set("altitoken", t)
+		return nil
+	}
+	conf := config.Load()
+	active := conf.GetActive()
+	if active.Token != token || active.Expiry == 0 {
+		tokenExpiry.Store(token, int64(0))
+		return nil
+	}
+	tokenExpiry.Store(token, active.Expiry)
+	expiry := time.Unix(active.Expiry, 0)
+	if time.Until(expiry) > RefreshBefore {
+		return nil
+	}
+	expired := !time.Now().Before(expiry)
+	if _, env := config.FromEnv(); env || time.Since(refresher.lastTry) < refreshRetryWait {
+		if expired {
+			return errors.ErrTokenExpired
+		}
+		return nil
+	}
+
+	refresher.lastTry = time.Now()
+	t, exp, err := RefreshUserToken(active.Endpoint, active.Key, token)
+	if err != nil {
+		if expired {
+			return errors.ErrTokenExpired
+		}
+		// retried later before the expiry
+		return nil
+	}
+	if err = conf.SetActiveToken(t, exp, true); err != nil {
+		return err
+	}
+	refresher.refreshed[token] = t
+	tokenExpiry.Store(t, exp)
+	req.Header.Set("altitoken", t)
+	return nil
+}
+
+// isAuthError tells if err is the rejection of an expired or invalid token.
+func isAuthError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"token expired", "expired token", "jwt expired", "invalid token", "unauthorized", "unauthenticated", "status code: 401"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
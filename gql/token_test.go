@@ -0,0 +1,45 @@
+package gql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/machinebox/graphql"
+)
+
+func TestFreshTokenCached(t *testing.T) {
+	tests := []struct {
+		name   string
+		expiry int64
+	}{
+		{"never refreshed", 0},
+		{"not due", time.Now().Add(2 * RefreshBefore).Unix()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := "token-" + tt.name
+			tokenExpiry.Store(token, tt.expiry)
+			defer tokenExpiry.Delete(token)
+
+			// a request not due for refresh never waits for the lock
+			refresher.Lock()
+			defer refresher.Unlock()
+			req := graphql.NewRequest("query { me { id } }")
+			req.Header.Set("altitoken", token)
+			errc := make(chan error, 1)
+			go func() { errc <- freshToken(context.Background(), req) }()
+			select {
+			case err := <-errc:
+				if err != nil {
+					t.Errorf("freshToken() error = %v", err)
+				}
+				if got := req.Header.Get("altitoken"); got != token {
+					t.Errorf("freshToken() token = %q, want %q", got, token)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("freshToken() waited for the lock")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package gql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// Trace is where the gql requests are traced to, nil to disable.
+var Trace io.Writer
+
+// traceMu serializes the trace lines of concurrent requests.
+var traceMu sync.Mutex
+
+// secretVar matches the names of variables to be redacted.
+var secretVar = regexp.MustCompile(`(?i)password|token|key|secret|code`)
+
+// opField matches the first field of the operation.
+var opField = regexp.MustCompile(`^\s*(query|mutation|subscription)?[^{]*\{\s*(\w+)`)
+
+// traceTransport logs the operation, variables, latency and response size
+// of each gql request to Trace.
+type traceTransport struct {
+	base http.RoundTripper
+}
+
+func (t traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if req.Body != nil {
+		data, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+		json.Unmarshal(data, &body)
+	}
+	op := operationName(body.Query)
+	vars := redact(body.Variables)
+
+	start := time.Now()
+	res, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		traceLog("[gql] %s %s vars=%s %s error: %v\n", req.URL, op, vars, elapsed, err)
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(data))
+	traceLog("[gql] %s %s vars=%s %s %d %s\n", req.URL, op, vars, elapsed, res.StatusCode, humanize.Bytes(uint64(len(data))))
+	return res, nil
+}
+
+// traceLog writes a trace line with timestamp.
+func traceLog(format string, a ...interface{}) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	fmt.Fprintf(Trace, time.Now().Format("2006/01/02 15:04:05.000 ")+format, a...)
+}
+
+// operationName gives the type and first field of a query,
+// e.g. 'mutation createProject'.
+func operationName(q string) string {
+	m := opField.FindStringSubmatch(q)
+	if m == nil {
+		return "unknown"
+	}
+	typ := m[1]
+	if typ == "" {
+		typ = "query"
+	}
+	return typ + " " + m[2]
+}
+
+// redact gives the json of vars with the values of secrets replaced.
+func redact(vars map[string]interface{}) string {
+	ret := make(map[string]interface{})
+	for k, v := range vars {
+		if secretVar.MatchString(k) {
+			v = "***"
+		}
+		ret[k] = v
+	}
+	data, err := json.Marshal(ret)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
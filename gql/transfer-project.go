@@ -14,7 +14,7 @@ import (
 func TransferProject(pid, email, message string) (string, error) {
 	config := config.Load()
 	active := config.GetActive()
-	client := graphql.NewClient(active.Endpoint + "/graphql")
+	client := NewClient(active.Endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		mutation ($id: ID!, $email: String!, $message: String){
@@ -37,7 +37,7 @@ func TransferProject(pid, email, message string) (string, error) {
 	ctx := context.Background()
 
 	var res transProjRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", err
 	}
 	errMsg := res.TransferProject.Error.Message
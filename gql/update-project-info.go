@@ -0,0 +1,67 @@
+package gql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackytck/alti-cli/config"
+	altiErrors "github.com/jackytck/alti-cli/errors"
+	"github.com/jackytck/alti-cli/types"
+	"github.com/machinebox/graphql"
+)
+
+// UpdateProjectInfo updates the name, visibility, description or tags of
+// project pid, leaving the unset fields of info unchanged.
+// Return the updated info.
+func UpdateProjectInfo(pid string, info types.ProjectInfo) (*types.ProjectInfoResult, error) {
+	config := config.Load()
+	active := config.GetActive()
+	client := NewClient(active.Endpoint + "/graphql")
+
+	req := graphql.NewRequest(`
+		mutation ($id: ID!, $info: ProjectInfoInput!) {
+			updateProjectInfo(id: $id, info: $info) {
+				error {
+					message
+				}
+				project {
+					id
+					name
+					visibility
+					description
+					tags
+				}
+			}
+		}
+	`)
+	req.Header.Set("key", active.Key)
+	req.Header.Set("altitoken", active.Token)
+
+	// set variables
+	req.Var("id", pid)
+	req.Var("info", info)
+
+	ctx := context.Background()
+
+	var res updateProjInfoRes
+	if err := client.RunMutation(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	if msg := res.UpdateProjectInfo.Error.Message; msg != "" {
+		return nil, errors.New(msg)
+	}
+	p := res.UpdateProjectInfo.Project
+	if p.ID == "" {
+		return nil, altiErrors.ErrProjUpdate
+	}
+	return &p, nil
+}
+
+type updateProjInfoRes struct {
+	UpdateProjectInfo struct {
+		Error struct {
+			Message string
+		}
+		Project types.ProjectInfoResult
+	}
+}
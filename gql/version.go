@@ -9,7 +9,7 @@ import (
 
 // Version gets the current version of api server.
 func Version(endpoint, key string) (string, time.Duration) {
-	client := graphql.NewClient(endpoint + "/graphql")
+	client := NewClient(endpoint + "/graphql")
 
 	req := graphql.NewRequest(`
 		{
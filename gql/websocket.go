@@ -0,0 +1,206 @@
+package gql
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// websocket opcodes
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+	wsPing  = 0x9
+	wsPong  = 0xa
+)
+
+// wsGUID is the magic of the accept key of the websocket handshake.
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsMaxMessage is the max size of a received message.
+const wsMaxMessage = 16 << 20
+
+// wsConn is a minimal client connection of the websocket protocol
+// (RFC 6455), for sending and receiving json text messages.
+type wsConn struct {
+	rwc io.ReadWriteCloser
+	br  *bufio.Reader
+	wmu sync.Mutex
+}
+
+// dialWS opens a websocket connection to the http(s) url u of subprotocol,
+// by the transport of the network settings, i.e. the proxy and TLS.
+func dialWS(ctx context.Context, u, protocol string) (*wsConn, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(b)
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Protocol", protocol)
+
+	res, err := (&http.Client{Transport: config.Transport()}).Do(req)
+	if err != nil {
+		return nil, errors.ErrOffline
+	}
+	rwc, ok := res.Body.(io.ReadWriteCloser)
+	if res.StatusCode != http.StatusSwitchingProtocols || !ok || res.Header.Get("Sec-WebSocket-Accept") != wsAccept(key) {
+		res.Body.Close()
+		return nil, errors.ErrSubscriptionUnsupported
+	}
+	return &wsConn{rwc: rwc, br: bufio.NewReader(rwc)}, nil
+}
+
+// wsAccept gives the expected accept key of the handshake of key.
+func wsAccept(key string) string {
+	h := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// WriteJSON sends v as a text message.
+func (c *wsConn) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(wsText, b)
+}
+
+// ReadJSON receives the next text message into v.
+func (c *wsConn) ReadJSON(v interface{}) error {
+	b, err := c.readMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Close closes the connection without the closing handshake.
+func (c *wsConn) Close() error {
+	return c.rwc.Close()
+}
+
+// writeFrame writes a single masked frame, as required for a client.
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	n := len(payload)
+	frame := []byte{0x80 | op}
+	switch {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n < 1<<16:
+		frame = append(frame, 0x80|126, byte(n>>8), byte(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = append(frame, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(frame[len(frame)-8:], uint64(n))
+	}
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.rwc.Write(frame)
+	return err
+}
+
+// readMessage reads the frames of the next data message, answering the
+// pings. Return io.EOF if the server closes the connection.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case wsPing:
+			if err = c.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			c.writeFrame(wsClose, nil)
+			return nil, io.EOF
+		}
+		if len(msg)+len(payload) > wsMaxMessage {
+			return nil, errors.ErrSubscriptionUnsupported
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// readFrame reads a single frame.
+func (c *wsConn) readFrame() (bool, byte, []byte, error) {
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(c.br, hdr); err != nil {
+		return false, 0, nil, err
+	}
+	fin := hdr[0]&0x80 != 0
+	op := hdr[0] & 0x0f
+	masked := hdr[1]&0x80 != 0
+	n := uint64(hdr[1] & 0x7f)
+	switch n {
+	case 126:
+		b := make([]byte, 2)
+		if _, err := io.ReadFull(c.br, b); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b))
+	case 127:
+		b := make([]byte, 8)
+		if _, err := io.ReadFull(c.br, b); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b)
+	}
+	if n > wsMaxMessage {
+		return false, 0, nil, errors.ErrSubscriptionUnsupported
+	}
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(c.br, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
@@ -0,0 +1,249 @@
+package gql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	altiErrors "github.com/jackytck/alti-cli/errors"
+)
+
+// wsServer starts a websocket server replying the handshake with the accept
+// key given by accept, then serving the connection by serve if set.
+func wsServer(t *testing.T, accept func(key string) string, serve func(rw *bufio.ReadWriter)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Sec-WebSocket-Key")
+		if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+			http.Error(w, "bad handshake", http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\nSec-WebSocket-Protocol: %s\r\n\r\n",
+			accept(key), r.Header.Get("Sec-WebSocket-Protocol"))
+		rw.Flush()
+		if serve != nil {
+			serve(rw)
+		}
+	}))
+}
+
+// wsFrame gives an unmasked frame of the server.
+func wsFrame(fin bool, op byte, payload []byte) []byte {
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0}
+	n := len(payload)
+	switch {
+	case n < 126:
+		frame = append(frame, byte(n))
+	case n < 1<<16:
+		frame = append(frame, 126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(n))
+	default:
+		frame = append(frame, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(n))
+	}
+	return append(frame, payload...)
+}
+
+// readClientFrame reads a frame sent by the client, which must be masked.
+func readClientFrame(t *testing.T, r *bufio.Reader) (byte, []byte, error) {
+	hdr, err := r.Peek(2)
+	if err != nil {
+		return 0, nil, err
+	}
+	if hdr[1]&0x80 == 0 {
+		t.Errorf("frame of opcode %#x is not masked", hdr[0]&0x0f)
+	}
+	_, op, payload, err := (&wsConn{br: r}).readFrame()
+	return op, payload, err
+}
+
+// recv receives from ch, failing t if nothing is sent in time.
+func recv(t *testing.T, ch <-chan []byte) []byte {
+	t.Helper()
+	select {
+	case b := <-ch:
+		return b
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing is received by the server")
+		return nil
+	}
+}
+
+func TestWSAccept(t *testing.T) {
+	// the example of RFC 6455
+	if got, want := wsAccept("dGhlIHNhbXBsZSBub25jZQ=="), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Errorf("wsAccept() = %q, want %q", got, want)
+	}
+}
+
+func TestDialWS(t *testing.T) {
+	tests := []struct {
+		name    string
+		accept  func(key string) string
+		wantErr error
+	}{
+		{"accepted", wsAccept, nil},
+		{"wrong accept key", func(key string) string { return wsAccept(key + "x") }, altiErrors.ErrSubscriptionUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := wsServer(t, tt.accept, nil)
+			defer srv.Close()
+			c, err := dialWS(context.Background(), srv.URL, subProtocol)
+			if err != tt.wantErr {
+				t.Fatalf("dialWS() error = %v, want %v", err, tt.wantErr)
+			}
+			if c != nil {
+				c.Close()
+			}
+		})
+	}
+
+	// not upgraded
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	if _, err := dialWS(context.Background(), srv.URL, subProtocol); err != altiErrors.ErrSubscriptionUnsupported {
+		t.Errorf("dialWS() of not upgraded error = %v, want %v", err, altiErrors.ErrSubscriptionUnsupported)
+	}
+}
+
+func TestWSWriteMasked(t *testing.T) {
+	// payloads of the 7-bit, 16-bit and 64-bit lengths
+	sizes := []int{10, 300, 70000}
+	got := make(chan []byte, len(sizes))
+	srv := wsServer(t, wsAccept, func(rw *bufio.ReadWriter) {
+		for range sizes {
+			op, payload, err := readClientFrame(t, rw.Reader)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if op != wsText {
+				t.Errorf("opcode = %#x, want %#x", op, wsText)
+			}
+			got <- payload
+		}
+	})
+	defer srv.Close()
+
+	c, err := dialWS(context.Background(), srv.URL, subProtocol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for _, n := range sizes {
+		s := strings.Repeat("a", n)
+		if err = c.WriteJSON(s); err != nil {
+			t.Fatal(err)
+		}
+		if want := `"` + s + `"`; string(recv(t, got)) != want {
+			t.Errorf("payload of %d bytes is not received as sent", n)
+		}
+	}
+}
+
+func TestWSReadFragmented(t *testing.T) {
+	part1 := bytes.Repeat([]byte("a"), 300)
+	part2 := bytes.Repeat([]byte("b"), 70000)
+	pong := make(chan []byte, 1)
+	srv := wsServer(t, wsAccept, func(rw *bufio.ReadWriter) {
+		rw.Write(wsFrame(false, wsText, part1))
+		rw.Write(wsFrame(true, wsPing, []byte("hi")))
+		rw.Write(wsFrame(true, 0x0, part2))
+		rw.Write(wsFrame(true, wsClose, nil))
+		rw.Flush()
+		for {
+			op, payload, err := readClientFrame(t, rw.Reader)
+			if err != nil {
+				return
+			}
+			if op == wsPong {
+				pong <- payload
+			}
+			if op == wsClose {
+				return
+			}
+		}
+	})
+	defer srv.Close()
+
+	c, err := dialWS(context.Background(), srv.URL, subProtocol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	msg, err := c.readMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(msg, append(part1, part2...)) {
+		t.Errorf("readMessage() gives %d bytes, want the %d bytes of the fragments", len(msg), len(part1)+len(part2))
+	}
+	if p := recv(t, pong); string(p) != "hi" {
+		t.Errorf("pong = %q, want %q", p, "hi")
+	}
+	if _, err = c.readMessage(); err != io.EOF {
+		t.Errorf("readMessage() after close error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestSubscriptionNext(t *testing.T) {
+	msgs := []string{
+		`{"type":"ka"}`,
+		`{"id":"1","type":"data","payload":{"data":{"n":1}}}`,
+		`{"id":"1","type":"data","payload":{"errors":[{"message":"denied"}]}}`,
+		`{"id":"1","type":"error","payload":{"message":"bad query"}}`,
+		`{"id":"1","type":"complete"}`,
+	}
+	srv := wsServer(t, wsAccept, func(rw *bufio.ReadWriter) {
+		for _, m := range msgs {
+			rw.Write(wsFrame(true, wsText, []byte(m)))
+		}
+		rw.Flush()
+		// until the subscription is closed
+		for {
+			if _, _, err := readClientFrame(t, rw.Reader); err != nil {
+				return
+			}
+		}
+	})
+	defer srv.Close()
+
+	c, err := dialWS(context.Background(), srv.URL, subProtocol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Subscription{conn: c, done: make(chan struct{})}
+	defer s.Close()
+
+	var v struct{ N int }
+	if err = s.Next(&v); err != nil || v.N != 1 {
+		t.Errorf("Next() = %v, %v, want n of 1", v, err)
+	}
+	wantErrs := []string{"graphql: denied", `graphql: {"message":"bad query"}`}
+	for _, want := range wantErrs {
+		if err = s.Next(&v); err == nil || err.Error() != want {
+			t.Errorf("Next() error = %v, want %q", err, want)
+		}
+	}
+	if err = s.Next(&v); err != io.EOF {
+		t.Errorf("Next() of complete error = %v, want %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,106 @@
+package lifecycle
+
+import (
+	"sync"
+)
+
+// Modes of handling ctrl+c or SIGTERM.
+const (
+	Graceful = "graceful" // stop the running operations and let them clean up, abort on another one
+	Abort    = "abort"    // run the cleanup hooks and exit immediately
+)
+
+// Modes are the supported modes of handling ctrl+c or SIGTERM.
+var Modes = []string{Graceful, Abort}
+
+// Manager keeps the cleanup hooks registered by the components of a command,
+// e.g. the direct upload server, the uploaders and the temp files, and runs
+// each of them exactly once, either when the component is done or when the
+// process is terminated early. It is safe for concurrent use.
+type Manager struct {
+	mu    sync.Mutex
+	hooks []*Hook
+}
+
+// Hook is a cleanup hook registered to a Manager.
+type Hook struct {
+	m    *Manager
+	name string
+	fn   func()
+	once sync.Once
+}
+
+// Default is the manager of the process.
+var Default = &Manager{}
+
+// Register registers the cleanup hook fn of name.
+func (m *Manager) Register(name string, fn func()) *Hook {
+	h := &Hook{m: m, name: name, fn: fn}
+	m.mu.Lock()
+	m.hooks = append(m.hooks, h)
+	m.mu.Unlock()
+	return h
+}
+
+// Release unregisters h and runs it if not yet run, usually deferred.
+func (h *Hook) Release() {
+	h.Remove()
+	h.once.Do(h.fn)
+}
+
+// Remove unregisters h without running it, e.g. once its component is
+// finished normally.
+func (h *Hook) Remove() {
+	m := h.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, o := range m.hooks {
+		if o == h {
+			m.hooks = append(m.hooks[:i], m.hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Cleanup runs the registered hooks in the reverse order of registration and
+// unregisters them. Hooks that are running or have run are not run again.
+func (m *Manager) Cleanup() {
+	for {
+		m.mu.Lock()
+		n := len(m.hooks)
+		if n == 0 {
+			m.mu.Unlock()
+			return
+		}
+		h := m.hooks[n-1]
+		m.hooks = m.hooks[:n-1]
+		m.mu.Unlock()
+		h.once.Do(h.fn)
+	}
+}
+
+// Pending gives the names of the registered hooks, in the order of registration.
+func (m *Manager) Pending() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ret []string
+	for _, h := range m.hooks {
+		ret = append(ret, h.name)
+	}
+	return ret
+}
+
+// Register registers the cleanup hook fn of name to the Default manager.
+func Register(name string, fn func()) *Hook {
+	return Default.Register(name, fn)
+}
+
+// Cleanup runs the hooks of the Default manager.
+func Cleanup() {
+	Default.Cleanup()
+}
+
+// Pending gives the names of the hooks of the Default manager.
+func Pending() []string {
+	return Default.Pending()
+}
@@ -0,0 +1,71 @@
+package lifecycle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		release []int // indexes of the hooks released before cleanup
+		remove  []int // indexes of the hooks removed before cleanup
+		want    []string
+	}{
+		{"cleanup all", nil, nil, []string{"c", "b", "a"}},
+		{"released", []int{1}, nil, []string{"b", "c", "a"}},
+		{"released twice", []int{0, 0, 2}, nil, []string{"a", "c", "b"}},
+		{"removed", nil, []int{0, 2}, []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Manager
+			var got []string
+			var hooks []*Hook
+			for _, n := range []string{"a", "b", "c"} {
+				n := n
+				hooks = append(hooks, m.Register(n, func() { got = append(got, n) }))
+			}
+			for _, i := range tt.release {
+				hooks[i].Release()
+			}
+			for _, i := range tt.remove {
+				hooks[i].Remove()
+			}
+			m.Cleanup()
+			m.Cleanup()
+			for i, h := range hooks {
+				if !contains(tt.remove, i) {
+					h.Release()
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hooks run = %v, want %v", got, tt.want)
+			}
+			if p := m.Pending(); len(p) != 0 {
+				t.Errorf("Pending() = %v, want none", p)
+			}
+		})
+	}
+}
+
+func TestPending(t *testing.T) {
+	var m Manager
+	m.Register("server", func() {})
+	h := m.Register("temp", func() {})
+	m.Register("uploader", func() {})
+	h.Release()
+	want := []string{"server", "uploader"}
+	if got := m.Pending(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Pending() = %v, want %v", got, want)
+	}
+}
+
+func contains(a []int, x int) bool {
+	for _, v := range a {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
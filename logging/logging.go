@@ -0,0 +1,163 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// Level is the severity of a log.
+type Level int
+
+// Levels of logs, in increasing severity.
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+// Levels are the names of all the levels.
+var Levels = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < Debug || l > Error {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return Levels[l]
+}
+
+// ParseLevel parses the case-insensitive name of a level.
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warning" {
+		s = "warn"
+	}
+	for i, n := range Levels {
+		if s == n {
+			return Level(i), nil
+		}
+	}
+	return Info, errors.ErrInvalidInput
+}
+
+var (
+	mu     sync.Mutex
+	level  = Info
+	asJSON bool
+)
+
+func init() {
+	// the final error of errors.Exit follows the level and format too
+	errors.SetExitLogger(func(err error) {
+		Errorln(err)
+	})
+}
+
+// SetLevel sets the minimum level of logs to be written.
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// SetJSON sets whether to write each log as a json object per line,
+// instead of the plain text of the standard logger.
+func SetJSON(b bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	asJSON = b
+}
+
+// Enabled tells if the logs of level l are written.
+func Enabled(l Level) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return l >= level
+}
+
+// entry is a json log.
+type entry struct {
+	Time  string `json:"time"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+// output writes msg of level l to the output of the standard logger,
+// so that it could be redirected by log.SetOutput.
+func output(l Level, msg string) {
+	mu.Lock()
+	minLevel, j := level, asJSON
+	mu.Unlock()
+	if l < minLevel {
+		return
+	}
+	msg = strings.TrimSuffix(msg, "\n")
+
+	if j {
+		b, err := json.Marshal(entry{
+			Time:  time.Now().Format(time.RFC3339),
+			Level: l.String(),
+			Msg:   msg,
+		})
+		if err != nil {
+			return
+		}
+		log.Writer().Write(append(b, '\n'))
+		return
+	}
+	if l != Info {
+		msg = fmt.Sprintf("[%s] %s", strings.ToUpper(l.String()), msg)
+	}
+	log.Output(3, msg)
+}
+
+// sprintln formats as fmt.Sprintln without the trailing newline.
+func sprintln(v ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+}
+
+// Debugf logs a debug message in the manner of fmt.Printf.
+func Debugf(format string, v ...interface{}) {
+	output(Debug, fmt.Sprintf(format, v...))
+}
+
+// Debugln logs a debug message in the manner of fmt.Println.
+func Debugln(v ...interface{}) {
+	output(Debug, sprintln(v...))
+}
+
+// Infof logs an info message in the manner of fmt.Printf.
+func Infof(format string, v ...interface{}) {
+	output(Info, fmt.Sprintf(format, v...))
+}
+
+// Infoln logs an info message in the manner of fmt.Println.
+func Infoln(v ...interface{}) {
+	output(Info, sprintln(v...))
+}
+
+// Warnf logs a warning in the manner of fmt.Printf.
+func Warnf(format string, v ...interface{}) {
+	output(Warn, fmt.Sprintf(format, v...))
+}
+
+// Warnln logs a warning in the manner of fmt.Println.
+func Warnln(v ...interface{}) {
+	output(Warn, sprintln(v...))
+}
+
+// Errorf logs an error in the manner of fmt.Printf.
+func Errorf(format string, v ...interface{}) {
+	output(Error, fmt.Sprintf(format, v...))
+}
+
+// Errorln logs an error in the manner of fmt.Println.
+func Errorln(v ...interface{}) {
+	output(Error, sprintln(v...))
+}
@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", "debug", Debug, false},
+		{"upper case", "WARN", Warn, false},
+		{"warning", "warning", Warn, false},
+		{"spaces", " error ", Error, false},
+		{"unknown", "verbose", Info, true},
+		{"empty", "", Info, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevel(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseLevel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
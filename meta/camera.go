@@ -0,0 +1,117 @@
+package meta
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Formats of the camera files that could be converted.
+const (
+	Pix4D     = "pix4d"     // calibrated camera parameters of Pix4D
+	Metashape = "metashape" // cameras xml of Agisoft Metashape
+	OPK       = "opk"       // csv of name, position and omega, phi, kappa
+)
+
+// Formats are the supported formats of the camera files.
+var Formats = []string{Pix4D, Metashape, OPK}
+
+// Camera is the pose and the optional calibration of an image.
+type Camera struct {
+	Filename string
+	// X, Y and Z are the position, i.e. longitude, latitude and altitude if
+	// Geodetic is set, otherwise easting, northing and height of a projected
+	// coordinate system.
+	X, Y, Z  float64
+	Geodetic bool
+	// Omega, Phi and Kappa are the rotation in degrees, zeros if unknown.
+	Omega, Phi, Kappa float64
+	// Calibration is the intrinsics of the camera, nil if unknown.
+	Calibration *Calibration
+}
+
+// Calibration is the intrinsics of a camera in pixels.
+type Calibration struct {
+	Width, Height  int
+	Fx, Fy, Cx, Cy float64
+	K1, K2, K3     float64 // radial distortion
+	P1, P2         float64 // tangential distortion
+}
+
+// Read reads the cameras of the camera file of format.
+func Read(format string, r io.Reader) ([]Camera, error) {
+	switch format {
+	case Pix4D:
+		return ReadPix4D(r)
+	case Metashape:
+		return ReadMetashape(r)
+	case OPK:
+		return ReadOPK(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// WritePose writes the cameras in the format of pose.txt, i.e. one
+// 'filename latitude longitude altitude' per line, sorted by filename.
+// All of the cameras must be geodetic.
+func WritePose(w io.Writer, cams []Camera) error {
+	cams = sortedCameras(cams)
+	for _, c := range cams {
+		if !c.Geodetic {
+			return fmt.Errorf("position of %q is not geodetic", c.Filename)
+		}
+	}
+	for _, c := range cams {
+		if _, err := fmt.Fprintf(w, "%s %.8f %.8f %.3f\n", c.Filename, c.Y, c.X, c.Z); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCamera writes the calibrations of the cameras in the format of
+// camera.txt, i.e. one 'filename width height fx fy cx cy k1 k2 k3 p1 p2'
+// per line, sorted by filename. Cameras without calibration are skipped.
+// Return the number of written cameras.
+func WriteCamera(w io.Writer, cams []Camera) (int, error) {
+	var n int
+	for _, c := range sortedCameras(cams) {
+		k := c.Calibration
+		if k == nil {
+			continue
+		}
+		_, err := fmt.Fprintf(w, "%s %d %d %.6f %.6f %.6f %.6f %.8f %.8f %.8f %.8f %.8f\n",
+			c.Filename, k.Width, k.Height, k.Fx, k.Fy, k.Cx, k.Cy, k.K1, k.K2, k.K3, k.P1, k.P2)
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// ToGeodetic converts the projected positions of cams in the UTM zone, e.g.
+// '50N', to longitudes and latitudes. Geodetic cameras are kept as is.
+func ToGeodetic(cams []Camera, zone string) error {
+	z, north, err := ParseUTMZone(zone)
+	if err != nil {
+		return err
+	}
+	for i, c := range cams {
+		if c.Geodetic {
+			continue
+		}
+		lat, lng := UTMToLatLng(z, north, c.X, c.Y)
+		cams[i].X, cams[i].Y, cams[i].Geodetic = lng, lat, true
+	}
+	return nil
+}
+
+// sortedCameras gives a copy of cams sorted by filename.
+func sortedCameras(cams []Camera) []Camera {
+	ret := append([]Camera{}, cams...)
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Filename < ret[j].Filename
+	})
+	return ret
+}
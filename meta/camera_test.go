@@ -0,0 +1,52 @@
+package meta
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestWritePose(t *testing.T) {
+	cams := []Camera{
+		{Filename: "b.jpg", X: 114.1, Y: 22.3, Z: 120, Geodetic: true},
+		{Filename: "a.jpg", X: -79.387139, Y: 43.642567, Z: 553.3, Geodetic: true},
+	}
+	var buf bytes.Buffer
+	if err := WritePose(&buf, cams); err != nil {
+		t.Fatal(err)
+	}
+	want := "a.jpg 43.64256700 -79.38713900 553.300\nb.jpg 22.30000000 114.10000000 120.000\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WritePose() = %q, want %q", got, want)
+	}
+
+	cams = append(cams, Camera{Filename: "c.jpg", X: 630084, Y: 4833438})
+	if err := WritePose(&bytes.Buffer{}, cams); err == nil {
+		t.Error("WritePose() of projected camera, want error")
+	}
+	if err := ToGeodetic(cams, "17N"); err != nil {
+		t.Fatal(err)
+	}
+	if c := cams[2]; !c.Geodetic || math.Abs(c.Y-43.642567) > 1e-4 || math.Abs(c.X+79.387139) > 1e-4 {
+		t.Errorf("ToGeodetic() = %+v", c)
+	}
+	if cams[0].X != 114.1 {
+		t.Errorf("ToGeodetic() changed the geodetic camera: %+v", cams[0])
+	}
+}
+
+func TestWriteCamera(t *testing.T) {
+	cams := []Camera{
+		{Filename: "b.jpg", Calibration: &Calibration{Width: 4000, Height: 3000, Fx: 2800, Fy: 2800, Cx: 2000, Cy: 1500, K1: 0.1}},
+		{Filename: "a.jpg"},
+	}
+	var buf bytes.Buffer
+	n, err := WriteCamera(&buf, cams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "b.jpg 4000 3000 2800.000000 2800.000000 2000.000000 1500.000000 0.10000000 0.00000000 0.00000000 0.00000000 0.00000000\n"
+	if got := buf.String(); n != 1 || got != want {
+		t.Errorf("WriteCamera() = %d %q, want 1 %q", n, got, want)
+	}
+}
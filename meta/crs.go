@@ -0,0 +1,220 @@
+package meta
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// helmert is the 7-parameter transformation of a datum to WGS84 by the
+// position vector convention (EPSG:9606), in meters, arc seconds and ppm.
+type helmert struct {
+	tx, ty, tz float64
+	rx, ry, rz float64
+	s          float64
+}
+
+// apply transforms the earth-centered, earth-fixed coordinates.
+func (t helmert) apply(x, y, z float64) (float64, float64, float64) {
+	sec := math.Pi / 180 / 3600
+	rx, ry, rz := t.rx*sec, t.ry*sec, t.rz*sec
+	k := 1 + t.s*1e-6
+	return t.tx + k*(x-rz*y+ry*z),
+		t.ty + k*(rz*x+y-rx*z),
+		t.tz + k*(-ry*x+rx*y+z)
+}
+
+// datum is a geodetic datum of ellipsoid el, transformed to WGS84 by shift,
+// nil if it is the same as WGS84 within a meter, e.g. ETRS89 and CGCS2000.
+type datum struct {
+	el    ellipsoid
+	shift *helmert
+}
+
+// toWGS84 converts the latitude, longitude and height of the datum to WGS84.
+func (d datum) toWGS84(lat, lng, h float64) (float64, float64, float64) {
+	if d.shift == nil {
+		return lat, lng, h
+	}
+	x, y, z := latLngToECEF(d.el, lat, lng, h)
+	x, y, z = d.shift.apply(x, y, z)
+	return ecefToLatLng(wgs84, x, y, z)
+}
+
+var (
+	wgs84Datum  = datum{el: wgs84}
+	grs80Datum  = datum{el: ellipsoid{6378137, 1 / 298.257222101}}
+	hk1980Datum = datum{
+		el: ellipsoid{6378388, 1 / 297.0},
+		// EPSG:1825
+		shift: &helmert{-162.619, -276.959, -161.764, 0.067753, -2.243649, -1.158827, -1.094246},
+	}
+	osgb36Datum = datum{
+		el: ellipsoid{6377563.396, 1 / 299.3249646},
+		// EPSG:1314
+		shift: &helmert{446.448, -125.157, 542.06, 0.15, 0.247, 0.842, -20.489},
+	}
+)
+
+// CRS is a coordinate reference system of an EPSG code, of which the
+// positions are converted to WGS84.
+type CRS struct {
+	Code int
+	Name string
+	// Geodetic tells if the positions are longitudes and latitudes.
+	Geodetic bool
+	datum    datum
+	tm       *transverseMercator // nil if not projected by transverse mercator
+	convert  func(x, y, z float64) (float64, float64, float64)
+}
+
+// CRSCodes describes the supported EPSG codes.
+var CRSCodes = []string{
+	"4326 (WGS 84)",
+	"4978 (WGS 84 geocentric)",
+	"3857 (web mercator)",
+	"326zz and 327zz (WGS 84 / UTM zone zzN and zzS)",
+	"4258 (ETRS89), 25828-25838 (ETRS89 / UTM)",
+	"4269 (NAD83), 26901-26923 (NAD83 / UTM)",
+	"4490 (CGCS2000), 4534-4554 (CGCS2000 / 3-degree Gauss-Kruger CM 75E-135E)",
+	"2326 (Hong Kong 1980 Grid)",
+	"27700 (British National Grid)",
+}
+
+// ParseCRS parses the EPSG code of a supported CRS, e.g. 'EPSG:2326' or '2326'.
+func ParseCRS(s string) (*CRS, error) {
+	t := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "EPSG:")
+	code, err := strconv.Atoi(t)
+	if err != nil {
+		return nil, fmt.Errorf("invalid crs %q, expect an EPSG code, e.g. EPSG:2326", s)
+	}
+	c := CRS{Code: code}
+	switch {
+	case code == 4326:
+		c.Name, c.Geodetic, c.datum = "WGS 84", true, wgs84Datum
+	case code == 4258:
+		c.Name, c.Geodetic, c.datum = "ETRS89", true, grs80Datum
+	case code == 4269:
+		c.Name, c.Geodetic, c.datum = "NAD83", true, grs80Datum
+	case code == 4490:
+		c.Name, c.Geodetic, c.datum = "CGCS2000", true, grs80Datum
+	case code == 4978:
+		c.Name = "WGS 84 geocentric"
+		c.convert = func(x, y, z float64) (float64, float64, float64) {
+			return ECEFToLatLng(x, y, z)
+		}
+	case code == 3857:
+		c.Name = "WGS 84 / Pseudo-Mercator"
+		c.convert = func(x, y, z float64) (float64, float64, float64) {
+			lat := (2*math.Atan(math.Exp(y/wgs84A)) - math.Pi/2) * 180 / math.Pi
+			return lat, x / wgs84A * 180 / math.Pi, z
+		}
+	case code >= 32601 && code <= 32660, code >= 32701 && code <= 32760:
+		zone, north := code%100, code < 32700
+		c.Name = fmt.Sprintf("WGS 84 / UTM zone %d%s", zone, hemisphere(north))
+		c.datum, c.tm = wgs84Datum, tmOf(utm(zone, north))
+	case code >= 25828 && code <= 25838:
+		c.Name = fmt.Sprintf("ETRS89 / UTM zone %dN", code-25800)
+		c.datum, c.tm = grs80Datum, tmOf(utm(code-25800, true))
+	case code >= 26901 && code <= 26923:
+		c.Name = fmt.Sprintf("NAD83 / UTM zone %dN", code-26900)
+		c.datum, c.tm = grs80Datum, tmOf(utm(code-26900, true))
+	case code >= 4534 && code <= 4554:
+		cm := 75 + 3*(code-4534)
+		c.Name = fmt.Sprintf("CGCS2000 / 3-degree Gauss-Kruger CM %dE", cm)
+		c.datum, c.tm = grs80Datum, &transverseMercator{lng0: float64(cm), k0: 1, fe: 500000}
+	case code == 2326:
+		c.Name, c.datum = "Hong Kong 1980 Grid", hk1980Datum
+		c.tm = &transverseMercator{
+			lat0: 22 + 18/60.0 + 43.68/3600,
+			lng0: 114 + 10/60.0 + 42.80/3600,
+			k0:   1, fe: 836694.05, fn: 819069.80,
+		}
+	case code == 27700:
+		c.Name, c.datum = "British National Grid", osgb36Datum
+		c.tm = &transverseMercator{lat0: 49, lng0: -2, k0: 0.9996012717, fe: 400000, fn: -100000}
+	default:
+		return nil, fmt.Errorf("unsupported crs EPSG:%d, supported codes are: %s", code, strings.Join(CRSCodes, ", "))
+	}
+	return &c, nil
+}
+
+// String gives the code and name, e.g. 'EPSG:2326 (Hong Kong 1980 Grid)'.
+func (c *CRS) String() string {
+	return fmt.Sprintf("EPSG:%d (%s)", c.Code, c.Name)
+}
+
+// ToWGS84 converts the position of the crs to latitude, longitude and
+// altitude of WGS84. x and y are longitude and latitude if it is geodetic,
+// otherwise easting and northing, or the geocentric x and y. The heights of
+// the projected and geodetic crs are kept as is, as they are usually above
+// the local geoid instead of the ellipsoid.
+func (c *CRS) ToWGS84(x, y, z float64) (float64, float64, float64) {
+	if c.convert != nil {
+		return c.convert(x, y, z)
+	}
+	lat, lng := y, x
+	if c.tm != nil {
+		lat, lng = c.tm.toLatLng(c.datum.el, x, y)
+	}
+	lat, lng, _ = c.datum.toWGS84(lat, lng, z)
+	return lat, lng, z
+}
+
+// Reproject converts the positions of cams in crs to longitudes, latitudes
+// and altitudes of WGS84. The positions must be geodetic if crs is, and
+// projected otherwise.
+func Reproject(cams []Camera, crs *CRS) error {
+	for _, c := range cams {
+		if c.Geodetic != crs.Geodetic {
+			if c.Geodetic {
+				return fmt.Errorf("position of %q is geodetic, but %s is not", c.Filename, crs)
+			}
+			return fmt.Errorf("position of %q is projected, but %s is geodetic", c.Filename, crs)
+		}
+	}
+	for i, c := range cams {
+		lat, lng, alt := crs.ToWGS84(c.X, c.Y, c.Z)
+		cams[i].X, cams[i].Y, cams[i].Z, cams[i].Geodetic = lng, lat, alt, true
+	}
+	return nil
+}
+
+// ReprojectPose converts the positions of the pose.txt of r in crs, i.e.
+// 'filename northing easting height', or 'filename latitude longitude height'
+// if crs is geodetic, to WGS84. Lines that could not be parsed are kept as is,
+// so that they are reported by Validate on the same lines.
+func ReprojectPose(r io.Reader, crs *CRS) (io.Reader, error) {
+	var b bytes.Buffer
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		toks := strings.Fields(s.Text())
+		var v [3]float64
+		var err error
+		for i := 0; i < len(v) && err == nil && len(toks) == 4; i++ {
+			v[i], err = strconv.ParseFloat(toks[i+1], 64)
+		}
+		if len(toks) != 4 || err != nil {
+			fmt.Fprintln(&b, s.Text())
+			continue
+		}
+		lat, lng, alt := crs.ToWGS84(v[1], v[0], v[2])
+		fmt.Fprintf(&b, "%s %.8f %.8f %.3f\n", toks[0], lat, lng, alt)
+	}
+	return &b, s.Err()
+}
+
+func tmOf(tm transverseMercator) *transverseMercator {
+	return &tm
+}
+
+func hemisphere(north bool) string {
+	if north {
+		return "N"
+	}
+	return "S"
+}
@@ -0,0 +1,91 @@
+package meta
+
+import (
+	"io/ioutil"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseCRS(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{"EPSG:2326", "EPSG:2326 (Hong Kong 1980 Grid)", false},
+		{"epsg:32650", "EPSG:32650 (WGS 84 / UTM zone 50N)", false},
+		{"32718", "EPSG:32718 (WGS 84 / UTM zone 18S)", false},
+		{"4547", "EPSG:4547 (CGCS2000 / 3-degree Gauss-Kruger CM 114E)", false},
+		{"EPSG:9999", "", true},
+		{"wgs84", "", true},
+	}
+	for _, tt := range tests {
+		c, err := ParseCRS(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCRS(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if err == nil && c.String() != tt.want {
+			t.Errorf("ParseCRS(%q) = %q, want %q", tt.s, c, tt.want)
+		}
+	}
+}
+
+func TestCRS_ToWGS84(t *testing.T) {
+	dms := func(d, m, s float64) float64 {
+		return d + m/60 + s/3600
+	}
+	tests := []struct {
+		name     string
+		code     string
+		x, y     float64
+		lat, lng float64
+		tol      float64 // in degrees
+	}{
+		// the origin, shifted by the approximation of the Survey and Mapping Office
+		{"hk1980 grid", "EPSG:2326", 836694.05, 819069.80, dms(22, 18, 43.68-5.5), dms(114, 10, 42.80+8.8), 2e-5},
+		{"utm", "EPSG:32617", 630084, 4833438, 43.642567, -79.387139, 1e-4},
+		{"web mercator", "EPSG:3857", 20037508.342789244, 0, 0, 180, 1e-9},
+		{"cgcs2000", "EPSG:4547", 500000, 0, 0, 114, 1e-9},
+		{"wgs84", "EPSG:4326", 114.1694, 22.3193, 22.3193, 114.1694, 1e-12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCRS(tt.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lat, lng, h := c.ToWGS84(tt.x, tt.y, 10)
+			if math.Abs(lat-tt.lat) > tt.tol || math.Abs(lng-tt.lng) > tt.tol || h != 10 {
+				t.Errorf("ToWGS84() = %.7f %.7f %.3f, want %.7f %.7f 10", lat, lng, h, tt.lat, tt.lng)
+			}
+		})
+	}
+}
+
+func TestTransverseMercator(t *testing.T) {
+	// worked example of the Ordnance Survey, on the OSGB36 datum
+	tm := transverseMercator{lat0: 49, lng0: -2, k0: 0.9996012717, fe: 400000, fn: -100000}
+	lat, lng := tm.toLatLng(osgb36Datum.el, 651409.903, 313177.270)
+	wantLat, wantLng := 52+39/60.0+27.2531/3600, 1+43/60.0+4.5177/3600
+	if math.Abs(lat-wantLat) > 1e-6 || math.Abs(lng-wantLng) > 1e-6 {
+		t.Errorf("toLatLng() = %.7f %.7f, want %.7f %.7f", lat, lng, wantLat, wantLng)
+	}
+}
+
+func TestReprojectPose(t *testing.T) {
+	c, err := ParseCRS("EPSG:32650")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ReprojectPose(strings.NewReader("a.jpg 2469000 208000 55.5\nb.jpg 1 2\n"), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(r)
+	lines := strings.Split(string(b), "\n")
+	if !strings.HasPrefix(lines[0], "a.jpg 22.3") || !strings.HasSuffix(lines[0], " 55.500") || lines[1] != "b.jpg 1 2" {
+		t.Errorf("ReprojectPose() = %q", b)
+	}
+}
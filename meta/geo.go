@@ -0,0 +1,141 @@
+package meta
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WGS84 ellipsoid and the scale of UTM.
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+	utmK0  = 0.9996
+)
+
+// ellipsoid is a reference ellipsoid of semi-major axis a and flattening f.
+type ellipsoid struct {
+	a, f float64
+}
+
+// e2 gives the squared eccentricity.
+func (el ellipsoid) e2() float64 {
+	return el.f * (2 - el.f)
+}
+
+var wgs84 = ellipsoid{wgs84A, wgs84F}
+
+// transverseMercator is a transverse mercator projection of the origin lat0,
+// lng0 in degrees, the scale k0 and the false easting and northing.
+type transverseMercator struct {
+	lat0, lng0 float64
+	k0         float64
+	fe, fn     float64
+}
+
+// ParseUTMZone parses the UTM zone of number and hemisphere, e.g. '50N' or '18S'.
+func ParseUTMZone(s string) (int, bool, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return 0, false, fmt.Errorf("invalid utm zone %q, expect e.g. 50N or 18S", s)
+	}
+	h := s[len(s)-1]
+	z, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || z < 1 || z > 60 || (h != 'N' && h != 'S') {
+		return 0, false, fmt.Errorf("invalid utm zone %q, expect e.g. 50N or 18S", s)
+	}
+	return z, h == 'N', nil
+}
+
+// utm gives the projection of the UTM zone.
+func utm(zone int, north bool) transverseMercator {
+	tm := transverseMercator{lng0: float64((zone-1)*6 - 180 + 3), k0: utmK0, fe: 500000}
+	if !north {
+		tm.fn = 10000000
+	}
+	return tm
+}
+
+// UTMToLatLng converts the easting and northing of the UTM zone to latitude
+// and longitude in degrees, by the series of Snyder's Map Projections.
+func UTMToLatLng(zone int, north bool, easting, northing float64) (float64, float64) {
+	return utm(zone, north).toLatLng(wgs84, easting, northing)
+}
+
+// meridianArc gives the distance along the meridian from the equator to the
+// latitude phi in radians.
+func meridianArc(el ellipsoid, phi float64) float64 {
+	e2 := el.e2()
+	e4, e6 := e2*e2, e2*e2*e2
+	return el.a * ((1-e2/4-3*e4/64-5*e6/256)*phi -
+		(3*e2/8+3*e4/32+45*e6/1024)*math.Sin(2*phi) +
+		(15*e4/256+45*e6/1024)*math.Sin(4*phi) -
+		(35*e6/3072)*math.Sin(6*phi))
+}
+
+// toLatLng converts the easting and northing of the projection on ellipsoid
+// el to latitude and longitude in degrees.
+func (tm transverseMercator) toLatLng(el ellipsoid, easting, northing float64) (float64, float64) {
+	e2 := el.e2()
+	ep2 := e2 / (1 - e2)
+	x := easting - tm.fe
+	y := northing - tm.fn
+
+	m := meridianArc(el, tm.lat0*math.Pi/180) + y/tm.k0
+	mu := m / (el.a * (1 - e2/4 - 3*e2*e2/64 - 5*e2*e2*e2/256))
+	e1 := (1 - math.Sqrt(1-e2)) / (1 + math.Sqrt(1-e2))
+	phi1 := mu +
+		(3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+		(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+		(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+		(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+
+	sin1, cos1, tan1 := math.Sin(phi1), math.Cos(phi1), math.Tan(phi1)
+	n1 := el.a / math.Sqrt(1-e2*sin1*sin1)
+	t1 := tan1 * tan1
+	c1 := ep2 * cos1 * cos1
+	r1 := el.a * (1 - e2) / math.Pow(1-e2*sin1*sin1, 1.5)
+	d := x / (n1 * tm.k0)
+
+	lat := phi1 - (n1*tan1/r1)*(d*d/2-
+		(5+3*t1+10*c1-4*c1*c1-9*ep2)*math.Pow(d, 4)/24+
+		(61+90*t1+298*c1+45*t1*t1-252*ep2-3*c1*c1)*math.Pow(d, 6)/720)
+	lng := (d - (1+2*t1+c1)*math.Pow(d, 3)/6 +
+		(5-2*c1+28*t1-3*c1*c1+8*ep2+24*t1*t1)*math.Pow(d, 5)/120) / cos1
+	return lat * 180 / math.Pi, tm.lng0 + lng*180/math.Pi
+}
+
+// ECEFToLatLng converts the earth-centered, earth-fixed coordinates to
+// latitude, longitude in degrees and the ellipsoidal height.
+func ECEFToLatLng(x, y, z float64) (float64, float64, float64) {
+	return ecefToLatLng(wgs84, x, y, z)
+}
+
+// ecefToLatLng is ECEFToLatLng of ellipsoid el.
+func ecefToLatLng(el ellipsoid, x, y, z float64) (float64, float64, float64) {
+	e2 := el.e2()
+	lng := math.Atan2(y, x)
+	p := math.Hypot(x, y)
+	lat := math.Atan2(z, p*(1-e2))
+	var h float64
+	for i := 0; i < 10; i++ {
+		sin := math.Sin(lat)
+		n := el.a / math.Sqrt(1-e2*sin*sin)
+		h = p/math.Cos(lat) - n
+		lat = math.Atan2(z, p*(1-e2*n/(n+h)))
+	}
+	return lat * 180 / math.Pi, lng * 180 / math.Pi, h
+}
+
+// latLngToECEF converts the latitude, longitude in degrees and the ellipsoidal
+// height on ellipsoid el to the earth-centered, earth-fixed coordinates.
+func latLngToECEF(el ellipsoid, lat, lng, h float64) (float64, float64, float64) {
+	e2 := el.e2()
+	phi, lambda := lat*math.Pi/180, lng*math.Pi/180
+	n := el.a / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+	x := (n + h) * math.Cos(phi) * math.Cos(lambda)
+	y := (n + h) * math.Cos(phi) * math.Sin(lambda)
+	z := (n*(1-e2) + h) * math.Sin(phi)
+	return x, y, z
+}
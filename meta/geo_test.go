@@ -0,0 +1,73 @@
+package meta
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseUTMZone(t *testing.T) {
+	tests := []struct {
+		s       string
+		zone    int
+		north   bool
+		wantErr bool
+	}{
+		{"50N", 50, true, false},
+		{"18s", 18, false, false},
+		{"61N", 0, false, true},
+		{"17T", 0, false, true},
+		{"N", 0, false, true},
+	}
+	for _, tt := range tests {
+		z, north, err := ParseUTMZone(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseUTMZone(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if z != tt.zone || north != tt.north {
+			t.Errorf("ParseUTMZone(%q) = %d %v, want %d %v", tt.s, z, north, tt.zone, tt.north)
+		}
+	}
+}
+
+func TestUTMToLatLng(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     int
+		north    bool
+		e, n     float64
+		lat, lng float64
+	}{
+		{"cn tower", 17, true, 630084, 4833438, 43.642567, -79.387139},
+		{"equator", 31, true, 500000, 0, 0, 3},
+		{"south equator", 56, false, 500000, 10000000, 0, 153},
+	}
+	for _, tt := range tests {
+		lat, lng := UTMToLatLng(tt.zone, tt.north, tt.e, tt.n)
+		if math.Abs(lat-tt.lat) > 1e-4 || math.Abs(lng-tt.lng) > 1e-4 {
+			t.Errorf("UTMToLatLng(%s) = %f %f, want %f %f", tt.name, lat, lng, tt.lat, tt.lng)
+		}
+	}
+}
+
+func TestECEFToLatLng(t *testing.T) {
+	tests := []struct {
+		lat, lng, h float64
+	}{
+		{22.3193, 114.1694, 120},
+		{-33.8568, 151.2153, 5},
+		{0, 0, 0},
+	}
+	e2 := wgs84F * (2 - wgs84F)
+	for _, tt := range tests {
+		phi, lambda := tt.lat*math.Pi/180, tt.lng*math.Pi/180
+		n := wgs84A / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+		x := (n + tt.h) * math.Cos(phi) * math.Cos(lambda)
+		y := (n + tt.h) * math.Cos(phi) * math.Sin(lambda)
+		z := (n*(1-e2) + tt.h) * math.Sin(phi)
+		lat, lng, h := ECEFToLatLng(x, y, z)
+		if math.Abs(lat-tt.lat) > 1e-8 || math.Abs(lng-tt.lng) > 1e-8 || math.Abs(h-tt.h) > 1e-3 {
+			t.Errorf("ECEFToLatLng() = %f %f %f, want %f %f %f", lat, lng, h, tt.lat, tt.lng, tt.h)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package meta
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type msDocument struct {
+	Chunk  []msChunk `xml:"chunk"`
+	Chunks struct {
+		Chunk []msChunk `xml:"chunk"`
+	} `xml:"chunks"`
+}
+
+type msChunk struct {
+	Sensors []msSensor `xml:"sensors>sensor"`
+	Cameras struct {
+		Camera []msCamera `xml:"camera"`
+		Group  []struct {
+			Camera []msCamera `xml:"camera"`
+		} `xml:"group"`
+	} `xml:"cameras"`
+	Transform struct {
+		Rotation    string `xml:"rotation"`
+		Translation string `xml:"translation"`
+		Scale       string `xml:"scale"`
+	} `xml:"transform"`
+}
+
+type msSensor struct {
+	ID          string `xml:"id,attr"`
+	Calibration *struct {
+		Resolution struct {
+			Width  int `xml:"width,attr"`
+			Height int `xml:"height,attr"`
+		} `xml:"resolution"`
+		F  float64 `xml:"f"`
+		Cx float64 `xml:"cx"`
+		Cy float64 `xml:"cy"`
+		B1 float64 `xml:"b1"`
+		K1 float64 `xml:"k1"`
+		K2 float64 `xml:"k2"`
+		K3 float64 `xml:"k3"`
+		P1 float64 `xml:"p1"`
+		P2 float64 `xml:"p2"`
+	} `xml:"calibration"`
+}
+
+type msCamera struct {
+	SensorID  string `xml:"sensor_id,attr"`
+	Label     string `xml:"label,attr"`
+	Transform string `xml:"transform"`
+	Reference *struct {
+		X *float64 `xml:"x,attr"`
+		Y *float64 `xml:"y,attr"`
+		Z *float64 `xml:"z,attr"`
+	} `xml:"reference"`
+}
+
+// ReadMetashape reads the cameras xml of Agisoft Metashape. The positions of
+// the aligned cameras are converted from the chunk to WGS84 by its transform,
+// otherwise the references of the cameras, which are assumed to be of WGS84,
+// are used. Cameras of neither are skipped. The labels are the filenames,
+// usually without extension.
+func ReadMetashape(r io.Reader) ([]Camera, error) {
+	var doc msDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("metashape: %v", err)
+	}
+	chunks := append(doc.Chunk, doc.Chunks.Chunk...)
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("metashape: no chunk")
+	}
+
+	var ret []Camera
+	for _, ch := range chunks {
+		calibs := make(map[string]*Calibration)
+		for _, s := range ch.Sensors {
+			k := s.Calibration
+			if k == nil {
+				continue
+			}
+			w, h := k.Resolution.Width, k.Resolution.Height
+			// the principal point is offset from the center, and p1 and p2
+			// are swapped compared to OpenCV
+			calibs[s.ID] = &Calibration{
+				Width:  w,
+				Height: h,
+				Fx:     k.F + k.B1,
+				Fy:     k.F,
+				Cx:     float64(w)/2 + k.Cx,
+				Cy:     float64(h)/2 + k.Cy,
+				K1:     k.K1,
+				K2:     k.K2,
+				K3:     k.K3,
+				P1:     k.P2,
+				P2:     k.P1,
+			}
+		}
+		toECEF, err := chunkTransform(ch)
+		if err != nil {
+			return nil, err
+		}
+
+		cams := ch.Cameras.Camera
+		for _, g := range ch.Cameras.Group {
+			cams = append(cams, g.Camera...)
+		}
+		for _, mc := range cams {
+			c := Camera{Filename: mc.Label, Geodetic: true, Calibration: calibs[mc.SensorID]}
+			m, err := parseFloats(mc.Transform, 16)
+			switch {
+			case err == nil && toECEF != nil:
+				x, y, z := toECEF(m[3], m[7], m[11])
+				c.Y, c.X, c.Z = ECEFToLatLng(x, y, z)
+			case mc.Reference != nil && mc.Reference.X != nil && mc.Reference.Y != nil && mc.Reference.Z != nil:
+				c.X, c.Y, c.Z = *mc.Reference.X, *mc.Reference.Y, *mc.Reference.Z
+			default:
+				continue
+			}
+			ret = append(ret, c)
+		}
+	}
+	return ret, nil
+}
+
+// chunkTransform gives the transform of the positions of ch to the ECEF, nil
+// if ch is not georeferenced.
+func chunkTransform(ch msChunk) (func(x, y, z float64) (float64, float64, float64), error) {
+	t := ch.Transform
+	if t.Rotation == "" || t.Translation == "" {
+		return nil, nil
+	}
+	r, err := parseFloats(t.Rotation, 9)
+	if err != nil {
+		return nil, fmt.Errorf("metashape: invalid chunk rotation: %v", err)
+	}
+	tr, err := parseFloats(t.Translation, 3)
+	if err != nil {
+		return nil, fmt.Errorf("metashape: invalid chunk translation: %v", err)
+	}
+	s := 1.0
+	if t.Scale != "" {
+		if s, err = strconv.ParseFloat(strings.TrimSpace(t.Scale), 64); err != nil {
+			return nil, fmt.Errorf("metashape: invalid chunk scale: %v", err)
+		}
+	}
+	return func(x, y, z float64) (float64, float64, float64) {
+		return s*(r[0]*x+r[1]*y+r[2]*z) + tr[0],
+			s*(r[3]*x+r[4]*y+r[5]*z) + tr[1],
+			s*(r[6]*x+r[7]*y+r[8]*z) + tr[2]
+	}, nil
+}
+
+// parseFloats parses the n floats separated by spaces of s.
+func parseFloats(s string, n int) ([]float64, error) {
+	fs := strings.Fields(s)
+	if len(fs) != n {
+		return nil, fmt.Errorf("expect %d values, got %d", n, len(fs))
+	}
+	ret := make([]float64, n)
+	for i, f := range fs {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = v
+	}
+	return ret, nil
+}
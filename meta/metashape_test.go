@@ -0,0 +1,77 @@
+package meta
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// the chunk is georeferenced by the identity rotation, so that the camera
+// position is the ECEF translated
+const metashapeXML = `<?xml version="1.0" encoding="UTF-8"?>
+<document version="1.4.0">
+  <chunk label="Chunk 1" enabled="true">
+    <sensors next_id="1">
+      <sensor id="0" label="FC6310 (8.8mm)" type="frame">
+        <resolution width="5472" height="3648"/>
+        <calibration type="frame" class="adjusted">
+          <resolution width="5472" height="3648"/>
+          <f>3700</f>
+          <cx>-12</cx>
+          <cy>8</cy>
+          <b1>2</b1>
+          <k1>-0.01</k1>
+          <k2>0.02</k2>
+          <k3>-0.003</k3>
+          <p1>0.0001</p1>
+          <p2>0.0002</p2>
+        </calibration>
+      </sensor>
+    </sensors>
+    <cameras next_id="3" next_group_id="1">
+      <camera id="0" sensor_id="0" label="DJI_0001">
+        <transform>1 0 0 0 0 1 0 0 0 0 1 0 0 0 0 1</transform>
+        <reference x="1" y="2" z="3" enabled="true"/>
+      </camera>
+      <group id="0" label="Group 1" type="folder">
+        <camera id="1" sensor_id="0" label="DJI_0002">
+          <reference x="114.1" y="22.3" z="120" enabled="true"/>
+        </camera>
+        <camera id="2" sensor_id="0" label="DJI_0003"/>
+      </group>
+    </cameras>
+    <transform>
+      <rotation locked="false">1 0 0 0 1 0 0 0 1</rotation>
+      <translation locked="false">6378137 0 0</translation>
+      <scale locked="false">1</scale>
+    </transform>
+  </chunk>
+</document>`
+
+func TestReadMetashape(t *testing.T) {
+	cams, err := ReadMetashape(strings.NewReader(metashapeXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cams) != 2 {
+		t.Fatalf("ReadMetashape() = %d cameras, want 2", len(cams))
+	}
+
+	a := cams[0]
+	if a.Filename != "DJI_0001" || !a.Geodetic || math.Abs(a.X) > 1e-9 || math.Abs(a.Y) > 1e-9 || math.Abs(a.Z) > 1e-6 {
+		t.Errorf("ReadMetashape() aligned = %+v, want at 0 0 0", a)
+	}
+	k := a.Calibration
+	if k == nil || k.Fx != 3702 || k.Fy != 3700 || k.Cx != 2724 || k.Cy != 1832 || k.P1 != 0.0002 || k.P2 != 0.0001 {
+		t.Errorf("ReadMetashape() calibration = %+v", k)
+	}
+
+	b := cams[1]
+	if b.Filename != "DJI_0002" || b.X != 114.1 || b.Y != 22.3 || b.Z != 120 {
+		t.Errorf("ReadMetashape() referenced = %+v", b)
+	}
+
+	if _, err := ReadMetashape(strings.NewReader("<document></document>")); err == nil {
+		t.Error("ReadMetashape() without chunk, want error")
+	}
+}
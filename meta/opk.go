@@ -0,0 +1,140 @@
+package meta
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// opkColumns maps the headers of the columns of an opk csv to the fields.
+var opkColumns = map[string]string{
+	"imagename": "name",
+	"name":      "name",
+	"filename":  "name",
+	"image":     "name",
+	"label":     "name",
+	"latitude":  "lat",
+	"lat":       "lat",
+	"longitude": "lng",
+	"lng":       "lng",
+	"lon":       "lng",
+	"long":      "lng",
+	"altitude":  "alt",
+	"alt":       "alt",
+	"height":    "alt",
+	"x":         "x",
+	"easting":   "x",
+	"y":         "y",
+	"northing":  "y",
+	"z":         "z",
+	"omega":     "omega",
+	"phi":       "phi",
+	"kappa":     "kappa",
+}
+
+// ReadOPK reads the csv of the name, position and rotation of each image,
+// separated by commas, tabs or spaces, with a header naming the columns, e.g.
+// 'imageName,latitude,longitude,altitude,omega,phi,kappa' or
+// 'name x y z omega phi kappa' as the external camera parameters of Pix4D.
+// Positions of latitude and longitude are geodetic, otherwise projected.
+func ReadOPK(r io.Reader) ([]Camera, error) {
+	br := bufio.NewReader(r)
+	rows, err := readRows(br)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("opk: no header")
+	}
+
+	cols := make(map[string]int)
+	for i, h := range rows[0] {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if f, ok := opkColumns[h]; ok {
+			if _, dup := cols[f]; !dup {
+				cols[f] = i
+			}
+		}
+	}
+	_, hasLat := cols["lat"]
+	_, hasLng := cols["lng"]
+	geodetic := hasLat && hasLng
+	required := []string{"name", "x", "y", "z"}
+	if geodetic {
+		required = []string{"name", "lat", "lng", "alt"}
+	}
+	for _, f := range required {
+		if _, ok := cols[f]; !ok {
+			return nil, fmt.Errorf("opk: missing column %q in header %q", f, strings.Join(rows[0], ","))
+		}
+	}
+
+	var ret []Camera
+	for i, row := range rows[1:] {
+		val := func(f string) (float64, error) {
+			c, ok := cols[f]
+			if !ok {
+				return 0, nil
+			}
+			if c >= len(row) {
+				return 0, fmt.Errorf("opk: missing %s in line %d", f, i+2)
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(row[c]), 64)
+			if err != nil {
+				return 0, fmt.Errorf("opk: invalid %s in line %d: %v", f, i+2, err)
+			}
+			return v, nil
+		}
+		if cols["name"] >= len(row) {
+			return nil, fmt.Errorf("opk: missing name in line %d", i+2)
+		}
+		c := Camera{Filename: strings.TrimSpace(row[cols["name"]]), Geodetic: geodetic}
+		fields := []struct {
+			name string
+			dst  *float64
+		}{
+			{"x", &c.X}, {"y", &c.Y}, {"z", &c.Z},
+			{"omega", &c.Omega}, {"phi", &c.Phi}, {"kappa", &c.Kappa},
+		}
+		if geodetic {
+			fields[0].name, fields[1].name, fields[2].name = "lng", "lat", "alt"
+		}
+		for _, f := range fields {
+			if *f.dst, err = val(f.name); err != nil {
+				return nil, err
+			}
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+// readRows reads the non-empty rows separated by commas if the first line has
+// any, otherwise by tabs or spaces.
+func readRows(br *bufio.Reader) ([][]string, error) {
+	head, err := br.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	first := string(head)
+	if i := strings.IndexByte(first, '\n'); i >= 0 {
+		first = first[:i]
+	}
+	if strings.Contains(first, ",") {
+		cr := csv.NewReader(br)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		return cr.ReadAll()
+	}
+	var rows [][]string
+	scanner := bufio.NewScanner(br)
+	for scanner.Scan() {
+		if fs := strings.Fields(scanner.Text()); len(fs) > 0 {
+			rows = append(rows, fs)
+		}
+	}
+	return rows, scanner.Err()
+}
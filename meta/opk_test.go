@@ -0,0 +1,49 @@
+package meta
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadOPK(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Camera
+		wantErr bool
+	}{
+		{
+			"csv geodetic",
+			"Filename, Latitude, Longitude, Altitude, Omega, Phi, Kappa\nA.JPG, 22.3, 114.1, 120.5, 1, 2, 3\n",
+			[]Camera{{Filename: "A.JPG", X: 114.1, Y: 22.3, Z: 120.5, Geodetic: true, Omega: 1, Phi: 2, Kappa: 3}},
+			false,
+		},
+		{
+			"pix4d external",
+			"imageName X Y Z Omega Phi Kappa\nB.JPG 834012.1 2473112.5 150.25 0.5 -0.5 90\n\n",
+			[]Camera{{Filename: "B.JPG", X: 834012.1, Y: 2473112.5, Z: 150.25, Omega: 0.5, Phi: -0.5, Kappa: 90}},
+			false,
+		},
+		{
+			"without rotation",
+			"name\tlat\tlng\talt\nC.JPG\t22.3\t114.1\t10\n",
+			[]Camera{{Filename: "C.JPG", X: 114.1, Y: 22.3, Z: 10, Geodetic: true}},
+			false,
+		},
+		{"missing column", "name,lat,lng\nA.JPG,22.3,114.1\n", nil, true},
+		{"invalid value", "name,x,y,z\nA.JPG,1,two,3\n", nil, true},
+		{"empty", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadOPK(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadOPK() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadOPK() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
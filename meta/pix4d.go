@@ -0,0 +1,77 @@
+package meta
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// pix4dBlock is the number of values of each camera of the Pix4D calibrated
+// camera parameters after its name, width and height, i.e. the camera matrix
+// K (3x3), radial distortion (3), tangential distortion (2), position (3) and
+// rotation R (3x3).
+const pix4dBlock = 9 + 3 + 2 + 3 + 9
+
+// ReadPix4D reads the calibrated camera parameters of Pix4D, i.e. the
+// '_calibrated_camera_parameters.txt' of its outputs. The positions are of
+// the output coordinate system, which is projected.
+func ReadPix4D(r io.Reader) ([]Camera, error) {
+	var tokens []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		l := strings.TrimSpace(scanner.Text())
+		// the title is not commented
+		if l == "" || strings.HasPrefix(l, "#") || strings.HasPrefix(l, "Pix4D") {
+			continue
+		}
+		tokens = append(tokens, strings.Fields(l)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var ret []Camera
+	for len(tokens) > 0 {
+		if len(tokens) < 3+pix4dBlock {
+			return nil, fmt.Errorf("pix4d: incomplete camera %q", tokens[0])
+		}
+		name := tokens[0]
+		w, errW := strconv.Atoi(tokens[1])
+		h, errH := strconv.Atoi(tokens[2])
+		if errW != nil || errH != nil {
+			return nil, fmt.Errorf("pix4d: invalid dimension of camera %q", name)
+		}
+		v := make([]float64, pix4dBlock)
+		for i := range v {
+			f, err := strconv.ParseFloat(tokens[3+i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("pix4d: invalid value of camera %q: %v", name, err)
+			}
+			v[i] = f
+		}
+		tokens = tokens[3+pix4dBlock:]
+
+		ret = append(ret, Camera{
+			Filename: name,
+			X:        v[14],
+			Y:        v[15],
+			Z:        v[16],
+			Calibration: &Calibration{
+				Width:  w,
+				Height: h,
+				Fx:     v[0],
+				Fy:     v[4],
+				Cx:     v[2],
+				Cy:     v[5],
+				K1:     v[9],
+				K2:     v[10],
+				K3:     v[11],
+				P1:     v[12],
+				P2:     v[13],
+			},
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,50 @@
+package meta
+
+import (
+	"strings"
+	"testing"
+)
+
+const pix4dCalib = `Pix4D camera calibration file 0
+#Focal Length mm assuming a sensor width of 12.83x9.62mm
+#Image Width Height
+#camera matrix K [3x3]
+#radial distortion [3x1]
+#tangential distortion [2x1]
+#camera position t [3x1]
+#camera rotation R [3x3]
+#camera model m = K [R|-Rt] X
+
+DJI_0001.JPG 4000 3000
+2800.5 0 2010.2
+0 2800.5 1495.8
+0 0 1
+-0.01 0.02 -0.003
+0.0001 -0.0002
+834012.1 2473112.5 150.25
+1 0 0
+0 -1 0
+0 0 -1
+`
+
+func TestReadPix4D(t *testing.T) {
+	cams, err := ReadPix4D(strings.NewReader(pix4dCalib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cams) != 1 {
+		t.Fatalf("ReadPix4D() = %d cameras, want 1", len(cams))
+	}
+	c := cams[0]
+	if c.Filename != "DJI_0001.JPG" || c.X != 834012.1 || c.Y != 2473112.5 || c.Z != 150.25 || c.Geodetic {
+		t.Errorf("ReadPix4D() = %+v", c)
+	}
+	k := c.Calibration
+	if k == nil || k.Width != 4000 || k.Height != 3000 || k.Fx != 2800.5 || k.Cx != 2010.2 || k.Cy != 1495.8 || k.K3 != -0.003 || k.P2 != -0.0002 {
+		t.Errorf("ReadPix4D() calibration = %+v", k)
+	}
+
+	if _, err := ReadPix4D(strings.NewReader("DJI_0001.JPG 4000 3000\n1 2 3\n")); err == nil {
+		t.Error("ReadPix4D() of incomplete camera, want error")
+	}
+}
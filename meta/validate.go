@@ -0,0 +1,273 @@
+package meta
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Meta files that could be validated.
+const (
+	CameraTxt = "camera.txt"
+	PoseTxt   = "pose.txt"
+	GroupTxt  = "group.txt"
+)
+
+// Files are the names of the meta files that could be validated.
+var Files = []string{CameraTxt, PoseTxt, GroupTxt}
+
+// outlierDistance is the distance in meters from the median position beyond
+// which a pose is regarded as an outlier.
+const outlierDistance = 50000
+
+// Issue is a problem of a meta file, of a line if Line is positive.
+type Issue struct {
+	File    string
+	Line    int
+	Message string
+	Warning bool
+}
+
+func (i Issue) String() string {
+	level := "error"
+	if i.Warning {
+		level = "warning"
+	}
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, level, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.File, level, i.Message)
+}
+
+// Report is the result of validating a meta file.
+type Report struct {
+	File   string
+	Names  map[string]int // line of each image name
+	Issues []Issue
+}
+
+// Errors gives the number of issues that are not warnings.
+func (r *Report) Errors() int {
+	var n int
+	for _, i := range r.Issues {
+		if !i.Warning {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) errorf(line int, format string, a ...interface{}) {
+	r.Issues = append(r.Issues, Issue{r.File, line, fmt.Sprintf(format, a...), false})
+}
+
+func (r *Report) warnf(line int, format string, a ...interface{}) {
+	r.Issues = append(r.Issues, Issue{r.File, line, fmt.Sprintf(format, a...), true})
+}
+
+// Validate validates the meta file of name, i.e. camera.txt, pose.txt or
+// group.txt, by its column counts, numeric ranges and duplicate entries. Poses
+// are also checked for swapped coordinates and outliers.
+func Validate(name string, r io.Reader) (*Report, error) {
+	var line func(*Report, int, []string)
+	cols := 0
+	switch name {
+	case CameraTxt:
+		line, cols = cameraLine, 12
+	case PoseTxt:
+		line, cols = poseLine, 4
+	case GroupTxt:
+		line, cols = groupLine, 2
+	default:
+		return nil, fmt.Errorf("unknown meta file %q", name)
+	}
+
+	rep := &Report{File: name, Names: make(map[string]int)}
+	var poses []pose
+	s := bufio.NewScanner(r)
+	for i := 1; s.Scan(); i++ {
+		toks := strings.Fields(s.Text())
+		if len(toks) == 0 {
+			continue
+		}
+		if len(toks) != cols {
+			rep.errorf(i, "expect %d columns, got %d", cols, len(toks))
+			continue
+		}
+		if first, ok := rep.Names[toks[0]]; ok {
+			rep.errorf(i, "duplicate entry of %q, first defined on line %d", toks[0], first)
+			continue
+		}
+		rep.Names[toks[0]] = i
+		n := len(rep.Issues)
+		line(rep, i, toks)
+		if name == PoseTxt && !hasError(rep.Issues[n:]) {
+			lat, _ := strconv.ParseFloat(toks[1], 64)
+			lng, _ := strconv.ParseFloat(toks[2], 64)
+			poses = append(poses, pose{i, toks[0], lat, lng})
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(rep.Names) == 0 && rep.Errors() == 0 {
+		rep.errorf(0, "no entry is found")
+	}
+	checkOutliers(rep, poses)
+	sort.SliceStable(rep.Issues, func(i, j int) bool {
+		return rep.Issues[i].Line < rep.Issues[j].Line
+	})
+	return rep, nil
+}
+
+// CrossCheck reports the names of the meta file that are not one of the
+// images, and gives the number of images not defined in it.
+func (r *Report) CrossCheck(images map[string]bool, where string) int {
+	var names []string
+	for n := range r.Names {
+		if !images[n] {
+			names = append(names, n)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return r.Names[names[i]] < r.Names[names[j]]
+	})
+	for _, n := range names {
+		r.errorf(r.Names[n], "image %q is not found in %s", n, where)
+	}
+	var undefined int
+	for img := range images {
+		if _, ok := r.Names[img]; !ok {
+			undefined++
+		}
+	}
+	sort.SliceStable(r.Issues, func(i, j int) bool {
+		return r.Issues[i].Line < r.Issues[j].Line
+	})
+	return undefined
+}
+
+// cameraLine validates 'filename width height fx fy cx cy k1 k2 k3 p1 p2'.
+func cameraLine(r *Report, line int, toks []string) {
+	w, errW := strconv.Atoi(toks[1])
+	h, errH := strconv.Atoi(toks[2])
+	if errW != nil || errH != nil || w <= 0 || h <= 0 {
+		r.errorf(line, "width and height must be positive integers, got %q and %q", toks[1], toks[2])
+		return
+	}
+	v, ok := parseColumns(r, line, toks[3:], []string{"fx", "fy", "cx", "cy", "k1", "k2", "k3", "p1", "p2"})
+	if !ok {
+		return
+	}
+	fx, fy, cx, cy := v[0], v[1], v[2], v[3]
+	if fx <= 0 || fy <= 0 {
+		r.errorf(line, "focal lengths must be positive, got fx %g and fy %g", fx, fy)
+	}
+	if cx <= 0 || cx >= float64(w) || cy <= 0 || cy >= float64(h) {
+		r.errorf(line, "principal point (%g, %g) is outside the image of %dx%d", cx, cy, w, h)
+	}
+	if fx > 0 && fy > 0 && (fx/fy > 1.1 || fy/fx > 1.1) {
+		r.warnf(line, "fx %g and fy %g differ by more than 10%%", fx, fy)
+	}
+	for i, k := range v[4:] {
+		if math.Abs(k) > 10 {
+			r.warnf(line, "distortion %s %g is unusually large", []string{"k1", "k2", "k3", "p1", "p2"}[i], k)
+		}
+	}
+}
+
+// poseLine validates 'filename latitude longitude altitude'.
+func poseLine(r *Report, line int, toks []string) {
+	v, ok := parseColumns(r, line, toks[1:], []string{"latitude", "longitude", "altitude"})
+	if !ok {
+		return
+	}
+	lat, lng, alt := v[0], v[1], v[2]
+	switch {
+	case math.Abs(lat) > 90 && math.Abs(lng) <= 90:
+		r.errorf(line, "latitude %g is out of [-90, 90], are latitude and longitude swapped?", lat)
+		return
+	case math.Abs(lat) > 90:
+		r.errorf(line, "latitude %g is out of [-90, 90]", lat)
+		return
+	case math.Abs(lng) > 180:
+		r.errorf(line, "longitude %g is out of [-180, 180]", lng)
+		return
+	}
+	if lat == 0 && lng == 0 {
+		r.warnf(line, "position is (0, 0), is the GPS missing?")
+	}
+	if alt < -500 || alt > 10000 {
+		r.warnf(line, "altitude %g is out of [-500, 10000] meters", alt)
+	}
+}
+
+// groupLine validates 'filename group'.
+func groupLine(r *Report, line int, toks []string) {
+	if g, err := strconv.Atoi(toks[1]); err != nil || g < 0 {
+		r.warnf(line, "group %q is not a non-negative integer", toks[1])
+	}
+}
+
+// parseColumns parses the finite numbers of the named columns.
+func parseColumns(r *Report, line int, toks, names []string) ([]float64, bool) {
+	ret := make([]float64, len(toks))
+	for i, t := range toks {
+		v, err := strconv.ParseFloat(t, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			r.errorf(line, "%s %q is not a number", names[i], t)
+			return nil, false
+		}
+		ret[i] = v
+	}
+	return ret, true
+}
+
+func hasError(issues []Issue) bool {
+	for _, i := range issues {
+		if !i.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+type pose struct {
+	line     int
+	name     string
+	lat, lng float64
+}
+
+// checkOutliers warns the poses farther than outlierDistance from the median
+// position.
+func checkOutliers(r *Report, poses []pose) {
+	if len(poses) < 3 {
+		return
+	}
+	lats := make([]float64, len(poses))
+	lngs := make([]float64, len(poses))
+	for i, p := range poses {
+		lats[i], lngs[i] = p.lat, p.lng
+	}
+	sort.Float64s(lats)
+	sort.Float64s(lngs)
+	mLat, mLng := lats[len(lats)/2], lngs[len(lngs)/2]
+	for _, p := range poses {
+		if d := distance(mLat, mLng, p.lat, p.lng); d > outlierDistance {
+			r.warnf(p.line, "%q is %.0f km away from the other images", p.name, d/1000)
+		}
+	}
+}
+
+// distance gives the great-circle distance in meters between two positions.
+func distance(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := math.Pi / 180
+	dLat, dLng := (lat2-lat1)*rad, (lng2-lng1)*rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * wgs84A * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,93 @@
+package meta
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		in   string
+		want []string // issues
+	}{
+		{
+			"valid pose",
+			PoseTxt,
+			"A.JPG 22.3 114.1 120\nB.JPG 22.3001 114.1001 121\n\n",
+			nil,
+		},
+		{
+			"pose issues",
+			PoseTxt,
+			"A.JPG 22.3 114.1 120\nB.JPG 114.1 22.3 120\nC.JPG 22.3 114.1\nA.JPG 22.3 114.1 120\nD.JPG 22.3 north 1\nE.JPG 22.31 114.11 20000\n",
+			[]string{
+				"pose.txt:2: error: latitude 114.1 is out of [-90, 90], are latitude and longitude swapped?",
+				"pose.txt:3: error: expect 4 columns, got 3",
+				`pose.txt:4: error: duplicate entry of "A.JPG", first defined on line 1`,
+				`pose.txt:5: error: longitude "north" is not a number`,
+				"pose.txt:6: warning: altitude 20000 is out of [-500, 10000] meters",
+			},
+		},
+		{
+			"pose outlier",
+			PoseTxt,
+			"A.JPG 22.3 114.1 10\nB.JPG 22.31 114.1 10\nC.JPG 0 0 10\n",
+			[]string{
+				"pose.txt:3: warning: position is (0, 0), is the GPS missing?",
+				`pose.txt:3: warning: "C.JPG" is 12490 km away from the other images`,
+			},
+		},
+		{
+			"camera",
+			CameraTxt,
+			"A.JPG 4000 3000 3000 3000 2000 1500 0.1 0 0 0 0\nB.JPG 4000 0 3000 3000 2000 1500 0 0 0 0 0\nC.JPG 4000 3000 3000 3000 5000 1500 0 0 0 0 0\n",
+			[]string{
+				`camera.txt:2: error: width and height must be positive integers, got "4000" and "0"`,
+				"camera.txt:3: error: principal point (5000, 1500) is outside the image of 4000x3000",
+			},
+		},
+		{
+			"group",
+			GroupTxt,
+			"A.JPG 0\nB.JPG 1\nC.JPG nadir\nD.JPG\n",
+			[]string{
+				`group.txt:3: warning: group "nadir" is not a non-negative integer`,
+				"group.txt:4: error: expect 2 columns, got 1",
+			},
+		},
+		{"empty", GroupTxt, "\n", []string{"group.txt: error: no entry is found"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep, err := Validate(tt.file, strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, i := range rep.Issues {
+				got = append(got, i.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() issues =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestReport_CrossCheck(t *testing.T) {
+	rep, err := Validate(GroupTxt, strings.NewReader("A.JPG 0\nB.JPG 0\nC.JPG 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	undefined := rep.CrossCheck(map[string]bool{"A.JPG": true, "C.JPG": true, "D.JPG": true, "E.JPG": true}, "~/myimg")
+	if undefined != 2 {
+		t.Errorf("CrossCheck() = %d undefined images, want 2", undefined)
+	}
+	want := []Issue{{GroupTxt, 2, `image "B.JPG" is not found in ~/myimg`, false}}
+	if !reflect.DeepEqual(rep.Issues, want) {
+		t.Errorf("CrossCheck() issues = %v, want %v", rep.Issues, want)
+	}
+}
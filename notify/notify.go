@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+)
+
+// Statuses of a finished command.
+const (
+	Succeeded   = "succeeded"
+	Failed      = "failed"
+	Interrupted = "interrupted"
+)
+
+// Summary is the result of a finished command, posted as json.
+type Summary struct {
+	Text     string         `json:"text"` // one line summary, e.g. for chat webhooks
+	Command  string         `json:"command"`
+	Status   string         `json:"status"`
+	Error    string         `json:"error,omitempty"`
+	ExitCode int            `json:"exitCode"`
+	Project  string         `json:"project,omitempty"`
+	Stats    map[string]int `json:"stats,omitempty"` // e.g. the number of total and failed files
+	Host     string         `json:"host"`
+	Version  string         `json:"version"`
+	Started  time.Time      `json:"started"`
+	Finished time.Time      `json:"finished"`
+	Duration float64        `json:"duration"` // in seconds
+}
+
+// Line gives the one line summary, e.g. 'alti-cli import image succeeded in
+// 1h2m3s, failed: 3, total: 1200'.
+func (s Summary) Line() string {
+	d := s.Finished.Sub(s.Started).Round(time.Second)
+	ret := fmt.Sprintf("%s %s in %s", s.Command, s.Status, d)
+	if s.Project != "" {
+		ret += fmt.Sprintf(" of project %s", s.Project)
+	}
+	var keys []string
+	for k := range s.Stats {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ret += fmt.Sprintf(", %s: %d", k, s.Stats[k])
+	}
+	if s.Error != "" {
+		ret += fmt.Sprintf(", error: %s", s.Error)
+	}
+	return ret
+}
+
+// Post posts the summary as json to the url within timeout. Status other
+// than 2xx gives error.
+func Post(ctx context.Context, url string, s Summary, timeout time.Duration) error {
+	if s.Text == "" {
+		s.Text = s.Line()
+	}
+	if s.Duration == 0 {
+		s.Duration = s.Finished.Sub(s.Started).Seconds()
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequest("POST", url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := config.HTTPClient(0).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("notification is rejected: %s", res.Status)
+	}
+	return nil
+}
+
+// Desktop shows a desktop notification by notify-send on linux, osascript on
+// macOS or powershell on windows.
+func Desktop(title, msg string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("notify-send", title, msg)
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %s with title %s", appleScriptString(msg), appleScriptString(title)))
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms;
+$n = New-Object System.Windows.Forms.NotifyIcon;
+$n.Icon = [System.Drawing.SystemIcons]::Information;
+$n.Visible = $true;
+$n.ShowBalloonTip(10000, %s, %s, 'Info');
+Start-Sleep -Seconds 10;
+$n.Dispose()`, powershellString(title), powershellString(msg))
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		return fmt.Errorf("desktop notification is not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// appleScriptString quotes s as a string literal of AppleScript.
+func appleScriptString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+}
+
+// powershellString quotes s as a single-quoted string literal of powershell.
+func powershellString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
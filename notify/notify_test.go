@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSummary_Line(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		s    Summary
+		want string
+	}{
+		{
+			"succeeded",
+			Summary{Command: "alti-cli import image", Status: Succeeded, Project: "5d37e", Stats: map[string]int{"total": 1200, "failed": 3}, Started: start, Finished: start.Add(time.Hour + 2*time.Minute)},
+			"alti-cli import image succeeded in 1h2m0s of project 5d37e, failed: 3, total: 1200",
+		},
+		{
+			"failed",
+			Summary{Command: "alti-cli sync", Status: Failed, Error: "server: offline", Started: start, Finished: start.Add(5 * time.Second)},
+			"alti-cli sync failed in 5s, error: server: offline",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Line(); got != tt.want {
+				t.Errorf("Line() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPost(t *testing.T) {
+	var got Summary
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if got.Status == Failed {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer ts.Close()
+
+	start := time.Now()
+	s := Summary{Command: "alti-cli import image", Status: Succeeded, Started: start, Finished: start.Add(90 * time.Second)}
+	if err := Post(context.Background(), ts.URL, s, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != s.Line() || got.Duration != 90 {
+		t.Errorf("posted text %q and duration %v, want %q and 90", got.Text, got.Duration, s.Line())
+	}
+	s.Status = Failed
+	if err := Post(context.Background(), ts.URL, s, time.Second); err == nil {
+		t.Error("Post() of rejected notification gives no error")
+	}
+}
@@ -0,0 +1,245 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// Styles of the tables.
+const (
+	StyleTable    = "table"    // decorated table with borders
+	StylePlain    = "plain"    // columns aligned by spaces
+	StyleMarkdown = "markdown" // github flavored markdown table
+	StyleCSV      = "csv"      // header and rows, without footer
+)
+
+// Styles are the supported styles of the tables.
+var Styles = []string{StyleTable, StylePlain, StyleMarkdown, StyleCSV}
+
+// Table collects the rows of a table and renders them in a style, keeping
+// only the selected columns. Its methods mirror the ones of tablewriter.
+type Table struct {
+	w       io.Writer
+	style   string
+	columns []string
+	header  []string
+	footer  []string
+	rows    [][]string
+	wrap    bool
+}
+
+// NewTable creates a table writing to w in style, of all columns if columns
+// is empty. Columns are matched to the header case-insensitively, ignoring
+// spaces and punctuation, e.g. 'TaskState' matches 'Task State'.
+func NewTable(w io.Writer, style string, columns []string) *Table {
+	if style == "" {
+		style = StyleTable
+	}
+	return &Table{w: w, style: strings.ToLower(style), columns: columns, wrap: true}
+}
+
+// SetHeader sets the header.
+func (t *Table) SetHeader(h []string) {
+	t.header = h
+}
+
+// SetFooter sets the footer, which is omitted in csv.
+func (t *Table) SetFooter(f []string) {
+	t.footer = f
+}
+
+// SetAutoWrapText sets if the long cells are wrapped in the decorated table.
+func (t *Table) SetAutoWrapText(b bool) {
+	t.wrap = b
+}
+
+// Append adds a row.
+func (t *Table) Append(r []string) {
+	t.rows = append(t.rows, r)
+}
+
+// AppendBulk adds the rows.
+func (t *Table) AppendBulk(rs [][]string) {
+	t.rows = append(t.rows, rs...)
+}
+
+// NumLines gives the number of rows.
+func (t *Table) NumLines() int {
+	return len(t.rows)
+}
+
+// Render writes the table. It fails if the style is unknown or a column is
+// not in the header. Tables without header have no columns to select.
+func (t *Table) Render() error {
+	idx, err := t.selected()
+	if err != nil {
+		return err
+	}
+	header := pick(t.header, idx)
+	footer := pick(t.footer, idx)
+	rows := make([][]string, len(t.rows))
+	for i, r := range t.rows {
+		rows[i] = pick(r, idx)
+	}
+
+	switch t.style {
+	case StyleTable:
+		table := tablewriter.NewWriter(t.w)
+		if len(header) > 0 {
+			table.SetHeader(header)
+		}
+		if len(footer) > 0 {
+			table.SetFooter(footer)
+		}
+		table.SetAutoWrapText(t.wrap)
+		table.AppendBulk(rows)
+		table.Render()
+		return nil
+	case StylePlain:
+		return writePlain(t.w, header, footer, rows)
+	case StyleMarkdown:
+		return writeMarkdown(t.w, header, footer, rows)
+	case StyleCSV:
+		w := csv.NewWriter(t.w)
+		if len(header) > 0 {
+			if err = w.Write(header); err != nil {
+				return err
+			}
+		}
+		if err = w.WriteAll(rows); err != nil {
+			return err
+		}
+		return w.Error()
+	}
+	return fmt.Errorf("render: unknown table style %q, valid styles are: %q", t.style, strings.Join(Styles, ", "))
+}
+
+// selected gives the indices of the selected columns in the header,
+// or nil for all of them.
+func (t *Table) selected() ([]int, error) {
+	if len(t.columns) == 0 || len(t.header) == 0 {
+		return nil, nil
+	}
+	var ret []int
+	for _, c := range t.columns {
+		found := false
+		for i, h := range t.header {
+			if columnKey(h) == columnKey(c) {
+				ret = append(ret, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("render: unknown column %q, valid columns are: %q", c, strings.Join(t.header, ", "))
+		}
+	}
+	return ret, nil
+}
+
+// columnKey gives the lower-cased letters and digits of column c.
+func columnKey(c string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, c)
+}
+
+// pick gives the cells of r at idx, or r itself if idx is nil.
+// Missing cells are empty.
+func pick(r []string, idx []int) []string {
+	if idx == nil || len(r) == 0 {
+		return r
+	}
+	ret := make([]string, len(idx))
+	for i, j := range idx {
+		if j < len(r) {
+			ret[i] = r[j]
+		}
+	}
+	return ret
+}
+
+// writePlain writes the rows as columns aligned by spaces, one line each.
+func writePlain(w io.Writer, header, footer []string, rows [][]string) error {
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	line := func(r []string) {
+		cells := make([]string, len(r))
+		for i, c := range r {
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(c)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	if len(header) > 0 {
+		line(header)
+	}
+	for _, r := range rows {
+		line(r)
+	}
+	if len(footer) > 0 {
+		line(footer)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	// trim the padding of the empty trailing cells
+	for _, l := range strings.SplitAfter(b.String(), "\n") {
+		if l == "" {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(l, " \n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown writes the rows as a markdown table, with the footer as its
+// last row. A header of empty cells is added if there is none.
+func writeMarkdown(w io.Writer, header, footer []string, rows [][]string) error {
+	if len(header) == 0 {
+		n := 0
+		for _, r := range rows {
+			n = max(n, len(r))
+		}
+		header = make([]string, n)
+	}
+	cell := strings.NewReplacer("|", `\|`, "\n", "<br>")
+	line := func(r []string) error {
+		cells := make([]string, len(r))
+		for i, c := range r {
+			cells[i] = cell.Replace(c)
+		}
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		return err
+	}
+	if err := line(header); err != nil {
+		return err
+	}
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	if err := line(sep); err != nil {
+		return err
+	}
+	if len(footer) > 0 {
+		rows = append(rows, footer)
+	}
+	for _, r := range rows {
+		if err := line(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		style   string
+		columns []string
+		want    string
+	}{
+		{"csv", StyleCSV, nil, "ID,Task State,Name\n5d37e,Done,\"Tower, v2\"\n"},
+		{"csv columns", StyleCSV, []string{"name", "TaskState"}, "Name,Task State\n\"Tower, v2\",Done\n"},
+		{"plain", StylePlain, []string{"ID", "Name"}, "ID          Name\n5d37e       Tower, v2\n2 projects\n"},
+		{"markdown", StyleMarkdown, []string{"Task State"}, "| Task State |\n| --- |\n| Done |\n|  |\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			table := NewTable(&b, tt.style, tt.columns)
+			table.SetHeader([]string{"ID", "Task State", "Name"})
+			table.Append([]string{"5d37e", "Done", "Tower, v2"})
+			table.SetFooter([]string{"2 projects", "", ""})
+			if err := table.Render(); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("Render() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+
+	table := NewTable(&bytes.Buffer{}, StyleCSV, []string{"Owner"})
+	table.SetHeader([]string{"ID"})
+	if err := table.Render(); err == nil {
+		t.Error("Render() of unknown column expects error")
+	}
+	if err := NewTable(&bytes.Buffer{}, "html", nil).Render(); err == nil {
+		t.Error("Render() of unknown style expects error")
+	}
+}
@@ -0,0 +1,98 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Funcs are the functions available in the templates besides the builtins.
+var Funcs = template.FuncMap{
+	"json":  toJSON,
+	"join":  join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"date":  date,
+}
+
+// Template renders the types structs by a Go template, e.g. '{{.ID}} {{.TaskState}}'.
+type Template struct {
+	t       *template.Template
+	newline bool
+}
+
+// New parses the template text. Each rendered item is followed by a newline,
+// unless text already ends with one.
+func New(text string) (*Template, error) {
+	t, err := template.New("output").Funcs(Funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{t: t, newline: !strings.HasSuffix(text, "\n")}, nil
+}
+
+// Render writes each element of items by the template if it is a slice or an
+// array, otherwise items itself. Pointers are dereferenced.
+func (t *Template) Render(w io.Writer, items interface{}) error {
+	v := reflect.ValueOf(items)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return t.render(w, v.Interface())
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := t.render(w, v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Template) render(w io.Writer, item interface{}) error {
+	if err := t.t.Execute(w, item); err != nil {
+		return err
+	}
+	if t.newline {
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+	return nil
+}
+
+// toJSON gives the compact json of v.
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// join joins the elements of the slice a by sep, e.g. '{{join .ImageCloud ","}}'.
+// Elements that are not strings are formatted by their String method, or in json.
+func join(a interface{}, sep string) string {
+	v := reflect.ValueOf(a)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return ""
+	}
+	var ss []string
+	for i := 0; i < v.Len(); i++ {
+		e := v.Index(i).Interface()
+		switch x := e.(type) {
+		case string:
+			ss = append(ss, x)
+		case interface{ String() string }:
+			ss = append(ss, x.String())
+		default:
+			b, _ := json.Marshal(x)
+			ss = append(ss, string(b))
+		}
+	}
+	return strings.Join(ss, sep)
+}
+
+// date formats t by layout, e.g. '{{date "2006-01-02" .Date}}'.
+func date(layout string, t time.Time) string {
+	return t.Format(layout)
+}
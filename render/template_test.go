@@ -0,0 +1,53 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jackytck/alti-cli/types"
+)
+
+func TestTemplate(t *testing.T) {
+	date := time.Date(2022, 6, 1, 8, 0, 0, 0, time.UTC)
+	projs := []types.Project{
+		{ID: "5d37e018", Name: "Tower", TaskState: "Done", Date: date},
+		{ID: "5d37e019", Name: "Bridge", TaskState: "Failed", Date: date},
+	}
+	tests := []struct {
+		name  string
+		text  string
+		items interface{}
+		want  string
+	}{
+		{"slice", "{{.ID}} {{.TaskState}}", projs, "5d37e018 Done\n5d37e019 Failed\n"},
+		{"pointer", "{{.Name}}\n", &projs[0], "Tower\n"},
+		{"funcs", `{{upper .Name}} {{date "2006-01-02" .Date}}`, projs[1], "BRIDGE 2022-06-01\n"},
+		{"join", `{{join . ","}}`, [][]string{{"s3", "oss"}}, "s3,oss\n"},
+		{"json", "{{json .State}}", types.Collaborator{State: "Pending"}, `"Pending"` + "\n"},
+		{"empty", "{{.ID}}", []types.Project{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := New(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b bytes.Buffer
+			if err = tmpl.Render(&b, tt.items); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("Render() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+
+	if _, err := New("{{.ID"); err == nil {
+		t.Error("New() of invalid template expects error")
+	}
+	tmpl, _ := New("{{.Owner}}")
+	if err := tmpl.Render(&bytes.Buffer{}, projs); err == nil {
+		t.Error("Render() of unknown field expects error")
+	}
+}
@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/logging"
+)
+
+// recheck is the longest sleep before checking the window again, so that a
+// suspended machine or a changed clock does not oversleep.
+const recheck = time.Minute
+
+// Window is a daily window of time, e.g. 22:00-06:00, which wraps around
+// midnight if it ends before it starts. The times are of the local time zone.
+type Window struct {
+	Start time.Duration // since midnight
+	End   time.Duration
+
+	mu     sync.Mutex
+	paused bool
+}
+
+// Parse parses the window of 'HH:MM-HH:MM', e.g. '22:00-06:00'.
+func Parse(s string) (*Window, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid schedule %q, want HH:MM-HH:MM", s)
+	}
+	var ds [2]time.Duration
+	for i, p := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(p))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q, want HH:MM-HH:MM", s)
+		}
+		ds[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if ds[0] == ds[1] {
+		return nil, fmt.Errorf("invalid schedule %q, start and end are the same", s)
+	}
+	return &Window{Start: ds[0], End: ds[1]}, nil
+}
+
+// String gives the window as 'HH:MM-HH:MM'.
+func (w *Window) String() string {
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+// Contains tells if t is inside the window.
+func (w *Window) Contains(t time.Time) bool {
+	c := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.Start < w.End {
+		return w.Start <= c && c < w.End
+	}
+	return c >= w.Start || c < w.End
+}
+
+// Next gives t if it is inside the window, otherwise the next start of the
+// window after t.
+func (w *Window) Next(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	h, m := int(w.Start/time.Hour), int(w.Start%time.Hour/time.Minute)
+	s := time.Date(t.Year(), t.Month(), t.Day(), h, m, 0, 0, t.Location())
+	if !s.After(t) {
+		s = time.Date(t.Year(), t.Month(), t.Day()+1, h, m, 0, 0, t.Location())
+	}
+	return s
+}
+
+// Wait blocks until the window is open or ctx is canceled. Pausing and
+// resuming are logged once for all of the waiting workers.
+func (w *Window) Wait(ctx context.Context) error {
+	for {
+		now := time.Now()
+		next := w.Next(now)
+		if !next.After(now) {
+			w.setPaused(false, next)
+			return nil
+		}
+		w.setPaused(true, next)
+		d := next.Sub(now)
+		if d > recheck {
+			d = recheck
+		}
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *Window) setPaused(paused bool, next time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.paused == paused {
+		return
+	}
+	w.paused = paused
+	if paused {
+		logging.Infof("Paused outside the schedule %s, resuming at %s\n", w, next.Format("2006-01-02 15:04"))
+		return
+	}
+	logging.Infof("Resumed inside the schedule %s\n", w)
+}
+
+// clock formats d since midnight as 'HH:MM'.
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
@@ -0,0 +1,59 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"22:00-06:00", "22:00-06:00", false},
+		{"9:30 - 17:00", "09:30-17:00", false},
+		{"22:00", "", true},
+		{"22:00-25:00", "", true},
+		{"06:00-06:00", "", true},
+	}
+	for _, tt := range tests {
+		w, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && w.String() != tt.want {
+			t.Errorf("Parse(%q) = %q, want %q", tt.in, w, tt.want)
+		}
+	}
+}
+
+func TestWindow_Next(t *testing.T) {
+	at := func(d, h, m int) time.Time {
+		return time.Date(2020, 1, d, h, m, 0, 0, time.UTC)
+	}
+	night, _ := Parse("22:00-06:00")
+	day, _ := Parse("09:00-17:00")
+	tests := []struct {
+		name string
+		w    *Window
+		t    time.Time
+		want time.Time
+	}{
+		{"inside before midnight", night, at(1, 23, 0), at(1, 23, 0)},
+		{"inside after midnight", night, at(2, 5, 59), at(2, 5, 59)},
+		{"at the end", night, at(2, 6, 0), at(2, 22, 0)},
+		{"before the start", night, at(1, 12, 0), at(1, 22, 0)},
+		{"inside", day, at(1, 9, 0), at(1, 9, 0)},
+		{"before", day, at(1, 8, 0), at(1, 9, 0)},
+		{"after", day, at(1, 17, 30), at(2, 9, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.w.Next(tt.t); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
+)
+
+// BucketLatencyTTL is how long the measured fastest bucket is reused.
+var BucketLatencyTTL = 24 * time.Hour
+
+// probeSize is the number of bytes of a probe upload.
+const probeSize = 64 * 1024
+
+// probeTimeout is the timeout of a probe upload.
+const probeTimeout = 15 * time.Second
+
+// bucketLatency is the cached fastest bucket of a profile, kind and method.
+type bucketLatency struct {
+	Bucket string        `json:"bucket"`
+	RTT    time.Duration `json:"rtt"`
+	Time   time.Time     `json:"time"`
+}
+
+// BucketURL gives the public url of a bucket of cloud, "" if unknown,
+// e.g. minio buckets are served by private servers.
+func BucketURL(cloud, bucket string) string {
+	switch strings.ToLower(cloud) {
+	case "s3":
+		return fmt.Sprintf("https://%s.s3.amazonaws.com", bucket)
+	case "gcs":
+		return fmt.Sprintf("https://storage.googleapis.com/%s", bucket)
+	}
+	return ""
+}
+
+// ProbeUpload times a small anonymous upload to the bucket of url.
+// Nothing is stored, as it is rejected by the bucket, but any response
+// counts as reachable.
+func ProbeUpload(url string) (time.Duration, error) {
+	client := config.HTTPClient(probeTimeout)
+	req, err := http.NewRequest(http.MethodPut, strings.TrimRight(url, "/")+"/alti-cli-probe", bytes.NewReader(make([]byte, probeSize)))
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	return time.Since(start), nil
+}
+
+// FastestBucket probes each bucket of method for kind concurrently and gives
+// the one of the lowest latency. The result is cached per profile for
+// BucketLatencyTTL.
+func FastestBucket(method, kind string) (string, time.Duration, error) {
+	key := fmt.Sprintf("%s/%s/%s", config.Load().Active, strings.ToLower(kind), strings.ToLower(method))
+	cache := loadBucketLatency()
+	if c, ok := cache[key]; ok && time.Since(c.Time) < BucketLatencyTTL {
+		return c.Bucket, c.RTT, nil
+	}
+
+	buks, err := gql.BucketList(kind, method)
+	if err != nil {
+		return "", 0, err
+	}
+	var best *bucketLatency
+	for b, rtt := range ProbeBuckets(method, buks) {
+		if best == nil || rtt < best.RTT {
+			best = &bucketLatency{Bucket: b, RTT: rtt}
+		}
+	}
+	if best == nil {
+		return "", 0, fmt.Errorf("no %s bucket is measurable", method)
+	}
+
+	best.Time = time.Now()
+	cache[key] = *best
+	if err = saveBucketLatency(cache); err != nil {
+		logging.Warnln("Bucket latency could not be cached:", err)
+	}
+	return best.Bucket, best.RTT, nil
+}
+
+// ProbeBuckets probes each of the buckets of method concurrently and gives
+// the latencies of the reachable ones.
+func ProbeBuckets(method string, buckets []string) map[string]time.Duration {
+	ret := make(map[string]time.Duration)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, b := range buckets {
+		u := BucketURL(method, b)
+		if u == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(b, u string) {
+			defer wg.Done()
+			rtt, err := ProbeUpload(u)
+			if err != nil {
+				logging.Debugf("Probing %q failed: %v\n", b, err)
+				return
+			}
+			logging.Debugf("Probed %q in %s\n", b, rtt.Round(time.Millisecond))
+			mu.Lock()
+			defer mu.Unlock()
+			ret[b] = rtt
+		}(b, u)
+	}
+	wg.Wait()
+	return ret
+}
+
+// bucketLatencyPath gives the path of the cached bucket latencies.
+func bucketLatencyPath() (string, error) {
+	confDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(confDir, "bucket-latency.json"), nil
+}
+
+// loadBucketLatency reads the cached bucket latencies, empty if not cached.
+func loadBucketLatency() map[string]bucketLatency {
+	ret := make(map[string]bucketLatency)
+	p, err := bucketLatencyPath()
+	if err != nil {
+		return ret
+	}
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return ret
+	}
+	json.Unmarshal(b, &ret)
+	return ret
+}
+
+// saveBucketLatency writes the cached bucket latencies.
+func saveBucketLatency(cache map[string]bucketLatency) error {
+	p, err := bucketLatencyPath()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, b, 0644)
+}
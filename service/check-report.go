@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// CheckItem is a named checker function.
+type CheckItem struct {
+	Name string
+	Fn   CheckFn
+}
+
+// CheckResult is the outcome of a CheckItem.
+type CheckResult struct {
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Error    string   `json:"error,omitempty"`
+	Code     int      `json:"code,omitempty"` // exit code of the error
+	Messages []string `json:"messages,omitempty"`
+	err      error
+}
+
+// CheckReport is the outcomes of all of the checks.
+type CheckReport struct {
+	Passed bool          `json:"passed"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// RunChecks runs all of the items, even if some of them failed, and collects
+// the logged messages of each.
+func RunChecks(items ...CheckItem) CheckReport {
+	ret := CheckReport{Passed: true}
+	for _, it := range items {
+		r := CheckResult{Name: it.Name}
+		logger := func(format string, a ...interface{}) {
+			r.Messages = append(r.Messages, strings.TrimSpace(fmt.Sprintf(format, a...)))
+		}
+		if err := it.Fn(logger); err != nil {
+			r.Error = err.Error()
+			r.Code = errors.ExitCode(err)
+			r.err = err
+			ret.Passed = false
+		} else {
+			r.Passed = true
+		}
+		ret.Checks = append(ret.Checks, r)
+	}
+	return ret
+}
+
+// Err gives the error of the first failed check, nil if all passed.
+func (r CheckReport) Err() error {
+	for _, c := range r.Checks {
+		if c.err != nil {
+			return c.err
+		}
+	}
+	return nil
+}
+
+// CheckFns gives the checker functions of items.
+func CheckFns(items []CheckItem) []CheckFn {
+	ret := make([]CheckFn, len(items))
+	for i, it := range items {
+		ret[i] = it.Fn
+	}
+	return ret
+}
@@ -2,16 +2,18 @@ package service
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	humanize "github.com/dustin/go-humanize"
 	"github.com/jackytck/alti-cli/config"
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/file"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 	"github.com/jackytck/alti-cli/text"
 	"github.com/jackytck/alti-cli/web"
 )
@@ -19,7 +21,7 @@ import (
 // CheckFn represents a checker function.
 type CheckFn func(LogFn) error
 
-// LogFn represents a logger function. Same signature as log.Printf.
+// LogFn represents a logger function. Same signature as logging.Infof.
 type LogFn func(string, ...interface{})
 
 // QuietLog is a dummy func for logging nothing.
@@ -29,7 +31,7 @@ func QuietLog(string, ...interface{}) {
 // Check checks all of the passed in checker functions.
 func Check(logger LogFn, cs ...CheckFn) error {
 	if logger == nil {
-		logger = log.Printf
+		logger = logging.Warnf
 	}
 	for _, c := range cs {
 		err := c(logger)
@@ -46,11 +48,12 @@ func CheckAPIServer() CheckFn {
 		mode := gql.ActiveSystemMode()
 		if mode != NormalMode {
 			logger("API server is in %q mode.\n", mode)
-			logger("Nothing could be uploaded at the moment!\n")
 			switch mode {
 			case ReadOnlyMode:
+				logger("Nothing could be changed at the moment! Listing and downloading still work.\n")
 				return errors.ErrReadOnly
 			}
+			logger("Nothing could be uploaded at the moment!\n")
 			return errors.ErrOffline
 		}
 		return nil
@@ -58,10 +61,12 @@ func CheckAPIServer() CheckFn {
 }
 
 // CheckAPIServerLite checks if API server is online, possibly in ReadOnly mode.
+// It is for the read-only commands, e.g. listing and downloading.
 func CheckAPIServerLite() CheckFn {
 	return func(logger LogFn) error {
 		mode := gql.ActiveSystemMode()
 		if mode != NormalMode && mode != ReadOnlyMode {
+			logger("API server is in %q mode.\n", mode)
 			return errors.ErrOffline
 		}
 		return nil
@@ -124,7 +129,7 @@ func CheckUploadMethod(kind, method, ip, port string, skip bool) CheckFn {
 // CheckDirectUpload checks if direct upload is supported.
 func CheckDirectUpload(verbose bool, logger LogFn) error {
 	if logger == nil {
-		logger = log.Printf
+		logger = logging.Warnf
 	}
 	logger("Checking direct upload...")
 	pu, _, err := web.PreferredLocalURL(verbose)
@@ -137,14 +142,14 @@ func CheckDirectUpload(verbose bool, logger LogFn) error {
 }
 
 // CheckDirectUploadIPPort checks if the given ip and port could be accessed by
-// api server.
+// api server, over https if web.DirectTLS is set.
 func CheckDirectUploadIPPort(ip, port string, logger LogFn) error {
 	if logger == nil {
-		logger = log.Printf
+		logger = logging.Warnf
 	}
 	_, err := web.CheckVisibilityIPPort(ip, port, true)
 	if err != nil {
-		url := fmt.Sprintf("http://%s:%s", ip, port)
+		url := fmt.Sprintf("%s://%s:%s", web.DirectScheme(), ip, port)
 		logger("%q is not accessible!", url)
 		return err
 	}
@@ -158,7 +163,7 @@ func CheckPID(kind, pid string) CheckFn {
 	return func(logger LogFn) error {
 		p, err := gql.SearchProjectID(pid, true)
 		if err != nil {
-			logger("Project could not be found! Error:", err)
+			logger("Project could not be found! Error: %v", err)
 			return err
 		}
 		notFound := func() error {
@@ -207,6 +212,52 @@ func CheckBalance(min float64) CheckFn {
 	}
 }
 
+// CheckGPQuota warns if uploading gp GP would exceed the GP quota of the
+// current user. It never fails, as the excess could still be paid by coins.
+func CheckGPQuota(gp float64) CheckFn {
+	return func(logger LogFn) error {
+		q, err := gql.MyQuota()
+		if err != nil {
+			return nil
+		}
+		if gp > q.GPQuota {
+			logger("Uploading %.2f GP would exceed the GP quota: %.2f. See 'alti-cli my membership'.", gp, q.GPQuota)
+		}
+		return nil
+	}
+}
+
+// CheckStorageQuota warns if uploading size bytes would exceed the remaining
+// storage of the current user. It never fails, as the quota is enforced by the api server.
+func CheckStorageQuota(size int64) CheckFn {
+	return func(logger LogFn) error {
+		q, err := gql.MyQuota()
+		if err != nil {
+			return nil
+		}
+		if left := q.StorageLeft(); size > left {
+			logger("Uploading %s would exceed the remaining storage: %s. See 'alti-cli my membership'.", humanize.IBytes(uint64(size)), humanize.IBytes(uint64(left)))
+		}
+		return nil
+	}
+}
+
+// CheckMaxFileSize checks if the file p of size is within the max file size
+// of kind of the api server. kind is "image", "model" or "meta".
+func CheckMaxFileSize(kind, p string, size int64) CheckFn {
+	return func(logger LogFn) error {
+		c, err := gql.Capabilities()
+		if err != nil {
+			return nil
+		}
+		if max := c.MaxFileSize(kind); max > 0 && size > max {
+			logger("%q of %s exceeds the max %s file size of the api server: %s", p, humanize.IBytes(uint64(size)), kind, humanize.IBytes(uint64(max)))
+			return errors.ErrFileTooLarge
+		}
+		return nil
+	}
+}
+
 // CheckFile checks if the file exists.
 func CheckFile(f string) CheckFn {
 	return func(logger LogFn) error {
@@ -319,3 +370,60 @@ func CheckIsLogin() CheckFn {
 		return nil
 	}
 }
+
+// CheckConfig checks if the active profile is found in the config.
+func CheckConfig() CheckFn {
+	return func(logger LogFn) error {
+		c := config.Load()
+		if c.Active == "" {
+			return nil
+		}
+		if p, err := c.GetProfile(c.Active); err != nil || p.ID != c.Active {
+			logger("Active profile %q is not found in the config.", c.Active)
+			return errors.ErrProfileNotFound
+		}
+		return nil
+	}
+}
+
+// CheckDiskSpace checks if the disk of path p has at least min free bytes.
+// If p does not exist yet, the disk of its nearest existing parent is checked.
+func CheckDiskSpace(p string, min uint64) CheckFn {
+	return func(logger LogFn) error {
+		d := p
+		for {
+			if _, err := os.Stat(d); err == nil || filepath.Dir(d) == d {
+				break
+			}
+			d = filepath.Dir(d)
+		}
+		free, err := file.DiskFree(d)
+		if err != nil {
+			logger("Could not get the free disk space of %q: %v", p, err)
+			return err
+		}
+		if free < min {
+			logger("%s free on the disk of %q, %s required", humanize.IBytes(free), p, humanize.IBytes(min))
+			return errors.ErrDiskSpace
+		}
+		logger("%s free on the disk of %q", humanize.IBytes(free), p)
+		return nil
+	}
+}
+
+// CheckClockSkew checks if the local clock differs from the api server clock
+// by at most max.
+func CheckClockSkew(max time.Duration) CheckFn {
+	return func(logger LogFn) error {
+		st, err := gql.ServerTime()
+		if err != nil {
+			return err
+		}
+		skew := time.Since(st).Round(time.Second)
+		logger("Local clock differs from the server clock by %s", skew)
+		if skew > max || -skew > max {
+			return errors.ErrClockSkew
+		}
+		return nil
+	}
+}
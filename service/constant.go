@@ -1,5 +1,7 @@
 package service
 
+import "regexp"
+
 // NormalMode is the literal of normal mode returned from gql.
 const NormalMode = "Normal"
 
@@ -18,6 +20,9 @@ const MinioUploadMethod = "minio"
 // OSSUploadMethod is the literal used in the arags of the import command.
 const OSSUploadMethod = "oss"
 
+// GCSUploadMethod is the literal used in the arags of the import command.
+const GCSUploadMethod = "gcs"
+
 // Pending represents the image or model or meta pending state.
 const Pending = "Pending"
 
@@ -27,8 +32,33 @@ const Ready = "Ready"
 // Failed represents the image or model or meta failed state.
 const Failed = "Failed"
 
+// TaskProcessing represents the processing state of a task.
+const TaskProcessing = "Processing"
+
+// TaskDone represents the done state of a task.
+const TaskDone = "Done"
+
+// TaskStopped represents the stopped state of a task.
+const TaskStopped = "Stopped"
+
 // Yes represents an answer of yes.
 const Yes = "YES"
 
 // ValidMetafileNames specifies the valid metafile names.
 var ValidMetafileNames = []string{"camera.txt", "pose.txt", "group.txt", "initial.xms", "initial.xms.zip"}
+
+// ModelQualities specifies the valid model quality presets of a reconstruction project.
+var ModelQualities = []string{"low", "medium", "high", "ultra"}
+
+// GeoRefModes specifies the valid geo-reference modes of a reconstruction project,
+// i.e. not geo-referenced, by the GPS of images or by ground control points.
+var GeoRefModes = []string{"none", "gps", "gcp"}
+
+// ProjectVisibilities specifies the valid visibilities of a project.
+var ProjectVisibilities = []string{"public", "unlisted", "private"}
+
+// SharePermissions specifies the valid permission levels of sharing a project.
+var SharePermissions = []string{"view", "edit", "admin"}
+
+// ModelFilenameRegex is the rule of the filename of a model file.
+var ModelFilenameRegex = regexp.MustCompile(`^[a-zA-Z0-9\._]*$`)
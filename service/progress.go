@@ -0,0 +1,362 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/jackytck/alti-cli/logging"
+)
+
+// ProgressReporter reports the progress of transferring files.
+// Files are identified by name, usually its local path.
+type ProgressReporter interface {
+	// Start (re)starts the transfer of file name of size bytes.
+	Start(name string, size int64)
+	// Add adds n transferred bytes to file name.
+	Add(name string, n int64)
+	// Done marks file name as finished, failed if err is not nil.
+	Done(name string, err error)
+	// Close stops the reporting and prints the summary.
+	Close()
+}
+
+// StateReporter is optionally implemented by a ProgressReporter to also
+// report the states of the uploaded files checked by the api server.
+type StateReporter interface {
+	// State reports the state of file name, with the error message if failed.
+	State(name, state, errMsg string)
+}
+
+// NewProgressReporter returns a reporter rendering live progress bars if
+// stdout is a terminal, otherwise a reporter of plain logs.
+// count and total are the expected number of files and bytes, 0 if unknown.
+func NewProgressReporter(count int, total int64) ProgressReporter {
+	return newProgressReporter(count, total, os.Stdout)
+}
+
+// newProgressReporter is NewProgressReporter rendering the bars to out.
+func newProgressReporter(count int, total int64, out *os.File) ProgressReporter {
+	stat := newProgressStat(count, total)
+	if IsTerminal(out) {
+		return newBarProgress(stat, out)
+	}
+	return &logProgress{stat: stat}
+}
+
+// IsTerminal tells if f is a character device, i.e. a terminal.
+func IsTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// fileProgress is the progress of a single file.
+type fileProgress struct {
+	name  string
+	size  int64
+	bytes int64
+}
+
+// progressStat accumulates the progress of all files.
+type progressStat struct {
+	mu      sync.Mutex
+	start   time.Time
+	count   int
+	total   int64
+	done    int
+	failed  int
+	bytes   int64
+	active  []*fileProgress
+	nameIdx map[string]*fileProgress
+}
+
+func newProgressStat(count int, total int64) *progressStat {
+	return &progressStat{
+		start:   time.Now(),
+		count:   count,
+		total:   total,
+		nameIdx: make(map[string]*fileProgress),
+	}
+}
+
+func (s *progressStat) begin(name string, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.nameIdx[name]; ok {
+		// restarted, e.g. retry
+		s.bytes -= f.bytes
+		f.bytes = 0
+		f.size = size
+		return
+	}
+	f := &fileProgress{name: name, size: size}
+	s.nameIdx[name] = f
+	s.active = append(s.active, f)
+}
+
+func (s *progressStat) add(name string, n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.nameIdx[name]; ok {
+		f.bytes += n
+		s.bytes += n
+	}
+}
+
+func (s *progressStat) finish(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failed++
+	} else {
+		s.done++
+	}
+	f, ok := s.nameIdx[name]
+	if !ok {
+		return
+	}
+	// count the whole file as processed, even if it is not transferred by us
+	if f.size > f.bytes {
+		s.bytes += f.size - f.bytes
+	}
+	delete(s.nameIdx, name)
+	for i, a := range s.active {
+		if a == f {
+			s.active = append(s.active[:i], s.active[i+1:]...)
+			break
+		}
+	}
+}
+
+// summary gives the aggregate progress in one line.
+func (s *progressStat) summary() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.summaryLocked()
+}
+
+func (s *progressStat) summaryLocked() string {
+	files := fmt.Sprintf("%d", s.done+s.failed)
+	if s.count > 0 {
+		files = fmt.Sprintf("%d/%d", s.done+s.failed, s.count)
+	}
+	size := humanize.IBytes(uint64(s.bytes))
+	if s.total > 0 {
+		size = fmt.Sprintf("%s/%s", size, humanize.IBytes(uint64(s.total)))
+	}
+	ret := fmt.Sprintf("%s files, %s", files, size)
+	if s.failed > 0 {
+		ret += fmt.Sprintf(", %d failed", s.failed)
+	}
+
+	elapsed := time.Since(s.start).Seconds()
+	if elapsed <= 0 || s.bytes <= 0 {
+		return ret
+	}
+	rate := float64(s.bytes) / elapsed
+	ret += fmt.Sprintf(", %s/s", humanize.IBytes(uint64(rate)))
+	if s.total > s.bytes {
+		eta := time.Duration(float64(s.total-s.bytes)/rate) * time.Second
+		ret += fmt.Sprintf(", ETA %s", eta)
+	}
+	return ret
+}
+
+// ratioLocked gives the overall ratio of completion, -1 if unknown.
+func (s *progressStat) ratioLocked() float64 {
+	if s.total > 0 {
+		return float64(s.bytes) / float64(s.total)
+	}
+	if s.count > 0 {
+		return float64(s.done+s.failed) / float64(s.count)
+	}
+	return -1
+}
+
+// logProgress reports the progress by plain logs, at most once per second.
+type logProgress struct {
+	stat    *progressStat
+	mu      sync.Mutex
+	lastLog time.Time
+}
+
+// Start implements ProgressReporter.
+func (lp *logProgress) Start(name string, size int64) {
+	lp.stat.begin(name, size)
+}
+
+// Add implements ProgressReporter.
+func (lp *logProgress) Add(name string, n int64) {
+	lp.stat.add(name, n)
+}
+
+// Done implements ProgressReporter.
+func (lp *logProgress) Done(name string, err error) {
+	lp.stat.finish(name, err)
+	if err != nil {
+		logging.Errorf("%s failed: %v\n", filepath.Base(name), err)
+	}
+
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	if time.Since(lp.lastLog) < time.Second {
+		return
+	}
+	lp.lastLog = time.Now()
+	logging.Infof("Progress: %s\n", lp.stat.summary())
+}
+
+// Close implements ProgressReporter.
+func (lp *logProgress) Close() {
+	logging.Infof("Finished: %s\n", lp.stat.summary())
+}
+
+// maxBars is the maximum number of per-file bars rendered.
+const maxBars = 5
+
+// barWidth is the number of characters of a bar.
+const barWidth = 30
+
+// barProgress renders live progress bars of the active files and the total.
+// Logs are printed above the bars while it is rendering.
+type barProgress struct {
+	stat    *progressStat
+	out     io.Writer
+	logOut  io.Writer
+	mu      sync.Mutex
+	lines   int
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newBarProgress(stat *progressStat, out io.Writer) *barProgress {
+	bp := &barProgress{
+		stat:    stat,
+		out:     out,
+		logOut:  log.Writer(),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	log.SetOutput(logWriter{bp})
+
+	go func() {
+		defer close(bp.stopped)
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				bp.render()
+			case <-bp.stop:
+				return
+			}
+		}
+	}()
+	return bp
+}
+
+// Start implements ProgressReporter.
+func (bp *barProgress) Start(name string, size int64) {
+	bp.stat.begin(name, size)
+}
+
+// Add implements ProgressReporter.
+func (bp *barProgress) Add(name string, n int64) {
+	bp.stat.add(name, n)
+}
+
+// Done implements ProgressReporter.
+func (bp *barProgress) Done(name string, err error) {
+	bp.stat.finish(name, err)
+	if err != nil {
+		logging.Errorf("%s failed: %v\n", filepath.Base(name), err)
+	}
+}
+
+// Close implements ProgressReporter.
+func (bp *barProgress) Close() {
+	close(bp.stop)
+	<-bp.stopped
+	bp.render()
+	log.SetOutput(bp.logOut)
+}
+
+// render redraws the bars in place.
+func (bp *barProgress) render() {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	bp.clear()
+
+	bp.stat.mu.Lock()
+	var lines []string
+	for i, f := range bp.stat.active {
+		if i == maxBars {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(bp.stat.active)-maxBars))
+			break
+		}
+		ratio := -1.0
+		size := humanize.IBytes(uint64(f.bytes))
+		if f.size > 0 {
+			ratio = float64(f.bytes) / float64(f.size)
+			size = fmt.Sprintf("%s/%s", size, humanize.IBytes(uint64(f.size)))
+		}
+		lines = append(lines, fmt.Sprintf("%-24s %s %s", truncate(filepath.Base(f.name), 24), bar(ratio), size))
+	}
+	lines = append(lines, fmt.Sprintf("%-24s %s %s", "Total", bar(bp.stat.ratioLocked()), bp.stat.summaryLocked()))
+	bp.stat.mu.Unlock()
+
+	for _, l := range lines {
+		fmt.Fprintln(bp.out, l)
+	}
+	bp.lines = len(lines)
+}
+
+// clear erases the previously rendered bars.
+func (bp *barProgress) clear() {
+	if bp.lines > 0 {
+		fmt.Fprintf(bp.out, "\033[%dF\033[J", bp.lines)
+		bp.lines = 0
+	}
+}
+
+// logWriter writes the logs above the bars.
+type logWriter struct {
+	bp *barProgress
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	w.bp.mu.Lock()
+	defer w.bp.mu.Unlock()
+	w.bp.clear()
+	return w.bp.logOut.Write(p)
+}
+
+// bar draws a bar of ratio with percentage. Unknown ratio is drawn empty.
+func bar(ratio float64) string {
+	if ratio < 0 {
+		return fmt.Sprintf("[%s]     ", strings.Repeat(" ", barWidth))
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+	n := int(ratio * barWidth)
+	return fmt.Sprintf("[%s%s] %3.0f%%", strings.Repeat("=", n), strings.Repeat(" ", barWidth-n), ratio*100)
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+// progressOp is a call to a progressStat: begin if size is set, finish if
+// done is set, otherwise add of n bytes.
+type progressOp struct {
+	name string
+	size int64
+	n    int64
+	done bool
+	err  error
+}
+
+func TestProgressStat(t *testing.T) {
+	errUpload := errors.New("upload failed")
+	tests := []struct {
+		name       string
+		ops        []progressOp
+		wantDone   int
+		wantFailed int
+		wantBytes  int64
+		wantActive int
+	}{
+		{"transferred", []progressOp{
+			{name: "a", size: 100},
+			{name: "a", n: 60},
+			{name: "a", n: 40},
+			{name: "a", done: true},
+		}, 1, 0, 100, 0},
+		{"in progress", []progressOp{
+			{name: "a", size: 100},
+			{name: "b", size: 50},
+			{name: "a", n: 30},
+		}, 0, 0, 30, 2},
+		{"finished partially transferred", []progressOp{
+			{name: "a", size: 100},
+			{name: "a", n: 30},
+			{name: "a", done: true},
+		}, 1, 0, 100, 0},
+		{"restarted on retry", []progressOp{
+			{name: "a", size: 100},
+			{name: "a", n: 70},
+			{name: "a", size: 100},
+			{name: "a", n: 20},
+		}, 0, 0, 20, 1},
+		{"finished after retry", []progressOp{
+			{name: "a", size: 100},
+			{name: "a", n: 70},
+			{name: "a", size: 100},
+			{name: "a", n: 100},
+			{name: "a", done: true},
+		}, 1, 0, 100, 0},
+		// the images uploaded by a previous run are started and done at once
+		{"resumed", []progressOp{
+			{name: "a", size: 100},
+			{name: "a", done: true},
+			{name: "b", size: 50},
+			{name: "b", n: 10},
+		}, 1, 0, 110, 1},
+		{"failed", []progressOp{
+			{name: "a", size: 100},
+			{name: "a", n: 10},
+			{name: "a", done: true, err: errUpload},
+		}, 0, 1, 100, 0},
+		{"not started", []progressOp{
+			{name: "a", n: 10},
+			{name: "a", done: true},
+		}, 1, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newProgressStat(0, 0)
+			for _, op := range tt.ops {
+				switch {
+				case op.size > 0:
+					s.begin(op.name, op.size)
+				case op.done:
+					s.finish(op.name, op.err)
+				default:
+					s.add(op.name, op.n)
+				}
+			}
+			if s.done != tt.wantDone || s.failed != tt.wantFailed {
+				t.Errorf("done, failed = %d, %d, want %d, %d", s.done, s.failed, tt.wantDone, tt.wantFailed)
+			}
+			if s.bytes != tt.wantBytes {
+				t.Errorf("bytes = %d, want %d", s.bytes, tt.wantBytes)
+			}
+			if len(s.active) != tt.wantActive || len(s.nameIdx) != tt.wantActive {
+				t.Errorf("active = %d, %d, want %d", len(s.active), len(s.nameIdx), tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestProgressSummary(t *testing.T) {
+	tests := []struct {
+		count int
+		total int64
+		want  string
+	}{
+		{0, 0, "2 files, 150 B, 1 failed"},
+		{4, 0, "2/4 files, 150 B, 1 failed"},
+		{4, 300, "2/4 files, 150 B/300 B, 1 failed"},
+	}
+	for _, tt := range tests {
+		s := newProgressStat(tt.count, tt.total)
+		s.begin("a", 100)
+		s.finish("a", nil)
+		s.begin("b", 50)
+		s.finish("b", errors.New("upload failed"))
+		if got := s.summary(); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("summary() of %d files, %d bytes = %q, want prefix %q", tt.count, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestProgressReporterPlainLog(t *testing.T) {
+	f, err := ioutil.TempFile("", "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	var b bytes.Buffer
+	log.SetOutput(&b)
+	defer log.SetOutput(os.Stderr)
+
+	pr := newProgressReporter(2, 0, f)
+	if _, ok := pr.(*logProgress); !ok {
+		t.Fatalf("reporter of a file is %T, want *logProgress", pr)
+	}
+	pr.Start("dir/a.jpg", 10)
+	pr.Done("dir/a.jpg", errors.New("upload failed"))
+	pr.Close()
+
+	for _, want := range []string{"a.jpg failed: upload failed", "Finished: 1/2 files, 10 B, 1 failed"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("logs %q do not contain %q", b.String(), want)
+		}
+	}
+	if fi, _ := f.Stat(); fi.Size() != 0 {
+		t.Errorf("%d bytes are rendered to the file, want none", fi.Size())
+	}
+}
@@ -4,14 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/logging"
 )
 
 // SuggestUploadMethod suggests the best upload method if it is not set.
-// Prefer direct upload over s3 over oss.
+// Prefer direct upload over s3 over minio over gcs over oss.
 // kind is "image" or "model" or "meta".
-// Return suggested method: "direct", "s3", "oss", ""
+// Return suggested method: "direct", "s3", "minio", "gcs", "oss", ""
 // and if it is suggested or not. If this is false, further checking is needed.
 func SuggestUploadMethod(method, kind string) (string, bool) {
 	if method != "" {
@@ -26,7 +28,7 @@ func SuggestUploadMethod(method, kind string) (string, bool) {
 
 	// check s3
 	sups := gql.SupportedCloud("", "", kind)
-	var hasS3, hasMinio, hasOSS bool
+	var hasS3, hasMinio, hasGCS, hasOSS bool
 	for _, s := range sups {
 		if s == "S3" {
 			hasS3 = true
@@ -34,6 +36,9 @@ func SuggestUploadMethod(method, kind string) (string, bool) {
 		if s == "MINIO" {
 			hasMinio = true
 		}
+		if s == "GCS" {
+			hasGCS = true
+		}
 		if s == "OSS" {
 			hasOSS = true
 		}
@@ -47,6 +52,10 @@ func SuggestUploadMethod(method, kind string) (string, bool) {
 		return "minio", true
 	}
 
+	if hasGCS {
+		return "gcs", true
+	}
+
 	if hasOSS {
 		return "oss", true
 	}
@@ -58,10 +67,20 @@ func SuggestUploadMethod(method, kind string) (string, bool) {
 // And check if the bucket is valid if is set.
 // Prefer the geo closest and supported one.
 // kind is "image", "model" or "meta".
-func SuggestBucket(method, bucket, kind string) (string, error) {
+// If auto is set, the unset bucket is the one of the lowest measured latency,
+// falling back to the suggested one if none is measurable.
+func SuggestBucket(method, bucket, kind string, auto bool) (string, error) {
 	if method == DirectUploadMethod {
 		return "", nil
 	}
+	if bucket == "" && auto {
+		b, rtt, err := FastestBucket(method, kind)
+		if err == nil {
+			logging.Infof("Bucket %q is the fastest (%s)\n", b, rtt.Round(time.Millisecond))
+			return b, nil
+		}
+		logging.Warnln("Auto bucket selection failed:", err)
+	}
 	if bucket == "" {
 		b, err := gql.SuggestedBucket(kind, method)
 		if err != nil {
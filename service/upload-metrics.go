@@ -0,0 +1,74 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+)
+
+// UploadMetric is the learned concurrency and throughput of the uploads of a
+// profile and method.
+type UploadMetric struct {
+	Limit int       `json:"limit"`
+	Speed float64   `json:"speed"` // bytes per second
+	Time  time.Time `json:"time"`
+}
+
+// uploadMetricKey gives the key of the metric of method of the active profile.
+func uploadMetricKey(method string) string {
+	return fmt.Sprintf("%s/%s", config.Load().Active, strings.ToLower(method))
+}
+
+// LoadUploadMetric reads the metric of the last adaptive upload by method.
+func LoadUploadMetric(method string) (UploadMetric, bool) {
+	m, ok := loadUploadMetrics()[uploadMetricKey(method)]
+	return m, ok
+}
+
+// SaveUploadMetric writes the metric of an adaptive upload by method.
+func SaveUploadMetric(method string, m UploadMetric) error {
+	metrics := loadUploadMetrics()
+	metrics[uploadMetricKey(method)] = m
+	p, err := uploadMetricsPath()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, b, 0644)
+}
+
+// uploadMetricsPath gives the path of the upload metrics.
+func uploadMetricsPath() (string, error) {
+	confDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(confDir, "upload-metrics.json"), nil
+}
+
+// loadUploadMetrics reads the upload metrics, empty if none.
+func loadUploadMetrics() map[string]UploadMetric {
+	ret := make(map[string]UploadMetric)
+	p, err := uploadMetricsPath()
+	if err != nil {
+		return ret
+	}
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return ret
+	}
+	json.Unmarshal(b, &ret)
+	return ret
+}
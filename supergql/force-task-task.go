@@ -45,7 +45,7 @@ func ForceStartTask(pid, taskType string) (string, string, error) {
 	ctx := context.Background()
 
 	var res forceStartRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", "", err
 	}
 
@@ -17,7 +17,7 @@ func GetUserToken(email string) (string, error) {
 	ctx := context.Background()
 
 	var res getUserTokenRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", err
 	}
 	return res.GetUserToken, nil
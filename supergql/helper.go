@@ -2,15 +2,16 @@ package supergql
 
 import (
 	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/gql"
 	"github.com/machinebox/graphql"
 )
 
 // SuperRequest returns the super gql request and client.
-func SuperRequest(gql string) (*graphql.Request, *graphql.Client) {
+func SuperRequest(q string) (*graphql.Request, *gql.Client) {
 	c := config.Load().GetActive()
-	client := graphql.NewClient(c.Endpoint + "/super")
+	client := gql.NewClient(c.Endpoint + "/super")
 
-	req := graphql.NewRequest(gql)
+	req := graphql.NewRequest(q)
 	req.Header.Set("key", c.Key)
 	req.Header.Set("altitoken", c.Token)
 
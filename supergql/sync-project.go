@@ -26,7 +26,7 @@ func SyncProject(pid string) (string, error) {
 	ctx := context.Background()
 
 	var res syncRes
-	if err := client.Run(ctx, req, &res); err != nil {
+	if err := client.RunMutation(ctx, req, &res); err != nil {
 		return "", err
 	}
 	if res.TriggerCloudSync.Error.Message != "" {
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// BatchManifest describes the projects to import by 'import batch'.
+type BatchManifest struct {
+	Parallel int        `yaml:"parallel" json:"parallel"` // number of jobs run at the same time, 1 if not positive
+	Jobs     []BatchJob `yaml:"jobs" json:"jobs"`
+}
+
+// BatchJob is a project and its sources of a batch manifest.
+// A reconstruction project has images and optionally meta files, while an
+// imported project has a model.
+type BatchJob struct {
+	Name        string `yaml:"name" json:"name"`               // name of the new project
+	PID         string `yaml:"pid" json:"pid"`                 // existing project, a new one is created if empty
+	ProjectType string `yaml:"projectType" json:"projectType"` // free or pro of the new project
+	Visibility  string `yaml:"visibility" json:"visibility"`   // public, unlisted or private of the new project
+	ModelType   string `yaml:"modelType" json:"modelType"`     // CAD, PHOTOGRAMMETRY or PTCLOUD of the new imported project
+	Images      string `yaml:"images" json:"images"`           // directory or zip of images
+	Meta        string `yaml:"meta" json:"meta"`               // meta file or directory of meta files
+	Model       string `yaml:"model" json:"model"`             // model zip or directory of multiparts zip
+	Method      string `yaml:"method" json:"method"`           // upload method
+	Bucket      string `yaml:"bucket" json:"bucket"`           // upload bucket
+	Start       bool   `yaml:"start" json:"start"`             // start the reconstruction after importing
+	TaskType    string `yaml:"taskType" json:"taskType"`       // task type of the reconstruction
+}
+
+// IsModel tells if the job imports a model.
+func (j BatchJob) IsModel() bool {
+	return j.Model != ""
+}
+
+// Title gives the name or pid of the job.
+func (j BatchJob) Title() string {
+	if j.Name != "" {
+		return j.Name
+	}
+	return j.PID
+}
+
+// ParseBatchManifest parses the batch manifest b of file name, in json if
+// the extension is .json, yaml otherwise.
+func ParseBatchManifest(name string, b []byte) (*BatchManifest, error) {
+	var m BatchManifest
+	var err error
+	if strings.ToLower(filepath.Ext(name)) == ".json" {
+		err = json.Unmarshal(b, &m)
+	} else {
+		err = yaml.UnmarshalStrict(b, &m)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = m.Validate(); err != nil {
+		return nil, err
+	}
+	if m.Parallel <= 0 {
+		m.Parallel = 1
+	}
+	return &m, nil
+}
+
+// Validate checks if each job has a project and the sources of its kind.
+func (m *BatchManifest) Validate() error {
+	if len(m.Jobs) == 0 {
+		return fmt.Errorf("no job")
+	}
+	for i, j := range m.Jobs {
+		var err error
+		switch {
+		case j.Name == "" && j.PID == "":
+			err = fmt.Errorf("either name or pid is required")
+		case j.Images == "" && j.Meta == "" && j.Model == "":
+			err = fmt.Errorf("nothing to import, one of images, meta or model is required")
+		case j.IsModel() && (j.Images != "" || j.Meta != ""):
+			err = fmt.Errorf("model could not be imported along images or meta")
+		case j.IsModel() && j.Start:
+			err = fmt.Errorf("imported project could not be started")
+		}
+		if err != nil {
+			return fmt.Errorf("job %d %q: %v", i+1, j.Title(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package types
+
+import "testing"
+
+func TestParseBatchManifest(t *testing.T) {
+	tests := []struct {
+		name         string
+		file         string
+		data         string
+		wantJobs     int
+		wantParallel int
+		wantErr      bool
+	}{
+		{"yaml", "jobs.yaml", "parallel: 2\njobs:\n  - name: a\n    images: /a\n    start: true\n  - pid: 5d37e\n    model: /b.zip\n", 2, 2, false},
+		{"json", "jobs.json", `{"jobs":[{"name":"a","images":"/a","meta":"/a"}]}`, 1, 1, false},
+		{"no job", "jobs.yaml", "parallel: 2\n", 0, 0, true},
+		{"unknown field", "jobs.yaml", "jobs:\n  - name: a\n    image: /a\n", 0, 0, true},
+		{"no project", "jobs.yaml", "jobs:\n  - images: /a\n", 0, 0, true},
+		{"no source", "jobs.yaml", "jobs:\n  - name: a\n", 0, 0, true},
+		{"model with images", "jobs.yaml", "jobs:\n  - name: a\n    images: /a\n    model: /b.zip\n", 0, 0, true},
+		{"start model", "jobs.yaml", "jobs:\n  - name: a\n    model: /b.zip\n    start: true\n", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBatchManifest(tt.file, []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBatchManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Jobs) != tt.wantJobs || got.Parallel != tt.wantParallel {
+				t.Errorf("ParseBatchManifest() = %d jobs in %d, want %d jobs in %d", len(got.Jobs), got.Parallel, tt.wantJobs, tt.wantParallel)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// Optional features of the api server.
+const (
+	FeatureChunkedModel     = "chunkedModelUpload" // pulling a model by its chunk manifest
+	FeatureSubscription     = "subscription"       // gql subscription over websocket
+	FeatureNetworkTest      = "networkTest"        // reaching the client for direct upload
+	FeatureDownloadChecksum = "downloadChecksum"   // checksums of the downloads
+	FeatureMultipartUpload  = "multipartUpload"    // multipart upload sessions of s3 and minio
+)
+
+// Capabilities are what an api server supports.
+type Capabilities struct {
+	Endpoint string `json:"endpoint"`
+	Version  string `json:"version"`
+	// Clouds are the supported clouds of each kind of upload, in upper case,
+	// e.g. "S3" or "GCS".
+	Clouds map[string][]string `json:"clouds"`
+	// MaxFileSizes are the max bytes of a file of each kind, zero if unlimited.
+	MaxFileSizes map[string]int64 `json:"maxFileSizes"`
+	// Features are the supported optional features, nil if the api server
+	// predates the capabilities query, i.e. all of them are assumed.
+	Features []string  `json:"features"`
+	Time     time.Time `json:"time"`
+}
+
+// Has tells if the optional feature is supported.
+func (c *Capabilities) Has(feature string) bool {
+	if c.Features == nil {
+		return true
+	}
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxFileSize gives the max bytes of a file of kind, zero if unlimited.
+func (c *Capabilities) MaxFileSize(kind string) int64 {
+	return c.MaxFileSizes[strings.ToLower(kind)]
+}
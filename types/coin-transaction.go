@@ -0,0 +1,13 @@
+package types
+
+import "time"
+
+// CoinTransaction represents the gql coin transaction type.
+type CoinTransaction struct {
+	ID          string
+	Date        time.Time
+	Type        string  // e.g. TOPUP, TRANSFER, RECONSTRUCTION, MEMBERSHIP
+	Amount      float64 // positive if received, negative if spent
+	Balance     float64 // balance after the transaction
+	Description string
+}
@@ -0,0 +1,28 @@
+package types
+
+import "time"
+
+// Collaborator represents a user, or an invited email, sharing a project.
+type Collaborator struct {
+	Email      string
+	Username   string
+	Permission string // VIEW, EDIT or ADMIN
+	State      string // Pending or Accepted
+	Date       time.Time
+}
+
+// CollaboratorHeaderString gives a row of string for the table header.
+func CollaboratorHeaderString() []string {
+	return []string{"Email", "Username", "Permission", "State", "Since"}
+}
+
+// RowString gives a row of string for the table output.
+func (c Collaborator) RowString() []string {
+	return []string{
+		c.Email,
+		c.Username,
+		c.Permission,
+		c.State,
+		c.Date.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,10 @@
+package types
+
+// DeviceCode represents the gql device code type of the sso login.
+type DeviceCode struct {
+	DeviceCode      string
+	UserCode        string
+	VerificationURI string
+	ExpiresIn       int // seconds
+	Interval        int // seconds
+}
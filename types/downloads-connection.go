@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"path"
+	"strings"
+	"time"
+)
 
 // DownloadsConnection represents the gql 'DownloadsConnection' type.
 type DownloadsConnection struct {
@@ -18,3 +22,49 @@ type Downloadable struct {
 	Mtime time.Time
 	Link  string
 }
+
+// Artifact types of downloadables.
+const (
+	ArtifactModel       = "model"
+	ArtifactOrtho       = "ortho"
+	ArtifactDSM         = "dsm"
+	ArtifactPointCloud  = "pointcloud"
+	ArtifactCalibration = "calibration"
+)
+
+// ArtifactTypes are all the artifact types of downloadables.
+var ArtifactTypes = []string{ArtifactModel, ArtifactOrtho, ArtifactDSM, ArtifactPointCloud, ArtifactCalibration}
+
+// Format gives the file format of d by the extension of its name, after
+// stripping the zip archive suffix, e.g. 'obj' of 'xxx_model.obj.zip'.
+func (d Downloadable) Format() string {
+	name := strings.ToLower(d.Name)
+	return strings.TrimPrefix(path.Ext(strings.TrimSuffix(name, ".zip")), ".")
+}
+
+// ArtifactType guesses the artifact type of d by its name, e.g.
+// 'xxx_orthophoto.tif' is an ortho and 'xxx_dense.laz' is a point cloud.
+// Anything else is a model.
+func (d Downloadable) ArtifactType() string {
+	name := strings.ToLower(d.Name)
+	ext := d.Format()
+	has := func(subs ...string) bool {
+		for _, s := range subs {
+			if strings.Contains(name, s) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("ortho"):
+		return ArtifactOrtho
+	case has("dsm", "dem", "elevation"):
+		return ArtifactDSM
+	case has("calib", "camera"):
+		return ArtifactCalibration
+	case has("pointcloud", "point_cloud", "point-cloud", "dense", "pcd") || ext == "laz" || ext == "e57" || ext == "pts":
+		return ArtifactPointCloud
+	}
+	return ArtifactModel
+}
@@ -0,0 +1,46 @@
+package types
+
+import "testing"
+
+func TestArtifactType(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"5d37e018_model.obj.zip", ArtifactModel},
+		{"5d37e018.las", ArtifactModel},
+		{"5d37e018_orthophoto.tif", ArtifactOrtho},
+		{"Ortho.zip", ArtifactOrtho},
+		{"5d37e018_DSM.tif", ArtifactDSM},
+		{"5d37e018_dense.ply", ArtifactPointCloud},
+		{"5d37e018.laz.zip", ArtifactPointCloud},
+		{"5d37e018_point_cloud.las", ArtifactPointCloud},
+		{"cameras.xml", ArtifactCalibration},
+		{"calibration.txt", ArtifactCalibration},
+	}
+	for _, c := range cases {
+		if got := (Downloadable{Name: c.name}).ArtifactType(); got != c.want {
+			t.Errorf("ArtifactType(%q) = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDownloadableFormat(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"5d37e018_model.obj.zip", "obj"},
+		{"5d37e018.LAS", "las"},
+		{"5d37e018.laz.zip", "laz"},
+		{"5d37e018_model.fbx", "fbx"},
+		{"model.objects.ply", "ply"},
+		{"texture.zip", ""},
+		{"README", ""},
+	}
+	for _, c := range cases {
+		if got := (Downloadable{Name: c.name}).Format(); got != c.want {
+			t.Errorf("Format(%q) = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
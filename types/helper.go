@@ -8,6 +8,10 @@ func ConvertToImageType(filetype string) string {
 		ret = "PNG"
 	case "image/tiff":
 		ret = "TIFF"
+	case "image/webp":
+		ret = "WEBP"
+	case "image/heic", "image/heif", "image/heic-sequence", "image/heif-sequence":
+		ret = "HEIC"
 	case "image/jpge":
 	default:
 		ret = "JPEG"
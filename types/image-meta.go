@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// ImageMeta is the metadata of an image sent along its registration,
+// i.e. the gql ImageMetaInput, for geo-referencing by the api server.
+type ImageMeta struct {
+	GPS         *GPS       `json:"gps,omitempty"`
+	Orientation int        `json:"orientation,omitempty"` // exif orientation, 1-8
+	CaptureTime *time.Time `json:"captureTime,omitempty"`
+}
+
+// GPS is the location of an image, with altitude in meters.
+type GPS struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+	Alt float64 `json:"alt"`
+}
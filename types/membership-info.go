@@ -20,6 +20,7 @@ type MembershipInfo struct {
 	ModelPerProject   int                  `json:"modelPerProject"`
 	CollaboratorQuota int                  `json:"collaboratorQuota"`
 	ForceWatermark    bool                 `json:"forceWaterMark"`
+	ConcurrentTask    int                  `json:"concurrentTask"`
 }
 
 // MembershipPlanCoupon represents the gql MEMBERSHIP_PLAN_COUPON type.
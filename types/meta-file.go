@@ -11,5 +11,6 @@ type MetaFile struct {
 	Filesize float64
 	Date     time.Time
 	Checksum string
+	URL      string
 	Error    []string
 }
@@ -0,0 +1,8 @@
+package types
+
+// UploadedPart is a part of a multipart upload stored by the cloud.
+type UploadedPart struct {
+	PartNumber int
+	ETag       string
+	Size       int64
+}
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProjectFilterFields are the fields of project that could be filtered.
+var ProjectFilterFields = []string{"taskState", "name", "projectType", "isImported", "numImage", "gigaPixel", "date"}
+
+// filterCondRe matches a condition of field, operator and value.
+var filterCondRe = regexp.MustCompile(`^\s*(\w+)\s*(!=|<=|>=|=|<|>)\s*(.*?)\s*$`)
+
+// filterAndRe splits the conditions joined by 'and'.
+var filterAndRe = regexp.MustCompile(`(?i)\s+and\s+`)
+
+// ProjectFilter is a conjunction of conditions on the fields of a project,
+// e.g. 'taskState=Failed and date<2023-01-01'.
+type ProjectFilter []projectCond
+
+type projectCond struct {
+	field string
+	op    string
+	value string
+}
+
+// ParseProjectFilter parses the filter expression s. Fields are case
+// insensitive, operators are =, !=, <, <=, > and >=. Dates are in the form of
+// 2006-01-02 or RFC3339.
+func ParseProjectFilter(s string) (ProjectFilter, error) {
+	var ret ProjectFilter
+	for _, c := range filterAndRe.Split(strings.TrimSpace(s), -1) {
+		m := filterCondRe.FindStringSubmatch(c)
+		if m == nil {
+			return nil, fmt.Errorf("invalid condition: %q", c)
+		}
+		field := ""
+		for _, f := range ProjectFilterFields {
+			if strings.EqualFold(f, m[1]) {
+				field = f
+			}
+		}
+		if field == "" {
+			return nil, fmt.Errorf("unknown field: %q, valid fields are: %q", m[1], strings.Join(ProjectFilterFields, ", "))
+		}
+		cond := projectCond{field, m[2], strings.Trim(m[3], `"'`)}
+		// validate the value by comparing with an empty project
+		if _, err := cond.match(Project{}); err != nil {
+			return nil, err
+		}
+		ret = append(ret, cond)
+	}
+	return ret, nil
+}
+
+// Match tells if project p satisfies all the conditions.
+func (f ProjectFilter) Match(p Project) bool {
+	for _, c := range f {
+		if ok, err := c.match(p); !ok || err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (c projectCond) match(p Project) (bool, error) {
+	switch c.field {
+	case "taskState":
+		return compareStrings(strings.ToLower(p.TaskState), c.op, strings.ToLower(c.value)), nil
+	case "name":
+		return compareStrings(p.Name, c.op, c.value), nil
+	case "projectType":
+		return compareStrings(strings.ToLower(p.ProjectType), c.op, strings.ToLower(c.value)), nil
+	case "isImported":
+		v, err := strconv.ParseBool(c.value)
+		if err != nil || (c.op != "=" && c.op != "!=") {
+			return false, fmt.Errorf("invalid condition of isImported: %s%s", c.op, c.value)
+		}
+		return (p.IsImported == v) == (c.op == "="), nil
+	case "numImage", "gigaPixel":
+		v, err := strconv.ParseFloat(c.value, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid number of %s: %q", c.field, c.value)
+		}
+		n := p.GigaPixel
+		if c.field == "numImage" {
+			n = float64(p.NumImage)
+		}
+		return compareFloats(n, c.op, v), nil
+	case "date":
+		t, err := parseFilterDate(c.value)
+		if err != nil {
+			return false, fmt.Errorf("invalid date: %q", c.value)
+		}
+		return compareFloats(float64(p.Date.Sub(t)), c.op, 0), nil
+	}
+	return false, fmt.Errorf("unknown field: %q", c.field)
+}
+
+func parseFilterDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+func compareStrings(a, op, b string) bool {
+	return compareFloats(float64(strings.Compare(a, b)), op, 0)
+}
+
+func compareFloats(a float64, op string, b float64) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProjectFilter(t *testing.T) {
+	p := Project{
+		Name:       "Tower",
+		TaskState:  "Failed",
+		NumImage:   120,
+		GigaPixel:  2.5,
+		IsImported: false,
+		Date:       time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	cases := []struct {
+		filter string
+		want   bool
+	}{
+		{"taskState=Failed", true},
+		{"taskstate = failed and date<2023-01-01", true},
+		{"taskState=Failed AND date>=2023-01-01", false},
+		{"taskState!=Done", true},
+		{"numImage>100 and gigaPixel<=2.5", true},
+		{"numImage<100", false},
+		{"isImported=false", true},
+		{`name="Tower"`, true},
+		{"date>2022-05-31T00:00:00Z", true},
+	}
+	for _, c := range cases {
+		f, err := ParseProjectFilter(c.filter)
+		if err != nil {
+			t.Errorf("ParseProjectFilter(%q) gives error: %v", c.filter, err)
+			continue
+		}
+		if got := f.Match(p); got != c.want {
+			t.Errorf("%q: got %v, want %v", c.filter, got, c.want)
+		}
+	}
+
+	invalid := []string{"", "owner=me", "numImage>many", "date<yesterday", "isImported<true", "taskState"}
+	for _, s := range invalid {
+		if _, err := ParseProjectFilter(s); err == nil {
+			t.Errorf("ParseProjectFilter(%q) expects error", s)
+		}
+	}
+}
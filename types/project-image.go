@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // ProjectImage represents the gql ProjectImage type.
 type ProjectImage struct {
 	ID       string
@@ -9,3 +11,11 @@ type ProjectImage struct {
 	Name     string
 	Filename string
 }
+
+// ImageDetail is a ProjectImage with its upload date, giga-pixel and errors.
+type ImageDetail struct {
+	ProjectImage
+	Date      time.Time
+	GigaPixel float64
+	Error     []string
+}
@@ -0,0 +1,19 @@
+package types
+
+// ProjectInfo is the editable info of a project, i.e. the gql
+// 'ProjectInfoInput' type. Nil fields are left unchanged.
+type ProjectInfo struct {
+	Name        *string   `json:"name,omitempty"`
+	Visibility  *string   `json:"visibility,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+}
+
+// ProjectInfoResult is the info of a project after updating.
+type ProjectInfoResult struct {
+	ID          string
+	Name        string
+	Visibility  string
+	Description string
+	Tags        []string
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Scopes of listing projects.
+const (
+	ProjectScopeMine   = "MINE"
+	ProjectScopeShared = "SHARED"
+)
+
+// ProjectSorts maps the sort keys of listing projects to the gql sort fields.
+var ProjectSorts = map[string]string{
+	"date":     "date",
+	"gp":       "gigaPixel",
+	"numImage": "numImage",
+}
+
+// searchTermRe matches a search term of field, operator and value.
+var searchTermRe = regexp.MustCompile(`^\s*(\w+)\s*(~|=)\s*(.*?)\s*$`)
+
+// ProjectQuery is the server-side search, sort, paging and scope of listing
+// projects. Empty fields are left to the server defaults.
+type ProjectQuery struct {
+	Search      string // part of the name
+	TaskState   string
+	ProjectType string
+	SortBy      string // gql sort field
+	Desc        bool
+	First       int
+	After       string
+	Scope       string // ProjectScopeMine or ProjectScopeShared
+}
+
+// ParseSearch parses the search terms, each of 'name~foo' for the names
+// containing foo, 'state=Done', 'type=pro' or a bare part of the name.
+func (q *ProjectQuery) ParseSearch(terms []string) error {
+	for _, t := range terms {
+		m := searchTermRe.FindStringSubmatch(t)
+		if m == nil {
+			q.Search = strings.TrimSpace(t)
+			continue
+		}
+		field, op, value := strings.ToLower(m[1]), m[2], strings.Trim(m[3], `"'`)
+		switch {
+		case field == "name" && op == "~":
+			q.Search = value
+		case (field == "state" || field == "taskstate") && op == "=":
+			q.TaskState = value
+		case (field == "type" || field == "projecttype") && op == "=":
+			q.ProjectType = strings.ToLower(value)
+		default:
+			return fmt.Errorf("invalid search term: %q, valid terms are: 'name~foo', 'state=Done' and 'type=pro'", t)
+		}
+	}
+	return nil
+}
+
+// SetSort sets the sort of key, descending if it is prefixed by '-'.
+func (q *ProjectQuery) SetSort(key string) error {
+	desc := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+	for k, f := range ProjectSorts {
+		if strings.EqualFold(k, key) {
+			q.SortBy = f
+			q.Desc = desc
+			return nil
+		}
+	}
+	var keys []string
+	for k := range ProjectSorts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("unknown sort: %q, valid sorts are: %q", key, strings.Join(keys, ", "))
+}
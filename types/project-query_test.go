@@ -0,0 +1,62 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestProjectQueryParseSearch(t *testing.T) {
+	cases := []struct {
+		terms []string
+		want  ProjectQuery
+	}{
+		{[]string{"name~tower"}, ProjectQuery{Search: "tower"}},
+		{[]string{"tower"}, ProjectQuery{Search: "tower"}},
+		{[]string{`name ~ "big tower"`}, ProjectQuery{Search: "big tower"}},
+		{[]string{"name~tower", "state=Done", "type=Pro"}, ProjectQuery{Search: "tower", TaskState: "Done", ProjectType: "pro"}},
+		{[]string{"taskState=Failed"}, ProjectQuery{TaskState: "Failed"}},
+	}
+	for _, c := range cases {
+		var q ProjectQuery
+		if err := q.ParseSearch(c.terms); err != nil {
+			t.Errorf("ParseSearch(%q) gives error: %v", c.terms, err)
+			continue
+		}
+		if q != c.want {
+			t.Errorf("ParseSearch(%q) = %+v, want %+v", c.terms, q, c.want)
+		}
+	}
+
+	invalid := []string{"name=tower", "owner~me", "state~Done"}
+	for _, s := range invalid {
+		var q ProjectQuery
+		if err := q.ParseSearch([]string{s}); err == nil {
+			t.Errorf("ParseSearch(%q) expects error", s)
+		}
+	}
+}
+
+func TestProjectQuerySetSort(t *testing.T) {
+	cases := []struct {
+		key      string
+		wantBy   string
+		wantDesc bool
+		wantErr  bool
+	}{
+		{"date", "date", false, false},
+		{"-date", "date", true, false},
+		{"gp", "gigaPixel", false, false},
+		{"-numimage", "numImage", true, false},
+		{"size", "", false, true},
+	}
+	for _, c := range cases {
+		var q ProjectQuery
+		err := q.SetSort(c.key)
+		if (err != nil) != c.wantErr {
+			t.Errorf("SetSort(%q) error = %v, wantErr %v", c.key, err, c.wantErr)
+			continue
+		}
+		if q.SortBy != c.wantBy || q.Desc != c.wantDesc {
+			t.Errorf("SetSort(%q) = %q %v, want %q %v", c.key, q.SortBy, q.Desc, c.wantBy, c.wantDesc)
+		}
+	}
+}
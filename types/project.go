@@ -2,11 +2,8 @@ package types
 
 import (
 	"fmt"
-	"io"
 	"strings"
 	"time"
-
-	"github.com/olekukonko/tablewriter"
 )
 
 // Project represents the gql project type.
@@ -69,13 +66,3 @@ func (p Project) RowString(webDomain string) []string {
 		fmt.Sprintf("%s/project-model?pid=%v", webDomain, p.ID),
 	}
 }
-
-// ProjectsToTable transforms slice of projects into a table.
-func ProjectsToTable(ps []Project, webDomain string, w io.Writer) *tablewriter.Table {
-	table := tablewriter.NewWriter(w)
-	table.SetHeader(ProjectHeaderString())
-	for _, p := range ps {
-		table.Append(p.RowString(webDomain))
-	}
-	return table
-}
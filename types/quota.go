@@ -0,0 +1,59 @@
+package types
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Quota represents the plan, usage and limits of the current user.
+type Quota struct {
+	State          string // NA, ACTIVE, SUSPENDED, TRIAL, EXPIRED, STOPPED
+	Plan           string
+	GPQuota        float64 // member plus free GP quota
+	Storage        float64 // in MB
+	StorageUsage   float64 // in MB
+	ConcurrentTask int     // max number of running tasks, 0 if unlimited
+	Expiry         time.Time
+}
+
+// NewQuota gives the quota of user u.
+func NewQuota(u *User) *Quota {
+	m := u.Membership
+	return &Quota{
+		State:          u.MembershipState,
+		Plan:           m.PlanName,
+		GPQuota:        m.MemberGPQuota + u.FreeGPQuota,
+		Storage:        m.AssetStorage,
+		StorageUsage:   u.ModelUsage,
+		ConcurrentTask: m.ConcurrentTask,
+		Expiry:         m.EndDate,
+	}
+}
+
+// StorageLeft gives the remaining storage in bytes, never negative.
+func (q Quota) StorageLeft() int64 {
+	return int64(math.Max(q.Storage-q.StorageUsage, 0) * 1048576)
+}
+
+// DaysLeft gives the number of days before the membership expires at now,
+// negative if expired.
+func (q Quota) DaysLeft(now time.Time) int {
+	return int(math.Floor(q.Expiry.Sub(now).Hours() / 24))
+}
+
+// TasksString gives the max number of running tasks, Unlimited if 0.
+func (q Quota) TasksString() string {
+	if q.ConcurrentTask > 0 {
+		return strconv.Itoa(q.ConcurrentTask)
+	}
+	return "Unlimited"
+}
+
+// DaysLeftString gives the days left at now, '-' if there is no expiry.
+func (q Quota) DaysLeftString(now time.Time) string {
+	if q.Expiry.IsZero() {
+		return "-"
+	}
+	return strconv.Itoa(q.DaysLeft(now))
+}
@@ -13,3 +13,37 @@ type Task struct {
 	Step       int
 	Queueing   int
 }
+
+// ProjectTask is the current task of a project.
+type ProjectTask struct {
+	PID  string
+	Name string
+	Task Task
+}
+
+// Ended tells if the task has ended, i.e. Done, Failed or Stopped.
+func (t Task) Ended() bool {
+	switch t.State {
+	case "Done", "Failed", "Stopped":
+		return true
+	}
+	return false
+}
+
+// Progress gives the fraction of the finished steps, zero if unknown.
+func (t Task) Progress() float64 {
+	if t.TotalSteps <= 0 {
+		return 0
+	}
+	return float64(t.Step) / float64(t.TotalSteps)
+}
+
+// ETA estimates the remaining time of the task at now by the average time of
+// its finished steps, zero if unknown, e.g. still queueing.
+func (t Task) ETA(now time.Time) time.Duration {
+	if t.Step <= 0 || t.Step >= t.TotalSteps || t.StartDate.IsZero() {
+		return 0
+	}
+	elapsed := now.Sub(t.StartDate)
+	return time.Duration(float64(elapsed) / float64(t.Step) * float64(t.TotalSteps-t.Step))
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTask_ETA(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(30 * time.Minute)
+	tests := []struct {
+		name string
+		task Task
+		want time.Duration
+	}{
+		{"halfway", Task{StartDate: start, Step: 2, TotalSteps: 4}, 30 * time.Minute},
+		{"one of four", Task{StartDate: start, Step: 1, TotalSteps: 4}, 90 * time.Minute},
+		{"not started", Task{StartDate: start, Step: 0, TotalSteps: 4}, 0},
+		{"finished", Task{StartDate: start, Step: 4, TotalSteps: 4}, 0},
+		{"no steps", Task{StartDate: start}, 0},
+		{"no start date", Task{Step: 1, TotalSteps: 4}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.ETA(now); got != tt.want {
+				t.Errorf("ETA() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_Ended(t *testing.T) {
+	tests := []struct {
+		state string
+		want  bool
+	}{
+		{"Done", true},
+		{"Failed", true},
+		{"Stopped", true},
+		{"Processing", false},
+		{"Pending", false},
+	}
+	for _, tt := range tests {
+		if got := (Task{State: tt.state}).Ended(); got != tt.want {
+			t.Errorf("Ended() of %q = %v, want %v", tt.state, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/jackytck/alti-cli/types"
+	tb "github.com/nsf/termbox-go"
+)
+
+// maxErrors is the number of the latest errors kept.
+const maxErrors = 100
+
+// maxStates is the number of the latest checked states kept.
+const maxStates = 20
+
+// fileProgress is the progress of a file being uploaded.
+type fileProgress struct {
+	name  string
+	size  int64
+	bytes int64
+}
+
+// Dashboard is a terminal UI of the projects, the upload workers with the
+// progress of each file, the image states checked by the api server and the errors.
+// It implements service.ProgressReporter and service.StateReporter, and
+// could be set as the output of the standard logger.
+type Dashboard struct {
+	mu       sync.Mutex
+	title    string
+	projects []types.Project
+	selected int
+
+	// upload session
+	uploading bool
+	start     time.Time
+	count     int
+	total     int64
+	done      int
+	failed    int
+	bytes     int64
+	active    []*fileProgress
+	states    map[string]int
+	recent    []string
+	errors    []string
+	lastLog   string
+
+	events  chan tb.Event
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// New initializes the terminal and starts rendering the dashboard of title,
+// until Close is called.
+func New(title string) (*Dashboard, error) {
+	if err := tb.Init(); err != nil {
+		return nil, err
+	}
+	d := &Dashboard{
+		title:   title,
+		states:  make(map[string]int),
+		events:  make(chan tb.Event),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go func() {
+		for {
+			ev := tb.PollEvent()
+			if ev.Type == tb.EventInterrupt {
+				close(d.events)
+				return
+			}
+			d.events <- ev
+		}
+	}()
+	go func() {
+		defer close(d.stopped)
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.render()
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+	return d, nil
+}
+
+// Events gives the key and resize events of the terminal.
+func (d *Dashboard) Events() <-chan tb.Event {
+	return d.events
+}
+
+// Quit stops rendering and restores the terminal.
+func (d *Dashboard) Quit() {
+	close(d.stop)
+	<-d.stopped
+	tb.Interrupt()
+	for range d.events {
+	}
+	tb.Close()
+}
+
+// SetProjects sets the projects listed, keeping the selection if possible.
+func (d *Dashboard) SetProjects(ps []types.Project) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.projects = ps
+	if d.selected >= len(ps) {
+		d.selected = len(ps) - 1
+	}
+	if d.selected < 0 {
+		d.selected = 0
+	}
+}
+
+// Move moves the selection of projects by delta.
+func (d *Dashboard) Move(delta int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.selected += delta
+	if d.selected >= len(d.projects) {
+		d.selected = len(d.projects) - 1
+	}
+	if d.selected < 0 {
+		d.selected = 0
+	}
+}
+
+// Selected gives the selected project, nil if there is none.
+func (d *Dashboard) Selected() *types.Project {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.selected >= len(d.projects) {
+		return nil
+	}
+	p := d.projects[d.selected]
+	return &p
+}
+
+// Uploading tells if an upload session is in progress.
+func (d *Dashboard) Uploading() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.uploading
+}
+
+// Begin begins a new upload session of count files of total bytes,
+// 0 if unknown. The errors of the previous sessions are kept.
+func (d *Dashboard) Begin(count int, total int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.uploading = true
+	d.start = time.Now()
+	d.count = count
+	d.total = total
+	d.done = 0
+	d.failed = 0
+	d.bytes = 0
+	d.active = nil
+	d.states = make(map[string]int)
+	d.recent = nil
+}
+
+// Start implements service.ProgressReporter.
+func (d *Dashboard) Start(name string, size int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if f := d.find(name); f != nil {
+		// restarted, e.g. retry
+		d.bytes -= f.bytes
+		f.bytes = 0
+		f.size = size
+		return
+	}
+	d.active = append(d.active, &fileProgress{name: name, size: size})
+}
+
+// Add implements service.ProgressReporter.
+func (d *Dashboard) Add(name string, n int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if f := d.find(name); f != nil {
+		f.bytes += n
+		d.bytes += n
+	}
+}
+
+// Done implements service.ProgressReporter.
+func (d *Dashboard) Done(name string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		d.failed++
+		d.addError(fmt.Sprintf("%s: %v", filepath.Base(name), err))
+	} else {
+		d.done++
+	}
+	for i, f := range d.active {
+		if f.name == name {
+			if f.size > f.bytes {
+				d.bytes += f.size - f.bytes
+			}
+			d.active = append(d.active[:i], d.active[i+1:]...)
+			break
+		}
+	}
+}
+
+// Close implements service.ProgressReporter. The transfers are finished,
+// the states may still be reported until End is called.
+func (d *Dashboard) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active = nil
+}
+
+// End ends the upload session.
+func (d *Dashboard) End() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.uploading = false
+}
+
+// State implements service.StateReporter.
+func (d *Dashboard) State(name, state, errMsg string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if state == "" {
+		state = "Unknown"
+	}
+	d.states[state]++
+	line := fmt.Sprintf("%s: %s", filepath.Base(name), state)
+	if errMsg != "" {
+		line += " (" + errMsg + ")"
+		d.addError(fmt.Sprintf("%s: %s", filepath.Base(name), errMsg))
+	}
+	d.recent = append(d.recent, line)
+	if len(d.recent) > maxStates {
+		d.recent = d.recent[len(d.recent)-maxStates:]
+	}
+}
+
+// Write keeps the last line of the logs, and the warnings and errors
+// in the error pane.
+func (d *Dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, l := range strings.Split(strings.TrimSpace(string(p)), "\n") {
+		if l == "" {
+			continue
+		}
+		d.lastLog = l
+		if strings.Contains(l, "[ERROR]") || strings.Contains(l, "[WARN]") {
+			d.addError(l)
+		}
+	}
+	return len(p), nil
+}
+
+func (d *Dashboard) find(name string) *fileProgress {
+	for _, f := range d.active {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (d *Dashboard) addError(e string) {
+	d.errors = append(d.errors, e)
+	if len(d.errors) > maxErrors {
+		d.errors = d.errors[len(d.errors)-maxErrors:]
+	}
+}
+
+// summary gives the aggregate progress of the session in one line.
+func (d *Dashboard) summary() string {
+	files := fmt.Sprintf("%d", d.done+d.failed)
+	if d.count > 0 {
+		files = fmt.Sprintf("%d/%d", d.done+d.failed, d.count)
+	}
+	size := humanize.IBytes(uint64(d.bytes))
+	if d.total > 0 {
+		size = fmt.Sprintf("%s/%s", size, humanize.IBytes(uint64(d.total)))
+	}
+	ret := fmt.Sprintf("%s files, %s", files, size)
+	if d.failed > 0 {
+		ret += fmt.Sprintf(", %d failed", d.failed)
+	}
+	elapsed := time.Since(d.start).Seconds()
+	if elapsed > 0 && d.bytes > 0 {
+		ret += fmt.Sprintf(", %s/s", humanize.IBytes(uint64(float64(d.bytes)/elapsed)))
+	}
+	return ret
+}
+
+// render redraws the whole dashboard.
+func (d *Dashboard) render() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tb.Clear(tb.ColorDefault, tb.ColorDefault)
+	w, h := tb.Size()
+	if w < 40 || h < 12 {
+		text(0, 0, w, tb.ColorDefault, "Terminal is too small")
+		tb.Flush()
+		return
+	}
+
+	text(0, 0, w, tb.ColorDefault|tb.AttrBold, d.title)
+	text(0, h-1, w, tb.ColorDefault, "[up/down] select  [u] upload  [r] refresh  [q] quit  "+d.lastLog)
+
+	lw := w * 2 / 5
+	rw := w - lw
+	bodyH := h - 2
+	upH := bodyH / 2
+	stH := (bodyH - upH) / 2
+	errH := bodyH - upH - stH
+
+	d.renderProjects(0, 1, lw, bodyH)
+	d.renderUpload(lw, 1, rw, upH)
+	d.renderStates(lw, 1+upH, rw, stH)
+	d.renderErrors(lw, 1+upH+stH, rw, errH)
+	tb.Flush()
+}
+
+func (d *Dashboard) renderProjects(x, y, w, h int) {
+	box(x, y, w, h, fmt.Sprintf("Projects (%d)", len(d.projects)))
+	rows := h - 2
+	first := 0
+	if d.selected >= rows {
+		first = d.selected - rows + 1
+	}
+	for i := 0; i < rows && first+i < len(d.projects); i++ {
+		p := d.projects[first+i]
+		attr := tb.ColorDefault
+		if first+i == d.selected {
+			attr |= tb.AttrReverse
+		}
+		line := fmt.Sprintf("%s  %d imgs, %.2f GP  %s", p.Name, p.NumImage, p.GigaPixel, p.TaskState)
+		text(x+1, y+1+i, w-2, attr, line)
+	}
+}
+
+func (d *Dashboard) renderUpload(x, y, w, h int) {
+	title := "Upload"
+	if d.uploading {
+		title = fmt.Sprintf("Upload (%d active)", len(d.active))
+	}
+	box(x, y, w, h, title)
+	if d.start.IsZero() {
+		text(x+1, y+1, w-2, tb.ColorDefault, "Select a project and press u to upload")
+		return
+	}
+	ratio := -1.0
+	if d.total > 0 {
+		ratio = float64(d.bytes) / float64(d.total)
+	} else if d.count > 0 {
+		ratio = float64(d.done+d.failed) / float64(d.count)
+	}
+	text(x+1, y+1, w-2, tb.ColorGreen, bar(ratio, w-10))
+	text(x+1, y+2, w-2, tb.ColorDefault, d.summary())
+	for i, f := range d.active {
+		if 3+i >= h-1 {
+			break
+		}
+		r := -1.0
+		if f.size > 0 {
+			r = float64(f.bytes) / float64(f.size)
+		}
+		line := fmt.Sprintf("%-20s %s", truncate(filepath.Base(f.name), 20), bar(r, w-32))
+		text(x+1, y+3+i, w-2, tb.ColorDefault, line)
+	}
+}
+
+func (d *Dashboard) renderStates(x, y, w, h int) {
+	box(x, y, w, h, "States")
+	var keys []string
+	for k := range d.states {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var counts []string
+	for _, k := range keys {
+		counts = append(counts, fmt.Sprintf("%s: %d", k, d.states[k]))
+	}
+	text(x+1, y+1, w-2, tb.ColorDefault|tb.AttrBold, strings.Join(counts, "  "))
+	rows := h - 3
+	recent := d.recent
+	if len(recent) > rows {
+		recent = recent[len(recent)-rows:]
+	}
+	for i, l := range recent {
+		text(x+1, y+2+i, w-2, tb.ColorDefault, l)
+	}
+}
+
+func (d *Dashboard) renderErrors(x, y, w, h int) {
+	box(x, y, w, h, fmt.Sprintf("Errors (%d)", len(d.errors)))
+	rows := h - 2
+	errs := d.errors
+	if len(errs) > rows {
+		errs = errs[len(errs)-rows:]
+	}
+	for i, e := range errs {
+		text(x+1, y+1+i, w-2, tb.ColorRed, e)
+	}
+}
+
+// text draws s at (x, y), truncated to width w.
+func text(x, y, w int, fg tb.Attribute, s string) {
+	for i, r := range []rune(s) {
+		if i >= w {
+			return
+		}
+		tb.SetCell(x+i, y, r, fg, tb.ColorDefault)
+	}
+}
+
+// box draws the border of a pane with title.
+func box(x, y, w, h int, title string) {
+	for i := 1; i < w-1; i++ {
+		tb.SetCell(x+i, y, '─', tb.ColorDefault, tb.ColorDefault)
+		tb.SetCell(x+i, y+h-1, '─', tb.ColorDefault, tb.ColorDefault)
+	}
+	for j := 1; j < h-1; j++ {
+		tb.SetCell(x, y+j, '│', tb.ColorDefault, tb.ColorDefault)
+		tb.SetCell(x+w-1, y+j, '│', tb.ColorDefault, tb.ColorDefault)
+	}
+	tb.SetCell(x, y, '┌', tb.ColorDefault, tb.ColorDefault)
+	tb.SetCell(x+w-1, y, '┐', tb.ColorDefault, tb.ColorDefault)
+	tb.SetCell(x, y+h-1, '└', tb.ColorDefault, tb.ColorDefault)
+	tb.SetCell(x+w-1, y+h-1, '┘', tb.ColorDefault, tb.ColorDefault)
+	text(x+2, y, w-4, tb.ColorDefault|tb.AttrBold, " "+title+" ")
+}
+
+// bar draws a bar of ratio with percentage in about n characters.
+// Unknown ratio is drawn empty.
+func bar(ratio float64, n int) string {
+	if n < 10 {
+		n = 10
+	}
+	if ratio < 0 {
+		return fmt.Sprintf("[%s]", strings.Repeat(" ", n))
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+	k := int(ratio * float64(n))
+	return fmt.Sprintf("[%s%s] %3.0f%%", strings.Repeat("=", k), strings.Repeat(" ", n-k), ratio*100)
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDashboardProgress(t *testing.T) {
+	d := &Dashboard{}
+	d.Begin(2, 300)
+	d.Start("a.jpg", 100)
+	d.Start("b.jpg", 200)
+	d.Add("a.jpg", 60)
+	d.Start("a.jpg", 100) // retry
+	d.Add("a.jpg", 100)
+	d.Done("a.jpg", nil)
+	d.Add("b.jpg", 50)
+	d.Done("b.jpg", errors.New("timeout"))
+
+	if d.bytes != 300 {
+		t.Errorf("bytes = %d, want %d", d.bytes, 300)
+	}
+	if d.done != 1 || d.failed != 1 {
+		t.Errorf("done, failed = %d, %d, want 1, 1", d.done, d.failed)
+	}
+	if len(d.active) != 0 {
+		t.Errorf("active = %d, want 0", len(d.active))
+	}
+	if len(d.errors) != 1 || d.errors[0] != "b.jpg: timeout" {
+		t.Errorf("errors = %q", d.errors)
+	}
+	if !d.uploading {
+		t.Error("uploading = false, want true")
+	}
+	d.End()
+	if d.uploading {
+		t.Error("uploading = true after End, want false")
+	}
+}
+
+func TestDashboardStateAndWrite(t *testing.T) {
+	d := &Dashboard{}
+	d.Begin(0, 0)
+	d.State("/img/a.jpg", "Ready", "")
+	d.State("/img/b.jpg", "Invalid", "bad exif")
+	d.Write([]byte("2019/11/06 Checking...\n2019/11/06 [WARN] Invalid image\n"))
+
+	if d.states["Ready"] != 1 || d.states["Invalid"] != 1 {
+		t.Errorf("states = %v", d.states)
+	}
+	if len(d.recent) != 2 || d.recent[1] != "b.jpg: Invalid (bad exif)" {
+		t.Errorf("recent = %q", d.recent)
+	}
+	if len(d.errors) != 2 {
+		t.Errorf("errors = %q, want 2", d.errors)
+	}
+	if d.lastLog != "2019/11/06 [WARN] Invalid image" {
+		t.Errorf("lastLog = %q", d.lastLog)
+	}
+}
@@ -0,0 +1,113 @@
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// BinaryName gives the name of the alti-cli binary in the archives of goos.
+func BinaryName(goos string) string {
+	if goos == "windows" {
+		return "alti-cli.exe"
+	}
+	return "alti-cli"
+}
+
+// ExtractBinary extracts the file of base name bin from the archive of name,
+// either a .tar.gz or a .zip.
+func ExtractBinary(name string, archive []byte, bin string) ([]byte, error) {
+	if strings.HasSuffix(name, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if path.Base(f.Name) != bin || f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return ioutil.ReadAll(rc)
+		}
+		return nil, errors.ErrReleaseNotFound
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.ErrReleaseNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag == tar.TypeReg && path.Base(h.Name) == bin {
+			return ioutil.ReadAll(tr)
+		}
+	}
+}
+
+// Replace replaces the executable exe by bin atomically, i.e. bin is written
+// next to exe and then renamed over it. On windows, the running exe could
+// not be overwritten, so it is renamed to exe.old first.
+func Replace(exe string, bin []byte) error {
+	exe, err := filepath.EvalSymlinks(exe)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(exe)
+	tmp, err := ioutil.TempFile(dir, ".alti-cli-update-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(bin); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), info.Mode().Perm()|0111); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err = os.Rename(exe, old); err != nil {
+			return err
+		}
+		if err = os.Rename(tmp.Name(), exe); err != nil {
+			// restore the current one
+			os.Rename(old, exe)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmp.Name(), exe)
+}
@@ -0,0 +1,58 @@
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractAndReplace(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	files := map[string]string{"README.md": "readme", "alti-cli": "new binary"}
+	for _, n := range []string{"README.md", "alti-cli"} {
+		c := files[n]
+		if err := tw.WriteHeader(&tar.Header{Name: n, Mode: 0755, Size: int64(len(c)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(c))
+	}
+	tw.Close()
+	gw.Close()
+
+	bin, err := ExtractBinary("a.tar.gz", buf.Bytes(), "alti-cli")
+	if err != nil || string(bin) != "new binary" {
+		t.Fatalf("ExtractBinary() = %q, %v", bin, err)
+	}
+	if _, err = ExtractBinary("a.tar.gz", buf.Bytes(), "alti-cli.exe"); err == nil {
+		t.Error("ExtractBinary() of missing binary gives no error")
+	}
+
+	dir, err := ioutil.TempDir("", "update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	exe := filepath.Join(dir, "alti-cli")
+	if err = ioutil.WriteFile(exe, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = Replace(exe, bin); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := ioutil.ReadFile(exe)
+	if string(got) != "new binary" {
+		t.Errorf("Replace() gives %q, want %q", got, "new binary")
+	}
+	if info, _ := os.Stat(exe); info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Replace() gives mode %v, not executable", info.Mode())
+	}
+	if fs, _ := ioutil.ReadDir(dir); len(fs) != 1 {
+		t.Errorf("Replace() leaves %d files, want 1", len(fs))
+	}
+}
@@ -0,0 +1,188 @@
+package update
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// Release channels.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// Channels are the release channels, beta includes the pre-releases.
+var Channels = []string{ChannelStable, ChannelBeta}
+
+// ReleasesURL is the url of the GitHub releases api of alti-cli.
+var ReleasesURL = "https://api.github.com/repos/jackytck/alti-cli/releases"
+
+// ChecksumsName is the name of the release asset of the sha256 checksums.
+const ChecksumsName = "checksums.txt"
+
+// SignatureName is the name of the release asset of the ed25519 signature
+// of the checksums.
+const SignatureName = ChecksumsName + ".sig"
+
+// timeout is the timeout of each request, including downloading a binary.
+const timeout = 5 * time.Minute
+
+// Asset represents a file of a GitHub release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Release represents a GitHub release.
+type Release struct {
+	Tag        string  `json:"tag_name"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Latest gives the latest release of channel.
+func Latest(channel string) (*Release, error) {
+	b, err := Download(ReleasesURL + "?per_page=30")
+	if err != nil {
+		return nil, err
+	}
+	var rs []Release
+	if err = json.Unmarshal(b, &rs); err != nil {
+		return nil, err
+	}
+	// newest first
+	for _, r := range rs {
+		if r.Draft || (r.Prerelease && channel != ChannelBeta) {
+			continue
+		}
+		return &r, nil
+	}
+	return nil, errors.ErrReleaseNotFound
+}
+
+// Asset gives the asset of name, nil if not found.
+func (r *Release) Asset(name string) *Asset {
+	for i, a := range r.Assets {
+		if a.Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// Archive gives the archive asset of the binary of goos and goarch, named by
+// goreleaser, e.g. alti-cli_1.2.0_Linux_x86_64.tar.gz. Return nil if not found.
+func (r *Release) Archive(goos, goarch string) *Asset {
+	suffix := fmt.Sprintf("_%s_%s", archiveOS(goos), archiveArch(goarch))
+	for i, a := range r.Assets {
+		for _, ext := range []string{".tar.gz", ".zip"} {
+			if strings.HasSuffix(a.Name, suffix+ext) {
+				return &r.Assets[i]
+			}
+		}
+	}
+	return nil
+}
+
+// CurrentArchive gives the archive asset of the running os and arch.
+func (r *Release) CurrentArchive() *Asset {
+	return r.Archive(runtime.GOOS, runtime.GOARCH)
+}
+
+// archiveOS gives the os in the archive name, as replaced in .goreleaser.yml.
+func archiveOS(goos string) string {
+	switch goos {
+	case "darwin":
+		return "Darwin"
+	case "linux":
+		return "Linux"
+	case "windows":
+		return "Windows"
+	}
+	return goos
+}
+
+// archiveArch gives the arch in the archive name, as replaced in .goreleaser.yml.
+func archiveArch(goarch string) string {
+	switch goarch {
+	case "386":
+		return "i386"
+	case "amd64":
+		return "x86_64"
+	}
+	return goarch
+}
+
+// Newer tells if version tag is newer than the current version cur,
+// both in the form of v1.2.3 or v1.2.3-beta.1. A release is newer than its
+// pre-releases. Unparsable versions are never newer.
+func Newer(tag, cur string) bool {
+	a, aPre, ok := parseVersion(tag)
+	if !ok {
+		return false
+	}
+	b, bPre, ok := parseVersion(cur)
+	if !ok {
+		return true
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return false
+	case aPre == "":
+		return true
+	case bPre == "":
+		return false
+	}
+	return aPre > bPre
+}
+
+// parseVersion parses v1.2.3-pre into its numbers and pre-release.
+func parseVersion(v string) ([3]int, string, bool) {
+	var ret [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	var pre string
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return ret, "", false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return ret, "", false
+		}
+		ret[i] = n
+	}
+	return ret, pre, true
+}
+
+// Download gets the body of url with the network settings.
+func Download(url string) ([]byte, error) {
+	res, err := config.HTTPClient(timeout).Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.NetworkError{Code: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+	}
+	return ioutil.ReadAll(res.Body)
+}
@@ -0,0 +1,62 @@
+package update
+
+import "testing"
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		tag  string
+		cur  string
+		want bool
+	}{
+		{"v1.1.0", "v1.0.0", true},
+		{"v1.0.0", "v1.0.0", false},
+		{"v1.0.0", "v1.1.0", false},
+		{"v1.10.0", "v1.9.3", true},
+		{"v2.0.0-beta.1", "v1.9.0", true},
+		{"v2.0.0", "v2.0.0-beta.1", true},
+		{"v2.0.0-beta.1", "v2.0.0", false},
+		{"v2.0.0-beta.2", "v2.0.0-beta.1", true},
+		{"nightly", "v1.0.0", false},
+		{"v1.0.0", "dev", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag+" "+tt.cur, func(t *testing.T) {
+			if got := Newer(tt.tag, tt.cur); got != tt.want {
+				t.Errorf("Newer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseArchive(t *testing.T) {
+	r := Release{
+		Tag: "v1.2.0",
+		Assets: []Asset{
+			{Name: "checksums.txt"},
+			{Name: "alti-cli_1.2.0_Darwin_x86_64.tar.gz"},
+			{Name: "alti-cli_1.2.0_Linux_x86_64.tar.gz"},
+			{Name: "alti-cli_1.2.0_Windows_i386.zip"},
+		},
+	}
+	tests := []struct {
+		goos   string
+		goarch string
+		want   string
+	}{
+		{"linux", "amd64", "alti-cli_1.2.0_Linux_x86_64.tar.gz"},
+		{"darwin", "amd64", "alti-cli_1.2.0_Darwin_x86_64.tar.gz"},
+		{"windows", "386", "alti-cli_1.2.0_Windows_i386.zip"},
+		{"linux", "arm64", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos+"/"+tt.goarch, func(t *testing.T) {
+			var got string
+			if a := r.Archive(tt.goos, tt.goarch); a != nil {
+				got = a.Name
+			}
+			if got != tt.want {
+				t.Errorf("Archive() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// VerifySignature verifies the ed25519 signature sig of the checksums sums by
+// the base64 public key pub. sig is either raw or base64 encoded.
+func VerifySignature(sums, sig []byte, pub string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(pub))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.ErrReleaseKeyInvalid
+	}
+	if len(sig) != ed25519.SignatureSize {
+		if sig, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig))); err != nil {
+			return errors.ErrReleaseUnverified
+		}
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), sums, sig) {
+		return errors.ErrReleaseUnverified
+	}
+	return nil
+}
+
+// ParseChecksums parses the lines of 'sha256  filename' of sums.
+func ParseChecksums(sums []byte) map[string]string {
+	ret := make(map[string]string)
+	s := bufio.NewScanner(bytes.NewReader(sums))
+	for s.Scan() {
+		fs := strings.Fields(s.Text())
+		if len(fs) != 2 {
+			continue
+		}
+		ret[strings.TrimPrefix(fs[1], "*")] = strings.ToLower(fs[0])
+	}
+	return ret
+}
+
+// VerifyChecksum verifies the sha256 of the file of name with content b
+// against the checksums sums.
+func VerifyChecksum(sums []byte, name string, b []byte) error {
+	want, ok := ParseChecksums(sums)[name]
+	if !ok {
+		return errors.ErrReleaseUnverified
+	}
+	got := sha256.Sum256(b)
+	if hex.EncodeToString(got[:]) != want {
+		return errors.ErrReleaseUnverified
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := []byte("binary")
+	sum := sha256.Sum256(bin)
+	sums := []byte(fmt.Sprintf("%s  alti-cli_1.2.0_Linux_x86_64.tar.gz\n", hex.EncodeToString(sum[:])))
+	sig := ed25519.Sign(priv, sums)
+	key := base64.StdEncoding.EncodeToString(pub)
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	tests := []struct {
+		name    string
+		sums    []byte
+		sig     []byte
+		key     string
+		wantErr bool
+	}{
+		{"raw", sums, sig, key, false},
+		{"base64", sums, []byte(base64.StdEncoding.EncodeToString(sig) + "\n"), key, false},
+		{"tampered", append([]byte("x"), sums...), sig, key, true},
+		{"other key", sums, sig, base64.StdEncoding.EncodeToString(other), true},
+		{"no key", sums, sig, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifySignature(tt.sums, tt.sig, tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("VerifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err = VerifyChecksum(sums, "alti-cli_1.2.0_Linux_x86_64.tar.gz", bin); err != nil {
+		t.Errorf("VerifyChecksum() error = %v", err)
+	}
+	if err = VerifyChecksum(sums, "alti-cli_1.2.0_Linux_x86_64.tar.gz", []byte("evil")); err == nil {
+		t.Error("VerifyChecksum() of modified binary gives no error")
+	}
+	if err = VerifyChecksum(sums, "missing.tar.gz", bin); err == nil {
+		t.Error("VerifyChecksum() of missing file gives no error")
+	}
+}
@@ -0,0 +1,48 @@
+package web
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/jackytck/alti-cli/file"
+)
+
+// archiveDir is http.Dir that also serves the files in the zip archives by
+// their file.ArchivePath, e.g. '/card.zip!/DCIM/IMG_0001.JPG'.
+type archiveDir struct {
+	http.Dir
+}
+
+func (d archiveDir) Open(name string) (http.File, error) {
+	zp, entry, ok := file.SplitArchivePath(name)
+	if !ok {
+		return d.Dir.Open(name)
+	}
+	p := filepath.Join(string(d.Dir), filepath.FromSlash(path.Clean("/"+zp)))
+	p = file.ArchivePath(p, entry)
+	info, err := file.StatFile(p)
+	if err != nil {
+		return nil, err
+	}
+	f, err := file.OpenFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return archiveFile{f, info}, nil
+}
+
+// archiveFile is a file in a zip archive served as http.File.
+type archiveFile struct {
+	file.ReadSeekCloser
+	info os.FileInfo
+}
+
+func (f archiveFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+func (f archiveFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackytck/alti-cli/config"
+	"github.com/jackytck/alti-cli/errors"
+)
+
+// BeamIndexPath is the path of the index of the files served by beam send.
+const BeamIndexPath = "/.beam/index"
+
+// BeamFile is a file served by beam send.
+// Path is slash separated and relative to the served directory.
+type BeamFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	SHA1 string `json:"sha1"`
+}
+
+// BeamIndex lists the files served by beam send.
+type BeamIndex struct {
+	Name  string     `json:"name"`
+	Files []BeamFile `json:"files"`
+}
+
+// TotalSize gives the total size of all the files in bytes.
+func (idx *BeamIndex) TotalSize() int64 {
+	var ret int64
+	for _, f := range idx.Files {
+		ret += f.Size
+	}
+	return ret
+}
+
+// ServeHTTP serves the index in json.
+func (idx *BeamIndex) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(idx); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// FetchBeamIndex gets the index of the beam sender at baseURL.
+func FetchBeamIndex(baseURL string) (*BeamIndex, error) {
+	resp, err := config.HTTPClient(0).Get(strings.TrimRight(baseURL, "/") + BeamIndexPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.NetworkError{Code: resp.StatusCode, Message: "bad status"}
+	}
+	var idx BeamIndex
+	if err := json.NewDecoder(resp.Body).Decode(&idx); err != nil {
+		return nil, err
+	}
+	return &idx, nil
+}
+
+// BeamURL gives the url of f served by the beam sender at baseURL.
+func BeamURL(baseURL string, f BeamFile) string {
+	var segs []string
+	for _, s := range strings.Split(f.Path, "/") {
+		segs = append(segs, url.PathEscape(s))
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.Join(segs, "/")
+}
+
+// BeamTarget gives the local path of f received into dir.
+// Paths escaping dir are rejected.
+func BeamTarget(dir string, f BeamFile) (string, error) {
+	p := path.Clean("/" + f.Path)
+	if p == "/" || p != "/"+f.Path || strings.Contains(f.Path, `\`) {
+		return "", fmt.Errorf("invalid path: %q", f.Path)
+	}
+	return filepath.Join(dir, filepath.FromSlash(p[1:])), nil
+}
@@ -0,0 +1,42 @@
+package web
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBeamTarget(t *testing.T) {
+	dir := filepath.FromSlash("/dest")
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"a.jpg", "a.jpg", false},
+		{"raw/b c.jpg", "raw/b c.jpg", false},
+		{"../a.jpg", "", true},
+		{"raw/../../a.jpg", "", true},
+		{"/etc/passwd", "", true},
+		{"raw/./a.jpg", "", true},
+		{`raw\..\a.jpg`, "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := BeamTarget(dir, BeamFile{Path: tt.path})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("BeamTarget(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if want := filepath.Join(dir, filepath.FromSlash(tt.want)); !tt.wantErr && got != want {
+			t.Errorf("BeamTarget(%q) = %q, want %q", tt.path, got, want)
+		}
+	}
+}
+
+func TestBeamURL(t *testing.T) {
+	got := BeamURL("http://10.0.0.2:8080/tok/", BeamFile{Path: "raw/a b#1.jpg"})
+	want := "http://10.0.0.2:8080/tok/raw/a%20b%231.jpg"
+	if got != want {
+		t.Errorf("BeamURL() = %q, want %q", got, want)
+	}
+}
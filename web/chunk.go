@@ -0,0 +1,207 @@
+package web
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackytck/alti-cli/logging"
+)
+
+// ChunkPrefix is the url prefix of the chunked files.
+const ChunkPrefix = "/.chunks/"
+
+// ChunkSize is the size of each chunk of a file served in chunks.
+var ChunkSize int64 = 8 << 20
+
+// Chunk represents a chunk of a file.
+type Chunk struct {
+	Index  int    `json:"index"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+	SHA1   string `json:"sha1"`
+	Served bool   `json:"served"` // completely served once
+}
+
+// Manifest lists the chunks of a file, for the api server to pull and
+// verify each chunk, and re-request the failed ones only.
+type Manifest struct {
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+	ChunkSize int64     `json:"chunkSize"`
+	Chunks    []Chunk   `json:"chunks"`
+}
+
+// ChunkedURL gives the manifest url of file served under baseURL.
+func ChunkedURL(baseURL, file string) string {
+	return strings.TrimRight(baseURL, "/") + ChunkPrefix + strings.TrimLeft(file, "/")
+}
+
+// BuildManifest splits the file p into chunks of chunkSize and computes
+// their checksums.
+func BuildManifest(p string, chunkSize int64) (*Manifest, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Filename:  filepath.Base(p),
+		Size:      info.Size(),
+		ModTime:   info.ModTime(),
+		ChunkSize: chunkSize,
+	}
+	for off, i := int64(0), 0; off < m.Size; off, i = off+chunkSize, i+1 {
+		size := chunkSize
+		if off+size > m.Size {
+			size = m.Size - off
+		}
+		h := sha1.New()
+		if _, err := io.Copy(h, io.NewSectionReader(f, off, size)); err != nil {
+			return nil, err
+		}
+		m.Chunks = append(m.Chunks, Chunk{
+			Index:  i,
+			Offset: off,
+			Size:   size,
+			SHA1:   hex.EncodeToString(h.Sum(nil)),
+		})
+	}
+	return m, nil
+}
+
+// LoadManifest loads the manifest of file p from statePath, or builds and
+// saves a new one if the file is changed since then.
+func LoadManifest(statePath, p string, chunkSize int64) (*Manifest, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := ioutil.ReadFile(statePath); err == nil {
+		var m Manifest
+		if json.Unmarshal(data, &m) == nil && m.Size == info.Size() &&
+			m.ModTime.Equal(info.ModTime()) && m.ChunkSize == chunkSize {
+			return &m, nil
+		}
+	}
+	m, err := BuildManifest(p, chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	return m, m.Save(statePath)
+}
+
+// Save saves the manifest in path.
+func (m *Manifest) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// chunkHandler serves the manifest by 'GET /.chunks/file' and the i-th chunk
+// by 'GET /.chunks/file?chunk=i'. Range requests of a chunk are supported.
+// The manifests are kept in stateDir, so that served chunks are remembered
+// across runs.
+type chunkHandler struct {
+	dir       string
+	stateDir  string
+	chunkSize int64
+	verbose   bool
+
+	mu        sync.Mutex
+	manifests map[string]*Manifest
+}
+
+func (h *chunkHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + strings.TrimPrefix(r.URL.Path, ChunkPrefix))
+	p := filepath.Join(h.dir, filepath.FromSlash(name))
+	m, statePath, err := h.manifest(p)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	q := r.URL.Query().Get("chunk")
+	if q == "" {
+		w.Header().Set("Content-Type", "application/json")
+		h.mu.Lock()
+		json.NewEncoder(w).Encode(m)
+		h.mu.Unlock()
+		return
+	}
+	i, err := strconv.Atoi(q)
+	if err != nil || i < 0 || i >= len(m.Chunks) {
+		http.Error(w, "invalid chunk", http.StatusBadRequest)
+		return
+	}
+	c := m.Chunks[i]
+
+	f, err := os.Open(p)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("X-Chunk-SHA1", c.SHA1)
+	name = fmt.Sprintf("%s.%d", m.Filename, i)
+	http.ServeContent(w, r, name, m.ModTime, io.NewSectionReader(f, c.Offset, c.Size))
+
+	// only a whole chunk counts as served
+	if r.Header.Get("Range") != "" || r.Method != http.MethodGet {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	m.Chunks[i].Served = true
+	if err := m.Save(statePath); err != nil {
+		logging.Warnf("Could not save manifest of %q: %v\n", p, err)
+	}
+	if h.verbose {
+		logging.Infof("Served chunk %d/%d of %q\n", i+1, len(m.Chunks), m.Filename)
+	}
+}
+
+// manifest gets the cached or loaded manifest of file p, with its state path.
+func (h *chunkHandler) manifest(p string) (*Manifest, string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha1.Sum([]byte(abs))
+	statePath := filepath.Join(h.stateDir, hex.EncodeToString(sum[:])[:16]+".json")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if m, ok := h.manifests[abs]; ok {
+		return m, statePath, nil
+	}
+	if err := os.MkdirAll(h.stateDir, 0755); err != nil {
+		return nil, "", err
+	}
+	m, err := LoadManifest(statePath, abs, h.chunkSize)
+	if err != nil {
+		return nil, "", err
+	}
+	h.manifests[abs] = m
+	return m, statePath, nil
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chunk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name      string
+		size      int
+		chunkSize int64
+		wantSizes []int64
+	}{
+		{"empty", 0, 4, nil},
+		{"exact", 8, 4, []int64{4, 4}},
+		{"remainder", 10, 4, []int64{4, 4, 2}},
+		{"single", 3, 4, []int64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := filepath.Join(dir, tt.name)
+			if err := ioutil.WriteFile(p, make([]byte, tt.size), 0644); err != nil {
+				t.Fatal(err)
+			}
+			m, err := BuildManifest(p, tt.chunkSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(m.Chunks) != len(tt.wantSizes) {
+				t.Fatalf("BuildManifest() got %d chunks, want %d", len(m.Chunks), len(tt.wantSizes))
+			}
+			var off int64
+			for i, c := range m.Chunks {
+				if c.Size != tt.wantSizes[i] || c.Offset != off || c.Index != i {
+					t.Errorf("BuildManifest() chunk %d = %+v, want offset %d size %d", i, c, off, tt.wantSizes[i])
+				}
+				off += c.Size
+			}
+		})
+	}
+}
@@ -1,25 +1,50 @@
 package web
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net"
 	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
-	"strings"
 	"sync"
 
+	"github.com/jackytck/alti-cli/config"
 	"github.com/jackytck/alti-cli/errors"
 	"github.com/jackytck/alti-cli/gql"
+	"github.com/jackytck/alti-cli/lifecycle"
+	"github.com/jackytck/alti-cli/logging"
+	"github.com/jackytck/alti-cli/rand"
 )
 
+// DirectTLS serves the direct upload over https with a self-signed cert.
+var DirectTLS bool
+
+// DirectInterface restricts the direct upload to the network interface of
+// the name, e.g. 'eth1', if set.
+var DirectInterface string
+
+// PreferIPv6 prefers the IPv6 addresses over the IPv4 ones for direct upload.
+var PreferIPv6 bool
+
+// DirectScheme gives the url scheme of the direct upload server.
+func DirectScheme() string {
+	if DirectTLS {
+		return "https"
+	}
+	return "http"
+}
+
 // StartLocalServer starts a local server serving dir on random port.
 // If ip is not provided, non-local ip will be used.
 // If port is not provided, a random port will be used.
+// The files are served under a random per-session token, which is part of
+// the returned base url. The returned done shuts down the server, and is
+// registered as a cleanup hook in case the process exits early.
 func StartLocalServer(dir, ip, port string, verbose bool) (string, func(), error) {
 	var address string
 
@@ -29,12 +54,26 @@ func StartLocalServer(dir, ip, port string, verbose bool) (string, func(), error
 		if err != nil {
 			return "", nil, err
 		}
-		address = pu.Hostname() + ":" + port
+		address = net.JoinHostPort(pu.Hostname(), port)
 	} else {
-		address = ip + ":" + port
+		address = net.JoinHostPort(ip, port)
 	}
 
-	s := Server{Directory: dir, Address: address}
+	confDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := rand.String(16)
+	if err != nil {
+		return "", nil, err
+	}
+	s := Server{
+		Directory: dir,
+		Address:   address,
+		StateDir:  filepath.Join(confDir, "chunks"),
+		TLS:       DirectTLS,
+		Token:     token,
+	}
 	hs, p, err := s.ServeStatic(verbose)
 	if err != nil {
 		return "", nil, err
@@ -45,15 +84,15 @@ func StartLocalServer(dir, ip, port string, verbose bool) (string, func(), error
 		address += ps
 	}
 
-	baseURL := fmt.Sprintf("http://%s", address)
-	log.Printf("Serving files at %s\n", baseURL)
+	logging.Infof("Serving files at %s://%s\n", s.Scheme(), address)
+	baseURL := fmt.Sprintf("%s://%s/%s", s.Scheme(), address, token)
 	done := func() {
-		log.Println("Shutting down local server...")
+		logging.Infoln("Shutting down local server...")
 		if err = hs.Shutdown(context.TODO()); err != nil {
 			panic(err)
 		}
 	}
-	return baseURL, done, nil
+	return baseURL, lifecycle.Register("direct upload server", done).Release, nil
 }
 
 // GetOutboundIP gets the preferred outbound ip of this machine.
@@ -69,17 +108,57 @@ func GetOutboundIP() (string, error) {
 	return localAddr.IP.String(), nil
 }
 
-// GetAllIP gets all the local ip.
+// GetAllIP gets all the local ips that could be tested for direct upload.
 func GetAllIP() ([]string, error) {
 	var ret []string
-	ifaces, err := net.Interfaces()
+	addrs, err := LocalAddrs("")
 	if err != nil {
 		return ret, err
 	}
+	for _, a := range addrs {
+		if a.skipReason() == "" {
+			ret = append(ret, a.IP.String())
+		}
+	}
+	return ret, nil
+}
+
+// LocalAddr is an ip of a network interface of this machine.
+type LocalAddr struct {
+	Interface string
+	IP        net.IP
+	Up        bool
+}
+
+// skipReason tells why the address is not tested for direct upload, empty if
+// it is tested.
+func (a LocalAddr) skipReason() string {
+	if !a.Up {
+		return "interface is down"
+	}
+	if a.IP.To4() == nil && a.IP.IsLinkLocalUnicast() {
+		return "link-local address is not routable"
+	}
+	return ""
+}
+
+// LocalAddrs gets the IPv4 and IPv6 ips of all network interfaces, or only of
+// the interface of name if it is not empty.
+func LocalAddrs(name string) ([]LocalAddr, error) {
+	var ret []LocalAddr
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	found := false
 	for _, i := range ifaces {
+		if name != "" && i.Name != name {
+			continue
+		}
+		found = true
 		addrs, err := i.Addrs()
 		if err != nil {
-			return ret, err
+			return nil, err
 		}
 		for _, addr := range addrs {
 			var ip net.IP
@@ -89,19 +168,58 @@ func GetAllIP() ([]string, error) {
 			case *net.IPAddr:
 				ip = v.IP
 			}
-			ipStr := ip.String()
-			if !strings.Contains(ipStr, ":") {
-				ret = append(ret, ip.String())
+			if ip == nil {
+				continue
 			}
+			ret = append(ret, LocalAddr{i.Name, ip, i.Flags&net.FlagUp != 0})
 		}
 	}
+	if name != "" && !found {
+		return nil, fmt.Errorf("network interface %q is not found", name)
+	}
 	return ret, nil
 }
 
+// sortAddrs sorts the addresses in the order of preference for direct upload:
+// non-loopback > loopback, then IPv4 > IPv6, or the reverse if preferV6.
+func sortAddrs(addrs []LocalAddr, preferV6 bool) {
+	rank := func(a LocalAddr) int {
+		r := 0
+		if a.IP.IsLoopback() {
+			r += 2
+		}
+		if (a.IP.To4() == nil) != preferV6 {
+			r++
+		}
+		return r
+	}
+	sort.SliceStable(addrs, func(i, j int) bool {
+		ri, rj := rank(addrs[i]), rank(addrs[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return bytes.Compare(addrs[i].IP.To16(), addrs[j].IP.To16()) < 0
+	})
+}
+
+// Visibility is the result of checking if the api server could reach a local
+// address.
+type Visibility struct {
+	Interface string
+	URL       string
+	Visible   bool
+	Reason    string // why it is invisible or not tested
+}
+
 // CheckVisibility checks if api server could reach this client machine
-// for each newtwork interface.
-func CheckVisibility(verbose bool) (map[string]bool, error) {
-	ret := make(map[string]bool)
+// for each address of the network interfaces, or only of DirectInterface if
+// set. The results are in the order of preference by PreferIPv6.
+func CheckVisibility(verbose bool) ([]Visibility, error) {
+	addrs, err := LocalAddrs(DirectInterface)
+	if err != nil {
+		return nil, err
+	}
+	sortAddrs(addrs, PreferIPv6)
 
 	// tmp dir for server
 	tmpDir, err := ioutil.TempDir(".", "alti-cli-")
@@ -111,39 +229,39 @@ func CheckVisibility(verbose bool) (map[string]bool, error) {
 	defer os.RemoveAll(tmpDir)
 
 	// create local web server
-	s := Server{Directory: tmpDir}
+	s := Server{Directory: tmpDir, TLS: DirectTLS}
 	server, port, err := s.ServeStatic(false)
 	if err != nil {
 		return nil, err
 	}
 
 	// check each ip
-	ips, err := GetAllIP()
-	if err != nil {
-		return nil, err
-	}
-	ch := make(chan netCheckResult)
+	ret := make([]Visibility, len(addrs))
 	var wg sync.WaitGroup
-	wg.Add(len(ips))
-
-	go func() {
-		wg.Wait()
-		close(ch)
-	}()
-	for _, ip := range ips {
-		go func(ip string) {
-			url := fmt.Sprintf("http://%v:%v", ip, port)
+	for i, a := range addrs {
+		url := fmt.Sprintf("%s://%s", s.Scheme(), net.JoinHostPort(a.IP.String(), strconv.Itoa(port)))
+		ret[i] = Visibility{Interface: a.Interface, URL: url, Reason: a.skipReason()}
+		if ret[i].Reason != "" {
+			continue
+		}
+		wg.Add(1)
+		go func(v *Visibility) {
+			defer wg.Done()
 			if verbose {
-				log.Printf("Checking %q...", url)
+				logging.Infof("Checking %q...", v.URL)
 			}
-			res := gql.CheckDirectNetwork(url)
-			ch <- netCheckResult{url, res}
-			wg.Done()
-		}(ip)
-	}
-	for r := range ch {
-		ret[r.url] = r.visibility
+			res, err := gql.DirectNetworkTest(v.URL)
+			switch {
+			case err != nil:
+				v.Reason = err.Error()
+			case res != "Success":
+				v.Reason = res
+			default:
+				v.Visible = true
+			}
+		}(&ret[i])
 	}
+	wg.Wait()
 
 	// close down temp server
 	if err := server.Shutdown(context.TODO()); err != nil {
@@ -153,57 +271,33 @@ func CheckVisibility(verbose bool) (map[string]bool, error) {
 	return ret, nil
 }
 
-type netCheckResult struct {
-	url        string
-	visibility bool
-}
-
-// PreferredLocalURL returns visible url in following preference:
-// non-localhost > localhost url.
-func PreferredLocalURL(verbose bool) (*url.URL, map[string]bool, error) {
+// PreferredLocalURL returns the most preferred visible url, i.e.
+// non-localhost > localhost url, with the results of all the checked addresses.
+func PreferredLocalURL(verbose bool) (*url.URL, []Visibility, error) {
 	checks, err := CheckVisibility(verbose)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	// classify local or non-local ips
-	var local, nonLocal []string
-	for k, v := range checks {
-		if !v {
+	for _, v := range checks {
+		if !v.Visible {
 			continue
 		}
-		if strings.Contains(k, "127.0.0.1") {
-			local = append(local, k)
-		} else {
-			nonLocal = append(nonLocal, k)
+		u, err := url.ParseRequestURI(v.URL)
+		if err != nil {
+			return nil, checks, err
 		}
+		return u, checks, nil
 	}
-	if len(local)+len(nonLocal) == 0 {
-		return nil, checks, errors.ErrClientInvisible
-	}
-	sort.Strings(nonLocal)
-
-	// prefer non-local over local ip
-	var ret string
-	if len(nonLocal) > 0 {
-		ret = nonLocal[0]
-	} else {
-		ret = local[0]
-	}
-
-	u, err := url.ParseRequestURI(ret)
-	if err != nil {
-		return nil, checks, err
-	}
-	return u, checks, nil
+	return nil, checks, errors.ErrClientInvisible
 }
 
 // CheckVisibilityIPPort checks if starting a local server over the given
 // ip and port could be visible by the api server.
 func CheckVisibilityIPPort(ip, port string, verbose bool) (bool, error) {
-	url := fmt.Sprintf("http://%v:%v", ip, port)
+	address := net.JoinHostPort(ip, port)
+	url := fmt.Sprintf("%s://%s", DirectScheme(), address)
 	if verbose {
-		log.Printf("Checking %q...", url)
+		logging.Infof("Checking %q...", url)
 	}
 
 	// tmp dir for server
@@ -216,7 +310,8 @@ func CheckVisibilityIPPort(ip, port string, verbose bool) (bool, error) {
 	// create local web server
 	s := Server{
 		Directory: tmpDir,
-		Address:   fmt.Sprintf("%s:%s", ip, port),
+		Address:   address,
+		TLS:       DirectTLS,
 	}
 	server, _, err := s.ServeStatic(false)
 	if err != nil {
@@ -0,0 +1,55 @@
+package web
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestSortAddrs(t *testing.T) {
+	addrs := func() []LocalAddr {
+		var ret []LocalAddr
+		for _, ip := range []string{"::1", "2001:db8::2", "127.0.0.1", "192.168.1.9", "10.0.0.5", "2001:db8::1"} {
+			ret = append(ret, LocalAddr{IP: net.ParseIP(ip), Up: true})
+		}
+		return ret
+	}
+	tests := []struct {
+		name     string
+		preferV6 bool
+		want     []string
+	}{
+		{"ipv4", false, []string{"10.0.0.5", "192.168.1.9", "2001:db8::1", "2001:db8::2", "127.0.0.1", "::1"}},
+		{"ipv6", true, []string{"2001:db8::1", "2001:db8::2", "10.0.0.5", "192.168.1.9", "::1", "127.0.0.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := addrs()
+			sortAddrs(a, tt.preferV6)
+			var got []string
+			for _, x := range a {
+				got = append(got, x.IP.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortAddrs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalAddr_skipReason(t *testing.T) {
+	tests := []struct {
+		addr LocalAddr
+		skip bool
+	}{
+		{LocalAddr{IP: net.ParseIP("192.168.1.9"), Up: true}, false},
+		{LocalAddr{IP: net.ParseIP("2001:db8::1"), Up: true}, false},
+		{LocalAddr{IP: net.ParseIP("fe80::1"), Up: true}, true},
+		{LocalAddr{IP: net.ParseIP("192.168.1.9")}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.addr.skipReason(); (got != "") != tt.skip {
+			t.Errorf("skipReason() of %v = %q, want skipped %v", tt.addr.IP, got, tt.skip)
+		}
+	}
+}
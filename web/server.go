@@ -1,27 +1,65 @@
 package web
 
 import (
-	"log"
+	"crypto/tls"
 	"net"
 	"net/http"
-	"strings"
+	"os"
+	"strconv"
+
+	"github.com/jackytck/alti-cli/logging"
 )
 
 // Server represents a local web server.
 // Format of `Address` is `ip:port`, or `ip:` to get random port.
+// If `StateDir` is set, files are also served in chunks of `ChunkSize`
+// under ChunkPrefix, with the manifests kept in `StateDir`.
+// If `TLS` is set, it is served over https with a self-signed cert.
+// If `Token` is set, it is required as the bearer token of each request.
+// Each of `Handlers` is also served under its pattern.
 type Server struct {
 	Directory string
 	Address   string
+	StateDir  string
+	TLS       bool
+	Token     string
+	Handlers  map[string]http.Handler
 }
 
-// ServeStatic starts a static server serving the contents of the `directory`
-// over `address`. It returns the http.Server and random port number with error.
+// Scheme gives the url scheme of the server, i.e. 'http' or 'https'.
+func (s *Server) Scheme() string {
+	if s.TLS {
+		return "https"
+	}
+	return "http"
+}
+
+// ServeStatic starts a static server serving the contents of the `directory`,
+// including the files in its zip archives, over `address`. It returns the
+// http.Server and random port number with error.
 func (s *Server) ServeStatic(verbose bool) (*http.Server, int, error) {
-	fs := http.FileServer(http.Dir(s.Directory))
+	fs := http.FileServer(archiveDir{http.Dir(s.Directory)})
 	mux := http.NewServeMux()
 	mux.Handle("/", fs)
+	if s.StateDir != "" {
+		mux.Handle(ChunkPrefix, &chunkHandler{
+			dir:       s.Directory,
+			stateDir:  s.StateDir,
+			chunkSize: ChunkSize,
+			verbose:   verbose,
+			manifests: make(map[string]*Manifest),
+		})
+	}
 
-	srv := &http.Server{Handler: mux}
+	for pattern, h := range s.Handlers {
+		mux.Handle(pattern, h)
+	}
+
+	var h http.Handler = mux
+	if s.Token != "" {
+		h = tokenHandler{token: s.Token, next: mux}
+	}
+	srv := &http.Server{Handler: h}
 
 	port := ":0"
 	if s.Address != "" {
@@ -32,19 +70,26 @@ func (s *Server) ServeStatic(verbose bool) (*http.Server, int, error) {
 		return nil, 0, err
 	}
 	p := listener.Addr().(*net.TCPAddr).Port
+	if s.TLS {
+		cert, err := SelfSignedCert(certHosts(s.Address))
+		if err != nil {
+			listener.Close()
+			return nil, 0, err
+		}
+		listener = tls.NewListener(listener, &tls.Config{Certificates: []tls.Certificate{cert}})
+	}
 	if verbose {
-		host := s.Address
-		if host == "" {
+		host, _, err := net.SplitHostPort(s.Address)
+		if err != nil || host == "" {
 			host = "127.0.0.1"
-		} else {
-			host = strings.Split(host, ":")[0]
 		}
-		log.Printf("Serving at http://%s:%d\n", host, p)
+		logging.Infof("Serving at %s://%s\n", s.Scheme(), net.JoinHostPort(host, strconv.Itoa(p)))
 	}
 
 	go func() {
 		if err := srv.Serve(listener); err != http.ErrServerClosed {
-			log.Fatalf("ListenAndServe(): %s", err)
+			logging.Errorf("ListenAndServe(): %s", err)
+			os.Exit(1)
 		}
 	}()
 
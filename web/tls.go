@@ -0,0 +1,96 @@
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// SelfSignedCert generates a self-signed certificate of the given hosts,
+// i.e. ips or domain names, valid for a day.
+func SelfSignedCert(hosts []string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	tmpl := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"alti-cli"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else if h != "" {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// certHosts gives the hosts of the cert of a server listening on address.
+// All of the local ips are given if the host of address is empty.
+func certHosts(address string) []string {
+	host, _, err := net.SplitHostPort(address)
+	if err == nil && host != "" {
+		return []string{host}
+	}
+	ips, _ := GetAllIP()
+	return append(ips, "127.0.0.1", "localhost")
+}
+
+// tokenHandler requires the bearer token of each request, either by the
+// 'Authorization: Bearer' header, or as the first path segment, which is
+// stripped, so that the urls given to the api server are authorized.
+type tokenHandler struct {
+	token string
+	next  http.Handler
+}
+
+func (h tokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		if h.valid(strings.TrimPrefix(auth, "Bearer ")) {
+			h.next.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	seg := strings.SplitN(strings.TrimLeft(r.URL.Path, "/"), "/", 2)
+	if len(seg) < 2 || !h.valid(seg[0]) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	r2 := r.Clone(r.Context())
+	r2.URL.Path = path.Clean("/" + seg[1])
+	r2.URL.RawPath = ""
+	h.next.ServeHTTP(w, r2)
+}
+
+func (h tokenHandler) valid(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
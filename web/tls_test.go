@@ -0,0 +1,64 @@
+package web
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenHandler(t *testing.T) {
+	h := tokenHandler{
+		token: "secret",
+		next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.URL.Path))
+		}),
+	}
+	tests := []struct {
+		name     string
+		target   string
+		auth     string
+		wantCode int
+		wantPath string
+	}{
+		{"no token", "/a.jpg", "", http.StatusUnauthorized, ""},
+		{"path token", "/secret/a.jpg", "", http.StatusOK, "/a.jpg"},
+		{"path token double slash", "/secret//dir/a.jpg", "", http.StatusOK, "/dir/a.jpg"},
+		{"wrong path token", "/guess/a.jpg", "", http.StatusUnauthorized, ""},
+		{"bearer", "/a.jpg", "Bearer secret", http.StatusOK, "/a.jpg"},
+		{"wrong bearer", "/secret/a.jpg", "Bearer guess", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusOK && rec.Body.String() != tt.wantPath {
+				t.Errorf("path = %q, want %q", rec.Body.String(), tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestSelfSignedCert(t *testing.T) {
+	cert, err := SelfSignedCert([]string{"127.0.0.1", "localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.VerifyHostname("127.0.0.1"); err != nil {
+		t.Error(err)
+	}
+	if err = c.VerifyHostname("localhost"); err != nil {
+		t.Error(err)
+	}
+}